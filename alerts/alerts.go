@@ -0,0 +1,187 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// How long to wait for the alert webhook to respond.
+const webhookTimeout = 10 * time.Second
+
+type Severity string
+
+const (
+	// Something is broken, e.g. the database keeps failing
+	SeverityCritical Severity = "critical"
+
+	// Something is degraded and may break, e.g. a backlog is growing
+	SeverityWarning Severity = "warning"
+)
+
+// An operational problem that operators should know about.
+type Alert struct {
+	// Identifies the problem, e.g. "breaker-open:redis". Alerts with the same
+	// key are only sent once per Config.Cooldown.
+	Key      string
+	Severity Severity
+	Summary  string
+}
+
+// The payload format of the alert webhook.
+type Format string
+
+const (
+	// A Slack incoming webhook, or anything that accepts {"text": "..."}
+	FormatSlack Format = "slack"
+
+	// The PagerDuty Events API v2
+	FormatPagerDuty Format = "pagerduty"
+)
+
+type Config struct {
+	// Alerts are only logged if it's empty
+	WebhookUrl string
+	Format     Format
+
+	// The integration key of the PagerDuty service, only for FormatPagerDuty
+	RoutingKey string
+
+	Cooldown time.Duration
+
+	// The database is failing if at least DatabaseFailureThreshold queries
+	// fail within DatabaseFailureWindow, see WatchDatabase
+	DatabaseFailureThreshold int
+	DatabaseFailureWindow    time.Duration
+
+	// How often circuit breakers and backlogs are checked, see Monitor
+	CheckInterval time.Duration
+}
+
+// Sends alerts to the configured webhook, see Config.
+type Dispatcher struct {
+	Config Config
+	Client *http.Client
+
+	// Where alerts come from, sent along with them
+	source string
+
+	mu sync.Mutex
+	// When each alert was last sent, by key
+	lastSent map[string]time.Time
+}
+
+func NewDispatcher(config Config) *Dispatcher {
+	source, err := os.Hostname()
+	if err != nil {
+		source = "open-collaboration"
+	}
+
+	return &Dispatcher{
+		Config:   config,
+		Client:   &http.Client{Timeout: webhookTimeout},
+		source:   source,
+		lastSent: map[string]time.Time{},
+	}
+}
+
+// Send an alert in the background, see Send.
+func (d *Dispatcher) Alert(ctx context.Context, alert Alert) {
+	if !d.claim(alert.Key) {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	go func() {
+		err := d.send(log.NewContext(context.Background(), logger), alert)
+		if err != nil {
+			logger.WithError(err).WithField("alert", alert.Key).Error("Failed to send alert")
+		}
+	}()
+}
+
+// Log an alert and post it to the webhook. Alerts whose key was sent less
+// than Config.Cooldown ago are skipped.
+func (d *Dispatcher) Send(ctx context.Context, alert Alert) error {
+	if !d.claim(alert.Key) {
+		return nil
+	}
+
+	return d.send(ctx, alert)
+}
+
+// Whether an alert can be sent, i.e. its key wasn't sent less than
+// Config.Cooldown ago, marking it as sent.
+func (d *Dispatcher) claim(key string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.lastSent[key]) < d.Config.Cooldown {
+		return false
+	}
+	d.lastSent[key] = time.Now()
+
+	return true
+}
+
+func (d *Dispatcher) send(ctx context.Context, alert Alert) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"alert":    alert.Key,
+		"severity": alert.Severity,
+	})
+	logger.Warn(alert.Summary)
+
+	if d.Config.WebhookUrl == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(d.payload(alert))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", d.Config.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := d.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook failed with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) payload(alert Alert) interface{} {
+	if d.Config.Format == FormatPagerDuty {
+		return map[string]interface{}{
+			"routing_key":  d.Config.RoutingKey,
+			"event_action": "trigger",
+			"dedup_key":    alert.Key,
+			"payload": map[string]interface{}{
+				"summary":  alert.Summary,
+				"source":   d.source,
+				"severity": alert.Severity,
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"text": fmt.Sprintf("[%s] %s (%s)", alert.Severity, alert.Summary, d.source),
+	}
+}
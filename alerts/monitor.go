@@ -0,0 +1,138 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/breaker"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+// Alert when the database keeps failing: when at least
+// Config.DatabaseFailureThreshold queries fail within
+// Config.DatabaseFailureWindow. Records that aren't found and canceled
+// queries aren't failures.
+func (d *Dispatcher) WatchDatabase(db *gorm.DB) error {
+	var mu sync.Mutex
+	var failures []time.Time
+
+	check := func(tx *gorm.DB) {
+		err := tx.Error
+		if err == nil ||
+			errors.Is(err, gorm.ErrRecordNotFound) ||
+			errors.Is(err, context.Canceled) ||
+			errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+
+		now := time.Now()
+
+		mu.Lock()
+		// Failures are in chronological order, drop the ones out of the window
+		start := 0
+		for start < len(failures) && now.Sub(failures[start]) > d.Config.DatabaseFailureWindow {
+			start++
+		}
+		failures = append(failures[start:], now)
+		count := len(failures)
+		mu.Unlock()
+
+		if count >= d.Config.DatabaseFailureThreshold {
+			d.Alert(tx.Statement.Context, Alert{
+				Key:      "database-failures",
+				Severity: SeverityCritical,
+				Summary:  fmt.Sprintf("%d database queries failed in the last %s, the last one with: %s", count, d.Config.DatabaseFailureWindow, err),
+			})
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("alerts:create", check),
+		callbacks.Query().After("gorm:query").Register("alerts:query", check),
+		callbacks.Update().After("gorm:update").Register("alerts:update", check),
+		callbacks.Delete().After("gorm:delete").Register("alerts:delete", check),
+		callbacks.Row().After("gorm:row").Register("alerts:row", check),
+		callbacks.Raw().After("gorm:raw").Register("alerts:raw", check),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// A queue of background work, e.g. reports waiting to be generated.
+type Backlog struct {
+	Name string
+
+	// Alert once the backlog has more than Threshold items
+	Threshold int64
+	Length    func(ctx context.Context) (int64, error)
+}
+
+// Checks circuit breakers and backlogs every Config.CheckInterval, alerting
+// when a breaker is open or a backlog is over its threshold.
+type Monitor struct {
+	Dispatcher *Dispatcher
+	Breakers   *breaker.Registry
+	Backlogs   []Backlog
+}
+
+func NewMonitor(dispatcher *Dispatcher, breakers *breaker.Registry, backlogs ...Backlog) *Monitor {
+	return &Monitor{
+		Dispatcher: dispatcher,
+		Breakers:   breakers,
+		Backlogs:   backlogs,
+	}
+}
+
+// Check until ctx is done. Should be run in its own goroutine.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.Dispatcher.Config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+func (m *Monitor) check(ctx context.Context) {
+	for _, stats := range m.Breakers.Stats() {
+		if stats.State != breaker.StateOpen {
+			continue
+		}
+
+		m.Dispatcher.Alert(ctx, Alert{
+			Key:      "breaker-open:" + stats.Name,
+			Severity: SeverityCritical,
+			Summary:  fmt.Sprintf("The %s circuit breaker is open after %d consecutive failures", stats.Name, stats.ConsecutiveFailures),
+		})
+	}
+
+	for _, backlog := range m.Backlogs {
+		length, err := backlog.Length(ctx)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).WithField("backlog", backlog.Name).Warn("Failed to check backlog")
+
+			continue
+		}
+
+		if length > backlog.Threshold {
+			m.Dispatcher.Alert(ctx, Alert{
+				Key:      "backlog:" + backlog.Name,
+				Severity: SeverityWarning,
+				Summary:  fmt.Sprintf("The %s backlog has %d items, over its threshold of %d", backlog.Name, length, backlog.Threshold),
+			})
+		}
+	}
+}
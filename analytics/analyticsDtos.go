@@ -0,0 +1,53 @@
+package analytics
+
+import "time"
+
+type EventsDto struct {
+	Events []NewEventDto `json:"events" validate:"required,min=1,max=50,dive"`
+}
+
+type NewEventDto struct {
+	// project-viewed, search-performed, apply-clicked or role-viewed
+	Type EventType `json:"type" validate:"required,oneof=project-viewed search-performed apply-clicked role-viewed"`
+
+	// Required for project-viewed, apply-clicked and role-viewed events
+	ProjectId uint `json:"projectId" validate:"required_unless=Type search-performed"`
+
+	// One of the project's roles, required for role-viewed events. Optional
+	// for apply-clicked events, when applying to a role.
+	RoleId uint `json:"roleId" validate:"required_if=Type role-viewed"`
+
+	// Required for search-performed events
+	Query string `json:"query" validate:"required_if=Type search-performed,max=200"`
+
+	// When the event happened on the client, now if missing. Times too far
+	// from the server's are replaced by the server's.
+	OccurredAt *time.Time `json:"occurredAt"`
+}
+
+type FunnelDto struct {
+	// The Mondays of the weeks the funnel covers, oldest first, as
+	// YYYY-MM-DD. The other series have a count for each week.
+	Weeks []string `json:"weeks"`
+
+	// Views of the project's page
+	ProjectViews []int64 `json:"projectViews"`
+
+	// The steps after the project's page, by role
+	Roles []RoleFunnelDto `json:"roles"`
+}
+
+type RoleFunnelDto struct {
+	// 0 for applications to the project in general
+	RoleId uint `json:"roleId"`
+
+	// Empty for applications to the project in general and for roles that
+	// don't exist anymore
+	Title string `json:"title"`
+
+	RoleViews    []int64 `json:"roleViews"`
+	Applications []int64 `json:"applications"`
+
+	// Counted in the week the application was accepted
+	Acceptances []int64 `json:"acceptances"`
+}
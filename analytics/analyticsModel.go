@@ -0,0 +1,36 @@
+package analytics
+
+import "time"
+
+// What a user did.
+type EventType string
+
+const (
+	EventProjectViewed   EventType = "project-viewed"
+	EventSearchPerformed EventType = "search-performed"
+	EventApplyClicked    EventType = "apply-clicked"
+
+	// The details of one of a project's roles were viewed
+	EventRoleViewed EventType = "role-viewed"
+)
+
+// An anonymized interaction: events don't record who did what, only what was
+// done and when.
+type Event struct {
+	Type EventType
+
+	// The project the event is about, nil for searches
+	ProjectId *uint
+
+	// The role the event is about, nil if it isn't about a role
+	RoleId *uint
+
+	// The normalized search query of search events, empty for the others
+	Query string
+
+	OccurredAt time.Time
+}
+
+func (Event) TableName() string {
+	return "analytics_events"
+}
@@ -0,0 +1,87 @@
+package analytics
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Record interaction events
+// @Description Events are anonymized: who performed them isn't recorded, even when authenticated. They're
+// @Description written in the background, so accepted events may still be dropped.
+// @Description project-viewed and apply-clicked events need a projectId, search-performed events need a query.
+// @Description role-viewed events need a projectId and a roleId, apply-clicked events can have one.
+// @Tags analytics
+// @Router /analytics/events [post]
+// @Param events body analytics.EventsDto true "Up to 50 events"
+// @Success 202
+// @Failure 400
+func RouteRecordEvents(
+	writer http.ResponseWriter,
+	request *http.Request,
+	analyticsService Service,
+) error {
+	dto := EventsDto{}
+	err := utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = analyticsService.RecordEvents(request.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusAccepted)
+
+	return nil
+}
+
+// @Summary Get a project's applicant funnel
+// @Description How many people viewed the project, viewed each of its roles, applied and were accepted, week by
+// @Description week, computed from the recorded events. Weeks start on Monday (UTC), the current week is included.
+// @Description Only the project's owner can see it. Unavailable unless ANALYTICS_SINK is database.
+// @Tags analytics
+// @Router /projects/{projectId}/funnel [get]
+// @Param projectId path int true "The project ID"
+// @Param weeks query int false "How many weeks to cover. Default is 12, max is 52."
+// @Success 200 {object} analytics.FunnelDto
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 501 "Events aren't written to the database"
+func RouteGetProjectFunnel(
+	writer http.ResponseWriter,
+	request *http.Request,
+	analyticsService Service,
+	projectsService projects.Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	if project.OwnerId != session.UserId() {
+		return auth.ErrForbidden
+	}
+
+	weeks, _ := utils.IntFromQuery(request, "weeks", DefaultFunnelWeeks)
+
+	funnel, err := analyticsService.GetProjectFunnel(request.Context(), project, weeks)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, funnel)
+}
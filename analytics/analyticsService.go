@@ -0,0 +1,168 @@
+package analytics
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/projects"
+	"strings"
+	"time"
+)
+
+// How many events can wait to be written. Events recorded while the queue is
+// full are dropped.
+const queueSize = 10000
+
+// Events are written to the sink in batches of up to this many events...
+const batchSize = 500
+
+// ...or every flushInterval, whichever comes first.
+const flushInterval = 5 * time.Second
+
+// Events whose client time is further than this from the server's are
+// recorded at the server's time.
+const maxClockSkew = time.Hour
+
+type Service interface {
+	// Queue events to be written to the sink by Run.
+	RecordEvents(ctx context.Context, dto EventsDto) error
+
+	// Write queued events to the sink in batches until ctx is done. Should be
+	// run in its own goroutine.
+	Run(ctx context.Context)
+
+	// How many events are waiting to be written.
+	QueueLength() int
+
+	// Get how many people viewed the project, viewed each of its roles,
+	// applied and were accepted, each week of the last weeks (see
+	// MaxFunnelWeeks), so that its owner can tell where applicants drop out.
+	// Returns ErrFunnelUnavailable if events aren't written to the database.
+	GetProjectFunnel(ctx context.Context, project projects.ProjectDto, weeks int) (FunnelDto, error)
+}
+
+type serviceImpl struct {
+	// nil if events aren't recorded
+	Sink Sink
+
+	queue chan Event
+}
+
+// Create an analytics service. Events are dropped if sink is nil.
+func NewService(sink Sink) Service {
+	return &serviceImpl{
+		Sink:  sink,
+		queue: make(chan Event, queueSize),
+	}
+}
+
+func (s *serviceImpl) RecordEvents(ctx context.Context, dto EventsDto) error {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return err
+	}
+
+	if s.Sink == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	for _, eventDto := range dto.Events {
+		event := Event{
+			Type:       eventDto.Type,
+			OccurredAt: now,
+		}
+
+		if eventDto.Type == EventSearchPerformed {
+			event.Query = normalizeQuery(eventDto.Query)
+		} else {
+			projectId := eventDto.ProjectId
+			event.ProjectId = &projectId
+		}
+
+		if eventDto.RoleId != 0 && (eventDto.Type == EventRoleViewed || eventDto.Type == EventApplyClicked) {
+			roleId := eventDto.RoleId
+			event.RoleId = &roleId
+		}
+
+		if eventDto.OccurredAt != nil {
+			skew := now.Sub(*eventDto.OccurredAt)
+			if skew > -maxClockSkew && skew < maxClockSkew {
+				event.OccurredAt = *eventDto.OccurredAt
+			}
+		}
+
+		select {
+		case s.queue <- event:
+		default:
+			log.FromContext(ctx).Warn("Analytics events queue is full, dropping events")
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Lowercase a search query and collapse its whitespace, so that the same
+// search is always recorded the same way.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+func (s *serviceImpl) QueueLength() int {
+	return len(s.queue)
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	if s.Sink == nil {
+		return
+	}
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, batchSize)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx is done, events still have a moment to be written
+			flushCtx, cancel := context.WithTimeout(log.NewContext(context.Background(), log.FromContext(ctx)), flushInterval)
+			s.flush(flushCtx, batch)
+			cancel()
+
+			return
+
+		case event := <-s.queue:
+			batch = append(batch, event)
+			if len(batch) >= batchSize {
+				s.flush(ctx, batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			s.flush(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// Write a batch of events to the sink. Batches that fail to be written are
+// dropped.
+func (s *serviceImpl) flush(ctx context.Context, batch []Event) {
+	if len(batch) < 1 {
+		return
+	}
+
+	err := s.Sink.Write(ctx, batch)
+	if err != nil {
+		log.FromContext(ctx).
+			WithError(err).
+			WithField("events", len(batch)).
+			Error("Failed to write analytics events")
+	}
+}
@@ -0,0 +1,164 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/projects"
+	"sort"
+	"time"
+)
+
+var ErrFunnelUnavailable = errors.New("funnels need analytics events to be written to the database")
+
+// How many weeks funnels cover by default, and at most.
+const DefaultFunnelWeeks = 12
+const MaxFunnelWeeks = 52
+
+// A count of a funnel step on a day, by role.
+type dayCount struct {
+	Day    string
+	RoleId *uint
+	Count  int64
+}
+
+func (s *serviceImpl) GetProjectFunnel(ctx context.Context, project projects.ProjectDto, weeks int) (FunnelDto, error) {
+	sink, ok := s.Sink.(*databaseSink)
+	if !ok {
+		return FunnelDto{}, ErrFunnelUnavailable
+	}
+
+	if weeks < 1 || weeks > MaxFunnelWeeks {
+		weeks = DefaultFunnelWeeks
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", project.Id)
+	db := sink.Db.WithContext(ctx)
+	day := database.DialectOf(sink.Db).Day
+
+	// Weeks start on Monday, UTC. The current week is included.
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	since := today.AddDate(0, 0, -(int(today.Weekday())+6)%7-7*(weeks-1))
+
+	var views, roleViews, applications, acceptances []dayCount
+
+	result := db.Table("analytics_events").
+		Select(day("occurred_at")+" AS day, count(*) AS count").
+		Where("project_id = ? AND type = ? AND occurred_at >= ?", project.Id, EventProjectViewed, since).
+		Group("day").
+		Scan(&views)
+
+	if result.Error == nil {
+		result = db.Table("analytics_events").
+			Select(day("occurred_at")+" AS day, role_id, count(*) AS count").
+			Where("project_id = ? AND type = ? AND occurred_at >= ?", project.Id, EventRoleViewed, since).
+			Group("day, role_id").
+			Scan(&roleViews)
+	}
+
+	if result.Error == nil {
+		result = db.Table("applications").
+			Select(day("created_at")+" AS day, role_id, count(*) AS count").
+			Where("project_id = ? AND deleted_at IS NULL AND hidden = false AND created_at >= ?", project.Id, since).
+			Group("day, role_id").
+			Scan(&applications)
+	}
+
+	if result.Error == nil {
+		result = db.Table("applications").
+			Select(day("reviewed_at")+" AS day, role_id, count(*) AS count").
+			Where("project_id = ? AND deleted_at IS NULL AND status = 'accepted' AND reviewed_at >= ?", project.Id, since).
+			Group("day, role_id").
+			Scan(&acceptances)
+	}
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to count funnel steps")
+
+		return FunnelDto{}, result.Error
+	}
+
+	funnel := FunnelDto{
+		Weeks:        make([]string, weeks),
+		ProjectViews: make([]int64, weeks),
+		Roles:        []RoleFunnelDto{},
+	}
+
+	for i := range funnel.Weeks {
+		funnel.Weeks[i] = since.AddDate(0, 0, 7*i).Format("2006-01-02")
+	}
+
+	// The project's current roles are listed even without events. Roles are
+	// recreated when the project is edited, so events can be of roles that
+	// don't exist anymore.
+	roles := map[uint]*RoleFunnelDto{}
+	role := func(roleId *uint) *RoleFunnelDto {
+		var id uint
+		if roleId != nil {
+			id = *roleId
+		}
+
+		if roles[id] == nil {
+			roles[id] = &RoleFunnelDto{
+				RoleId:       id,
+				RoleViews:    make([]int64, weeks),
+				Applications: make([]int64, weeks),
+				Acceptances:  make([]int64, weeks),
+			}
+		}
+
+		return roles[id]
+	}
+
+	for _, projectRole := range project.Roles {
+		id := projectRole.Id
+		role(&id).Title = projectRole.Title
+	}
+
+	for _, count := range views {
+		if week, ok := weekIndex(since, count.Day, weeks); ok {
+			funnel.ProjectViews[week] += count.Count
+		}
+	}
+
+	for _, step := range []struct {
+		counts []dayCount
+		series func(role *RoleFunnelDto) []int64
+	}{
+		{roleViews, func(role *RoleFunnelDto) []int64 { return role.RoleViews }},
+		{applications, func(role *RoleFunnelDto) []int64 { return role.Applications }},
+		{acceptances, func(role *RoleFunnelDto) []int64 { return role.Acceptances }},
+	} {
+		for _, count := range step.counts {
+			if week, ok := weekIndex(since, count.Day, weeks); ok {
+				step.series(role(count.RoleId))[week] += count.Count
+			}
+		}
+	}
+
+	for _, role := range roles {
+		funnel.Roles = append(funnel.Roles, *role)
+	}
+
+	// Applications to the project in general (role 0) come first
+	sort.Slice(funnel.Roles, func(i, j int) bool {
+		return funnel.Roles[i].RoleId < funnel.Roles[j].RoleId
+	})
+
+	return funnel, nil
+}
+
+// The index of the week of a YYYY-MM-DD day among the weeks starting at
+// since, false if it isn't in one of them.
+func weekIndex(since time.Time, day string, weeks int) (int, bool) {
+	date, err := time.Parse("2006-01-02", day)
+	if err != nil || date.Before(since) {
+		return 0, false
+	}
+
+	week := int(date.Sub(since).Hours() / 24 / 7)
+
+	return week, week < weeks
+}
@@ -0,0 +1,91 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: analyticsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	analytics "github.com/open-collaboration/server/analytics"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// RecordEvents mocks base method
+func (m *MockService) RecordEvents(ctx context.Context, dto analytics.EventsDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordEvents", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordEvents indicates an expected call of RecordEvents
+func (mr *MockServiceMockRecorder) RecordEvents(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordEvents", reflect.TypeOf((*MockService)(nil).RecordEvents), ctx, dto)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
+
+// QueueLength mocks base method
+func (m *MockService) QueueLength() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueLength")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// QueueLength indicates an expected call of QueueLength
+func (mr *MockServiceMockRecorder) QueueLength() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueLength", reflect.TypeOf((*MockService)(nil).QueueLength))
+}
+
+// GetProjectFunnel mocks base method
+func (m *MockService) GetProjectFunnel(ctx context.Context, project projects.ProjectDto, weeks int) (analytics.FunnelDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectFunnel", ctx, project, weeks)
+	ret0, _ := ret[0].(analytics.FunnelDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProjectFunnel indicates an expected call of GetProjectFunnel
+func (mr *MockServiceMockRecorder) GetProjectFunnel(ctx, project, weeks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectFunnel", reflect.TypeOf((*MockService)(nil).GetProjectFunnel), ctx, project, weeks)
+}
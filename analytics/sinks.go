@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/database"
+	"gorm.io/gorm"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How long to wait for the webhook sink to respond.
+const webhookTimeout = 10 * time.Second
+
+// Where batches of events are written to.
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+}
+
+// Writes events to the analytics_events table, which is partitioned by month
+// (UTC).
+// Partitions are created as events of new months come in; events that can't
+// be written to their month's partition end up in the default partition.
+type databaseSink struct {
+	Db *gorm.DB
+
+	// Months whose partition is known to exist, by partition name
+	partitions sync.Map
+}
+
+func NewDatabaseSink(db *gorm.DB) Sink {
+	return &databaseSink{Db: db}
+}
+
+func (s *databaseSink) Write(ctx context.Context, events []Event) error {
+	for _, event := range events {
+		err := s.ensurePartition(ctx, event.OccurredAt)
+		if err != nil {
+			// e.g. the default partition already has events of the month
+			log.FromContext(ctx).WithError(err).Warn("Failed to create analytics events partition, using the default one")
+		}
+	}
+
+	return s.Db.WithContext(ctx).CreateInBatches(events, 500).Error
+}
+
+// Create the partition of the month of t if it doesn't exist yet. SQLite
+// has no partitions.
+func (s *databaseSink) ensurePartition(ctx context.Context, t time.Time) error {
+	if database.IsSqlite(s.Db) {
+		return nil
+	}
+
+	t = t.UTC()
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	name := fmt.Sprintf("analytics_events_%04d_%02d", month.Year(), month.Month())
+
+	if _, ok := s.partitions.Load(name); ok {
+		return nil
+	}
+
+	// Partition bounds can't be bind parameters, they're formatted dates
+	err := s.Db.WithContext(ctx).Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF analytics_events FOR VALUES FROM ('%s') TO ('%s')",
+		name,
+		month.Format("2006-01-02 15:04:05Z07:00"),
+		month.AddDate(0, 1, 0).Format("2006-01-02 15:04:05Z07:00"),
+	)).Error
+
+	// Not retried if it failed, events go to the default partition instead
+	s.partitions.Store(name, true)
+
+	return err
+}
+
+// Posts batches of events as JSON to a webhook, e.g. the ingestion endpoint
+// of an analytics pipeline.
+type webhookSink struct {
+	Url string
+
+	// Sent as a bearer token, optional
+	Token string
+
+	Client *http.Client
+}
+
+func NewWebhookSink(url string, token string) Sink {
+	return &webhookSink{
+		Url:    url,
+		Token:  token,
+		Client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+type webhookEvent struct {
+	Type       EventType `json:"type"`
+	ProjectId  *uint     `json:"projectId,omitempty"`
+	RoleId     *uint     `json:"roleId,omitempty"`
+	Query      string    `json:"query,omitempty"`
+	OccurredAt time.Time `json:"occurredAt"`
+}
+
+func (s *webhookSink) Write(ctx context.Context, events []Event) error {
+	payload := make([]webhookEvent, len(events))
+	for i, event := range events {
+		payload[i] = webhookEvent(event)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"events": payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", s.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("analytics webhook responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
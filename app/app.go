@@ -0,0 +1,607 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-redis/redis/v8"
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/open-collaboration/server/alerts"
+	"github.com/open-collaboration/server/analytics"
+	"github.com/open-collaboration/server/applications"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/blocklist"
+	"github.com/open-collaboration/server/breaker"
+	"github.com/open-collaboration/server/broadcasts"
+	"github.com/open-collaboration/server/calendar"
+	"github.com/open-collaboration/server/capture"
+	"github.com/open-collaboration/server/cdn"
+	"github.com/open-collaboration/server/chat"
+	"github.com/open-collaboration/server/collections"
+	"github.com/open-collaboration/server/contributions"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/diagnostics"
+	"github.com/open-collaboration/server/email"
+	"github.com/open-collaboration/server/encryption"
+	"github.com/open-collaboration/server/experiments"
+	"github.com/open-collaboration/server/exports"
+	"github.com/open-collaboration/server/homepage"
+	"github.com/open-collaboration/server/identities"
+	"github.com/open-collaboration/server/impersonation"
+	"github.com/open-collaboration/server/integrations"
+	"github.com/open-collaboration/server/invites"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/meta"
+	"github.com/open-collaboration/server/migrations"
+	"github.com/open-collaboration/server/mobilepush"
+	"github.com/open-collaboration/server/moderation"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/ogimage"
+	"github.com/open-collaboration/server/portfolio"
+	"github.com/open-collaboration/server/presence"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/readme"
+	"github.com/open-collaboration/server/reports"
+	"github.com/open-collaboration/server/retention"
+	"github.com/open-collaboration/server/router"
+	"github.com/open-collaboration/server/savedsearches"
+	"github.com/open-collaboration/server/search"
+	"github.com/open-collaboration/server/secrets"
+	"github.com/open-collaboration/server/selfcheck"
+	"github.com/open-collaboration/server/sockpuppets"
+	"github.com/open-collaboration/server/tags"
+	"github.com/open-collaboration/server/undo"
+	"github.com/open-collaboration/server/usage"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"github.com/open-collaboration/server/waitlist"
+	"github.com/open-collaboration/server/webpush"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"time"
+)
+
+// The wired application: its connections, services and router.
+//
+// New subsystems are constructed in Wire and added to Providers, which makes
+// them available to route handlers.
+type App struct {
+	Config   Config
+	Db       *gorm.DB
+	Breakers *breaker.Registry
+
+	// Nil if the key value store is Postgres
+	Redis *redis.Client
+	Kv    kv.Store
+
+	// The services and values route handlers can receive
+	Providers []interface{}
+	Router    *mux.Router
+
+	// Background work started by Start
+	background []func(ctx context.Context)
+}
+
+// Connect to the database and redis (unless KEY_VALUE_STORE is "postgres"),
+// run the database migrations and wire the application.
+func New(config Config) (*App, error) {
+	db, err := OpenDatabase(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = migrations.GetMigration(db).Migrate()
+	if err != nil {
+		// Sent before returning since the server won't start
+		alertErr := alerts.NewDispatcher(config.Alerts).Send(context.Background(), alerts.Alert{
+			Key:      "migration-failed",
+			Severity: alerts.SeverityCritical,
+			Summary:  fmt.Sprintf("Database migrations failed at startup: %s", err),
+		})
+		if alertErr != nil {
+			log.WithError(alertErr).Error("Failed to send alert")
+		}
+
+		return nil, err
+	}
+
+	if config.KeyValueStore != "redis" {
+		return Wire(config, db, nil)
+	}
+
+	redisDb := redis.NewClient(&redis.Options{
+		Addr: config.RedisAddr,
+
+		// Read on every connection instead of set as the Password option, so
+		// that new connections use the rotated password
+		OnConnect: func(ctx context.Context, conn *redis.Conn) error {
+			password := config.Secrets.Get("REDIS_PASSWORD")
+			if password == "" {
+				return nil
+			}
+
+			return conn.Auth(ctx, password).Err()
+		},
+	})
+
+	// Reported by the startup checks, which decide whether to serve
+	_, err = redisDb.Ping(context.Background()).Result()
+	if err != nil {
+		log.WithError(err).Error("Failed to connect to redis")
+	}
+
+	return Wire(config, db, redisDb)
+}
+
+// How long database connections are kept when secrets can rotate, so that no
+// connection outlives rotated credentials for long.
+const secretsConnMaxLifetime = 15 * time.Minute
+
+// Connect to the database without migrating it, e.g. to restore a backup.
+func OpenDatabase(config Config) (*gorm.DB, error) {
+	dialector := postgres.Open(config.PostgresDsn)
+	if path, ok := database.SqlitePath(config.DatabaseUrl); ok {
+		dialector = database.OpenSqlite(path)
+	} else if config.DatabaseUrl != "" {
+		// An explicit URL wins over the secrets provider's credentials
+		dialector = postgres.Open(config.DatabaseUrl)
+	} else if config.Secrets != nil {
+		sqlDb := sql.OpenDB(secretsConnector{Secrets: config.Secrets})
+		sqlDb.SetConnMaxLifetime(secretsConnMaxLifetime)
+
+		dialector = postgres.New(postgres.Config{Conn: sqlDb})
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Interface(&utils.GormLogger{}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return db.Debug(), nil
+}
+
+// Opens database connections with the current secrets, so that new
+// connections use rotated credentials.
+type secretsConnector struct {
+	Secrets *secrets.Store
+}
+
+func (c secretsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	connector, err := stdlib.GetDefaultDriver().(driver.DriverContext).OpenConnector(postgresDsn(c.Secrets))
+	if err != nil {
+		return nil, err
+	}
+
+	return connector.Connect(ctx)
+}
+
+func (c secretsConnector) Driver() driver.Driver {
+	return stdlib.GetDefaultDriver()
+}
+
+// Wire the application on already open connections, e.g. the containers of
+// the testsupport package. The database has to be migrated already. If
+// redisDb is nil sessions, caches and rate limits are stored in the database.
+func Wire(config Config, db *gorm.DB, redisDb *redis.Client) (*App, error) {
+	app := &App{
+		Config:   config,
+		Db:       db,
+		Redis:    redisDb,
+		Breakers: breaker.NewRegistry(),
+	}
+
+	alertDispatcher := alerts.NewDispatcher(config.Alerts)
+	err := alertDispatcher.WatchDatabase(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var sessionStore auth.SessionStore
+	if redisDb != nil {
+		// Added after the connection is tested so that the server doesn't start
+		// with an open breaker.
+		redisDb.AddHook(breaker.NewRedisHook(app.breaker("redis")))
+
+		app.Kv = kv.NewRedisStore(redisDb, config.RedisKeyPrefix)
+		sessionStore = auth.NewRedisSessionStore(redisDb, config.RedisKeyPrefix)
+	} else {
+		kvStore := kv.NewPostgresStore(db)
+		app.Kv = kvStore
+		app.background = append(app.background, kvStore.Run)
+
+		postgresSessionStore := auth.NewPostgresSessionStore(db)
+		sessionStore = postgresSessionStore
+		app.background = append(app.background, postgresSessionStore.Run)
+	}
+
+	if config.Secrets != nil {
+		app.background = append(app.background, config.Secrets.Run)
+	}
+
+	emailSender, err := app.newEmailSender()
+	if err != nil {
+		return nil, err
+	}
+
+	emailTemplates, err := email.NewTemplates(config.EmailTemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	blocklistService := blocklist.NewService(db, app.Kv)
+	invitesService := invites.NewService(db, config.RegistrationMode, config.InviteQuota)
+	waitlistService := waitlist.NewService(db, emailSender, emailTemplates, config.RegistrationMode, config.FrontendUrl+"/signup")
+
+	// The invites and waitlist services claim invite codes and signup tokens in
+	// the transaction the user is created in (see users.RegistrationClaim), so a
+	// registration that's rejected or fails doesn't burn a code.
+	usersService := users.NewService(
+		users.NewGormRepository(db),
+		emailSender,
+		emailTemplates,
+		config.EnumerationProtection,
+		config.FrontendUrl,
+		blocklistService,
+		invitesService,
+		waitlistService,
+	)
+
+	sessionCache := auth.NewSessionCache(app.Kv, config.SessionCacheSize, config.SessionCacheTtl)
+	app.background = append(app.background, sessionCache.Listen)
+
+	authService := auth.NewService(db, sessionStore, sessionCache, usersService, config.EnumerationProtection, blocklistService)
+
+	var oauthProviders []identities.Provider
+	if config.Github.ClientId != "" {
+		oauthProviders = append(oauthProviders, identities.NewGithubProvider(config.Github.ClientId, config.Github.ClientSecret))
+	}
+	if config.Google.ClientId != "" {
+		oauthProviders = append(oauthProviders, identities.NewGoogleProvider(config.Google.ClientId, config.Google.ClientSecret))
+	}
+	for i, provider := range oauthProviders {
+		oauthProviders[i] = identities.NewBreakerProvider(provider, app.breaker("oauth-"+provider.Name()))
+	}
+
+	identitiesService := identities.NewService(
+		db,
+		app.Kv,
+		authService,
+		usersService,
+		config.FrontendUrl,
+		oauthProviders...,
+	)
+
+	auditService := audit.NewService(db)
+	impersonationService := impersonation.NewService(authService, usersService, auditService, config.ImpersonationDuration)
+
+	var embeddingProvider search.EmbeddingProvider
+	if config.EmbeddingsProvider == "openai" {
+		embeddingProvider = search.NewBreakerEmbeddingProvider(search.NewOpenAiEmbeddingProvider(
+			config.Embeddings.Url,
+			config.Embeddings.ApiKey,
+			config.Embeddings.Model,
+		), app.breaker("embeddings"))
+	}
+
+	encryptionService, err := encryption.NewService(config.EncryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	webpushService, err := webpush.NewService(db, config.VapidPrivateKey, config.VapidSubject, encryptionService)
+	if err != nil {
+		return nil, err
+	}
+
+	app.background = append(app.background, webpushService.Run)
+
+	var notificationChannels []notifications.Channel
+	if config.VapidPrivateKey != "" {
+		notificationChannels = append(notificationChannels, webpushService)
+	}
+
+	var pushProviders []mobilepush.Provider
+	if config.FcmCredentialsFile != "" {
+		fcmProvider, err := mobilepush.NewFcmProvider(config.FcmCredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pushProviders = append(pushProviders, fcmProvider)
+	}
+	if config.Apns.KeyFile != "" {
+		apnsProvider, err := mobilepush.NewApnsProvider(
+			config.Apns.KeyFile,
+			config.Apns.KeyId,
+			config.Apns.TeamId,
+			config.Apns.Topic,
+			config.Apns.Sandbox,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		pushProviders = append(pushProviders, apnsProvider)
+	}
+
+	mobilepushService := mobilepush.NewService(db, pushProviders...)
+	if len(pushProviders) > 0 {
+		notificationChannels = append(notificationChannels, mobilepushService)
+	}
+
+	notificationsService := notifications.NewService(db, usersService, notificationChannels...)
+	savedSearchesService := savedsearches.NewService(
+		db,
+		notificationsService,
+		usersService,
+		emailSender,
+		emailTemplates,
+		config.FrontendUrl,
+	)
+	searchService := search.NewService(db, embeddingProvider)
+	cdnService := cdn.NewService(config.CdnPurgeWebhookUrls, config.CdnPurgeToken)
+	projectsService := projects.NewService(
+		projects.NewGormRepository(db),
+		app.Kv,
+		config.ProjectRules,
+		usersService,
+		projects.NewTrigramSimilarity(db),
+		notificationsService,
+		searchService,
+		savedSearchesService,
+		cdnService,
+	)
+	webhookBreakers := map[integrations.Provider]*breaker.Breaker{}
+	for _, provider := range integrations.Providers {
+		webhookBreakers[provider] = app.breaker("webhook-" + string(provider))
+	}
+	integrationsService := integrations.NewService(
+		db,
+		projectsService,
+		usersService,
+		notificationsService,
+		config.FrontendUrl,
+		encryptionService,
+		webhookBreakers,
+	)
+	applicationsService := applications.NewService(
+		db,
+		app.Kv,
+		projectsService,
+		notificationsService,
+		applications.SpamThresholds{
+			Window:            config.ApplicationSpamWindow,
+			FlagThreshold:     config.ApplicationSpamFlagThreshold,
+			ThrottleThreshold: config.ApplicationSpamThrottleThreshold,
+		},
+		config.ApplicationExpiry,
+		integrationsService,
+	)
+
+	app.background = append(app.background, applicationsService.Run)
+
+	homepageService := homepage.NewService(db, app.Kv, projectsService)
+
+	githubClient := contributions.NewBreakerGithubClient(contributions.NewGithubClient(config.GithubApiToken), app.breaker("github"))
+	readmeService := readme.NewService(db, projectsService, githubClient, config.ReadmeRefreshInterval)
+	app.background = append(app.background, readmeService.Run)
+
+	ogImageService, err := ogimage.NewService(db, app.Kv, config.OgImageTemplate, config.Instance.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	broadcastsService := broadcasts.NewService(
+		db,
+		notificationsService,
+		auditService,
+		emailSender,
+		emailTemplates,
+		config.BroadcastRateLimit,
+	)
+	app.background = append(app.background, broadcastsService.Run)
+
+	usageService := usage.NewService(db, usersService, auditService, int64(config.UsageDailyQuota))
+	app.background = append(app.background, usageService.Run)
+
+	chatService := chat.NewService(db, app.Kv, projectsService, usersService, notificationsService, auditService)
+	app.background = append(app.background, chatService.Run)
+
+	undoService := undo.NewService(app.Kv)
+	undoService.Handle(chat.UndoDeleteMessage, chatService.RestoreMessage)
+
+	calendarService := calendar.NewService(db, usersService, config.PublicUrl, config.FrontendUrl)
+	contributionsService := contributions.NewService(db, projectsService, githubClient)
+
+	exportsService := exports.NewService(db, projectsService, contributionsService, calendarService, chatService, notificationsService)
+	app.background = append(app.background, exportsService.Run)
+
+	reportsService := reports.NewService(db)
+	// Reports are generated with Postgres only SQL, they stay pending on SQLite
+	if !database.IsSqlite(db) {
+		app.background = append(app.background, reportsService.Run)
+	}
+
+	var analyticsSink analytics.Sink
+	switch config.AnalyticsSink {
+	case "database":
+		analyticsSink = analytics.NewDatabaseSink(db)
+	case "webhook":
+		analyticsSink = analytics.NewWebhookSink(config.AnalyticsWebhookUrl, config.AnalyticsWebhookToken)
+	}
+
+	analyticsService := analytics.NewService(analyticsSink)
+	app.background = append(app.background, analyticsService.Run)
+
+	experimentsService := experiments.NewService(db)
+	app.background = append(app.background, experimentsService.Run)
+
+	retentionService := retention.NewService(db, config.Retention)
+	app.background = append(app.background, retentionService.Run)
+
+	backlogs := []alerts.Backlog{{
+		Name:      "analytics-events",
+		Threshold: config.AnalyticsQueueAlertThreshold,
+		Length: func(ctx context.Context) (int64, error) {
+			return int64(analyticsService.QueueLength()), nil
+		},
+	}}
+	if !database.IsSqlite(db) {
+		backlogs = append(backlogs, alerts.Backlog{
+			Name:      "reports",
+			Threshold: config.ReportsBacklogAlertThreshold,
+			Length:    reportsService.CountPendingReports,
+		})
+	}
+
+	alertMonitor := alerts.NewMonitor(alertDispatcher, app.Breakers, backlogs...)
+	app.background = append(app.background, alertMonitor.Run)
+
+	instance := config.Instance
+	instance.Features = append([]string{}, config.Instance.Features...)
+	instance.RegistrationMode = config.RegistrationMode
+	if config.Github.ClientId != "" {
+		instance.Enable(meta.FeatureGithubLogin)
+	}
+	if config.Google.ClientId != "" {
+		instance.Enable(meta.FeatureGoogleLogin)
+	}
+	if config.VapidPrivateKey != "" {
+		instance.Enable(meta.FeatureWebPush)
+	}
+	if len(pushProviders) > 0 {
+		instance.Enable(meta.FeatureMobilePush)
+	}
+	if embeddingProvider != nil {
+		instance.Enable(meta.FeatureSemanticSearch)
+	}
+	if analyticsSink != nil {
+		instance.Enable(meta.FeatureAnalytics)
+	}
+
+	app.Providers = []interface{}{
+		authService,
+		usersService,
+		projectsService,
+		blocklistService,
+		invitesService,
+		waitlistService,
+		identitiesService,
+		auditService,
+		impersonationService,
+		moderation.NewService(db, app.Kv, auditService),
+		portfolio.NewService(db, auditService),
+		notificationsService,
+		applicationsService,
+		searchService,
+		savedSearchesService,
+		homepageService,
+		ogImageService,
+		tags.NewService(projectsService, homepageService, savedSearchesService, auditService),
+		app.Breakers,
+		auth.GatewayKey(config.GatewayApiKey),
+		capture.NewService(app.Kv, config.DebugCapture, config.RedactedFields),
+		diagnostics.NewService(config.DiagnosticsDir),
+		emailSender,
+		emailTemplates,
+		webpushService,
+		mobilepushService,
+		integrationsService,
+		calendarService,
+		contributionsService,
+		readmeService,
+		cdnService,
+		collections.NewService(db, cdnService),
+		reportsService,
+		broadcastsService,
+		usageService,
+		presence.NewService(app.Kv),
+		chatService,
+		undoService,
+		exportsService,
+		sockpuppets.NewService(db, app.Kv, auditService, config.SockpuppetHashKey),
+		analyticsService,
+		experimentsService,
+		retentionService,
+		encryptionService,
+		&instance,
+	}
+
+	startupReport := selfcheck.Run(context.Background(), app.startupChecks(encryptionService)...)
+	if err = startupReport.Err(); err != nil {
+		alert := alerts.Alert{
+			Key:      "startup-checks-failed",
+			Severity: alerts.SeverityCritical,
+			Summary:  err.Error(),
+		}
+
+		if config.StartupCheckPolicy != StartupCheckDegraded {
+			alertErr := alertDispatcher.Send(context.Background(), alert)
+			if alertErr != nil {
+				log.WithError(alertErr).Error("Failed to send alert")
+			}
+
+			return nil, err
+		}
+
+		log.Warn("Serving read-only because startup checks failed")
+		startupReport.Degraded = true
+		alertDispatcher.Alert(context.Background(), alert)
+	}
+
+	app.Providers = append(app.Providers, startupReport)
+
+	app.Router = router.SetupRoutes(app.Providers)
+
+	return app, nil
+}
+
+// Start the application's background work, which runs until ctx is done.
+func (a *App) Start(ctx context.Context) {
+	for _, run := range a.background {
+		go run(ctx)
+	}
+}
+
+// Create the email sender of the configured provider. Senders of remote
+// providers are guarded by a circuit breaker.
+func (a *App) newEmailSender() (email.Sender, error) {
+	var sender email.Sender
+
+	switch a.Config.EmailProvider {
+	case "log":
+		return email.NewLogSender(), nil
+	case "smtp":
+		sender = email.NewSmtpSender(
+			a.Config.Smtp.Host,
+			a.Config.Smtp.Port,
+			a.Config.Smtp.Username,
+			func() string { return a.Config.Secrets.Get("SMTP_PASSWORD") },
+			a.Config.EmailFrom,
+		)
+	case "sendgrid":
+		sender = email.NewSendgridSender(a.Config.SendgridApiKey, a.Config.EmailFrom)
+	case "ses":
+		sender = email.NewSesSender(
+			a.Config.Ses.Region,
+			a.Config.Ses.AccessKeyId,
+			a.Config.Ses.SecretAccessKey,
+			a.Config.EmailFrom,
+		)
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_PROVIDER %q", a.Config.EmailProvider)
+	}
+
+	return email.NewGuardedSender(sender, a.breaker("email")), nil
+}
+
+func (a *App) breaker(name string) *breaker.Breaker {
+	return a.Breakers.Breaker(name, a.Config.BreakerFailureThreshold, a.Config.BreakerCooldown)
+}
@@ -0,0 +1,127 @@
+package app_test
+
+import (
+	"github.com/open-collaboration/server/app"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/testsupport"
+	"github.com/open-collaboration/server/users"
+	"net/http"
+	"net/url"
+	"sort"
+	"testing"
+)
+
+// Start a server wired like the real one, with sessions in Redis.
+func newServer(t *testing.T) (*testsupport.Env, *testsupport.Server) {
+	env := testsupport.NewEnv(t)
+
+	config := app.Config{
+		KeyValueStore:         "redis",
+		FrontendUrl:           "http://localhost:3000",
+		PublicUrl:             "http://localhost:3001",
+		EmailProvider:         "log",
+		RegistrationMode:      users.RegistrationModeOpen,
+		EnumerationProtection: users.EnumerationProtectionOff,
+		SessionCacheSize:      100,
+		AnalyticsSink:         "none",
+		EmbeddingsProvider:    "none",
+		StartupCheckPolicy:    app.StartupCheckRefuse,
+	}
+
+	a, err := app.Wire(config, env.Db, env.Redis)
+	if err != nil {
+		t.Fatalf("failed to wire the app: %v", err)
+	}
+
+	return env, env.NewServer(t, a.Providers...)
+}
+
+func TestLoginSession(t *testing.T) {
+	env, server := newServer(t)
+	alice := env.CreateUser(t, "alice", "password", users.RoleUser)
+
+	response := server.Do(t, "GET", "/auth/session", nil, nil)
+	testsupport.ExpectStatus(t, response, http.StatusUnauthorized)
+
+	response = server.Do(t, "POST", "/auth/login", map[string]string{
+		"usernameOrEmail": "alice",
+		"password":        "wrong password",
+	}, nil)
+	testsupport.ExpectStatus(t, response, http.StatusUnauthorized)
+
+	server.Login(t, "alice", "password")
+
+	session := struct {
+		UserId uint `json:"userId"`
+		User   struct {
+			Username string `json:"username"`
+		} `json:"user"`
+	}{}
+	response = server.Do(t, "GET", "/auth/session", nil, &session)
+	testsupport.ExpectStatus(t, response, http.StatusOK)
+
+	if session.UserId != alice.ID || session.User.Username != "alice" {
+		t.Errorf("expected alice's session, got user %d (%q)", session.UserId, session.User.Username)
+	}
+
+	serverUrl, err := url.Parse(server.Url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cookies := server.Client.Jar.Cookies(serverUrl)
+
+	response = server.Do(t, "POST", "/auth/logout", nil, nil)
+	testsupport.ExpectStatus(t, response, http.StatusNoContent)
+
+	// The session must be invalidated, not only its cookie cleared
+	server.Client.Jar.SetCookies(serverUrl, cookies)
+
+	response = server.Do(t, "GET", "/auth/session", nil, nil)
+	testsupport.ExpectStatus(t, response, http.StatusUnauthorized)
+}
+
+func TestListProjectsByTags(t *testing.T) {
+	env, server := newServer(t)
+	alice := env.CreateUser(t, "alice", "password", users.RoleUser)
+
+	env.CreateProject(t, alice.ID, "Go web", "go", "web")
+	env.CreateProject(t, alice.ID, "Go cli", "go", "cli")
+	env.CreateProject(t, alice.ID, "Rust web", "rust", "web")
+	env.CreateProject(t, alice.ID, "Untagged")
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"Go cli", "Go web", "Rust web", "Untagged"}},
+		{"?tags=go", []string{"Go cli", "Go web"}},
+		{"?tags=web", []string{"Go web", "Rust web"}},
+		{"?tags=cli,rust", []string{"Go cli", "Rust web"}},
+		{"?tags=cli&tags=rust", []string{"Go cli", "Rust web"}},
+		{"?tags=python", []string{}},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.query, func(t *testing.T) {
+			var summaries []projects.ProjectSummaryDto
+			response := server.Do(t, "GET", "/projects"+test.query, nil, &summaries)
+			testsupport.ExpectStatus(t, response, http.StatusOK)
+
+			names := []string{}
+			for _, summary := range summaries {
+				names = append(names, summary.Name)
+			}
+			sort.Strings(names)
+
+			if len(names) != len(test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, names)
+			}
+			for i := range names {
+				if names[i] != test.expected[i] {
+					t.Fatalf("expected %v, got %v", test.expected, names)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,490 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/alerts"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/encryption"
+	"github.com/open-collaboration/server/meta"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/retention"
+	"github.com/open-collaboration/server/secrets"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"os"
+	"strings"
+	"time"
+)
+
+// The application's configuration. See LoadConfig for the environment
+// variables it's read from.
+type Config struct {
+	Host string
+	Port string
+
+	// Address of the admin listener, e.g. "127.0.0.1:3002". If it's set the
+	// admin routes are only served there, see router.SplitAdminRoutes.
+	AdminAddr string
+
+	PostgresDsn string
+
+	// The database of DATABASE_URL or `serve --db`, replacing the PG_*
+	// variables: a postgres:// URL, or a sqlite:// URL for development and
+	// small installs (see the database package). Optional.
+	DatabaseUrl string
+
+	// Where sessions, caches and rate limits are stored: "redis" or
+	// "postgres", for installs with a single database
+	KeyValueStore string
+
+	// Only set if KeyValueStore is "redis"
+	RedisAddr string
+
+	// Prepended to Redis keys and channels, e.g. "opencollab:", so that
+	// installs can share a Redis database. Keys are also namespaced by schema
+	// version, see kv.KeySchemaVersion.
+	RedisKeyPrefix string
+
+	// Secrets loaded from SECRETS_PROVIDER, nil if they're read from the
+	// environment only. The Postgres, Redis and SMTP credentials are read from
+	// it on every connection, so that rotated secrets apply without a restart.
+	Secrets *secrets.Store
+
+	FrontendUrl string
+
+	// The API's public base URL, used to build links to the API itself
+	PublicUrl string
+
+	// The instance's branding and feature flags, served at /meta. Its
+	// registration mode and the features derived from the configuration are
+	// set by Wire.
+	Instance meta.Instance
+
+	BreakerFailureThreshold int
+	BreakerCooldown         time.Duration
+
+	// "log", "smtp", "sendgrid" or "ses"
+	EmailProvider  string
+	EmailFrom      string
+	Smtp           SmtpConfig
+	SendgridApiKey string
+	Ses            SesConfig
+
+	// Directory of files overriding the embedded email templates, optional
+	EmailTemplatesDir string
+
+	RegistrationMode      users.RegistrationMode
+	EnumerationProtection users.EnumerationProtection
+	InviteQuota           int
+
+	SessionCacheSize int
+	SessionCacheTtl  time.Duration
+
+	// OAuth providers whose client id is empty are disabled.
+	Github OAuthConfig
+	Google OAuthConfig
+
+	// Token for the GitHub API calls of contribution and README imports, optional
+	GithubApiToken string
+
+	// How often READMEs synced as project descriptions are imported again, 0
+	// to only import them when their owners ask to
+	ReadmeRefreshInterval time.Duration
+
+	// Business rules of created and updated projects
+	ProjectRules projects.Rules
+
+	ImpersonationDuration time.Duration
+
+	// Keys encrypting sensitive columns, by version. Values are stored in
+	// plaintext if there are none.
+	EncryptionKeys map[int][]byte
+
+	// Web push is disabled if the private key is empty
+	VapidPrivateKey string
+	VapidSubject    string
+
+	// Mobile push providers are disabled if their key file is empty
+	FcmCredentialsFile string
+	Apns               ApnsConfig
+
+	// "none" or "openai"
+	EmbeddingsProvider string
+	Embeddings         EmbeddingsConfig
+
+	ApplicationSpamWindow            time.Duration
+	ApplicationSpamFlagThreshold     int
+	ApplicationSpamThrottleThreshold int
+
+	// How long applications stay pending before they expire, 0 if they don't
+	ApplicationExpiry time.Duration
+
+	// How many users admins' broadcasts are delivered to per minute
+	BroadcastRateLimit int
+
+	// How many requests users can make per day with their sessions, 0 for
+	// unlimited
+	UsageDailyQuota int
+
+	GatewayApiKey string
+
+	// Key IP addresses, device fingerprints and emails are hashed with to
+	// detect duplicate accounts, optional
+	SockpuppetHashKey string
+
+	// Webhooks purging CDN caches, optional
+	CdnPurgeWebhookUrls []string
+	CdnPurgeToken       string
+
+	// "database", "webhook" or "none"
+	AnalyticsSink         string
+	AnalyticsWebhookUrl   string
+	AnalyticsWebhookToken string
+
+	// How long rows are kept, 0 keeps them forever
+	Retention retention.Policies
+
+	DebugCapture   bool
+	RedactedFields []string
+
+	// Where diagnostics snapshots are written
+	DiagnosticsDir string
+
+	// PNG drawn under the text of projects' Open Graph images, optional
+	OgImageTemplate string
+
+	// Operational alerts, only logged if there's no webhook
+	Alerts alerts.Config
+
+	// Alert when more analytics events or reports than this are waiting
+	AnalyticsQueueAlertThreshold int64
+	ReportsBacklogAlertThreshold int64
+
+	// What happens when startup checks fail, see App.startupChecks
+	StartupCheckPolicy StartupCheckPolicy
+}
+
+// SMTP_PASSWORD is read for every email, see Config.Secrets.
+type SmtpConfig struct {
+	Host     string
+	Port     string
+	Username string
+}
+
+type SesConfig struct {
+	Region          string
+	AccessKeyId     string
+	SecretAccessKey string
+}
+
+type ApnsConfig struct {
+	KeyFile string
+	KeyId   string
+	TeamId  string
+
+	// The app's bundle id
+	Topic string
+
+	// Send to development builds of the app
+	Sandbox bool
+}
+
+type OAuthConfig struct {
+	ClientId     string
+	ClientSecret string
+}
+
+type EmbeddingsConfig struct {
+	Url    string
+	ApiKey string
+	Model  string
+}
+
+// Read the configuration from environment variables, after loading the
+// secrets of SECRETS_PROVIDER into them. Panics if a required variable is
+// missing or the secrets can't be loaded.
+func LoadConfig() Config {
+	secretStore := loadSecrets()
+
+	config := Config{
+		Host: utils.GetEnvOrPanic("HOST"),
+		Port: utils.GetEnvOrPanic("PORT"),
+
+		AdminAddr: os.Getenv("ADMIN_ADDR"),
+
+		PostgresDsn:   postgresDsn(secretStore),
+		DatabaseUrl:   os.Getenv("DATABASE_URL"),
+		Secrets:       secretStore,
+		KeyValueStore: utils.GetEnvOrDefault("KEY_VALUE_STORE", "redis"),
+
+		FrontendUrl: utils.GetEnvOrDefault("FRONTEND_URL", ""),
+		PublicUrl:   os.Getenv("PUBLIC_URL"),
+
+		Instance: meta.Instance{
+			Name:       utils.GetEnvOrDefault("INSTANCE_NAME", "Open Collaboration"),
+			LogoUrl:    os.Getenv("INSTANCE_LOGO_URL"),
+			Features:   strings.FieldsFunc(os.Getenv("INSTANCE_FEATURES"), func(r rune) bool { return r == ',' }),
+			TosVersion: os.Getenv("TOS_VERSION"),
+		},
+
+		BreakerFailureThreshold: utils.GetIntEnvOrDefault("BREAKER_FAILURE_THRESHOLD", 5),
+		BreakerCooldown:         time.Duration(utils.GetIntEnvOrDefault("BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+
+		EmailProvider:     utils.GetEnvOrDefault("EMAIL_PROVIDER", "log"),
+		EmailTemplatesDir: os.Getenv("EMAIL_TEMPLATES_DIR"),
+
+		RegistrationMode:      users.RegistrationMode(utils.GetEnvOrDefault("REGISTRATION_MODE", string(users.RegistrationModeOpen))),
+		EnumerationProtection: users.EnumerationProtection(utils.GetEnvOrDefault("ENUMERATION_PROTECTION", string(users.EnumerationProtectionLogin))),
+		InviteQuota:           utils.GetIntEnvOrDefault("INVITE_QUOTA", 5),
+
+		SessionCacheSize: utils.GetIntEnvOrDefault("SESSION_CACHE_SIZE", 10000),
+		SessionCacheTtl:  time.Duration(utils.GetIntEnvOrDefault("SESSION_CACHE_TTL_SECONDS", 10)) * time.Second,
+
+		Github: OAuthConfig{ClientId: os.Getenv("GITHUB_CLIENT_ID")},
+		Google: OAuthConfig{ClientId: os.Getenv("GOOGLE_CLIENT_ID")},
+
+		GithubApiToken: os.Getenv("GITHUB_API_TOKEN"),
+
+		ReadmeRefreshInterval: time.Duration(utils.GetIntEnvOrDefault("README_REFRESH_HOURS", 6)) * time.Hour,
+
+		ProjectRules: projects.Rules{
+			BannedNameWords:   strings.FieldsFunc(os.Getenv("PROJECT_NAME_BANNED_WORDS"), func(r rune) bool { return r == ',' }),
+			GithubHosts:       strings.Split(utils.GetEnvOrDefault("PROJECT_GITHUB_HOSTS", strings.Join(projects.DefaultGithubHosts, ",")), ","),
+			DescriptionLimits: projects.DefaultDescriptionLimits,
+		},
+
+		ImpersonationDuration: time.Duration(utils.GetIntEnvOrDefault("IMPERSONATION_DURATION_MINUTES", 30)) * time.Minute,
+
+		VapidPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
+
+		FcmCredentialsFile: os.Getenv("FCM_CREDENTIALS_FILE"),
+		Apns:               ApnsConfig{KeyFile: os.Getenv("APNS_KEY_FILE")},
+
+		EmbeddingsProvider: utils.GetEnvOrDefault("EMBEDDINGS_PROVIDER", "none"),
+
+		ApplicationSpamWindow:            time.Duration(utils.GetIntEnvOrDefault("APPLICATION_SPAM_WINDOW_HOURS", 24)) * time.Hour,
+		ApplicationSpamFlagThreshold:     utils.GetIntEnvOrDefault("APPLICATION_SPAM_FLAG_THRESHOLD", 5),
+		ApplicationSpamThrottleThreshold: utils.GetIntEnvOrDefault("APPLICATION_SPAM_THROTTLE_THRESHOLD", 15),
+
+		ApplicationExpiry: time.Duration(utils.GetIntEnvOrDefault("APPLICATION_EXPIRY_DAYS", 14)) * 24 * time.Hour,
+
+		BroadcastRateLimit: utils.GetIntEnvOrDefault("BROADCAST_RATE_PER_MINUTE", 300),
+
+		UsageDailyQuota: utils.GetIntEnvOrDefault("USAGE_DAILY_QUOTA", 0),
+
+		GatewayApiKey: os.Getenv("GATEWAY_API_KEY"),
+
+		SockpuppetHashKey: os.Getenv("SOCKPUPPET_HASH_KEY"),
+
+		CdnPurgeWebhookUrls: strings.FieldsFunc(os.Getenv("CDN_PURGE_WEBHOOK_URLS"), func(r rune) bool { return r == ',' }),
+		CdnPurgeToken:       os.Getenv("CDN_PURGE_TOKEN"),
+
+		AnalyticsSink: utils.GetEnvOrDefault("ANALYTICS_SINK", "database"),
+
+		Retention: retention.Policies{
+			AuditLogDays:         utils.GetIntEnvOrDefault("RETENTION_AUDIT_LOG_DAYS", 0),
+			AnalyticsEventsDays:  utils.GetIntEnvOrDefault("RETENTION_ANALYTICS_EVENTS_DAYS", 395),
+			NotificationsDays:    utils.GetIntEnvOrDefault("RETENTION_NOTIFICATIONS_DAYS", 180),
+			AccountSightingsDays: utils.GetIntEnvOrDefault("RETENTION_ACCOUNT_SIGHTINGS_DAYS", 90),
+			SoftDeletedDays:      utils.GetIntEnvOrDefault("RETENTION_SOFT_DELETED_DAYS", 90),
+		},
+
+		DebugCapture:   utils.GetEnvOrDefault("DEBUG_CAPTURE", "disabled") == "enabled",
+		RedactedFields: strings.Split(utils.GetEnvOrDefault("LOG_REDACT_FIELDS", utils.DefaultRedactedFields), ","),
+
+		DiagnosticsDir: utils.GetEnvOrDefault("DIAGNOSTICS_DIR", "debug-snapshots"),
+
+		OgImageTemplate: os.Getenv("OG_IMAGE_TEMPLATE"),
+
+		Alerts: alerts.Config{
+			WebhookUrl:               os.Getenv("OPS_ALERT_WEBHOOK_URL"),
+			Format:                   alerts.Format(utils.GetEnvOrDefault("OPS_ALERT_FORMAT", string(alerts.FormatSlack))),
+			Cooldown:                 time.Duration(utils.GetIntEnvOrDefault("OPS_ALERT_COOLDOWN_MINUTES", 15)) * time.Minute,
+			DatabaseFailureThreshold: utils.GetIntEnvOrDefault("OPS_ALERT_DB_FAILURE_THRESHOLD", 10),
+			DatabaseFailureWindow:    time.Duration(utils.GetIntEnvOrDefault("OPS_ALERT_DB_FAILURE_WINDOW_SECONDS", 60)) * time.Second,
+			CheckInterval:            time.Duration(utils.GetIntEnvOrDefault("OPS_ALERT_CHECK_INTERVAL_SECONDS", 60)) * time.Second,
+		},
+
+		AnalyticsQueueAlertThreshold: int64(utils.GetIntEnvOrDefault("OPS_ALERT_ANALYTICS_QUEUE_THRESHOLD", 8000)),
+		ReportsBacklogAlertThreshold: int64(utils.GetIntEnvOrDefault("OPS_ALERT_REPORTS_BACKLOG_THRESHOLD", 20)),
+
+		StartupCheckPolicy: StartupCheckPolicy(utils.GetEnvOrDefault("STARTUP_CHECK_POLICY", string(StartupCheckRefuse))),
+	}
+
+	encryptionKeys, err := encryption.ParseKeys(os.Getenv("ENCRYPTION_KEYS"))
+	if err != nil {
+		log.WithError(err).Error("Failed to parse ENCRYPTION_KEYS")
+		panic("Failed to parse ENCRYPTION_KEYS")
+	}
+
+	config.EncryptionKeys = encryptionKeys
+
+	err = config.SetDatabaseUrl(config.DatabaseUrl)
+	if err != nil {
+		panic(err)
+	}
+
+	switch config.KeyValueStore {
+	case "redis":
+		config.RedisAddr = fmt.Sprintf("%s:%s", utils.GetEnvOrPanic("REDIS_HOST"), utils.GetEnvOrPanic("REDIS_PORT"))
+		config.RedisKeyPrefix = os.Getenv("REDIS_KEY_PREFIX")
+	case "postgres":
+	default:
+		panic(fmt.Sprintf("unknown KEY_VALUE_STORE %q", config.KeyValueStore))
+	}
+
+	if config.PublicUrl == "" {
+		config.PublicUrl = fmt.Sprintf("http://%s:%s", config.Host, config.Port)
+	}
+
+	switch config.EmailProvider {
+	case "smtp":
+		config.Smtp = SmtpConfig{
+			Host:     utils.GetEnvOrPanic("SMTP_HOST"),
+			Port:     utils.GetEnvOrPanic("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+		}
+	case "sendgrid":
+		config.SendgridApiKey = utils.GetEnvOrPanic("SENDGRID_API_KEY")
+	case "ses":
+		config.Ses = SesConfig{
+			Region:          utils.GetEnvOrPanic("SES_REGION"),
+			AccessKeyId:     utils.GetEnvOrPanic("SES_ACCESS_KEY_ID"),
+			SecretAccessKey: utils.GetEnvOrPanic("SES_SECRET_ACCESS_KEY"),
+		}
+	}
+
+	if config.EmailProvider != "log" {
+		config.EmailFrom = utils.GetEnvOrPanic("EMAIL_FROM")
+	}
+
+	if config.Github.ClientId != "" {
+		config.Github.ClientSecret = utils.GetEnvOrPanic("GITHUB_CLIENT_SECRET")
+	}
+	if config.Google.ClientId != "" {
+		config.Google.ClientSecret = utils.GetEnvOrPanic("GOOGLE_CLIENT_SECRET")
+	}
+
+	if config.VapidPrivateKey != "" {
+		config.VapidSubject = utils.GetEnvOrPanic("VAPID_SUBJECT")
+	}
+
+	if config.Apns.KeyFile != "" {
+		config.Apns.KeyId = utils.GetEnvOrPanic("APNS_KEY_ID")
+		config.Apns.TeamId = utils.GetEnvOrPanic("APNS_TEAM_ID")
+		config.Apns.Topic = utils.GetEnvOrPanic("APNS_TOPIC")
+		config.Apns.Sandbox = utils.GetEnvOrDefault("APNS_ENVIRONMENT", "production") == "sandbox"
+	}
+
+	if config.EmbeddingsProvider == "openai" {
+		config.Embeddings = EmbeddingsConfig{
+			Url:    utils.GetEnvOrDefault("EMBEDDINGS_URL", "https://api.openai.com/v1/embeddings"),
+			ApiKey: utils.GetEnvOrPanic("EMBEDDINGS_API_KEY"),
+			Model:  utils.GetEnvOrDefault("EMBEDDINGS_MODEL", "text-embedding-3-small"),
+		}
+	}
+
+	if config.AnalyticsSink == "webhook" {
+		config.AnalyticsWebhookUrl = utils.GetEnvOrPanic("ANALYTICS_WEBHOOK_URL")
+		config.AnalyticsWebhookToken = os.Getenv("ANALYTICS_WEBHOOK_TOKEN")
+	}
+
+	switch config.Alerts.Format {
+	case alerts.FormatSlack:
+	case alerts.FormatPagerDuty:
+		if config.Alerts.WebhookUrl != "" {
+			config.Alerts.RoutingKey = utils.GetEnvOrPanic("OPS_ALERT_ROUTING_KEY")
+		}
+	default:
+		panic(fmt.Sprintf("unknown OPS_ALERT_FORMAT %q", config.Alerts.Format))
+	}
+
+	switch config.StartupCheckPolicy {
+	case StartupCheckRefuse, StartupCheckDegraded:
+	default:
+		panic(fmt.Sprintf("unknown STARTUP_CHECK_POLICY %q", config.StartupCheckPolicy))
+	}
+
+	return config
+}
+
+// Connect to the database of a URL instead of the PG_* variables, see
+// DatabaseUrl. An empty url keeps the PG_* variables.
+func (c *Config) SetDatabaseUrl(url string) error {
+	_, isSqlite := database.SqlitePath(url)
+	isPostgres := strings.HasPrefix(url, "postgres://") || strings.HasPrefix(url, "postgresql://")
+
+	if url != "" && !isSqlite && !isPostgres {
+		return fmt.Errorf("unsupported database url %q, expected a postgres:// or sqlite:// url", url)
+	}
+
+	// The kv store relies on Postgres, e.g. LISTEN/NOTIFY
+	if isSqlite && c.KeyValueStore == "postgres" {
+		return errors.New("KEY_VALUE_STORE=postgres requires a Postgres database")
+	}
+
+	c.DatabaseUrl = url
+
+	// pg_dump and pg_restore accept URLs too
+	if isPostgres {
+		c.PostgresDsn = url
+	}
+
+	return nil
+}
+
+// Load the secrets of SECRETS_PROVIDER into the environment. Returns nil if
+// SECRETS_PROVIDER is "env", i.e. everything is read from the environment.
+func loadSecrets() *secrets.Store {
+	var provider secrets.Provider
+
+	switch name := utils.GetEnvOrDefault("SECRETS_PROVIDER", "env"); name {
+	case "env":
+		return nil
+	case "vault":
+		provider = secrets.NewVaultProvider(
+			utils.GetEnvOrPanic("VAULT_ADDR"),
+			utils.GetEnvOrPanic("VAULT_TOKEN"),
+			utils.GetEnvOrPanic("VAULT_SECRET_PATH"),
+		)
+	case "aws":
+		provider = secrets.NewAwsProvider(
+			utils.GetEnvOrPanic("AWS_REGION"),
+			utils.AwsCredentials{
+				AccessKeyId:     utils.GetEnvOrPanic("AWS_ACCESS_KEY_ID"),
+				SecretAccessKey: utils.GetEnvOrPanic("AWS_SECRET_ACCESS_KEY"),
+				SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			},
+			utils.GetEnvOrPanic("AWS_SECRET_ID"),
+		)
+	case "gcp":
+		provider = secrets.NewGcpProvider(utils.GetEnvOrPanic("GCP_SECRET_VERSION"))
+	default:
+		panic(fmt.Sprintf("unknown SECRETS_PROVIDER %q", name))
+	}
+
+	store := secrets.NewStore(provider, time.Duration(utils.GetIntEnvOrDefault("SECRETS_REFRESH_SECONDS", 300))*time.Second)
+
+	err := store.Load(context.Background())
+	if err != nil {
+		log.WithError(err).WithField("provider", provider.Name()).Error("Failed to load secrets")
+		panic("Failed to load secrets")
+	}
+
+	return store
+}
+
+// The libpq connection string of the database, with the current secrets.
+func postgresDsn(secretStore *secrets.Store) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+		secretStore.Get("PG_HOST"),
+		secretStore.Get("PG_PORT"),
+		secretStore.Get("PG_USER"),
+		secretStore.Get("PG_PASSWORD"),
+		secretStore.Get("PG_DB_NAME"),
+	)
+}
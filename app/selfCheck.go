@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/encryption"
+	"github.com/open-collaboration/server/migrations"
+	"github.com/open-collaboration/server/selfcheck"
+	"github.com/open-collaboration/server/users"
+	"net/url"
+)
+
+// What happens when startup checks fail.
+type StartupCheckPolicy string
+
+const (
+	// Fail to start
+	StartupCheckRefuse StartupCheckPolicy = "refuse"
+
+	// Serve read-only, see selfcheck.DegradedMiddleware
+	StartupCheckDegraded StartupCheckPolicy = "degraded"
+)
+
+// Columns encrypted by the encryption service.
+var EncryptedColumns = []encryption.Column{
+	{Table: "project_integrations", Name: "webhook_url"},
+	{Table: "push_subscriptions", Name: "p256dh"},
+	{Table: "push_subscriptions", Name: "auth"},
+}
+
+// The checks run by Wire before serving.
+func (a *App) startupChecks(encryptionService encryption.Service) []selfcheck.Check {
+	checks := []selfcheck.Check{
+		{
+			Name: "schema",
+			Run: func(ctx context.Context) error {
+				return migrations.CheckSchema(ctx, a.Db)
+			},
+		},
+		{
+			Name: "config",
+			Run: func(ctx context.Context) error {
+				return a.Config.Validate()
+			},
+		},
+		{
+			Name: "encryption-keys",
+			Run: func(ctx context.Context) error {
+				return encryption.CheckKeys(ctx, a.Db, encryptionService, EncryptedColumns)
+			},
+		},
+	}
+
+	if a.Redis != nil {
+		checks = append(checks, selfcheck.Check{
+			Name: "redis",
+			Run: func(ctx context.Context) error {
+				err := a.Redis.Ping(ctx).Err()
+				if err != nil {
+					// The error has Redis' address
+					log.FromContext(ctx).WithError(err).Error("Redis is unreachable")
+
+					return errors.New("redis is unreachable")
+				}
+
+				return nil
+			},
+		})
+	}
+
+	return checks
+}
+
+// Check the values LoadConfig doesn't, e.g. that URLs are valid. Returns the
+// first invalid value.
+func (c Config) Validate() error {
+	for name, value := range map[string]string{
+		"FRONTEND_URL":      c.FrontendUrl,
+		"PUBLIC_URL":        c.PublicUrl,
+		"INSTANCE_LOGO_URL": c.Instance.LogoUrl,
+	} {
+		if value == "" {
+			continue
+		}
+
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%s isn't an absolute http(s) URL", name)
+		}
+	}
+
+	switch c.RegistrationMode {
+	case users.RegistrationModeOpen, users.RegistrationModeInviteOnly, users.RegistrationModeWaitlist:
+	default:
+		return fmt.Errorf("unknown REGISTRATION_MODE %q", c.RegistrationMode)
+	}
+
+	switch c.EnumerationProtection {
+	case users.EnumerationProtectionOff, users.EnumerationProtectionLogin, users.EnumerationProtectionStrict:
+	default:
+		return fmt.Errorf("unknown ENUMERATION_PROTECTION %q", c.EnumerationProtection)
+	}
+
+	switch c.AnalyticsSink {
+	case "database", "webhook", "none":
+	default:
+		return fmt.Errorf("unknown ANALYTICS_SINK %q", c.AnalyticsSink)
+	}
+
+	switch c.EmbeddingsProvider {
+	case "none", "openai":
+	default:
+		return fmt.Errorf("unknown EMBEDDINGS_PROVIDER %q", c.EmbeddingsProvider)
+	}
+
+	return nil
+}
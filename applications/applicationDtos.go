@@ -0,0 +1,102 @@
+package applications
+
+import "time"
+
+type NewApplicationDto struct {
+	Message string `json:"message" validate:"required,min=20,max=5000"`
+
+	// The role applied to, one of the project's roles. Required if the
+	// project has roles, left out for projects without roles.
+	RoleId uint `json:"roleId"`
+
+	// Answers to the role's screening questions, or to the project's if it
+	// has no roles. Required questions must be answered.
+	Answers []NewAnswerDto `json:"answers" validate:"max=10,dive"`
+}
+
+type NewAnswerDto struct {
+	QuestionId uint   `json:"questionId" validate:"required"`
+	Answer     string `json:"answer" validate:"max=2000"`
+}
+
+type AnswerDto struct {
+	QuestionId uint   `json:"questionId"`
+	Question   string `json:"question"`
+	Answer     string `json:"answer"`
+}
+
+type ReviewApplicationDto struct {
+	Status Status `json:"status" validate:"required,oneof=accepted rejected"`
+}
+
+type ApplicationDto struct {
+	Id          uint        `json:"id"`
+	ProjectId   uint        `json:"projectId"`
+	ApplicantId uint        `json:"applicantId"`
+	Message     string      `json:"message"`
+	RoleId      uint        `json:"roleId"`
+	Answers     []AnswerDto `json:"answers"`
+	Status      Status      `json:"status"`
+	CreatedAt   time.Time   `json:"createdAt"`
+
+	// When the application expires if the project's owner doesn't review it,
+	// nil if it isn't pending or doesn't expire
+	ExpiresAt *time.Time `json:"expiresAt"`
+
+	// nil if the application wasn't accepted or rejected
+	ReviewedAt *time.Time `json:"reviewedAt"`
+
+	// nil if no interview was proposed
+	Interview *InterviewDto `json:"interview"`
+}
+
+type NewInterviewDto struct {
+	// Start times the applicant can pick from
+	Slots           []time.Time `json:"slots" validate:"required,min=1,max=5"`
+	DurationMinutes int         `json:"durationMinutes" validate:"required,min=15,max=240"`
+
+	// An address or a meeting link, can be empty
+	Location string `json:"location" validate:"max=200"`
+}
+
+type ScheduleInterviewDto struct {
+	SlotId uint `json:"slotId" validate:"required"`
+}
+
+type InterviewSlotDto struct {
+	Id       uint      `json:"id"`
+	StartsAt time.Time `json:"startsAt"`
+}
+
+type InterviewDto struct {
+	Status InterviewStatus    `json:"status"`
+	Slots  []InterviewSlotDto `json:"slots"`
+
+	// The picked slot, nil until the interview is scheduled
+	StartsAt        *time.Time `json:"startsAt"`
+	DurationMinutes int        `json:"durationMinutes"`
+	Location        string     `json:"location"`
+}
+
+type FlaggedApplicationDto struct {
+	ApplicationDto
+	MessageHash string `json:"messageHash"`
+
+	// Sent by a shadow restricted user, the project doesn't see it
+	Hidden bool `json:"hidden"`
+}
+
+type ResponseTimeDto struct {
+	// Median time between applications to the owner's projects and their
+	// review, in the last 90 days. nil if too few applications were reviewed
+	// to tell.
+	MedianHours *float64 `json:"medianHours"`
+
+	// Applications reviewed and expired in the last 90 days
+	Reviewed int `json:"reviewed"`
+	Expired  int `json:"expired"`
+
+	// Days after which applications that weren't reviewed expire, 0 if they
+	// don't expire
+	ExpiryDays int `json:"expiryDays"`
+}
@@ -0,0 +1,179 @@
+package applications
+
+import (
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/metrics"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/projects"
+	"math"
+	"sort"
+	"time"
+)
+
+// How often pending applications are checked for expiry.
+const expiryInterval = 15 * time.Minute
+
+// At most this many applications are expired per check, the rest are left
+// for the next one.
+const expiryBatchSize = 500
+
+// Applications reviewed or expired in this window count towards an owner's
+// response time.
+const responseTimeWindow = 90 * 24 * time.Hour
+
+// The median response time is only computed from at least this many reviews.
+const minResponseTimeReviews = 3
+
+var expiredCounter = metrics.NewCounter(
+	"opencollab_applications_expired_total",
+	"Applications that expired before the projects' owners reviewed them.",
+)
+
+func (s *serviceImpl) ExpireApplications(ctx context.Context) error {
+	if s.Expiry <= 0 {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+	now := time.Now()
+
+	// Applications sent while they didn't expire have no expiry, they expire
+	// as if they had
+	var due []Application
+	result := s.Db.WithContext(ctx).
+		Where("status = ?", StatusPending).
+		Where("expires_at <= ? OR (expires_at IS NULL AND created_at <= ?)", now, now.Add(-s.Expiry)).
+		Where("interview_status NOT IN ?", []InterviewStatus{InterviewProposed, InterviewScheduled}).
+		Order("created_at").
+		Limit(expiryBatchSize).
+		Find(&due)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list applications to expire")
+
+		return result.Error
+	}
+
+	expired := 0
+	for _, application := range due {
+		// Only the instance that expires the application notifies
+		result = s.Db.WithContext(ctx).
+			Model(&Application{}).
+			Where("id = ? AND status = ?", application.ID, StatusPending).
+			Update("status", StatusExpired)
+
+		if result.Error != nil {
+			logger.WithError(result.Error).WithField("applicationId", application.ID).Error("Failed to expire application")
+
+			continue
+		}
+
+		if result.RowsAffected < 1 {
+			continue
+		}
+
+		expired++
+		expiredCounter.Inc()
+
+		project, err := s.ProjectsService.GetProject(ctx, application.ProjectId)
+		if err != nil {
+			logger.WithError(err).WithField("applicationId", application.ID).Error("Failed to get project of expired application")
+
+			continue
+		}
+
+		s.notifyExpired(ctx, application, project, application.ApplicantId, fmt.Sprintf(
+			"%s didn't review your application in time. You can apply again.",
+			project.Name,
+		))
+		s.notifyExpired(ctx, application, project, project.OwnerId, fmt.Sprintf(
+			"An application to %s expired because it wasn't reviewed within %d days.",
+			project.Name,
+			s.expiryDays(),
+		))
+	}
+
+	if expired > 0 {
+		logger.WithField("count", expired).Info("Expired applications")
+	}
+
+	return nil
+}
+
+// Notify the applicant or the owner of an expired application. Failures are
+// only logged, the application has already expired.
+func (s *serviceImpl) notifyExpired(ctx context.Context, application Application, project projects.ProjectDto, userId uint, body string) {
+	err := s.NotificationsService.Notify(ctx, userId, notifications.NewNotificationDto{
+		Type:  notifications.TypeApplicationExpired,
+		Title: "Application expired",
+		Body:  body,
+		Data: map[string]interface{}{
+			"applicationId": application.ID,
+			"projectId":     project.Id,
+		},
+	})
+
+	if err != nil {
+		log.FromContext(ctx).
+			WithError(err).
+			WithFields(log.Fields{"applicationId": application.ID, "userId": userId}).
+			Error("Failed to notify of expired application")
+	}
+}
+
+func (s *serviceImpl) GetResponseTime(ctx context.Context, ownerId uint) (ResponseTimeDto, error) {
+	var applications []Application
+	result := s.Db.WithContext(ctx).
+		Select("applications.status, applications.created_at, applications.reviewed_at").
+		Joins("JOIN projects ON projects.id = applications.project_id").
+		Where("projects.owner_id = ? AND applications.hidden = ?", ownerId, false).
+		Where(
+			"(applications.status IN ? AND applications.reviewed_at > ?) OR (applications.status = ? AND applications.updated_at > ?)",
+			[]Status{StatusAccepted, StatusRejected},
+			time.Now().Add(-responseTimeWindow),
+			StatusExpired,
+			time.Now().Add(-responseTimeWindow),
+		).
+		Find(&applications)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("ownerId", ownerId).Error("Failed to list reviewed applications")
+
+		return ResponseTimeDto{}, result.Error
+	}
+
+	var responseTimes []time.Duration
+	responseTime := ResponseTimeDto{ExpiryDays: s.expiryDays()}
+
+	for _, application := range applications {
+		if application.Status == StatusExpired {
+			responseTime.Expired++
+		} else {
+			responseTimes = append(responseTimes, application.ReviewedAt.Sub(application.CreatedAt))
+		}
+	}
+
+	responseTime.Reviewed = len(responseTimes)
+
+	if len(responseTimes) >= minResponseTimeReviews {
+		sort.Slice(responseTimes, func(i, j int) bool {
+			return responseTimes[i] < responseTimes[j]
+		})
+
+		median := responseTimes[len(responseTimes)/2]
+		if len(responseTimes)%2 == 0 {
+			median = (responseTimes[len(responseTimes)/2-1] + median) / 2
+		}
+
+		hours := math.Round(median.Hours()*10) / 10
+		responseTime.MedianHours = &hours
+	}
+
+	return responseTime, nil
+}
+
+func (s *serviceImpl) expiryDays() int {
+	return int(s.Expiry / (24 * time.Hour))
+}
@@ -0,0 +1,64 @@
+package applications
+
+import (
+	"context"
+	"github.com/open-collaboration/server/metrics"
+	"gorm.io/gorm"
+	"time"
+)
+
+var submittedCounter = metrics.NewCounter(
+	"opencollab_applications_submitted_total",
+	"Applications sent to projects.",
+)
+
+var reviewedCounter = metrics.NewCounter(
+	"opencollab_applications_reviewed_total",
+	"Applications accepted or rejected by the projects' owners, by status.",
+	"status",
+)
+
+// Applications reviewed in this window count towards the acceptance ratio.
+const acceptanceRatioWindow = 30 * 24 * time.Hour
+
+// Export the share of the applications reviewed in the last
+// acceptanceRatioWindow that were accepted, 0 if none were reviewed.
+// Applications count from their last update, which is their review unless
+// an interview was scheduled after it.
+func registerAcceptanceRatio(db *gorm.DB) {
+	metrics.RegisterGauge(
+		"opencollab_application_acceptance_ratio",
+		"Share of the applications reviewed in the last 30 days that were accepted.",
+		func(ctx context.Context) (float64, error) {
+			var counts []struct {
+				Status Status
+				Count  int64
+			}
+
+			result := db.WithContext(ctx).
+				Model(&Application{}).
+				Select("status, count(*) AS count").
+				Where("status IN ? AND updated_at > ?", []Status{StatusAccepted, StatusRejected}, time.Now().Add(-acceptanceRatioWindow)).
+				Group("status").
+				Scan(&counts)
+
+			if result.Error != nil {
+				return 0, result.Error
+			}
+
+			var accepted, reviewed int64
+			for _, count := range counts {
+				if count.Status == StatusAccepted {
+					accepted = count.Count
+				}
+				reviewed += count.Count
+			}
+
+			if reviewed == 0 {
+				return 0, nil
+			}
+
+			return float64(accepted) / float64(reviewed), nil
+		},
+	)
+}
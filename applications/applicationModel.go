@@ -0,0 +1,79 @@
+package applications
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusAccepted Status = "accepted"
+	StatusRejected Status = "rejected"
+
+	// The project's owner didn't review the application in time, see
+	// ExpireApplications
+	StatusExpired Status = "expired"
+)
+
+type Application struct {
+	gorm.Model
+
+	ProjectId   uint
+	ApplicantId uint
+	Message     string
+	Status      Status
+
+	// The role applied to, 0 if the application isn't for a specific role
+	RoleId uint
+
+	// Answers to the role's screening questions
+	Answers []Answer
+
+	// Hash of the normalized message, used to detect users
+	// sending the same message to many projects.
+	MessageHash string
+
+	// Flagged by the spam heuristics for moderator review.
+	Flagged bool
+
+	// Sent by a shadow restricted user, only visible to them and to
+	// moderators, who review it like flagged applications
+	Hidden bool
+
+	// Empty until the project's owner proposes an interview, see
+	// interviews.go
+	InterviewStatus InterviewStatus
+	InterviewSlots  []InterviewSlot
+
+	// The slot the applicant picked, nil until the interview is scheduled
+	InterviewAt       *time.Time
+	InterviewMinutes  int
+	InterviewLocation string
+
+	// Whether the participants were reminded of the scheduled interview
+	InterviewReminded bool
+
+	// When the application expires if it's still pending, nil if
+	// applications didn't expire when it was sent
+	ExpiresAt *time.Time
+
+	// When the application was accepted or rejected, nil if it wasn't
+	ReviewedAt *time.Time
+}
+
+// An answer to one of a role's screening questions. The question is copied so
+// that the answer still makes sense if the project's roles are edited.
+type Answer struct {
+	gorm.Model
+
+	ApplicationId uint
+	QuestionId    uint
+	Question      string
+	Answer        string
+}
+
+func (Answer) TableName() string {
+	return "application_answers"
+}
@@ -0,0 +1,338 @@
+package applications
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Apply to a project
+// @Description Applications to projects with roles must be for one of them, and answer the role's required
+// @Description screening questions. Applications to projects without roles answer the project's questions.
+// @Tags applications
+// @Router /projects/{projectId}/applications [post]
+// @Param projectId path int true "The project ID"
+// @Param application body applications.NewApplicationDto true "The application"
+// @Success 201 {object} applications.ApplicationDto
+// @Failure 400 "Missing or unknown role, or missing or invalid answers"
+// @Failure 401
+// @Failure 404
+// @Failure 409 "The user already has a pending application to the project"
+// @Failure 429 "The user sent too many applications with the same message"
+func RouteApply(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+	accountStatusProvider auth.AccountStatusProvider,
+) error {
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewApplicationDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	application, err := applicationsService.Apply(request.Context(), session.UserId(), projectId, dto, accountStatus.ShadowHidden)
+	if err != nil {
+		return err
+	}
+
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, application)
+}
+
+// @Summary List a project's applications
+// @Description Only the project's owner and members with the manage-roles permission can list its applications.
+// @Tags applications
+// @Router /projects/{projectId}/applications [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {array} applications.ApplicationDto
+// @Failure 401
+// @Failure 403
+func RouteListProjectApplications(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageRoles)
+	if err != nil {
+		return err
+	}
+
+	applications, err := applicationsService.ListProjectApplications(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, applications)
+}
+
+// @Summary Accept or reject an application
+// @Description Only the project's owner and members with the manage-roles permission can review its applications.
+// @Tags applications
+// @Router /projects/{projectId}/applications/{applicationId}/review [post]
+// @Param projectId path int true "The project ID"
+// @Param applicationId path int true "The application ID"
+// @Param review body applications.ReviewApplicationDto true "The review"
+// @Success 204
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteReviewApplication(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageRoles)
+	if err != nil {
+		return err
+	}
+
+	applicationId, err := utils.UintFromVars(request, "applicationId")
+	if err != nil {
+		return err
+	}
+
+	dto := ReviewApplicationDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = applicationsService.ReviewApplication(request.Context(), projectId, applicationId, dto.Status)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Propose interview slots to an applicant
+// @Description Only the project's owner and members with the manage-roles permission can propose interviews.
+// @Description Proposing again replaces the previous proposal, which reschedules a scheduled interview. The
+// @Description applicant is notified.
+// @Tags applications
+// @Router /projects/{projectId}/applications/{applicationId}/interview [post]
+// @Param projectId path int true "The project ID"
+// @Param applicationId path int true "The application ID"
+// @Param interview body applications.NewInterviewDto true "The proposed slots"
+// @Success 200 {object} applications.ApplicationDto
+// @Failure 400 "A slot isn't in the future"
+// @Failure 401
+// @Failure 403
+// @Failure 404 "The project doesn't have a pending application with the given id"
+func RouteProposeInterview(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageRoles)
+	if err != nil {
+		return err
+	}
+
+	applicationId, err := utils.UintFromVars(request, "applicationId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewInterviewDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	application, err := applicationsService.ProposeInterview(request.Context(), projectId, applicationId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, application)
+}
+
+// @Summary Pick an interview slot
+// @Description Only the applicant can pick one of the proposed slots. The project's owner is notified and the
+// @Description interview is added to both of their calendar feeds.
+// @Tags applications
+// @Router /applications/{applicationId}/interview/schedule [post]
+// @Param applicationId path int true "The application ID"
+// @Param slot body applications.ScheduleInterviewDto true "The picked slot"
+// @Success 200 {object} applications.ApplicationDto
+// @Failure 400 "The slot wasn't proposed or is in the past"
+// @Failure 401
+// @Failure 404
+// @Failure 409 "No interview is proposed"
+func RouteScheduleInterview(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	applicationId, err := utils.UintFromVars(request, "applicationId")
+	if err != nil {
+		return err
+	}
+
+	dto := ScheduleInterviewDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	application, err := applicationsService.ScheduleInterview(request.Context(), session.UserId(), applicationId, dto.SlotId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, application)
+}
+
+// @Summary Cancel an interview
+// @Description Either the applicant or the project's owner can cancel a proposed or scheduled interview.
+// @Description The other one is notified.
+// @Tags applications
+// @Router /applications/{applicationId}/interview [delete]
+// @Param applicationId path int true "The application ID"
+// @Success 204
+// @Failure 401
+// @Failure 404
+// @Failure 409 "No interview is proposed or scheduled"
+func RouteCancelInterview(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	applicationId, err := utils.UintFromVars(request, "applicationId")
+	if err != nil {
+		return err
+	}
+
+	err = applicationsService.CancelInterview(request.Context(), session.UserId(), applicationId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary List the current user's applications
+// @Tags applications
+// @Router /users/me/applications [get]
+// @Success 200 {array} applications.ApplicationDto
+// @Failure 401
+func RouteListUserApplications(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	applications, err := applicationsService.ListUserApplications(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, applications)
+}
+
+// @Summary List applications flagged as spam
+// @Description Also lists the applications of shadow restricted users (hidden), which their project doesn't see.
+// @Tags moderation
+// @Router /moderation/applications/flagged [get]
+// @Param pageSize query int false "Maximum amount of applications in the response. Default is 20, max is 100."
+// @Param pageOffset query int false "Response page number."
+// @Success 200 {array} applications.FlaggedApplicationDto
+// @Failure 403
+func RouteListFlaggedApplications(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+	usersService users.Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 20)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if pageOffset < 0 {
+		pageOffset = 0
+	}
+
+	applications, err := applicationsService.ListFlaggedApplications(request.Context(), uint(pageSize), uint(pageOffset))
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, applications)
+}
+
+// @Summary Get how long a project's owner takes to review applications
+// @Description The median time the owner took to accept or reject applications to any of their projects in the
+// @Description last 90 days, so that applicants know what to expect. medianHours is null until at least 3
+// @Description applications were reviewed. Applications that aren't reviewed within expiryDays expire.
+// @Tags applications
+// @Router /projects/{projectId}/response-time [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {object} applications.ResponseTimeDto
+// @Failure 404
+func RouteGetResponseTime(
+	writer http.ResponseWriter,
+	request *http.Request,
+	applicationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	responseTime, err := applicationsService.GetResponseTime(request.Context(), project.OwnerId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, responseTime)
+}
@@ -0,0 +1,417 @@
+package applications
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/projects"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var ErrApplicationNotFound = errors.New("application not found")
+var ErrAlreadyApplied = errors.New("user already applied to the project")
+var ErrOwnProject = errors.New("users cannot apply to their own projects")
+var ErrApplicationThrottled = errors.New("too many similar applications")
+var ErrRoleNotFound = errors.New("role not found")
+var ErrInvalidAnswers = errors.New("invalid screening answers")
+var ErrRoleRequired = errors.New("the project has roles, applications must be to one of them")
+
+type Service interface {
+	// Apply to a project. The application is checked by the spam heuristics
+	// and may be flagged for moderator review. Hidden applications (of shadow
+	// restricted users) are flagged, and the project's members don't see
+	// them nor are notified.
+	// Returns projects.ErrProjectNotFound if the project doesn't exist, ErrOwnProject if
+	// the applicant owns the project, ErrAlreadyApplied if the applicant has a pending
+	// application to the project and ErrApplicationThrottled if the applicant sent
+	// too many applications with the same message recently.
+	// Returns ErrRoleRequired if the project has roles and the application
+	// isn't to one of them, ErrRoleNotFound if the project doesn't have the
+	// role and ErrInvalidAnswers if a required screening question of the role
+	// (or of the project, for projects without roles) isn't answered or an
+	// answer isn't to one of its questions.
+	Apply(ctx context.Context, applicantId uint, projectId uint, dto NewApplicationDto, hidden bool) (ApplicationDto, error)
+
+	// List a project's applications, newest to oldest, except hidden ones.
+	ListProjectApplications(ctx context.Context, projectId uint) ([]ApplicationDto, error)
+
+	// List a user's applications, newest to oldest.
+	ListUserApplications(ctx context.Context, applicantId uint) ([]ApplicationDto, error)
+
+	// Accept or reject a pending application.
+	// Returns ErrApplicationNotFound if the project doesn't have a pending
+	// application with the given id.
+	ReviewApplication(ctx context.Context, projectId uint, applicationId uint, status Status) error
+
+	// List applications flagged by the spam heuristics and hidden
+	// applications, newest to oldest.
+	ListFlaggedApplications(ctx context.Context, pageSize uint, pageOffset uint) ([]FlaggedApplicationDto, error)
+
+	// Propose interview time slots to the applicant of a pending application,
+	// replacing any previous proposal. Proposing again reschedules a scheduled
+	// or cancelled interview. The applicant is notified.
+	// Returns ErrApplicationNotFound if the project doesn't have a pending
+	// application with the given id and ErrInvalidInterviewTime if a slot
+	// isn't in the future.
+	ProposeInterview(ctx context.Context, projectId uint, applicationId uint, dto NewInterviewDto) (ApplicationDto, error)
+
+	// Schedule an application's interview at one of the proposed slots. The
+	// project's owner is notified.
+	// Returns ErrApplicationNotFound if the applicant doesn't have the
+	// application, ErrInvalidInterviewTransition if no interview is proposed,
+	// ErrSlotNotFound if the slot wasn't proposed and ErrInvalidInterviewTime
+	// if the slot is in the past.
+	ScheduleInterview(ctx context.Context, applicantId uint, applicationId uint, slotId uint) (ApplicationDto, error)
+
+	// Cancel a proposed or scheduled interview, by either the applicant or
+	// the project's owner. The other one is notified.
+	// Returns ErrApplicationNotFound if the user is neither the applicant nor
+	// the owner and ErrInvalidInterviewTransition if no interview is proposed
+	// or scheduled.
+	CancelInterview(ctx context.Context, userId uint, applicationId uint) error
+
+	// Remind the participants of interviews starting within the next hour.
+	// Each interview is reminded once.
+	SendInterviewReminders(ctx context.Context) error
+
+	// Expire the pending applications the projects' owners didn't review in
+	// time, notifying both the applicants and the owners. Applications with a
+	// proposed or scheduled interview don't expire.
+	ExpireApplications(ctx context.Context) error
+
+	// Get how long the owner takes to review applications to their projects.
+	GetResponseTime(ctx context.Context, ownerId uint) (ResponseTimeDto, error)
+
+	// Send interview reminders and expire applications until ctx is done.
+	// Should be run in its own goroutine.
+	Run(ctx context.Context)
+}
+
+// An ApplicationListener is notified after an application is created. Listeners
+// can't fail the application, so anything slow or fallible should be done in
+// the background.
+type ApplicationListener interface {
+	ApplicationCreated(ctx context.Context, project projects.ProjectDto, application ApplicationDto)
+}
+
+type serviceImpl struct {
+	Db                   *gorm.DB
+	ProjectsService      projects.Service
+	NotificationsService notifications.Service
+	SpamDetector         *spamDetector
+	Listeners            []ApplicationListener
+
+	// How long applications stay pending before they expire, 0 if they
+	// don't expire
+	Expiry time.Duration
+}
+
+func NewService(
+	db *gorm.DB,
+	kvStore kv.Store,
+	projectsService projects.Service,
+	notificationsService notifications.Service,
+	spamThresholds SpamThresholds,
+	expiry time.Duration,
+	listeners ...ApplicationListener,
+) Service {
+	registerAcceptanceRatio(db)
+
+	return &serviceImpl{
+		Db:                   db,
+		ProjectsService:      projectsService,
+		NotificationsService: notificationsService,
+		SpamDetector: &spamDetector{
+			Db:                   db,
+			Kv:                   kvStore,
+			NotificationsService: notificationsService,
+			Thresholds:           spamThresholds,
+		},
+		Listeners: listeners,
+		Expiry:    expiry,
+	}
+}
+
+func (s *serviceImpl) Apply(ctx context.Context, applicantId uint, projectId uint, dto NewApplicationDto, hidden bool) (ApplicationDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"applicantId": applicantId,
+		"projectId":   projectId,
+	})
+
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return ApplicationDto{}, err
+	}
+
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return ApplicationDto{}, err
+	}
+
+	if project.OwnerId == applicantId {
+		return ApplicationDto{}, ErrOwnProject
+	}
+
+	answers, err := checkAnswers(project, dto)
+	if err != nil {
+		return ApplicationDto{}, err
+	}
+
+	var pendingCount int64
+	result := s.Db.WithContext(ctx).
+		Model(&Application{}).
+		Where("project_id = ? AND applicant_id = ? AND status = ?", projectId, applicantId, StatusPending).
+		Count(&pendingCount)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to check for pending applications")
+
+		return ApplicationDto{}, result.Error
+	}
+
+	if pendingCount > 0 {
+		return ApplicationDto{}, ErrAlreadyApplied
+	}
+
+	messageHash := hashMessage(dto.Message)
+
+	verdict, err := s.SpamDetector.check(ctx, applicantId, messageHash)
+	if err != nil {
+		return ApplicationDto{}, err
+	}
+
+	if verdict == verdictThrottle {
+		return ApplicationDto{}, ErrApplicationThrottled
+	}
+
+	application := Application{
+		ProjectId:   projectId,
+		ApplicantId: applicantId,
+		Message:     dto.Message,
+		RoleId:      dto.RoleId,
+		Answers:     answers,
+		Status:      StatusPending,
+		MessageHash: messageHash,
+		Flagged:     verdict == verdictFlag || hidden,
+		Hidden:      hidden,
+	}
+
+	if s.Expiry > 0 {
+		expiresAt := time.Now().Add(s.Expiry)
+		application.ExpiresAt = &expiresAt
+	}
+
+	result = s.Db.WithContext(ctx).Create(&application)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create application")
+
+		return ApplicationDto{}, result.Error
+	}
+
+	logger.Debug("Application created")
+	submittedCounter.Inc()
+
+	applicationDto := applicationToDto(application)
+
+	// Nobody on the project sees hidden applications, so they aren't notified
+	if !hidden {
+		for _, listener := range s.Listeners {
+			listener.ApplicationCreated(ctx, project, applicationDto)
+		}
+	}
+
+	return applicationDto, nil
+}
+
+func (s *serviceImpl) ListProjectApplications(ctx context.Context, projectId uint) ([]ApplicationDto, error) {
+	return s.listApplications(ctx, s.Db.WithContext(ctx).Where("project_id = ? AND hidden = ?", projectId, false))
+}
+
+func (s *serviceImpl) ListUserApplications(ctx context.Context, applicantId uint) ([]ApplicationDto, error) {
+	return s.listApplications(ctx, s.Db.WithContext(ctx).Where("applicant_id = ?", applicantId))
+}
+
+func (s *serviceImpl) ReviewApplication(ctx context.Context, projectId uint, applicationId uint, status Status) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId":     projectId,
+		"applicationId": applicationId,
+		"status":        status,
+	})
+
+	result := s.Db.WithContext(ctx).
+		Model(&Application{}).
+		Where("id = ? AND project_id = ? AND status = ? AND hidden = ?", applicationId, projectId, StatusPending, false).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to review application")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrApplicationNotFound
+	}
+
+	logger.Debug("Application reviewed")
+	reviewedCounter.Inc(string(status))
+
+	return nil
+}
+
+func (s *serviceImpl) ListFlaggedApplications(ctx context.Context, pageSize uint, pageOffset uint) ([]FlaggedApplicationDto, error) {
+	var applications []Application
+	result := s.Db.WithContext(ctx).
+		Preload("Answers").
+		Where("flagged = true").
+		Order("created_at desc").
+		Limit(int(pageSize)).
+		Offset(int(pageOffset * pageSize)).
+		Find(&applications)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list flagged applications")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]FlaggedApplicationDto, len(applications))
+	for i, application := range applications {
+		dtos[i] = FlaggedApplicationDto{
+			ApplicationDto: applicationToDto(application),
+			MessageHash:    application.MessageHash,
+			Hidden:         application.Hidden,
+		}
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) listApplications(ctx context.Context, query *gorm.DB) ([]ApplicationDto, error) {
+	var applications []Application
+	result := query.
+		Preload("Answers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("InterviewSlots", func(db *gorm.DB) *gorm.DB {
+			return db.Order("starts_at")
+		}).
+		Order("created_at desc").
+		Find(&applications)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list applications")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]ApplicationDto, len(applications))
+	for i, application := range applications {
+		dtos[i] = applicationToDto(application)
+	}
+
+	return dtos, nil
+}
+
+func applicationToDto(application Application) ApplicationDto {
+	answers := make([]AnswerDto, len(application.Answers))
+	for i, answer := range application.Answers {
+		answers[i] = AnswerDto{
+			QuestionId: answer.QuestionId,
+			Question:   answer.Question,
+			Answer:     answer.Answer,
+		}
+	}
+
+	dto := ApplicationDto{
+		Id:          application.ID,
+		ProjectId:   application.ProjectId,
+		ApplicantId: application.ApplicantId,
+		Message:     application.Message,
+		RoleId:      application.RoleId,
+		Answers:     answers,
+		Status:      application.Status,
+		CreatedAt:   application.CreatedAt,
+		ReviewedAt:  application.ReviewedAt,
+		Interview:   interviewToDto(application),
+	}
+
+	if application.Status == StatusPending {
+		dto.ExpiresAt = application.ExpiresAt
+	}
+
+	return dto
+}
+
+// Check an application's answers against the screening questions of the role
+// it applies to, or of the project if it has no roles, in the questions'
+// order.
+// Returns ErrRoleRequired if the project has roles and the application isn't
+// to one of them, ErrRoleNotFound if the project doesn't have the role and
+// ErrInvalidAnswers if a required question isn't answered or an answer isn't
+// to one of the questions.
+func checkAnswers(project projects.ProjectDto, dto NewApplicationDto) ([]Answer, error) {
+	// Applications to projects without roles answer the project's questions
+	questions := project.Questions
+
+	if dto.RoleId == 0 {
+		if len(project.Roles) > 0 {
+			return nil, ErrRoleRequired
+		}
+	} else {
+		var role *projects.RoleDto
+		for i := range project.Roles {
+			if project.Roles[i].Id == dto.RoleId {
+				role = &project.Roles[i]
+				break
+			}
+		}
+
+		if role == nil {
+			return nil, ErrRoleNotFound
+		}
+
+		questions = role.Questions
+	}
+
+	answersByQuestion := make(map[uint]string, len(dto.Answers))
+	for _, answer := range dto.Answers {
+		answersByQuestion[answer.QuestionId] = strings.TrimSpace(answer.Answer)
+	}
+
+	answers := make([]Answer, 0, len(questions))
+	for _, question := range questions {
+		answer := answersByQuestion[question.Id]
+		delete(answersByQuestion, question.Id)
+
+		if answer == "" {
+			if question.Required {
+				return nil, fmt.Errorf("%w: question %d is required", ErrInvalidAnswers, question.Id)
+			}
+
+			continue
+		}
+
+		answers = append(answers, Answer{
+			QuestionId: question.Id,
+			Question:   question.Text,
+			Answer:     answer,
+		})
+	}
+
+	for questionId := range answersByQuestion {
+		return nil, fmt.Errorf("%w: question %d isn't one of the questions", ErrInvalidAnswers, questionId)
+	}
+
+	return answers, nil
+}
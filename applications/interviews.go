@@ -0,0 +1,394 @@
+package applications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/notifications"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrInvalidInterviewTransition = errors.New("invalid interview transition")
+var ErrInvalidInterviewTime = errors.New("interview slots must be in the future")
+var ErrSlotNotFound = errors.New("interview slot not found")
+
+// How long before an interview its participants are reminded of it.
+const interviewReminderLead = time.Hour
+
+// How often due interview reminders are sent.
+const interviewReminderInterval = time.Minute
+
+// Where an application's interview is in scheduling.
+type InterviewStatus string
+
+const (
+	// The owner proposed time slots, waiting for the applicant to pick one.
+	InterviewProposed InterviewStatus = "proposed"
+
+	// The applicant picked a slot.
+	InterviewScheduled InterviewStatus = "scheduled"
+
+	// The owner or the applicant cancelled the interview.
+	InterviewCancelled InterviewStatus = "cancelled"
+)
+
+// A time slot proposed for an application's interview.
+type InterviewSlot struct {
+	gorm.Model
+
+	ApplicationId uint
+	StartsAt      time.Time
+}
+
+func (InterviewSlot) TableName() string {
+	return "interview_slots"
+}
+
+func (s *serviceImpl) ProposeInterview(ctx context.Context, projectId uint, applicationId uint, dto NewInterviewDto) (ApplicationDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId":     projectId,
+		"applicationId": applicationId,
+	})
+
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return ApplicationDto{}, err
+	}
+
+	for _, startsAt := range dto.Slots {
+		if !startsAt.After(time.Now()) {
+			return ApplicationDto{}, ErrInvalidInterviewTime
+		}
+	}
+
+	application := Application{}
+	err = s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Preload("Answers").
+			Where("id = ? AND project_id = ? AND status = ? AND hidden = ?", applicationId, projectId, StatusPending, false).
+			First(&application)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrApplicationNotFound
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		// Proposing again replaces the previous proposal, which is also how a
+		// scheduled interview is rescheduled
+		result = tx.Unscoped().Where("application_id = ?", applicationId).Delete(&InterviewSlot{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		application.InterviewSlots = make([]InterviewSlot, len(dto.Slots))
+		for i, startsAt := range dto.Slots {
+			application.InterviewSlots[i] = InterviewSlot{
+				ApplicationId: applicationId,
+				StartsAt:      startsAt.UTC(),
+			}
+		}
+
+		result = tx.Create(&application.InterviewSlots)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		application.InterviewStatus = InterviewProposed
+		application.InterviewAt = nil
+		application.InterviewMinutes = dto.DurationMinutes
+		application.InterviewLocation = dto.Location
+		application.InterviewReminded = false
+
+		return tx.Model(&application).
+			Select("interview_status", "interview_at", "interview_minutes", "interview_location", "interview_reminded").
+			Updates(&application).
+			Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrApplicationNotFound) {
+			logger.WithError(err).Error("Failed to propose interview")
+		}
+
+		return ApplicationDto{}, err
+	}
+
+	logger.Debug("Interview proposed")
+
+	s.notifyInterview(ctx, application, application.ApplicantId, notifications.NewNotificationDto{
+		Type:  notifications.TypeInterviewProposed,
+		Title: "You're invited to an interview",
+		Body:  "Pick one of the proposed time slots for your interview.",
+	})
+
+	return applicationToDto(application), nil
+}
+
+func (s *serviceImpl) ScheduleInterview(ctx context.Context, applicantId uint, applicationId uint, slotId uint) (ApplicationDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"applicationId": applicationId,
+		"slotId":        slotId,
+	})
+
+	application := Application{}
+	err := s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Preload("Answers").
+			Preload("InterviewSlots").
+			Where("id = ? AND applicant_id = ?", applicationId, applicantId).
+			First(&application)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrApplicationNotFound
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		if application.InterviewStatus != InterviewProposed {
+			return fmt.Errorf("%w: can't schedule a %s interview", ErrInvalidInterviewTransition, statusOrNone(application.InterviewStatus))
+		}
+
+		var slot *InterviewSlot
+		for i := range application.InterviewSlots {
+			if application.InterviewSlots[i].ID == slotId {
+				slot = &application.InterviewSlots[i]
+				break
+			}
+		}
+
+		if slot == nil {
+			return ErrSlotNotFound
+		}
+
+		if !slot.StartsAt.After(time.Now()) {
+			return ErrInvalidInterviewTime
+		}
+
+		// Only update if the interview is still proposed, in case the owner
+		// cancelled or changed the proposal in the meantime
+		result = tx.Model(&Application{}).
+			Where("id = ? AND interview_status = ?", applicationId, InterviewProposed).
+			Updates(map[string]interface{}{
+				"interview_status": InterviewScheduled,
+				"interview_at":     slot.StartsAt,
+			})
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected < 1 {
+			return fmt.Errorf("%w: the interview was changed", ErrInvalidInterviewTransition)
+		}
+
+		application.InterviewStatus = InterviewScheduled
+		application.InterviewAt = &slot.StartsAt
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrApplicationNotFound) && !errors.Is(err, ErrInvalidInterviewTransition) &&
+			!errors.Is(err, ErrSlotNotFound) && !errors.Is(err, ErrInvalidInterviewTime) {
+			logger.WithError(err).Error("Failed to schedule interview")
+		}
+
+		return ApplicationDto{}, err
+	}
+
+	logger.Debug("Interview scheduled")
+
+	project, err := s.ProjectsService.GetProject(ctx, application.ProjectId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get project, the owner won't be notified of the interview")
+	} else {
+		s.notifyInterview(ctx, application, project.OwnerId, notifications.NewNotificationDto{
+			Type:  notifications.TypeInterviewScheduled,
+			Title: "Interview scheduled",
+			Body:  fmt.Sprintf("An applicant to %s picked %s for their interview.", project.Name, application.InterviewAt.Format(time.RFC1123)),
+		})
+	}
+
+	return applicationToDto(application), nil
+}
+
+func (s *serviceImpl) CancelInterview(ctx context.Context, userId uint, applicationId uint) error {
+	logger := log.FromContext(ctx).WithField("applicationId", applicationId)
+
+	application := Application{}
+	result := s.Db.WithContext(ctx).First(&application, applicationId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return ErrApplicationNotFound
+	} else if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get application")
+
+		return result.Error
+	}
+
+	project, err := s.ProjectsService.GetProject(ctx, application.ProjectId)
+	if err != nil {
+		return err
+	}
+
+	// Notify the other participant
+	var recipientId uint
+	switch userId {
+	case application.ApplicantId:
+		recipientId = project.OwnerId
+	case project.OwnerId:
+		recipientId = application.ApplicantId
+	default:
+		return ErrApplicationNotFound
+	}
+
+	result = s.Db.WithContext(ctx).
+		Model(&Application{}).
+		Where("id = ? AND interview_status IN ?", applicationId, []InterviewStatus{InterviewProposed, InterviewScheduled}).
+		Update("interview_status", InterviewCancelled)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to cancel interview")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return fmt.Errorf("%w: can't cancel a %s interview", ErrInvalidInterviewTransition, statusOrNone(application.InterviewStatus))
+	}
+
+	logger.Debug("Interview cancelled")
+
+	application.InterviewStatus = InterviewCancelled
+	s.notifyInterview(ctx, application, recipientId, notifications.NewNotificationDto{
+		Type:  notifications.TypeInterviewCancelled,
+		Title: "Interview cancelled",
+		Body:  fmt.Sprintf("The interview for an application to %s was cancelled.", project.Name),
+	})
+
+	return nil
+}
+
+func (s *serviceImpl) SendInterviewReminders(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	var due []Application
+	result := s.Db.WithContext(ctx).
+		Where("interview_status = ? AND interview_reminded = false", InterviewScheduled).
+		Where("interview_at > ? AND interview_at <= ?", time.Now(), time.Now().Add(interviewReminderLead)).
+		Find(&due)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list interviews to remind")
+
+		return result.Error
+	}
+
+	for _, application := range due {
+		// Only the instance that marks the interview sends the reminder
+		result = s.Db.WithContext(ctx).
+			Model(&Application{}).
+			Where("id = ? AND interview_reminded = false", application.ID).
+			Update("interview_reminded", true)
+
+		if result.Error != nil {
+			logger.WithError(result.Error).WithField("applicationId", application.ID).Error("Failed to mark interview as reminded")
+
+			continue
+		}
+
+		if result.RowsAffected < 1 {
+			continue
+		}
+
+		project, err := s.ProjectsService.GetProject(ctx, application.ProjectId)
+		if err != nil {
+			logger.WithError(err).WithField("applicationId", application.ID).Error("Failed to get project of interview to remind")
+
+			continue
+		}
+
+		reminder := notifications.NewNotificationDto{
+			Type:  notifications.TypeInterviewReminder,
+			Title: "Upcoming interview",
+			Body:  fmt.Sprintf("Your interview for %s starts at %s.", project.Name, application.InterviewAt.Format(time.RFC1123)),
+		}
+
+		s.notifyInterview(ctx, application, application.ApplicantId, reminder)
+		s.notifyInterview(ctx, application, project.OwnerId, reminder)
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(interviewReminderInterval)
+	defer ticker.Stop()
+
+	expiryTicker := time.NewTicker(expiryInterval)
+	defer expiryTicker.Stop()
+
+	for {
+		// Errors are logged, the next tick tries again
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.SendInterviewReminders(ctx)
+		case <-expiryTicker.C:
+			_ = s.ExpireApplications(ctx)
+		}
+	}
+}
+
+// Notify a participant of an interview. Failures are only logged, the
+// interview has already changed.
+func (s *serviceImpl) notifyInterview(ctx context.Context, application Application, userId uint, notification notifications.NewNotificationDto) {
+	notification.Data = map[string]interface{}{
+		"applicationId":   application.ID,
+		"projectId":       application.ProjectId,
+		"interviewStatus": application.InterviewStatus,
+	}
+	if application.InterviewAt != nil {
+		notification.Data["interviewAt"] = application.InterviewAt
+	}
+
+	err := s.NotificationsService.Notify(ctx, userId, notification)
+	if err != nil {
+		log.FromContext(ctx).
+			WithError(err).
+			WithFields(log.Fields{"applicationId": application.ID, "userId": userId}).
+			Error("Failed to notify of interview")
+	}
+}
+
+func statusOrNone(status InterviewStatus) string {
+	if status == "" {
+		return "missing"
+	}
+
+	return string(status)
+}
+
+func interviewToDto(application Application) *InterviewDto {
+	if application.InterviewStatus == "" {
+		return nil
+	}
+
+	slots := make([]InterviewSlotDto, len(application.InterviewSlots))
+	for i, slot := range application.InterviewSlots {
+		slots[i] = InterviewSlotDto{
+			Id:       slot.ID,
+			StartsAt: slot.StartsAt,
+		}
+	}
+
+	return &InterviewDto{
+		Status:          application.InterviewStatus,
+		Slots:           slots,
+		StartsAt:        application.InterviewAt,
+		DurationMinutes: application.InterviewMinutes,
+		Location:        application.InterviewLocation,
+	}
+}
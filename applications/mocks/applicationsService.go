@@ -0,0 +1,244 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: applicationsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	applications "github.com/open-collaboration/server/applications"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Apply mocks base method
+func (m *MockService) Apply(ctx context.Context, applicantId, projectId uint, dto applications.NewApplicationDto, hidden bool) (applications.ApplicationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Apply", ctx, applicantId, projectId, dto, hidden)
+	ret0, _ := ret[0].(applications.ApplicationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Apply indicates an expected call of Apply
+func (mr *MockServiceMockRecorder) Apply(ctx, applicantId, projectId, dto, hidden interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Apply", reflect.TypeOf((*MockService)(nil).Apply), ctx, applicantId, projectId, dto, hidden)
+}
+
+// ListProjectApplications mocks base method
+func (m *MockService) ListProjectApplications(ctx context.Context, projectId uint) ([]applications.ApplicationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectApplications", ctx, projectId)
+	ret0, _ := ret[0].([]applications.ApplicationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjectApplications indicates an expected call of ListProjectApplications
+func (mr *MockServiceMockRecorder) ListProjectApplications(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectApplications", reflect.TypeOf((*MockService)(nil).ListProjectApplications), ctx, projectId)
+}
+
+// ListUserApplications mocks base method
+func (m *MockService) ListUserApplications(ctx context.Context, applicantId uint) ([]applications.ApplicationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserApplications", ctx, applicantId)
+	ret0, _ := ret[0].([]applications.ApplicationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserApplications indicates an expected call of ListUserApplications
+func (mr *MockServiceMockRecorder) ListUserApplications(ctx, applicantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserApplications", reflect.TypeOf((*MockService)(nil).ListUserApplications), ctx, applicantId)
+}
+
+// ReviewApplication mocks base method
+func (m *MockService) ReviewApplication(ctx context.Context, projectId, applicationId uint, status applications.Status) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewApplication", ctx, projectId, applicationId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReviewApplication indicates an expected call of ReviewApplication
+func (mr *MockServiceMockRecorder) ReviewApplication(ctx, projectId, applicationId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewApplication", reflect.TypeOf((*MockService)(nil).ReviewApplication), ctx, projectId, applicationId, status)
+}
+
+// ListFlaggedApplications mocks base method
+func (m *MockService) ListFlaggedApplications(ctx context.Context, pageSize, pageOffset uint) ([]applications.FlaggedApplicationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFlaggedApplications", ctx, pageSize, pageOffset)
+	ret0, _ := ret[0].([]applications.FlaggedApplicationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFlaggedApplications indicates an expected call of ListFlaggedApplications
+func (mr *MockServiceMockRecorder) ListFlaggedApplications(ctx, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFlaggedApplications", reflect.TypeOf((*MockService)(nil).ListFlaggedApplications), ctx, pageSize, pageOffset)
+}
+
+// ProposeInterview mocks base method
+func (m *MockService) ProposeInterview(ctx context.Context, projectId, applicationId uint, dto applications.NewInterviewDto) (applications.ApplicationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProposeInterview", ctx, projectId, applicationId, dto)
+	ret0, _ := ret[0].(applications.ApplicationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProposeInterview indicates an expected call of ProposeInterview
+func (mr *MockServiceMockRecorder) ProposeInterview(ctx, projectId, applicationId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProposeInterview", reflect.TypeOf((*MockService)(nil).ProposeInterview), ctx, projectId, applicationId, dto)
+}
+
+// ScheduleInterview mocks base method
+func (m *MockService) ScheduleInterview(ctx context.Context, applicantId, applicationId, slotId uint) (applications.ApplicationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleInterview", ctx, applicantId, applicationId, slotId)
+	ret0, _ := ret[0].(applications.ApplicationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleInterview indicates an expected call of ScheduleInterview
+func (mr *MockServiceMockRecorder) ScheduleInterview(ctx, applicantId, applicationId, slotId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleInterview", reflect.TypeOf((*MockService)(nil).ScheduleInterview), ctx, applicantId, applicationId, slotId)
+}
+
+// CancelInterview mocks base method
+func (m *MockService) CancelInterview(ctx context.Context, userId, applicationId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelInterview", ctx, userId, applicationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelInterview indicates an expected call of CancelInterview
+func (mr *MockServiceMockRecorder) CancelInterview(ctx, userId, applicationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelInterview", reflect.TypeOf((*MockService)(nil).CancelInterview), ctx, userId, applicationId)
+}
+
+// SendInterviewReminders mocks base method
+func (m *MockService) SendInterviewReminders(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendInterviewReminders", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendInterviewReminders indicates an expected call of SendInterviewReminders
+func (mr *MockServiceMockRecorder) SendInterviewReminders(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendInterviewReminders", reflect.TypeOf((*MockService)(nil).SendInterviewReminders), ctx)
+}
+
+// ExpireApplications mocks base method
+func (m *MockService) ExpireApplications(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireApplications", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExpireApplications indicates an expected call of ExpireApplications
+func (mr *MockServiceMockRecorder) ExpireApplications(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireApplications", reflect.TypeOf((*MockService)(nil).ExpireApplications), ctx)
+}
+
+// GetResponseTime mocks base method
+func (m *MockService) GetResponseTime(ctx context.Context, ownerId uint) (applications.ResponseTimeDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResponseTime", ctx, ownerId)
+	ret0, _ := ret[0].(applications.ResponseTimeDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResponseTime indicates an expected call of GetResponseTime
+func (mr *MockServiceMockRecorder) GetResponseTime(ctx, ownerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResponseTime", reflect.TypeOf((*MockService)(nil).GetResponseTime), ctx, ownerId)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
+
+// MockApplicationListener is a mock of ApplicationListener interface
+type MockApplicationListener struct {
+	ctrl     *gomock.Controller
+	recorder *MockApplicationListenerMockRecorder
+}
+
+// MockApplicationListenerMockRecorder is the mock recorder for MockApplicationListener
+type MockApplicationListenerMockRecorder struct {
+	mock *MockApplicationListener
+}
+
+// NewMockApplicationListener creates a new mock instance
+func NewMockApplicationListener(ctrl *gomock.Controller) *MockApplicationListener {
+	mock := &MockApplicationListener{ctrl: ctrl}
+	mock.recorder = &MockApplicationListenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockApplicationListener) EXPECT() *MockApplicationListenerMockRecorder {
+	return m.recorder
+}
+
+// ApplicationCreated mocks base method
+func (m *MockApplicationListener) ApplicationCreated(ctx context.Context, project projects.ProjectDto, application applications.ApplicationDto) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ApplicationCreated", ctx, project, application)
+}
+
+// ApplicationCreated indicates an expected call of ApplicationCreated
+func (mr *MockApplicationListenerMockRecorder) ApplicationCreated(ctx, project, application interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplicationCreated", reflect.TypeOf((*MockApplicationListener)(nil).ApplicationCreated), ctx, project, application)
+}
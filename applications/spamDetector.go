@@ -0,0 +1,130 @@
+// NOTE: take a look at the projects redis documentation (docs/redis.md)
+// to better understand how spam notifications are deduplicated.
+
+package applications
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Thresholds of the application spam heuristics. A user that sends
+// the same message (ignoring case and whitespace) in FlagThreshold
+// applications within Window has their applications flagged and
+// moderators are notified. At ThrottleThreshold their applications
+// are rejected until the window passes.
+type SpamThresholds struct {
+	Window            time.Duration
+	FlagThreshold     int
+	ThrottleThreshold int
+}
+
+type spamVerdict int
+
+const (
+	verdictOk spamVerdict = iota
+	verdictFlag
+	verdictThrottle
+)
+
+type spamDetector struct {
+	Db                   *gorm.DB
+	Kv                   kv.Store
+	NotificationsService notifications.Service
+	Thresholds           SpamThresholds
+}
+
+// Decide what to do with a new application with the given message hash,
+// based on how many applications with the same message the applicant
+// sent recently.
+func (d *spamDetector) check(ctx context.Context, applicantId uint, messageHash string) (spamVerdict, error) {
+	logger := log.FromContext(ctx).WithField("applicantId", applicantId)
+
+	var count int64
+	result := d.Db.WithContext(ctx).
+		Model(&Application{}).
+		Where("applicant_id = ? AND message_hash = ? AND created_at > ?", applicantId, messageHash, time.Now().Add(-d.Thresholds.Window)).
+		Count(&count)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to count similar applications")
+
+		return verdictOk, result.Error
+	}
+
+	// Count the application being created too
+	count++
+
+	if count >= int64(d.Thresholds.ThrottleThreshold) {
+		logger.WithField("count", count).Warn("Throttling application spam")
+		d.notifyModerators(ctx, applicantId, count)
+
+		return verdictThrottle, nil
+	}
+
+	if count >= int64(d.Thresholds.FlagThreshold) {
+		logger.WithField("count", count).Warn("Flagging application spam")
+		d.notifyModerators(ctx, applicantId, count)
+
+		return verdictFlag, nil
+	}
+
+	return verdictOk, nil
+}
+
+// Notify moderators about a spamming user. Moderators are notified at
+// most once per user per window.
+func (d *spamDetector) notifyModerators(ctx context.Context, applicantId uint, count int64) {
+	logger := log.FromContext(ctx).WithField("applicantId", applicantId)
+
+	first, err := d.Kv.SetNX(ctx, spamNotifiedRedisKey(applicantId), "1", d.Thresholds.Window)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to check whether moderators were notified")
+		return
+	}
+
+	if !first {
+		return
+	}
+
+	err = d.NotificationsService.NotifyRole(ctx, users.RoleModerator, notifications.NewNotificationDto{
+		Type:  notifications.TypeApplicationSpam,
+		Title: "Possible application spam",
+		Body: fmt.Sprintf(
+			"User %d sent the same application message %d times in the last %s.",
+			applicantId,
+			count,
+			d.Thresholds.Window,
+		),
+		Data: map[string]interface{}{
+			"userId": applicantId,
+			"count":  count,
+		},
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to notify moderators about application spam")
+	}
+}
+
+// Hash a message ignoring case and whitespace differences, so that
+// trivially modified copies of a message have the same hash.
+func hashMessage(message string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(message)), " ")
+	hash := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Exists while moderators were notified about a user's application spam.
+func spamNotifiedRedisKey(userId uint) string {
+	return fmt.Sprintf("user:%d:application.spam.notified", userId)
+}
@@ -0,0 +1,23 @@
+package audit
+
+import "time"
+
+type EntryDto struct {
+	Id         uint                   `json:"id"`
+	ActorId    uint                   `json:"actorId"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"targetType"`
+	TargetId   uint                   `json:"targetId"`
+	Details    map[string]interface{} `json:"details"`
+	Ip         string                 `json:"ip"`
+	CreatedAt  time.Time              `json:"createdAt"`
+}
+
+type ListEntriesParamsDto struct {
+	ActorId    uint
+	Action     string
+	TargetType string
+	TargetId   uint
+	PageSize   uint
+	PageOffset uint
+}
@@ -0,0 +1,26 @@
+package audit
+
+import "gorm.io/gorm"
+
+type Entry struct {
+	gorm.Model
+
+	// The user that performed the action.
+	ActorId uint
+
+	// What was done, e.g. "impersonation.start".
+	Action string
+
+	// What the action was performed on, e.g. "user" and 42.
+	TargetType string
+	TargetId   uint
+
+	// Action specific data, stored as a json object.
+	Details string `gorm:"type: JSONB"`
+
+	Ip string
+}
+
+func (Entry) TableName() string {
+	return "audit_log_entries"
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List audit log entries
+// @Tags admin
+// @Router /admin/audit-log [get]
+// @Param actorId query int false "Only entries of actions performed by this user"
+// @Param action query string false "Only entries of this action, e.g. impersonation.start"
+// @Param targetType query string false "Only entries of actions performed on this type of target, e.g. user"
+// @Param targetId query int false "Only entries of actions performed on the target with this id"
+// @Param pageSize query int false "Maximum amount of entries in the response. Default is 50, max is 200."
+// @Param pageOffset query int false "Response page number."
+// @Success 200 {array} audit.EntryDto
+// @Failure 403
+func RouteListEntries(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	auditService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 50)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+	actorId, _ := utils.IntFromQuery(request, "actorId", 0)
+	targetId, _ := utils.IntFromQuery(request, "targetId", 0)
+
+	if pageSize < 1 || pageSize > 200 {
+		pageSize = 50
+	}
+
+	if pageOffset < 0 {
+		pageOffset = 0
+	}
+
+	if actorId < 0 {
+		actorId = 0
+	}
+
+	if targetId < 0 {
+		targetId = 0
+	}
+
+	entries, err := auditService.ListEntries(request.Context(), ListEntriesParamsDto{
+		ActorId:    uint(actorId),
+		Action:     request.URL.Query().Get("action"),
+		TargetType: request.URL.Query().Get("targetType"),
+		TargetId:   uint(targetId),
+		PageSize:   uint(pageSize),
+		PageOffset: uint(pageOffset),
+	})
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, entries)
+}
@@ -0,0 +1,136 @@
+package audit
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/utils"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	// Record an action in the audit log. The client's IP address is taken from ctx.
+	// details can be nil.
+	Record(
+		ctx context.Context,
+		actorId uint,
+		action string,
+		targetType string,
+		targetId uint,
+		details map[string]interface{},
+	) error
+
+	// List audit log entries, newest to oldest. Zero values in params
+	// (other than the page) are ignored.
+	ListEntries(ctx context.Context, params ListEntriesParamsDto) ([]EntryDto, error)
+}
+
+type serviceImpl struct {
+	Db *gorm.DB
+}
+
+func NewService(db *gorm.DB) Service {
+	return &serviceImpl{Db: db}
+}
+
+func (s *serviceImpl) Record(
+	ctx context.Context,
+	actorId uint,
+	action string,
+	targetType string,
+	targetId uint,
+	details map[string]interface{},
+) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"actorId":    actorId,
+		"action":     action,
+		"targetType": targetType,
+		"targetId":   targetId,
+	})
+
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+
+	detailsJson, err := json.Marshal(details)
+	if err != nil {
+		logger.WithError(err).Error("Failed to serialize audit log details")
+
+		return err
+	}
+
+	entry := Entry{
+		ActorId:    actorId,
+		Action:     action,
+		TargetType: targetType,
+		TargetId:   targetId,
+		Details:    string(detailsJson),
+		Ip:         utils.ClientIp(ctx),
+	}
+
+	result := s.Db.WithContext(ctx).Create(&entry)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to record audit log entry")
+
+		return result.Error
+	}
+
+	logger.Info("Audit log entry recorded")
+
+	return nil
+}
+
+func (s *serviceImpl) ListEntries(ctx context.Context, params ListEntriesParamsDto) ([]EntryDto, error) {
+	query := s.Db.WithContext(ctx).Order("created_at desc")
+
+	if params.ActorId != 0 {
+		query = query.Where("actor_id = ?", params.ActorId)
+	}
+
+	if params.Action != "" {
+		query = query.Where("action = ?", params.Action)
+	}
+
+	if params.TargetType != "" {
+		query = query.Where("target_type = ?", params.TargetType)
+	}
+
+	if params.TargetId != 0 {
+		query = query.Where("target_id = ?", params.TargetId)
+	}
+
+	var entries []Entry
+	result := query.
+		Limit(int(params.PageSize)).
+		Offset(int(params.PageOffset * params.PageSize)).
+		Find(&entries)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list audit log entries")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]EntryDto, len(entries))
+	for i, entry := range entries {
+		details := map[string]interface{}{}
+
+		// Details are always serialized by Record, so this shouldn't fail
+		_ = json.Unmarshal([]byte(entry.Details), &details)
+
+		dtos[i] = EntryDto{
+			Id:         entry.ID,
+			ActorId:    entry.ActorId,
+			Action:     entry.Action,
+			TargetType: entry.TargetType,
+			TargetId:   entry.TargetId,
+			Details:    details,
+			Ip:         entry.Ip,
+			CreatedAt:  entry.CreatedAt,
+		}
+	}
+
+	return dtos, nil
+}
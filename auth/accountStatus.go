@@ -0,0 +1,145 @@
+package auth
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrAccountReadOnly = errors.New("account is read-only")
+var ErrPostingCooldown = errors.New("posting cooldown has not elapsed")
+
+// The restrictions moderators placed on a user's account.
+type AccountStatus struct {
+	// The user can't change anything, only read.
+	ReadOnly bool
+
+	// The minimum time between two pieces of content
+	// created by the user. 0 means no cooldown.
+	PostingCooldown time.Duration
+
+	// Content created by the user is hidden from everyone
+	// else until a moderator reviews it.
+	ShadowHidden bool
+}
+
+// An AccountStatusProvider provides the account status of users. It's
+// implemented by moderation.Service.
+type AccountStatusProvider interface {
+	// Get the restrictions currently placed on a user.
+	GetAccountStatus(ctx context.Context, userId uint) (AccountStatus, error)
+
+	// Returns ErrPostingCooldown if the user's posting cooldown has not
+	// elapsed yet.
+	CheckPostingCooldown(ctx context.Context, userId uint) error
+
+	// Start a user's posting cooldown.
+	// Returns ErrPostingCooldown if the previous cooldown has not elapsed yet.
+	StartPostingCooldown(ctx context.Context, userId uint, cooldown time.Duration) error
+}
+
+// Helper function to check if a request contains a valid session whose user is
+// allowed to create content (e.g. a project or a comment). Intended to be used
+// inside route handlers that create content, instead of CheckSession.
+//
+// Returns ErrUnauthenticated if there is no session, ErrAccountReadOnly if the user
+// is in read-only mode and ErrPostingCooldown if the user's posting cooldown has not
+// elapsed. Otherwise returns the session and the user's account status, which the
+// handler should use to shadow hide the content if necessary, and pass to
+// StartPostingCooldown once the content is created.
+func CheckPostingSession(r *http.Request, statusProvider AccountStatusProvider) (Session, AccountStatus, error) {
+	session, err := CheckSession(r)
+	if err != nil {
+		return Session{}, AccountStatus{}, err
+	}
+
+	status, err := statusProvider.GetAccountStatus(r.Context(), session.userId)
+	if err != nil {
+		return Session{}, AccountStatus{}, err
+	}
+
+	if status.ReadOnly {
+		return Session{}, AccountStatus{}, ErrAccountReadOnly
+	}
+
+	if status.PostingCooldown > 0 {
+		err = statusProvider.CheckPostingCooldown(r.Context(), session.userId)
+		if err != nil {
+			return Session{}, AccountStatus{}, err
+		}
+	}
+
+	return session, status, nil
+}
+
+// Start the posting cooldown of a user with a cooldown restriction, once the
+// content they were checked for by CheckPostingSession is created, so that
+// failed attempts don't make them wait. Failures are only logged since the
+// content exists anyway.
+func StartPostingCooldown(r *http.Request, statusProvider AccountStatusProvider, session Session, status AccountStatus) {
+	if status.PostingCooldown <= 0 {
+		return
+	}
+
+	err := statusProvider.StartPostingCooldown(r.Context(), session.userId, status.PostingCooldown)
+	if err != nil && !errors.Is(err, ErrPostingCooldown) {
+		log.FromContext(r.Context()).WithError(err).Error("Failed to start posting cooldown")
+	}
+}
+
+// Rejects requests that may change data (i.e. anything but GET, HEAD and
+// OPTIONS) made by users in read-only mode. Authentication routes are
+// allowed so that read-only users can still log in and out.
+//
+// Must be used after SessionMiddleware.
+func ReadOnlyMiddleware(statusProvider AccountStatusProvider) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" ||
+				r.URL.Path == "/login" || strings.HasPrefix(r.URL.Path, "/auth/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			session, err := CheckSession(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			logger := log.FromContext(ctx)
+
+			status, err := statusProvider.GetAccountStatus(ctx, session.userId)
+			if err != nil {
+				logger.WithError(err).Error("Failed to get account status")
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			if status.ReadOnly {
+				logger.Debug("Rejecting request of read-only user")
+
+				err = utils.WriteJson(w, ctx, http.StatusForbidden, map[string]interface{}{
+					"code":    "account-read-only-error",
+					"details": map[string]interface{}{},
+				})
+				if err != nil {
+					logger.WithError(err).Error("Failed to write error response")
+				}
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
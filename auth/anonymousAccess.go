@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strings"
+)
+
+// Whether requests without a session can call a route.
+type Access string
+
+const (
+	// Part of the public, read-only API: anonymous requests can read the
+	// route while the public API is enabled. Only allowed for GET routes.
+	AccessPublic Access = "public"
+
+	// Always reachable without a session, e.g. the routes that create a
+	// session or that are authenticated by other means.
+	AccessAnonymous Access = "anonymous"
+
+	// Requires a session.
+	AccessSession Access = "session"
+)
+
+// The access of routes, by the route's method and path template, e.g.
+// "GET /projects/{projectId}". Routes that aren't in the policy require a
+// session.
+type AccessPolicy map[string]Access
+
+// The access of a route.
+func (p AccessPolicy) Access(method string, pathTemplate string) Access {
+	// HEAD is answered by the GET route
+	if method == "HEAD" {
+		method = "GET"
+	}
+
+	access, ok := p[method+" "+pathTemplate]
+	if !ok {
+		return AccessSession
+	}
+
+	return access
+}
+
+// A copy of the policy with the entries of overrides, which replace the
+// policy's entries for the same routes.
+func (p AccessPolicy) With(overrides AccessPolicy) AccessPolicy {
+	policy := AccessPolicy{}
+	for route, access := range p {
+		policy[route] = access
+	}
+	for route, access := range overrides {
+		policy[route] = access
+	}
+
+	return policy
+}
+
+// Parse route accesses in the format "METHOD /path/template=access", separated
+// by commas, e.g. "GET /users/{userId}/contributions=session,GET /skills=public".
+func ParseAccessPolicy(value string) (AccessPolicy, error) {
+	policy := AccessPolicy{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		separator := strings.LastIndex(entry, "=")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid route access %q: missing =", entry)
+		}
+
+		route := strings.TrimSpace(entry[:separator])
+		access := Access(strings.TrimSpace(entry[separator+1:]))
+
+		switch access {
+		case AccessPublic:
+			// The public API is read-only
+			if !strings.HasPrefix(route, "GET ") {
+				return nil, fmt.Errorf("invalid route access %q: only GET routes can be public", entry)
+			}
+		case AccessAnonymous, AccessSession:
+		default:
+			return nil, fmt.Errorf("invalid route access %q: access must be public, anonymous or session", entry)
+		}
+
+		policy[route] = access
+	}
+
+	return policy, nil
+}
+
+// Rejects requests without a session to routes that require one, with a 401.
+// Routes are only public if publicApi is true, otherwise they require a
+// session too.
+//
+// Route handlers may still check the session to tell who the user is, but
+// don't need to reject anonymous requests themselves.
+//
+// Must be used after SessionMiddleware.
+func AnonymousAccessMiddleware(policy AccessPolicy, publicApi bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := CheckSession(r); err == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			route := mux.CurrentRoute(r)
+			if route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			access := policy.Access(r.Method, template)
+			if access == AccessAnonymous || (access == AccessPublic && publicApi) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			logger := log.FromContext(ctx)
+
+			logger.Debug("Rejecting anonymous request to a route that requires a session")
+
+			err = utils.WriteJson(w, ctx, http.StatusUnauthorized, map[string]interface{}{
+				"code":    "unauthenticated-error",
+				"details": map[string]interface{}{},
+			})
+			if err != nil {
+				logger.WithError(err).Error("Failed to write error response")
+			}
+		})
+	}
+}
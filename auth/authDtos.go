@@ -1,7 +1,32 @@
 package auth
 
+import "github.com/open-collaboration/server/users"
+
 type LoginDto struct {
 	UsernameOrEmail string `json:"usernameOrEmail"`
 	Password        string `json:"password"`
 	RecaptchaToken  string `json:"recaptchaToken"`
 }
+
+type SessionDto struct {
+	UserId         uint              `json:"userId"`
+	User           users.UserDataDto `json:"user"`
+	Role           users.Role        `json:"role"`
+	ImpersonatorId uint              `json:"impersonatorId,omitempty"`
+}
+
+type ValidateSessionsDto struct {
+	SessionTokens []string `json:"sessionTokens" validate:"required,max=100"`
+}
+
+type SessionValidationDto struct {
+	SessionToken   string `json:"sessionToken"`
+	Valid          bool   `json:"valid"`
+	UserId         uint   `json:"userId,omitempty"`
+	ImpersonatorId uint   `json:"impersonatorId,omitempty"`
+}
+
+type InvalidateSessionsDto struct {
+	// Why the sessions are invalidated, recorded in the audit log.
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -1,61 +1,268 @@
 package auth
 
 import (
-	"fmt"
-	"github.com/apex/log"
+	"context"
+	"crypto/subtle"
+	"errors"
 	"github.com/open-collaboration/server/users"
 	"github.com/open-collaboration/server/utils"
 	"net/http"
 )
 
-// @Summary Authenticate user
+// @Summary Log in
+// @Description Authenticates a user with their username (or email) and password and creates a session.
+// @Description Also served at /login for older clients.
 // @Tags users
-// @Router /login [post]
-// @Param credentials body dtos.LoginDto true "The user's credentials"
-// @Success 200 {object} dtos.UserDataDto "User successfully authenticated"
+// @Router /auth/login [post]
+// @Param credentials body auth.LoginDto true "The user's credentials"
+// @Success 200 {object} users.UserDataDto "User successfully authenticated"
 // @Header 200 {string} Set-Cookie "Session token. E.g. sessionToken=72f34c69-6eb0-47cf-83ed-c2b5ad3989df"
 // @Failure 401
-func RouteAuthenticateUser(
+func RouteLogin(
 	writer http.ResponseWriter,
 	request *http.Request,
 	authService Service,
 ) error {
 	ctx := request.Context()
 
-	logger := log.FromContext(ctx)
-
 	dto := LoginDto{}
 	err := utils.ReadJson(ctx, request, &dto)
 	if err != nil {
 		return err
 	}
 
-	user, err := authService.AuthenticateUser(ctx, dto)
+	user, sessionToken, err := authService.AuthenticateUser(ctx, dto)
 	if err != nil {
 		return err
 	}
 
-	if user != nil {
-		sessionToken, err := authService.CreateSession(ctx, user.ID)
-		if err != nil {
-			logger.WithError(err).Error("Failed to create session")
-		}
+	SetSessionCookie(writer, sessionToken)
 
-		userData := users.UserDataDto{
-			Username: user.Username,
-			Email:    user.Email,
-		}
+	return utils.WriteJson(writer, ctx, http.StatusOK, users.UserToDataDto(user))
+}
 
-		cookieHeader := fmt.Sprintf("%s=%s", "sessionToken", sessionToken)
-		writer.Header().Set("Set-Cookie", cookieHeader)
+// @Summary Log out
+// @Description Invalidates the request's session. The user's other sessions stay valid.
+// @Tags users
+// @Router /auth/logout [post]
+// @Success 204
+// @Header 204 {string} Set-Cookie "Expired session token cookie"
+// @Failure 401
+func RouteLogout(writer http.ResponseWriter, request *http.Request, authService Service) error {
+	session, err := CheckSession(request)
+	if err != nil {
+		return err
+	}
 
-		err = utils.WriteJson(writer, ctx, http.StatusOK, userData)
-		if err != nil {
-			return err
-		}
-	} else {
-		writer.WriteHeader(401)
+	err = authService.InvalidateSession(request.Context(), session.Token())
+	if err != nil && !errors.Is(err, ErrInvalidSessionToken) {
+		return err
+	}
+
+	ClearSessionCookie(writer)
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Get the current session
+// @Description The user that owns the request's session and, for impersonation sessions, the impersonating admin.
+// @Tags users
+// @Router /auth/session [get]
+// @Param fields query string false "Comma separated fields of the session to include in the response, e.g. userId,role. All fields by default."
+// @Success 200 {object} auth.SessionDto
+// @Failure 401
+func RouteGetSession(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+) error {
+	session, err := CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := usersService.GetUser(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	impersonatorId, _ := session.ImpersonatorId()
+
+	response, err := utils.SelectFields(SessionDto{
+		UserId:         user.ID,
+		User:           users.UserToDataDto(user),
+		Role:           user.Role,
+		ImpersonatorId: impersonatorId,
+	}, utils.FieldsFromQuery(request))
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, response)
+}
+
+// Records admin actions in the audit log. Implemented by audit.Service,
+// which can't be imported here because it depends on this package.
+type ActionRecorder interface {
+	Record(
+		ctx context.Context,
+		actorId uint,
+		action string,
+		targetType string,
+		targetId uint,
+		details map[string]interface{},
+	) error
+}
+
+// @Summary Invalidate a user's sessions
+// @Description Logs the user out everywhere. Sessions created afterwards are valid.
+// @Tags admin
+// @Router /admin/users/{userId}/sessions/invalidate [post]
+// @Param userId path int true "The user ID"
+// @Param reason body auth.InvalidateSessionsDto true "Why the sessions are invalidated"
+// @Success 204
+// @Failure 403
+func RouteInvalidateUserSessions(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	authService Service,
+	actionRecorder ActionRecorder,
+) error {
+	ctx := request.Context()
+
+	session, err := CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	dto := InvalidateSessionsDto{}
+	err = utils.ReadJson(ctx, request, &dto)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the user exists
+	_, err = usersService.GetUser(ctx, userId)
+	if err != nil {
+		return err
 	}
 
+	err = actionRecorder.Record(ctx, session.UserId(), "sessions.invalidate", "user", userId, map[string]interface{}{
+		"reason": dto.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = authService.BumpSessionEpoch(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
 	return nil
 }
+
+// @Summary Invalidate all sessions
+// @Description Logs every user out, including the admin making the request. Intended for incident response.
+// @Tags admin
+// @Router /admin/sessions/invalidate [post]
+// @Param reason body auth.InvalidateSessionsDto true "Why the sessions are invalidated"
+// @Success 204
+// @Failure 403
+func RouteInvalidateAllSessions(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	authService Service,
+	actionRecorder ActionRecorder,
+) error {
+	ctx := request.Context()
+
+	session, err := CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := InvalidateSessionsDto{}
+	err = utils.ReadJson(ctx, request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = actionRecorder.Record(ctx, session.UserId(), "sessions.invalidate-all", "", 0, map[string]interface{}{
+		"reason": dto.Reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = authService.BumpGlobalSessionEpoch(ctx)
+	if err != nil {
+		return err
+	}
+
+	ClearSessionCookie(writer)
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// Shared secret that API gateways send in the X-Gateway-Key header to call
+// internal endpoints. Internal endpoints are disabled when it's empty.
+type GatewayKey string
+
+// @Summary Validate session tokens
+// @Description Internal endpoint for API gateways: validates up to 100 session tokens at once.
+// @Description Results are in the same order as the tokens.
+// @Tags internal
+// @Router /internal/sessions/validate [post]
+// @Param X-Gateway-Key header string true "The gateway key"
+// @Param tokens body auth.ValidateSessionsDto true "The session tokens"
+// @Success 200 {array} auth.SessionValidationDto
+// @Failure 403
+func RouteValidateSessions(
+	writer http.ResponseWriter,
+	request *http.Request,
+	authService Service,
+	gatewayKey GatewayKey,
+) error {
+	ctx := request.Context()
+
+	requestKey := request.Header.Get("X-Gateway-Key")
+	if gatewayKey == "" || subtle.ConstantTimeCompare([]byte(requestKey), []byte(gatewayKey)) != 1 {
+		return ErrForbidden
+	}
+
+	dto := ValidateSessionsDto{}
+	err := utils.ReadJson(ctx, request, &dto)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := authService.AuthenticateSessions(ctx, dto.SessionTokens)
+	if err != nil {
+		return err
+	}
+
+	validations := make([]SessionValidationDto, len(dto.SessionTokens))
+	for i, sessionToken := range dto.SessionTokens {
+		validations[i].SessionToken = sessionToken
+
+		if session, ok := sessions[sessionToken]; ok {
+			validations[i].Valid = true
+			validations[i].UserId = session.UserId()
+			validations[i].ImpersonatorId, _ = session.ImpersonatorId()
+		}
+	}
+
+	return utils.WriteJson(writer, ctx, http.StatusOK, validations)
+}
@@ -3,12 +3,12 @@
 
 package auth
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
 import (
 	"context"
 	"errors"
-	"fmt"
 	"github.com/apex/log"
-	"github.com/go-redis/redis/v8"
 	"github.com/gofrs/uuid"
 	"github.com/open-collaboration/server/users"
 	"gorm.io/gorm"
@@ -18,96 +18,280 @@ import (
 var ErrInvalidSessionToken = errors.New("invalid session key")
 var ErrWrongPassword = errors.New("wrong password")
 
+// Returned instead of users.ErrUserNotFound and ErrWrongPassword when
+// enumeration protection is on, see users.EnumerationProtection.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
 type Service interface {
-	// Authenticate a user with username or email and a password.
-	// Returns ErrUserNotFound if a user with a matching username/email and password pair cannot be found.
+	// Authenticate a user with username or email and a password and log them
+	// in (see LogIn). Returns the user and the new session's key.
+	// Returns users.ErrUserNotFound if a user with a matching username/email cannot be found.
 	// Returns ErrWrongPassword if the hashed password does not equal the user's stored password hash.
-	AuthenticateUser(ctx context.Context, authUser LoginDto) (*users.User, error)
+	// Both are replaced by ErrInvalidCredentials if enumeration protection is on.
+	AuthenticateUser(ctx context.Context, authUser LoginDto) (*users.User, string, error)
 
-	// Check if a session exists and, if it does, return the session's user.
+	// Log in a user whose credentials were verified, by password or through
+	// an oauth provider: run the login guards, then create a session (see
+	// CreateSession). Returns the session key, or the error of the first
+	// guard that rejects the login.
+	LogIn(ctx context.Context, user *users.User) (string, error)
+
+	// Check if a session exists and, if it does, return it.
 	// Returns ErrInvalidSessionToken if the session does not exist.
-	AuthenticateSession(ctx context.Context, sessionKey string) (uint, error)
+	AuthenticateSession(ctx context.Context, sessionKey string) (Session, error)
+
+	// Check many sessions in a single round trip to the session store. Returns the sessions
+	// that exist, by session key.
+	AuthenticateSessions(ctx context.Context, sessionKeys []string) (map[string]Session, error)
 
 	// Create a session key for a user. The session key will last 30 days.
 	CreateSession(ctx context.Context, userId uint) (string, error)
 
+	// Create a session key for a user on behalf of another user (the impersonator,
+	// usually an admin). The session key will last for the given duration. Requests
+	// made with the session are flagged as impersonated (see Session.ImpersonatorId).
+	CreateImpersonationSession(ctx context.Context, impersonatorId uint, userId uint, duration time.Duration) (string, error)
+
+	// Invalidate (delete) a single session.
+	// Returns ErrInvalidSessionToken if the session does not exist.
+	InvalidateSession(ctx context.Context, sessionKey string) error
+
 	// Invalidate (delete) all sessions of a user.
 	InvalidateSessions(ctx context.Context, userId uint) error
+
+	// Invalidate all sessions a user has now, without enumerating them. Used
+	// when the user's credentials or permissions change.
+	BumpSessionEpoch(ctx context.Context, userId uint) error
+
+	// Invalidate all sessions a user has now, like BumpSessionEpoch, and
+	// create a new session for them, so that the user who changed their
+	// credentials stays logged in.
+	ReissueSession(ctx context.Context, userId uint) (string, error)
+
+	// Invalidate all sessions of all users, e.g. after a security incident.
+	BumpGlobalSessionEpoch(ctx context.Context) error
+}
+
+// A LoginGuard is consulted after a user's credentials have been verified. If
+// it returns an error the user is not logged in and the error is returned by
+// LogIn.
+type LoginGuard interface {
+	CheckLogin(ctx context.Context, user *users.User) error
 }
 
 type serviceImpl struct {
 	Db           *gorm.DB
-	Redis        *redis.Client
+	Sessions     SessionStore
+	SessionCache *SessionCache
 	UsersService users.Service
+	Guards       []LoginGuard
+
+	EnumerationProtection users.EnumerationProtection
+
+	// Passwords are compared against this user's hash when the user logging
+	// in doesn't exist or has no password, see AuthenticateUser.
+	dummyUser *users.User
+
+	// Compares a password with a user's hash, users.User.ComparePassword
+	// except in tests
+	comparePassword func(user *users.User, password string) (bool, error)
 }
 
-func NewService(db *gorm.DB, redisDb *redis.Client, usersService users.Service) Service {
+func NewService(
+	db *gorm.DB,
+	sessionStore SessionStore,
+	sessionCache *SessionCache,
+	usersService users.Service,
+	enumerationProtection users.EnumerationProtection,
+	guards ...LoginGuard,
+) Service {
+	dummyUser := &users.User{}
+	err := dummyUser.SetPassword("dummy password")
+	if err != nil {
+		panic(err)
+	}
+
 	return &serviceImpl{
 		Db:           db,
-		Redis:        redisDb,
+		Sessions:     sessionStore,
+		SessionCache: sessionCache,
 		UsersService: usersService,
+		Guards:       guards,
+		dummyUser:    dummyUser,
+
+		comparePassword: (*users.User).ComparePassword,
+
+		EnumerationProtection: enumerationProtection,
 	}
 }
 
-func (s *serviceImpl) AuthenticateUser(ctx context.Context, authUser LoginDto) (*users.User, error) {
+func (s *serviceImpl) AuthenticateUser(ctx context.Context, authUser LoginDto) (*users.User, string, error) {
 	logger := log.FromContext(ctx).
-		WithField("username", authUser.UsernameOrEmail)
+		WithField("usernameOrEmail", authUser.UsernameOrEmail)
 
 	logger.Debug("Authenticating user")
-	logger.Debug("Searching for user")
 
 	user, err := s.UsersService.FindUserByUsernameOrEmail(ctx, authUser.UsernameOrEmail)
-	if err != nil {
+	if errors.Is(err, users.ErrUserNotFound) {
+		// Hash the password anyway, otherwise the faster response would
+		// reveal that the username/email isn't registered.
+		_, _ = s.comparePassword(s.dummyUser, authUser.Password)
+
+		logger.Debug("User not found")
+
+		return nil, "", s.credentialsError(users.ErrUserNotFound)
+	} else if err != nil {
 		logger.WithError(err).Error("Failed to authenticate user")
+
+		return nil, "", err
+	}
+
+	if user.PasswordHash == "" {
+		// Users that signed up with an oauth provider have no password
+		_, _ = s.comparePassword(s.dummyUser, authUser.Password)
+
+		logger.Debug("User has no password")
+
+		return nil, "", s.credentialsError(ErrWrongPassword)
 	}
 
 	logger.Debug("Comparing passwords")
 
-	passwordMatch, err := user.ComparePassword(authUser.Password)
+	passwordMatch, err := s.comparePassword(user, authUser.Password)
 	if err != nil {
 		logger.WithError(err).Error("Error comparing passwords")
 
-		return nil, err
+		return nil, "", err
 	} else if passwordMatch {
 		logger.Debug("Passwords match, user authenticated")
 
-		return user, nil
+		sessionKey, err := s.LogIn(ctx, user)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return user, sessionKey, nil
 	} else {
 		logger.Debug("Wrong password")
 
-		return nil, ErrWrongPassword
+		return nil, "", s.credentialsError(ErrWrongPassword)
+	}
+}
+
+func (s *serviceImpl) LogIn(ctx context.Context, user *users.User) (string, error) {
+	logger := log.FromContext(ctx).WithField("userId", user.ID)
+
+	for _, guard := range s.Guards {
+		err := guard.CheckLogin(ctx, user)
+		if err != nil {
+			logger.WithError(err).Debug("Login rejected by guard")
+
+			return "", err
+		}
+	}
+
+	return s.CreateSession(ctx, user.ID)
+}
+
+// The error to return for a failed login, which hides whether the user
+// exists if enumeration protection is on.
+func (s *serviceImpl) credentialsError(err error) error {
+	if s.EnumerationProtection == users.EnumerationProtectionOff {
+		return err
 	}
+
+	return ErrInvalidCredentials
 }
 
-func (s *serviceImpl) AuthenticateSession(ctx context.Context, sessionKey string) (uint, error) {
+func (s *serviceImpl) AuthenticateSession(ctx context.Context, sessionKey string) (Session, error) {
 	logger := log.FromContext(ctx)
 
-	logger.Debug("Checking for session in redis")
+	if session, ok := s.SessionCache.get(sessionKey); ok {
+		logger.Debug("Session found in cache")
+
+		return session, nil
+	}
 
-	redisKey := sessionRedisKey(sessionKey)
-	userId, err := s.Redis.Get(ctx, redisKey).Int()
+	logger.Debug("Checking for session in the session store")
+
+	sessions, err := s.Sessions.Lookup(ctx, []string{sessionKey})
 	if err != nil {
-		if errors.Is(err, redis.Nil) {
-			logger.Debug("Session does not exist")
+		logger.WithError(err).Error("Failed to check for session in the session store")
 
-			return 0, ErrInvalidSessionToken
-		} else {
-			logger.WithError(err).Error("Failed to check for session in redis")
+		return Session{}, err
+	}
 
-			return 0, err
-		}
+	session, ok := sessions[sessionKey]
+	if !ok {
+		logger.Debug("Session does not exist")
+
+		return Session{}, ErrInvalidSessionToken
 	}
 
 	logger.Debug("Session is valid")
 
-	return uint(userId), nil
+	s.SessionCache.add(session)
+
+	return session, nil
+}
+
+func (s *serviceImpl) AuthenticateSessions(ctx context.Context, sessionKeys []string) (map[string]Session, error) {
+	logger := log.FromContext(ctx).WithField("count", len(sessionKeys))
+
+	sessions := make(map[string]Session, len(sessionKeys))
+	var uncachedKeys []string
+
+	for _, sessionKey := range sessionKeys {
+		if session, ok := s.SessionCache.get(sessionKey); ok {
+			sessions[sessionKey] = session
+		} else {
+			uncachedKeys = append(uncachedKeys, sessionKey)
+		}
+	}
+
+	if len(uncachedKeys) == 0 {
+		return sessions, nil
+	}
+
+	found, err := s.Sessions.Lookup(ctx, uncachedKeys)
+	if err != nil {
+		logger.WithError(err).Error("Failed to check for sessions in the session store")
+
+		return nil, err
+	}
+
+	for sessionKey, session := range found {
+		sessions[sessionKey] = session
+		s.SessionCache.add(session)
+	}
+
+	logger.Debugf("%d sessions are valid", len(sessions))
+
+	return sessions, nil
 }
 
 func (s *serviceImpl) CreateSession(ctx context.Context, userId uint) (string, error) {
+	// 1 month
+	return s.storeSession(ctx, userId, 0, time.Hour*24*30)
+}
+
+func (s *serviceImpl) CreateImpersonationSession(
+	ctx context.Context,
+	impersonatorId uint,
+	userId uint,
+	duration time.Duration,
+) (string, error) {
+	return s.storeSession(ctx, userId, impersonatorId, duration)
+}
+
+// Create a session key for a user and store it. If impersonatorId is not 0
+// the session is flagged as impersonated by that user.
+func (s *serviceImpl) storeSession(ctx context.Context, userId uint, impersonatorId uint, keyDuration time.Duration) (string, error) {
 	logger := log.FromContext(ctx)
 
 	logger.
 		WithField("userId", userId).
+		WithField("impersonatorId", impersonatorId).
 		Debug("Creating session key")
 
 	// Using uuid is as session key is safe here because it uses the rand
@@ -120,30 +304,15 @@ func (s *serviceImpl) CreateSession(ctx context.Context, userId uint) (string, e
 		return "", err
 	}
 
-	// 1 month
-	keyDuration := time.Hour * 24 * 30
-
-	// Do everything in a transaction so that we don't end up
-	// with a corrupted state.
-	err = s.Redis.Watch(ctx, func(tx *redis.Tx) error {
-		// Create the session token's key
-		err = s.Redis.Set(ctx, sessionRedisKey(sessionKey.String()), userId, keyDuration).Err()
-		if err != nil {
-			return err
-		}
-
-		// Add the session token to the user's session token inverted index. This inverted
-		// index exists so that we can find all active sessions of a user and delete them.
-		// Take a look at InvalidateSessions.
-		err = s.Redis.SAdd(ctx, sessionInvertedIndexRedisKey(userId), sessionKey).Err()
-		if err != nil {
-			return err
-		}
+	session := Session{
+		token:          sessionKey.String(),
+		userId:         userId,
+		impersonatorId: impersonatorId,
+	}
 
-		return nil
-	})
+	err = s.Sessions.Create(ctx, session, keyDuration)
 	if err != nil {
-		logger.WithError(err).Error("Failed to store session key: redis transaction failed")
+		logger.WithError(err).Error("Failed to store session key")
 
 		return "", err
 	}
@@ -151,55 +320,46 @@ func (s *serviceImpl) CreateSession(ctx context.Context, userId uint) (string, e
 	return sessionKey.String(), nil
 }
 
-func (s *serviceImpl) InvalidateSessions(ctx context.Context, userId uint) error {
-	logger := log.FromContext(ctx).WithField("userId", userId)
+func (s *serviceImpl) InvalidateSession(ctx context.Context, sessionKey string) error {
+	logger := log.FromContext(ctx)
 
-	logger.Debug("Invalidating all sessions of user")
+	logger.Debug("Invalidating session")
 
-	var sessionsSet []string
+	err := s.Sessions.Delete(ctx, sessionKey)
+	if errors.Is(err, ErrInvalidSessionToken) {
+		return err
+	} else if err != nil {
+		logger.WithError(err).Error("Failed to delete session")
 
-	// Get all session tokens of the user by getting the user's
-	// sessions inverted index. It's basically a set that contains
-	// all of the user's sessions.
-	redisKey := sessionInvertedIndexRedisKey(userId)
-	err := s.Redis.GetSet(ctx, redisKey, &sessionsSet).Err()
-	if err != nil {
-		logger.WithError(err).Error("Failed to get all of a user's session tokens")
 		return err
 	}
 
-	// Convert the session tokens (which are just UUIDs) into
-	// their respective redis keys so that we can delete them.
-	// E.g.: convert
-	//  "2c816d07-9499-4907-8ea3-1785dfa0f9a0"
-	// into
-	//  "session:2c816d07-9499-4907-8ea3-1785dfa0f9a0:user.id"
-	for i, key := range sessionsSet {
-		sessionsSet[i] = sessionRedisKey(key)
+	err = s.SessionCache.invalidate(ctx, sessionKey)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to publish session invalidation")
 	}
 
-	// Delete the session token keys and the user's
-	// sessions inverted index.
-	keysToDelete := append([]string{}, sessionsSet...)
-	keysToDelete = append(keysToDelete, redisKey)
+	return nil
+}
+
+func (s *serviceImpl) InvalidateSessions(ctx context.Context, userId uint) error {
+	logger := log.FromContext(ctx).WithField("userId", userId)
 
-	err = s.Redis.Del(ctx, keysToDelete...).Err()
+	logger.Debug("Invalidating all sessions of user")
+
+	sessionKeys, err := s.Sessions.DeleteUser(ctx, userId)
 	if err != nil {
-		logger.WithError(err).Error("Failed to delete session token keys")
+		logger.WithError(err).Error("Failed to delete the user's sessions")
+
 		return err
 	}
 
-	return nil
-}
-
-// Maps a session key to a user id.
-func sessionRedisKey(sessionKey string) string {
-	return fmt.Sprintf("session:%s:user.id", sessionKey)
-}
+	err = s.SessionCache.invalidate(ctx, sessionKeys...)
+	if err != nil {
+		// The sessions are deleted, other server instances will stop
+		// accepting them once their cache entries expire.
+		logger.WithError(err).Warn("Failed to publish session invalidations")
+	}
 
-// Maps a user id to a set of session keys.
-//
-// It's an inverted index of sessionRedisKey.
-func sessionInvertedIndexRedisKey(userId uint) string {
-	return fmt.Sprintf("user:%d:session.keys", userId)
+	return nil
 }
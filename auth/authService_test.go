@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/users/mocks"
+	"testing"
+)
+
+// An auth service without session store that records the users whose hash
+// each password is compared with.
+func newTestService(usersService users.Service, protection users.EnumerationProtection) (*serviceImpl, *[]*users.User) {
+	service := NewService(nil, nil, nil, usersService, protection).(*serviceImpl)
+
+	compared := &[]*users.User{}
+	service.comparePassword = func(user *users.User, password string) (bool, error) {
+		*compared = append(*compared, user)
+
+		return user.ComparePassword(password)
+	}
+
+	return service, compared
+}
+
+func TestAuthenticateUserNotFound(t *testing.T) {
+	tests := []struct {
+		protection users.EnumerationProtection
+		err        error
+	}{
+		{users.EnumerationProtectionOff, users.ErrUserNotFound},
+		{users.EnumerationProtectionLogin, ErrInvalidCredentials},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.protection), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usersService := mocks.NewMockService(ctrl)
+			usersService.EXPECT().
+				FindUserByUsernameOrEmail(gomock.Any(), "alice").
+				Return(nil, users.ErrUserNotFound)
+
+			service, compared := newTestService(usersService, test.protection)
+
+			user, sessionKey, err := service.AuthenticateUser(context.Background(), LoginDto{
+				UsernameOrEmail: "alice",
+				Password:        "password",
+			})
+
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+
+			if user != nil || sessionKey != "" {
+				t.Errorf("expected no user nor session, got %v and %q", user, sessionKey)
+			}
+
+			// Without the dummy compare, unknown usernames would be answered
+			// faster than wrong passwords
+			if len(*compared) != 1 || (*compared)[0] != service.dummyUser {
+				t.Errorf("expected the password to be compared with the dummy user's, compared %d hashes", len(*compared))
+			}
+		})
+	}
+}
+
+func TestAuthenticateUserWithoutPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	usersService := mocks.NewMockService(ctrl)
+	usersService.EXPECT().
+		FindUserByUsernameOrEmail(gomock.Any(), "alice").
+		Return(&users.User{Username: "alice"}, nil)
+
+	service, compared := newTestService(usersService, users.EnumerationProtectionOff)
+
+	_, _, err := service.AuthenticateUser(context.Background(), LoginDto{
+		UsernameOrEmail: "alice",
+		Password:        "",
+	})
+
+	if !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("expected ErrWrongPassword, got %v", err)
+	}
+
+	if len(*compared) != 1 || (*compared)[0] != service.dummyUser {
+		t.Errorf("expected the password to be compared with the dummy user's, compared %d hashes", len(*compared))
+	}
+}
+
+func TestAuthenticateUserWrongPassword(t *testing.T) {
+	tests := []struct {
+		protection users.EnumerationProtection
+		err        error
+	}{
+		{users.EnumerationProtectionOff, ErrWrongPassword},
+		{users.EnumerationProtectionLogin, ErrInvalidCredentials},
+	}
+
+	alice := &users.User{Username: "alice"}
+	err := alice.SetPassword("right password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.protection), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			usersService := mocks.NewMockService(ctrl)
+			usersService.EXPECT().
+				FindUserByUsernameOrEmail(gomock.Any(), "alice").
+				Return(alice, nil)
+
+			service, compared := newTestService(usersService, test.protection)
+
+			user, sessionKey, err := service.AuthenticateUser(context.Background(), LoginDto{
+				UsernameOrEmail: "alice",
+				Password:        "wrong password",
+			})
+
+			if !errors.Is(err, test.err) {
+				t.Errorf("expected %v, got %v", test.err, err)
+			}
+
+			if user != nil || sessionKey != "" {
+				t.Errorf("expected no user nor session, got %v and %q", user, sessionKey)
+			}
+
+			if len(*compared) != 1 || (*compared)[0] != alice {
+				t.Errorf("expected the password to be compared with the user's, compared %d hashes", len(*compared))
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: accountStatus.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/open-collaboration/server/auth"
+	reflect "reflect"
+	time "time"
+)
+
+// MockAccountStatusProvider is a mock of AccountStatusProvider interface
+type MockAccountStatusProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountStatusProviderMockRecorder
+}
+
+// MockAccountStatusProviderMockRecorder is the mock recorder for MockAccountStatusProvider
+type MockAccountStatusProviderMockRecorder struct {
+	mock *MockAccountStatusProvider
+}
+
+// NewMockAccountStatusProvider creates a new mock instance
+func NewMockAccountStatusProvider(ctrl *gomock.Controller) *MockAccountStatusProvider {
+	mock := &MockAccountStatusProvider{ctrl: ctrl}
+	mock.recorder = &MockAccountStatusProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAccountStatusProvider) EXPECT() *MockAccountStatusProviderMockRecorder {
+	return m.recorder
+}
+
+// GetAccountStatus mocks base method
+func (m *MockAccountStatusProvider) GetAccountStatus(ctx context.Context, userId uint) (auth.AccountStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountStatus", ctx, userId)
+	ret0, _ := ret[0].(auth.AccountStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountStatus indicates an expected call of GetAccountStatus
+func (mr *MockAccountStatusProviderMockRecorder) GetAccountStatus(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountStatus", reflect.TypeOf((*MockAccountStatusProvider)(nil).GetAccountStatus), ctx, userId)
+}
+
+// CheckPostingCooldown mocks base method
+func (m *MockAccountStatusProvider) CheckPostingCooldown(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPostingCooldown", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPostingCooldown indicates an expected call of CheckPostingCooldown
+func (mr *MockAccountStatusProviderMockRecorder) CheckPostingCooldown(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPostingCooldown", reflect.TypeOf((*MockAccountStatusProvider)(nil).CheckPostingCooldown), ctx, userId)
+}
+
+// StartPostingCooldown mocks base method
+func (m *MockAccountStatusProvider) StartPostingCooldown(ctx context.Context, userId uint, cooldown time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartPostingCooldown", ctx, userId, cooldown)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartPostingCooldown indicates an expected call of StartPostingCooldown
+func (mr *MockAccountStatusProviderMockRecorder) StartPostingCooldown(ctx, userId, cooldown interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartPostingCooldown", reflect.TypeOf((*MockAccountStatusProvider)(nil).StartPostingCooldown), ctx, userId, cooldown)
+}
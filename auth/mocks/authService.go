@@ -0,0 +1,236 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: authService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/open-collaboration/server/auth"
+	users "github.com/open-collaboration/server/users"
+	reflect "reflect"
+	time "time"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateUser mocks base method
+func (m *MockService) AuthenticateUser(ctx context.Context, authUser auth.LoginDto) (*users.User, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateUser", ctx, authUser)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuthenticateUser indicates an expected call of AuthenticateUser
+func (mr *MockServiceMockRecorder) AuthenticateUser(ctx, authUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateUser", reflect.TypeOf((*MockService)(nil).AuthenticateUser), ctx, authUser)
+}
+
+// LogIn mocks base method
+func (m *MockService) LogIn(ctx context.Context, user *users.User) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogIn", ctx, user)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LogIn indicates an expected call of LogIn
+func (mr *MockServiceMockRecorder) LogIn(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogIn", reflect.TypeOf((*MockService)(nil).LogIn), ctx, user)
+}
+
+// AuthenticateSession mocks base method
+func (m *MockService) AuthenticateSession(ctx context.Context, sessionKey string) (auth.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateSession", ctx, sessionKey)
+	ret0, _ := ret[0].(auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateSession indicates an expected call of AuthenticateSession
+func (mr *MockServiceMockRecorder) AuthenticateSession(ctx, sessionKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateSession", reflect.TypeOf((*MockService)(nil).AuthenticateSession), ctx, sessionKey)
+}
+
+// AuthenticateSessions mocks base method
+func (m *MockService) AuthenticateSessions(ctx context.Context, sessionKeys []string) (map[string]auth.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateSessions", ctx, sessionKeys)
+	ret0, _ := ret[0].(map[string]auth.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateSessions indicates an expected call of AuthenticateSessions
+func (mr *MockServiceMockRecorder) AuthenticateSessions(ctx, sessionKeys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateSessions", reflect.TypeOf((*MockService)(nil).AuthenticateSessions), ctx, sessionKeys)
+}
+
+// CreateSession mocks base method
+func (m *MockService) CreateSession(ctx context.Context, userId uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession
+func (mr *MockServiceMockRecorder) CreateSession(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockService)(nil).CreateSession), ctx, userId)
+}
+
+// CreateImpersonationSession mocks base method
+func (m *MockService) CreateImpersonationSession(ctx context.Context, impersonatorId, userId uint, duration time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImpersonationSession", ctx, impersonatorId, userId, duration)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateImpersonationSession indicates an expected call of CreateImpersonationSession
+func (mr *MockServiceMockRecorder) CreateImpersonationSession(ctx, impersonatorId, userId, duration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImpersonationSession", reflect.TypeOf((*MockService)(nil).CreateImpersonationSession), ctx, impersonatorId, userId, duration)
+}
+
+// InvalidateSession mocks base method
+func (m *MockService) InvalidateSession(ctx context.Context, sessionKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateSession", ctx, sessionKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateSession indicates an expected call of InvalidateSession
+func (mr *MockServiceMockRecorder) InvalidateSession(ctx, sessionKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateSession", reflect.TypeOf((*MockService)(nil).InvalidateSession), ctx, sessionKey)
+}
+
+// InvalidateSessions mocks base method
+func (m *MockService) InvalidateSessions(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateSessions", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateSessions indicates an expected call of InvalidateSessions
+func (mr *MockServiceMockRecorder) InvalidateSessions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateSessions", reflect.TypeOf((*MockService)(nil).InvalidateSessions), ctx, userId)
+}
+
+// BumpSessionEpoch mocks base method
+func (m *MockService) BumpSessionEpoch(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BumpSessionEpoch", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BumpSessionEpoch indicates an expected call of BumpSessionEpoch
+func (mr *MockServiceMockRecorder) BumpSessionEpoch(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BumpSessionEpoch", reflect.TypeOf((*MockService)(nil).BumpSessionEpoch), ctx, userId)
+}
+
+// ReissueSession mocks base method
+func (m *MockService) ReissueSession(ctx context.Context, userId uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReissueSession", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReissueSession indicates an expected call of ReissueSession
+func (mr *MockServiceMockRecorder) ReissueSession(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReissueSession", reflect.TypeOf((*MockService)(nil).ReissueSession), ctx, userId)
+}
+
+// BumpGlobalSessionEpoch mocks base method
+func (m *MockService) BumpGlobalSessionEpoch(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BumpGlobalSessionEpoch", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BumpGlobalSessionEpoch indicates an expected call of BumpGlobalSessionEpoch
+func (mr *MockServiceMockRecorder) BumpGlobalSessionEpoch(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BumpGlobalSessionEpoch", reflect.TypeOf((*MockService)(nil).BumpGlobalSessionEpoch), ctx)
+}
+
+// MockLoginGuard is a mock of LoginGuard interface
+type MockLoginGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginGuardMockRecorder
+}
+
+// MockLoginGuardMockRecorder is the mock recorder for MockLoginGuard
+type MockLoginGuardMockRecorder struct {
+	mock *MockLoginGuard
+}
+
+// NewMockLoginGuard creates a new mock instance
+func NewMockLoginGuard(ctrl *gomock.Controller) *MockLoginGuard {
+	mock := &MockLoginGuard{ctrl: ctrl}
+	mock.recorder = &MockLoginGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLoginGuard) EXPECT() *MockLoginGuardMockRecorder {
+	return m.recorder
+}
+
+// CheckLogin mocks base method
+func (m *MockLoginGuard) CheckLogin(ctx context.Context, user *users.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckLogin", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckLogin indicates an expected call of CheckLogin
+func (mr *MockLoginGuardMockRecorder) CheckLogin(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckLogin", reflect.TypeOf((*MockLoginGuard)(nil).CheckLogin), ctx, user)
+}
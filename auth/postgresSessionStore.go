@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"context"
+	"github.com/apex/log"
+	"gorm.io/gorm"
+	"time"
+)
+
+// How often expired sessions and epochs are deleted. Expired sessions are
+// ignored until then.
+const sessionPruneInterval = 10 * time.Minute
+
+// Stores sessions in the sessions table and session epochs in the
+// session_epochs table, for installs without Redis. The global epoch is the
+// epoch of user 0.
+type postgresSessionStore struct {
+	Db *gorm.DB
+}
+
+type sessionRow struct {
+	Token          string
+	UserId         uint
+	ImpersonatorId *uint
+}
+
+func NewPostgresSessionStore(db *gorm.DB) *postgresSessionStore {
+	return &postgresSessionStore{Db: db}
+}
+
+func (s *postgresSessionStore) Create(ctx context.Context, session Session, ttl time.Duration) error {
+	var impersonatorId *uint
+	if session.impersonatorId != 0 {
+		impersonatorId = &session.impersonatorId
+	}
+
+	return s.Db.WithContext(ctx).Exec(`
+		INSERT INTO sessions (token, user_id, impersonator_id, created_at, expires_at)
+		VALUES (?, ?, ?, now(), now() + ?::BIGINT * INTERVAL '1 millisecond')`,
+		session.token, session.userId, impersonatorId, ttl.Milliseconds(),
+	).Error
+}
+
+func (s *postgresSessionStore) Lookup(ctx context.Context, sessionKeys []string) (map[string]Session, error) {
+	var rows []sessionRow
+	err := s.Db.WithContext(ctx).
+		Raw(`
+			SELECT s.token, s.user_id, s.impersonator_id
+			FROM sessions s
+			LEFT JOIN session_epochs user_epoch ON user_epoch.user_id = s.user_id
+			LEFT JOIN session_epochs global_epoch ON global_epoch.user_id = 0
+			WHERE s.token IN ?
+			AND s.expires_at > now()
+			AND (user_epoch.epoch IS NULL OR s.created_at > user_epoch.epoch)
+			AND (global_epoch.epoch IS NULL OR s.created_at > global_epoch.epoch)`,
+			sessionKeys,
+		).
+		Scan(&rows).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string]Session, len(rows))
+	for _, row := range rows {
+		session := Session{
+			token:  row.Token,
+			userId: row.UserId,
+		}
+
+		if row.ImpersonatorId != nil {
+			session.impersonatorId = *row.ImpersonatorId
+		}
+
+		sessions[row.Token] = session
+	}
+
+	return sessions, nil
+}
+
+func (s *postgresSessionStore) Delete(ctx context.Context, sessionKey string) error {
+	result := s.Db.WithContext(ctx).Exec("DELETE FROM sessions WHERE token = ? AND expires_at > now()", sessionKey)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrInvalidSessionToken
+	}
+
+	return nil
+}
+
+func (s *postgresSessionStore) DeleteUser(ctx context.Context, userId uint) ([]string, error) {
+	var sessionKeys []string
+	err := s.Db.WithContext(ctx).
+		Raw("DELETE FROM sessions WHERE user_id = ? RETURNING token", userId).
+		Scan(&sessionKeys).
+		Error
+
+	return sessionKeys, err
+}
+
+func (s *postgresSessionStore) BumpEpoch(ctx context.Context, userId uint) error {
+	return s.Db.WithContext(ctx).Exec(`
+		INSERT INTO session_epochs (user_id, epoch) VALUES (?, now())
+		ON CONFLICT (user_id) DO UPDATE SET epoch = excluded.epoch`,
+		userId,
+	).Error
+}
+
+// Delete expired sessions, and epochs older than any session, periodically
+// until ctx is done. Should be run in its own goroutine.
+func (s *postgresSessionStore) Run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	ticker := time.NewTicker(sessionPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := s.Db.WithContext(ctx).Exec("DELETE FROM sessions WHERE expires_at <= now()").Error
+		if err == nil {
+			err = s.Db.WithContext(ctx).
+				Exec("DELETE FROM session_epochs WHERE epoch < now() - ?::BIGINT * INTERVAL '1 millisecond'", sessionEpochDuration.Milliseconds()).
+				Error
+		}
+
+		if err != nil && ctx.Err() == nil {
+			logger.WithError(err).Warn("Failed to delete expired sessions")
+		}
+	}
+}
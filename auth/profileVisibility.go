@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"github.com/open-collaboration/server/users"
+	"net/http"
+)
+
+// Helper function to get a user whose profile a route handler is about to
+// show. Returns the user and the id of the user viewing the profile, 0 for
+// anonymous visitors.
+//
+// Returns users.ErrUserNotFound if the user doesn't exist or hides their
+// profile from the viewer, so that hidden profiles look like missing ones.
+func CheckProfileVisible(r *http.Request, usersService users.Service, userId uint) (*users.User, uint, error) {
+	var viewerId uint
+	session, err := CheckSession(r)
+	if err == nil {
+		viewerId = session.userId
+	}
+
+	user, err := usersService.GetUser(r.Context(), userId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !user.ProfileVisibleTo(viewerId) {
+		return nil, 0, users.ErrUserNotFound
+	}
+
+	return user, viewerId, nil
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/open-collaboration/server/kv"
+	"strconv"
+	"time"
+)
+
+// Sessions last up to 30 days, after that an epoch is useless.
+const sessionEpochDuration = time.Hour * 24 * 31
+
+// The version of the format of the session keys, see kv.KeySchemaVersion.
+// It's separate from the kv store's so that changing a cache's format
+// doesn't log users out.
+const SessionKeySchemaVersion = 1
+
+type redisSessionStore struct {
+	Redis *redis.Client
+
+	// Prepended to keys, see kv.KeyNamespace
+	namespace string
+}
+
+// Create a session store whose keys start with prefix, like the kv store's
+// (see kv.NewRedisStore).
+func NewRedisSessionStore(redisDb *redis.Client, prefix string) SessionStore {
+	return &redisSessionStore{
+		Redis:     redisDb,
+		namespace: kv.KeyNamespace(prefix, SessionKeySchemaVersion),
+	}
+}
+
+func (s *redisSessionStore) key(key string) string {
+	return s.namespace + key
+}
+
+func (s *redisSessionStore) Create(ctx context.Context, session Session, ttl time.Duration) error {
+	sessionKey := session.token
+
+	// Do everything in a transaction so that we don't end up
+	// with a corrupted state.
+	_, err := s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Create the session token's key
+		pipe.Set(ctx, s.key(sessionRedisKey(sessionKey)), session.userId, ttl)
+
+		// Used to check the session against session epochs, see BumpEpoch
+		pipe.Set(ctx, s.key(sessionCreatedAtRedisKey(sessionKey)), sessionTimestamp(), ttl)
+
+		if session.impersonatorId != 0 {
+			pipe.Set(ctx, s.key(sessionImpersonatorRedisKey(sessionKey)), session.impersonatorId, ttl)
+		}
+
+		// Add the session token to the user's session token inverted index. This inverted
+		// index exists so that we can find all active sessions of a user and delete them.
+		// Take a look at DeleteUser.
+		pipe.SAdd(ctx, s.key(sessionInvertedIndexRedisKey(session.userId)), sessionKey)
+
+		return nil
+	})
+
+	return err
+}
+
+func (s *redisSessionStore) Delete(ctx context.Context, sessionKey string) error {
+	// The session's user is needed to remove the session from the user's
+	// sessions inverted index.
+	value, err := s.Redis.Get(ctx, s.key(sessionRedisKey(sessionKey))).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrInvalidSessionToken
+	} else if err != nil {
+		return err
+	}
+
+	userId, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.key(sessionRedisKey(sessionKey)), s.key(sessionImpersonatorRedisKey(sessionKey)), s.key(sessionCreatedAtRedisKey(sessionKey)))
+		pipe.SRem(ctx, s.key(sessionInvertedIndexRedisKey(uint(userId))), sessionKey)
+
+		return nil
+	})
+
+	return err
+}
+
+func (s *redisSessionStore) DeleteUser(ctx context.Context, userId uint) ([]string, error) {
+	// Get all session tokens of the user by getting the user's
+	// sessions inverted index. It's basically a set that contains
+	// all of the user's sessions.
+	redisKey := s.key(sessionInvertedIndexRedisKey(userId))
+	sessionsSet, err := s.Redis.SMembers(ctx, redisKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert the session tokens (which are just UUIDs) into
+	// their respective redis keys so that we can delete them.
+	// E.g.: convert
+	//  "2c816d07-9499-4907-8ea3-1785dfa0f9a0"
+	// into
+	//  "session:2c816d07-9499-4907-8ea3-1785dfa0f9a0:user.id"
+	keysToDelete := make([]string, 0, len(sessionsSet)*3+1)
+	for _, key := range sessionsSet {
+		keysToDelete = append(keysToDelete, s.key(sessionRedisKey(key)), s.key(sessionImpersonatorRedisKey(key)), s.key(sessionCreatedAtRedisKey(key)))
+	}
+
+	// Delete the session token keys and the user's
+	// sessions inverted index.
+	keysToDelete = append(keysToDelete, redisKey)
+
+	err = s.Redis.Del(ctx, keysToDelete...).Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return sessionsSet, nil
+}
+
+// Looks up sessions and drops the ones created before their user's session
+// epoch or the global session epoch, in a single round trip.
+//
+// KEYS[1] is the global epoch key, ARGV[1] the namespace of keys and the
+// rest of ARGV are the session keys. Returns, for each session key, nil if
+// the session is invalid or its user id and its impersonator id ("" if it's
+// not an impersonation session).
+//
+// The redis keys built here must match sessionRedisKey, sessionImpersonatorRedisKey,
+// sessionCreatedAtRedisKey and sessionEpochRedisKey.
+var lookupSessionsScript = redis.NewScript(`
+local globalEpoch = tonumber(redis.call('GET', KEYS[1])) or 0
+local namespace = ARGV[1]
+local results = {}
+
+for i = 2, #ARGV do
+	local sessionKey = ARGV[i]
+	results[i - 1] = false
+
+	local userId = redis.call('GET', namespace .. 'session:' .. sessionKey .. ':user.id')
+	if userId then
+		local createdAt = tonumber(redis.call('GET', namespace .. 'session:' .. sessionKey .. ':created.at')) or 0
+		local userEpoch = tonumber(redis.call('GET', namespace .. 'user:' .. userId .. ':session.epoch')) or 0
+
+		if createdAt > globalEpoch and createdAt > userEpoch then
+			local impersonatorId = redis.call('GET', namespace .. 'session:' .. sessionKey .. ':impersonator.id') or ''
+			results[i - 1] = {userId, impersonatorId}
+		end
+	end
+end
+
+return results
+`)
+
+func (s *redisSessionStore) Lookup(ctx context.Context, sessionKeys []string) (map[string]Session, error) {
+	args := make([]interface{}, len(sessionKeys)+1)
+	args[0] = s.namespace
+	for i, sessionKey := range sessionKeys {
+		args[i+1] = sessionKey
+	}
+
+	result, err := lookupSessionsScript.Run(ctx, s.Redis, []string{s.key(globalSessionEpochRedisKey)}, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make(map[string]Session, len(sessionKeys))
+	for i, value := range result.([]interface{}) {
+		if value == nil {
+			continue
+		}
+
+		ids := value.([]interface{})
+
+		var impersonatorId interface{}
+		if ids[1].(string) != "" {
+			impersonatorId = ids[1]
+		}
+
+		session, err := parseSession(sessionKeys[i], ids[0], impersonatorId)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions[sessionKeys[i]] = session
+	}
+
+	return sessions, nil
+}
+
+func (s *redisSessionStore) BumpEpoch(ctx context.Context, userId uint) error {
+	redisKey := globalSessionEpochRedisKey
+	if userId != 0 {
+		redisKey = sessionEpochRedisKey(userId)
+	}
+
+	return s.Redis.Set(ctx, s.key(redisKey), sessionTimestamp(), sessionEpochDuration).Err()
+}
+
+// Build a session from the values of its redis keys.
+func parseSession(sessionKey string, userIdValue interface{}, impersonatorIdValue interface{}) (Session, error) {
+	userId, err := strconv.ParseUint(userIdValue.(string), 10, 0)
+	if err != nil {
+		return Session{}, err
+	}
+
+	session := Session{
+		token:  sessionKey,
+		userId: uint(userId),
+	}
+
+	if impersonatorIdValue != nil {
+		impersonatorId, err := strconv.ParseUint(impersonatorIdValue.(string), 10, 0)
+		if err != nil {
+			return Session{}, err
+		}
+
+		session.impersonatorId = uint(impersonatorId)
+	}
+
+	return session, nil
+}
+
+// The current time as a session timestamp, comparable with session epochs.
+func sessionTimestamp() string {
+	return strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+}
+
+// Maps a session key to a user id.
+func sessionRedisKey(sessionKey string) string {
+	return fmt.Sprintf("session:%s:user.id", sessionKey)
+}
+
+// Maps a session key to the id of the user impersonating the session's
+// user. Only exists for impersonation sessions.
+func sessionImpersonatorRedisKey(sessionKey string) string {
+	return fmt.Sprintf("session:%s:impersonator.id", sessionKey)
+}
+
+// Maps a session key to the time (unix milliseconds) the session was created.
+func sessionCreatedAtRedisKey(sessionKey string) string {
+	return fmt.Sprintf("session:%s:created.at", sessionKey)
+}
+
+// Maps a user id to a set of session keys.
+//
+// It's an inverted index of sessionRedisKey.
+func sessionInvertedIndexRedisKey(userId uint) string {
+	return fmt.Sprintf("user:%d:session.keys", userId)
+}
+
+// Maps a user id to the time (unix milliseconds) before which all of the
+// user's sessions are invalid.
+func sessionEpochRedisKey(userId uint) string {
+	return fmt.Sprintf("user:%d:session.epoch", userId)
+}
+
+// The time (unix milliseconds) before which all sessions are invalid.
+const globalSessionEpochRedisKey = "session.epoch"
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/kv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Channel on which session invalidations are published, so
+// that every server instance evicts the sessions from its SessionCache.
+// Messages are "session:<session_key>", "user:<user_id>" (all of a user's
+// sessions) or "all".
+const sessionInvalidationChannel = "session.invalidations"
+
+// An in-process LRU cache of sessions, which saves a session store round
+// trip on most authenticated requests.
+//
+// Entries live for a short TTL. Sessions invalidated by any server instance
+// are evicted through the kv store's pub/sub (see Listen), the TTL bounds how
+// long a session can outlive its invalidation if a message is missed.
+type SessionCache struct {
+	kv       kv.Store
+	capacity int
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
+}
+
+type sessionCacheEntry struct {
+	sessionKey string
+	session    Session
+	expiresAt  time.Time
+}
+
+// Create a session cache holding up to capacity sessions for ttl. A capacity
+// or ttl of 0 disables the cache.
+func NewSessionCache(kvStore kv.Store, capacity int, ttl time.Duration) *SessionCache {
+	return &SessionCache{
+		kv:       kvStore,
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (c *SessionCache) enabled() bool {
+	return c.capacity > 0 && c.ttl > 0
+}
+
+func (c *SessionCache) get(sessionKey string) (Session, bool) {
+	if !c.enabled() {
+		return Session{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[sessionKey]
+	if !ok {
+		return Session{}, false
+	}
+
+	entry := element.Value.(*sessionCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, sessionKey)
+
+		return Session{}, false
+	}
+
+	c.order.MoveToFront(element)
+
+	return entry.session, true
+}
+
+func (c *SessionCache) add(session Session) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &sessionCacheEntry{
+		sessionKey: session.token,
+		session:    session,
+		expiresAt:  time.Now().Add(c.ttl),
+	}
+
+	if element, ok := c.entries[session.token]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+
+		return
+	}
+
+	c.entries[session.token] = c.order.PushFront(entry)
+
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*sessionCacheEntry).sessionKey)
+	}
+}
+
+func (c *SessionCache) evict(sessionKeys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sessionKey := range sessionKeys {
+		if element, ok := c.entries[sessionKey]; ok {
+			c.order.Remove(element)
+			delete(c.entries, sessionKey)
+		}
+	}
+}
+
+// Evict all sessions of a user, or of all users if userId is 0.
+func (c *SessionCache) evictUser(userId uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for sessionKey, element := range c.entries {
+		if userId == 0 || element.Value.(*sessionCacheEntry).session.userId == userId {
+			c.order.Remove(element)
+			delete(c.entries, sessionKey)
+		}
+	}
+}
+
+// Evict sessions from this instance's cache and tell the other instances to
+// evict them too.
+func (c *SessionCache) invalidate(ctx context.Context, sessionKeys ...string) error {
+	c.evict(sessionKeys...)
+
+	for _, sessionKey := range sessionKeys {
+		err := c.publish(ctx, "session:"+sessionKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Evict all sessions of a user from every instance's cache.
+func (c *SessionCache) invalidateUser(ctx context.Context, userId uint) error {
+	c.evictUser(userId)
+
+	return c.publish(ctx, fmt.Sprintf("user:%d", userId))
+}
+
+// Evict all sessions from every instance's cache.
+func (c *SessionCache) invalidateAll(ctx context.Context) error {
+	c.evictUser(0)
+
+	return c.publish(ctx, "all")
+}
+
+func (c *SessionCache) publish(ctx context.Context, message string) error {
+	if !c.enabled() {
+		return nil
+	}
+
+	return c.kv.Publish(ctx, sessionInvalidationChannel, message)
+}
+
+// Handle a message of the invalidation channel.
+func (c *SessionCache) handleInvalidation(message string) {
+	if message == "all" {
+		c.evictUser(0)
+	} else if strings.HasPrefix(message, "user:") {
+		userId, err := strconv.ParseUint(strings.TrimPrefix(message, "user:"), 10, 0)
+		if err == nil && userId != 0 {
+			c.evictUser(uint(userId))
+		}
+	} else {
+		c.evict(strings.TrimPrefix(message, "session:"))
+	}
+}
+
+// Evict the sessions invalidated by other server instances until ctx is done.
+// Should be run in its own goroutine.
+func (c *SessionCache) Listen(ctx context.Context) {
+	if !c.enabled() {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	messages, err := c.kv.Subscribe(ctx, sessionInvalidationChannel)
+	if err != nil {
+		logger.WithError(err).Error("Failed to subscribe to session invalidations")
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				if ctx.Err() == nil {
+					logger.Warn("Session invalidation subscription closed")
+				}
+
+				return
+			}
+
+			c.handleInvalidation(message)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"github.com/apex/log"
+	"time"
+)
+
+func (s *serviceImpl) BumpSessionEpoch(ctx context.Context, userId uint) error {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	logger.Info("Bumping user's session epoch")
+
+	err := s.Sessions.BumpEpoch(ctx, userId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to bump session epoch")
+
+		return err
+	}
+
+	err = s.SessionCache.invalidateUser(ctx, userId)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to publish session invalidation")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ReissueSession(ctx context.Context, userId uint) (string, error) {
+	err := s.BumpSessionEpoch(ctx, userId)
+	if err != nil {
+		return "", err
+	}
+
+	// Sessions must be created after the epoch, which Redis stores in
+	// milliseconds
+	time.Sleep(time.Millisecond)
+
+	return s.CreateSession(ctx, userId)
+}
+
+func (s *serviceImpl) BumpGlobalSessionEpoch(ctx context.Context) error {
+	logger := log.FromContext(ctx)
+
+	logger.Warn("Bumping global session epoch")
+
+	err := s.Sessions.BumpEpoch(ctx, 0)
+	if err != nil {
+		logger.WithError(err).Error("Failed to bump global session epoch")
+
+		return err
+	}
+
+	err = s.SessionCache.invalidateAll(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to publish session invalidation")
+	}
+
+	return nil
+}
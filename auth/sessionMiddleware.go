@@ -3,23 +3,61 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/apex/log"
 	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/users"
 	"net/http"
 )
 
 type Session struct {
 	token  string
 	userId uint
+
+	// The user impersonating userId, 0 if the session is not
+	// an impersonation session.
+	impersonatorId uint
+}
+
+// The id of the user that owns the session.
+func (s Session) UserId() uint {
+	return s.userId
+}
+
+// The id of the user impersonating the session's user and whether the
+// session is an impersonation session.
+func (s Session) ImpersonatorId() (uint, bool) {
+	return s.impersonatorId, s.impersonatorId != 0
+}
+
+// The session's token.
+func (s Session) Token() string {
+	return s.token
 }
 
 var ErrUnauthenticated = errors.New("unauthenticated")
+var ErrForbidden = errors.New("forbidden")
+
+// What SessionMiddleware does when a session can't be checked because
+// the session store (redis) is failing.
+type SessionFailurePolicy string
+
+const (
+	// Respond with a 503.
+	SessionFailureReject SessionFailurePolicy = "reject"
+
+	// Handle the request as if it had no session. Public routes keep
+	// working while routes that require a session respond with a 401.
+	SessionFailureAnonymous SessionFailurePolicy = "anonymous"
+)
 
 // Checks the incoming request for a session token. If the session token
 // exists and is valid, a session is added to the request's context.
 // You can get the session with
 //	r.Context().Value(Session{})
-func SessionMiddleware(authService Service) mux.MiddlewareFunc {
+//
+// failurePolicy decides what happens if the session can't be checked.
+func SessionMiddleware(authService Service, failurePolicy SessionFailurePolicy) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			logger := log.FromContext(r.Context())
@@ -32,9 +70,12 @@ func SessionMiddleware(authService Service) mux.MiddlewareFunc {
 			if err != nil {
 				if !errors.Is(err, http.ErrNoCookie) && !errors.Is(err, ErrInvalidSessionToken) {
 					logger.WithError(err).Error("Failed to get request's session")
-					w.WriteHeader(http.StatusInternalServerError)
 
-					return
+					if failurePolicy != SessionFailureAnonymous {
+						w.WriteHeader(http.StatusServiceUnavailable)
+
+						return
+					}
 				}
 			} else {
 				logger.Debug("Session token found")
@@ -55,15 +96,7 @@ func getSessionFromRequest(r *http.Request, authService Service) (Session, error
 		return Session{}, err
 	}
 
-	userId, err := authService.AuthenticateSession(r.Context(), sessionToken.Value)
-	if err != nil {
-		return Session{}, err
-	}
-
-	return Session{
-		token:  sessionToken.Value,
-		userId: userId,
-	}, nil
+	return authService.AuthenticateSession(r.Context(), sessionToken.Value)
 }
 
 // Helper function to check if a request contains a valid session. Returns
@@ -78,3 +111,48 @@ func CheckSession(r *http.Request) (Session, error) {
 
 	return session.(Session), nil
 }
+
+// Add a session of the user to the request's context, as SessionMiddleware
+// does, e.g. to call route handlers in tests without a session store.
+func WithSession(r *http.Request, token string, userId uint) *http.Request {
+	session := Session{token: token, userId: userId}
+
+	return r.WithContext(context.WithValue(r.Context(), Session{}, session))
+}
+
+// Helper function to check if a request contains a valid session that belongs
+// to a user with at least the given role. Returns ErrUnauthenticated if there
+// is no session and ErrForbidden if the user's role does not include role.
+func CheckRole(r *http.Request, usersService users.Service, role users.Role) (Session, error) {
+	session, err := CheckSession(r)
+	if err != nil {
+		return Session{}, err
+	}
+
+	user, err := usersService.GetUser(r.Context(), session.userId)
+	if err != nil {
+		return Session{}, err
+	}
+
+	if !user.Role.Includes(role) {
+		log.FromContext(r.Context()).
+			WithField("userId", session.userId).
+			WithField("role", role).
+			Debug("User does not have the required role")
+
+		return Session{}, ErrForbidden
+	}
+
+	return session, nil
+}
+
+// Set the session cookie on a response. The cookie's path is / so that it's
+// sent to all routes, not only the ones next to the route that set it.
+func SetSessionCookie(writer http.ResponseWriter, sessionToken string) {
+	writer.Header().Set("Set-Cookie", fmt.Sprintf("sessionToken=%s; Path=/", sessionToken))
+}
+
+// Expire the session cookie.
+func ClearSessionCookie(writer http.ResponseWriter) {
+	writer.Header().Set("Set-Cookie", "sessionToken=; Path=/; Max-Age=0")
+}
@@ -0,0 +1,30 @@
+package auth
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"time"
+)
+
+// Persists sessions and session epochs, in Redis or in Postgres. See
+// docs/redis.md for how they're stored.
+type SessionStore interface {
+	// Store a new session, which expires after ttl.
+	Create(ctx context.Context, session Session, ttl time.Duration) error
+
+	// Get the sessions that exist and weren't invalidated by a session epoch,
+	// by session key.
+	Lookup(ctx context.Context, sessionKeys []string) (map[string]Session, error)
+
+	// Delete a session.
+	// Returns ErrInvalidSessionToken if the session does not exist.
+	Delete(ctx context.Context, sessionKey string) error
+
+	// Delete all sessions of a user. Returns their session keys.
+	DeleteUser(ctx context.Context, userId uint) ([]string, error)
+
+	// Invalidate the sessions created until now of a user, or of all users
+	// if userId is 0.
+	BumpEpoch(ctx context.Context, userId uint) error
+}
@@ -0,0 +1,406 @@
+// Package backup creates, verifies and restores backups of an installation:
+// a Postgres dump, a Redis snapshot (unless the installation stores
+// everything in Postgres) and a manifest of the media the data links to.
+//
+// The dump and snapshot are made by pg_dump and redis-cli, which have to be
+// installed where the backup commands run, and restored by pg_restore.
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"gorm.io/gorm"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var ErrChecksumMismatch = errors.New("backup file doesn't match its checksum")
+var ErrCountMismatch = errors.New("restored table doesn't have as many rows as when backed up")
+
+// Files of a backup directory.
+const (
+	ManifestFile = "manifest.json"
+	PostgresFile = "postgres.dump"
+	RedisFile    = "redis.rdb"
+	MediaFile    = "media.json"
+)
+
+// Describes a backup, written last so that a backup directory without a
+// manifest is known to be incomplete.
+type Manifest struct {
+	CreatedAt time.Time `json:"createdAt"`
+
+	// The id of the last database migration applied when the backup was made
+	SchemaVersion string `json:"schemaVersion"`
+
+	// Checksums of the backup's files, by file name
+	Files map[string]FileInfo `json:"files"`
+
+	// Rows of each table when the dump was made, to verify restores
+	RowCounts map[string]int64 `json:"rowCounts"`
+}
+
+type FileInfo struct {
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// Media isn't stored by the server, projects and collections link to images
+// hosted elsewhere. The manifest lists them so that self-hosters can mirror
+// them along with the backup.
+type MediaEntry struct {
+	Table  string `json:"table"`
+	Id     uint   `json:"id"`
+	Column string `json:"column"`
+	Url    string `json:"url"`
+}
+
+// Columns of media urls, by table.
+var mediaColumns = map[string]string{
+	"projects":    "cover_image_url",
+	"collections": "banner_image_url",
+}
+
+// Where to back up from or restore to.
+type Target struct {
+	Db *gorm.DB
+
+	// libpq connection string of the database, for pg_dump and pg_restore
+	PostgresDsn string
+
+	// Empty if there's no redis, see app.Config.KeyValueStore
+	RedisHost string
+	RedisPort string
+}
+
+// Back up target into dir, which is created if it doesn't exist.
+func Create(ctx context.Context, target Target, dir string) (Manifest, error) {
+	logger := log.FromContext(ctx).WithField("dir", dir)
+
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	manifest := Manifest{
+		CreatedAt: time.Now(),
+		Files:     map[string]FileInfo{},
+	}
+
+	var media []MediaEntry
+
+	// The rows are counted, the media listed and the database dumped in the
+	// same snapshot, which the transaction exports to pg_dump
+	tx := target.Db.WithContext(ctx).Begin(&sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if tx.Error != nil {
+		return Manifest{}, tx.Error
+	}
+	defer tx.Rollback()
+
+	var snapshot string
+	result := tx.Raw("SELECT pg_export_snapshot()").Scan(&snapshot)
+	if result.Error != nil {
+		return Manifest{}, result.Error
+	}
+
+	manifest.SchemaVersion, err = schemaVersion(tx)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	manifest.RowCounts, err = rowCounts(tx)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	media, err = listMedia(tx)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	logger.Info("Dumping postgres")
+
+	err = run(ctx, "pg_dump", "--format=custom", "--no-owner", "--snapshot="+snapshot, "--file="+filepath.Join(dir, PostgresFile), "--dbname="+target.PostgresDsn)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	tx.Rollback()
+
+	files := []string{PostgresFile, MediaFile}
+
+	if target.RedisHost != "" {
+		logger.Info("Saving a redis snapshot")
+
+		err = run(ctx, "redis-cli", "-h", target.RedisHost, "-p", target.RedisPort, "--rdb", filepath.Join(dir, RedisFile))
+		if err != nil {
+			return Manifest{}, err
+		}
+
+		files = append(files, RedisFile)
+	}
+
+	err = writeJson(filepath.Join(dir, MediaFile), media)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	for _, name := range files {
+		manifest.Files[name], err = checksum(filepath.Join(dir, name))
+		if err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	err = writeJson(filepath.Join(dir, ManifestFile), manifest)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	return manifest, nil
+}
+
+// Check that the backup in dir is complete and undamaged: its files match
+// their checksums and pg_restore can read the dump.
+func Verify(ctx context.Context, dir string) (Manifest, error) {
+	manifest := Manifest{}
+
+	content, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	// Backups of installations without redis have no redis snapshot
+	files := []string{PostgresFile, MediaFile}
+	if _, ok := manifest.Files[RedisFile]; ok {
+		files = append(files, RedisFile)
+	}
+
+	for _, name := range files {
+		expected, ok := manifest.Files[name]
+		if !ok {
+			return Manifest{}, fmt.Errorf("%w: %s isn't in the manifest", ErrChecksumMismatch, name)
+		}
+
+		actual, err := checksum(filepath.Join(dir, name))
+		if err != nil {
+			return Manifest{}, err
+		}
+
+		if actual != expected {
+			return Manifest{}, fmt.Errorf("%w: %s", ErrChecksumMismatch, name)
+		}
+	}
+
+	// Lists the dump's contents without restoring it
+	err = run(ctx, "pg_restore", "--list", filepath.Join(dir, PostgresFile))
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	return manifest, nil
+}
+
+// Restore the backup in dir to target, replacing the database's contents, and
+// check that every table has as many rows as when backed up.
+//
+// Redis snapshots can only be loaded by Redis when it starts: if redisDir is
+// set the snapshot is copied there as dump.rdb, to be loaded when Redis is
+// restarted. Redis only holds sessions and caches, skipping it logs everyone
+// out.
+func Restore(ctx context.Context, target Target, dir string, redisDir string) error {
+	logger := log.FromContext(ctx).WithField("dir", dir)
+
+	manifest, err := Verify(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Restoring postgres")
+
+	err = run(ctx, "pg_restore", "--clean", "--if-exists", "--no-owner", "--single-transaction", "--dbname="+target.PostgresDsn, filepath.Join(dir, PostgresFile))
+	if err != nil {
+		return err
+	}
+
+	counts, err := rowCounts(target.Db.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+
+	for table, expected := range manifest.RowCounts {
+		if counts[table] != expected {
+			return fmt.Errorf("%w: %s has %d rows, %d expected", ErrCountMismatch, table, counts[table], expected)
+		}
+	}
+
+	if _, ok := manifest.Files[RedisFile]; !ok {
+		return nil
+	}
+
+	if redisDir == "" {
+		logger.Warn("Redis snapshot not restored, copy it to redis' data directory as dump.rdb and restart redis to restore it")
+
+		return nil
+	}
+
+	logger.WithField("redisDir", redisDir).Info("Copying the redis snapshot, restart redis to load it")
+
+	return copyFile(filepath.Join(dir, RedisFile), filepath.Join(redisDir, "dump.rdb"))
+}
+
+// The id of the last applied migration.
+func schemaVersion(db *gorm.DB) (string, error) {
+	var ids []string
+	result := db.Raw("SELECT id FROM migrations").Scan(&ids)
+	if result.Error != nil {
+		return "", result.Error
+	}
+
+	version := 0
+	for _, id := range ids {
+		number, err := strconv.Atoi(id)
+		if err == nil && number > version {
+			version = number
+		}
+	}
+
+	return strconv.Itoa(version), nil
+}
+
+// Count the rows of every table.
+func rowCounts(db *gorm.DB) (map[string]int64, error) {
+	var tables []string
+	result := db.Raw(`
+		SELECT table_name
+		FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_type = 'BASE TABLE'`,
+	).Scan(&tables)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := map[string]int64{}
+	for _, table := range tables {
+		var count int64
+		result = db.Table(table).Count(&count)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		counts[table] = count
+	}
+
+	return counts, nil
+}
+
+// List the media urls in the database, deleted rows included since they may
+// be restored.
+func listMedia(db *gorm.DB) ([]MediaEntry, error) {
+	tables := make([]string, 0, len(mediaColumns))
+	for table := range mediaColumns {
+		tables = append(tables, table)
+	}
+
+	sort.Strings(tables)
+
+	media := []MediaEntry{}
+	for _, table := range tables {
+		column := mediaColumns[table]
+
+		var rows []struct {
+			Id  uint
+			Url string
+		}
+
+		result := db.Table(table).
+			Select("id, " + column + " AS url").
+			Where(column + " <> ''").
+			Order("id").
+			Scan(&rows)
+
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		for _, row := range rows {
+			media = append(media, MediaEntry{Table: table, Id: row.Id, Column: column, Url: row.Url})
+		}
+	}
+
+	return media, nil
+}
+
+// Run a command, failing with its output if it fails.
+func run(ctx context.Context, name string, args ...string) error {
+	output, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, output)
+	}
+
+	return nil
+}
+
+func writeJson(path string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0o600)
+}
+
+func checksum(path string) (FileInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	return FileInfo{Size: size, Sha256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+func copyFile(from string, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	destination, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(destination, source)
+	if err != nil {
+		destination.Close()
+
+		return err
+	}
+
+	return destination.Close()
+}
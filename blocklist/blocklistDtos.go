@@ -0,0 +1,17 @@
+package blocklist
+
+import "time"
+
+type NewEntryDto struct {
+	Kind   Kind   `json:"kind" validate:"required,oneof=email-domain ip-range username"`
+	Value  string `json:"value" validate:"required,max=255"`
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type EntryDto struct {
+	Id        uint      `json:"id"`
+	Kind      Kind      `json:"kind"`
+	Value     string    `json:"value"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
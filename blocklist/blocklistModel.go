@@ -0,0 +1,30 @@
+package blocklist
+
+import "gorm.io/gorm"
+
+// The kind of value a blocklist entry matches against.
+type Kind string
+
+const (
+	// The value is an email domain, e.g. "mailinator.com". Subdomains
+	// of the domain are blocked too.
+	KindEmailDomain Kind = "email-domain"
+
+	// The value is an IP address or a CIDR range, e.g. "10.0.0.0/8".
+	KindIpRange Kind = "ip-range"
+
+	// The value is a username. Matching is case insensitive.
+	KindUsername Kind = "username"
+)
+
+type Entry struct {
+	gorm.Model
+
+	Kind   Kind
+	Value  string
+	Reason string
+}
+
+func (Entry) TableName() string {
+	return "blocklist_entries"
+}
@@ -0,0 +1,96 @@
+package blocklist
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List blocklist entries
+// @Tags admin
+// @Router /admin/blocklist [get]
+// @Success 200 {array} blocklist.EntryDto
+// @Failure 403
+func RouteListEntries(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	blocklistService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	entries, err := blocklistService.ListEntries(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, entries)
+}
+
+// @Summary Add a blocklist entry
+// @Tags admin
+// @Router /admin/blocklist [post]
+// @Param entry body blocklist.NewEntryDto true "The entry"
+// @Success 201 {object} blocklist.EntryDto
+// @Failure 403
+func RouteAddEntry(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	blocklistService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := NewEntryDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	entry, err := blocklistService.AddEntry(request.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, entry)
+}
+
+// @Summary Remove a blocklist entry
+// @Tags admin
+// @Router /admin/blocklist/{entryId} [delete]
+// @Param entryId path int true "The entry ID"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteRemoveEntry(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	blocklistService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	entryId, err := utils.UintFromVars(request, "entryId")
+	if err != nil {
+		return err
+	}
+
+	err = blocklistService.RemoveEntry(request.Context(), entryId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
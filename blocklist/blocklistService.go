@@ -0,0 +1,351 @@
+// NOTE: take a look at the projects redis documentation (docs/redis.md)
+// to better understand how blocklist entries are cached.
+
+package blocklist
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"gorm.io/gorm"
+	"net"
+	"strings"
+	"time"
+)
+
+var ErrBlocked = errors.New("blocked")
+var ErrEntryNotFound = errors.New("blocklist entry not found")
+var ErrInvalidEntry = errors.New("invalid blocklist entry")
+
+type Service interface {
+	// Check whether a registration is allowed, i.e. the new user's username, email
+	// domain and the client's IP address are not blocked.
+	// Returns ErrBlocked if any of them are blocked.
+	CheckRegistration(ctx context.Context, newUser users.NewUserDto) error
+
+	// Check whether a user is allowed to log in, i.e. neither the user's username
+	// nor the client's IP address are blocked.
+	// Returns ErrBlocked if any of them are blocked.
+	CheckLogin(ctx context.Context, user *users.User) error
+
+	// List all blocklist entries, newest to oldest.
+	ListEntries(ctx context.Context) ([]EntryDto, error)
+
+	// Add an entry to the blocklist.
+	// Returns ErrInvalidEntry if the entry's value is not valid for its kind
+	// (e.g. an ip-range entry that isn't an IP address or a CIDR range).
+	AddEntry(ctx context.Context, newEntry NewEntryDto) (EntryDto, error)
+
+	// Remove an entry from the blocklist.
+	// Returns ErrEntryNotFound if the entry doesn't exist.
+	RemoveEntry(ctx context.Context, entryId uint) error
+}
+
+type serviceImpl struct {
+	Db *gorm.DB
+	Kv kv.Store
+}
+
+func NewService(db *gorm.DB, kvStore kv.Store) Service {
+	return &serviceImpl{
+		Db: db,
+		Kv: kvStore,
+	}
+}
+
+// How long a kind's cached values last in the cache. Changes to the blocklist
+// invalidate the cache, so this only matters if the database is changed
+// by something other than this service.
+const cacheDuration = time.Hour
+
+func (s *serviceImpl) CheckRegistration(ctx context.Context, newUser users.NewUserDto) error {
+	err := s.checkUsername(ctx, newUser.Username)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkEmail(ctx, newUser.Email)
+	if err != nil {
+		return err
+	}
+
+	return s.checkIp(ctx, utils.ClientIp(ctx))
+}
+
+func (s *serviceImpl) CheckLogin(ctx context.Context, user *users.User) error {
+	err := s.checkUsername(ctx, user.Username)
+	if err != nil {
+		return err
+	}
+
+	return s.checkIp(ctx, utils.ClientIp(ctx))
+}
+
+func (s *serviceImpl) ListEntries(ctx context.Context) ([]EntryDto, error) {
+	logger := log.FromContext(ctx)
+
+	var entries []Entry
+	result := s.Db.WithContext(ctx).Order("created_at desc").Find(&entries)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list blocklist entries")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]EntryDto, len(entries))
+	for i, entry := range entries {
+		dtos[i] = entryToDto(entry)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) AddEntry(ctx context.Context, newEntry NewEntryDto) (EntryDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"kind":  newEntry.Kind,
+		"value": newEntry.Value,
+	})
+
+	err := validator.New().Struct(newEntry)
+	if err != nil {
+		return EntryDto{}, err
+	}
+
+	value, err := normalizeValue(newEntry.Kind, newEntry.Value)
+	if err != nil {
+		return EntryDto{}, err
+	}
+
+	entry := Entry{
+		Kind:   newEntry.Kind,
+		Value:  value,
+		Reason: newEntry.Reason,
+	}
+
+	result := s.Db.WithContext(ctx).Create(&entry)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create blocklist entry")
+
+		return EntryDto{}, result.Error
+	}
+
+	s.invalidateCache(ctx, entry.Kind)
+
+	logger.Info("Blocklist entry added")
+
+	return entryToDto(entry), nil
+}
+
+func (s *serviceImpl) RemoveEntry(ctx context.Context, entryId uint) error {
+	logger := log.FromContext(ctx).WithField("entryId", entryId)
+
+	entry := Entry{}
+	result := s.Db.WithContext(ctx).First(&entry, entryId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrEntryNotFound
+		} else {
+			logger.WithError(result.Error).Error("Failed to query for blocklist entry")
+
+			return result.Error
+		}
+	}
+
+	result = s.Db.WithContext(ctx).Delete(&entry)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to delete blocklist entry")
+
+		return result.Error
+	}
+
+	s.invalidateCache(ctx, entry.Kind)
+
+	logger.Info("Blocklist entry removed")
+
+	return nil
+}
+
+func (s *serviceImpl) checkUsername(ctx context.Context, username string) error {
+	values, err := s.getValues(ctx, KindUsername)
+	if err != nil {
+		return err
+	}
+
+	username = strings.ToLower(username)
+	for _, value := range values {
+		if value == username {
+			log.FromContext(ctx).WithField("username", username).Info("Blocked username")
+
+			return ErrBlocked
+		}
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) checkEmail(ctx context.Context, email string) error {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return nil
+	}
+
+	domain := strings.ToLower(email[at+1:])
+
+	values, err := s.getValues(ctx, KindEmailDomain)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if domain == value || strings.HasSuffix(domain, "."+value) {
+			log.FromContext(ctx).WithField("domain", domain).Info("Blocked email domain")
+
+			return ErrBlocked
+		}
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) checkIp(ctx context.Context, ipStr string) error {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return nil
+	}
+
+	values, err := s.getValues(ctx, KindIpRange)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			continue
+		}
+
+		if ipNet.Contains(ip) {
+			log.FromContext(ctx).WithField("ip", ipStr).Info("Blocked IP address")
+
+			return ErrBlocked
+		}
+	}
+
+	return nil
+}
+
+// Get all values of the given kind. The values are read from the cache, if they
+// are not cached they are loaded from the database and cached.
+func (s *serviceImpl) getValues(ctx context.Context, kind Kind) ([]string, error) {
+	logger := log.FromContext(ctx).WithField("kind", kind)
+
+	var values []string
+
+	cached, err := s.Kv.Get(ctx, blocklistRedisKey(kind))
+	if err == nil {
+		err = json.Unmarshal([]byte(cached), &values)
+		if err == nil {
+			return values, nil
+		}
+
+		logger.WithError(err).Warn("Failed to unmarshal cached blocklist")
+	} else if !errors.Is(err, kv.ErrNotFound) {
+		logger.WithError(err).Warn("Failed to get cached blocklist, falling back to the database")
+	}
+
+	result := s.Db.WithContext(ctx).Model(&Entry{}).Where("kind = ?", kind).Pluck("value", &values)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to load blocklist")
+
+		return nil, result.Error
+	}
+
+	if values == nil {
+		values = []string{}
+	}
+
+	encoded, err := json.Marshal(values)
+	if err == nil {
+		err = s.Kv.Set(ctx, blocklistRedisKey(kind), string(encoded), cacheDuration)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to cache blocklist")
+	}
+
+	return values, nil
+}
+
+func (s *serviceImpl) invalidateCache(ctx context.Context, kind Kind) {
+	err := s.Kv.Del(ctx, blocklistRedisKey(kind))
+	if err != nil {
+		log.FromContext(ctx).
+			WithError(err).
+			WithField("kind", kind).
+			Warn("Failed to invalidate cached blocklist")
+	}
+}
+
+// Normalize a blocklist value so that it can be compared against
+// user input (e.g. lower case usernames, IPs as CIDR ranges).
+// Returns ErrInvalidEntry if the value is not valid for the kind.
+func normalizeValue(kind Kind, value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	switch kind {
+	case KindIpRange:
+		if ip := net.ParseIP(value); ip != nil {
+			if ip.To4() != nil {
+				return value + "/32", nil
+			}
+
+			return value + "/128", nil
+		}
+
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return "", ErrInvalidEntry
+		}
+
+		return ipNet.String(), nil
+
+	case KindEmailDomain:
+		value = strings.TrimPrefix(value, "@")
+		if value == "" || strings.ContainsAny(value, "@ ") {
+			return "", ErrInvalidEntry
+		}
+
+		return value, nil
+
+	case KindUsername:
+		if value == "" {
+			return "", ErrInvalidEntry
+		}
+
+		return value, nil
+	}
+
+	return "", ErrInvalidEntry
+}
+
+func entryToDto(entry Entry) EntryDto {
+	return EntryDto{
+		Id:        entry.ID,
+		Kind:      entry.Kind,
+		Value:     entry.Value,
+		Reason:    entry.Reason,
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+// Stores all blocklist values of a kind as a json array.
+func blocklistRedisKey(kind Kind) string {
+	return fmt.Sprintf("blocklist:%s:values", kind)
+}
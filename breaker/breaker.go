@@ -0,0 +1,208 @@
+package breaker
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Returned instead of calling a service whose circuit breaker is open.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State string
+
+const (
+	// Calls go through. Consecutive failures are counted and the breaker
+	// opens once they reach the failure threshold.
+	StateClosed State = "closed"
+
+	// Calls fail immediately with ErrOpen until the cooldown elapses.
+	StateOpen State = "open"
+
+	// The cooldown elapsed. A single trial call goes through: if it succeeds
+	// the breaker closes, otherwise it opens again.
+	StateHalfOpen State = "half-open"
+)
+
+// A circuit breaker stops calling a failing service for a while, so that
+// requests fail fast (or fall back) instead of piling up waiting for timeouts.
+type Breaker struct {
+	name             string
+	failureThreshold int
+	cooldown         time.Duration
+
+	mu          sync.Mutex
+	state       State
+	failures    int
+	openedAt    time.Time
+	trialActive bool
+
+	// Totals, exposed as metrics
+	totalFailures int64
+	totalRejected int64
+	timesOpened   int64
+}
+
+// Check whether a call is allowed. Returns ErrOpen if it isn't. Every
+// allowed call must be followed by a call to Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cooldown {
+		b.state = StateHalfOpen
+		b.trialActive = false
+	}
+
+	switch b.state {
+	case StateOpen:
+		b.totalRejected++
+
+		return ErrOpen
+	case StateHalfOpen:
+		if b.trialActive {
+			b.totalRejected++
+
+			return ErrOpen
+		}
+
+		b.trialActive = true
+	}
+
+	return nil
+}
+
+// Record a successful call.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trialActive = false
+	b.state = StateClosed
+}
+
+// Record a failed call.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.totalFailures++
+	b.failures++
+	b.trialActive = false
+
+	if b.state == StateHalfOpen || b.failures >= b.failureThreshold {
+		if b.state != StateOpen {
+			b.timesOpened++
+		}
+
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Call fn if the breaker allows it and record its result. Returns ErrOpen
+// without calling fn if the breaker is open.
+func (b *Breaker) Do(fn func() error) error {
+	err := b.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	if err != nil {
+		b.Failure()
+	} else {
+		b.Success()
+	}
+
+	return err
+}
+
+// The breaker's current state.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+
+	return b.state
+}
+
+func (b *Breaker) stats() StatsDto {
+	state := b.State()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return StatsDto{
+		Name:                b.name,
+		State:               state,
+		ConsecutiveFailures: b.failures,
+		TotalFailures:       b.totalFailures,
+		TotalRejected:       b.totalRejected,
+		TimesOpened:         b.timesOpened,
+	}
+}
+
+// Keeps track of all circuit breakers so that their state can be exposed.
+type Registry struct {
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// Create a registry, whose breakers' states are exported as metrics.
+func NewRegistry() *Registry {
+	registry := &Registry{
+		breakers: map[string]*Breaker{},
+	}
+	registerStateGauge(registry)
+
+	return registry
+}
+
+// Get the breaker with the given name, creating it if it doesn't exist. The
+// breaker opens after failureThreshold consecutive failures and stays open
+// for cooldown.
+func (r *Registry) Breaker(name string, failureThreshold int, cooldown time.Duration) *Breaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if breaker, ok := r.breakers[name]; ok {
+		return breaker
+	}
+
+	breaker := &Breaker{
+		name:             name,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		state:            StateClosed,
+	}
+	r.breakers[name] = breaker
+
+	return breaker
+}
+
+// The stats of all breakers, ordered by name.
+func (r *Registry) Stats() []StatsDto {
+	r.mu.Lock()
+	breakers := make([]*Breaker, 0, len(r.breakers))
+	for _, breaker := range r.breakers {
+		breakers = append(breakers, breaker)
+	}
+	r.mu.Unlock()
+
+	stats := make([]StatsDto, len(breakers))
+	for i, breaker := range breakers {
+		stats[i] = breaker.stats()
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats
+}
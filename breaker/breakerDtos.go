@@ -0,0 +1,10 @@
+package breaker
+
+type StatsDto struct {
+	Name                string `json:"name"`
+	State               State  `json:"state"`
+	ConsecutiveFailures int    `json:"consecutiveFailures"`
+	TotalFailures       int64  `json:"totalFailures"`
+	TotalRejected       int64  `json:"totalRejected"`
+	TimesOpened         int64  `json:"timesOpened"`
+}
@@ -0,0 +1,36 @@
+package breaker
+
+import (
+	"context"
+	"github.com/open-collaboration/server/metrics"
+)
+
+// The values of the states in the state gauge, ordered so that alerts can
+// fire when a breaker's state is above 0.
+var stateValues = map[State]float64{
+	StateClosed:   0,
+	StateHalfOpen: 1,
+	StateOpen:     2,
+}
+
+// Export the state of each of the registry's breakers.
+func registerStateGauge(r *Registry) {
+	metrics.RegisterGaugeVec(
+		"opencollab_circuit_breaker_state",
+		"State of each circuit breaker: 0 closed, 1 half-open, 2 open.",
+		[]string{"breaker"},
+		func(ctx context.Context) ([]metrics.Sample, error) {
+			stats := r.Stats()
+
+			samples := make([]metrics.Sample, len(stats))
+			for i, breakerStats := range stats {
+				samples[i] = metrics.Sample{
+					LabelValues: []string{breakerStats.Name},
+					Value:       stateValues[breakerStats.State],
+				}
+			}
+
+			return samples, nil
+		},
+	)
+}
@@ -0,0 +1,28 @@
+package breaker
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List circuit breakers
+// @Description The state and failure counts of the circuit breakers around redis and external services.
+// @Tags admin
+// @Router /admin/circuit-breakers [get]
+// @Success 200 {array} breaker.StatsDto
+// @Failure 403
+func RouteListBreakers(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	registry *Registry,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, registry.Stats())
+}
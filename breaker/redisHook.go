@@ -0,0 +1,80 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+)
+
+// A redis hook that guards all commands with a circuit breaker. Add it with
+// redis.Client.AddHook. While the breaker is open commands fail with ErrOpen.
+//
+// Only connection level errors count as failures: redis.Nil and errors
+// replied by redis (e.g. WRONGTYPE) mean redis is up.
+type redisHook struct {
+	breaker *Breaker
+}
+
+func NewRedisHook(breaker *Breaker) redis.Hook {
+	return &redisHook{breaker: breaker}
+}
+
+func (h *redisHook) BeforeProcess(ctx context.Context, _ redis.Cmder) (context.Context, error) {
+	return ctx, h.breaker.Allow()
+}
+
+func (h *redisHook) AfterProcess(_ context.Context, cmd redis.Cmder) error {
+	h.record(cmd.Err())
+
+	return nil
+}
+
+func (h *redisHook) BeforeProcessPipeline(ctx context.Context, _ []redis.Cmder) (context.Context, error) {
+	return ctx, h.breaker.Allow()
+}
+
+func (h *redisHook) AfterProcessPipeline(_ context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if errors.Is(cmd.Err(), ErrOpen) || isRedisFailure(cmd.Err()) {
+			err = cmd.Err()
+			break
+		}
+	}
+
+	h.record(err)
+
+	return nil
+}
+
+func (h *redisHook) record(err error) {
+	if errors.Is(err, ErrOpen) {
+		// The command was rejected by the breaker, it was never sent
+		return
+	}
+
+	if isRedisFailure(err) {
+		h.breaker.Failure()
+	} else {
+		h.breaker.Success()
+	}
+}
+
+func isRedisFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, ErrOpen) || errors.Is(err, redis.TxFailedErr) {
+		return false
+	}
+
+	// Errors replied by redis itself
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		return false
+	}
+
+	// The request was cancelled on our side
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	return true
+}
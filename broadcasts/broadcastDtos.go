@@ -0,0 +1,41 @@
+package broadcasts
+
+import "time"
+
+type NewBroadcastDto struct {
+	// all, role or tag
+	Audience Audience `json:"audience" validate:"required,oneof=all role tag"`
+
+	// The role (user, organizer, moderator or admin) or the tag the broadcast
+	// is sent to. Ignored for all.
+	Target string `json:"target" validate:"max=40"`
+
+	// Go templates (text/template) rendered for each recipient with the
+	// fields of TemplateDataDto, e.g. "Hi {{.Username}}"
+	Title string `json:"title" validate:"required,max=200"`
+	Body  string `json:"body" validate:"required,max=5000"`
+
+	// Also email the recipients, unless they disabled broadcast emails
+	Email bool `json:"email"`
+}
+
+// The data broadcasts' templates are rendered with.
+type TemplateDataDto struct {
+	Username string
+}
+
+type BroadcastDto struct {
+	Id          uint       `json:"id"`
+	CreatedBy   uint       `json:"createdBy"`
+	Audience    Audience   `json:"audience"`
+	Target      string     `json:"target"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	Email       bool       `json:"email"`
+	Status      Status     `json:"status"`
+	Recipients  int        `json:"recipients"`
+	Delivered   int        `json:"delivered"`
+	CancelledBy *uint      `json:"cancelledBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
@@ -0,0 +1,64 @@
+package broadcasts
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Who a broadcast is sent to.
+type Audience string
+
+const (
+	// Every user
+	AudienceAll Audience = "all"
+
+	// Users whose role includes the broadcast's role, e.g. moderators and
+	// admins for moderator
+	AudienceRole Audience = "role"
+
+	// Users following the broadcast's tag, i.e. with a saved search for it
+	AudienceTag Audience = "tag"
+)
+
+type Status string
+
+const (
+	StatusSending   Status = "sending"
+	StatusSent      Status = "sent"
+	StatusCancelled Status = "cancelled"
+)
+
+// A notification sent by an admin to many users, e.g. a maintenance notice.
+// Broadcasts are delivered in the background (see Service.Run) a batch of
+// recipients at a time, in order of user id, so that any instance can pick
+// up where another one left off.
+type Broadcast struct {
+	gorm.Model
+
+	CreatedBy uint
+	Audience  Audience
+
+	// The role or the tag of the audience, empty for AudienceAll
+	Target string
+
+	// text/template templates rendered for each recipient, see
+	// TemplateDataDto
+	Title string
+	Body  string
+
+	// Also email the recipients, unless they disabled broadcast emails
+	Email bool
+
+	Status Status
+
+	// How many users were in the audience when the broadcast was created
+	Recipients int
+
+	// The id of the last user the broadcast was delivered to, and to how
+	// many users it was delivered so far
+	LastUserId uint
+	Delivered  int
+
+	CancelledBy *uint
+	CompletedAt *time.Time
+}
@@ -0,0 +1,137 @@
+package broadcasts
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Send a broadcast
+// @Description Notify all users, the users whose role includes a role or the users following a tag (with a saved
+// @Description search for it), e.g. of a maintenance window. The title and the body are Go templates rendered for each
+// @Description recipient, e.g. "Hi {{.Username}}". Broadcasts are delivered in the background at a limited rate, poll
+// @Description GET /admin/broadcasts/{broadcastId} for the progress. Users who disabled broadcast notifications on a
+// @Description channel don't get them on it. Broadcasts are recorded in the audit log.
+// @Tags admin
+// @Router /admin/broadcasts [post]
+// @Param broadcast body broadcasts.NewBroadcastDto true "The broadcast"
+// @Success 202 {object} broadcasts.BroadcastDto
+// @Failure 400 "The role doesn't exist, the tag is empty or a template is invalid"
+// @Failure 403
+func RouteCreateBroadcast(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	broadcastsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := NewBroadcastDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	broadcast, err := broadcastsService.CreateBroadcast(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("Location", "/admin/broadcasts/"+strconv.Itoa(int(broadcast.Id)))
+
+	return utils.WriteJson(writer, request.Context(), http.StatusAccepted, broadcast)
+}
+
+// @Summary List broadcasts
+// @Description The latest 100 broadcasts, newest to oldest.
+// @Tags admin
+// @Router /admin/broadcasts [get]
+// @Success 200 {array} broadcasts.BroadcastDto
+// @Failure 403
+func RouteListBroadcasts(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	broadcastsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	broadcasts, err := broadcastsService.ListBroadcasts(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, broadcasts)
+}
+
+// @Summary Get a broadcast
+// @Tags admin
+// @Router /admin/broadcasts/{broadcastId} [get]
+// @Param broadcastId path int true "The broadcast ID"
+// @Success 200 {object} broadcasts.BroadcastDto
+// @Failure 403
+// @Failure 404
+func RouteGetBroadcast(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	broadcastsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	broadcastId, err := utils.UintFromVars(request, "broadcastId")
+	if err != nil {
+		return err
+	}
+
+	broadcast, err := broadcastsService.GetBroadcast(request.Context(), broadcastId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, broadcast)
+}
+
+// @Summary Cancel a broadcast
+// @Description Stop delivering a broadcast. Users it was already delivered to keep their notifications.
+// @Tags admin
+// @Router /admin/broadcasts/{broadcastId}/cancel [post]
+// @Param broadcastId path int true "The broadcast ID"
+// @Success 200 {object} broadcasts.BroadcastDto
+// @Failure 403
+// @Failure 404
+// @Failure 409 "The broadcast was already sent or cancelled"
+func RouteCancelBroadcast(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	broadcastsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	broadcastId, err := utils.UintFromVars(request, "broadcastId")
+	if err != nil {
+		return err
+	}
+
+	broadcast, err := broadcastsService.CancelBroadcast(request.Context(), session.UserId(), broadcastId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, broadcast)
+}
@@ -0,0 +1,494 @@
+package broadcasts
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/email"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"text/template"
+	"time"
+)
+
+var ErrBroadcastNotFound = errors.New("broadcast not found")
+var ErrBroadcastFinished = errors.New("broadcast was already sent or cancelled")
+var ErrInvalidAudience = errors.New("invalid broadcast audience")
+var ErrInvalidTemplate = errors.New("invalid broadcast template")
+
+// How often Run delivers a batch of broadcasts. The rate limit is per
+// interval.
+const deliveryInterval = time.Minute
+
+// How many broadcasts are listed.
+const broadcastsListLimit = 100
+
+// The roles broadcasts can target.
+var roles = []users.Role{users.RoleUser, users.RoleOrganizer, users.RoleModerator, users.RoleAdmin}
+
+type Service interface {
+	// Create a broadcast, which is delivered in the background by Run, and
+	// record it in the audit log.
+	// Returns ErrInvalidAudience if the audience's role doesn't exist or its
+	// tag is empty, and ErrInvalidTemplate if the title or the body isn't a
+	// valid template.
+	CreateBroadcast(ctx context.Context, createdBy uint, dto NewBroadcastDto) (BroadcastDto, error)
+
+	// List the latest broadcasts, newest to oldest.
+	ListBroadcasts(ctx context.Context) ([]BroadcastDto, error)
+
+	// Returns ErrBroadcastNotFound if the broadcast can't be found.
+	GetBroadcast(ctx context.Context, broadcastId uint) (BroadcastDto, error)
+
+	// Stop delivering a broadcast, and record it in the audit log. Users it
+	// was already delivered to keep their notifications.
+	// Returns ErrBroadcastNotFound if the broadcast can't be found and
+	// ErrBroadcastFinished if it was already sent or cancelled.
+	CancelBroadcast(ctx context.Context, cancelledBy uint, broadcastId uint) (BroadcastDto, error)
+
+	// Deliver broadcasts to at most the rate limit of users per minute until
+	// ctx is done. Should be run in its own goroutine. Each user is delivered
+	// a broadcast by a single instance.
+	Run(ctx context.Context)
+}
+
+type serviceImpl struct {
+	Db                   *gorm.DB
+	NotificationsService notifications.Service
+	AuditService         audit.Service
+	EmailSender          email.Sender
+	EmailTemplates       *email.Templates
+
+	// How many users broadcasts are delivered to per minute, across
+	// broadcasts
+	RateLimit int
+}
+
+func NewService(
+	db *gorm.DB,
+	notificationsService notifications.Service,
+	auditService audit.Service,
+	emailSender email.Sender,
+	emailTemplates *email.Templates,
+	rateLimit int,
+) Service {
+	return &serviceImpl{
+		Db:                   db,
+		NotificationsService: notificationsService,
+		AuditService:         auditService,
+		EmailSender:          emailSender,
+		EmailTemplates:       emailTemplates,
+		RateLimit:            rateLimit,
+	}
+}
+
+func (s *serviceImpl) CreateBroadcast(ctx context.Context, createdBy uint, dto NewBroadcastDto) (BroadcastDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return BroadcastDto{}, err
+	}
+
+	if dto.Audience == AudienceAll {
+		dto.Target = ""
+	}
+
+	err = checkAudience(dto.Audience, dto.Target)
+	if err != nil {
+		return BroadcastDto{}, err
+	}
+
+	// Templates are checked with sample data, so that they don't fail for
+	// every recipient
+	for _, text := range []string{dto.Title, dto.Body} {
+		_, err = render(text, TemplateDataDto{Username: "sample"})
+		if err != nil {
+			return BroadcastDto{}, ErrInvalidTemplate
+		}
+	}
+
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"audience": dto.Audience,
+		"target":   dto.Target,
+	})
+
+	broadcast := Broadcast{
+		CreatedBy: createdBy,
+		Audience:  dto.Audience,
+		Target:    dto.Target,
+		Title:     dto.Title,
+		Body:      dto.Body,
+		Email:     dto.Email,
+		Status:    StatusSending,
+	}
+
+	var recipients int64
+	result := s.audience(s.Db.WithContext(ctx), broadcast).Count(&recipients)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to count broadcast recipients")
+
+		return BroadcastDto{}, result.Error
+	}
+
+	broadcast.Recipients = int(recipients)
+
+	result = s.Db.WithContext(ctx).Create(&broadcast)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create broadcast")
+
+		return BroadcastDto{}, result.Error
+	}
+
+	err = s.AuditService.Record(ctx, createdBy, "broadcast.create", "broadcast", broadcast.ID, map[string]interface{}{
+		"audience":   broadcast.Audience,
+		"target":     broadcast.Target,
+		"title":      broadcast.Title,
+		"email":      broadcast.Email,
+		"recipients": broadcast.Recipients,
+	})
+	if err != nil {
+		return BroadcastDto{}, err
+	}
+
+	logger.WithField("broadcastId", broadcast.ID).Info("Broadcast created")
+
+	return broadcastToDto(broadcast), nil
+}
+
+func (s *serviceImpl) ListBroadcasts(ctx context.Context) ([]BroadcastDto, error) {
+	var broadcasts []Broadcast
+	result := s.Db.WithContext(ctx).
+		Order("created_at desc").
+		Limit(broadcastsListLimit).
+		Find(&broadcasts)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list broadcasts")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]BroadcastDto, len(broadcasts))
+	for i, broadcast := range broadcasts {
+		dtos[i] = broadcastToDto(broadcast)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) GetBroadcast(ctx context.Context, broadcastId uint) (BroadcastDto, error) {
+	broadcast, err := s.findBroadcast(ctx, broadcastId)
+	if err != nil {
+		return BroadcastDto{}, err
+	}
+
+	return broadcastToDto(broadcast), nil
+}
+
+func (s *serviceImpl) CancelBroadcast(ctx context.Context, cancelledBy uint, broadcastId uint) (BroadcastDto, error) {
+	logger := log.FromContext(ctx).WithField("broadcastId", broadcastId)
+
+	result := s.Db.WithContext(ctx).
+		Model(&Broadcast{}).
+		Where("id = ? AND status = ?", broadcastId, StatusSending).
+		Updates(map[string]interface{}{
+			"status":       StatusCancelled,
+			"cancelled_by": cancelledBy,
+			"completed_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to cancel broadcast")
+
+		return BroadcastDto{}, result.Error
+	}
+
+	broadcast, err := s.findBroadcast(ctx, broadcastId)
+	if err != nil {
+		return BroadcastDto{}, err
+	}
+
+	if result.RowsAffected < 1 {
+		return BroadcastDto{}, ErrBroadcastFinished
+	}
+
+	err = s.AuditService.Record(ctx, cancelledBy, "broadcast.cancel", "broadcast", broadcastId, map[string]interface{}{
+		"delivered": broadcast.Delivered,
+	})
+	if err != nil {
+		return BroadcastDto{}, err
+	}
+
+	logger.Info("Broadcast cancelled")
+
+	return broadcastToDto(broadcast), nil
+}
+
+func (s *serviceImpl) findBroadcast(ctx context.Context, broadcastId uint) (Broadcast, error) {
+	broadcast := Broadcast{}
+	result := s.Db.WithContext(ctx).First(&broadcast, broadcastId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return Broadcast{}, ErrBroadcastNotFound
+		}
+
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for broadcast")
+
+		return Broadcast{}, result.Error
+	}
+
+	return broadcast, nil
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	if s.RateLimit <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(deliveryInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deliverBroadcasts(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Deliver the broadcasts being sent to at most the rate limit of users, oldest
+// broadcast first.
+func (s *serviceImpl) deliverBroadcasts(ctx context.Context) {
+	var broadcasts []Broadcast
+	result := s.Db.WithContext(ctx).
+		Where("status = ?", StatusSending).
+		Order("id").
+		Find(&broadcasts)
+
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			log.FromContext(ctx).WithError(result.Error).Error("Failed to list broadcasts to deliver")
+		}
+
+		return
+	}
+
+	budget := s.RateLimit
+	for _, broadcast := range broadcasts {
+		if budget < 1 || ctx.Err() != nil {
+			return
+		}
+
+		budget -= s.deliverBatch(ctx, broadcast, budget)
+	}
+}
+
+// Deliver a broadcast to the next batch of at most limit users. Returns how
+// many users it was delivered to.
+func (s *serviceImpl) deliverBatch(ctx context.Context, broadcast Broadcast, limit int) int {
+	logger := log.FromContext(ctx).WithField("broadcastId", broadcast.ID)
+
+	var recipients []users.User
+	result := s.audience(s.Db.WithContext(ctx), broadcast).
+		Select("id", "username", "email").
+		Where("id > ?", broadcast.LastUserId).
+		Order("id").
+		Limit(limit).
+		Find(&recipients)
+
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			logger.WithError(result.Error).Error("Failed to list broadcast recipients")
+		}
+
+		return 0
+	}
+
+	if len(recipients) < 1 {
+		result = s.Db.WithContext(ctx).
+			Model(&Broadcast{}).
+			Where("id = ? AND status = ?", broadcast.ID, StatusSending).
+			Updates(map[string]interface{}{
+				"status":       StatusSent,
+				"completed_at": time.Now(),
+			})
+
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to complete broadcast")
+		} else if result.RowsAffected > 0 {
+			logger.WithField("delivered", broadcast.Delivered).Info("Broadcast sent")
+		}
+
+		return 0
+	}
+
+	// Claim the batch. Only one instance moves the broadcast past its last
+	// recipient, the others leave the batch alone. Cancelled broadcasts
+	// aren't claimed either.
+	result = s.Db.WithContext(ctx).
+		Model(&Broadcast{}).
+		Where("id = ? AND status = ? AND last_user_id = ?", broadcast.ID, StatusSending, broadcast.LastUserId).
+		Updates(map[string]interface{}{
+			"last_user_id": recipients[len(recipients)-1].ID,
+			"delivered":    gorm.Expr("delivered + ?", len(recipients)),
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to claim broadcast recipients")
+
+		return 0
+	}
+
+	if result.RowsAffected < 1 {
+		return 0
+	}
+
+	for _, recipient := range recipients {
+		err := s.deliver(ctx, broadcast, recipient)
+		if err != nil {
+			// Keep delivering to the other users
+			logger.WithError(err).WithField("userId", recipient.ID).Error("Failed to deliver broadcast")
+		}
+	}
+
+	logger.Debugf("Delivered broadcast to %d users", len(recipients))
+
+	return len(recipients)
+}
+
+// Notify a user of a broadcast, and email it if the broadcast is emailed and
+// the user didn't disable broadcast emails.
+func (s *serviceImpl) deliver(ctx context.Context, broadcast Broadcast, recipient users.User) error {
+	data := TemplateDataDto{Username: recipient.Username}
+
+	title, err := render(broadcast.Title, data)
+	if err != nil {
+		return err
+	}
+
+	body, err := render(broadcast.Body, data)
+	if err != nil {
+		return err
+	}
+
+	err = s.NotificationsService.Notify(ctx, recipient.ID, notifications.NewNotificationDto{
+		Type:  notifications.TypeBroadcast,
+		Title: title,
+		Body:  body,
+		Data: map[string]interface{}{
+			"broadcastId": broadcast.ID,
+		},
+	})
+	if err != nil || !broadcast.Email {
+		return err
+	}
+
+	return s.email(ctx, recipient, title, body)
+}
+
+func (s *serviceImpl) email(ctx context.Context, recipient users.User, title string, body string) error {
+	enabled, err := s.NotificationsService.IsEnabled(ctx, recipient.ID, notifications.TypeBroadcast, notifications.ChannelEmail)
+	if err != nil || !enabled {
+		return err
+	}
+
+	message, err := s.EmailTemplates.Render(email.TemplateBroadcast, recipient.Email, map[string]interface{}{
+		"Title": title,
+		"Body":  body,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.EmailSender.SendEmail(ctx, message)
+}
+
+// Select the users of a broadcast's audience.
+func (s *serviceImpl) audience(db *gorm.DB, broadcast Broadcast) *gorm.DB {
+	query := db.Model(&users.User{})
+
+	switch broadcast.Audience {
+	case AudienceRole:
+		var included []users.Role
+		for _, role := range roles {
+			if role.Includes(users.Role(broadcast.Target)) {
+				included = append(included, role)
+			}
+		}
+
+		query = query.Where("role IN ?", included)
+
+	case AudienceTag:
+		query = query.Where(
+			"id IN (SELECT user_id FROM saved_searches WHERE deleted_at IS NULL AND "+database.DialectOf(db).Overlaps("tags")+")",
+			pq.StringArray{broadcast.Target},
+		)
+	}
+
+	return query
+}
+
+// Returns ErrInvalidAudience if the audience's role doesn't exist or its tag
+// is empty.
+func checkAudience(audience Audience, target string) error {
+	switch audience {
+	case AudienceRole:
+		for _, role := range roles {
+			if string(role) == target {
+				return nil
+			}
+		}
+
+		return ErrInvalidAudience
+
+	case AudienceTag:
+		if target == "" {
+			return ErrInvalidAudience
+		}
+	}
+
+	return nil
+}
+
+func render(text string, data TemplateDataDto) (string, error) {
+	parsed, err := template.New("broadcast").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var rendered bytes.Buffer
+	err = parsed.Execute(&rendered, data)
+	if err != nil {
+		return "", err
+	}
+
+	return rendered.String(), nil
+}
+
+func broadcastToDto(broadcast Broadcast) BroadcastDto {
+	return BroadcastDto{
+		Id:          broadcast.ID,
+		CreatedBy:   broadcast.CreatedBy,
+		Audience:    broadcast.Audience,
+		Target:      broadcast.Target,
+		Title:       broadcast.Title,
+		Body:        broadcast.Body,
+		Email:       broadcast.Email,
+		Status:      broadcast.Status,
+		Recipients:  broadcast.Recipients,
+		Delivered:   broadcast.Delivered,
+		CancelledBy: broadcast.CancelledBy,
+		CreatedAt:   broadcast.CreatedAt,
+		CompletedAt: broadcast.CompletedAt,
+	}
+}
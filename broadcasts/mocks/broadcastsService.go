@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: broadcastsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	broadcasts "github.com/open-collaboration/server/broadcasts"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateBroadcast mocks base method
+func (m *MockService) CreateBroadcast(ctx context.Context, createdBy uint, dto broadcasts.NewBroadcastDto) (broadcasts.BroadcastDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBroadcast", ctx, createdBy, dto)
+	ret0, _ := ret[0].(broadcasts.BroadcastDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBroadcast indicates an expected call of CreateBroadcast
+func (mr *MockServiceMockRecorder) CreateBroadcast(ctx, createdBy, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBroadcast", reflect.TypeOf((*MockService)(nil).CreateBroadcast), ctx, createdBy, dto)
+}
+
+// ListBroadcasts mocks base method
+func (m *MockService) ListBroadcasts(ctx context.Context) ([]broadcasts.BroadcastDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBroadcasts", ctx)
+	ret0, _ := ret[0].([]broadcasts.BroadcastDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBroadcasts indicates an expected call of ListBroadcasts
+func (mr *MockServiceMockRecorder) ListBroadcasts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBroadcasts", reflect.TypeOf((*MockService)(nil).ListBroadcasts), ctx)
+}
+
+// GetBroadcast mocks base method
+func (m *MockService) GetBroadcast(ctx context.Context, broadcastId uint) (broadcasts.BroadcastDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBroadcast", ctx, broadcastId)
+	ret0, _ := ret[0].(broadcasts.BroadcastDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBroadcast indicates an expected call of GetBroadcast
+func (mr *MockServiceMockRecorder) GetBroadcast(ctx, broadcastId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBroadcast", reflect.TypeOf((*MockService)(nil).GetBroadcast), ctx, broadcastId)
+}
+
+// CancelBroadcast mocks base method
+func (m *MockService) CancelBroadcast(ctx context.Context, cancelledBy, broadcastId uint) (broadcasts.BroadcastDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBroadcast", ctx, cancelledBy, broadcastId)
+	ret0, _ := ret[0].(broadcasts.BroadcastDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelBroadcast indicates an expected call of CancelBroadcast
+func (mr *MockServiceMockRecorder) CancelBroadcast(ctx, cancelledBy, broadcastId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBroadcast", reflect.TypeOf((*MockService)(nil).CancelBroadcast), ctx, cancelledBy, broadcastId)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
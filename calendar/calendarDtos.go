@@ -0,0 +1,36 @@
+package calendar
+
+import "time"
+
+type NewEventDto struct {
+	Kind        EventKind  `json:"kind" validate:"required,oneof=deadline meetup"`
+	Title       string     `json:"title" validate:"required,min=2,max=100"`
+	Description string     `json:"description" validate:"max=2000"`
+	Location    string     `json:"location" validate:"max=200"`
+	StartsAt    time.Time  `json:"startsAt" validate:"required"`
+	EndsAt      *time.Time `json:"endsAt"`
+}
+
+type EventDto struct {
+	Id          uint       `json:"id"`
+	ProjectId   uint       `json:"projectId"`
+	Kind        EventKind  `json:"kind"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Location    string     `json:"location"`
+	StartsAt    time.Time  `json:"startsAt"`
+	EndsAt      *time.Time `json:"endsAt"`
+}
+
+// The URLs of a calendar feed. They're only returned when the feed is created
+// since the feed's token isn't stored.
+type FeedDto struct {
+	// The iCalendar feed
+	Url string `json:"url"`
+
+	// The feed with the webcal: scheme, which opens the user's calendar app
+	WebcalUrl string `json:"webcalUrl"`
+
+	// Adds the feed to the user's Google Calendar
+	GoogleCalendarUrl string `json:"googleCalendarUrl"`
+}
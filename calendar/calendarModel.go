@@ -0,0 +1,60 @@
+package calendar
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type EventKind string
+
+const (
+	// Something due at a point in time, e.g. a release.
+	EventKindDeadline EventKind = "deadline"
+
+	// A meeting of the project's team.
+	EventKindMeetup EventKind = "meetup"
+
+	// An applicant's interview. Interviews aren't stored as events, they're
+	// added to the feeds of the applicant and the project's owner when
+	// scheduled (see applications.ScheduleInterview).
+	EventKindInterview EventKind = "interview"
+)
+
+// A project event, listed in the calendar feeds of the project's owner and
+// members.
+type Event struct {
+	gorm.Model
+
+	ProjectId   uint
+	Kind        EventKind
+	Title       string
+	Description string
+
+	// An address or a meeting link, can be empty.
+	Location string
+
+	StartsAt time.Time
+
+	// nil for events without a duration, e.g. deadlines.
+	EndsAt *time.Time
+}
+
+func (Event) TableName() string {
+	return "project_events"
+}
+
+// A user's calendar feed, which calendar apps subscribe to with the token in
+// its URL instead of a session.
+type Feed struct {
+	gorm.Model
+
+	UserId uint
+
+	// Tokens are stored hashed so that a database leak doesn't leak usable
+	// feed URLs.
+	TokenHash string
+}
+
+func (Feed) TableName() string {
+	return "calendar_feeds"
+}
@@ -0,0 +1,233 @@
+package calendar
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List a project's events
+// @Tags calendar
+// @Router /projects/{projectId}/events [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {array} calendar.EventDto
+// @Failure 404
+func RouteListEvents(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calendarService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	_, err = projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	events, err := calendarService.ListEvents(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, events)
+}
+
+// @Summary Create a project event
+// @Description Only the project's owner and members with the edit-project permission can manage its events.
+// @Tags calendar
+// @Router /projects/{projectId}/events [post]
+// @Param projectId path int true "The project ID"
+// @Param event body calendar.NewEventDto true "The event"
+// @Success 201 {object} calendar.EventDto
+// @Failure 400 "The event ends before it starts"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteCreateEvent(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calendarService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	dto := NewEventDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	event, err := calendarService.CreateEvent(request.Context(), projectId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, event)
+}
+
+// @Summary Update a project event
+// @Description Only the project's owner and members with the edit-project permission can manage its events.
+// @Tags calendar
+// @Router /projects/{projectId}/events/{eventId} [put]
+// @Param projectId path int true "The project ID"
+// @Param eventId path int true "The event ID"
+// @Param event body calendar.NewEventDto true "The event"
+// @Success 200 {object} calendar.EventDto
+// @Failure 400 "The event ends before it starts"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteUpdateEvent(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calendarService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	eventId, err := utils.UintFromVars(request, "eventId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewEventDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	event, err := calendarService.UpdateEvent(request.Context(), projectId, eventId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, event)
+}
+
+// @Summary Delete a project event
+// @Description Only the project's owner and members with the edit-project permission can manage its events.
+// @Tags calendar
+// @Router /projects/{projectId}/events/{eventId} [delete]
+// @Param projectId path int true "The project ID"
+// @Param eventId path int true "The event ID"
+// @Success 204
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteDeleteEvent(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calendarService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	eventId, err := utils.UintFromVars(request, "eventId")
+	if err != nil {
+		return err
+	}
+
+	err = calendarService.DeleteEvent(request.Context(), projectId, eventId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Create the user's calendar feed
+// @Description The feed has the events of the projects the user owns or was accepted to. Its URL is only returned
+// @Description once; creating the feed again replaces the previous URL.
+// @Tags calendar
+// @Router /users/me/calendar-feed [post]
+// @Success 201 {object} calendar.FeedDto
+// @Failure 401
+func RouteCreateFeed(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calendarService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	feed, err := calendarService.CreateFeed(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, feed)
+}
+
+// @Summary Delete the user's calendar feed
+// @Tags calendar
+// @Router /users/me/calendar-feed [delete]
+// @Success 204
+// @Failure 401
+// @Failure 404
+func RouteDeleteFeed(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calendarService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	err = calendarService.DeleteFeed(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Get a calendar feed
+// @Description An iCalendar file, authenticated by the token in the feed's URL.
+// @Tags calendar
+// @Router /calendar/{token}.ics [get]
+// @Param token path string true "The feed's token"
+// @Produce text/calendar
+// @Success 200
+// @Failure 404
+func RouteGetFeed(
+	writer http.ResponseWriter,
+	request *http.Request,
+	calendarService Service,
+) error {
+	token := mux.Vars(request)["token"]
+
+	feed, err := calendarService.RenderFeed(request.Context(), token)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	writer.Header().Set("Cache-Control", "private, max-age=300")
+	writer.WriteHeader(http.StatusOK)
+
+	_, err = writer.Write(feed)
+
+	return err
+}
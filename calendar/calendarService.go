@@ -0,0 +1,382 @@
+package calendar
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/applications"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrEventNotFound = errors.New("event not found")
+var ErrFeedNotFound = errors.New("calendar feed not found")
+var ErrInvalidEventTime = errors.New("event ends before it starts")
+
+// How long past events stay in feeds.
+const feedHistory = 30 * 24 * time.Hour
+
+// Maximum amount of events in a feed.
+const maxFeedEvents = 1000
+
+type Service interface {
+	// List a project's events, oldest to newest.
+	ListEvents(ctx context.Context, projectId uint) ([]EventDto, error)
+
+	// Returns ErrInvalidEventTime if the event ends before it starts.
+	CreateEvent(ctx context.Context, projectId uint, dto NewEventDto) (EventDto, error)
+
+	// Returns ErrEventNotFound if the project doesn't have the event and
+	// ErrInvalidEventTime if the event ends before it starts.
+	UpdateEvent(ctx context.Context, projectId uint, eventId uint, dto NewEventDto) (EventDto, error)
+
+	// Returns ErrEventNotFound if the project doesn't have the event.
+	DeleteEvent(ctx context.Context, projectId uint, eventId uint) error
+
+	// Create a user's calendar feed, replacing the previous one so that its URL
+	// stops working.
+	CreateFeed(ctx context.Context, userId uint) (FeedDto, error)
+
+	// Returns ErrFeedNotFound if the user doesn't have a feed.
+	DeleteFeed(ctx context.Context, userId uint) error
+
+	// Render the feed with the given token as an iCalendar file. The feed has
+	// the recent and upcoming events of the projects the feed's user owns or
+	// was accepted to, and the user's scheduled interviews.
+	// Returns ErrFeedNotFound if no feed has the token.
+	RenderFeed(ctx context.Context, token string) ([]byte, error)
+}
+
+type serviceImpl struct {
+	Db           *gorm.DB
+	UsersService users.Service
+
+	// The API's public base URL, which feed URLs are built on
+	PublicUrl   string
+	FrontendUrl string
+}
+
+func NewService(db *gorm.DB, usersService users.Service, publicUrl string, frontendUrl string) Service {
+	return &serviceImpl{
+		Db:           db,
+		UsersService: usersService,
+		PublicUrl:    strings.TrimSuffix(publicUrl, "/"),
+		FrontendUrl:  frontendUrl,
+	}
+}
+
+func (s *serviceImpl) ListEvents(ctx context.Context, projectId uint) ([]EventDto, error) {
+	var events []Event
+	result := s.Db.WithContext(ctx).
+		Where("project_id = ?", projectId).
+		Order("starts_at asc").
+		Find(&events)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list events")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]EventDto, len(events))
+	for i, event := range events {
+		dtos[i] = eventToDto(event)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) CreateEvent(ctx context.Context, projectId uint, dto NewEventDto) (EventDto, error) {
+	err := validateEvent(dto)
+	if err != nil {
+		return EventDto{}, err
+	}
+
+	event := Event{ProjectId: projectId}
+	setEventFields(&event, dto)
+
+	result := s.Db.WithContext(ctx).Create(&event)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to create event")
+
+		return EventDto{}, result.Error
+	}
+
+	return eventToDto(event), nil
+}
+
+func (s *serviceImpl) UpdateEvent(ctx context.Context, projectId uint, eventId uint, dto NewEventDto) (EventDto, error) {
+	err := validateEvent(dto)
+	if err != nil {
+		return EventDto{}, err
+	}
+
+	var event Event
+	result := s.Db.WithContext(ctx).
+		Where("id = ? AND project_id = ?", eventId, projectId).
+		First(&event)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return EventDto{}, ErrEventNotFound
+	} else if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to get event")
+
+		return EventDto{}, result.Error
+	}
+
+	setEventFields(&event, dto)
+
+	result = s.Db.WithContext(ctx).Save(&event)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to update event")
+
+		return EventDto{}, result.Error
+	}
+
+	return eventToDto(event), nil
+}
+
+func (s *serviceImpl) DeleteEvent(ctx context.Context, projectId uint, eventId uint) error {
+	result := s.Db.WithContext(ctx).
+		Where("id = ? AND project_id = ?", eventId, projectId).
+		Delete(&Event{})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete event")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrEventNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) CreateFeed(ctx context.Context, userId uint) (FeedDto, error) {
+	token, err := generateToken()
+	if err != nil {
+		return FeedDto{}, err
+	}
+
+	err = s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("user_id = ?", userId).Delete(&Feed{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Create(&Feed{
+			UserId:    userId,
+			TokenHash: hashToken(token),
+		}).Error
+	})
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to create calendar feed")
+
+		return FeedDto{}, err
+	}
+
+	feedUrl := fmt.Sprintf("%s/calendar/%s.ics", s.PublicUrl, token)
+	webcalUrl := "webcal" + strings.TrimPrefix(strings.TrimPrefix(feedUrl, "https"), "http")
+
+	return FeedDto{
+		Url:               feedUrl,
+		WebcalUrl:         webcalUrl,
+		GoogleCalendarUrl: "https://calendar.google.com/calendar/r?cid=" + url.QueryEscape(webcalUrl),
+	}, nil
+}
+
+func (s *serviceImpl) DeleteFeed(ctx context.Context, userId uint) error {
+	result := s.Db.WithContext(ctx).
+		Unscoped().
+		Where("user_id = ?", userId).
+		Delete(&Feed{})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete calendar feed")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrFeedNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) RenderFeed(ctx context.Context, token string) ([]byte, error) {
+	logger := log.FromContext(ctx)
+
+	var feed Feed
+	result := s.Db.WithContext(ctx).
+		Where("token_hash = ?", hashToken(token)).
+		First(&feed)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrFeedNotFound
+	} else if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get calendar feed")
+
+		return nil, result.Error
+	}
+
+	user, err := s.UsersService.GetUser(ctx, feed.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []feedEvent
+	result = s.Db.WithContext(ctx).
+		Model(&Event{}).
+		Select("project_events.*, projects.name AS project_name").
+		Joins("JOIN projects ON projects.id = project_events.project_id AND projects.deleted_at IS NULL").
+		Where(
+			"projects.owner_id = ? OR projects.id IN (SELECT project_id FROM applications WHERE applicant_id = ? AND status = ? AND deleted_at IS NULL)",
+			feed.UserId,
+			feed.UserId,
+			applications.StatusAccepted,
+		).
+		Where("COALESCE(project_events.ends_at, project_events.starts_at) >= ?", time.Now().Add(-feedHistory)).
+		Order("project_events.starts_at asc").
+		Limit(maxFeedEvents).
+		Scan(&events)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list calendar feed events")
+
+		return nil, result.Error
+	}
+
+	interviews, err := s.listInterviews(ctx, feed.UserId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list calendar feed interviews")
+
+		return nil, err
+	}
+
+	events = append(events, interviews...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].StartsAt.Before(events[j].StartsAt)
+	})
+
+	if len(events) > maxFeedEvents {
+		events = events[:maxFeedEvents]
+	}
+
+	uidDomain := "open-collaboration"
+	if publicUrl, err := url.Parse(s.PublicUrl); err == nil && publicUrl.Hostname() != "" {
+		uidDomain = publicUrl.Hostname()
+	}
+
+	return writeICalendar(events, user.Timezone, uidDomain, func(projectId uint) string {
+		return fmt.Sprintf("%s/projects/%d", s.FrontendUrl, projectId)
+	}), nil
+}
+
+// List the scheduled interviews of a user, as the applicant or as the
+// project's owner, as feed events.
+func (s *serviceImpl) listInterviews(ctx context.Context, userId uint) ([]feedEvent, error) {
+	var interviews []struct {
+		applications.Application
+		ProjectName string
+	}
+
+	result := s.Db.WithContext(ctx).
+		Model(&applications.Application{}).
+		Select("applications.*, projects.name AS project_name").
+		Joins("JOIN projects ON projects.id = applications.project_id AND projects.deleted_at IS NULL").
+		Where("applications.applicant_id = ? OR projects.owner_id = ?", userId, userId).
+		Where("applications.interview_status = ?", applications.InterviewScheduled).
+		Where("applications.interview_at >= ?", time.Now().Add(-feedHistory)).
+		Order("applications.interview_at asc").
+		Limit(maxFeedEvents).
+		Scan(&interviews)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	events := make([]feedEvent, len(interviews))
+	for i, interview := range interviews {
+		endsAt := interview.InterviewAt.Add(time.Duration(interview.InterviewMinutes) * time.Minute)
+
+		events[i] = feedEvent{
+			Event: Event{
+				Model:     gorm.Model{ID: interview.ID, UpdatedAt: interview.UpdatedAt},
+				ProjectId: interview.ProjectId,
+				Kind:      EventKindInterview,
+				Title:     "Interview",
+				Location:  interview.InterviewLocation,
+				StartsAt:  *interview.InterviewAt,
+				EndsAt:    &endsAt,
+			},
+			ProjectName: interview.ProjectName,
+		}
+	}
+
+	return events, nil
+}
+
+func validateEvent(dto NewEventDto) error {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return err
+	}
+
+	if dto.EndsAt != nil && dto.EndsAt.Before(dto.StartsAt) {
+		return ErrInvalidEventTime
+	}
+
+	return nil
+}
+
+func setEventFields(event *Event, dto NewEventDto) {
+	event.Kind = dto.Kind
+	event.Title = dto.Title
+	event.Description = dto.Description
+	event.Location = dto.Location
+	event.StartsAt = dto.StartsAt
+	event.EndsAt = dto.EndsAt
+}
+
+func eventToDto(event Event) EventDto {
+	return EventDto{
+		Id:          event.ID,
+		ProjectId:   event.ProjectId,
+		Kind:        event.Kind,
+		Title:       event.Title,
+		Description: event.Description,
+		Location:    event.Location,
+		StartsAt:    event.StartsAt,
+		EndsAt:      event.EndsAt,
+	}
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
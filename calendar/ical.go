@@ -0,0 +1,111 @@
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar apps poll feeds at most this often.
+const feedRefreshInterval = time.Hour
+
+// An event as listed in a feed.
+type feedEvent struct {
+	Event
+	ProjectName string
+}
+
+// Events and interviews have separate ids, so they need different UIDs.
+func (e feedEvent) uid(domain string) string {
+	if e.Kind == EventKindInterview {
+		return fmt.Sprintf("interview-%d@%s", e.ID, domain)
+	}
+
+	return fmt.Sprintf("event-%d@%s", e.ID, domain)
+}
+
+// Write events as an iCalendar (RFC 5545) feed. timezone is the IANA time zone
+// calendar apps should display the events in. projectUrl builds the URL of an
+// event's project.
+func writeICalendar(events []feedEvent, timezone string, uidDomain string, projectUrl func(projectId uint) string) []byte {
+	var buffer bytes.Buffer
+	line := func(name string, value string) {
+		writeLine(&buffer, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//Open Collaboration//Project events//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", "Open Collaboration")
+	line("X-WR-TIMEZONE", timezone)
+	line("REFRESH-INTERVAL;VALUE=DURATION", fmt.Sprintf("PT%dM", int(feedRefreshInterval.Minutes())))
+	line("X-PUBLISHED-TTL", fmt.Sprintf("PT%dM", int(feedRefreshInterval.Minutes())))
+
+	for _, event := range events {
+		endsAt := event.StartsAt
+		if event.EndsAt != nil {
+			endsAt = *event.EndsAt
+		}
+
+		line("BEGIN", "VEVENT")
+		line("UID", event.uid(uidDomain))
+		line("DTSTAMP", formatTime(event.UpdatedAt))
+		line("DTSTART", formatTime(event.StartsAt))
+		line("DTEND", formatTime(endsAt))
+		line("SUMMARY", escapeText(fmt.Sprintf("[%s] %s", event.ProjectName, event.Title)))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION", escapeText(event.Location))
+		}
+		line("CATEGORIES", escapeText(string(event.Kind)))
+		line("URL", projectUrl(event.ProjectId))
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+
+	return buffer.Bytes()
+}
+
+// Times are written in UTC so that the feed doesn't need time zone
+// definitions, X-WR-TIMEZONE only sets how they're displayed.
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func escapeText(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(text)
+}
+
+// Write a content line, folded into lines of at most 75 octets and terminated
+// by CRLF. Lines are folded between UTF-8 characters, never inside one.
+func writeLine(buffer *bytes.Buffer, line string) {
+	const maxLength = 75
+
+	length := 0
+	for _, char := range line {
+		size := len(string(char))
+		if length+size > maxLength {
+			// The continuation line starts with a space, which counts towards
+			// its length
+			buffer.WriteString("\r\n ")
+			length = 1
+		}
+
+		buffer.WriteRune(char)
+		length += size
+	}
+
+	buffer.WriteString("\r\n")
+}
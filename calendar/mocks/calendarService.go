@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: calendarService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	calendar "github.com/open-collaboration/server/calendar"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ListEvents mocks base method
+func (m *MockService) ListEvents(ctx context.Context, projectId uint) ([]calendar.EventDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEvents", ctx, projectId)
+	ret0, _ := ret[0].([]calendar.EventDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEvents indicates an expected call of ListEvents
+func (mr *MockServiceMockRecorder) ListEvents(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEvents", reflect.TypeOf((*MockService)(nil).ListEvents), ctx, projectId)
+}
+
+// CreateEvent mocks base method
+func (m *MockService) CreateEvent(ctx context.Context, projectId uint, dto calendar.NewEventDto) (calendar.EventDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", ctx, projectId, dto)
+	ret0, _ := ret[0].(calendar.EventDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateEvent indicates an expected call of CreateEvent
+func (mr *MockServiceMockRecorder) CreateEvent(ctx, projectId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockService)(nil).CreateEvent), ctx, projectId, dto)
+}
+
+// UpdateEvent mocks base method
+func (m *MockService) UpdateEvent(ctx context.Context, projectId, eventId uint, dto calendar.NewEventDto) (calendar.EventDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEvent", ctx, projectId, eventId, dto)
+	ret0, _ := ret[0].(calendar.EventDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEvent indicates an expected call of UpdateEvent
+func (mr *MockServiceMockRecorder) UpdateEvent(ctx, projectId, eventId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEvent", reflect.TypeOf((*MockService)(nil).UpdateEvent), ctx, projectId, eventId, dto)
+}
+
+// DeleteEvent mocks base method
+func (m *MockService) DeleteEvent(ctx context.Context, projectId, eventId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEvent", ctx, projectId, eventId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEvent indicates an expected call of DeleteEvent
+func (mr *MockServiceMockRecorder) DeleteEvent(ctx, projectId, eventId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEvent", reflect.TypeOf((*MockService)(nil).DeleteEvent), ctx, projectId, eventId)
+}
+
+// CreateFeed mocks base method
+func (m *MockService) CreateFeed(ctx context.Context, userId uint) (calendar.FeedDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFeed", ctx, userId)
+	ret0, _ := ret[0].(calendar.FeedDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFeed indicates an expected call of CreateFeed
+func (mr *MockServiceMockRecorder) CreateFeed(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFeed", reflect.TypeOf((*MockService)(nil).CreateFeed), ctx, userId)
+}
+
+// DeleteFeed mocks base method
+func (m *MockService) DeleteFeed(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFeed", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFeed indicates an expected call of DeleteFeed
+func (mr *MockServiceMockRecorder) DeleteFeed(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFeed", reflect.TypeOf((*MockService)(nil).DeleteFeed), ctx, userId)
+}
+
+// RenderFeed mocks base method
+func (m *MockService) RenderFeed(ctx context.Context, token string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenderFeed", ctx, token)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenderFeed indicates an expected call of RenderFeed
+func (mr *MockServiceMockRecorder) RenderFeed(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenderFeed", reflect.TypeOf((*MockService)(nil).RenderFeed), ctx, token)
+}
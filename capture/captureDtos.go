@@ -0,0 +1,41 @@
+package capture
+
+import "time"
+
+type StartCaptureDto struct {
+	// Fraction of requests to capture, between 0 and 1.
+	SampleRate float64 `json:"sampleRate" validate:"gt=0,lte=1"`
+
+	// Only requests whose path starts with the prefix are captured.
+	PathPrefix string `json:"pathPrefix"`
+
+	// How long to capture requests for. Captured exchanges are kept for
+	// the same duration after the capture ends.
+	DurationMinutes int `json:"durationMinutes" validate:"required,min=1,max=120"`
+}
+
+type CaptureDto struct {
+	SampleRate float64   `json:"sampleRate"`
+	PathPrefix string    `json:"pathPrefix"`
+	Until      time.Time `json:"until"`
+}
+
+type ExchangeDto struct {
+	RequestId       string              `json:"requestId"`
+	Time            time.Time           `json:"time"`
+	DurationMs      int64               `json:"durationMs"`
+	UserId          uint                `json:"userId,omitempty"`
+	Method          string              `json:"method"`
+	Url             string              `json:"url"`
+	RequestHeaders  map[string][]string `json:"requestHeaders"`
+	RequestBody     string              `json:"requestBody"`
+	Status          int                 `json:"status"`
+	ResponseHeaders map[string][]string `json:"responseHeaders"`
+	ResponseBody    string              `json:"responseBody"`
+}
+
+type CaptureStateDto struct {
+	// nil if no capture is active
+	Capture   *CaptureDto   `json:"capture"`
+	Exchanges []ExchangeDto `json:"exchanges"`
+}
@@ -0,0 +1,118 @@
+package capture
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Captures a sample of requests and their responses while a capture is
+// active (see Service.StartCapture). Must come after auth.SessionMiddleware
+// to record the requests' users.
+func CaptureMiddleware(captureService Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			// Capturing the capture routes would nest captures in captures
+			if strings.HasPrefix(r.URL.Path, "/admin/debug/") || utils.IsWebsocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			capture := captureService.activeCapture(ctx)
+			if capture == nil ||
+				!strings.HasPrefix(r.URL.Path, capture.PathPrefix) ||
+				rand.Float64() >= capture.SampleRate {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+
+			// Read the body up front so that it can be both captured and
+			// read by the handler
+			requestBody, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				log.FromContext(ctx).WithError(err).Warn("Failed to read request body for capture")
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+
+			recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			exchange := ExchangeDto{
+				RequestId:       requestId(r),
+				Time:            start,
+				DurationMs:      time.Since(start).Milliseconds(),
+				Method:          r.Method,
+				Url:             r.URL.String(),
+				RequestHeaders:  r.Header.Clone(),
+				RequestBody:     truncateBody(requestBody),
+				Status:          recorder.status,
+				ResponseHeaders: w.Header().Clone(),
+				ResponseBody:    truncateBody(recorder.body.Bytes()),
+			}
+
+			if session, err := auth.CheckSession(r); err == nil {
+				exchange.UserId = session.UserId()
+			}
+
+			captureService.storeExchange(ctx, exchange)
+		})
+	}
+}
+
+// Records a response's status and the beginning of its body.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	if remaining := maxBodySize + 1 - r.body.Len(); remaining > 0 {
+		if len(data) < remaining {
+			remaining = len(data)
+		}
+
+		r.body.Write(data[:remaining])
+	}
+
+	return r.ResponseWriter.Write(data)
+}
+
+func truncateBody(body []byte) string {
+	if len(body) > maxBodySize {
+		return string(body[:maxBodySize]) + fmt.Sprintf("... (truncated to %d bytes)", maxBodySize)
+	}
+
+	return string(body)
+}
+
+// The id set on the request's logger by middleware.LoggingMiddleware.
+func requestId(r *http.Request) string {
+	if entry, ok := log.FromContext(r.Context()).(*log.Entry); ok {
+		if id := entry.Fields.Get("requestId"); id != nil {
+			return fmt.Sprint(id)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,104 @@
+package capture
+
+import (
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Start capturing requests
+// @Description Records a sample of full requests and responses, with secrets redacted, to debug client
+// @Description integrations. Replaces the active capture, if any.
+// @Tags admin
+// @Router /admin/debug/capture [post]
+// @Param capture body capture.StartCaptureDto true "What to capture and for how long"
+// @Success 201 {object} capture.CaptureDto
+// @Failure 403
+func RouteStartCapture(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	auditService audit.Service,
+	captureService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := StartCaptureDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = auditService.Record(request.Context(), session.UserId(), "debug.start-capture", "", 0, map[string]interface{}{
+		"sampleRate":      dto.SampleRate,
+		"pathPrefix":      dto.PathPrefix,
+		"durationMinutes": dto.DurationMinutes,
+	})
+	if err != nil {
+		return err
+	}
+
+	capture, err := captureService.StartCapture(request.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, capture)
+}
+
+// @Summary Stop capturing requests
+// @Description The captured requests are kept until they expire.
+// @Tags admin
+// @Router /admin/debug/capture [delete]
+// @Success 204
+// @Failure 403
+func RouteStopCapture(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	captureService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = captureService.StopCapture(request.Context())
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Get captured requests
+// @Description The active capture, if any, and the captured requests, newest to oldest.
+// @Tags admin
+// @Router /admin/debug/capture [get]
+// @Success 200 {object} capture.CaptureStateDto
+// @Failure 403
+func RouteGetCapture(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	captureService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	state, err := captureService.GetCaptureState(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, state)
+}
@@ -0,0 +1,303 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var ErrCaptureDisabled = errors.New("request capture is disabled")
+
+// Maximum amount of exchanges kept.
+const maxExchanges = 200
+
+// Maximum size of the request and response bodies kept, longer bodies are
+// truncated.
+const maxBodySize = 16 * 1024
+
+// How long the active capture is cached in-process, so that the capture
+// middleware doesn't query the kv store on every request.
+const captureCacheDuration = 5 * time.Second
+
+// Headers whose values are always redacted.
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+	"X-Gateway-Key": true,
+}
+
+type Service interface {
+	// Start capturing a sample of requests and their responses, replacing
+	// the active capture, if any.
+	// Returns ErrCaptureDisabled if captures are disabled for the deployment.
+	StartCapture(ctx context.Context, dto StartCaptureDto) (CaptureDto, error)
+
+	// Stop the active capture. The captured exchanges are kept until they
+	// expire.
+	StopCapture(ctx context.Context) error
+
+	// Get the active capture and the captured exchanges, newest to oldest.
+	GetCaptureState(ctx context.Context) (CaptureStateDto, error)
+
+	// The active capture, or nil if there is none. Cached for a few seconds.
+	activeCapture(ctx context.Context) *CaptureDto
+
+	// Redact and store an exchange in the background.
+	storeExchange(ctx context.Context, exchange ExchangeDto)
+}
+
+type serviceImpl struct {
+	Kv      kv.Store
+	Enabled bool
+
+	// Field names (or parts of them) whose values are redacted from JSON bodies
+	RedactedFields []string
+
+	mu              sync.Mutex
+	cachedCapture   *CaptureDto
+	cachedCaptureAt time.Time
+}
+
+// Create a capture service. If enabled is false captures can't be started,
+// so that deployments have to opt in to capturing requests.
+func NewService(kvStore kv.Store, enabled bool, redactedFields []string) Service {
+	return &serviceImpl{
+		Kv:             kvStore,
+		Enabled:        enabled,
+		RedactedFields: redactedFields,
+	}
+}
+
+func (s *serviceImpl) StartCapture(ctx context.Context, dto StartCaptureDto) (CaptureDto, error) {
+	if !s.Enabled {
+		return CaptureDto{}, ErrCaptureDisabled
+	}
+
+	duration := time.Duration(dto.DurationMinutes) * time.Minute
+	capture := CaptureDto{
+		SampleRate: dto.SampleRate,
+		PathPrefix: dto.PathPrefix,
+		Until:      time.Now().Add(duration),
+	}
+
+	captureJson, err := json.Marshal(capture)
+	if err != nil {
+		return CaptureDto{}, err
+	}
+
+	err = s.Kv.Set(ctx, captureRedisKey, string(captureJson), duration)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to start capture")
+
+		return CaptureDto{}, err
+	}
+
+	s.setCachedCapture(&capture)
+
+	return capture, nil
+}
+
+func (s *serviceImpl) StopCapture(ctx context.Context) error {
+	err := s.Kv.Del(ctx, captureRedisKey)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to stop capture")
+
+		return err
+	}
+
+	s.setCachedCapture(nil)
+
+	return nil
+}
+
+func (s *serviceImpl) GetCaptureState(ctx context.Context) (CaptureStateDto, error) {
+	logger := log.FromContext(ctx)
+
+	capture, err := s.getCapture(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get capture")
+
+		return CaptureStateDto{}, err
+	}
+
+	values, err := s.Kv.List(ctx, exchangesRedisKey)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get captured exchanges")
+
+		return CaptureStateDto{}, err
+	}
+
+	exchanges := make([]ExchangeDto, 0, len(values))
+	for _, value := range values {
+		var exchange ExchangeDto
+		err = json.Unmarshal([]byte(value), &exchange)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to unmarshal captured exchange")
+
+			continue
+		}
+
+		exchanges = append(exchanges, exchange)
+	}
+
+	return CaptureStateDto{
+		Capture:   capture,
+		Exchanges: exchanges,
+	}, nil
+}
+
+func (s *serviceImpl) activeCapture(ctx context.Context) *CaptureDto {
+	if !s.Enabled {
+		return nil
+	}
+
+	s.mu.Lock()
+	if time.Since(s.cachedCaptureAt) < captureCacheDuration {
+		capture := s.cachedCapture
+		s.mu.Unlock()
+
+		return capture
+	}
+	s.mu.Unlock()
+
+	capture, err := s.getCapture(ctx)
+	if err != nil {
+		// Don't capture rather than fail the request
+		log.FromContext(ctx).WithError(err).Warn("Failed to get capture")
+	}
+
+	s.setCachedCapture(capture)
+
+	return capture
+}
+
+func (s *serviceImpl) setCachedCapture(capture *CaptureDto) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cachedCapture = capture
+	s.cachedCaptureAt = time.Now()
+}
+
+// Get the active capture from the kv store, nil if there is none.
+func (s *serviceImpl) getCapture(ctx context.Context) (*CaptureDto, error) {
+	value, err := s.Kv.Get(ctx, captureRedisKey)
+	if errors.Is(err, kv.ErrNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	capture := &CaptureDto{}
+	err = json.Unmarshal([]byte(value), capture)
+	if err != nil {
+		return nil, err
+	}
+
+	return capture, nil
+}
+
+func (s *serviceImpl) storeExchange(ctx context.Context, exchange ExchangeDto) {
+	logger := log.FromContext(ctx)
+
+	// Storing shouldn't slow down the response
+	go func() {
+		ctx := log.NewContext(context.Background(), logger)
+
+		exchange.RequestHeaders = redactHeaders(exchange.RequestHeaders)
+		exchange.ResponseHeaders = redactHeaders(exchange.ResponseHeaders)
+		exchange.RequestBody = s.redactBody(exchange.RequestBody)
+		exchange.ResponseBody = s.redactBody(exchange.ResponseBody)
+
+		exchangeJson, err := json.Marshal(exchange)
+		if err != nil {
+			logger.WithError(err).Error("Failed to marshal captured exchange")
+
+			return
+		}
+
+		// Keep the exchanges for as long as the capture lasted
+		ttl := time.Duration(0)
+		if capture := s.activeCapture(ctx); capture != nil {
+			ttl = time.Until(capture.Until) * 2
+		}
+		if ttl < time.Hour {
+			ttl = time.Hour
+		}
+
+		err = s.Kv.PushList(ctx, exchangesRedisKey, string(exchangeJson), maxExchanges, ttl)
+		if err != nil {
+			logger.WithError(err).Error("Failed to store captured exchange")
+		}
+	}()
+}
+
+// Redact the values of sensitive fields of a JSON body. Bodies that aren't
+// JSON are only kept if no sensitive field name appears in them.
+func (s *serviceImpl) redactBody(body string) string {
+	if body == "" {
+		return body
+	}
+
+	var value interface{}
+	err := json.Unmarshal([]byte(body), &value)
+	if err != nil {
+		if utils.IsSensitiveField(body, s.RedactedFields) {
+			return "[redacted]"
+		}
+
+		return body
+	}
+
+	redacted, err := json.Marshal(s.redactValue(value))
+	if err != nil {
+		return "[redacted]"
+	}
+
+	return string(redacted)
+}
+
+func (s *serviceImpl) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, fieldValue := range v {
+			if utils.IsSensitiveField(key, s.RedactedFields) {
+				v[key] = "[redacted]"
+			} else {
+				v[key] = s.redactValue(fieldValue)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = s.redactValue(item)
+		}
+	}
+
+	return value
+}
+
+func redactHeaders(headers http.Header) map[string][]string {
+	redacted := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			redacted[name] = []string{"[redacted]"}
+		} else {
+			redacted[name] = values
+		}
+	}
+
+	return redacted
+}
+
+// JSON object of the active capture, expires when the capture ends.
+const captureRedisKey = "debug.capture"
+
+// List of JSON objects of the captured exchanges, newest first.
+const exchangesRedisKey = "debug.capture:exchanges"
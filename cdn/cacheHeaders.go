@@ -0,0 +1,117 @@
+package cdn
+
+import (
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Tag of the cached project listings, purged whenever any project changes.
+const TagProjects = "projects"
+
+// Tag of a project's cached details.
+func ProjectTag(projectId uint) string {
+	return fmt.Sprintf("project-%d", projectId)
+}
+
+// The cache tags of the responses of each cached route, by the route's
+// method and path template. Responses of other routes aren't cached.
+var cachedRoutes = map[string]func(vars map[string]string) []string{
+	"GET /projects": func(vars map[string]string) []string {
+		return []string{TagProjects}
+	},
+	"GET /projects/{projectId}": func(vars map[string]string) []string {
+		return []string{"project-" + vars["projectId"]}
+	},
+	"GET /projects/{projectId}/og-image.png": func(vars map[string]string) []string {
+		return []string{"project-" + vars["projectId"]}
+	},
+}
+
+// Lets CDNs cache the successful responses of anonymous requests to the
+// cached routes for maxAge. The responses are tagged (Surrogate-Key and
+// Cache-Tag headers) so that they can be purged when they change, see
+// Service. Browsers are told to revalidate, only the CDN keeps the responses.
+//
+// Responses of requests with a session are never cached, the CDN should
+// bypass its cache for requests with a sessionToken cookie.
+//
+// Does nothing if maxAge is 0. Must be used after auth.SessionMiddleware.
+func CacheMiddleware(maxAge time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := mux.CurrentRoute(r)
+			if maxAge <= 0 || route == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			template, err := route.GetPathTemplate()
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			routeTags, ok := cachedRoutes[r.Method+" "+template]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if _, err := auth.CheckSession(r); err == nil {
+				w.Header().Set("Cache-Control", "private, no-store")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(&cacheableResponse{
+				ResponseWriter: w,
+				maxAge:         maxAge,
+				tags:           routeTags(mux.Vars(r)),
+			}, r)
+		})
+	}
+}
+
+// Adds the cache headers to a response if it's successful.
+type cacheableResponse struct {
+	http.ResponseWriter
+	maxAge time.Duration
+	tags   []string
+
+	wroteHeader bool
+}
+
+func (r *cacheableResponse) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+
+		header := r.Header()
+		if status == http.StatusOK {
+			cacheControl := fmt.Sprintf("max-age=%d", int(r.maxAge.Seconds()))
+
+			// Surrogate-Control is understood by Fastly and Akamai,
+			// CDN-Cache-Control (RFC 9213) by Cloudflare
+			header.Set("Surrogate-Control", cacheControl)
+			header.Set("CDN-Cache-Control", cacheControl)
+			header.Set("Surrogate-Key", strings.Join(r.tags, " "))
+			header.Set("Cache-Tag", strings.Join(r.tags, ","))
+			header.Set("Cache-Control", "public, no-cache")
+		} else {
+			header.Set("Cache-Control", "no-store")
+		}
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *cacheableResponse) Write(data []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	return r.ResponseWriter.Write(data)
+}
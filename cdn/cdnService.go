@@ -0,0 +1,104 @@
+package cdn
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/projects"
+	"net/http"
+	"time"
+)
+
+// How long to wait for a purge webhook to respond.
+const purgeTimeout = 10 * time.Second
+
+type Service interface {
+	// Purge the cached responses with any of the tags, in the background.
+	// Does nothing if no purge webhook is configured.
+	Purge(ctx context.Context, tags ...string)
+
+	// Purges the project's cached details and all project listings.
+	ProjectSaved(ctx context.Context, project *projects.Project)
+}
+
+type serviceImpl struct {
+	// Each purge is posted to all webhooks, e.g. one per CDN
+	WebhookUrls []string
+
+	// Sent as a bearer token to the webhooks, optional
+	Token string
+}
+
+// Create a CDN service posting purges to the webhooks. The webhooks receive
+// a JSON object with the tags to purge, e.g. {"tags": ["projects"]}, and are
+// expected to translate it to their CDN's purge API.
+func NewService(webhookUrls []string, token string) Service {
+	return &serviceImpl{
+		WebhookUrls: webhookUrls,
+		Token:       token,
+	}
+}
+
+func (s *serviceImpl) ProjectSaved(ctx context.Context, project *projects.Project) {
+	s.Purge(ctx, TagProjects, ProjectTag(project.ID))
+}
+
+func (s *serviceImpl) Purge(ctx context.Context, tags ...string) {
+	if len(s.WebhookUrls) < 1 || len(tags) < 1 {
+		return
+	}
+
+	logger := log.FromContext(ctx).WithField("tags", tags)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"tags": tags,
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to encode purge request")
+
+		return
+	}
+
+	for _, webhookUrl := range s.WebhookUrls {
+		webhookUrl := webhookUrl
+		go func() {
+			ctx := log.NewContext(context.Background(), logger)
+
+			err := s.post(ctx, webhookUrl, body)
+			if err != nil {
+				logger.WithError(err).Error("Failed to purge CDN cache")
+			}
+		}()
+	}
+}
+
+func (s *serviceImpl) post(ctx context.Context, webhookUrl string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, purgeTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "POST", webhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("purge webhook failed with status %d", response.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package chat
+
+import "time"
+
+type ChannelDto struct {
+	ProjectId uint `json:"projectId"`
+	Enabled   bool `json:"enabled"`
+
+	// Whether the user can't post, and until when (null if until they're
+	// unmuted)
+	Muted      bool       `json:"muted"`
+	MutedUntil *time.Time `json:"mutedUntil"`
+}
+
+type ChannelSettingsDto struct {
+	Enabled bool `json:"enabled"`
+}
+
+type NewMessageDto struct {
+	// Members can be mentioned with @username
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+type MessageDto struct {
+	Id             uint      `json:"id"`
+	ProjectId      uint      `json:"projectId"`
+	AuthorId       uint      `json:"authorId"`
+	AuthorUsername string    `json:"authorUsername"`
+	Body           string    `json:"body"`
+	Mentions       []uint    `json:"mentions"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type NewMuteDto struct {
+	// How long the member is muted for, null until they're unmuted
+	Minutes *int `json:"minutes" validate:"omitempty,min=1,max=525600"`
+}
+
+type MuteDto struct {
+	UserId    uint       `json:"userId"`
+	MutedBy   uint       `json:"mutedBy"`
+	Until     *time.Time `json:"until"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Sent on chat sockets when a message is posted ("message", with Message) or
+// deleted ("message.deleted", with MessageId), and every heartbeat interval
+// ("heartbeat").
+type EventDto struct {
+	Type      string      `json:"type"`
+	Message   *MessageDto `json:"message,omitempty"`
+	MessageId uint        `json:"messageId,omitempty"`
+}
@@ -0,0 +1,60 @@
+package chat
+
+import (
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"time"
+)
+
+// A project's chat channel, which its owner can turn on. Projects without a
+// channel have chat turned off.
+type Channel struct {
+	ProjectId uint `gorm:"primaryKey"`
+	Enabled   bool
+	UpdatedAt time.Time
+}
+
+func (Channel) TableName() string {
+	return "chat_channels"
+}
+
+// A message posted to a project's chat channel. Messages deleted by their
+// author or a moderator are soft deleted.
+type Message struct {
+	gorm.Model
+
+	ProjectId uint
+	AuthorId  uint
+	Body      string
+
+	// The members mentioned with @username, who are notified
+	Mentions pq.Int64Array `gorm:"type: BIGINT[]"`
+
+	// Posted by a shadow restricted user, only visible to them
+	Hidden bool
+
+	DeletedBy *uint
+}
+
+func (Message) TableName() string {
+	return "chat_messages"
+}
+
+// A member who can't post to a project's chat channel, until Until or until
+// they're unmuted if it's nil.
+type Mute struct {
+	ProjectId uint `gorm:"primaryKey"`
+	UserId    uint `gorm:"primaryKey"`
+	MutedBy   uint
+	Until     *time.Time
+	CreatedAt time.Time
+}
+
+func (Mute) TableName() string {
+	return "chat_mutes"
+}
+
+// Whether the mute is still in effect.
+func (m Mute) Active(now time.Time) bool {
+	return m.Until == nil || m.Until.After(now)
+}
@@ -0,0 +1,526 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/presence"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/undo"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"golang.org/x/net/websocket"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// What the request's user can do in a project's chat.
+type access struct {
+	session   auth.Session
+	projectId uint
+
+	// Members with the moderate-comments permission and site moderators,
+	// who can delete any message and mute members
+	moderator bool
+
+	// Members with the edit-project permission, who can turn chat on or off
+	editor bool
+}
+
+// Check that the request's session belongs to a member of the project in the
+// projectId route variable, or to a site moderator.
+func checkAccess(request *http.Request, projectsService projects.Service, usersService users.Service) (access, error) {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return access{}, err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return access{}, err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return access{}, err
+	}
+
+	_, err = auth.CheckRole(request, usersService, users.RoleModerator)
+	siteModerator := err == nil
+
+	// Same visibility as the project itself
+	if (project.PendingReview || project.Draft) && project.OwnerId != session.UserId() && !siteModerator {
+		return access{}, projects.ErrProjectNotFound
+	}
+
+	member, err := projectsService.IsMember(request.Context(), projectId, session.UserId())
+	if err != nil {
+		return access{}, err
+	}
+
+	if !member && !siteModerator {
+		return access{}, auth.ErrForbidden
+	}
+
+	permissions, err := projectsService.GetPermissions(request.Context(), projectId, session.UserId())
+	if err != nil {
+		return access{}, err
+	}
+
+	result := access{
+		session:   session,
+		projectId: projectId,
+		moderator: siteModerator,
+	}
+
+	for _, permission := range permissions {
+		switch permission {
+		case projects.PermissionModerateComments:
+			result.moderator = true
+		case projects.PermissionEditProject:
+			result.editor = true
+		}
+	}
+
+	return result, nil
+}
+
+// @Summary Get a project's chat channel
+// @Description Whether the project's members-only chat is turned on, and whether the user is muted in it.
+// @Tags chat
+// @Router /projects/{projectId}/chat [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {object} chat.ChannelDto
+// @Failure 401
+// @Failure 403 "The user isn't a member of the project"
+// @Failure 404 "Project not found"
+func RouteGetChannel(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	channel, err := chatService.GetChannel(request.Context(), access.projectId, access.session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, channel)
+}
+
+// @Summary Turn a project's chat on or off
+// @Description Only the project's owner and members with the edit-project permission can. Turning chat off keeps its
+// @Description history, which is back when it's turned on.
+// @Tags chat
+// @Router /projects/{projectId}/chat [put]
+// @Param projectId path int true "The project ID"
+// @Param settings body chat.ChannelSettingsDto true "The settings"
+// @Success 200 {object} chat.ChannelDto
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404 "Project not found"
+func RouteSetChannel(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	if !access.editor {
+		return auth.ErrForbidden
+	}
+
+	dto := ChannelSettingsDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = chatService.SetEnabled(request.Context(), access.projectId, dto.Enabled)
+	if err != nil {
+		return err
+	}
+
+	channel, err := chatService.GetChannel(request.Context(), access.projectId, access.session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, channel)
+}
+
+// @Summary List a project's chat messages
+// @Description The history of the project's chat, newest first. Older pages are listed by passing the id of the
+// @Description oldest message listed so far as before.
+// @Tags chat
+// @Router /projects/{projectId}/chat/messages [get]
+// @Param projectId path int true "The project ID"
+// @Param before query int false "List the messages posted before this message"
+// @Param pageSize query int false "Default is 50, max is 100"
+// @Success 200 {array} chat.MessageDto
+// @Header 200 {bool} X-Has-Next-Page "Whether there are older messages"
+// @Failure 401
+// @Failure 403 "The user isn't a member of the project"
+// @Failure 404 "Project not found or chat-disabled-error"
+func RouteListMessages(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	before, _ := utils.IntFromQuery(request, "before", 0)
+	if before < 0 {
+		before = 0
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", DefaultPageSize)
+
+	messages, hasMore, err := chatService.ListMessages(request.Context(), access.session.UserId(), access.projectId, uint(before), pageSize)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("X-Has-Next-Page", strconv.FormatBool(hasMore))
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, messages)
+}
+
+// @Summary Post a chat message
+// @Description Members mentioned with @username are notified. Muted members can't post.
+// @Tags chat
+// @Router /projects/{projectId}/chat/messages [post]
+// @Param projectId path int true "The project ID"
+// @Param message body chat.NewMessageDto true "The message"
+// @Success 201 {object} chat.MessageDto
+// @Failure 400
+// @Failure 401
+// @Failure 403 "The user isn't a member of the project, or chat-muted-error"
+// @Failure 404 "Project not found or chat-disabled-error"
+func RoutePostMessage(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	accountStatusProvider auth.AccountStatusProvider,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
+	if err != nil {
+		return err
+	}
+
+	dto := NewMessageDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	message, err := chatService.PostMessage(request.Context(), access.session.UserId(), access.projectId, dto, accountStatus.ShadowHidden)
+	if err != nil {
+		return err
+	}
+
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, message)
+}
+
+// @Summary Delete a chat message
+// @Description Members can delete their own messages. Members with the moderate-comments permission (including the
+// @Description project's owner) and moderators can delete any message,
+// @Description which is recorded in the audit log. The deletion can be undone for 30 seconds by sending the
+// @Description returned token to POST /undo.
+// @Tags chat
+// @Router /projects/{projectId}/chat/messages/{messageId} [delete]
+// @Param projectId path int true "The project ID"
+// @Param messageId path int true "The message ID"
+// @Success 200 {object} undo.UndoDto
+// @Failure 401
+// @Failure 403
+// @Failure 404 "Project or message not found"
+func RouteDeleteMessage(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+	undoService undo.Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	messageId, err := utils.UintFromVars(request, "messageId")
+	if err != nil {
+		return err
+	}
+
+	message, err := chatService.GetMessage(request.Context(), access.projectId, messageId)
+	if err != nil {
+		return err
+	}
+
+	if message.AuthorId != access.session.UserId() && !access.moderator {
+		return auth.ErrForbidden
+	}
+
+	err = chatService.DeleteMessage(request.Context(), access.session.UserId(), access.projectId, messageId)
+	if err != nil {
+		return err
+	}
+
+	undoDto, err := undoService.Record(request.Context(), access.session.UserId(), UndoDeleteMessage, messageId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, undoDto)
+}
+
+// @Summary List a project's muted members
+// @Description Only members with the moderate-comments permission (including the project's owner) and moderators can.
+// @Tags chat
+// @Router /projects/{projectId}/chat/mutes [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {array} chat.MuteDto
+// @Failure 401
+// @Failure 403
+// @Failure 404 "Project not found"
+func RouteListMutes(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	if !access.moderator {
+		return auth.ErrForbidden
+	}
+
+	mutes, err := chatService.ListMutes(request.Context(), access.projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, mutes)
+}
+
+// @Summary Mute a member in a project's chat
+// @Description Only members with the moderate-comments permission (including the project's owner) and moderators can.
+// @Description Muted members can still read the chat. Mutes are recorded in the audit log.
+// @Tags chat
+// @Router /projects/{projectId}/chat/mutes/{userId} [put]
+// @Param projectId path int true "The project ID"
+// @Param userId path int true "The member's user ID"
+// @Param mute body chat.NewMuteDto true "How long to mute the member for"
+// @Success 200 {object} chat.MuteDto
+// @Failure 400 "The user isn't a member (not-member-error) or is the project's owner"
+// @Failure 401
+// @Failure 403
+// @Failure 404 "Project not found"
+func RouteMuteMember(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	if !access.moderator {
+		return auth.ErrForbidden
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewMuteDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	mute, err := chatService.MuteMember(request.Context(), access.session.UserId(), access.projectId, userId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, mute)
+}
+
+// @Summary Unmute a member in a project's chat
+// @Description Only members with the moderate-comments permission (including the project's owner) and moderators can.
+// @Tags chat
+// @Router /projects/{projectId}/chat/mutes/{userId} [delete]
+// @Param projectId path int true "The project ID"
+// @Param userId path int true "The member's user ID"
+// @Success 204
+// @Failure 401
+// @Failure 403
+// @Failure 404 "Project not found"
+func RouteUnmuteMember(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	if !access.moderator {
+		return auth.ErrForbidden
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	err = chatService.UnmuteMember(request.Context(), access.session.UserId(), access.projectId, userId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Connect to a project's chat
+// @Description A WebSocket that receives the project's chat events as JSON: {"type": "message", "message": {...}} when
+// @Description a message is posted, {"type": "message.deleted", "messageId": 1} when one is deleted and
+// @Description {"type": "heartbeat"} every 25 seconds. Messages are posted with POST /projects/{projectId}/chat/messages,
+// @Description clients don't need to send anything. Connections must come from CORS_ORIGIN.
+// @Tags chat
+// @Router /projects/{projectId}/chat/socket [get]
+// @Param projectId path int true "The project ID"
+// @Success 101
+// @Failure 400 "The request isn't a WebSocket handshake"
+// @Failure 401
+// @Failure 403 "The user isn't a member of the project"
+// @Failure 404 "Project not found or chat-disabled-error"
+func RouteConnect(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	chatService Service,
+) error {
+	access, err := checkAccess(request, projectsService, usersService)
+	if err != nil {
+		return err
+	}
+
+	channel, err := chatService.GetChannel(request.Context(), access.projectId, access.session.UserId())
+	if err != nil {
+		return err
+	}
+
+	if !channel.Enabled {
+		return ErrChatDisabled
+	}
+
+	server := websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			serveConnection(request.Context(), conn, chatService, access.projectId)
+		},
+	}
+
+	server.ServeHTTP(writer, request)
+
+	return nil
+}
+
+// Rejects handshakes from other sites, see utils.IsAllowedWebsocketOrigin.
+func checkOrigin(config *websocket.Config, request *http.Request) error {
+	if !utils.IsAllowedWebsocketOrigin(request) {
+		return errors.New("origin not allowed")
+	}
+
+	return nil
+}
+
+// Send the project's chat events until the connection is closed.
+func serveConnection(ctx context.Context, conn *websocket.Conn, chatService Service, projectId uint) {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	events, unsubscribe := chatService.Subscribe(projectId)
+	defer unsubscribe()
+
+	logger.Debug("Chat connection opened")
+	defer logger.Debug("Chat connection closed")
+
+	// Clients don't send anything but closing frames, reading only tells
+	// when the connection is closed
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, conn)
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(presence.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		var event EventDto
+
+		select {
+		case <-closed:
+			return
+		case event = <-events:
+		case <-ticker.C:
+			event = EventDto{Type: EventHeartbeat}
+		}
+
+		// Connections whose client went away without closing them fail to
+		// write
+		_ = conn.SetWriteDeadline(time.Now().Add(presence.HeartbeatInterval))
+
+		err := websocket.JSON.Send(conn, event)
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,761 @@
+package chat
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/undo"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrChatDisabled = errors.New("project chat is disabled")
+var ErrMessageNotFound = errors.New("chat message not found")
+var ErrMuted = errors.New("muted in project chat")
+var ErrNotMember = errors.New("user isn't a member of the project")
+var ErrCannotMuteOwner = errors.New("project owners can't be muted")
+
+// Types of the events sent on chat sockets, see EventDto.
+const (
+	EventMessage        = "message"
+	EventMessageDeleted = "message.deleted"
+	EventHeartbeat      = "heartbeat"
+)
+
+// The kind of the undo.Action of deleting a message, see RestoreMessage.
+const UndoDeleteMessage = "chat.message.delete"
+
+// The kv channel messages and deletions are published on, so that every
+// instance relays them to its sockets.
+const eventsChannel = "chat.events"
+
+// How many messages are listed per page by default, and at most.
+const DefaultPageSize = 50
+const MaxPageSize = 100
+
+// At most this many members are notified of being mentioned in a message.
+const maxMentions = 10
+
+// Events that sockets don't read fast enough are dropped once this many are
+// waiting.
+const socketBufferSize = 32
+
+// @username, not preceded by a word character so that emails aren't mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w])@([\w.-]+)`)
+
+type Service interface {
+	// Get whether a project's chat is turned on, and whether the user is muted.
+	GetChannel(ctx context.Context, projectId uint, userId uint) (ChannelDto, error)
+
+	// Turn a project's chat on or off. Turning it off keeps its messages.
+	SetEnabled(ctx context.Context, projectId uint, enabled bool) error
+
+	// List a page of a project's messages, newest first, posted before the
+	// message before (0 for the latest). Returns whether there are older
+	// messages. Messages of shadow restricted users are only listed to them.
+	// Returns ErrChatDisabled if the project's chat is turned off.
+	ListMessages(ctx context.Context, viewerId uint, projectId uint, before uint, pageSize int) ([]MessageDto, bool, error)
+
+	// Post a message and notify the members it mentions. Hidden messages
+	// (of shadow restricted users) are only shown to their author.
+	// Returns ErrChatDisabled if the project's chat is turned off and
+	// ErrMuted if the author is muted.
+	PostMessage(ctx context.Context, authorId uint, projectId uint, dto NewMessageDto, hidden bool) (MessageDto, error)
+
+	// Returns ErrMessageNotFound if the project doesn't have the message.
+	GetMessage(ctx context.Context, projectId uint, messageId uint) (MessageDto, error)
+
+	// Delete a message. Deletions by someone else than the message's author
+	// are recorded in the audit log.
+	// Returns ErrMessageNotFound if the project doesn't have the message.
+	DeleteMessage(ctx context.Context, deletedBy uint, projectId uint, messageId uint) error
+
+	// Undo the deletion of a message by the action's user, the handler of
+	// UndoDeleteMessage. Restorations by someone else than the message's
+	// author are recorded in the audit log.
+	// Returns ErrMessageNotFound if the user didn't delete the message.
+	RestoreMessage(ctx context.Context, action undo.Action) error
+
+	// Stop a member from posting, and record it in the audit log. Muting a
+	// muted member replaces their mute.
+	// Returns ErrNotMember if the user isn't a member of the project and
+	// ErrCannotMuteOwner if they're its owner.
+	MuteMember(ctx context.Context, mutedBy uint, projectId uint, userId uint, dto NewMuteDto) (MuteDto, error)
+
+	// Let a muted member post again, and record it in the audit log.
+	UnmuteMember(ctx context.Context, unmutedBy uint, projectId uint, userId uint) error
+
+	// List the project's muted members, most recently muted first.
+	ListMutes(ctx context.Context, projectId uint) ([]MuteDto, error)
+
+	// Receive the events of a project's chat until unsubscribe is called.
+	Subscribe(projectId uint) (events <-chan EventDto, unsubscribe func())
+
+	// Relay the messages and deletions of every instance to this instance's
+	// subscribers until ctx is done. Should be run in its own goroutine.
+	Run(ctx context.Context)
+}
+
+// An event published on eventsChannel. Messages are read from the database
+// by every instance rather than published, they can be larger than what
+// Postgres notifications can hold.
+type publishedEvent struct {
+	ProjectId uint   `json:"projectId"`
+	Type      string `json:"type"`
+	MessageId uint   `json:"messageId"`
+}
+
+type serviceImpl struct {
+	Db                   *gorm.DB
+	Kv                   kv.Store
+	ProjectsService      projects.Service
+	UsersService         users.Service
+	NotificationsService notifications.Service
+	AuditService         audit.Service
+
+	mutex sync.Mutex
+
+	// The channels of this instance's sockets, by project id
+	subscribers map[uint]map[chan EventDto]bool
+}
+
+func NewService(
+	db *gorm.DB,
+	kvStore kv.Store,
+	projectsService projects.Service,
+	usersService users.Service,
+	notificationsService notifications.Service,
+	auditService audit.Service,
+) Service {
+	return &serviceImpl{
+		Db:                   db,
+		Kv:                   kvStore,
+		ProjectsService:      projectsService,
+		UsersService:         usersService,
+		NotificationsService: notificationsService,
+		AuditService:         auditService,
+		subscribers:          map[uint]map[chan EventDto]bool{},
+	}
+}
+
+func (s *serviceImpl) GetChannel(ctx context.Context, projectId uint, userId uint) (ChannelDto, error) {
+	enabled, err := s.isEnabled(ctx, projectId)
+	if err != nil {
+		return ChannelDto{}, err
+	}
+
+	mute, err := s.activeMute(ctx, projectId, userId)
+	if err != nil {
+		return ChannelDto{}, err
+	}
+
+	dto := ChannelDto{ProjectId: projectId, Enabled: enabled}
+	if mute != nil {
+		dto.Muted = true
+		dto.MutedUntil = mute.Until
+	}
+
+	return dto, nil
+}
+
+func (s *serviceImpl) isEnabled(ctx context.Context, projectId uint) (bool, error) {
+	var channel Channel
+	result := s.Db.WithContext(ctx).Limit(1).Find(&channel, "project_id = ?", projectId)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("projectId", projectId).Error("Failed to get chat channel")
+
+		return false, result.Error
+	}
+
+	return channel.Enabled, nil
+}
+
+// The user's mute if they're muted, nil otherwise.
+func (s *serviceImpl) activeMute(ctx context.Context, projectId uint, userId uint) (*Mute, error) {
+	var mute Mute
+	result := s.Db.WithContext(ctx).Limit(1).Find(&mute, "project_id = ? AND user_id = ?", projectId, userId)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("projectId", projectId).Error("Failed to get chat mute")
+
+		return nil, result.Error
+	}
+
+	if result.RowsAffected < 1 || !mute.Active(time.Now()) {
+		return nil, nil
+	}
+
+	return &mute, nil
+}
+
+func (s *serviceImpl) SetEnabled(ctx context.Context, projectId uint, enabled bool) error {
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).
+		Create(&Channel{ProjectId: projectId, Enabled: enabled})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("projectId", projectId).Error("Failed to set chat channel")
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// Messages with their author's username.
+func (s *serviceImpl) messages(ctx context.Context) *gorm.DB {
+	return s.Db.WithContext(ctx).
+		Model(&Message{}).
+		Select("chat_messages.*, users.username AS author_username").
+		Joins("JOIN users ON users.id = chat_messages.author_id")
+}
+
+type messageWithAuthor struct {
+	Message
+	AuthorUsername string
+}
+
+func messageToDto(message messageWithAuthor) MessageDto {
+	mentions := make([]uint, len(message.Mentions))
+	for i, userId := range message.Mentions {
+		mentions[i] = uint(userId)
+	}
+
+	return MessageDto{
+		Id:             message.ID,
+		ProjectId:      message.ProjectId,
+		AuthorId:       message.AuthorId,
+		AuthorUsername: message.AuthorUsername,
+		Body:           message.Body,
+		Mentions:       mentions,
+		CreatedAt:      message.CreatedAt,
+	}
+}
+
+func (s *serviceImpl) ListMessages(ctx context.Context, viewerId uint, projectId uint, before uint, pageSize int) ([]MessageDto, bool, error) {
+	if pageSize < 1 || pageSize > MaxPageSize {
+		pageSize = DefaultPageSize
+	}
+
+	enabled, err := s.isEnabled(ctx, projectId)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !enabled {
+		return nil, false, ErrChatDisabled
+	}
+
+	query := s.messages(ctx).
+		Where("chat_messages.project_id = ? AND chat_messages.deleted_at IS NULL", projectId).
+		Where("chat_messages.hidden = ? OR chat_messages.author_id = ?", false, viewerId)
+
+	if before > 0 {
+		query = query.Where("chat_messages.id < ?", before)
+	}
+
+	// One more than the page tells whether there are older messages
+	var messages []messageWithAuthor
+	result := query.
+		Order("chat_messages.id desc").
+		Limit(pageSize + 1).
+		Scan(&messages)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("projectId", projectId).Error("Failed to list chat messages")
+
+		return nil, false, result.Error
+	}
+
+	hasMore := len(messages) > pageSize
+	if hasMore {
+		messages = messages[:pageSize]
+	}
+
+	dtos := make([]MessageDto, len(messages))
+	for i, message := range messages {
+		dtos[i] = messageToDto(message)
+	}
+
+	return dtos, hasMore, nil
+}
+
+func (s *serviceImpl) PostMessage(ctx context.Context, authorId uint, projectId uint, dto NewMessageDto, hidden bool) (MessageDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return MessageDto{}, err
+	}
+
+	enabled, err := s.isEnabled(ctx, projectId)
+	if err != nil {
+		return MessageDto{}, err
+	}
+
+	if !enabled {
+		return MessageDto{}, ErrChatDisabled
+	}
+
+	mute, err := s.activeMute(ctx, projectId, authorId)
+	if err != nil {
+		return MessageDto{}, err
+	}
+
+	if mute != nil {
+		return MessageDto{}, ErrMuted
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	mentions, err := s.mentionedMembers(ctx, projectId, authorId, dto.Body)
+	if err != nil {
+		return MessageDto{}, err
+	}
+
+	message := Message{
+		ProjectId: projectId,
+		AuthorId:  authorId,
+		Body:      dto.Body,
+		Mentions:  pq.Int64Array{},
+		Hidden:    hidden,
+	}
+
+	for _, userId := range mentions {
+		message.Mentions = append(message.Mentions, int64(userId))
+	}
+
+	result := s.Db.WithContext(ctx).Create(&message)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create chat message")
+
+		return MessageDto{}, result.Error
+	}
+
+	messageDto, err := s.GetMessage(ctx, projectId, message.ID)
+	if err != nil {
+		return MessageDto{}, err
+	}
+
+	// Nobody else sees hidden messages, so they aren't relayed nor notified
+	if !hidden {
+		s.publish(ctx, publishedEvent{ProjectId: projectId, Type: EventMessage, MessageId: message.ID})
+		s.notifyMentions(ctx, messageDto)
+	}
+
+	return messageDto, nil
+}
+
+// The ids of the members mentioned in a message, except its author. Unknown
+// usernames and users who aren't members are ignored.
+func (s *serviceImpl) mentionedMembers(ctx context.Context, projectId uint, authorId uint, body string) ([]uint, error) {
+	userIds := []uint{}
+	seen := map[string]bool{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// Usernames can't end with punctuation, e.g. "thanks @alice."
+		username := strings.TrimRight(match[1], ".-")
+		if username == "" || seen[strings.ToLower(username)] || len(userIds) >= maxMentions {
+			continue
+		}
+
+		seen[strings.ToLower(username)] = true
+
+		user, err := s.UsersService.FindUserByUsernameOrEmail(ctx, username)
+		if errors.Is(err, users.ErrUserNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if user.ID == authorId {
+			continue
+		}
+
+		member, err := s.ProjectsService.IsMember(ctx, projectId, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if member {
+			userIds = append(userIds, user.ID)
+		}
+	}
+
+	return userIds, nil
+}
+
+// Notify the members mentioned in a message. Failures are only logged, the
+// message has already been posted.
+func (s *serviceImpl) notifyMentions(ctx context.Context, message MessageDto) {
+	if len(message.Mentions) < 1 {
+		return
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", message.ProjectId)
+
+	project, err := s.ProjectsService.GetProject(ctx, message.ProjectId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get project, mentions won't be notified")
+
+		return
+	}
+
+	for _, userId := range message.Mentions {
+		err = s.NotificationsService.Notify(ctx, userId, notifications.NewNotificationDto{
+			Type:  notifications.TypeChatMention,
+			Title: fmt.Sprintf("%s mentioned you in %s", message.AuthorUsername, project.Name),
+			Body:  message.Body,
+			Data: map[string]interface{}{
+				"projectId": message.ProjectId,
+				"messageId": message.Id,
+			},
+		})
+		if err != nil {
+			logger.WithError(err).WithField("userId", userId).Error("Failed to notify of chat mention")
+		}
+	}
+}
+
+func (s *serviceImpl) GetMessage(ctx context.Context, projectId uint, messageId uint) (MessageDto, error) {
+	var messages []messageWithAuthor
+	result := s.messages(ctx).
+		Where("chat_messages.id = ? AND chat_messages.project_id = ? AND chat_messages.deleted_at IS NULL", messageId, projectId).
+		Limit(1).
+		Scan(&messages)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("messageId", messageId).Error("Failed to get chat message")
+
+		return MessageDto{}, result.Error
+	}
+
+	if len(messages) < 1 {
+		return MessageDto{}, ErrMessageNotFound
+	}
+
+	return messageToDto(messages[0]), nil
+}
+
+func (s *serviceImpl) DeleteMessage(ctx context.Context, deletedBy uint, projectId uint, messageId uint) error {
+	message, err := s.GetMessage(ctx, projectId, messageId)
+	if err != nil {
+		return err
+	}
+
+	logger := log.FromContext(ctx).WithField("messageId", messageId)
+
+	result := s.Db.WithContext(ctx).
+		Model(&Message{}).
+		Where("id = ? AND deleted_at IS NULL", messageId).
+		Updates(map[string]interface{}{
+			"deleted_at": time.Now(),
+			"deleted_by": deletedBy,
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to delete chat message")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrMessageNotFound
+	}
+
+	if deletedBy != message.AuthorId {
+		err = s.AuditService.Record(ctx, deletedBy, "chat.message.delete", "chat-message", messageId, map[string]interface{}{
+			"projectId": projectId,
+			"authorId":  message.AuthorId,
+			"body":      message.Body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.publish(ctx, publishedEvent{ProjectId: projectId, Type: EventMessageDeleted, MessageId: messageId})
+
+	return nil
+}
+
+func (s *serviceImpl) RestoreMessage(ctx context.Context, action undo.Action) error {
+	logger := log.FromContext(ctx).WithField("messageId", action.TargetId)
+
+	var message Message
+	result := s.Db.WithContext(ctx).
+		Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL AND deleted_by = ?", action.TargetId, action.UserId).
+		Limit(1).
+		Find(&message)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get deleted chat message")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrMessageNotFound
+	}
+
+	result = s.Db.WithContext(ctx).
+		Unscoped().
+		Model(&Message{}).
+		Where("id = ? AND deleted_at IS NOT NULL", message.ID).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"deleted_by": nil,
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to restore chat message")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrMessageNotFound
+	}
+
+	if action.UserId != message.AuthorId {
+		err := s.AuditService.Record(ctx, action.UserId, "chat.message.restore", "chat-message", message.ID, map[string]interface{}{
+			"projectId": message.ProjectId,
+			"authorId":  message.AuthorId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	s.publish(ctx, publishedEvent{ProjectId: message.ProjectId, Type: EventMessage, MessageId: message.ID})
+
+	return nil
+}
+
+func (s *serviceImpl) MuteMember(ctx context.Context, mutedBy uint, projectId uint, userId uint, dto NewMuteDto) (MuteDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return MuteDto{}, err
+	}
+
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return MuteDto{}, err
+	}
+
+	if project.OwnerId == userId {
+		return MuteDto{}, ErrCannotMuteOwner
+	}
+
+	member, err := s.ProjectsService.IsMember(ctx, projectId, userId)
+	if err != nil {
+		return MuteDto{}, err
+	}
+
+	if !member {
+		return MuteDto{}, ErrNotMember
+	}
+
+	mute := Mute{
+		ProjectId: projectId,
+		UserId:    userId,
+		MutedBy:   mutedBy,
+		CreatedAt: time.Now(),
+	}
+
+	if dto.Minutes != nil {
+		until := mute.CreatedAt.Add(time.Duration(*dto.Minutes) * time.Minute)
+		mute.Until = &until
+	}
+
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"muted_by", "until", "created_at"}),
+		}).
+		Create(&mute)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("projectId", projectId).Error("Failed to mute chat member")
+
+		return MuteDto{}, result.Error
+	}
+
+	err = s.AuditService.Record(ctx, mutedBy, "chat.mute", "user", userId, map[string]interface{}{
+		"projectId": projectId,
+		"until":     mute.Until,
+	})
+	if err != nil {
+		return MuteDto{}, err
+	}
+
+	return muteToDto(mute), nil
+}
+
+func (s *serviceImpl) UnmuteMember(ctx context.Context, unmutedBy uint, projectId uint, userId uint) error {
+	result := s.Db.WithContext(ctx).Delete(&Mute{}, "project_id = ? AND user_id = ?", projectId, userId)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("projectId", projectId).Error("Failed to unmute chat member")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return nil
+	}
+
+	return s.AuditService.Record(ctx, unmutedBy, "chat.unmute", "user", userId, map[string]interface{}{
+		"projectId": projectId,
+	})
+}
+
+func (s *serviceImpl) ListMutes(ctx context.Context, projectId uint) ([]MuteDto, error) {
+	var mutes []Mute
+	result := s.Db.WithContext(ctx).
+		Where("project_id = ? AND (until IS NULL OR until > ?)", projectId, time.Now()).
+		Order("created_at desc").
+		Find(&mutes)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("projectId", projectId).Error("Failed to list chat mutes")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]MuteDto, len(mutes))
+	for i, mute := range mutes {
+		dtos[i] = muteToDto(mute)
+	}
+
+	return dtos, nil
+}
+
+func muteToDto(mute Mute) MuteDto {
+	return MuteDto{
+		UserId:    mute.UserId,
+		MutedBy:   mute.MutedBy,
+		Until:     mute.Until,
+		CreatedAt: mute.CreatedAt,
+	}
+}
+
+// Relay an event to every instance's subscribers. Failures are only logged,
+// sockets miss the event but it's in the history.
+func (s *serviceImpl) publish(ctx context.Context, event publishedEvent) {
+	message, err := json.Marshal(event)
+	if err == nil {
+		err = s.Kv.Publish(ctx, eventsChannel, string(message))
+	}
+
+	if err != nil {
+		log.FromContext(ctx).WithError(err).WithField("projectId", event.ProjectId).Error("Failed to publish chat event")
+	}
+}
+
+func (s *serviceImpl) Subscribe(projectId uint) (<-chan EventDto, func()) {
+	events := make(chan EventDto, socketBufferSize)
+
+	s.mutex.Lock()
+	if s.subscribers[projectId] == nil {
+		s.subscribers[projectId] = map[chan EventDto]bool{}
+	}
+	s.subscribers[projectId][events] = true
+	s.mutex.Unlock()
+
+	unsubscribe := func() {
+		s.mutex.Lock()
+		delete(s.subscribers[projectId], events)
+		if len(s.subscribers[projectId]) < 1 {
+			delete(s.subscribers, projectId)
+		}
+		s.mutex.Unlock()
+	}
+
+	return events, unsubscribe
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	messages, err := s.Kv.Subscribe(ctx, eventsChannel)
+	if err != nil {
+		logger.WithError(err).Error("Failed to subscribe to chat events")
+
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				if ctx.Err() == nil {
+					logger.Warn("Chat events subscription closed")
+				}
+
+				return
+			}
+
+			s.relay(ctx, message)
+		}
+	}
+}
+
+// Send a published event to this instance's subscribers of its project.
+func (s *serviceImpl) relay(ctx context.Context, message string) {
+	var published publishedEvent
+	err := json.Unmarshal([]byte(message), &published)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Warn("Ignoring invalid chat event")
+
+		return
+	}
+
+	s.mutex.Lock()
+	subscribed := len(s.subscribers[published.ProjectId]) > 0
+	s.mutex.Unlock()
+
+	if !subscribed {
+		return
+	}
+
+	event := EventDto{Type: published.Type, MessageId: published.MessageId}
+	if published.Type == EventMessage {
+		message, err := s.GetMessage(ctx, published.ProjectId, published.MessageId)
+		if err != nil {
+			// e.g. deleted right away
+			return
+		}
+
+		event.Message = &message
+		event.MessageId = 0
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for events := range s.subscribers[published.ProjectId] {
+		select {
+		case events <- event:
+		default:
+			log.FromContext(ctx).WithField("projectId", published.ProjectId).Warn("Chat socket is too slow, dropping event")
+		}
+	}
+}
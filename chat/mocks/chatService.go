@@ -0,0 +1,210 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: chatService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	chat "github.com/open-collaboration/server/chat"
+	undo "github.com/open-collaboration/server/undo"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetChannel mocks base method
+func (m *MockService) GetChannel(ctx context.Context, projectId, userId uint) (chat.ChannelDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChannel", ctx, projectId, userId)
+	ret0, _ := ret[0].(chat.ChannelDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChannel indicates an expected call of GetChannel
+func (mr *MockServiceMockRecorder) GetChannel(ctx, projectId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChannel", reflect.TypeOf((*MockService)(nil).GetChannel), ctx, projectId, userId)
+}
+
+// SetEnabled mocks base method
+func (m *MockService) SetEnabled(ctx context.Context, projectId uint, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetEnabled", ctx, projectId, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetEnabled indicates an expected call of SetEnabled
+func (mr *MockServiceMockRecorder) SetEnabled(ctx, projectId, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetEnabled", reflect.TypeOf((*MockService)(nil).SetEnabled), ctx, projectId, enabled)
+}
+
+// ListMessages mocks base method
+func (m *MockService) ListMessages(ctx context.Context, viewerId, projectId, before uint, pageSize int) ([]chat.MessageDto, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMessages", ctx, viewerId, projectId, before, pageSize)
+	ret0, _ := ret[0].([]chat.MessageDto)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListMessages indicates an expected call of ListMessages
+func (mr *MockServiceMockRecorder) ListMessages(ctx, viewerId, projectId, before, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMessages", reflect.TypeOf((*MockService)(nil).ListMessages), ctx, viewerId, projectId, before, pageSize)
+}
+
+// PostMessage mocks base method
+func (m *MockService) PostMessage(ctx context.Context, authorId, projectId uint, dto chat.NewMessageDto, hidden bool) (chat.MessageDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostMessage", ctx, authorId, projectId, dto, hidden)
+	ret0, _ := ret[0].(chat.MessageDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PostMessage indicates an expected call of PostMessage
+func (mr *MockServiceMockRecorder) PostMessage(ctx, authorId, projectId, dto, hidden interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostMessage", reflect.TypeOf((*MockService)(nil).PostMessage), ctx, authorId, projectId, dto, hidden)
+}
+
+// GetMessage mocks base method
+func (m *MockService) GetMessage(ctx context.Context, projectId, messageId uint) (chat.MessageDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessage", ctx, projectId, messageId)
+	ret0, _ := ret[0].(chat.MessageDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessage indicates an expected call of GetMessage
+func (mr *MockServiceMockRecorder) GetMessage(ctx, projectId, messageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessage", reflect.TypeOf((*MockService)(nil).GetMessage), ctx, projectId, messageId)
+}
+
+// DeleteMessage mocks base method
+func (m *MockService) DeleteMessage(ctx context.Context, deletedBy, projectId, messageId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", ctx, deletedBy, projectId, messageId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage
+func (mr *MockServiceMockRecorder) DeleteMessage(ctx, deletedBy, projectId, messageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockService)(nil).DeleteMessage), ctx, deletedBy, projectId, messageId)
+}
+
+// RestoreMessage mocks base method
+func (m *MockService) RestoreMessage(ctx context.Context, action undo.Action) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreMessage", ctx, action)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreMessage indicates an expected call of RestoreMessage
+func (mr *MockServiceMockRecorder) RestoreMessage(ctx, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreMessage", reflect.TypeOf((*MockService)(nil).RestoreMessage), ctx, action)
+}
+
+// MuteMember mocks base method
+func (m *MockService) MuteMember(ctx context.Context, mutedBy, projectId, userId uint, dto chat.NewMuteDto) (chat.MuteDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MuteMember", ctx, mutedBy, projectId, userId, dto)
+	ret0, _ := ret[0].(chat.MuteDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MuteMember indicates an expected call of MuteMember
+func (mr *MockServiceMockRecorder) MuteMember(ctx, mutedBy, projectId, userId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MuteMember", reflect.TypeOf((*MockService)(nil).MuteMember), ctx, mutedBy, projectId, userId, dto)
+}
+
+// UnmuteMember mocks base method
+func (m *MockService) UnmuteMember(ctx context.Context, unmutedBy, projectId, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmuteMember", ctx, unmutedBy, projectId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmuteMember indicates an expected call of UnmuteMember
+func (mr *MockServiceMockRecorder) UnmuteMember(ctx, unmutedBy, projectId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmuteMember", reflect.TypeOf((*MockService)(nil).UnmuteMember), ctx, unmutedBy, projectId, userId)
+}
+
+// ListMutes mocks base method
+func (m *MockService) ListMutes(ctx context.Context, projectId uint) ([]chat.MuteDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMutes", ctx, projectId)
+	ret0, _ := ret[0].([]chat.MuteDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMutes indicates an expected call of ListMutes
+func (mr *MockServiceMockRecorder) ListMutes(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMutes", reflect.TypeOf((*MockService)(nil).ListMutes), ctx, projectId)
+}
+
+// Subscribe mocks base method
+func (m *MockService) Subscribe(projectId uint) (<-chan chat.EventDto, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", projectId)
+	ret0, _ := ret[0].(<-chan chat.EventDto)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockServiceMockRecorder) Subscribe(projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockService)(nil).Subscribe), projectId)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
@@ -0,0 +1,42 @@
+package collections
+
+import "time"
+
+type NewCollectionDto struct {
+	Name           string `json:"name" validate:"required,min=4,max=64"`
+	Description    string `json:"description" validate:"max=5000"`
+	BannerImageUrl string `json:"bannerImageUrl" validate:"omitempty,url,max=500"`
+	WebsiteUrl     string `json:"websiteUrl" validate:"omitempty,url,max=500"`
+
+	// When the event takes place, both optional
+	StartsAt *time.Time `json:"startsAt"`
+	EndsAt   *time.Time `json:"endsAt"`
+
+	// When projects can be added to the collection. A missing bound leaves
+	// the window open on that side.
+	JoinOpensAt  *time.Time `json:"joinOpensAt"`
+	JoinClosesAt *time.Time `json:"joinClosesAt"`
+}
+
+type CollectionDto struct {
+	Id             uint       `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	BannerImageUrl string     `json:"bannerImageUrl"`
+	WebsiteUrl     string     `json:"websiteUrl"`
+	StartsAt       *time.Time `json:"startsAt"`
+	EndsAt         *time.Time `json:"endsAt"`
+	JoinOpensAt    *time.Time `json:"joinOpensAt"`
+	JoinClosesAt   *time.Time `json:"joinClosesAt"`
+	OrganizerId    uint       `json:"organizerId"`
+
+	// Whether projects can currently be added to the collection
+	JoinOpen bool `json:"joinOpen"`
+
+	// Listed projects in the collection, see GET /projects?collection=
+	ProjectCount int64 `json:"projectCount"`
+}
+
+type AddProjectDto struct {
+	ProjectId uint `json:"projectId" validate:"required"`
+}
@@ -0,0 +1,44 @@
+package collections
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// A group of projects, e.g. the teams of a hackathon. Collections are created
+// by organizers (users.RoleOrganizer), and project owners add their projects
+// to them while the collection's join window is open.
+type Collection struct {
+	gorm.Model
+
+	Name           string
+	Description    string
+	BannerImageUrl string
+	WebsiteUrl     string
+
+	// When the event takes place, nil if the collection didn't say
+	StartsAt *time.Time
+	EndsAt   *time.Time
+
+	// When projects can be added to the collection. A nil bound leaves the
+	// window open on that side.
+	JoinOpensAt  *time.Time
+	JoinClosesAt *time.Time
+
+	// The organizer who created the collection and manages it, along with
+	// moderators
+	OrganizerId uint
+}
+
+// Whether projects can be added to the collection at the given time.
+func (c *Collection) JoinOpen(now time.Time) bool {
+	return (c.JoinOpensAt == nil || !now.Before(*c.JoinOpensAt)) &&
+		(c.JoinClosesAt == nil || now.Before(*c.JoinClosesAt))
+}
+
+// A project added to a collection.
+type CollectionProject struct {
+	CollectionId uint `gorm:"primaryKey"`
+	ProjectId    uint `gorm:"primaryKey"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,279 @@
+package collections
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+)
+
+// @Summary List collections
+// @Description Collections group projects, e.g. the teams of a hackathon. The collections starting last come first.
+// @Description The projects of a collection are listed with GET /projects?collection={collectionId}.
+// @Tags collections
+// @Router /collections [get]
+// @Success 200 {array} collections.CollectionDto
+func RouteListCollections(writer http.ResponseWriter, request *http.Request, collectionsService Service) error {
+	collections, err := collectionsService.ListCollections(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, collections)
+}
+
+// @Summary Get a collection
+// @Tags collections
+// @Router /collections/{collectionId} [get]
+// @Param collectionId path int true "The collection ID"
+// @Success 200 {object} collections.CollectionDto
+// @Failure 404
+func RouteGetCollection(writer http.ResponseWriter, request *http.Request, collectionsService Service) error {
+	collectionId, err := utils.UintFromVars(request, "collectionId")
+	if err != nil {
+		return err
+	}
+
+	collection, err := collectionsService.GetCollection(request.Context(), collectionId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, collection)
+}
+
+// @Summary Create a collection
+// @Description Only organizers can create collections. The collection is managed by the organizer who created it
+// @Description and by moderators.
+// @Tags collections
+// @Router /collections [post]
+// @Param collection body collections.NewCollectionDto true "The collection"
+// @Success 201 {object} collections.CollectionDto
+// @Failure 400 "The event or the join window ends before it starts"
+// @Failure 403
+func RouteCreateCollection(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	collectionsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleOrganizer)
+	if err != nil {
+		return err
+	}
+
+	dto := NewCollectionDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	collection, err := collectionsService.CreateCollection(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("Location", "/collections/"+strconv.Itoa(int(collection.Id)))
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, collection)
+}
+
+// @Summary Update a collection
+// @Description Only the collection's organizer and moderators can update it.
+// @Tags collections
+// @Router /collections/{collectionId} [put]
+// @Param collectionId path int true "The collection ID"
+// @Param collection body collections.NewCollectionDto true "The collection"
+// @Success 200 {object} collections.CollectionDto
+// @Failure 400 "The event or the join window ends before it starts"
+// @Failure 403
+// @Failure 404
+func RouteUpdateCollection(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	collectionsService Service,
+) error {
+	collectionId, err := checkCollectionManager(request, usersService, collectionsService)
+	if err != nil {
+		return err
+	}
+
+	dto := NewCollectionDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	collection, err := collectionsService.UpdateCollection(request.Context(), collectionId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, collection)
+}
+
+// @Summary Delete a collection
+// @Description Only the collection's organizer and moderators can delete it. Its projects aren't deleted.
+// @Tags collections
+// @Router /collections/{collectionId} [delete]
+// @Param collectionId path int true "The collection ID"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteDeleteCollection(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	collectionsService Service,
+) error {
+	collectionId, err := checkCollectionManager(request, usersService, collectionsService)
+	if err != nil {
+		return err
+	}
+
+	err = collectionsService.DeleteCollection(request.Context(), collectionId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Add a project to a collection
+// @Description Only the project's owner can add it, while the collection's join window is open.
+// @Tags collections
+// @Router /collections/{collectionId}/projects [post]
+// @Param collectionId path int true "The collection ID"
+// @Param project body collections.AddProjectDto true "The project"
+// @Success 204
+// @Failure 403 "User does not own the project"
+// @Failure 404 "Collection or project not found"
+// @Failure 409 "The collection's join window is closed"
+func RouteAddProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	collectionsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	collectionId, err := utils.UintFromVars(request, "collectionId")
+	if err != nil {
+		return err
+	}
+
+	dto := AddProjectDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), dto.ProjectId)
+	if err != nil {
+		return err
+	}
+
+	if project.OwnerId != session.UserId() {
+		return auth.ErrForbidden
+	}
+
+	err = collectionsService.AddProject(request.Context(), collectionId, dto.ProjectId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Remove a project from a collection
+// @Description The project's owner, the collection's organizer and moderators can remove projects, even after
+// @Description the join window closed.
+// @Tags collections
+// @Router /collections/{collectionId}/projects/{projectId} [delete]
+// @Param collectionId path int true "The collection ID"
+// @Param projectId path int true "The project ID"
+// @Success 204
+// @Failure 403
+// @Failure 404 "The project isn't in the collection"
+func RouteRemoveProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	projectsService projects.Service,
+	collectionsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	collectionId, err := utils.UintFromVars(request, "collectionId")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	if project.OwnerId != session.UserId() {
+		_, err = checkCollectionManager(request, usersService, collectionsService)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = collectionsService.RemoveProject(request.Context(), collectionId, projectId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// Check that the request's session belongs to the organizer of the collection
+// in the collectionId route variable, or to a moderator. Returns the
+// collection's id.
+func checkCollectionManager(request *http.Request, usersService users.Service, collectionsService Service) (uint, error) {
+	session, err := auth.CheckRole(request, usersService, users.RoleOrganizer)
+	if err != nil {
+		return 0, err
+	}
+
+	collectionId, err := utils.UintFromVars(request, "collectionId")
+	if err != nil {
+		return 0, err
+	}
+
+	collection, err := collectionsService.GetCollection(request.Context(), collectionId)
+	if err != nil {
+		return 0, err
+	}
+
+	if collection.OrganizerId != session.UserId() {
+		_, err = auth.CheckRole(request, usersService, users.RoleModerator)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return collectionId, nil
+}
@@ -0,0 +1,346 @@
+package collections
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/cdn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrCollectionNotFound = errors.New("collection not found")
+var ErrProjectNotInCollection = errors.New("project is not in the collection")
+var ErrJoinWindowClosed = errors.New("the collection's join window is closed")
+var ErrInvalidDates = errors.New("a collection can't end before it starts")
+
+type Service interface {
+	// List all collections, the ones starting last first. Collections
+	// without a start date are listed last, newest to oldest.
+	ListCollections(ctx context.Context) ([]CollectionDto, error)
+
+	// Returns ErrCollectionNotFound if the collection can't be found.
+	GetCollection(ctx context.Context, collectionId uint) (CollectionDto, error)
+
+	// Create a collection managed by the given organizer.
+	// Returns ErrInvalidDates if the event or the join window ends before it
+	// starts.
+	CreateCollection(ctx context.Context, organizerId uint, dto NewCollectionDto) (CollectionDto, error)
+
+	// Returns ErrCollectionNotFound if the collection can't be found and
+	// ErrInvalidDates like CreateCollection.
+	UpdateCollection(ctx context.Context, collectionId uint, dto NewCollectionDto) (CollectionDto, error)
+
+	// Delete a collection. Its projects are left untouched.
+	// Returns ErrCollectionNotFound if the collection can't be found.
+	DeleteCollection(ctx context.Context, collectionId uint) error
+
+	// Add a project to a collection. Adding a project that's already in the
+	// collection does nothing.
+	// Returns ErrCollectionNotFound if the collection can't be found and
+	// ErrJoinWindowClosed if the collection's join window isn't open.
+	AddProject(ctx context.Context, collectionId uint, projectId uint) error
+
+	// Remove a project from a collection, regardless of the join window.
+	// Returns ErrProjectNotInCollection if the project isn't in the collection.
+	RemoveProject(ctx context.Context, collectionId uint, projectId uint) error
+}
+
+type serviceImpl struct {
+	Db  *gorm.DB
+	Cdn cdn.Service
+}
+
+// Create a collections service. Project listings are purged from the CDN
+// whenever a collection's projects change, since they can be filtered by
+// collection.
+func NewService(db *gorm.DB, cdnService cdn.Service) Service {
+	return &serviceImpl{
+		Db:  db,
+		Cdn: cdnService,
+	}
+}
+
+func (s *serviceImpl) ListCollections(ctx context.Context) ([]CollectionDto, error) {
+	logger := log.FromContext(ctx)
+
+	var collections []Collection
+	result := s.Db.WithContext(ctx).
+		Order("starts_at desc nulls last").
+		Order("created_at desc").
+		Find(&collections)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list collections")
+
+		return nil, result.Error
+	}
+
+	ids := make([]uint, len(collections))
+	for i, collection := range collections {
+		ids[i] = collection.ID
+	}
+
+	counts, err := s.countProjects(ctx, ids)
+	if err != nil {
+		logger.WithError(err).Error("Failed to count the collections' projects")
+
+		return nil, err
+	}
+
+	now := time.Now()
+
+	dtos := make([]CollectionDto, len(collections))
+	for i, collection := range collections {
+		dtos[i] = collectionToDto(collection, counts[collection.ID], now)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) GetCollection(ctx context.Context, collectionId uint) (CollectionDto, error) {
+	collection, err := s.findCollection(ctx, collectionId)
+	if err != nil {
+		return CollectionDto{}, err
+	}
+
+	counts, err := s.countProjects(ctx, []uint{collectionId})
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to count the collection's projects")
+
+		return CollectionDto{}, err
+	}
+
+	return collectionToDto(collection, counts[collectionId], time.Now()), nil
+}
+
+func (s *serviceImpl) CreateCollection(ctx context.Context, organizerId uint, dto NewCollectionDto) (CollectionDto, error) {
+	err := validateCollection(dto)
+	if err != nil {
+		return CollectionDto{}, err
+	}
+
+	collection := Collection{OrganizerId: organizerId}
+	applyCollectionDto(&collection, dto)
+
+	result := s.Db.WithContext(ctx).Create(&collection)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to create collection")
+
+		return CollectionDto{}, result.Error
+	}
+
+	return collectionToDto(collection, 0, time.Now()), nil
+}
+
+func (s *serviceImpl) UpdateCollection(ctx context.Context, collectionId uint, dto NewCollectionDto) (CollectionDto, error) {
+	err := validateCollection(dto)
+	if err != nil {
+		return CollectionDto{}, err
+	}
+
+	collection, err := s.findCollection(ctx, collectionId)
+	if err != nil {
+		return CollectionDto{}, err
+	}
+
+	applyCollectionDto(&collection, dto)
+
+	// Save so that cleared dates are written too
+	result := s.Db.WithContext(ctx).Save(&collection)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to update collection")
+
+		return CollectionDto{}, result.Error
+	}
+
+	counts, err := s.countProjects(ctx, []uint{collectionId})
+	if err != nil {
+		return CollectionDto{}, err
+	}
+
+	return collectionToDto(collection, counts[collectionId], time.Now()), nil
+}
+
+func (s *serviceImpl) DeleteCollection(ctx context.Context, collectionId uint) error {
+	logger := log.FromContext(ctx).WithField("collectionId", collectionId)
+
+	err := s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&Collection{}, collectionId)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected < 1 {
+			return ErrCollectionNotFound
+		}
+
+		return tx.Where("collection_id = ?", collectionId).Delete(&CollectionProject{}).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrCollectionNotFound) {
+			logger.WithError(err).Error("Failed to delete collection")
+		}
+
+		return err
+	}
+
+	s.Cdn.Purge(ctx, cdn.TagProjects)
+
+	return nil
+}
+
+func (s *serviceImpl) AddProject(ctx context.Context, collectionId uint, projectId uint) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"collectionId": collectionId,
+		"projectId":    projectId,
+	})
+
+	collection, err := s.findCollection(ctx, collectionId)
+	if err != nil {
+		return err
+	}
+
+	if !collection.JoinOpen(time.Now()) {
+		return ErrJoinWindowClosed
+	}
+
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&CollectionProject{
+			CollectionId: collectionId,
+			ProjectId:    projectId,
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to add project to collection")
+
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Info("Project added to collection")
+
+		s.Cdn.Purge(ctx, cdn.TagProjects)
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) RemoveProject(ctx context.Context, collectionId uint, projectId uint) error {
+	result := s.Db.WithContext(ctx).
+		Where("collection_id = ? AND project_id = ?", collectionId, projectId).
+		Delete(&CollectionProject{})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to remove project from collection")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrProjectNotInCollection
+	}
+
+	s.Cdn.Purge(ctx, cdn.TagProjects)
+
+	return nil
+}
+
+func (s *serviceImpl) findCollection(ctx context.Context, collectionId uint) (Collection, error) {
+	collection := Collection{}
+	result := s.Db.WithContext(ctx).First(&collection, collectionId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return Collection{}, ErrCollectionNotFound
+		}
+
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for collection")
+
+		return Collection{}, result.Error
+	}
+
+	return collection, nil
+}
+
+// Count the listed projects of each collection, the same projects listed by
+// GET /projects?collection=, by collection id.
+func (s *serviceImpl) countProjects(ctx context.Context, collectionIds []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(collectionIds))
+	if len(collectionIds) < 1 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		CollectionId uint
+		Count        int64
+	}
+
+	result := s.Db.WithContext(ctx).
+		Model(&CollectionProject{}).
+		Select("collection_projects.collection_id, count(*) AS count").
+		Joins("JOIN projects ON projects.id = collection_projects.project_id").
+		Where("collection_projects.collection_id IN ?", collectionIds).
+		Where("projects.deleted_at IS NULL AND projects.pending_review = false AND projects.draft = false").
+		Group("collection_projects.collection_id").
+		Scan(&rows)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, row := range rows {
+		counts[row.CollectionId] = row.Count
+	}
+
+	return counts, nil
+}
+
+func validateCollection(dto NewCollectionDto) error {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return err
+	}
+
+	if dto.StartsAt != nil && dto.EndsAt != nil && dto.EndsAt.Before(*dto.StartsAt) {
+		return ErrInvalidDates
+	}
+
+	if dto.JoinOpensAt != nil && dto.JoinClosesAt != nil && dto.JoinClosesAt.Before(*dto.JoinOpensAt) {
+		return ErrInvalidDates
+	}
+
+	return nil
+}
+
+func applyCollectionDto(collection *Collection, dto NewCollectionDto) {
+	collection.Name = dto.Name
+	collection.Description = dto.Description
+	collection.BannerImageUrl = dto.BannerImageUrl
+	collection.WebsiteUrl = dto.WebsiteUrl
+	collection.StartsAt = dto.StartsAt
+	collection.EndsAt = dto.EndsAt
+	collection.JoinOpensAt = dto.JoinOpensAt
+	collection.JoinClosesAt = dto.JoinClosesAt
+}
+
+func collectionToDto(collection Collection, projectCount int64, now time.Time) CollectionDto {
+	return CollectionDto{
+		Id:             collection.ID,
+		Name:           collection.Name,
+		Description:    collection.Description,
+		BannerImageUrl: collection.BannerImageUrl,
+		WebsiteUrl:     collection.WebsiteUrl,
+		StartsAt:       collection.StartsAt,
+		EndsAt:         collection.EndsAt,
+		JoinOpensAt:    collection.JoinOpensAt,
+		JoinClosesAt:   collection.JoinClosesAt,
+		OrganizerId:    collection.OrganizerId,
+		JoinOpen:       collection.JoinOpen(now),
+		ProjectCount:   projectCount,
+	}
+}
@@ -0,0 +1,137 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: collectionsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	collections "github.com/open-collaboration/server/collections"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ListCollections mocks base method
+func (m *MockService) ListCollections(ctx context.Context) ([]collections.CollectionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCollections", ctx)
+	ret0, _ := ret[0].([]collections.CollectionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCollections indicates an expected call of ListCollections
+func (mr *MockServiceMockRecorder) ListCollections(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollections", reflect.TypeOf((*MockService)(nil).ListCollections), ctx)
+}
+
+// GetCollection mocks base method
+func (m *MockService) GetCollection(ctx context.Context, collectionId uint) (collections.CollectionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCollection", ctx, collectionId)
+	ret0, _ := ret[0].(collections.CollectionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCollection indicates an expected call of GetCollection
+func (mr *MockServiceMockRecorder) GetCollection(ctx, collectionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCollection", reflect.TypeOf((*MockService)(nil).GetCollection), ctx, collectionId)
+}
+
+// CreateCollection mocks base method
+func (m *MockService) CreateCollection(ctx context.Context, organizerId uint, dto collections.NewCollectionDto) (collections.CollectionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCollection", ctx, organizerId, dto)
+	ret0, _ := ret[0].(collections.CollectionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCollection indicates an expected call of CreateCollection
+func (mr *MockServiceMockRecorder) CreateCollection(ctx, organizerId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCollection", reflect.TypeOf((*MockService)(nil).CreateCollection), ctx, organizerId, dto)
+}
+
+// UpdateCollection mocks base method
+func (m *MockService) UpdateCollection(ctx context.Context, collectionId uint, dto collections.NewCollectionDto) (collections.CollectionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCollection", ctx, collectionId, dto)
+	ret0, _ := ret[0].(collections.CollectionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCollection indicates an expected call of UpdateCollection
+func (mr *MockServiceMockRecorder) UpdateCollection(ctx, collectionId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCollection", reflect.TypeOf((*MockService)(nil).UpdateCollection), ctx, collectionId, dto)
+}
+
+// DeleteCollection mocks base method
+func (m *MockService) DeleteCollection(ctx context.Context, collectionId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCollection", ctx, collectionId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCollection indicates an expected call of DeleteCollection
+func (mr *MockServiceMockRecorder) DeleteCollection(ctx, collectionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCollection", reflect.TypeOf((*MockService)(nil).DeleteCollection), ctx, collectionId)
+}
+
+// AddProject mocks base method
+func (m *MockService) AddProject(ctx context.Context, collectionId, projectId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProject", ctx, collectionId, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddProject indicates an expected call of AddProject
+func (mr *MockServiceMockRecorder) AddProject(ctx, collectionId, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProject", reflect.TypeOf((*MockService)(nil).AddProject), ctx, collectionId, projectId)
+}
+
+// RemoveProject mocks base method
+func (m *MockService) RemoveProject(ctx context.Context, collectionId, projectId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveProject", ctx, collectionId, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveProject indicates an expected call of RemoveProject
+func (mr *MockServiceMockRecorder) RemoveProject(ctx, collectionId, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveProject", reflect.TypeOf((*MockService)(nil).RemoveProject), ctx, collectionId, projectId)
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/app"
+	"github.com/open-collaboration/server/backup"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/encryption"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/search"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Key (in the kv store) of the cursor of the last interrupted reindex.
+const reindexCursorKey = "search.reindex:cursor"
+
+// How often the reindex progress is logged.
+const reindexProgressInterval = 5 * time.Second
+
+var errUnknownCommand = errors.New("unknown command")
+var errSqliteBackup = errors.New("backups need a Postgres database, copy the SQLite file instead")
+
+// Run the command given as arguments instead of serving, e.g.
+// `server search reindex`.
+func runCommand(args []string) error {
+	if len(args) >= 1 && args[0] == "serve" {
+		return runServe(args[1:])
+	}
+
+	if len(args) >= 2 && args[0] == "search" && args[1] == "reindex" {
+		return runSearchReindex(args[2:])
+	}
+
+	if len(args) >= 2 && args[0] == "encryption" && args[1] == "reencrypt" {
+		return runEncryptionReencrypt(args[2:])
+	}
+
+	if len(args) >= 2 && args[0] == "backup" {
+		switch args[1] {
+		case "create":
+			return runBackupCreate(args[2:])
+		case "verify":
+			return runBackupVerify(args[2:])
+		case "restore":
+			return runBackupRestore(args[2:])
+		}
+	}
+
+	return fmt.Errorf("%w: %s", errUnknownCommand, strings.Join(args, " "))
+}
+
+// Run the server, like running without a command, on the database of --db
+// instead of DATABASE_URL, e.g. `server serve --db sqlite://dev.db`.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	db := flags.String("db", "", "the postgres:// or sqlite:// url of the database")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	config := app.LoadConfig()
+	if *db != "" {
+		err = config.SetDatabaseUrl(*db)
+		if err != nil {
+			return err
+		}
+	}
+
+	return runServer(config)
+}
+
+// Regenerate the embeddings of all projects, e.g. after changing the
+// embedding model. An interrupted reindex (Ctrl+C or a failure) can be
+// resumed with --resume.
+func runSearchReindex(args []string) error {
+	flags := flag.NewFlagSet("search reindex", flag.ContinueOnError)
+	concurrency := flags.Int("concurrency", 4, "how many batches are embedded at the same time")
+	batchSize := flags.Int("batch-size", 50, "how many projects are embedded per embedding provider request")
+	resume := flags.Bool("resume", false, "continue the last interrupted reindex")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	application, err := app.New(app.LoadConfig())
+	if err != nil {
+		return err
+	}
+
+	var searchService search.Service
+	for _, provider := range application.Providers {
+		if service, ok := provider.(search.Service); ok {
+			searchService = service
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	options := search.ReindexOptions{
+		Concurrency: *concurrency,
+		BatchSize:   *batchSize,
+	}
+
+	if *resume {
+		cursor, err := application.Kv.Get(ctx, reindexCursorKey)
+		if err == nil {
+			afterId, err := strconv.ParseUint(cursor, 10, 0)
+			if err != nil {
+				return err
+			}
+
+			options.AfterId = uint(afterId)
+		} else if !errors.Is(err, kv.ErrNotFound) {
+			return err
+		}
+	}
+
+	log.WithField("afterId", options.AfterId).Info("Reindexing projects")
+
+	lastLog := time.Time{}
+	err = searchService.Reindex(ctx, options, func(progress search.ReindexProgress) {
+		// The cursor is saved even if ctx is done, so that the reindex can be resumed
+		err := application.Kv.Set(context.Background(), reindexCursorKey, strconv.FormatUint(uint64(progress.Cursor), 10), 0)
+		if err != nil {
+			log.WithError(err).Warn("Failed to save the reindex cursor")
+		}
+
+		if time.Since(lastLog) >= reindexProgressInterval || progress.Indexed == progress.Total {
+			lastLog = time.Now()
+
+			percent := int64(100)
+			if progress.Total > 0 {
+				percent = progress.Indexed * 100 / progress.Total
+			}
+
+			log.WithFields(log.Fields{
+				"indexed": progress.Indexed,
+				"total":   progress.Total,
+				"cursor":  progress.Cursor,
+			}).Infof("Reindexed %d%% of the projects", percent)
+		}
+	})
+
+	if err != nil {
+		if !errors.Is(err, search.ErrSemanticSearchDisabled) {
+			log.Info("Reindex interrupted, run it again with --resume to continue")
+		}
+
+		return err
+	}
+
+	err = application.Kv.Del(context.Background(), reindexCursorKey)
+	if err != nil {
+		log.WithError(err).Warn("Failed to delete the reindex cursor")
+	}
+
+	log.Info("Reindexed all projects")
+
+	return nil
+}
+
+// The database and redis of the configuration, for the backup commands. The
+// database isn't migrated. There's no redis to back up if the key value store
+// is Postgres.
+func backupTarget(config app.Config) (backup.Target, error) {
+	if _, ok := database.SqlitePath(config.DatabaseUrl); ok {
+		return backup.Target{}, errSqliteBackup
+	}
+
+	db, err := app.OpenDatabase(config)
+	if err != nil {
+		return backup.Target{}, err
+	}
+
+	target := backup.Target{
+		Db:          db,
+		PostgresDsn: config.PostgresDsn,
+	}
+
+	if config.RedisAddr != "" {
+		target.RedisHost, target.RedisPort, err = net.SplitHostPort(config.RedisAddr)
+		if err != nil {
+			return backup.Target{}, err
+		}
+	}
+
+	return target, nil
+}
+
+// Back up postgres, redis and the media manifest into a directory.
+func runBackupCreate(args []string) error {
+	flags := flag.NewFlagSet("backup create", flag.ContinueOnError)
+	dir := flags.String("dir", filepath.Join("backups", time.Now().UTC().Format("20060102-150405")), "directory to write the backup to")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	target, err := backupTarget(app.LoadConfig())
+	if err != nil {
+		return err
+	}
+
+	manifest, err := backup.Create(context.Background(), target, *dir)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"dir":           *dir,
+		"schemaVersion": manifest.SchemaVersion,
+	}).Info("Backup created")
+
+	return nil
+}
+
+// Check that a backup is complete and undamaged.
+func runBackupVerify(args []string) error {
+	flags := flag.NewFlagSet("backup verify", flag.ContinueOnError)
+	dir := flags.String("dir", "", "directory of the backup")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		return errors.New("--dir is required")
+	}
+
+	manifest, err := backup.Verify(context.Background(), *dir)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"dir":           *dir,
+		"createdAt":     manifest.CreatedAt,
+		"schemaVersion": manifest.SchemaVersion,
+	}).Info("Backup verified")
+
+	return nil
+}
+
+// Replace the database's contents with a backup's. The server should be
+// stopped while restoring.
+func runBackupRestore(args []string) error {
+	flags := flag.NewFlagSet("backup restore", flag.ContinueOnError)
+	dir := flags.String("dir", "", "directory of the backup")
+	redisDir := flags.String("redis-dir", "", "redis' data directory to copy the redis snapshot to, optional")
+	confirm := flags.Bool("yes", false, "confirm that the database's contents are replaced")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		return errors.New("--dir is required")
+	}
+
+	if !*confirm {
+		return errors.New("restoring replaces the database's contents, confirm with --yes")
+	}
+
+	target, err := backupTarget(app.LoadConfig())
+	if err != nil {
+		return err
+	}
+
+	err = backup.Restore(context.Background(), target, *dir, *redisDir)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("dir", *dir).Info("Backup restored")
+
+	return nil
+}
+
+// Encrypt the encrypted columns' values with the latest key, after adding a
+// key or enabling encryption. Old keys can be removed once it's done.
+func runEncryptionReencrypt(args []string) error {
+	flags := flag.NewFlagSet("encryption reencrypt", flag.ContinueOnError)
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	config := app.LoadConfig()
+	if len(config.EncryptionKeys) < 1 {
+		return errors.New("ENCRYPTION_KEYS is empty, there's no key to encrypt with")
+	}
+
+	encryptionService, err := encryption.NewService(config.EncryptionKeys)
+	if err != nil {
+		return err
+	}
+
+	db, err := app.OpenDatabase(config)
+	if err != nil {
+		return err
+	}
+
+	reencrypted, err := encryption.ReencryptColumns(context.Background(), db, encryptionService, app.EncryptedColumns)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("reencrypted", reencrypted).Info("Re-encrypted all columns")
+
+	return nil
+}
@@ -3,4 +3,5 @@ package consts
 const (
 	RequestIdKey = "request-id-ck"
 	LoggerKey    = "logger-ck"
+	ClientIpKey  = "client-ip-ck"
 )
@@ -0,0 +1,36 @@
+package contributions
+
+import "time"
+
+type NewContributionDto struct {
+	// The member who made the contribution
+	UserId      uint   `json:"userId" validate:"required"`
+	Title       string `json:"title" validate:"required,min=4,max=100"`
+	Description string `json:"description" validate:"max=2000"`
+	Url         string `json:"url" validate:"omitempty,url,max=500"`
+
+	// Defaults to now
+	OccurredAt *time.Time `json:"occurredAt"`
+}
+
+type ContributionDto struct {
+	Id          uint      `json:"id"`
+	ProjectId   uint      `json:"projectId"`
+	ProjectName string    `json:"projectName"`
+	UserId      uint      `json:"userId"`
+	Source      Source    `json:"source"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Url         string    `json:"url"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+type ImportResultDto struct {
+	// Contributions that weren't imported before
+	Imported int `json:"imported"`
+}
+
+type ImportProjectDto struct {
+	// e.g. https://github.com/open-collaboration/server
+	RepositoryUrl string `json:"repositoryUrl" validate:"required,max=500"`
+}
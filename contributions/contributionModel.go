@@ -0,0 +1,40 @@
+package contributions
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// Where a contribution comes from.
+type Source string
+
+const (
+	// Logged by the project's owner.
+	SourceManual Source = "manual"
+
+	// A merged pull request imported from the project's GitHub repository.
+	SourceGithub Source = "github"
+)
+
+// A milestone a member reached in a project, e.g. a merged pull request or a
+// shipped feature. Contributions are only logged by project owners or
+// imported, so they make up a user's verified collaboration history.
+type Contribution struct {
+	gorm.Model
+
+	ProjectId   uint
+	UserId      uint
+	Source      Source
+	Title       string
+	Description string
+
+	// A link to the contribution, e.g. the pull request. Can be empty.
+	Url string
+
+	OccurredAt time.Time
+
+	// The id of the contribution in its source, e.g. the pull request number,
+	// so that imports don't duplicate contributions. nil for manual
+	// contributions.
+	ExternalId *string
+}
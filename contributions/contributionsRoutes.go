@@ -0,0 +1,225 @@
+package contributions
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+)
+
+// @Summary List a project's contributions
+// @Description Contributions of members who hide their memberships are only listed to them and to the project's owner.
+// @Tags contributions
+// @Router /projects/{projectId}/contributions [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {array} contributions.ContributionDto
+func RouteListProjectContributions(writer http.ResponseWriter, request *http.Request, contributionsService Service) error {
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	var viewerId uint
+	session, err := auth.CheckSession(request)
+	if err == nil {
+		viewerId = session.UserId()
+	}
+
+	contributions, err := contributionsService.ListProjectContributions(request.Context(), projectId, viewerId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, contributions)
+}
+
+// @Summary List a user's collaboration history
+// @Description The contributions the user made to projects they were accepted to, as logged by the
+// @Description projects' owners or imported from GitHub. Empty for users who hide their memberships, except to
+// @Description themselves. Users who hide their profile from anonymous visitors look like they don't exist to them.
+// @Tags contributions
+// @Router /users/{userId}/contributions [get]
+// @Param userId path int true "The user ID"
+// @Param fields query string false "Comma separated fields of the contributions to include in the response, e.g. projectName,title,occurredAt. All fields by default."
+// @Success 200 {array} contributions.ContributionDto
+// @Failure 404
+func RouteListUserContributions(
+	writer http.ResponseWriter,
+	request *http.Request,
+	contributionsService Service,
+	usersService users.Service,
+) error {
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	user, viewerId, err := auth.CheckProfileVisible(request, usersService, userId)
+	if err != nil {
+		return err
+	}
+
+	contributions := []ContributionDto{}
+	if user.MembershipsVisibleTo(viewerId) {
+		contributions, err = contributionsService.ListUserContributions(request.Context(), userId)
+		if err != nil {
+			return err
+		}
+	}
+
+	response, err := utils.SelectFields(contributions, utils.FieldsFromQuery(request))
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, response)
+}
+
+// @Summary Log a member's contribution
+// @Description Only the project's owner and members with the edit-project permission can log contributions, and
+// @Description only for users accepted to the project.
+// @Tags contributions
+// @Router /projects/{projectId}/contributions [post]
+// @Param projectId path int true "The project ID"
+// @Param contribution body contributions.NewContributionDto true "The contribution"
+// @Success 201 {object} contributions.ContributionDto
+// @Failure 400 "The user isn't a member of the project"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteLogContribution(
+	writer http.ResponseWriter,
+	request *http.Request,
+	contributionsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	dto := NewContributionDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	contribution, err := contributionsService.LogContribution(request.Context(), projectId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, contribution)
+}
+
+// @Summary Delete a contribution
+// @Description Only the project's owner and members with the edit-project permission can delete its contributions,
+// @Description imported ones included.
+// @Tags contributions
+// @Router /projects/{projectId}/contributions/{contributionId} [delete]
+// @Param projectId path int true "The project ID"
+// @Param contributionId path int true "The contribution ID"
+// @Success 204
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteDeleteContribution(
+	writer http.ResponseWriter,
+	request *http.Request,
+	contributionsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	contributionId, err := utils.UintFromVars(request, "contributionId")
+	if err != nil {
+		return err
+	}
+
+	err = contributionsService.DeleteContribution(request.Context(), projectId, contributionId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Import contributions from GitHub
+// @Description Import the merged pull requests of the project's GitHub repository made by its owner or members,
+// @Description matched through the GitHub accounts they linked. Pull requests imported before are skipped.
+// @Tags contributions
+// @Router /projects/{projectId}/contributions/import [post]
+// @Param projectId path int true "The project ID"
+// @Success 200 {object} contributions.ImportResultDto
+// @Failure 400 "The project's link isn't a GitHub repository"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteImportGithubContributions(
+	writer http.ResponseWriter,
+	request *http.Request,
+	contributionsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	importResult, err := contributionsService.ImportGithubContributions(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, importResult)
+}
+
+// @Summary Import a project from GitHub
+// @Description Creates a draft project pre-filled from a public GitHub repository: its name, description, topics as
+// @Description tags, license and languages as the project's tech stack. Topics, licenses and languages that don't fit
+// @Description the project's rules, the license catalog or the skills taxonomy are left out. The draft is only visible
+// @Description to its owner, who completes it (POST /projects/{projectId}) before publishing it
+// @Description (POST /projects/{projectId}/publish).
+// @Tags projects
+// @Router /projects/import-from-github [post]
+// @Param repository body contributions.ImportProjectDto true "The repository"
+// @Success 201 {object} dtos.ProjectSummaryDto
+// @Failure 400 "The URL isn't a public GitHub repository's, or the name has banned words"
+// @Failure 401
+// @Failure 403 "User can't post"
+func RouteImportGithubProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	contributionsService Service,
+	projectsService projects.Service,
+	accountStatusProvider auth.AccountStatusProvider,
+) error {
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
+	if err != nil {
+		return err
+	}
+
+	dto := ImportProjectDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	project, err := contributionsService.ImportGithubProject(request.Context(), session.UserId(), dto, accountStatus.ShadowHidden)
+	if err != nil {
+		return err
+	}
+
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
+	writer.Header().Set("Location", "/projects/"+strconv.Itoa(int(project.ID)))
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, projectsService.GetProjectSummary(project))
+}
@@ -0,0 +1,339 @@
+package contributions
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/applications"
+	"github.com/open-collaboration/server/identities"
+	"github.com/open-collaboration/server/projects"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strconv"
+	"time"
+)
+
+var ErrContributionNotFound = errors.New("contribution not found")
+var ErrNotMember = errors.New("user isn't a member of the project")
+
+type Service interface {
+	// List a project's contributions as seen by viewerId (0 for anonymous
+	// visitors), newest to oldest. Contributions of members who hide their
+	// memberships are only listed to themselves and to the project's owner.
+	ListProjectContributions(ctx context.Context, projectId uint, viewerId uint) ([]ContributionDto, error)
+
+	// List a user's contributions to projects that are listed (not deleted or
+	// pending review), newest to oldest.
+	ListUserContributions(ctx context.Context, userId uint) ([]ContributionDto, error)
+
+	// Log a member's contribution to a project.
+	// Returns ErrNotMember if the user wasn't accepted to the project.
+	LogContribution(ctx context.Context, projectId uint, dto NewContributionDto) (ContributionDto, error)
+
+	// Returns ErrContributionNotFound if the project doesn't have the
+	// contribution.
+	DeleteContribution(ctx context.Context, projectId uint, contributionId uint) error
+
+	// Import the merged pull requests of the project's GitHub repository whose
+	// authors are the project's owner or members, through the GitHub identities
+	// they linked. Pull requests imported before are skipped, even if their
+	// contribution was deleted since.
+	// Returns ErrNotGithubRepository if the project's link isn't a GitHub
+	// repository.
+	ImportGithubContributions(ctx context.Context, projectId uint) (ImportResultDto, error)
+
+	// Create a draft project owned by the given user from a GitHub
+	// repository: its name, description, topics as tags, license and
+	// languages, see projects.Service.ImportProject.
+	// Returns ErrNotGithubRepository if the URL isn't a public GitHub
+	// repository's.
+	ImportGithubProject(ctx context.Context, ownerId uint, dto ImportProjectDto, pendingReview bool) (*projects.Project, error)
+}
+
+type serviceImpl struct {
+	Db              *gorm.DB
+	ProjectsService projects.Service
+	GithubClient    GithubClient
+}
+
+func NewService(db *gorm.DB, projectsService projects.Service, githubClient GithubClient) Service {
+	return &serviceImpl{
+		Db:              db,
+		ProjectsService: projectsService,
+		GithubClient:    githubClient,
+	}
+}
+
+func (s *serviceImpl) ListProjectContributions(ctx context.Context, projectId uint, viewerId uint) ([]ContributionDto, error) {
+	return s.listContributions(ctx, s.Db.WithContext(ctx).
+		Joins("JOIN users ON users.id = contributions.user_id").
+		Where("contributions.project_id = ?", projectId).
+		Where("users.hide_memberships = false OR users.id = ? OR projects.owner_id = ?", viewerId, viewerId))
+}
+
+func (s *serviceImpl) ListUserContributions(ctx context.Context, userId uint) ([]ContributionDto, error) {
+	return s.listContributions(ctx, s.Db.WithContext(ctx).
+		Where("contributions.user_id = ?", userId).
+		Where("projects.pending_review = false AND projects.draft = false"))
+}
+
+func (s *serviceImpl) LogContribution(ctx context.Context, projectId uint, dto NewContributionDto) (ContributionDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId": projectId,
+		"userId":    dto.UserId,
+	})
+
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return ContributionDto{}, err
+	}
+
+	members, err := s.listMembers(ctx, projectId)
+	if err != nil {
+		return ContributionDto{}, err
+	}
+
+	if !members[dto.UserId] {
+		return ContributionDto{}, ErrNotMember
+	}
+
+	occurredAt := time.Now()
+	if dto.OccurredAt != nil {
+		occurredAt = *dto.OccurredAt
+	}
+
+	contribution := Contribution{
+		ProjectId:   projectId,
+		UserId:      dto.UserId,
+		Source:      SourceManual,
+		Title:       dto.Title,
+		Description: dto.Description,
+		Url:         dto.Url,
+		OccurredAt:  occurredAt,
+	}
+
+	result := s.Db.WithContext(ctx).Create(&contribution)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to log contribution")
+
+		return ContributionDto{}, result.Error
+	}
+
+	logger.Debug("Contribution logged")
+
+	return contributionToDto(contribution, ""), nil
+}
+
+func (s *serviceImpl) DeleteContribution(ctx context.Context, projectId uint, contributionId uint) error {
+	result := s.Db.WithContext(ctx).
+		Where("id = ? AND project_id = ?", contributionId, projectId).
+		Delete(&Contribution{})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete contribution")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrContributionNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ImportGithubProject(ctx context.Context, ownerId uint, dto ImportProjectDto, pendingReview bool) (*projects.Project, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, repositoryName, err := ParseRepositoryUrl(dto.RepositoryUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	repository, err := s.GithubClient.GetRepository(ctx, owner, repositoryName)
+	if err != nil {
+		if !errors.Is(err, ErrNotGithubRepository) {
+			log.FromContext(ctx).
+				WithError(err).
+				WithField("repository", owner+"/"+repositoryName).
+				Error("Failed to get GitHub repository")
+		}
+
+		return nil, err
+	}
+
+	return s.ProjectsService.ImportProject(ctx, ownerId, projects.ImportedProjectDto{
+		Name:        repository.Name,
+		Description: repository.Description,
+		GithubLink:  repository.Url,
+		Topics:      repository.Topics,
+		License:     repository.License,
+		Languages:   repository.Languages,
+	}, pendingReview)
+}
+
+func (s *serviceImpl) ImportGithubContributions(ctx context.Context, projectId uint) (ImportResultDto, error) {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return ImportResultDto{}, err
+	}
+
+	owner, repository, err := ParseRepositoryUrl(project.GithubLink)
+	if err != nil {
+		return ImportResultDto{}, err
+	}
+
+	members, err := s.listMembers(ctx, projectId)
+	if err != nil {
+		return ImportResultDto{}, err
+	}
+
+	// Imported contributions are verified by GitHub, so the owner's count too
+	members[project.OwnerId] = true
+
+	memberIds := make([]uint, 0, len(members))
+	for userId := range members {
+		memberIds = append(memberIds, userId)
+	}
+
+	var githubIdentities []identities.Identity
+	result := s.Db.WithContext(ctx).
+		Where("provider = ? AND user_id IN ?", "github", memberIds).
+		Find(&githubIdentities)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list the members' GitHub identities")
+
+		return ImportResultDto{}, result.Error
+	}
+
+	if len(githubIdentities) < 1 {
+		return ImportResultDto{}, nil
+	}
+
+	usersByGithubId := make(map[string]uint, len(githubIdentities))
+	for _, identity := range githubIdentities {
+		usersByGithubId[identity.Subject] = identity.UserId
+	}
+
+	pullRequests, err := s.GithubClient.ListMergedPullRequests(ctx, owner, repository)
+	if err != nil {
+		if !errors.Is(err, ErrNotGithubRepository) {
+			logger.WithError(err).Error("Failed to list the repository's pull requests")
+		}
+
+		return ImportResultDto{}, err
+	}
+
+	var contributions []Contribution
+	for _, pullRequest := range pullRequests {
+		userId, ok := usersByGithubId[pullRequest.AuthorId]
+		if !ok {
+			continue
+		}
+
+		externalId := strconv.Itoa(pullRequest.Number)
+		contributions = append(contributions, Contribution{
+			ProjectId:  projectId,
+			UserId:     userId,
+			Source:     SourceGithub,
+			Title:      pullRequest.Title,
+			Url:        pullRequest.Url,
+			OccurredAt: pullRequest.MergedAt,
+			ExternalId: &externalId,
+		})
+	}
+
+	if len(contributions) < 1 {
+		return ImportResultDto{}, nil
+	}
+
+	result = s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "source"}, {Name: "external_id"}},
+			DoNothing: true,
+		}).
+		Create(&contributions)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to save imported contributions")
+
+		return ImportResultDto{}, result.Error
+	}
+
+	logger.Infof("Imported %d contributions from GitHub", result.RowsAffected)
+
+	return ImportResultDto{Imported: int(result.RowsAffected)}, nil
+}
+
+// The ids of the users accepted to a project.
+func (s *serviceImpl) listMembers(ctx context.Context, projectId uint) (map[uint]bool, error) {
+	var memberIds []uint
+	result := s.Db.WithContext(ctx).
+		Model(&applications.Application{}).
+		Where("project_id = ? AND status = ?", projectId, applications.StatusAccepted).
+		Distinct().
+		Pluck("applicant_id", &memberIds)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list project members")
+
+		return nil, result.Error
+	}
+
+	members := make(map[uint]bool, len(memberIds))
+	for _, userId := range memberIds {
+		members[userId] = true
+	}
+
+	return members, nil
+}
+
+func (s *serviceImpl) listContributions(ctx context.Context, query *gorm.DB) ([]ContributionDto, error) {
+	var contributions []struct {
+		Contribution
+		ProjectName string
+	}
+
+	result := query.
+		Model(&Contribution{}).
+		Select("contributions.*, projects.name AS project_name").
+		Joins("JOIN projects ON projects.id = contributions.project_id AND projects.deleted_at IS NULL").
+		Order("contributions.occurred_at desc").
+		Scan(&contributions)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list contributions")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]ContributionDto, len(contributions))
+	for i, contribution := range contributions {
+		dtos[i] = contributionToDto(contribution.Contribution, contribution.ProjectName)
+	}
+
+	return dtos, nil
+}
+
+func contributionToDto(contribution Contribution, projectName string) ContributionDto {
+	return ContributionDto{
+		Id:          contribution.ID,
+		ProjectId:   contribution.ProjectId,
+		ProjectName: projectName,
+		UserId:      contribution.UserId,
+		Source:      contribution.Source,
+		Title:       contribution.Title,
+		Description: contribution.Description,
+		Url:         contribution.Url,
+		OccurredAt:  contribution.OccurredAt,
+	}
+}
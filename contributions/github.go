@@ -0,0 +1,371 @@
+package contributions
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/open-collaboration/server/breaker"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrNotGithubRepository = errors.New("the project's link isn't a GitHub repository")
+var ErrReadmeNotFound = errors.New("the repository doesn't have a README")
+
+// Pull requests are listed 100 per page, at most this many pages per import.
+const maxPullRequestPages = 10
+
+// At most this much of a README is read.
+const maxReadmeBytes = 512 * 1024
+
+// A merged pull request.
+type PullRequest struct {
+	Number   int
+	Title    string
+	Url      string
+	MergedAt time.Time
+
+	// The GitHub id of the author, as stored in identities.Identity.Subject
+	AuthorId string
+}
+
+// A repository's details.
+type Repository struct {
+	Name        string
+	Description string
+	Url         string
+	Topics      []string
+
+	// SPDX identifier, empty if GitHub doesn't recognize the license
+	License string
+
+	// The repository's languages, the most used first
+	Languages []string
+}
+
+// A GithubClient reads repositories through the GitHub API.
+type GithubClient interface {
+	// List the repository's merged pull requests, most recently updated first.
+	ListMergedPullRequests(ctx context.Context, owner string, repository string) ([]PullRequest, error)
+
+	// Get the raw contents of the repository's README, as rendered on its
+	// page. Returns ErrReadmeNotFound if the repository doesn't exist or
+	// doesn't have a README.
+	GetReadme(ctx context.Context, owner string, repository string) (string, error)
+
+	// Get the repository's details and languages.
+	// Returns ErrNotGithubRepository if the repository doesn't exist or is
+	// private.
+	GetRepository(ctx context.Context, owner string, repository string) (Repository, error)
+}
+
+type githubClient struct {
+	// Optional, raises the API's rate limit
+	Token string
+}
+
+// Create a GitHub client. token may be empty, in which case requests are
+// unauthenticated and limited to 60 per hour.
+func NewGithubClient(token string) GithubClient {
+	return &githubClient{
+		Token: token,
+	}
+}
+
+func (c *githubClient) ListMergedPullRequests(ctx context.Context, owner string, repository string) ([]PullRequest, error) {
+	var pullRequests []PullRequest
+
+	for page := 1; page <= maxPullRequestPages; page++ {
+		listUrl := fmt.Sprintf(
+			"https://api.github.com/repos/%s/%s/pulls?state=closed&sort=updated&direction=desc&per_page=100&page=%d",
+			url.PathEscape(owner),
+			url.PathEscape(repository),
+			page,
+		)
+
+		request, err := http.NewRequestWithContext(ctx, "GET", listUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		request.Header.Set("Accept", "application/vnd.github+json")
+		if c.Token != "" {
+			request.Header.Set("Authorization", "Bearer "+c.Token)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		var results []struct {
+			Number   int        `json:"number"`
+			Title    string     `json:"title"`
+			HtmlUrl  string     `json:"html_url"`
+			MergedAt *time.Time `json:"merged_at"`
+			User     struct {
+				Id int64 `json:"id"`
+			} `json:"user"`
+		}
+
+		if response.StatusCode == http.StatusNotFound {
+			response.Body.Close()
+			return nil, ErrNotGithubRepository
+		} else if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("github request failed with status %d", response.StatusCode)
+		}
+
+		err = json.NewDecoder(response.Body).Decode(&results)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range results {
+			// Closed pull requests that weren't merged
+			if result.MergedAt == nil {
+				continue
+			}
+
+			pullRequests = append(pullRequests, PullRequest{
+				Number:   result.Number,
+				Title:    result.Title,
+				Url:      result.HtmlUrl,
+				MergedAt: *result.MergedAt,
+				AuthorId: fmt.Sprint(result.User.Id),
+			})
+		}
+
+		if len(results) < 100 {
+			break
+		}
+	}
+
+	return pullRequests, nil
+}
+
+func (c *githubClient) GetReadme(ctx context.Context, owner string, repository string) (string, error) {
+	readmeUrl := fmt.Sprintf(
+		"https://api.github.com/repos/%s/%s/readme",
+		url.PathEscape(owner),
+		url.PathEscape(repository),
+	)
+
+	request, err := http.NewRequestWithContext(ctx, "GET", readmeUrl, nil)
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Accept", "application/vnd.github.raw")
+	if c.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", ErrReadmeNotFound
+	} else if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("github request failed with status %d", response.StatusCode)
+	}
+
+	// READMEs bigger than this aren't worth showing whole anyway
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, maxReadmeBytes))
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+func (c *githubClient) GetRepository(ctx context.Context, owner string, repository string) (Repository, error) {
+	repositoryUrl := fmt.Sprintf(
+		"https://api.github.com/repos/%s/%s",
+		url.PathEscape(owner),
+		url.PathEscape(repository),
+	)
+
+	var details struct {
+		Name        string   `json:"name"`
+		Description string   `json:"description"`
+		HtmlUrl     string   `json:"html_url"`
+		Topics      []string `json:"topics"`
+		License     *struct {
+			SpdxId string `json:"spdx_id"`
+		} `json:"license"`
+	}
+
+	err := c.getJson(ctx, repositoryUrl, &details)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	// Bytes of code per language
+	var languages map[string]int64
+
+	err = c.getJson(ctx, repositoryUrl+"/languages", &languages)
+	if err != nil {
+		return Repository{}, err
+	}
+
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if languages[names[i]] != languages[names[j]] {
+			return languages[names[i]] > languages[names[j]]
+		}
+
+		return names[i] < names[j]
+	})
+
+	result := Repository{
+		Name:        details.Name,
+		Description: details.Description,
+		Url:         details.HtmlUrl,
+		Topics:      details.Topics,
+		Languages:   names,
+	}
+
+	if details.License != nil && details.License.SpdxId != "NOASSERTION" {
+		result.License = details.License.SpdxId
+	}
+
+	return result, nil
+}
+
+// Decode the JSON response of a GET request to the API into result.
+// Returns ErrNotGithubRepository if the response is a 404.
+func (c *githubClient) getJson(ctx context.Context, requestUrl string, result interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, "GET", requestUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return ErrNotGithubRepository
+	} else if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("github request failed with status %d", response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// Get the owner and name of a GitHub repository from its URL, e.g.
+// https://github.com/open-collaboration/server.
+// Returns ErrNotGithubRepository if the URL isn't a GitHub repository's.
+func ParseRepositoryUrl(repositoryUrl string) (string, string, error) {
+	repositoryUrl = strings.TrimSpace(repositoryUrl)
+	if !strings.Contains(repositoryUrl, "://") {
+		repositoryUrl = "https://" + repositoryUrl
+	}
+
+	parsed, err := url.Parse(repositoryUrl)
+	if err != nil || (parsed.Host != "github.com" && parsed.Host != "www.github.com") {
+		return "", "", ErrNotGithubRepository
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", ErrNotGithubRepository
+	}
+
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// Guards a GitHub client's requests with a circuit breaker. Missing
+// repositories and READMEs are the project's fault and don't count as
+// failures.
+type breakerGithubClient struct {
+	GithubClient
+	Breaker *breaker.Breaker
+}
+
+func NewBreakerGithubClient(client GithubClient, breaker *breaker.Breaker) GithubClient {
+	return &breakerGithubClient{
+		GithubClient: client,
+		Breaker:      breaker,
+	}
+}
+
+func (c *breakerGithubClient) ListMergedPullRequests(ctx context.Context, owner string, repository string) ([]PullRequest, error) {
+	var pullRequests []PullRequest
+
+	err := c.guard(ctx, func() error {
+		var err error
+		pullRequests, err = c.GithubClient.ListMergedPullRequests(ctx, owner, repository)
+
+		return err
+	})
+
+	return pullRequests, err
+}
+
+func (c *breakerGithubClient) GetReadme(ctx context.Context, owner string, repository string) (string, error) {
+	var readme string
+
+	err := c.guard(ctx, func() error {
+		var err error
+		readme, err = c.GithubClient.GetReadme(ctx, owner, repository)
+
+		return err
+	})
+
+	return readme, err
+}
+
+func (c *breakerGithubClient) GetRepository(ctx context.Context, owner string, repository string) (Repository, error) {
+	var result Repository
+
+	err := c.guard(ctx, func() error {
+		var err error
+		result, err = c.GithubClient.GetRepository(ctx, owner, repository)
+
+		return err
+	})
+
+	return result, err
+}
+
+// Call fn if the breaker allows it and record whether GitHub failed.
+func (c *breakerGithubClient) guard(ctx context.Context, fn func() error) error {
+	err := c.Breaker.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	if err != nil && !errors.Is(err, ErrNotGithubRepository) && !errors.Is(err, ErrReadmeNotFound) && ctx.Err() == nil {
+		c.Breaker.Failure()
+	} else {
+		c.Breaker.Success()
+	}
+
+	return err
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: contributionsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	contributions "github.com/open-collaboration/server/contributions"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ListProjectContributions mocks base method
+func (m *MockService) ListProjectContributions(ctx context.Context, projectId, viewerId uint) ([]contributions.ContributionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectContributions", ctx, projectId, viewerId)
+	ret0, _ := ret[0].([]contributions.ContributionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjectContributions indicates an expected call of ListProjectContributions
+func (mr *MockServiceMockRecorder) ListProjectContributions(ctx, projectId, viewerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectContributions", reflect.TypeOf((*MockService)(nil).ListProjectContributions), ctx, projectId, viewerId)
+}
+
+// ListUserContributions mocks base method
+func (m *MockService) ListUserContributions(ctx context.Context, userId uint) ([]contributions.ContributionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserContributions", ctx, userId)
+	ret0, _ := ret[0].([]contributions.ContributionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserContributions indicates an expected call of ListUserContributions
+func (mr *MockServiceMockRecorder) ListUserContributions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserContributions", reflect.TypeOf((*MockService)(nil).ListUserContributions), ctx, userId)
+}
+
+// LogContribution mocks base method
+func (m *MockService) LogContribution(ctx context.Context, projectId uint, dto contributions.NewContributionDto) (contributions.ContributionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogContribution", ctx, projectId, dto)
+	ret0, _ := ret[0].(contributions.ContributionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LogContribution indicates an expected call of LogContribution
+func (mr *MockServiceMockRecorder) LogContribution(ctx, projectId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogContribution", reflect.TypeOf((*MockService)(nil).LogContribution), ctx, projectId, dto)
+}
+
+// DeleteContribution mocks base method
+func (m *MockService) DeleteContribution(ctx context.Context, projectId, contributionId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteContribution", ctx, projectId, contributionId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteContribution indicates an expected call of DeleteContribution
+func (mr *MockServiceMockRecorder) DeleteContribution(ctx, projectId, contributionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContribution", reflect.TypeOf((*MockService)(nil).DeleteContribution), ctx, projectId, contributionId)
+}
+
+// ImportGithubContributions mocks base method
+func (m *MockService) ImportGithubContributions(ctx context.Context, projectId uint) (contributions.ImportResultDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportGithubContributions", ctx, projectId)
+	ret0, _ := ret[0].(contributions.ImportResultDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportGithubContributions indicates an expected call of ImportGithubContributions
+func (mr *MockServiceMockRecorder) ImportGithubContributions(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportGithubContributions", reflect.TypeOf((*MockService)(nil).ImportGithubContributions), ctx, projectId)
+}
+
+// ImportGithubProject mocks base method
+func (m *MockService) ImportGithubProject(ctx context.Context, ownerId uint, dto contributions.ImportProjectDto, pendingReview bool) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportGithubProject", ctx, ownerId, dto, pendingReview)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportGithubProject indicates an expected call of ImportGithubProject
+func (mr *MockServiceMockRecorder) ImportGithubProject(ctx, ownerId, dto, pendingReview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportGithubProject", reflect.TypeOf((*MockService)(nil).ImportGithubProject), ctx, ownerId, dto, pendingReview)
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	contributions "github.com/open-collaboration/server/contributions"
+	reflect "reflect"
+)
+
+// MockGithubClient is a mock of GithubClient interface
+type MockGithubClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockGithubClientMockRecorder
+}
+
+// MockGithubClientMockRecorder is the mock recorder for MockGithubClient
+type MockGithubClientMockRecorder struct {
+	mock *MockGithubClient
+}
+
+// NewMockGithubClient creates a new mock instance
+func NewMockGithubClient(ctrl *gomock.Controller) *MockGithubClient {
+	mock := &MockGithubClient{ctrl: ctrl}
+	mock.recorder = &MockGithubClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGithubClient) EXPECT() *MockGithubClientMockRecorder {
+	return m.recorder
+}
+
+// ListMergedPullRequests mocks base method
+func (m *MockGithubClient) ListMergedPullRequests(ctx context.Context, owner, repository string) ([]contributions.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMergedPullRequests", ctx, owner, repository)
+	ret0, _ := ret[0].([]contributions.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMergedPullRequests indicates an expected call of ListMergedPullRequests
+func (mr *MockGithubClientMockRecorder) ListMergedPullRequests(ctx, owner, repository interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMergedPullRequests", reflect.TypeOf((*MockGithubClient)(nil).ListMergedPullRequests), ctx, owner, repository)
+}
+
+// GetReadme mocks base method
+func (m *MockGithubClient) GetReadme(ctx context.Context, owner, repository string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReadme", ctx, owner, repository)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReadme indicates an expected call of GetReadme
+func (mr *MockGithubClientMockRecorder) GetReadme(ctx, owner, repository interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReadme", reflect.TypeOf((*MockGithubClient)(nil).GetReadme), ctx, owner, repository)
+}
+
+// GetRepository mocks base method
+func (m *MockGithubClient) GetRepository(ctx context.Context, owner, repository string) (contributions.Repository, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepository", ctx, owner, repository)
+	ret0, _ := ret[0].(contributions.Repository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRepository indicates an expected call of GetRepository
+func (mr *MockGithubClientMockRecorder) GetRepository(ctx, owner, repository interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepository", reflect.TypeOf((*MockGithubClient)(nil).GetRepository), ctx, owner, repository)
+}
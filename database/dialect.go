@@ -0,0 +1,90 @@
+// Package database opens the database of a database URL, Postgres or SQLite,
+// and hides the differences between them from the services.
+//
+// SQLite is meant for development and small installs: arrays (e.g. tags) are
+// stored in Postgres' text format and queried through functions registered on
+// every SQLite connection, and the features built on Postgres extensions
+// (search, similar projects, ...) are unavailable. See docs/database.md.
+package database
+
+import (
+	"gorm.io/gorm"
+)
+
+// Builds the SQL of the queries that differ between Postgres and SQLite.
+type Dialect interface {
+	// The condition that an array column has any of the values of an array
+	// parameter, e.g. Overlaps("tags") with pq.StringArray{"go", "web"}.
+	Overlaps(column string) string
+
+	// An expression of an array column whose values that are in an array
+	// parameter are replaced by a second parameter. Duplicates are dropped,
+	// keeping the position of each value's first occurrence.
+	ReplaceValues(column string) string
+
+	// The column identifying a row of any table, for deleting rows in
+	// batches.
+	RowId() string
+
+	// The UTC date of a timestamp column, as YYYY-MM-DD text, for grouping
+	// rows by day.
+	Day(column string) string
+}
+
+// The dialect of the database db is connected to.
+func DialectOf(db *gorm.DB) Dialect {
+	if IsSqlite(db) {
+		return sqliteDialect{}
+	}
+
+	return postgresDialect{}
+}
+
+// Whether db is connected to a SQLite database.
+func IsSqlite(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite"
+}
+
+type postgresDialect struct{}
+
+// Backed by the GIN indexes of the array columns, see docs/database.md.
+func (postgresDialect) Overlaps(column string) string {
+	return column + " && ?"
+}
+
+func (postgresDialect) ReplaceValues(column string) string {
+	return `ARRAY(
+		SELECT CASE WHEN value = ANY(?) THEN ? ELSE value END AS replaced
+		FROM unnest(` + column + `) WITH ORDINALITY AS t(value, position)
+		GROUP BY replaced
+		ORDER BY min(position)
+	)`
+}
+
+func (postgresDialect) RowId() string {
+	return "ctid"
+}
+
+func (postgresDialect) Day(column string) string {
+	return "to_char(" + column + " AT TIME ZONE 'UTC', 'YYYY-MM-DD')"
+}
+
+// Uses the functions of sqliteFunctions.
+type sqliteDialect struct{}
+
+func (sqliteDialect) Overlaps(column string) string {
+	return "array_overlaps(" + column + ", ?)"
+}
+
+func (sqliteDialect) ReplaceValues(column string) string {
+	return "array_replace_values(" + column + ", ?, ?)"
+}
+
+func (sqliteDialect) RowId() string {
+	return "rowid"
+}
+
+// Timestamps are stored as text with their offset, which date() applies.
+func (sqliteDialect) Day(column string) string {
+	return "date(" + column + ")"
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"strings"
+)
+
+const sqliteScheme = "sqlite://"
+
+// The go-sqlite3 driver with the functions of sqliteFunctions.
+const sqliteDriverName = "sqlite3_open_collaboration"
+
+// Stand-ins for the Postgres array operators and functions the services use.
+// Arrays are stored in Postgres' text format by pq.StringArray, e.g.
+// {"go","web"}.
+var sqliteFunctions = map[string]interface{}{
+	// a && b
+	"array_overlaps": func(a interface{}, b interface{}) (bool, error) {
+		values, err := parseArray(a)
+		if err != nil {
+			return false, err
+		}
+
+		others, err := parseArray(b)
+		if err != nil {
+			return false, err
+		}
+
+		for _, value := range values {
+			for _, other := range others {
+				if value == other {
+					return true, nil
+				}
+			}
+		}
+
+		return false, nil
+	},
+
+	// See Dialect.ReplaceValues
+	"array_replace_values": func(a interface{}, replaced interface{}, replacement string) (string, error) {
+		values, err := parseArray(a)
+		if err != nil {
+			return "", err
+		}
+
+		replacedValues, err := parseArray(replaced)
+		if err != nil {
+			return "", err
+		}
+
+		isReplaced := make(map[string]bool, len(replacedValues))
+		for _, value := range replacedValues {
+			isReplaced[value] = true
+		}
+
+		seen := map[string]bool{}
+		result := pq.StringArray{}
+		for _, value := range values {
+			if isReplaced[value] {
+				value = replacement
+			}
+
+			if !seen[value] {
+				seen[value] = true
+				result = append(result, value)
+			}
+		}
+
+		return formatArray(result)
+	},
+
+	// array_remove(a, value)
+	"array_remove": func(a interface{}, removed string) (string, error) {
+		values, err := parseArray(a)
+		if err != nil {
+			return "", err
+		}
+
+		result := pq.StringArray{}
+		for _, value := range values {
+			if value != removed {
+				result = append(result, value)
+			}
+		}
+
+		return formatArray(result)
+	},
+
+	// cardinality(a), 0 instead of NULL for a NULL array
+	"cardinality": func(a interface{}) (int64, error) {
+		values, err := parseArray(a)
+
+		return int64(len(values)), err
+	},
+}
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			for name, function := range sqliteFunctions {
+				err := conn.RegisterFunc(name, function, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	})
+}
+
+// The path of the database file of a SQLite URL, e.g. "dev.db" for
+// "sqlite://dev.db", and whether url is a SQLite URL.
+func SqlitePath(url string) (string, bool) {
+	if !strings.HasPrefix(url, sqliteScheme) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(url, sqliteScheme), true
+}
+
+// Open a SQLite database file, which is created if it doesn't exist.
+func OpenSqlite(path string) gorm.Dialector {
+	// Foreign keys are off by default in SQLite. Background work writes
+	// concurrently with requests, writers wait for each other instead of
+	// failing with "database is locked".
+	return &sqlite.Dialector{
+		DriverName: sqliteDriverName,
+		DSN:        "file:" + path + "?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000",
+	}
+}
+
+func parseArray(value interface{}) (pq.StringArray, error) {
+	// go-sqlite3 passes NULL as a nil []byte
+	if bytes, ok := value.([]byte); ok && bytes == nil {
+		return nil, nil
+	}
+
+	var array pq.StringArray
+	err := array.Scan(value)
+
+	return array, err
+}
+
+func formatArray(array pq.StringArray) (string, error) {
+	value, err := array.Value()
+	if err != nil {
+		return "", err
+	}
+
+	return value.(string), nil
+}
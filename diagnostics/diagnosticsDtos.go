@@ -0,0 +1,13 @@
+package diagnostics
+
+import "time"
+
+type SnapshotDto struct {
+	CreatedAt  time.Time `json:"createdAt"`
+	Goroutines int       `json:"goroutines"`
+
+	// Paths of the snapshot's files on the server. The goroutine dump is text,
+	// the heap profile can be read with `go tool pprof`.
+	GoroutinesFile string `json:"goroutinesFile"`
+	HeapFile       string `json:"heapFile"`
+}
@@ -0,0 +1,43 @@
+package diagnostics
+
+import (
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Take a diagnostics snapshot
+// @Description Writes a dump of all goroutines and a heap profile to the server's DIAGNOSTICS_DIR, e.g. to
+// @Description investigate a hang or a memory leak. The last 10 snapshots are kept.
+// @Tags admin
+// @Router /admin/debug/snapshots [post]
+// @Success 201 {object} diagnostics.SnapshotDto
+// @Failure 403
+func RouteCreateSnapshot(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	auditService audit.Service,
+	diagnosticsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := diagnosticsService.Snapshot(request.Context())
+	if err != nil {
+		return err
+	}
+
+	err = auditService.Record(request.Context(), session.UserId(), "debug.snapshot", "", 0, map[string]interface{}{
+		"goroutinesFile": snapshot.GoroutinesFile,
+	})
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, snapshot)
+}
@@ -0,0 +1,119 @@
+// Package diagnostics helps debugging the running server: goroutine and heap
+// snapshots, pprof and expvar (see DebugHandler) and slow request logging
+// (see SlowRequestMiddleware).
+package diagnostics
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"github.com/apex/log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How many snapshots are kept, older ones are deleted.
+const keptSnapshots = 10
+
+type Service interface {
+	// Write a dump of all goroutines and a heap profile to the snapshots
+	// directory.
+	Snapshot(ctx context.Context) (SnapshotDto, error)
+}
+
+type serviceImpl struct {
+	Dir string
+
+	// Snapshots are taken one at a time
+	mu sync.Mutex
+}
+
+// Create a diagnostics service writing snapshots to dir, which is created if
+// it doesn't exist.
+func NewService(dir string) Service {
+	return &serviceImpl{Dir: dir}
+}
+
+func (s *serviceImpl) Snapshot(ctx context.Context) (SnapshotDto, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := os.MkdirAll(s.Dir, 0o700)
+	if err != nil {
+		return SnapshotDto{}, err
+	}
+
+	now := time.Now().UTC()
+	prefix := filepath.Join(s.Dir, now.Format("20060102-150405.000"))
+
+	dto := SnapshotDto{
+		CreatedAt:      now,
+		Goroutines:     runtime.NumGoroutine(),
+		GoroutinesFile: prefix + "-goroutines.txt",
+		HeapFile:       prefix + "-heap.pprof",
+	}
+
+	// debug=2 prints every goroutine's stack like an unrecovered panic
+	err = writeProfile(dto.GoroutinesFile, "goroutine", 2)
+	if err != nil {
+		return SnapshotDto{}, err
+	}
+
+	// Up to date allocation statistics
+	runtime.GC()
+
+	err = writeProfile(dto.HeapFile, "heap", 0)
+	if err != nil {
+		return SnapshotDto{}, err
+	}
+
+	log.FromContext(ctx).WithField("goroutinesFile", dto.GoroutinesFile).Info("Diagnostics snapshot written")
+
+	s.pruneSnapshots(ctx)
+
+	return dto, nil
+}
+
+func writeProfile(path string, profile string, debug int) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	err = pprof.Lookup(profile).WriteTo(file, debug)
+	if err != nil {
+		file.Close()
+
+		return err
+	}
+
+	return file.Close()
+}
+
+// Delete all but the last keptSnapshots snapshots.
+func (s *serviceImpl) pruneSnapshots(ctx context.Context) {
+	files, err := filepath.Glob(filepath.Join(s.Dir, "*-goroutines.txt"))
+	if err != nil || len(files) <= keptSnapshots {
+		return
+	}
+
+	// The names start with the time they were taken
+	sort.Strings(files)
+
+	for _, file := range files[:len(files)-keptSnapshots] {
+		prefix := strings.TrimSuffix(file, "-goroutines.txt")
+
+		for _, path := range []string{file, prefix + "-heap.pprof"} {
+			err = os.Remove(path)
+			if err != nil && !os.IsNotExist(err) {
+				log.FromContext(ctx).WithError(err).WithField("file", path).Warn("Failed to delete old diagnostics snapshot")
+			}
+		}
+	}
+}
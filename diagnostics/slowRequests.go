@@ -0,0 +1,118 @@
+package diagnostics
+
+import (
+	"bytes"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Stack traces of slow requests are logged at most this often, dumping all
+// goroutines of an overloaded server for every slow request would make it
+// slower.
+const slowRequestLogInterval = 10 * time.Second
+
+// The largest dump of all goroutines searched for a request's stack trace.
+const maxStackDumpSize = 64 << 20
+
+// Logs a warning with the stack trace of requests still being handled after
+// threshold, showing where they're stuck (e.g. waiting for a query or a
+// lock). Requests are logged again when they complete.
+func SlowRequestMiddleware(threshold time.Duration) mux.MiddlewareFunc {
+	var mu sync.Mutex
+	var lastLogged time.Time
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if utils.IsWebsocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logger := log.FromContext(r.Context())
+			goroutine := currentGoroutineId()
+			start := time.Now()
+
+			slow := false
+			timer := time.AfterFunc(threshold, func() {
+				mu.Lock()
+				slow = true
+				logStack := time.Since(lastLogged) >= slowRequestLogInterval
+				if logStack {
+					lastLogged = time.Now()
+				}
+				mu.Unlock()
+
+				entry := logger.WithField("threshold", threshold.String())
+				if logStack {
+					entry = entry.WithField("stack", goroutineStack(goroutine))
+				}
+
+				entry.Warn("Slow request still in progress")
+			})
+
+			next.ServeHTTP(w, r)
+
+			if !timer.Stop() {
+				mu.Lock()
+				wasSlow := slow
+				mu.Unlock()
+
+				if wasSlow {
+					logger.WithField("durationMs", time.Since(start).Milliseconds()).Warn("Slow request completed")
+				}
+			}
+		})
+	}
+}
+
+// The id of the calling goroutine, parsed from its stack trace header, e.g.
+// "goroutine 42 [running]:". Returns "" if it can't be parsed.
+func currentGoroutineId() string {
+	buffer := make([]byte, 64)
+	buffer = buffer[:runtime.Stack(buffer, false)]
+
+	fields := bytes.Fields(buffer)
+	if len(fields) < 2 {
+		return ""
+	}
+
+	if _, err := strconv.ParseUint(string(fields[1]), 10, 64); err != nil {
+		return ""
+	}
+
+	return string(fields[1])
+}
+
+// The stack trace of a goroutine, taken from a dump of all goroutines.
+// Returns "" if it's not found, e.g. because it has completed.
+func goroutineStack(id string) string {
+	if id == "" {
+		return ""
+	}
+
+	buffer := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buffer, true)
+		if n < len(buffer) || len(buffer) >= maxStackDumpSize {
+			buffer = buffer[:n]
+			break
+		}
+
+		buffer = make([]byte, 2*len(buffer))
+	}
+
+	// Goroutines are separated by an empty line
+	for _, stack := range bytes.Split(buffer, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, []byte("goroutine "+id+" ")) {
+			return string(stack)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,124 @@
+package directory
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// The user directory, where project owners look for collaborators. Users are
+// only listed once they opt in (see users.DirectoryProfileDto), and only
+// their username, skills, availability and, if they show it, email are shown.
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 50
+)
+
+// @Summary List discoverable users
+// @Description Users who opted in to the directory. Users actively looking come first, then users open to offers,
+// @Description then everyone else, each most recently updated first. Expired availabilities count as unavailable.
+// @Description Only signed in users can browse it. Emails are only listed for users who show them.
+// @Tags users
+// @Router /users [get]
+// @Param skill query string false "Comma separated skills, users with any of them are listed"
+// @Param availability query string false "Comma separated availabilities (actively-looking, open-to-offers, unavailable), users with any of them are listed"
+// @Param availableForWork query bool false "Only list users actively looking or open to offers, overrides availability"
+// @Param pageSize query int false "Page size, at most 50"
+// @Param pageOffset query int false "Page offset"
+// @Success 200 {array} users.DirectoryUserDto
+// @Header 200 {int} X-Total-Count "Total amount of users matching the filters"
+// @Header 200 {int} X-Page "The current page (pageOffset)"
+// @Header 200 {int} X-Page-Size "The page size"
+// @Header 200 {bool} X-Has-Next-Page "Whether there are more users after this page"
+// @Failure 401
+func RouteListUsers(writer http.ResponseWriter, request *http.Request, usersService users.Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", defaultPageSize)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	} else if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	if pageOffset < 1 {
+		pageOffset = 0
+	}
+
+	filters := users.DirectoryFilters{
+		Skills: utils.StringsFromQuery(request, "skill"),
+	}
+
+	for _, availability := range utils.StringsFromQuery(request, "availability") {
+		filters.Availabilities = append(filters.Availabilities, users.Availability(availability))
+	}
+
+	if request.URL.Query().Get("availableForWork") == "true" {
+		filters.Availabilities = users.AvailableForWork
+	}
+
+	directoryUsers, totalCount, err := usersService.ListDirectory(request.Context(), session.UserId(), filters, uint(pageSize), uint(pageOffset))
+	if err != nil {
+		return err
+	}
+
+	utils.WritePaginationHeaders(writer, pageOffset, pageSize, totalCount)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, directoryUsers)
+}
+
+// @Summary Get the user's directory profile
+// @Tags users
+// @Router /users/me/directory-profile [get]
+// @Success 200 {object} users.DirectoryProfileDto
+// @Failure 401
+func RouteGetDirectoryProfile(writer http.ResponseWriter, request *http.Request, usersService users.Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	profile, err := usersService.GetDirectoryProfile(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, profile)
+}
+
+// @Summary Update the user's directory profile
+// @Description Discoverable users are listed in GET /users with their username, skills and availability. Skills are
+// @Description lowercased. An availability other than unavailable can expire at availabilityExpiresAt, which has to be
+// @Description in the future.
+// @Tags users
+// @Router /users/me/directory-profile [put]
+// @Param profile body users.DirectoryProfileDto true "The directory profile"
+// @Success 200 {object} users.DirectoryProfileDto
+// @Failure 400
+// @Failure 401
+func RouteUpdateDirectoryProfile(writer http.ResponseWriter, request *http.Request, usersService users.Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := users.DirectoryProfileDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	profile, err := usersService.UpdateDirectoryProfile(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, profile)
+}
@@ -0,0 +1,70 @@
+package email
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"github.com/apex/log"
+)
+
+type Message struct {
+	To      string
+	Subject string
+
+	// The plain text body
+	Body string
+
+	// The HTML body, optional. Emails with an HTML body are sent with both
+	// bodies and clients choose which one to display.
+	HtmlBody string
+}
+
+// A Sender delivers emails.
+type Sender interface {
+	// Send an email.
+	SendEmail(ctx context.Context, message Message) error
+}
+
+// Logs emails instead of sending them. Intended for development.
+type logSender struct{}
+
+func NewLogSender() Sender {
+	return &logSender{}
+}
+
+func (s *logSender) SendEmail(ctx context.Context, message Message) error {
+	log.FromContext(ctx).
+		WithFields(log.Fields{
+			"to":      message.To,
+			"subject": message.Subject,
+		}).
+		Info("Email (not sent):\n" + message.Body)
+
+	return nil
+}
+
+// Guards calls to an external service, e.g. a breaker.Breaker.
+type Guard interface {
+	// Call fn unless the guard rejects the call.
+	Do(fn func() error) error
+}
+
+// Sends emails through another sender guarded by a Guard, e.g. a circuit
+// breaker so that emails fail fast while the email server is down.
+type guardedSender struct {
+	Sender Sender
+	Guard  Guard
+}
+
+func NewGuardedSender(sender Sender, guard Guard) Sender {
+	return &guardedSender{
+		Sender: sender,
+		Guard:  guard,
+	}
+}
+
+func (s *guardedSender) SendEmail(ctx context.Context, message Message) error {
+	return s.Guard.Do(func() error {
+		return s.Sender.SendEmail(ctx, message)
+	})
+}
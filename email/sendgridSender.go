@@ -0,0 +1,81 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	"net/http"
+)
+
+const sendgridUrl = "https://api.sendgrid.com/v3/mail/send"
+
+// Sends emails through SendGrid's v3 mail send API.
+type sendgridSender struct {
+	ApiKey string
+	From   string
+}
+
+func NewSendgridSender(apiKey string, from string) Sender {
+	return &sendgridSender{
+		ApiKey: apiKey,
+		From:   from,
+	}
+}
+
+func (s *sendgridSender) SendEmail(ctx context.Context, message Message) error {
+	logger := log.FromContext(ctx).WithField("subject", message.Subject)
+
+	type content struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+
+	// The plain text content has to come first
+	contents := []content{{Type: "text/plain", Value: message.Body}}
+	if message.HtmlBody != "" {
+		contents = append(contents, content{Type: "text/html", Value: message.HtmlBody})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"personalizations": []interface{}{
+			map[string]interface{}{
+				"to": []interface{}{map[string]string{"email": message.To}},
+			},
+		},
+		"from":    map[string]string{"email": s.From},
+		"subject": message.Subject,
+		"content": contents,
+	})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", sendgridUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+s.ApiKey)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		logger.WithError(err).Error("Failed to send email")
+
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		err = fmt.Errorf("sendgrid request failed with status %d", response.StatusCode)
+		logger.WithError(err).Error("Failed to send email")
+
+		return err
+	}
+
+	logger.Debug("Email sent")
+
+	return nil
+}
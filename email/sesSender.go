@@ -0,0 +1,88 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"time"
+)
+
+// Sends emails through the Amazon SES v2 API. Requests are signed with AWS
+// signature version 4.
+type sesSender struct {
+	Region          string
+	AccessKeyId     string
+	SecretAccessKey string
+	From            string
+}
+
+func NewSesSender(region string, accessKeyId string, secretAccessKey string, from string) Sender {
+	return &sesSender{
+		Region:          region,
+		AccessKeyId:     accessKeyId,
+		SecretAccessKey: secretAccessKey,
+		From:            from,
+	}
+}
+
+func (s *sesSender) SendEmail(ctx context.Context, message Message) error {
+	logger := log.FromContext(ctx).WithField("subject", message.Subject)
+
+	body := map[string]interface{}{
+		"Text": map[string]string{"Data": message.Body, "Charset": "UTF-8"},
+	}
+	if message.HtmlBody != "" {
+		body["Html"] = map[string]string{"Data": message.HtmlBody, "Charset": "UTF-8"}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"FromEmailAddress": s.From,
+		"Destination": map[string]interface{}{
+			"ToAddresses": []string{message.To},
+		},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": map[string]string{"Data": message.Subject, "Charset": "UTF-8"},
+				"Body":    body,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	host := fmt.Sprintf("email.%s.amazonaws.com", s.Region)
+	request, err := http.NewRequestWithContext(ctx, "POST", "https://"+host+"/v2/email/outbound-emails", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	utils.SignAwsRequest(request, payload, utils.AwsCredentials{
+		AccessKeyId:     s.AccessKeyId,
+		SecretAccessKey: s.SecretAccessKey,
+	}, s.Region, "ses", time.Now())
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		logger.WithError(err).Error("Failed to send email")
+
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 300 {
+		err = fmt.Errorf("ses request failed with status %d", response.StatusCode)
+		logger.WithError(err).Error("Failed to send email")
+
+		return err
+	}
+
+	logger.Debug("Email sent")
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+)
+
+// Sends emails through an SMTP server.
+type smtpSender struct {
+	Host     string
+	Port     string
+	Username string
+	From     string
+
+	// Called for every email, so that a rotated password is used without a
+	// restart
+	Password func() string
+}
+
+func NewSmtpSender(host string, port string, username string, password func() string, from string) Sender {
+	return &smtpSender{
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+func (s *smtpSender) SendEmail(ctx context.Context, message Message) error {
+	logger := log.FromContext(ctx).WithField("subject", message.Subject)
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password(), s.Host)
+	}
+
+	body, err := s.formatMessage(message)
+	if err != nil {
+		return err
+	}
+
+	err = smtp.SendMail(s.Host+":"+s.Port, auth, s.From, []string{message.To}, body)
+	if err != nil {
+		logger.WithError(err).Error("Failed to send email")
+
+		return err
+	}
+
+	logger.Debug("Email sent")
+
+	return nil
+}
+
+// Format a message as a MIME email. Messages with an HTML body are sent as
+// multipart/alternative, with the plain text body first.
+func (s *smtpSender) formatMessage(message Message) ([]byte, error) {
+	headers := []string{
+		fmt.Sprintf("From: %s", s.From),
+		fmt.Sprintf("To: %s", message.To),
+		fmt.Sprintf("Subject: %s", mime.QEncoding.Encode("utf-8", message.Subject)),
+		"MIME-Version: 1.0",
+	}
+
+	if message.HtmlBody == "" {
+		headers = append(headers, "Content-Type: text/plain; charset=\"utf-8\"")
+
+		return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body), nil
+	}
+
+	var parts bytes.Buffer
+	writer := multipart.NewWriter(&parts)
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=\"utf-8\"", message.Body},
+		{"text/html; charset=\"utf-8\"", message.HtmlBody},
+	} {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = partWriter.Write([]byte(part.body))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	headers = append(headers, fmt.Sprintf("Content-Type: multipart/alternative; boundary=\"%s\"", writer.Boundary()))
+
+	return append([]byte(strings.Join(headers, "\r\n")+"\r\n\r\n"), parts.Bytes()...), nil
+}
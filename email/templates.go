@@ -0,0 +1,252 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+var ErrTemplateNotFound = errors.New("email template not found")
+var ErrInvalidTemplate = errors.New("invalid email template")
+
+// Names of the templates
+const (
+	// Sent to a user when someone tries to register with their email.
+	// Data: Username, LoginUrl.
+	TemplateEmailTaken = "email-taken"
+
+	// Sent when a waitlist entry is activated. Data: SignupUrl.
+	TemplateWaitlistActivation = "waitlist-activation"
+
+	// Sent when a new project matches a saved search. Data: SearchName,
+	// ProjectName, ProjectDescription, ProjectUrl.
+	TemplateSavedSearchMatch = "saved-search-match"
+
+	// Sent when an admin emails a broadcast. Data: Title, Body.
+	TemplateBroadcast = "broadcast"
+)
+
+var templateNames = []string{
+	TemplateEmailTaken,
+	TemplateWaitlistActivation,
+	TemplateSavedSearchMatch,
+	TemplateBroadcast,
+}
+
+// The default template files. Each template has the files:
+//
+// <name>.subject.txt: the subject.
+// <name>.txt: the plain text body.
+// <name>.html: the HTML body, which defines the "content" template rendered
+// by layout.html.
+// <name>.sample.json: data to preview the template with.
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// An email's subject and bodies as Go templates, all executed with the same
+// data. The subject and the text body are text/template templates, the HTML
+// body is an html/template template so that the data is escaped.
+type Template struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+
+	// nil if the emails don't have an HTML body
+	html *htmltemplate.Template
+}
+
+// Parse a template. html may be empty for plain text only emails. Otherwise,
+// if layout isn't empty, html is parsed into it, so html should define the
+// templates the layout renders.
+func ParseTemplate(name string, subject string, text string, html string, layout string) (*Template, error) {
+	template := &Template{}
+
+	var err error
+	template.subject, err = texttemplate.New(name + ".subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	template.text, err = texttemplate.New(name + ".text").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if html != "" {
+		template.html = htmltemplate.New(name + ".html").Option("missingkey=error")
+
+		if layout != "" {
+			template.html, err = template.html.Parse(layout)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		template.html, err = template.html.Parse(html)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return template, nil
+}
+
+// Render the email to send to an address.
+func (t *Template) Render(to string, data interface{}) (Message, error) {
+	var subject, text, html bytes.Buffer
+
+	err := t.subject.Execute(&subject, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	err = t.text.Execute(&text, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	if t.html != nil {
+		err = t.html.Execute(&html, data)
+		if err != nil {
+			return Message{}, err
+		}
+	}
+
+	return Message{
+		To: to,
+		// Line breaks would end the subject header early
+		Subject:  strings.Join(strings.Fields(subject.String()), " "),
+		Body:     text.String(),
+		HtmlBody: html.String(),
+	}, nil
+}
+
+// The templates of the emails sent by the server, by name.
+//
+// The templates are embedded in the binary. Operators can override any of
+// their files (see embeddedTemplates), e.g. layout.html to brand all emails,
+// by putting a file with the same name in the overrides directory.
+type Templates struct {
+	OverridesDir string
+
+	mu        sync.RWMutex
+	templates map[string]*Template
+	samples   map[string]map[string]interface{}
+}
+
+// Load the templates. overridesDir may be empty if templates aren't overridden.
+func NewTemplates(overridesDir string) (*Templates, error) {
+	templates := &Templates{
+		OverridesDir: overridesDir,
+	}
+
+	err := templates.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// Load the templates again, picking up changes to the override files. The
+// current templates are kept if any template is invalid.
+// Returns ErrInvalidTemplate if a template can't be parsed.
+func (t *Templates) Reload() error {
+	layout, err := t.readFile("layout.html")
+	if err != nil {
+		return err
+	}
+
+	templates := map[string]*Template{}
+	samples := map[string]map[string]interface{}{}
+
+	for _, name := range templateNames {
+		parts := map[string]string{}
+		for _, suffix := range []string{".subject.txt", ".txt", ".html", ".sample.json"} {
+			parts[suffix], err = t.readFile(name + suffix)
+			if err != nil {
+				return err
+			}
+		}
+
+		templates[name], err = ParseTemplate(name, parts[".subject.txt"], parts[".txt"], parts[".html"], layout)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+		}
+
+		sample := map[string]interface{}{}
+		err = json.Unmarshal([]byte(parts[".sample.json"]), &sample)
+		if err != nil {
+			return fmt.Errorf("%w: %s.sample.json: %v", ErrInvalidTemplate, name, err)
+		}
+
+		samples[name] = sample
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.templates = templates
+	t.samples = samples
+
+	return nil
+}
+
+// Read a template file from the overrides directory, or the embedded
+// templates if it isn't overridden.
+func (t *Templates) readFile(name string) (string, error) {
+	if t.OverridesDir != "" {
+		content, err := os.ReadFile(filepath.Join(t.OverridesDir, name))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	content, err := embeddedTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+// The names of all templates.
+func (t *Templates) Names() []string {
+	return append([]string{}, templateNames...)
+}
+
+// Render a template by name.
+// Returns ErrTemplateNotFound if there's no template with the name.
+func (t *Templates) Render(name string, to string, data interface{}) (Message, error) {
+	t.mu.RLock()
+	template, ok := t.templates[name]
+	t.mu.RUnlock()
+
+	if !ok {
+		return Message{}, ErrTemplateNotFound
+	}
+
+	return template.Render(to, data)
+}
+
+// Render a template with its sample data.
+// Returns ErrTemplateNotFound if there's no template with the name.
+func (t *Templates) RenderSample(name string, to string) (Message, error) {
+	t.mu.RLock()
+	sample := t.samples[name]
+	t.mu.RUnlock()
+
+	return t.Render(name, to, sample)
+}
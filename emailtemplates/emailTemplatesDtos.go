@@ -0,0 +1,9 @@
+package emailtemplates
+
+// An email rendered with a template's sample data.
+type PreviewDto struct {
+	Template string `json:"template"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	Html     string `json:"html"`
+}
@@ -0,0 +1,121 @@
+package emailtemplates
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/email"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List email templates
+// @Tags admin
+// @Router /admin/email-templates [get]
+// @Success 200 {array} string
+// @Failure 403
+func RouteListTemplates(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	templates *email.Templates,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, templates.Names())
+}
+
+// @Summary Preview an email template
+// @Description Renders the template with its sample data. Template files are reloaded first, so changes
+// @Description to the overrides directory are previewed (and used for all emails) without a restart.
+// @Tags admin
+// @Router /admin/email-templates/{template}/preview [get]
+// @Param template path string true "Template name"
+// @Success 200 {object} emailtemplates.PreviewDto
+// @Failure 400 "A template file is invalid"
+// @Failure 403
+// @Failure 404
+func RoutePreviewTemplate(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	templates *email.Templates,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = templates.Reload()
+	if err != nil {
+		return err
+	}
+
+	name := mux.Vars(request)["template"]
+
+	message, err := templates.RenderSample(name, "")
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, PreviewDto{
+		Template: name,
+		Subject:  message.Subject,
+		Text:     message.Body,
+		Html:     message.HtmlBody,
+	})
+}
+
+// @Summary Send a test email
+// @Description Sends the template rendered with its sample data to the admin's own email address.
+// @Tags admin
+// @Router /admin/email-templates/{template}/test [post]
+// @Param template path string true "Template name"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteSendTestEmail(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	auditService audit.Service,
+	templates *email.Templates,
+	emailSender email.Sender,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	user, err := usersService.GetUser(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	name := mux.Vars(request)["template"]
+
+	message, err := templates.RenderSample(name, user.Email)
+	if err != nil {
+		return err
+	}
+
+	err = auditService.Record(request.Context(), session.UserId(), "email.send-test", "", 0, map[string]interface{}{
+		"template": name,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = emailSender.SendEmail(request.Context(), message)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
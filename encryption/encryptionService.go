@@ -0,0 +1,185 @@
+package encryption
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("encryption keys must be 32 bytes, base64 encoded")
+var ErrUnknownKey = errors.New("value is encrypted with an unknown key")
+var ErrInvalidCiphertext = errors.New("encrypted value is damaged")
+
+// Encrypted values are stored as "enc:v<key version>:<base64 nonce and
+// ciphertext>". Values without the prefix are plaintext, e.g. stored before
+// encryption was enabled.
+const prefix = "enc:v"
+
+// Encrypts sensitive columns with AES-256-GCM. Each key has a version: values
+// are encrypted with the latest key and decrypted with the key they were
+// encrypted with, so a key is rotated by adding a new version and
+// re-encrypting the columns (see ReencryptColumns) before removing the old
+// key.
+type Service interface {
+	// Encrypt a value with the latest key. Returns the value unchanged if
+	// there are no keys.
+	Encrypt(plaintext string) (string, error)
+
+	// Decrypt a value encrypted with any of the keys. Plaintext values are
+	// returned unchanged.
+	// Returns ErrUnknownKey if its key isn't configured and
+	// ErrInvalidCiphertext if it was tampered with.
+	Decrypt(value string) (string, error)
+
+	// Whether a value isn't encrypted with the latest key (yet).
+	NeedsReencryption(value string) bool
+}
+
+type serviceImpl struct {
+	// By version
+	ciphers map[int]cipher.AEAD
+
+	// The latest version, 0 if there are no keys
+	current int
+}
+
+// Create an encryption service from keys, by version. Encryption is disabled
+// if keys is empty.
+func NewService(keys map[int][]byte) (Service, error) {
+	service := &serviceImpl{
+		ciphers: map[int]cipher.AEAD{},
+	}
+
+	for version, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("%w: version %d", ErrInvalidKey, version)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		service.ciphers[version], err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		if version > service.current {
+			service.current = version
+		}
+	}
+
+	return service, nil
+}
+
+// Parse keys formatted as comma separated "<version>:<base64 key>", e.g.
+// "1:<key>,2:<key>".
+func ParseKeys(value string) (map[int][]byte, error) {
+	keys := map[int][]byte{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 2)
+		if len(parts) != 2 {
+			return nil, ErrInvalidKey
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("%w: invalid version %q", ErrInvalidKey, parts[0])
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: version %d", ErrInvalidKey, version)
+		}
+
+		keys[version] = key
+	}
+
+	return keys, nil
+}
+
+func (s *serviceImpl) Encrypt(plaintext string) (string, error) {
+	if s.current == 0 {
+		return plaintext, nil
+	}
+
+	aead := s.ciphers[s.current]
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return prefix + strconv.Itoa(s.current) + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *serviceImpl) Decrypt(value string) (string, error) {
+	version, encoded, ok := parseValue(value)
+	if !ok {
+		return value, nil
+	}
+
+	aead, ok := s.ciphers[version]
+	if !ok {
+		return "", fmt.Errorf("%w: version %d", ErrUnknownKey, version)
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+
+	return string(plaintext), nil
+}
+
+func (s *serviceImpl) NeedsReencryption(value string) bool {
+	if s.current == 0 {
+		return false
+	}
+
+	version, _, ok := parseValue(value)
+
+	return !ok || version != s.current
+}
+
+// Split an encrypted value into its key version and encoded ciphertext.
+// Returns false if the value isn't encrypted.
+func parseValue(value string) (int, string, bool) {
+	if !strings.HasPrefix(value, prefix) {
+		return 0, "", false
+	}
+
+	parts := strings.SplitN(value[len(prefix):], ":", 2)
+	if len(parts) != 2 {
+		return 0, "", false
+	}
+
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", false
+	}
+
+	return version, parts[1], true
+}
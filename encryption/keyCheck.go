@@ -0,0 +1,39 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// How many of the latest values of each column are decrypted by CheckKeys.
+const keyCheckSampleSize = 20
+
+// Check that the keys decrypt the latest values of columns, e.g. that no key
+// was removed before its values were re-encrypted, or replaced by another key
+// with the same version. Returns ErrUnknownKey or ErrInvalidCiphertext,
+// wrapped with the column, if they don't.
+func CheckKeys(ctx context.Context, db *gorm.DB, service Service, columns []Column) error {
+	for _, column := range columns {
+		var values []string
+
+		result := db.WithContext(ctx).Raw(
+			fmt.Sprintf("SELECT %s FROM %s WHERE %s LIKE ? ORDER BY id DESC LIMIT ?", column.Name, column.Table, column.Name),
+			prefix+"%",
+			keyCheckSampleSize,
+		).Scan(&values)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for _, value := range values {
+			_, err := service.Decrypt(value)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", column.Table, column.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
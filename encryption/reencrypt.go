@@ -0,0 +1,90 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"gorm.io/gorm"
+)
+
+// How many rows are re-encrypted per query.
+const reencryptBatchSize = 500
+
+// An encrypted column. Its table must have an id column.
+type Column struct {
+	Table string
+	Name  string
+}
+
+// Encrypt the values of columns that aren't encrypted with the latest key
+// yet, e.g. after adding a key or enabling encryption. Values changed while
+// they're re-encrypted are left to the next run. Returns how many values were
+// re-encrypted.
+func ReencryptColumns(ctx context.Context, db *gorm.DB, service Service, columns []Column) (int64, error) {
+	var reencrypted int64
+
+	for _, column := range columns {
+		logger := log.FromContext(ctx).WithFields(log.Fields{
+			"table":  column.Table,
+			"column": column.Name,
+		})
+
+		var afterId uint
+		for {
+			var rows []struct {
+				Id    uint
+				Value string
+			}
+
+			// Soft deleted rows included, they may be restored
+			result := db.WithContext(ctx).Raw(
+				fmt.Sprintf("SELECT id, %s AS value FROM %s WHERE id > ? ORDER BY id LIMIT ?", column.Name, column.Table),
+				afterId,
+				reencryptBatchSize,
+			).Scan(&rows)
+
+			if result.Error != nil {
+				return reencrypted, result.Error
+			}
+
+			if len(rows) < 1 {
+				break
+			}
+
+			for _, row := range rows {
+				if row.Value == "" || !service.NeedsReencryption(row.Value) {
+					continue
+				}
+
+				plaintext, err := service.Decrypt(row.Value)
+				if err != nil {
+					return reencrypted, fmt.Errorf("%s.%s of row %d: %w", column.Table, column.Name, row.Id, err)
+				}
+
+				encrypted, err := service.Encrypt(plaintext)
+				if err != nil {
+					return reencrypted, err
+				}
+
+				result = db.WithContext(ctx).Exec(
+					fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ? AND %s = ?", column.Table, column.Name, column.Name),
+					encrypted,
+					row.Id,
+					row.Value,
+				)
+
+				if result.Error != nil {
+					return reencrypted, result.Error
+				}
+
+				reencrypted += result.RowsAffected
+			}
+
+			afterId = rows[len(rows)-1].Id
+		}
+
+		logger.Info("Re-encrypted column")
+	}
+
+	return reencrypted, nil
+}
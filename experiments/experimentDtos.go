@@ -0,0 +1,35 @@
+package experiments
+
+type NewExperimentDto struct {
+	// Lowercase letters, digits and dashes
+	Key         string `json:"key" validate:"required,max=64"`
+	Description string `json:"description" validate:"max=1000"`
+
+	// Lowercase letters, digits and dashes. The first variant is the control,
+	// served to everyone while the experiment is disabled.
+	Variants []string `json:"variants" validate:"required,min=2,max=10,unique,dive,required,max=32"`
+
+	// How many subjects get each variant, relative to the others. Same length
+	// as Variants.
+	Weights []int64 `json:"weights" validate:"required,dive,min=0,max=10000"`
+}
+
+type UpdateExperimentDto struct {
+	Description *string `json:"description" validate:"omitempty,max=1000"`
+	Enabled     *bool   `json:"enabled"`
+
+	// Changing the weights reassigns part of the subjects
+	Weights []int64 `json:"weights" validate:"omitempty,dive,min=0,max=10000"`
+}
+
+type ExperimentDto struct {
+	Id          uint     `json:"id"`
+	Key         string   `json:"key"`
+	Description string   `json:"description"`
+	Enabled     bool     `json:"enabled"`
+	Variants    []string `json:"variants"`
+	Weights     []int64  `json:"weights"`
+
+	// How many subjects were exposed to each variant
+	Exposures map[string]int64 `json:"exposures"`
+}
@@ -0,0 +1,73 @@
+package experiments
+
+import (
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"hash/fnv"
+	"time"
+)
+
+// An A/B experiment. Subjects (users or anonymous visitors) are assigned to
+// one of the experiment's variants, each variant getting a share of the
+// subjects proportional to its weight.
+//
+// Experiments double as feature flags: while an experiment is disabled every
+// subject gets its first variant, the control, so server code branching on a
+// variant (see Service.Variant) falls back to the existing behaviour.
+type Experiment struct {
+	gorm.Model
+
+	// Used by clients and server code to refer to the experiment
+	Key         string
+	Description string
+	Enabled     bool
+
+	// The first variant is the control
+	Variants pq.StringArray `gorm:"type: TEXT[]"`
+	Weights  pq.Int64Array  `gorm:"type: BIGINT[]"`
+}
+
+// The variant a subject is assigned to. The assignment only depends on the
+// experiment's key, its weights and the subject, so it's the same on every
+// instance and stays the same as long as the weights don't change.
+func (e *Experiment) Assign(subject string) string {
+	if !e.Enabled {
+		return e.Variants[0]
+	}
+
+	var total uint64
+	for _, weight := range e.Weights {
+		total += uint64(weight)
+	}
+
+	if total < 1 {
+		return e.Variants[0]
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(e.Key + ":" + subject))
+	bucket := hash.Sum64() % total
+
+	for i, weight := range e.Weights {
+		if bucket < uint64(weight) {
+			return e.Variants[i]
+		}
+
+		bucket -= uint64(weight)
+	}
+
+	return e.Variants[0]
+}
+
+// A subject that was shown a variant of an experiment. Only the first
+// exposure of each subject is recorded.
+type Exposure struct {
+	ExperimentId uint   `gorm:"primaryKey"`
+	Subject      string `gorm:"primaryKey"`
+	Variant      string
+	ExposedAt    time.Time
+}
+
+func (Exposure) TableName() string {
+	return "experiment_exposures"
+}
@@ -0,0 +1,31 @@
+package experiments
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"net/http"
+)
+
+// Sets the X-Experiments response header to the experiment assignments of the
+// session's user, e.g. "new-homepage=treatment,onboarding-tour=control", so
+// that clients don't need to call GET /experiments. Anonymous responses don't
+// get the header since CDNs may cache them for everyone, visitors call
+// GET /experiments instead.
+//
+// Must be used after auth.SessionMiddleware.
+func ExperimentsMiddleware(experimentsService Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := auth.CheckSession(r); err == nil {
+				subject, _ := SubjectFromRequest(r)
+
+				assignments := experimentsService.Assignments(subject)
+				if len(assignments) > 0 {
+					w.Header().Set("X-Experiments", FormatAssignments(assignments))
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package experiments
+
+import (
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+)
+
+var ErrNoSubject = errors.New("the request has neither a session nor an X-Visitor-Id")
+
+// Longest X-Visitor-Id accepted.
+const maxVisitorIdLength = 64
+
+// The subject experiments are assigned by: the user of the request's session
+// or else the anonymous visitor of its X-Visitor-Id header, a random id the
+// client generates once and keeps. Returns false if the request has neither.
+func SubjectFromRequest(request *http.Request) (string, bool) {
+	session, err := auth.CheckSession(request)
+	if err == nil {
+		return "user:" + strconv.FormatUint(uint64(session.UserId()), 10), true
+	}
+
+	visitorId := request.Header.Get("X-Visitor-Id")
+	if visitorId == "" || len(visitorId) > maxVisitorIdLength {
+		return "", false
+	}
+
+	return "visitor:" + visitorId, true
+}
+
+// @Summary Get experiment assignments
+// @Description The variant of each enabled experiment the user (or the anonymous visitor identified by the
+// @Description X-Visitor-Id header) is assigned to, by experiment key. Requests with neither a session nor an
+// @Description X-Visitor-Id get an empty object and should show the control variants.
+// @Tags experiments
+// @Router /experiments [get]
+// @Param X-Visitor-Id header string false "A random id identifying an anonymous visitor"
+// @Success 200 {object} map[string]string
+func RouteGetAssignments(writer http.ResponseWriter, request *http.Request, experimentsService Service) error {
+	assignments := map[string]string{}
+
+	subject, ok := SubjectFromRequest(request)
+	if ok {
+		assignments = experimentsService.Assignments(subject)
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, assignments)
+}
+
+// @Summary Record an experiment exposure
+// @Description Should be called when the user (or anonymous visitor) is shown their variant of an experiment. Only
+// @Description the first exposure is recorded, exposures to disabled experiments are ignored.
+// @Tags experiments
+// @Router /experiments/{experimentKey}/exposures [post]
+// @Param experimentKey path string true "The experiment's key"
+// @Param X-Visitor-Id header string false "A random id identifying an anonymous visitor"
+// @Success 204
+// @Failure 400 "Neither a session nor an X-Visitor-Id"
+// @Failure 404
+func RouteRecordExposure(writer http.ResponseWriter, request *http.Request, experimentsService Service) error {
+	subject, ok := SubjectFromRequest(request)
+	if !ok {
+		return ErrNoSubject
+	}
+
+	err := experimentsService.RecordExposure(request.Context(), subject, mux.Vars(request)["experimentKey"])
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary List experiments
+// @Description All experiments with how many users and visitors were exposed to each variant.
+// @Tags admin
+// @Router /admin/experiments [get]
+// @Success 200 {array} experiments.ExperimentDto
+// @Failure 403
+func RouteListExperiments(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	experimentsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	experiments, err := experimentsService.ListExperiments(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, experiments)
+}
+
+// @Summary Create an experiment
+// @Description Experiments are created disabled, enable them with PATCH /admin/experiments/{experimentId}. While an
+// @Description experiment is disabled everyone gets its first variant, the control.
+// @Tags admin
+// @Router /admin/experiments [post]
+// @Param experiment body experiments.NewExperimentDto true "The experiment"
+// @Success 201 {object} experiments.ExperimentDto
+// @Failure 400 "Invalid key or variants, or the weights don't match the variants"
+// @Failure 403
+// @Failure 409 "Another experiment has the same key"
+func RouteCreateExperiment(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	experimentsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := NewExperimentDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	experiment, err := experimentsService.CreateExperiment(request.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, experiment)
+}
+
+// @Summary Update an experiment
+// @Description Enable or disable an experiment, or change its description or weights. Changing the weights
+// @Description reassigns part of the users and visitors to other variants.
+// @Tags admin
+// @Router /admin/experiments/{experimentId} [patch]
+// @Param experimentId path int true "The experiment ID"
+// @Param experiment body experiments.UpdateExperimentDto true "The fields to update"
+// @Success 200 {object} experiments.ExperimentDto
+// @Failure 400 "The weights don't match the variants"
+// @Failure 403
+// @Failure 404
+func RouteUpdateExperiment(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	experimentsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	experimentId, err := utils.UintFromVars(request, "experimentId")
+	if err != nil {
+		return err
+	}
+
+	dto := UpdateExperimentDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	experiment, err := experimentsService.UpdateExperiment(request.Context(), experimentId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, experiment)
+}
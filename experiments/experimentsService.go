@@ -0,0 +1,387 @@
+package experiments
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrExperimentNotFound = errors.New("experiment not found")
+var ErrKeyTaken = errors.New("experiment key is taken")
+var ErrInvalidKey = errors.New("keys and variants can only contain lowercase letters, digits and dashes")
+var ErrInvalidWeights = errors.New("there must be a weight per variant and at least one weight above 0")
+
+// How often experiments are reloaded, so that changes made on other instances
+// are picked up.
+const reloadInterval = 30 * time.Second
+
+var keyPattern = regexp.MustCompile("^[a-z0-9][a-z0-9-]*$")
+
+type Service interface {
+	// Returns ErrInvalidKey if the key or a variant has invalid characters,
+	// ErrInvalidWeights if the weights don't match the variants and
+	// ErrKeyTaken if another experiment has the same key.
+	CreateExperiment(ctx context.Context, dto NewExperimentDto) (ExperimentDto, error)
+
+	// List all experiments with their exposure counts, by key.
+	ListExperiments(ctx context.Context) ([]ExperimentDto, error)
+
+	// Returns ErrExperimentNotFound if the experiment can't be found and
+	// ErrInvalidWeights if the weights don't match the variants.
+	UpdateExperiment(ctx context.Context, experimentId uint, dto UpdateExperimentDto) (ExperimentDto, error)
+
+	// The variants of the enabled experiments a subject is assigned to, by
+	// experiment key.
+	Assignments(subject string) map[string]string
+
+	// The variant of an experiment a subject is assigned to, so that server
+	// code can branch on it. Disabled experiments give their control variant
+	// and unknown experiments an empty string.
+	Variant(subject string, key string) string
+
+	// Record that a subject was shown its variant of an experiment. Only the
+	// first exposure is recorded and exposures to disabled experiments are
+	// ignored. Returns ErrExperimentNotFound if the experiment can't be found.
+	RecordExposure(ctx context.Context, subject string, key string) error
+
+	// Reload experiments periodically until ctx is done. Should be run in its
+	// own goroutine.
+	Run(ctx context.Context)
+}
+
+type serviceImpl struct {
+	Db *gorm.DB
+
+	// Experiments by key, assignments don't query the database
+	mutex       sync.RWMutex
+	experiments map[string]Experiment
+}
+
+func NewService(db *gorm.DB) Service {
+	return &serviceImpl{
+		Db:          db,
+		experiments: map[string]Experiment{},
+	}
+}
+
+func (s *serviceImpl) CreateExperiment(ctx context.Context, dto NewExperimentDto) (ExperimentDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return ExperimentDto{}, err
+	}
+
+	if !keyPattern.MatchString(dto.Key) {
+		return ExperimentDto{}, ErrInvalidKey
+	}
+
+	for _, variant := range dto.Variants {
+		if !keyPattern.MatchString(variant) {
+			return ExperimentDto{}, ErrInvalidKey
+		}
+	}
+
+	err = checkWeights(len(dto.Variants), dto.Weights)
+	if err != nil {
+		return ExperimentDto{}, err
+	}
+
+	experiment := Experiment{
+		Key:         dto.Key,
+		Description: dto.Description,
+		Variants:    dto.Variants,
+		Weights:     dto.Weights,
+	}
+
+	err = s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		result := tx.Model(&Experiment{}).Where("key = ?", dto.Key).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count > 0 {
+			return ErrKeyTaken
+		}
+
+		return tx.Create(&experiment).Error
+	})
+
+	if err != nil {
+		if !errors.Is(err, ErrKeyTaken) {
+			log.FromContext(ctx).WithError(err).Error("Failed to create experiment")
+		}
+
+		return ExperimentDto{}, err
+	}
+
+	s.reload(ctx)
+
+	return experimentToDto(experiment, map[string]int64{}), nil
+}
+
+func (s *serviceImpl) ListExperiments(ctx context.Context) ([]ExperimentDto, error) {
+	var experiments []Experiment
+	result := s.Db.WithContext(ctx).Order("key").Find(&experiments)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list experiments")
+
+		return nil, result.Error
+	}
+
+	exposures, err := s.countExposures(s.Db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]ExperimentDto, len(experiments))
+	for i, experiment := range experiments {
+		dtos[i] = experimentToDto(experiment, exposures[experiment.ID])
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) UpdateExperiment(ctx context.Context, experimentId uint, dto UpdateExperimentDto) (ExperimentDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return ExperimentDto{}, err
+	}
+
+	experiment := Experiment{}
+	result := s.Db.WithContext(ctx).First(&experiment, experimentId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ExperimentDto{}, ErrExperimentNotFound
+		}
+
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for experiment")
+
+		return ExperimentDto{}, result.Error
+	}
+
+	if dto.Description != nil {
+		experiment.Description = *dto.Description
+	}
+
+	if dto.Enabled != nil {
+		experiment.Enabled = *dto.Enabled
+	}
+
+	if dto.Weights != nil {
+		err = checkWeights(len(experiment.Variants), dto.Weights)
+		if err != nil {
+			return ExperimentDto{}, err
+		}
+
+		experiment.Weights = dto.Weights
+	}
+
+	result = s.Db.WithContext(ctx).
+		Model(&experiment).
+		Select("description", "enabled", "weights").
+		Updates(&experiment)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to update experiment")
+
+		return ExperimentDto{}, result.Error
+	}
+
+	s.reload(ctx)
+
+	exposures, err := s.countExposures(s.Db.WithContext(ctx).Where("experiment_id = ?", experiment.ID))
+	if err != nil {
+		return ExperimentDto{}, err
+	}
+
+	return experimentToDto(experiment, exposures[experiment.ID]), nil
+}
+
+// Count the exposures to each variant of the experiments matched by query, by
+// experiment id.
+func (s *serviceImpl) countExposures(query *gorm.DB) (map[uint]map[string]int64, error) {
+	var counts []struct {
+		ExperimentId uint
+		Variant      string
+		Count        int64
+	}
+
+	result := query.
+		Model(&Exposure{}).
+		Select("experiment_id, variant, count(*) AS count").
+		Group("experiment_id, variant").
+		Scan(&counts)
+
+	if result.Error != nil {
+		log.FromContext(query.Statement.Context).WithError(result.Error).Error("Failed to count experiment exposures")
+
+		return nil, result.Error
+	}
+
+	exposures := map[uint]map[string]int64{}
+	for _, count := range counts {
+		if exposures[count.ExperimentId] == nil {
+			exposures[count.ExperimentId] = map[string]int64{}
+		}
+
+		exposures[count.ExperimentId][count.Variant] = count.Count
+	}
+
+	return exposures, nil
+}
+
+func checkWeights(variants int, weights []int64) error {
+	if len(weights) != variants {
+		return ErrInvalidWeights
+	}
+
+	for _, weight := range weights {
+		if weight > 0 {
+			return nil
+		}
+	}
+
+	return ErrInvalidWeights
+}
+
+func (s *serviceImpl) Assignments(subject string) map[string]string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	assignments := map[string]string{}
+	for key, experiment := range s.experiments {
+		if experiment.Enabled {
+			assignments[key] = experiment.Assign(subject)
+		}
+	}
+
+	return assignments
+}
+
+func (s *serviceImpl) Variant(subject string, key string) string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	experiment, ok := s.experiments[key]
+	if !ok {
+		return ""
+	}
+
+	return experiment.Assign(subject)
+}
+
+func (s *serviceImpl) RecordExposure(ctx context.Context, subject string, key string) error {
+	s.mutex.RLock()
+	experiment, ok := s.experiments[key]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return ErrExperimentNotFound
+	}
+
+	if !experiment.Enabled {
+		return nil
+	}
+
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Exposure{
+			ExperimentId: experiment.ID,
+			Subject:      subject,
+			Variant:      experiment.Assign(subject),
+			ExposedAt:    time.Now(),
+		})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to record experiment exposure")
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		s.reload(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Load all experiments from the database. The previously loaded experiments
+// are kept if they can't be loaded.
+func (s *serviceImpl) reload(ctx context.Context) {
+	var experiments []Experiment
+	result := s.Db.WithContext(ctx).Find(&experiments)
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			log.FromContext(ctx).WithError(result.Error).Error("Failed to load experiments")
+		}
+
+		return
+	}
+
+	byKey := make(map[string]Experiment, len(experiments))
+	for _, experiment := range experiments {
+		byKey[experiment.Key] = experiment
+	}
+
+	s.mutex.Lock()
+	s.experiments = byKey
+	s.mutex.Unlock()
+}
+
+// Format assignments as the X-Experiments header, e.g.
+// "new-homepage=treatment,onboarding-tour=control".
+func FormatAssignments(assignments map[string]string) string {
+	keys := make([]string, 0, len(assignments))
+	for key := range assignments {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	header := ""
+	for i, key := range keys {
+		if i > 0 {
+			header += ","
+		}
+
+		header += key + "=" + assignments[key]
+	}
+
+	return header
+}
+
+func experimentToDto(experiment Experiment, exposures map[string]int64) ExperimentDto {
+	if exposures == nil {
+		exposures = map[string]int64{}
+	}
+
+	return ExperimentDto{
+		Id:          experiment.ID,
+		Key:         experiment.Key,
+		Description: experiment.Description,
+		Enabled:     experiment.Enabled,
+		Variants:    experiment.Variants,
+		Weights:     experiment.Weights,
+		Exposures:   exposures,
+	}
+}
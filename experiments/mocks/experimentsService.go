@@ -0,0 +1,134 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: experimentsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	experiments "github.com/open-collaboration/server/experiments"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateExperiment mocks base method
+func (m *MockService) CreateExperiment(ctx context.Context, dto experiments.NewExperimentDto) (experiments.ExperimentDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateExperiment", ctx, dto)
+	ret0, _ := ret[0].(experiments.ExperimentDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateExperiment indicates an expected call of CreateExperiment
+func (mr *MockServiceMockRecorder) CreateExperiment(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateExperiment", reflect.TypeOf((*MockService)(nil).CreateExperiment), ctx, dto)
+}
+
+// ListExperiments mocks base method
+func (m *MockService) ListExperiments(ctx context.Context) ([]experiments.ExperimentDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExperiments", ctx)
+	ret0, _ := ret[0].([]experiments.ExperimentDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExperiments indicates an expected call of ListExperiments
+func (mr *MockServiceMockRecorder) ListExperiments(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExperiments", reflect.TypeOf((*MockService)(nil).ListExperiments), ctx)
+}
+
+// UpdateExperiment mocks base method
+func (m *MockService) UpdateExperiment(ctx context.Context, experimentId uint, dto experiments.UpdateExperimentDto) (experiments.ExperimentDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExperiment", ctx, experimentId, dto)
+	ret0, _ := ret[0].(experiments.ExperimentDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateExperiment indicates an expected call of UpdateExperiment
+func (mr *MockServiceMockRecorder) UpdateExperiment(ctx, experimentId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExperiment", reflect.TypeOf((*MockService)(nil).UpdateExperiment), ctx, experimentId, dto)
+}
+
+// Assignments mocks base method
+func (m *MockService) Assignments(subject string) map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Assignments", subject)
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// Assignments indicates an expected call of Assignments
+func (mr *MockServiceMockRecorder) Assignments(subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assignments", reflect.TypeOf((*MockService)(nil).Assignments), subject)
+}
+
+// Variant mocks base method
+func (m *MockService) Variant(subject, key string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Variant", subject, key)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Variant indicates an expected call of Variant
+func (mr *MockServiceMockRecorder) Variant(subject, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Variant", reflect.TypeOf((*MockService)(nil).Variant), subject, key)
+}
+
+// RecordExposure mocks base method
+func (m *MockService) RecordExposure(ctx context.Context, subject, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordExposure", ctx, subject, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordExposure indicates an expected call of RecordExposure
+func (mr *MockServiceMockRecorder) RecordExposure(ctx, subject, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordExposure", reflect.TypeOf((*MockService)(nil).RecordExposure), ctx, subject, key)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
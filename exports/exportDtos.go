@@ -0,0 +1,44 @@
+package exports
+
+import (
+	"github.com/open-collaboration/server/calendar"
+	"github.com/open-collaboration/server/chat"
+	"github.com/open-collaboration/server/contributions"
+	"github.com/open-collaboration/server/projects"
+	"time"
+)
+
+type ExportDto struct {
+	Id          uint       `json:"id"`
+	ProjectId   uint       `json:"projectId"`
+	Format      Format     `json:"format"`
+	Status      Status     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+
+	// When the export can't be downloaded anymore
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// The content of an export. ZIP exports have a file per field, e.g.
+// members.json.
+type ArchiveDto struct {
+	// Bumped when the archive's format changes incompatibly
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+
+	// The project's metadata and roles
+	Project projects.ProjectDto `json:"project"`
+
+	// Members who hide their memberships are left out
+	Members []projects.MemberDto `json:"members"`
+
+	// The project's activity
+	StatusHistory []projects.StatusChangeDto      `json:"statusHistory"`
+	Contributions []contributions.ContributionDto `json:"contributions"`
+	Events        []calendar.EventDto             `json:"events"`
+
+	// The project's chat history, oldest first. Empty while chat is turned off
+	ChatMessages []chat.MessageDto `json:"chatMessages"`
+}
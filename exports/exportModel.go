@@ -0,0 +1,50 @@
+package exports
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// How an export is archived.
+type Format string
+
+const (
+	// A single JSON document
+	FormatJson Format = "json"
+
+	// A ZIP file with a JSON file per section
+	FormatZip Format = "zip"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusReady   Status = "ready"
+	StatusFailed  Status = "failed"
+)
+
+// An archive of a project's data requested by its owner. Exports are
+// generated in the background (see Service.Run) and stored in the database,
+// so that any instance can serve them, until they expire.
+type Export struct {
+	gorm.Model
+
+	ProjectId   uint
+	RequestedBy uint
+	Format      Format
+	Status      Status
+
+	// The archive, once the export is ready
+	Content []byte
+
+	// Why the export failed, if it did
+	Error string
+
+	CompletedAt *time.Time
+}
+
+func (Export) TableName() string {
+	return "project_exports"
+}
@@ -0,0 +1,106 @@
+package exports
+
+import (
+	"fmt"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Export a project's data
+// @Description An archive of the project's metadata and roles, members, status history, contributions, events and
+// @Description chat history, for its owner to back it up or migrate it. Archives are generated in the background: the
+// @Description first request returns 202 with the export's status, and the owner is notified once the archive is
+// @Description ready; requesting the export again then downloads it. Archives can be downloaded for 7 days, pass
+// @Description refresh=true to generate a new one.
+// @Tags projects
+// @Router /projects/{projectId}/export [get]
+// @Param projectId path int true "The project ID"
+// @Param format query string false "json (default) for a single JSON document, or zip for a JSON file per section"
+// @Param refresh query bool false "Generate a new archive even if there's one already"
+// @Produce json,application/zip
+// @Success 200 {object} exports.ArchiveDto "The archive, as a file"
+// @Success 202 {object} exports.ExportDto "The archive is being generated"
+// @Failure 400 "Unknown format"
+// @Failure 401
+// @Failure 403 "User does not own the project"
+// @Failure 404 "Project not found"
+func RouteExportProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	exportsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := checkProjectOwner(request, projectsService)
+	if err != nil {
+		return err
+	}
+
+	format := Format(request.URL.Query().Get("format"))
+	if format == "" {
+		format = FormatJson
+	}
+
+	refresh := request.URL.Query().Get("refresh") == "true"
+
+	export, err := exportsService.GetOrRequestExport(request.Context(), session.UserId(), projectId, format, refresh)
+	if err != nil {
+		return err
+	}
+
+	if export.Status != StatusReady {
+		return utils.WriteJson(writer, request.Context(), http.StatusAccepted, export)
+	}
+
+	export, content, err := exportsService.GetExportContent(request.Context(), projectId, export.Id)
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/json"
+	if export.Format == FormatZip {
+		contentType = "application/zip"
+	}
+
+	filename := fmt.Sprintf("project-%d-export-%d.%s", projectId, export.Id, export.Format)
+
+	writer.Header().Set("Content-Type", contentType)
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	writer.Header().Set("Cache-Control", "private, no-store")
+	writer.WriteHeader(http.StatusOK)
+
+	_, err = writer.Write(content)
+
+	return err
+}
+
+// Check that the request's session belongs to the owner of the project in
+// the projectId route variable. Returns the project's id.
+func checkProjectOwner(request *http.Request, projectsService projects.Service) (uint, error) {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return 0, err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return 0, err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return 0, err
+	}
+
+	if project.OwnerId != session.UserId() {
+		return 0, auth.ErrForbidden
+	}
+
+	return projectId, nil
+}
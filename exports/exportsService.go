@@ -0,0 +1,461 @@
+package exports
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/calendar"
+	"github.com/open-collaboration/server/chat"
+	"github.com/open-collaboration/server/contributions"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/projects"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrExportNotFound = errors.New("export not found")
+var ErrExportNotReady = errors.New("export is not ready")
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// How often pending exports are looked for, besides right after an export is
+// requested on this instance.
+const exportPollInterval = 30 * time.Second
+
+// Exports running for longer than this are assumed to have been abandoned,
+// e.g. because the instance generating them stopped, and are generated again.
+const exportTimeout = 10 * time.Minute
+
+// How long exports can be downloaded. Expired exports are deleted by Run.
+const exportRetention = 7 * 24 * time.Hour
+
+// The version of ArchiveDto.
+const archiveVersion = 1
+
+type Service interface {
+	// Get the project's latest export in the format, requesting one if the
+	// project has no pending, running or ready export in that format or if
+	// refresh is true. Exports are generated in the background by Run, which
+	// notifies whoever requested them once they're ready.
+	// Returns ErrUnknownFormat if the format isn't json or zip.
+	GetOrRequestExport(ctx context.Context, requestedBy uint, projectId uint, format Format, refresh bool) (ExportDto, error)
+
+	// Get an export's archive.
+	// Returns ErrExportNotFound if the project doesn't have the export and
+	// ErrExportNotReady if it wasn't generated (yet).
+	GetExportContent(ctx context.Context, projectId uint, exportId uint) (ExportDto, []byte, error)
+
+	// Generate requested exports and delete expired ones until ctx is done.
+	// Should be run in its own goroutine. Each export is generated by a
+	// single instance.
+	Run(ctx context.Context)
+}
+
+type serviceImpl struct {
+	Db                   *gorm.DB
+	ProjectsService      projects.Service
+	ContributionsService contributions.Service
+	CalendarService      calendar.Service
+	ChatService          chat.Service
+	NotificationsService notifications.Service
+
+	// Wakes Run up when an export is requested
+	wake chan struct{}
+}
+
+func NewService(
+	db *gorm.DB,
+	projectsService projects.Service,
+	contributionsService contributions.Service,
+	calendarService calendar.Service,
+	chatService chat.Service,
+	notificationsService notifications.Service,
+) Service {
+	return &serviceImpl{
+		Db:                   db,
+		ProjectsService:      projectsService,
+		ContributionsService: contributionsService,
+		CalendarService:      calendarService,
+		ChatService:          chatService,
+		NotificationsService: notificationsService,
+		wake:                 make(chan struct{}, 1),
+	}
+}
+
+func exportToDto(export Export) ExportDto {
+	return ExportDto{
+		Id:          export.ID,
+		ProjectId:   export.ProjectId,
+		Format:      export.Format,
+		Status:      export.Status,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.CreatedAt.Add(exportRetention),
+	}
+}
+
+func (s *serviceImpl) GetOrRequestExport(ctx context.Context, requestedBy uint, projectId uint, format Format, refresh bool) (ExportDto, error) {
+	if format != FormatJson && format != FormatZip {
+		return ExportDto{}, ErrUnknownFormat
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	if !refresh {
+		var exports []Export
+		result := s.Db.WithContext(ctx).
+			Omit("content").
+			Where("project_id = ? AND format = ? AND status <> ?", projectId, format, StatusFailed).
+			Where("created_at > ?", time.Now().Add(-exportRetention)).
+			Order("id desc").
+			Limit(1).
+			Find(&exports)
+
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to get latest export")
+
+			return ExportDto{}, result.Error
+		}
+
+		if len(exports) > 0 {
+			return exportToDto(exports[0]), nil
+		}
+	}
+
+	export := Export{
+		ProjectId:   projectId,
+		RequestedBy: requestedBy,
+		Format:      format,
+		Status:      StatusPending,
+	}
+
+	result := s.Db.WithContext(ctx).Create(&export)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to request export")
+
+		return ExportDto{}, result.Error
+	}
+
+	// Run may already be awake, in which case it will find the export anyway
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return exportToDto(export), nil
+}
+
+func (s *serviceImpl) GetExportContent(ctx context.Context, projectId uint, exportId uint) (ExportDto, []byte, error) {
+	export := Export{}
+	result := s.Db.WithContext(ctx).
+		Where("project_id = ? AND created_at > ?", projectId, time.Now().Add(-exportRetention)).
+		First(&export, exportId)
+
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ExportDto{}, nil, ErrExportNotFound
+		}
+
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for export")
+
+		return ExportDto{}, nil, result.Error
+	}
+
+	if export.Status != StatusReady {
+		return ExportDto{}, nil, ErrExportNotReady
+	}
+
+	return exportToDto(export), export.Content, nil
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(exportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deleteExpired(ctx)
+
+		// Generate exports until there are none left
+		for s.generateNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Delete expired exports, and their archives right away rather than when
+// soft deleted rows are pruned.
+func (s *serviceImpl) deleteExpired(ctx context.Context) {
+	result := s.Db.WithContext(ctx).
+		Model(&Export{}).
+		Where("created_at <= ?", time.Now().Add(-exportRetention)).
+		Updates(map[string]interface{}{
+			"content":    nil,
+			"deleted_at": time.Now(),
+		})
+
+	if result.Error != nil && ctx.Err() == nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete expired exports")
+	}
+}
+
+// Claim the oldest pending export and generate it. Returns whether an export
+// was claimed.
+func (s *serviceImpl) generateNext(ctx context.Context) bool {
+	logger := log.FromContext(ctx)
+
+	claimable := s.Db.
+		Where("status = ? OR (status = ? AND updated_at < ?)", StatusPending, StatusRunning, time.Now().Add(-exportTimeout))
+
+	var exports []Export
+	result := s.Db.WithContext(ctx).
+		Omit("content").
+		Where(claimable).
+		Order("id").
+		Limit(1).
+		Find(&exports)
+
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			logger.WithError(result.Error).Error("Failed to find a pending export")
+		}
+
+		return false
+	}
+
+	if len(exports) < 1 {
+		return false
+	}
+
+	export := exports[0]
+	logger = logger.WithFields(log.Fields{
+		"exportId":  export.ID,
+		"projectId": export.ProjectId,
+	})
+
+	// Only one instance's update matches, the others look for another export
+	result = s.Db.WithContext(ctx).
+		Model(&Export{}).
+		Where("id = ?", export.ID).
+		Where(claimable).
+		Updates(map[string]interface{}{
+			"status":     StatusRunning,
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to claim export")
+
+		return false
+	}
+
+	if result.RowsAffected < 1 {
+		return true
+	}
+
+	content, err := s.generate(ctx, export)
+	now := time.Now()
+
+	update := map[string]interface{}{
+		"status":       StatusReady,
+		"content":      content,
+		"completed_at": now,
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to generate export")
+
+		update = map[string]interface{}{
+			"status":       StatusFailed,
+			"error":        err.Error(),
+			"completed_at": now,
+		}
+	}
+
+	result = s.Db.WithContext(ctx).Model(&Export{}).Where("id = ?", export.ID).Updates(update)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to save export")
+
+		return true
+	}
+
+	if err == nil {
+		logger.Info("Export generated")
+
+		s.notifyReady(ctx, export)
+	}
+
+	return true
+}
+
+// Generate an export's archive.
+func (s *serviceImpl) generate(ctx context.Context, export Export) ([]byte, error) {
+	archive, err := s.collect(ctx, export)
+	if err != nil {
+		return nil, err
+	}
+
+	if export.Format == FormatJson {
+		return json.MarshalIndent(archive, "", "  ")
+	}
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"project.json", archive.Project},
+		{"members.json", archive.Members},
+		{"status-history.json", archive.StatusHistory},
+		{"contributions.json", archive.Contributions},
+		{"events.json", archive.Events},
+		{"chat-messages.json", archive.ChatMessages},
+		{"export.json", map[string]interface{}{
+			"version":    archive.Version,
+			"exportedAt": archive.ExportedAt,
+		}},
+	}
+
+	buffer := bytes.Buffer{}
+	writer := zip.NewWriter(&buffer)
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		fileWriter, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: archive.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = fileWriter.Write(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Collect the project's data, as seen by whoever requested the export.
+func (s *serviceImpl) collect(ctx context.Context, export Export) (ArchiveDto, error) {
+	archive := ArchiveDto{
+		Version:    archiveVersion,
+		ExportedAt: time.Now().UTC(),
+	}
+
+	project, err := s.ProjectsService.GetProject(ctx, export.ProjectId)
+	if err != nil {
+		return ArchiveDto{}, err
+	}
+
+	archive.Project = project
+
+	archive.Members, err = s.ProjectsService.ListMembers(ctx, export.RequestedBy, export.ProjectId)
+	if err != nil {
+		return ArchiveDto{}, err
+	}
+
+	archive.StatusHistory, err = s.ProjectsService.ListStatusChanges(ctx, export.ProjectId)
+	if err != nil {
+		return ArchiveDto{}, err
+	}
+
+	archive.Contributions, err = s.ContributionsService.ListProjectContributions(ctx, export.ProjectId, export.RequestedBy)
+	if err != nil {
+		return ArchiveDto{}, err
+	}
+
+	archive.Events, err = s.CalendarService.ListEvents(ctx, export.ProjectId)
+	if err != nil {
+		return ArchiveDto{}, err
+	}
+
+	archive.ChatMessages, err = s.chatHistory(ctx, export)
+	if err != nil {
+		return ArchiveDto{}, err
+	}
+
+	return archive, nil
+}
+
+// The project's chat messages, oldest first.
+func (s *serviceImpl) chatHistory(ctx context.Context, export Export) ([]chat.MessageDto, error) {
+	var messages []chat.MessageDto
+
+	var before uint
+	for {
+		page, hasMore, err := s.ChatService.ListMessages(ctx, export.RequestedBy, export.ProjectId, before, chat.MaxPageSize)
+		if errors.Is(err, chat.ErrChatDisabled) {
+			return []chat.MessageDto{}, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, page...)
+
+		if !hasMore || len(page) < 1 {
+			break
+		}
+
+		before = page[len(page)-1].Id
+	}
+
+	// Pages are listed newest first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	if messages == nil {
+		messages = []chat.MessageDto{}
+	}
+
+	return messages, nil
+}
+
+// Tell whoever requested an export that it can be downloaded. Failures are
+// only logged, the export is downloadable anyway.
+func (s *serviceImpl) notifyReady(ctx context.Context, export Export) {
+	logger := log.FromContext(ctx).WithField("exportId", export.ID)
+
+	project, err := s.ProjectsService.GetProject(ctx, export.ProjectId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get project, the export won't be notified")
+
+		return
+	}
+
+	err = s.NotificationsService.Notify(ctx, export.RequestedBy, notifications.NewNotificationDto{
+		Type:  notifications.TypeProjectExportReady,
+		Title: fmt.Sprintf("Your export of %s is ready", project.Name),
+		Body:  fmt.Sprintf("It can be downloaded for %d days.", int(exportRetention.Hours()/24)),
+		Data: map[string]interface{}{
+			"projectId": export.ProjectId,
+			"exportId":  export.ID,
+			"format":    export.Format,
+		},
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to notify of export")
+	}
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: exportsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	exports "github.com/open-collaboration/server/exports"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetOrRequestExport mocks base method
+func (m *MockService) GetOrRequestExport(ctx context.Context, requestedBy, projectId uint, format exports.Format, refresh bool) (exports.ExportDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrRequestExport", ctx, requestedBy, projectId, format, refresh)
+	ret0, _ := ret[0].(exports.ExportDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrRequestExport indicates an expected call of GetOrRequestExport
+func (mr *MockServiceMockRecorder) GetOrRequestExport(ctx, requestedBy, projectId, format, refresh interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrRequestExport", reflect.TypeOf((*MockService)(nil).GetOrRequestExport), ctx, requestedBy, projectId, format, refresh)
+}
+
+// GetExportContent mocks base method
+func (m *MockService) GetExportContent(ctx context.Context, projectId, exportId uint) (exports.ExportDto, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExportContent", ctx, projectId, exportId)
+	ret0, _ := ret[0].(exports.ExportDto)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetExportContent indicates an expected call of GetExportContent
+func (mr *MockServiceMockRecorder) GetExportContent(ctx, projectId, exportId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExportContent", reflect.TypeOf((*MockService)(nil).GetExportContent), ctx, projectId, exportId)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
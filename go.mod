@@ -4,23 +4,28 @@ go 1.16
 
 require (
 	github.com/ItsaMeTuni/godi v0.0.0-20210410034142-393252e8d661
+	github.com/andybalholm/brotli v1.0.4
 	github.com/apex/log v1.9.0
 	github.com/fatih/color v1.9.0
 	github.com/go-gormigrate/gormigrate/v2 v2.0.0
-	github.com/go-playground/validator/v10 v10.5.0 // indirect
+	github.com/go-playground/validator/v10 v10.5.0
 	github.com/go-redis/redis/v8 v8.8.0
 	github.com/gofrs/uuid v3.2.0+incompatible
+	github.com/golang/mock v1.3.1-0.20190508161146-9fa652df1129
 	github.com/gorilla/mux v1.8.0
+	github.com/jackc/pgconn v1.6.4
+	github.com/jackc/pgx/v4 v4.8.1
 	github.com/joho/godotenv v1.3.0
 	github.com/lib/pq v1.3.0
 	github.com/mattn/go-colorable v0.1.6
-	github.com/mattn/go-sqlite3 v1.14.6 // indirect
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/pkg/errors v0.9.1 // indirect
 	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2
+	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110
 	golang.org/x/sys v0.0.0-20210403161142-5e06dd20ab57 // indirect
-	golang.org/x/text v0.3.6 // indirect
+	golang.org/x/text v0.3.6
 	gopkg.in/yaml.v3 v3.0.0-20200615113413-eeeca48fe776 // indirect
 	gorm.io/driver/postgres v1.0.0
-	gorm.io/driver/sqlite v1.1.4 // indirect
+	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.21.6
 )
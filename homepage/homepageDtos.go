@@ -0,0 +1,40 @@
+package homepage
+
+import "github.com/open-collaboration/server/projects"
+
+type NewSectionDto struct {
+	Kind  SectionKind `json:"kind" validate:"required,oneof=featured new trending-tag"`
+	Title string      `json:"title" validate:"required,max=64"`
+	Tag   string      `json:"tag" validate:"required_if=Kind trending-tag,max=32"`
+	Size  int         `json:"size" validate:"min=1,max=20"`
+}
+
+type SectionDto struct {
+	Id       uint        `json:"id"`
+	Position int         `json:"position"`
+	Kind     SectionKind `json:"kind"`
+	Title    string      `json:"title"`
+	Tag      string      `json:"tag"`
+	Size     int         `json:"size"`
+}
+
+type FeatureProjectDto struct {
+	ProjectId uint `json:"projectId" validate:"required"`
+}
+
+// A new order for sections or featured projects: all ids, in
+// the new order.
+type ReorderDto struct {
+	Ids []uint `json:"ids" validate:"required"`
+}
+
+type HomepageSectionDto struct {
+	Title    string                       `json:"title"`
+	Kind     SectionKind                  `json:"kind"`
+	Tag      string                       `json:"tag,omitempty"`
+	Projects []projects.ProjectSummaryDto `json:"projects"`
+}
+
+type HomepageDto struct {
+	Sections []HomepageSectionDto `json:"sections"`
+}
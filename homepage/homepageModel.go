@@ -0,0 +1,44 @@
+package homepage
+
+import "gorm.io/gorm"
+
+type SectionKind string
+
+const (
+	// The projects pinned by admins, see FeaturedProject.
+	SectionFeatured SectionKind = "featured"
+
+	// The newest projects.
+	SectionNew SectionKind = "new"
+
+	// The most recently updated projects with a tag.
+	SectionTrendingTag SectionKind = "trending-tag"
+)
+
+// A section of the homepage. Sections are shown ordered by position.
+type Section struct {
+	gorm.Model
+
+	Position int
+	Kind     SectionKind
+	Title    string
+
+	// Only used by SectionTrendingTag sections.
+	Tag string
+
+	// Maximum amount of projects in the section.
+	Size int
+}
+
+func (Section) TableName() string {
+	return "homepage_sections"
+}
+
+// A project pinned by an admin to the featured section. Featured projects
+// are shown ordered by position.
+type FeaturedProject struct {
+	gorm.Model
+
+	ProjectId uint
+	Position  int
+}
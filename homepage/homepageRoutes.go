@@ -0,0 +1,312 @@
+package homepage
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Get the homepage
+// @Description The curated homepage sections with their projects.
+// @Tags homepage
+// @Router /homepage [get]
+// @Success 200 {object} homepage.HomepageDto
+func RouteGetHomepage(writer http.ResponseWriter, request *http.Request, homepageService Service) error {
+	homepage, err := homepageService.GetHomepage(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, homepage)
+}
+
+// @Summary List homepage sections
+// @Tags admin
+// @Router /admin/homepage/sections [get]
+// @Success 200 {array} homepage.SectionDto
+// @Failure 403
+func RouteListSections(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	sections, err := homepageService.ListSections(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, sections)
+}
+
+// @Summary Add a homepage section
+// @Description The section is added to the bottom of the homepage.
+// @Tags admin
+// @Router /admin/homepage/sections [post]
+// @Param section body homepage.NewSectionDto true "The section"
+// @Success 201 {object} homepage.SectionDto
+// @Failure 403
+func RouteCreateSection(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := NewSectionDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	section, err := homepageService.CreateSection(request.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, section)
+}
+
+// @Summary Update a homepage section
+// @Tags admin
+// @Router /admin/homepage/sections/{sectionId} [post]
+// @Param sectionId path int true "The section ID"
+// @Param section body homepage.NewSectionDto true "The section"
+// @Success 200
+// @Failure 403
+// @Failure 404
+func RouteUpdateSection(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	sectionId, err := utils.UintFromVars(request, "sectionId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewSectionDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = homepageService.UpdateSection(request.Context(), sectionId, dto)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// @Summary Delete a homepage section
+// @Tags admin
+// @Router /admin/homepage/sections/{sectionId} [delete]
+// @Param sectionId path int true "The section ID"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteDeleteSection(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	sectionId, err := utils.UintFromVars(request, "sectionId")
+	if err != nil {
+		return err
+	}
+
+	err = homepageService.DeleteSection(request.Context(), sectionId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Reorder homepage sections
+// @Tags admin
+// @Router /admin/homepage/sections/order [post]
+// @Param order body homepage.ReorderDto true "The ids of all sections in their new order"
+// @Success 200
+// @Failure 400 "The ids don't match the existing sections"
+// @Failure 403
+func RouteReorderSections(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := ReorderDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = homepageService.ReorderSections(request.Context(), dto.Ids)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// @Summary List featured projects
+// @Tags admin
+// @Router /admin/homepage/featured [get]
+// @Success 200 {array} dtos.ProjectSummaryDto
+// @Failure 403
+func RouteListFeaturedProjects(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	projectSummaries, err := homepageService.ListFeaturedProjects(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, projectSummaries)
+}
+
+// @Summary Feature a project
+// @Description The project is added to the end of the featured projects.
+// @Tags admin
+// @Router /admin/homepage/featured [post]
+// @Param project body homepage.FeatureProjectDto true "The project to feature"
+// @Success 201
+// @Failure 403
+// @Failure 404
+// @Failure 409 "The project is already featured"
+func RouteFeatureProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := FeatureProjectDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = homepageService.FeatureProject(request.Context(), dto.ProjectId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+
+	return nil
+}
+
+// @Summary Unfeature a project
+// @Tags admin
+// @Router /admin/homepage/featured/{projectId} [delete]
+// @Param projectId path int true "The project ID"
+// @Success 204
+// @Failure 403
+// @Failure 404 "The project is not featured"
+func RouteUnfeatureProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	err = homepageService.UnfeatureProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Reorder featured projects
+// @Tags admin
+// @Router /admin/homepage/featured/order [post]
+// @Param order body homepage.ReorderDto true "The ids of all featured projects in their new order"
+// @Success 200
+// @Failure 400 "The ids don't match the featured projects"
+// @Failure 403
+func RouteReorderFeaturedProjects(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	homepageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := ReorderDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = homepageService.ReorderFeaturedProjects(request.Context(), dto.Ids)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+
+	return nil
+}
@@ -0,0 +1,463 @@
+package homepage
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/projects"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrSectionNotFound = errors.New("homepage section not found")
+var ErrProjectNotFeatured = errors.New("project is not featured")
+var ErrProjectAlreadyFeatured = errors.New("project is already featured")
+var ErrInvalidOrder = errors.New("the new order must contain every id exactly once")
+
+// How long the assembled homepage is cached. The cache is also invalidated
+// whenever the curation changes, so this only bounds how stale the New and
+// Trending sections can get.
+const homepageCacheDuration = 5 * time.Minute
+
+// The assembled homepage.
+const homepageRedisKey = "homepage"
+
+// Shown when no sections have been configured.
+var defaultSections = []Section{
+	{Kind: SectionFeatured, Title: "Featured", Size: 6},
+	{Kind: SectionNew, Title: "New", Size: 6},
+}
+
+type Service interface {
+	// Assemble the homepage from the configured sections. If no sections are
+	// configured, the featured and newest projects are shown.
+	GetHomepage(ctx context.Context) (HomepageDto, error)
+
+	// List the homepage sections, ordered by position.
+	ListSections(ctx context.Context) ([]SectionDto, error)
+
+	// Add a section to the bottom of the homepage.
+	CreateSection(ctx context.Context, dto NewSectionDto) (SectionDto, error)
+
+	// Update a section. Returns ErrSectionNotFound if the section can't be found.
+	UpdateSection(ctx context.Context, sectionId uint, dto NewSectionDto) error
+
+	// Delete a section. Returns ErrSectionNotFound if the section can't be found.
+	DeleteSection(ctx context.Context, sectionId uint) error
+
+	// Reorder the sections. sectionIds must contain the ids of all sections in
+	// their new order, otherwise ErrInvalidOrder is returned.
+	ReorderSections(ctx context.Context, sectionIds []uint) error
+
+	// Point the trending tag sections of the source tags to the target tag,
+	// used when tags are merged.
+	MergeTags(ctx context.Context, sources []string, target string) error
+
+	// Delete the cached homepage, e.g. after projects were changed in bulk.
+	InvalidateCache(ctx context.Context)
+
+	// List the featured projects, ordered by position.
+	ListFeaturedProjects(ctx context.Context) ([]projects.ProjectSummaryDto, error)
+
+	// Pin a project to the end of the featured section.
+	// Returns projects.ErrProjectNotFound if the project can't be found or is
+	// pending review and ErrProjectAlreadyFeatured if it's already featured.
+	FeatureProject(ctx context.Context, projectId uint) error
+
+	// Remove a project from the featured section.
+	// Returns ErrProjectNotFeatured if the project isn't featured.
+	UnfeatureProject(ctx context.Context, projectId uint) error
+
+	// Reorder the featured projects. projectIds must contain the ids of all
+	// featured projects in their new order, otherwise ErrInvalidOrder is returned.
+	ReorderFeaturedProjects(ctx context.Context, projectIds []uint) error
+}
+
+type serviceImpl struct {
+	Db              *gorm.DB
+	Kv              kv.Store
+	ProjectsService projects.Service
+}
+
+func NewService(db *gorm.DB, kvStore kv.Store, projectsService projects.Service) Service {
+	return &serviceImpl{
+		Db:              db,
+		Kv:              kvStore,
+		ProjectsService: projectsService,
+	}
+}
+
+func (s *serviceImpl) GetHomepage(ctx context.Context) (HomepageDto, error) {
+	logger := log.FromContext(ctx)
+
+	homepage := HomepageDto{}
+
+	cached, err := s.Kv.Get(ctx, homepageRedisKey)
+	if err == nil {
+		err = json.Unmarshal([]byte(cached), &homepage)
+		if err == nil {
+			return homepage, nil
+		}
+
+		logger.WithError(err).Warn("Failed to unmarshal cached homepage")
+	} else if !errors.Is(err, kv.ErrNotFound) {
+		logger.WithError(err).Warn("Failed to get cached homepage, falling back to the database")
+	}
+
+	var sections []Section
+	result := s.Db.WithContext(ctx).Order("position asc").Find(&sections)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list homepage sections")
+
+		return HomepageDto{}, result.Error
+	}
+
+	if len(sections) < 1 {
+		sections = defaultSections
+	}
+
+	homepage.Sections = make([]HomepageSectionDto, len(sections))
+	for i, section := range sections {
+		projectSummaries, err := s.sectionProjects(ctx, section)
+		if err != nil {
+			return HomepageDto{}, err
+		}
+
+		homepage.Sections[i] = HomepageSectionDto{
+			Title:    section.Title,
+			Kind:     section.Kind,
+			Tag:      section.Tag,
+			Projects: projectSummaries,
+		}
+	}
+
+	encoded, err := json.Marshal(homepage)
+	if err == nil {
+		err = s.Kv.Set(ctx, homepageRedisKey, string(encoded), homepageCacheDuration)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to cache homepage")
+	}
+
+	return homepage, nil
+}
+
+// Get the projects shown in a section.
+func (s *serviceImpl) sectionProjects(ctx context.Context, section Section) ([]projects.ProjectSummaryDto, error) {
+	switch section.Kind {
+	case SectionFeatured:
+		projectSummaries, err := s.ListFeaturedProjects(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(projectSummaries) > section.Size {
+			projectSummaries = projectSummaries[:section.Size]
+		}
+
+		return projectSummaries, nil
+
+	case SectionNew:
+		projectSummaries, _, err := s.ProjectsService.ListProjects(
+			ctx,
+			uint(section.Size),
+			0,
+			projects.ProjectFilters{},
+			projects.OrderByNewest,
+		)
+
+		return projectSummaries, err
+
+	case SectionTrendingTag:
+		projectSummaries, _, err := s.ProjectsService.ListProjects(
+			ctx,
+			uint(section.Size),
+			0,
+			projects.ProjectFilters{Tags: []string{section.Tag}},
+			projects.OrderByRecentlyUpdated,
+		)
+
+		return projectSummaries, err
+
+	default:
+		log.FromContext(ctx).WithField("kind", section.Kind).Warn("Unknown homepage section kind")
+
+		return []projects.ProjectSummaryDto{}, nil
+	}
+}
+
+func (s *serviceImpl) ListSections(ctx context.Context) ([]SectionDto, error) {
+	var sections []Section
+	result := s.Db.WithContext(ctx).Order("position asc").Find(&sections)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list homepage sections")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]SectionDto, len(sections))
+	for i, section := range sections {
+		dtos[i] = sectionToDto(section)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) CreateSection(ctx context.Context, dto NewSectionDto) (SectionDto, error) {
+	logger := log.FromContext(ctx)
+
+	var lastPosition int
+	result := s.Db.WithContext(ctx).Model(&Section{}).Select("coalesce(max(position), -1)").Scan(&lastPosition)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get the last homepage section")
+
+		return SectionDto{}, result.Error
+	}
+
+	section := Section{
+		Position: lastPosition + 1,
+		Kind:     dto.Kind,
+		Title:    dto.Title,
+		Tag:      dto.Tag,
+		Size:     dto.Size,
+	}
+
+	result = s.Db.WithContext(ctx).Create(&section)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create homepage section")
+
+		return SectionDto{}, result.Error
+	}
+
+	s.InvalidateCache(ctx)
+
+	return sectionToDto(section), nil
+}
+
+func (s *serviceImpl) UpdateSection(ctx context.Context, sectionId uint, dto NewSectionDto) error {
+	result := s.Db.WithContext(ctx).
+		Model(&Section{Model: gorm.Model{ID: sectionId}}).
+		Select("kind", "title", "tag", "size").
+		Updates(&Section{
+			Kind:  dto.Kind,
+			Title: dto.Title,
+			Tag:   dto.Tag,
+			Size:  dto.Size,
+		})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to update homepage section")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrSectionNotFound
+	}
+
+	s.InvalidateCache(ctx)
+
+	return nil
+}
+
+func (s *serviceImpl) DeleteSection(ctx context.Context, sectionId uint) error {
+	result := s.Db.WithContext(ctx).Delete(&Section{}, sectionId)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete homepage section")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrSectionNotFound
+	}
+
+	s.InvalidateCache(ctx)
+
+	return nil
+}
+
+func (s *serviceImpl) ReorderSections(ctx context.Context, sectionIds []uint) error {
+	err := s.reorder(ctx, &Section{}, "id", sectionIds)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidOrder) {
+			log.FromContext(ctx).WithError(err).Error("Failed to reorder homepage sections")
+		}
+
+		return err
+	}
+
+	s.InvalidateCache(ctx)
+
+	return nil
+}
+
+func (s *serviceImpl) MergeTags(ctx context.Context, sources []string, target string) error {
+	result := s.Db.WithContext(ctx).
+		Model(&Section{}).
+		Where("kind = ? AND tag IN ?", SectionTrendingTag, sources).
+		Update("tag", target)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to merge homepage section tags")
+
+		return result.Error
+	}
+
+	s.InvalidateCache(ctx)
+
+	return nil
+}
+
+func (s *serviceImpl) ListFeaturedProjects(ctx context.Context) ([]projects.ProjectSummaryDto, error) {
+	var projectIds []uint
+	result := s.Db.WithContext(ctx).Model(&FeaturedProject{}).Order("position asc").Pluck("project_id", &projectIds)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list featured projects")
+
+		return nil, result.Error
+	}
+
+	return s.ProjectsService.ListProjectsById(ctx, projectIds)
+}
+
+func (s *serviceImpl) FeatureProject(ctx context.Context, projectId uint) error {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	// Also makes sure the project isn't pending review
+	found, err := s.ProjectsService.ListProjectsById(ctx, []uint{projectId})
+	if err != nil {
+		return err
+	}
+
+	if len(found) < 1 {
+		return projects.ErrProjectNotFound
+	}
+
+	err = s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		result := tx.Model(&FeaturedProject{}).Where("project_id = ?", projectId).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count > 0 {
+			return ErrProjectAlreadyFeatured
+		}
+
+		var lastPosition int
+		result = tx.Model(&FeaturedProject{}).Select("coalesce(max(position), -1)").Scan(&lastPosition)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Create(&FeaturedProject{
+			ProjectId: projectId,
+			Position:  lastPosition + 1,
+		}).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrProjectAlreadyFeatured) {
+			logger.WithError(err).Error("Failed to feature project")
+		}
+
+		return err
+	}
+
+	s.InvalidateCache(ctx)
+
+	return nil
+}
+
+func (s *serviceImpl) UnfeatureProject(ctx context.Context, projectId uint) error {
+	// Featured projects are deleted for good, so that the
+	// unique project id index doesn't stop them from being featured again
+	result := s.Db.WithContext(ctx).Unscoped().Where("project_id = ?", projectId).Delete(&FeaturedProject{})
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to unfeature project")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrProjectNotFeatured
+	}
+
+	s.InvalidateCache(ctx)
+
+	return nil
+}
+
+func (s *serviceImpl) ReorderFeaturedProjects(ctx context.Context, projectIds []uint) error {
+	err := s.reorder(ctx, &FeaturedProject{}, "project_id", projectIds)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidOrder) {
+			log.FromContext(ctx).WithError(err).Error("Failed to reorder featured projects")
+		}
+
+		return err
+	}
+
+	s.InvalidateCache(ctx)
+
+	return nil
+}
+
+// Set the position of every row of model to the index of its idColumn
+// in ids. Returns ErrInvalidOrder if ids doesn't contain every row's id
+// exactly once.
+func (s *serviceImpl) reorder(ctx context.Context, model interface{}, idColumn string, ids []uint) error {
+	return s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingIds []uint
+		result := tx.Model(model).Pluck(idColumn, &existingIds)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(existingIds) != len(ids) {
+			return ErrInvalidOrder
+		}
+
+		positions := make(map[uint]int, len(ids))
+		for i, id := range ids {
+			positions[id] = i
+		}
+
+		for _, id := range existingIds {
+			if _, ok := positions[id]; !ok {
+				return ErrInvalidOrder
+			}
+		}
+
+		for id, position := range positions {
+			result = tx.Model(model).Where(idColumn+" = ?", id).Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *serviceImpl) InvalidateCache(ctx context.Context) {
+	err := s.Kv.Del(ctx, homepageRedisKey)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Warn("Failed to invalidate the homepage cache")
+	}
+}
+
+func sectionToDto(section Section) SectionDto {
+	return SectionDto{
+		Id:       section.ID,
+		Position: section.Position,
+		Kind:     section.Kind,
+		Title:    section.Title,
+		Tag:      section.Tag,
+		Size:     section.Size,
+	}
+}
@@ -0,0 +1,206 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: homepageService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	homepage "github.com/open-collaboration/server/homepage"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetHomepage mocks base method
+func (m *MockService) GetHomepage(ctx context.Context) (homepage.HomepageDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHomepage", ctx)
+	ret0, _ := ret[0].(homepage.HomepageDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHomepage indicates an expected call of GetHomepage
+func (mr *MockServiceMockRecorder) GetHomepage(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHomepage", reflect.TypeOf((*MockService)(nil).GetHomepage), ctx)
+}
+
+// ListSections mocks base method
+func (m *MockService) ListSections(ctx context.Context) ([]homepage.SectionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSections", ctx)
+	ret0, _ := ret[0].([]homepage.SectionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSections indicates an expected call of ListSections
+func (mr *MockServiceMockRecorder) ListSections(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSections", reflect.TypeOf((*MockService)(nil).ListSections), ctx)
+}
+
+// CreateSection mocks base method
+func (m *MockService) CreateSection(ctx context.Context, dto homepage.NewSectionDto) (homepage.SectionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSection", ctx, dto)
+	ret0, _ := ret[0].(homepage.SectionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSection indicates an expected call of CreateSection
+func (mr *MockServiceMockRecorder) CreateSection(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSection", reflect.TypeOf((*MockService)(nil).CreateSection), ctx, dto)
+}
+
+// UpdateSection mocks base method
+func (m *MockService) UpdateSection(ctx context.Context, sectionId uint, dto homepage.NewSectionDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSection", ctx, sectionId, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSection indicates an expected call of UpdateSection
+func (mr *MockServiceMockRecorder) UpdateSection(ctx, sectionId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSection", reflect.TypeOf((*MockService)(nil).UpdateSection), ctx, sectionId, dto)
+}
+
+// DeleteSection mocks base method
+func (m *MockService) DeleteSection(ctx context.Context, sectionId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSection", ctx, sectionId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSection indicates an expected call of DeleteSection
+func (mr *MockServiceMockRecorder) DeleteSection(ctx, sectionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSection", reflect.TypeOf((*MockService)(nil).DeleteSection), ctx, sectionId)
+}
+
+// ReorderSections mocks base method
+func (m *MockService) ReorderSections(ctx context.Context, sectionIds []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderSections", ctx, sectionIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderSections indicates an expected call of ReorderSections
+func (mr *MockServiceMockRecorder) ReorderSections(ctx, sectionIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderSections", reflect.TypeOf((*MockService)(nil).ReorderSections), ctx, sectionIds)
+}
+
+// MergeTags mocks base method
+func (m *MockService) MergeTags(ctx context.Context, sources []string, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", ctx, sources, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeTags indicates an expected call of MergeTags
+func (mr *MockServiceMockRecorder) MergeTags(ctx, sources, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockService)(nil).MergeTags), ctx, sources, target)
+}
+
+// InvalidateCache mocks base method
+func (m *MockService) InvalidateCache(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "InvalidateCache", ctx)
+}
+
+// InvalidateCache indicates an expected call of InvalidateCache
+func (mr *MockServiceMockRecorder) InvalidateCache(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateCache", reflect.TypeOf((*MockService)(nil).InvalidateCache), ctx)
+}
+
+// ListFeaturedProjects mocks base method
+func (m *MockService) ListFeaturedProjects(ctx context.Context) ([]projects.ProjectSummaryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeaturedProjects", ctx)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeaturedProjects indicates an expected call of ListFeaturedProjects
+func (mr *MockServiceMockRecorder) ListFeaturedProjects(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeaturedProjects", reflect.TypeOf((*MockService)(nil).ListFeaturedProjects), ctx)
+}
+
+// FeatureProject mocks base method
+func (m *MockService) FeatureProject(ctx context.Context, projectId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FeatureProject", ctx, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FeatureProject indicates an expected call of FeatureProject
+func (mr *MockServiceMockRecorder) FeatureProject(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeatureProject", reflect.TypeOf((*MockService)(nil).FeatureProject), ctx, projectId)
+}
+
+// UnfeatureProject mocks base method
+func (m *MockService) UnfeatureProject(ctx context.Context, projectId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnfeatureProject", ctx, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnfeatureProject indicates an expected call of UnfeatureProject
+func (mr *MockServiceMockRecorder) UnfeatureProject(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnfeatureProject", reflect.TypeOf((*MockService)(nil).UnfeatureProject), ctx, projectId)
+}
+
+// ReorderFeaturedProjects mocks base method
+func (m *MockService) ReorderFeaturedProjects(ctx context.Context, projectIds []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderFeaturedProjects", ctx, projectIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderFeaturedProjects indicates an expected call of ReorderFeaturedProjects
+func (mr *MockServiceMockRecorder) ReorderFeaturedProjects(ctx, projectIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderFeaturedProjects", reflect.TypeOf((*MockService)(nil).ReorderFeaturedProjects), ctx, projectIds)
+}
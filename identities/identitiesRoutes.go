@@ -0,0 +1,172 @@
+package identities
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Start an oauth flow
+// @Description Returns the url of the provider's authorization page. After the user authorizes us, the provider
+// @Description redirects them to the frontend's `/oauth/{provider}/callback` page, which should complete the flow.
+// @Description Linking an identity requires the session to have been re-authenticated recently.
+// @Tags auth
+// @Router /auth/oauth/{provider}/start [post]
+// @Param provider path string true "github or google"
+// @Param flow body identities.StartOAuthDto true "What the flow is for"
+// @Success 200 {object} identities.OAuthUrlDto
+func RouteStartOAuth(
+	writer http.ResponseWriter,
+	request *http.Request,
+	identitiesService Service,
+) error {
+	dto := StartOAuthDto{}
+	err := utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	var session *auth.Session
+	if dto.Mode != ModeLogin {
+		s, err := auth.CheckSession(request)
+		if err != nil {
+			return err
+		}
+
+		session = &s
+	}
+
+	url, err := identitiesService.StartOAuth(request.Context(), mux.Vars(request)["provider"], dto.Mode, session)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, OAuthUrlDto{Url: url})
+}
+
+// @Summary Complete an oauth flow
+// @Tags auth
+// @Router /auth/oauth/{provider}/callback [post]
+// @Param provider path string true "github or google"
+// @Param callback body identities.CompleteOAuthDto true "The query parameters the provider redirected the user with"
+// @Success 200 {object} identities.OAuthResultDto
+// @Header 200 {string} Set-Cookie "Session token, only when logging in. E.g. sessionToken=72f34c69-6eb0-47cf-83ed-c2b5ad3989df"
+// @Failure 409 "When linking, the account is linked to a user or the user already linked an account of the provider"
+func RouteCompleteOAuth(
+	writer http.ResponseWriter,
+	request *http.Request,
+	identitiesService Service,
+) error {
+	dto := CompleteOAuthDto{}
+	err := utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	result, err := identitiesService.CompleteOAuth(request.Context(), mux.Vars(request)["provider"], dto.Code, dto.State)
+	if err != nil {
+		return err
+	}
+
+	if result.SessionToken != "" {
+		auth.SetSessionCookie(writer, result.SessionToken)
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, OAuthResultDto{Mode: result.Mode})
+}
+
+// @Summary Re-authenticate the current session
+// @Description Some sensitive operations (e.g. linking and unlinking identities) require the session to have
+// @Description been re-authenticated in the last 5 minutes. Users without a password can re-authenticate with
+// @Description an oauth flow in `reauth` mode.
+// @Tags auth
+// @Router /auth/reauth [post]
+// @Param credentials body identities.ReauthDto true "The user's password"
+// @Success 204
+// @Failure 401
+func RouteReauthenticate(
+	writer http.ResponseWriter,
+	request *http.Request,
+	identitiesService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := ReauthDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = identitiesService.Reauthenticate(request.Context(), session, dto.Password)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary List the current user's authentication methods
+// @Tags users
+// @Router /users/me/identities [get]
+// @Success 200 {array} identities.IdentityDto
+// @Failure 401
+func RouteListIdentities(
+	writer http.ResponseWriter,
+	request *http.Request,
+	identitiesService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	identities, err := identitiesService.ListIdentities(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, identities)
+}
+
+// @Summary Unlink an authentication method from the current user
+// @Description Requires the session to have been re-authenticated recently. The last
+// @Description authentication method of a user can't be unlinked. The user's other sessions are logged out and
+// @Description the current session is replaced with a new one.
+// @Tags users
+// @Router /users/me/identities/{provider} [delete]
+// @Param provider path string true "password, github or google"
+// @Success 204
+// @Header 204 {string} Set-Cookie "The new session token"
+// @Failure 401
+// @Failure 403 "The session wasn't re-authenticated recently"
+// @Failure 409 "It's the user's last authentication method"
+func RouteUnlinkIdentity(
+	writer http.ResponseWriter,
+	request *http.Request,
+	identitiesService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	sessionToken, err := identitiesService.Unlink(request.Context(), session, mux.Vars(request)["provider"])
+	if err != nil {
+		return err
+	}
+
+	if sessionToken != "" {
+		auth.SetSessionCookie(writer, sessionToken)
+	} else {
+		auth.ClearSessionCookie(writer)
+	}
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
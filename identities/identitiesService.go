@@ -0,0 +1,492 @@
+// NOTE: take a look at the projects redis documentation (docs/redis.md)
+// to better understand how oauth states and re-authentications are stored.
+
+package identities
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrUnknownProvider = errors.New("unknown auth provider")
+var ErrInvalidOAuthState = errors.New("invalid oauth state")
+var ErrIdentityNotLinked = errors.New("identity is not linked to any user")
+var ErrIdentityAlreadyLinked = errors.New("identity is already linked to a user")
+var ErrProviderAlreadyLinked = errors.New("user already has an identity of the provider")
+var ErrLastAuthMethod = errors.New("cannot remove the last authentication method")
+var ErrReauthRequired = errors.New("re-authentication required")
+
+// Name of the password "provider". Passwords are not stored in the identities
+// table, but they're listed and unlinked like any other identity.
+const PasswordProvider = "password"
+
+// What an oauth flow is for.
+type Mode string
+
+const (
+	// Log in with an identity that is already linked to a user.
+	ModeLogin Mode = "login"
+
+	// Link an identity to the current user.
+	ModeLink Mode = "link"
+
+	// Re-authenticate the current session (for users without a password).
+	ModeReauth Mode = "reauth"
+)
+
+// How long a re-authentication is valid for.
+const reauthDuration = 5 * time.Minute
+
+// How long a user has to complete an oauth flow.
+const oauthStateDuration = 10 * time.Minute
+
+// Stored in the kv store while an oauth flow is in progress.
+type oauthState struct {
+	Provider     string `json:"provider"`
+	Mode         Mode   `json:"mode"`
+	UserId       uint   `json:"userId"`
+	SessionToken string `json:"sessionToken"`
+}
+
+type OAuthResult struct {
+	Mode Mode
+
+	// The session created for the user. Only set when Mode is ModeLogin.
+	SessionToken string
+}
+
+type Service interface {
+	// List all of a user's authentication methods, including their password.
+	ListIdentities(ctx context.Context, userId uint) ([]IdentityDto, error)
+
+	// Start an oauth flow and return the url the user has to be redirected to.
+	// session must be set when mode is ModeLink or ModeReauth.
+	// Returns ErrUnknownProvider if the provider is not configured and
+	// ErrReauthRequired if mode is ModeLink and the session was not
+	// re-authenticated recently.
+	StartOAuth(ctx context.Context, provider string, mode Mode, session *auth.Session) (string, error)
+
+	// Complete an oauth flow started with StartOAuth.
+	// Returns ErrInvalidOAuthState if the flow doesn't exist or expired,
+	// ErrIdentityNotLinked if logging in with an identity that's not linked to a user
+	// and ErrIdentityAlreadyLinked when linking an identity that's linked to a user.
+	// Logins are rejected with the error of the auth.LoginGuard that rejects them.
+	CompleteOAuth(ctx context.Context, provider string, code string, state string) (OAuthResult, error)
+
+	// Unlink an identity from a user. Use PasswordProvider to remove the user's password.
+	// The user's sessions are invalidated and the caller's session is replaced
+	// with the returned one, or ended if it's impersonated (the token is then
+	// empty).
+	// Returns ErrReauthRequired if the session was not re-authenticated recently
+	// and ErrLastAuthMethod if it's the user's only way to log in.
+	Unlink(ctx context.Context, session auth.Session, provider string) (string, error)
+
+	// Re-authenticate a session with the user's password. Some sensitive
+	// operations require the session to have been re-authenticated recently.
+	// Returns auth.ErrWrongPassword if the password is wrong.
+	Reauthenticate(ctx context.Context, session auth.Session, password string) error
+}
+
+type serviceImpl struct {
+	Db           *gorm.DB
+	Kv           kv.Store
+	AuthService  auth.Service
+	UsersService users.Service
+	Providers    map[string]Provider
+
+	// The frontend's url, used to build oauth redirect urls. The frontend
+	// receives the authorization code and completes the flow by calling
+	// the API.
+	FrontendUrl string
+}
+
+func NewService(
+	db *gorm.DB,
+	kvStore kv.Store,
+	authService auth.Service,
+	usersService users.Service,
+	frontendUrl string,
+	providers ...Provider,
+) Service {
+	providersMap := make(map[string]Provider, len(providers))
+	for _, provider := range providers {
+		providersMap[provider.Name()] = provider
+	}
+
+	return &serviceImpl{
+		Db:           db,
+		Kv:           kvStore,
+		AuthService:  authService,
+		UsersService: usersService,
+		Providers:    providersMap,
+		FrontendUrl:  frontendUrl,
+	}
+}
+
+func (s *serviceImpl) ListIdentities(ctx context.Context, userId uint) ([]IdentityDto, error) {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	user, err := s.UsersService.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var identities []Identity
+	result := s.Db.WithContext(ctx).Where("user_id = ?", userId).Order("created_at asc").Find(&identities)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to list identities")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]IdentityDto, 0, len(identities)+1)
+	if user.PasswordHash != "" {
+		dtos = append(dtos, IdentityDto{
+			Provider: PasswordProvider,
+			Email:    user.Email,
+		})
+	}
+
+	for i := range identities {
+		dtos = append(dtos, IdentityDto{
+			Provider:  identities[i].Provider,
+			Email:     identities[i].Email,
+			CreatedAt: &identities[i].CreatedAt,
+		})
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) StartOAuth(ctx context.Context, providerName string, mode Mode, session *auth.Session) (string, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"provider": providerName,
+		"mode":     mode,
+	})
+
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	state := oauthState{
+		Provider: providerName,
+		Mode:     mode,
+	}
+
+	if mode != ModeLogin {
+		if session == nil {
+			return "", auth.ErrUnauthenticated
+		}
+
+		if mode == ModeLink {
+			err := s.checkReauthenticated(ctx, *session)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		state.UserId = session.UserId()
+		state.SessionToken = session.Token()
+	}
+
+	stateKey, err := generateState()
+	if err != nil {
+		logger.WithError(err).Error("Failed to generate oauth state")
+
+		return "", err
+	}
+
+	stateJson, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.Kv.Set(ctx, oauthStateRedisKey(stateKey), string(stateJson), oauthStateDuration)
+	if err != nil {
+		logger.WithError(err).Error("Failed to store oauth state")
+
+		return "", err
+	}
+
+	logger.Debug("OAuth flow started")
+
+	return provider.AuthCodeUrl(stateKey, s.redirectUrl(providerName)), nil
+}
+
+func (s *serviceImpl) CompleteOAuth(ctx context.Context, providerName string, code string, stateKey string) (OAuthResult, error) {
+	logger := log.FromContext(ctx).WithField("provider", providerName)
+
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return OAuthResult{}, ErrUnknownProvider
+	}
+
+	// States can only be used once, so get and delete it atomically
+	stateJson, err := s.Kv.Take(ctx, oauthStateRedisKey(stateKey))
+	if err != nil {
+		if errors.Is(err, kv.ErrNotFound) {
+			return OAuthResult{}, ErrInvalidOAuthState
+		}
+
+		logger.WithError(err).Error("Failed to get oauth state")
+
+		return OAuthResult{}, err
+	}
+
+	state := oauthState{}
+	err = json.Unmarshal([]byte(stateJson), &state)
+	if err != nil || state.Provider != providerName {
+		return OAuthResult{}, ErrInvalidOAuthState
+	}
+
+	logger = logger.WithField("mode", state.Mode)
+
+	account, err := provider.FetchAccount(ctx, code, s.redirectUrl(providerName))
+	if err != nil {
+		logger.WithError(err).Warn("Failed to fetch account from auth provider")
+
+		return OAuthResult{}, err
+	}
+
+	identity := Identity{}
+	result := s.Db.WithContext(ctx).
+		Where("provider = ? AND subject = ?", providerName, account.Subject).
+		First(&identity)
+
+	identityExists := true
+	if result.Error != nil {
+		if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			logger.WithError(result.Error).Error("Failed to query for identity")
+
+			return OAuthResult{}, result.Error
+		}
+
+		identityExists = false
+	}
+
+	switch state.Mode {
+	case ModeLogin:
+		if !identityExists {
+			return OAuthResult{}, ErrIdentityNotLinked
+		}
+
+		user, err := s.UsersService.GetUser(ctx, identity.UserId)
+		if err != nil {
+			return OAuthResult{}, err
+		}
+
+		// Guards apply to every login, e.g. blocklisted users can't log in
+		// through a provider either
+		sessionToken, err := s.AuthService.LogIn(ctx, user)
+		if err != nil {
+			return OAuthResult{}, err
+		}
+
+		logger.WithField("userId", identity.UserId).Debug("User logged in through oauth")
+
+		return OAuthResult{Mode: ModeLogin, SessionToken: sessionToken}, nil
+
+	case ModeLink:
+		if identityExists {
+			return OAuthResult{}, ErrIdentityAlreadyLinked
+		}
+
+		// Users have at most one identity per provider (see the
+		// idx_identities_user_provider index), which Unlink removes
+		var linked int64
+		result = s.Db.WithContext(ctx).
+			Model(&Identity{}).
+			Where("user_id = ? AND provider = ?", state.UserId, providerName).
+			Count(&linked)
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to query for identity")
+
+			return OAuthResult{}, result.Error
+		}
+
+		if linked > 0 {
+			return OAuthResult{}, ErrProviderAlreadyLinked
+		}
+
+		identity = Identity{
+			UserId:   state.UserId,
+			Provider: providerName,
+			Subject:  account.Subject,
+			Email:    account.Email,
+		}
+
+		result = s.Db.WithContext(ctx).Create(&identity)
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to link identity")
+
+			return OAuthResult{}, result.Error
+		}
+
+		logger.WithField("userId", state.UserId).Info("Identity linked")
+
+		return OAuthResult{Mode: ModeLink}, nil
+
+	case ModeReauth:
+		if !identityExists || identity.UserId != state.UserId {
+			return OAuthResult{}, ErrIdentityNotLinked
+		}
+
+		err = s.markReauthenticated(ctx, state.SessionToken)
+		if err != nil {
+			return OAuthResult{}, err
+		}
+
+		return OAuthResult{Mode: ModeReauth}, nil
+	}
+
+	return OAuthResult{}, ErrInvalidOAuthState
+}
+
+func (s *serviceImpl) Unlink(ctx context.Context, session auth.Session, provider string) (string, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"userId":   session.UserId(),
+		"provider": provider,
+	})
+
+	err := s.checkReauthenticated(ctx, session)
+	if err != nil {
+		return "", err
+	}
+
+	identities, err := s.ListIdentities(ctx, session.UserId())
+	if err != nil {
+		return "", err
+	}
+
+	// The methods the user would have left
+	remaining := 0
+	for _, identity := range identities {
+		if identity.Provider != provider {
+			remaining++
+		}
+	}
+
+	if remaining == len(identities) {
+		return "", ErrIdentityNotLinked
+	}
+
+	if remaining < 1 {
+		logger.Debug("Refusing to unlink the user's last authentication method")
+
+		return "", ErrLastAuthMethod
+	}
+
+	if provider == PasswordProvider {
+		err = s.UsersService.RemovePassword(ctx, session.UserId())
+		if err != nil {
+			return "", err
+		}
+	} else {
+		result := s.Db.WithContext(ctx).
+			Unscoped().
+			Where("user_id = ? AND provider = ?", session.UserId(), provider).
+			Delete(&Identity{})
+
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to unlink identity")
+
+			return "", result.Error
+		}
+	}
+
+	logger.Info("Identity unlinked")
+
+	// Sessions logged in with the unlinked method end, impersonation
+	// sessions included
+	if _, impersonated := session.ImpersonatorId(); impersonated {
+		return "", s.AuthService.BumpSessionEpoch(ctx, session.UserId())
+	}
+
+	return s.AuthService.ReissueSession(ctx, session.UserId())
+}
+
+func (s *serviceImpl) Reauthenticate(ctx context.Context, session auth.Session, password string) error {
+	user, err := s.UsersService.GetUser(ctx, session.UserId())
+	if err != nil {
+		return err
+	}
+
+	if user.PasswordHash == "" {
+		return auth.ErrWrongPassword
+	}
+
+	match, err := user.ComparePassword(password)
+	if err != nil {
+		return err
+	}
+
+	if !match {
+		return auth.ErrWrongPassword
+	}
+
+	return s.markReauthenticated(ctx, session.Token())
+}
+
+func (s *serviceImpl) markReauthenticated(ctx context.Context, sessionToken string) error {
+	err := s.Kv.Set(ctx, reauthRedisKey(sessionToken), "1", reauthDuration)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to store re-authentication")
+
+		return err
+	}
+
+	return nil
+}
+
+// Returns ErrReauthRequired if the session was not re-authenticated recently.
+func (s *serviceImpl) checkReauthenticated(ctx context.Context, session auth.Session) error {
+	exists, err := s.Kv.Exists(ctx, reauthRedisKey(session.Token()))
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to check for re-authentication")
+
+		return err
+	}
+
+	if !exists {
+		return ErrReauthRequired
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) redirectUrl(provider string) string {
+	return fmt.Sprintf("%s/oauth/%s/callback", s.FrontendUrl, provider)
+}
+
+func generateState() (string, error) {
+	bytes := make([]byte, 16)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// Maps an oauth state to the data of the flow it belongs to.
+func oauthStateRedisKey(state string) string {
+	return fmt.Sprintf("oauth.state:%s", state)
+}
+
+// Exists while a session is re-authenticated.
+func reauthRedisKey(sessionToken string) string {
+	return fmt.Sprintf("session:%s:reauth", sessionToken)
+}
@@ -0,0 +1,32 @@
+package identities
+
+import "time"
+
+type IdentityDto struct {
+	// "password", "github" or "google".
+	Provider  string     `json:"provider"`
+	Email     string     `json:"email"`
+	CreatedAt *time.Time `json:"createdAt"`
+}
+
+type ReauthDto struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type StartOAuthDto struct {
+	Mode Mode `json:"mode" validate:"required,oneof=login link reauth"`
+}
+
+type OAuthUrlDto struct {
+	// The url the user has to be redirected to.
+	Url string `json:"url"`
+}
+
+type CompleteOAuthDto struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+}
+
+type OAuthResultDto struct {
+	Mode Mode `json:"mode"`
+}
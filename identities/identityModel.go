@@ -0,0 +1,17 @@
+package identities
+
+import "gorm.io/gorm"
+
+// An Identity links an account of an external auth provider (e.g. GitHub)
+// to a user. Password authentication is not stored as an identity, it's
+// determined by whether the user has a password hash.
+type Identity struct {
+	gorm.Model
+
+	UserId   uint
+	Provider string
+
+	// The id of the account in the provider.
+	Subject string
+	Email   string
+}
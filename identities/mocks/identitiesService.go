@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: identitiesService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/open-collaboration/server/auth"
+	identities "github.com/open-collaboration/server/identities"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ListIdentities mocks base method
+func (m *MockService) ListIdentities(ctx context.Context, userId uint) ([]identities.IdentityDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIdentities", ctx, userId)
+	ret0, _ := ret[0].([]identities.IdentityDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIdentities indicates an expected call of ListIdentities
+func (mr *MockServiceMockRecorder) ListIdentities(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIdentities", reflect.TypeOf((*MockService)(nil).ListIdentities), ctx, userId)
+}
+
+// StartOAuth mocks base method
+func (m *MockService) StartOAuth(ctx context.Context, provider string, mode identities.Mode, session *auth.Session) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartOAuth", ctx, provider, mode, session)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartOAuth indicates an expected call of StartOAuth
+func (mr *MockServiceMockRecorder) StartOAuth(ctx, provider, mode, session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartOAuth", reflect.TypeOf((*MockService)(nil).StartOAuth), ctx, provider, mode, session)
+}
+
+// CompleteOAuth mocks base method
+func (m *MockService) CompleteOAuth(ctx context.Context, provider, code, state string) (identities.OAuthResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteOAuth", ctx, provider, code, state)
+	ret0, _ := ret[0].(identities.OAuthResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteOAuth indicates an expected call of CompleteOAuth
+func (mr *MockServiceMockRecorder) CompleteOAuth(ctx, provider, code, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteOAuth", reflect.TypeOf((*MockService)(nil).CompleteOAuth), ctx, provider, code, state)
+}
+
+// Unlink mocks base method
+func (m *MockService) Unlink(ctx context.Context, session auth.Session, provider string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlink", ctx, session, provider)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unlink indicates an expected call of Unlink
+func (mr *MockServiceMockRecorder) Unlink(ctx, session, provider interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlink", reflect.TypeOf((*MockService)(nil).Unlink), ctx, session, provider)
+}
+
+// Reauthenticate mocks base method
+func (m *MockService) Reauthenticate(ctx context.Context, session auth.Session, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reauthenticate", ctx, session, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reauthenticate indicates an expected call of Reauthenticate
+func (mr *MockServiceMockRecorder) Reauthenticate(ctx, session, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reauthenticate", reflect.TypeOf((*MockService)(nil).Reauthenticate), ctx, session, password)
+}
@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: oauthProviders.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	identities "github.com/open-collaboration/server/identities"
+	reflect "reflect"
+)
+
+// MockProvider is a mock of Provider interface
+type MockProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockProviderMockRecorder
+}
+
+// MockProviderMockRecorder is the mock recorder for MockProvider
+type MockProviderMockRecorder struct {
+	mock *MockProvider
+}
+
+// NewMockProvider creates a new mock instance
+func NewMockProvider(ctrl *gomock.Controller) *MockProvider {
+	mock := &MockProvider{ctrl: ctrl}
+	mock.recorder = &MockProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProvider) EXPECT() *MockProviderMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method
+func (m *MockProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name
+func (mr *MockProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockProvider)(nil).Name))
+}
+
+// AuthCodeUrl mocks base method
+func (m *MockProvider) AuthCodeUrl(state, redirectUrl string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeUrl", state, redirectUrl)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// AuthCodeUrl indicates an expected call of AuthCodeUrl
+func (mr *MockProviderMockRecorder) AuthCodeUrl(state, redirectUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeUrl", reflect.TypeOf((*MockProvider)(nil).AuthCodeUrl), state, redirectUrl)
+}
+
+// FetchAccount mocks base method
+func (m *MockProvider) FetchAccount(ctx context.Context, code, redirectUrl string) (identities.ProviderAccount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAccount", ctx, code, redirectUrl)
+	ret0, _ := ret[0].(identities.ProviderAccount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAccount indicates an expected call of FetchAccount
+func (mr *MockProviderMockRecorder) FetchAccount(ctx, code, redirectUrl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAccount", reflect.TypeOf((*MockProvider)(nil).FetchAccount), ctx, code, redirectUrl)
+}
@@ -0,0 +1,230 @@
+package identities
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/open-collaboration/server/breaker"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The provider refused to exchange the authorization code, e.g. because it
+// expired or was already used.
+var ErrAuthCodeRejected = errors.New("authorization code rejected")
+
+// Data about an account, fetched from an auth provider.
+type ProviderAccount struct {
+	Subject string
+	Email   string
+}
+
+// An OAuth 2 auth provider.
+type Provider interface {
+	// The provider's name, e.g. "github".
+	Name() string
+
+	// The url the user has to be redirected to in order to authorize us.
+	AuthCodeUrl(state string, redirectUrl string) string
+
+	// Exchange an authorization code for an access token and use it
+	// to fetch the user's account.
+	FetchAccount(ctx context.Context, code string, redirectUrl string) (ProviderAccount, error)
+}
+
+type githubProvider struct {
+	ClientId     string
+	ClientSecret string
+}
+
+func NewGithubProvider(clientId string, clientSecret string) Provider {
+	return &githubProvider{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+	}
+}
+
+func (p *githubProvider) Name() string {
+	return "github"
+}
+
+func (p *githubProvider) AuthCodeUrl(state string, redirectUrl string) string {
+	return "https://github.com/login/oauth/authorize?" + url.Values{
+		"client_id":    {p.ClientId},
+		"redirect_uri": {redirectUrl},
+		"scope":        {"read:user user:email"},
+		"state":        {state},
+	}.Encode()
+}
+
+func (p *githubProvider) FetchAccount(ctx context.Context, code string, redirectUrl string) (ProviderAccount, error) {
+	accessToken, err := exchangeCode(ctx, "https://github.com/login/oauth/access_token", url.Values{
+		"client_id":     {p.ClientId},
+		"client_secret": {p.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectUrl},
+	})
+	if err != nil {
+		return ProviderAccount{}, err
+	}
+
+	user := struct {
+		Id    int64  `json:"id"`
+		Email string `json:"email"`
+	}{}
+
+	err = getJson(ctx, "https://api.github.com/user", accessToken, &user)
+	if err != nil {
+		return ProviderAccount{}, err
+	}
+
+	return ProviderAccount{
+		Subject: strconv.FormatInt(user.Id, 10),
+		Email:   user.Email,
+	}, nil
+}
+
+type googleProvider struct {
+	ClientId     string
+	ClientSecret string
+}
+
+func NewGoogleProvider(clientId string, clientSecret string) Provider {
+	return &googleProvider{
+		ClientId:     clientId,
+		ClientSecret: clientSecret,
+	}
+}
+
+func (p *googleProvider) Name() string {
+	return "google"
+}
+
+func (p *googleProvider) AuthCodeUrl(state string, redirectUrl string) string {
+	return "https://accounts.google.com/o/oauth2/v2/auth?" + url.Values{
+		"client_id":     {p.ClientId},
+		"redirect_uri":  {redirectUrl},
+		"response_type": {"code"},
+		"scope":         {"openid email"},
+		"state":         {state},
+	}.Encode()
+}
+
+func (p *googleProvider) FetchAccount(ctx context.Context, code string, redirectUrl string) (ProviderAccount, error) {
+	accessToken, err := exchangeCode(ctx, "https://oauth2.googleapis.com/token", url.Values{
+		"client_id":     {p.ClientId},
+		"client_secret": {p.ClientSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectUrl},
+		"grant_type":    {"authorization_code"},
+	})
+	if err != nil {
+		return ProviderAccount{}, err
+	}
+
+	user := struct {
+		Sub   string `json:"sub"`
+		Email string `json:"email"`
+	}{}
+
+	err = getJson(ctx, "https://openidconnect.googleapis.com/v1/userinfo", accessToken, &user)
+	if err != nil {
+		return ProviderAccount{}, err
+	}
+
+	return ProviderAccount{
+		Subject: user.Sub,
+		Email:   user.Email,
+	}, nil
+}
+
+// Exchange an authorization code for an access token.
+func exchangeCode(ctx context.Context, tokenUrl string, params url.Values) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", tokenUrl, strings.NewReader(params.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}{}
+
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil {
+		return "", err
+	}
+
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("%w: %s", ErrAuthCodeRejected, token.Error)
+	}
+
+	return token.AccessToken, nil
+}
+
+// Make an authenticated GET request and unmarshal the JSON response into `dst`.
+func getJson(ctx context.Context, url string, accessToken string, dst interface{}) error {
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("request to %s failed with status %d", url, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(dst)
+}
+
+// Guards a provider's FetchAccount with a circuit breaker. A rejected
+// authorization code is the user's fault and doesn't count as a failure.
+type breakerProvider struct {
+	Provider
+	Breaker *breaker.Breaker
+}
+
+func NewBreakerProvider(provider Provider, breaker *breaker.Breaker) Provider {
+	return &breakerProvider{
+		Provider: provider,
+		Breaker:  breaker,
+	}
+}
+
+func (p *breakerProvider) FetchAccount(ctx context.Context, code string, redirectUrl string) (ProviderAccount, error) {
+	err := p.Breaker.Allow()
+	if err != nil {
+		return ProviderAccount{}, err
+	}
+
+	account, err := p.Provider.FetchAccount(ctx, code, redirectUrl)
+	if err != nil && !errors.Is(err, ErrAuthCodeRejected) && !errors.Is(err, context.Canceled) {
+		p.Breaker.Failure()
+	} else {
+		p.Breaker.Success()
+	}
+
+	return account, err
+}
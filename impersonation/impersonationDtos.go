@@ -0,0 +1,15 @@
+package impersonation
+
+import "time"
+
+type StartImpersonationDto struct {
+	UserId uint `json:"userId" validate:"required"`
+
+	// Why the user is being impersonated, e.g. a support ticket.
+	Reason string `json:"reason" validate:"required,min=4,max=500"`
+}
+
+type ImpersonationDto struct {
+	SessionToken string    `json:"sessionToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,49 @@
+package impersonation
+
+import (
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"net/http"
+	"strconv"
+)
+
+// Flags requests made with impersonation sessions. The X-Impersonated-By
+// response header is set to the impersonator's id so that clients can
+// show a banner, and requests that may change data (i.e. anything but
+// GET, HEAD and OPTIONS) are recorded in the audit log.
+//
+// Must be used after auth.SessionMiddleware.
+func ImpersonationMiddleware(impersonationService Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := auth.CheckSession(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			impersonatorId, impersonated := session.ImpersonatorId()
+			if !impersonated {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			logger := log.FromContext(r.Context()).WithField("impersonatorId", impersonatorId)
+			ctx := log.NewContext(r.Context(), logger)
+
+			w.Header().Set("X-Impersonated-By", strconv.FormatUint(uint64(impersonatorId), 10))
+
+			if r.Method != "GET" && r.Method != "HEAD" && r.Method != "OPTIONS" {
+				err = impersonationService.RecordImpersonatedRequest(ctx, session, r.Method, r.URL.Path)
+				if err != nil {
+					// Requests that can't be audited are not allowed
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package impersonation
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Impersonate a user
+// @Description Creates a time limited session for the user and sets it as the session cookie. Every request
+// @Description made with the session has the `X-Impersonated-By` response header and is recorded in the audit log.
+// @Tags admin
+// @Router /admin/impersonations [post]
+// @Param impersonation body impersonation.StartImpersonationDto true "The user to impersonate and why"
+// @Success 201 {object} impersonation.ImpersonationDto
+// @Header 201 {string} Set-Cookie "Impersonation session token. E.g. sessionToken=72f34c69-6eb0-47cf-83ed-c2b5ad3989df"
+// @Failure 403
+func RouteStartImpersonation(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	impersonationService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := StartImpersonationDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	impersonation, err := impersonationService.StartImpersonation(request.Context(), session, dto)
+	if err != nil {
+		return err
+	}
+
+	auth.SetSessionCookie(writer, impersonation.SessionToken)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, impersonation)
+}
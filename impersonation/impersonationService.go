@@ -0,0 +1,110 @@
+package impersonation
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"time"
+)
+
+var ErrCannotImpersonate = errors.New("user cannot be impersonated")
+
+type Service interface {
+	// Start impersonating a user on behalf of an admin. Returns a session token
+	// for the impersonated user that is valid for a limited time.
+	// Returns ErrCannotImpersonate if the impersonator is already impersonating
+	// someone or if the target is a moderator or an admin.
+	StartImpersonation(ctx context.Context, impersonator auth.Session, dto StartImpersonationDto) (ImpersonationDto, error)
+
+	// Record a request made with an impersonation session in the audit log.
+	RecordImpersonatedRequest(ctx context.Context, session auth.Session, method string, path string) error
+}
+
+type serviceImpl struct {
+	AuthService  auth.Service
+	UsersService users.Service
+	AuditService audit.Service
+	Duration     time.Duration
+}
+
+// Create an impersonation service. duration is how long
+// impersonation sessions last.
+func NewService(
+	authService auth.Service,
+	usersService users.Service,
+	auditService audit.Service,
+	duration time.Duration,
+) Service {
+	return &serviceImpl{
+		AuthService:  authService,
+		UsersService: usersService,
+		AuditService: auditService,
+		Duration:     duration,
+	}
+}
+
+func (s *serviceImpl) StartImpersonation(
+	ctx context.Context,
+	impersonator auth.Session,
+	dto StartImpersonationDto,
+) (ImpersonationDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"impersonatorId": impersonator.UserId(),
+		"userId":         dto.UserId,
+	})
+
+	if _, impersonating := impersonator.ImpersonatorId(); impersonating {
+		logger.Warn("Refusing nested impersonation")
+
+		return ImpersonationDto{}, ErrCannotImpersonate
+	}
+
+	target, err := s.UsersService.GetUser(ctx, dto.UserId)
+	if err != nil {
+		return ImpersonationDto{}, err
+	}
+
+	// Impersonating staff would allow an admin to act with another
+	// staff member's permissions, so it's not allowed.
+	if target.Role.Includes(users.RoleModerator) {
+		logger.Warn("Refusing to impersonate a staff member")
+
+		return ImpersonationDto{}, ErrCannotImpersonate
+	}
+
+	// Record before creating the session, we don't want
+	// impersonation sessions that aren't in the audit log.
+	err = s.AuditService.Record(ctx, impersonator.UserId(), "impersonation.start", "user", target.ID, map[string]interface{}{
+		"reason":          dto.Reason,
+		"durationSeconds": s.Duration.Seconds(),
+	})
+	if err != nil {
+		return ImpersonationDto{}, err
+	}
+
+	sessionToken, err := s.AuthService.CreateImpersonationSession(ctx, impersonator.UserId(), target.ID, s.Duration)
+	if err != nil {
+		return ImpersonationDto{}, err
+	}
+
+	logger.Info("Impersonation started")
+
+	return ImpersonationDto{
+		SessionToken: sessionToken,
+		ExpiresAt:    time.Now().Add(s.Duration),
+	}, nil
+}
+
+func (s *serviceImpl) RecordImpersonatedRequest(ctx context.Context, session auth.Session, method string, path string) error {
+	impersonatorId, _ := session.ImpersonatorId()
+
+	return s.AuditService.Record(ctx, impersonatorId, "impersonation.request", "user", session.UserId(), map[string]interface{}{
+		"method": method,
+		"path":   path,
+	})
+}
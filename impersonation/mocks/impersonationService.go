@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: impersonationService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/open-collaboration/server/auth"
+	impersonation "github.com/open-collaboration/server/impersonation"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// StartImpersonation mocks base method
+func (m *MockService) StartImpersonation(ctx context.Context, impersonator auth.Session, dto impersonation.StartImpersonationDto) (impersonation.ImpersonationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartImpersonation", ctx, impersonator, dto)
+	ret0, _ := ret[0].(impersonation.ImpersonationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartImpersonation indicates an expected call of StartImpersonation
+func (mr *MockServiceMockRecorder) StartImpersonation(ctx, impersonator, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartImpersonation", reflect.TypeOf((*MockService)(nil).StartImpersonation), ctx, impersonator, dto)
+}
+
+// RecordImpersonatedRequest mocks base method
+func (m *MockService) RecordImpersonatedRequest(ctx context.Context, session auth.Session, method, path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordImpersonatedRequest", ctx, session, method, path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordImpersonatedRequest indicates an expected call of RecordImpersonatedRequest
+func (mr *MockServiceMockRecorder) RecordImpersonatedRequest(ctx, session, method, path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordImpersonatedRequest", reflect.TypeOf((*MockService)(nil).RecordImpersonatedRequest), ctx, session, method, path)
+}
@@ -0,0 +1,46 @@
+package integrations
+
+import "time"
+
+// Whether each event is posted.
+type EventsDto struct {
+	NewApplicant bool `json:"newApplicant"`
+	NewComment   bool `json:"newComment"`
+	Announcement bool `json:"announcement"`
+}
+
+type NewIntegrationDto struct {
+	Provider Provider `json:"provider" validate:"required,oneof=slack discord"`
+
+	// A Slack incoming webhook (https://hooks.slack.com/...) or a Discord
+	// channel webhook (https://discord.com/api/webhooks/...).
+	WebhookUrl string `json:"webhookUrl" validate:"required,url,max=500"`
+
+	Events EventsDto `json:"events"`
+}
+
+type NewAnnouncementDto struct {
+	Title string `json:"title" validate:"required,min=2,max=100"`
+	Text  string `json:"text" validate:"required,max=2000"`
+}
+
+// Fields missing from the update are left unchanged.
+type UpdateIntegrationDto struct {
+	Events *EventsDto `json:"events"`
+
+	// Enabling an integration resets its failures.
+	Enabled *bool `json:"enabled"`
+}
+
+// The webhook URL isn't included since anyone who has it can post to the
+// chat.
+type IntegrationDto struct {
+	Id            uint       `json:"id"`
+	Provider      Provider   `json:"provider"`
+	Events        EventsDto  `json:"events"`
+	Enabled       bool       `json:"enabled"`
+	FailureCount  int        `json:"failureCount"`
+	LastError     string     `json:"lastError"`
+	LastFailureAt *time.Time `json:"lastFailureAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,75 @@
+package integrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// The chat service a webhook posts to.
+type Provider string
+
+const (
+	// Slack incoming webhooks.
+	ProviderSlack Provider = "slack"
+
+	// Discord channel webhooks.
+	ProviderDiscord Provider = "discord"
+)
+
+// Every provider, e.g. to create their circuit breakers.
+var Providers = []Provider{ProviderSlack, ProviderDiscord}
+
+// A project event that can be posted to a project's chat.
+type Event string
+
+const (
+	// Someone applied to the project.
+	EventNewApplicant Event = "new-applicant"
+
+	// Someone commented on the project.
+	EventNewComment Event = "new-comment"
+
+	// The project published an announcement, see RoutePostAnnouncement.
+	EventAnnouncement Event = "announcement"
+)
+
+// A webhook of a project's team chat that project events are posted to.
+type Integration struct {
+	gorm.Model
+
+	ProjectId  uint
+	Provider   Provider
+	WebhookUrl string
+
+	// Whether each event is posted
+	NewApplicant bool
+	NewComment   bool
+	Announcement bool
+
+	// Integrations are disabled after too many consecutive failed posts, until
+	// the project's owner enables them again.
+	Enabled bool
+
+	// Consecutive failed posts and the last failure, reported to the owner.
+	FailureCount  int
+	LastError     string
+	LastFailureAt *time.Time
+}
+
+func (Integration) TableName() string {
+	return "project_integrations"
+}
+
+// Whether the integration posts an event.
+func (i Integration) posts(event Event) bool {
+	switch event {
+	case EventNewApplicant:
+		return i.NewApplicant
+	case EventNewComment:
+		return i.NewComment
+	case EventAnnouncement:
+		return i.Announcement
+	default:
+		return false
+	}
+}
@@ -0,0 +1,234 @@
+package integrations
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List a project's chat integrations
+// @Description Only the project's owner and members with the manage-integrations permission can manage its
+// @Description integrations.
+// @Tags integrations
+// @Router /projects/{projectId}/integrations [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {array} integrations.IntegrationDto
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteListIntegrations(
+	writer http.ResponseWriter,
+	request *http.Request,
+	integrationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageIntegrations)
+	if err != nil {
+		return err
+	}
+
+	integrations, err := integrationsService.ListIntegrations(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, integrations)
+}
+
+// @Summary Connect a Slack or Discord webhook to a project
+// @Description Only the project's owner and members with the manage-integrations permission can manage its
+// @Description integrations.
+// @Tags integrations
+// @Router /projects/{projectId}/integrations [post]
+// @Param projectId path int true "The project ID"
+// @Param integration body integrations.NewIntegrationDto true "The webhook"
+// @Success 201 {object} integrations.IntegrationDto
+// @Failure 400 "The URL isn't a webhook of the provider"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteCreateIntegration(
+	writer http.ResponseWriter,
+	request *http.Request,
+	integrationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageIntegrations)
+	if err != nil {
+		return err
+	}
+
+	dto := NewIntegrationDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	integration, err := integrationsService.CreateIntegration(request.Context(), projectId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, integration)
+}
+
+// @Summary Update a project's chat integration
+// @Description Change the events posted to the webhook, or enable or disable it. Enabling an integration resets its failures.
+// @Tags integrations
+// @Router /projects/{projectId}/integrations/{integrationId} [put]
+// @Param projectId path int true "The project ID"
+// @Param integrationId path int true "The integration ID"
+// @Param update body integrations.UpdateIntegrationDto true "The update"
+// @Success 200 {object} integrations.IntegrationDto
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteUpdateIntegration(
+	writer http.ResponseWriter,
+	request *http.Request,
+	integrationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageIntegrations)
+	if err != nil {
+		return err
+	}
+
+	integrationId, err := utils.UintFromVars(request, "integrationId")
+	if err != nil {
+		return err
+	}
+
+	dto := UpdateIntegrationDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	integration, err := integrationsService.UpdateIntegration(request.Context(), projectId, integrationId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, integration)
+}
+
+// @Summary Disconnect a project's chat integration
+// @Tags integrations
+// @Router /projects/{projectId}/integrations/{integrationId} [delete]
+// @Param projectId path int true "The project ID"
+// @Param integrationId path int true "The integration ID"
+// @Success 204
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteDeleteIntegration(
+	writer http.ResponseWriter,
+	request *http.Request,
+	integrationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageIntegrations)
+	if err != nil {
+		return err
+	}
+
+	integrationId, err := utils.UintFromVars(request, "integrationId")
+	if err != nil {
+		return err
+	}
+
+	err = integrationsService.DeleteIntegration(request.Context(), projectId, integrationId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Post a test message to a project's chat integration
+// @Tags integrations
+// @Router /projects/{projectId}/integrations/{integrationId}/test [post]
+// @Param projectId path int true "The project ID"
+// @Param integrationId path int true "The integration ID"
+// @Success 204
+// @Failure 400 "The webhook rejected the message or couldn't be reached"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 503 "The provider is failing, the test wasn't posted"
+func RouteTestIntegration(
+	writer http.ResponseWriter,
+	request *http.Request,
+	integrationsService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionManageIntegrations)
+	if err != nil {
+		return err
+	}
+
+	integrationId, err := utils.UintFromVars(request, "integrationId")
+	if err != nil {
+		return err
+	}
+
+	err = integrationsService.TestIntegration(request.Context(), projectId, integrationId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Post an announcement of a project
+// @Description Posts the announcement to the project's enabled integrations that post announcements. Only the
+// @Description project's owner and members with the post-announcements permission can.
+// @Tags integrations
+// @Router /projects/{projectId}/announcements [post]
+// @Param projectId path int true "The project ID"
+// @Param announcement body integrations.NewAnnouncementDto true "The announcement"
+// @Success 204
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RoutePostAnnouncement(
+	writer http.ResponseWriter,
+	request *http.Request,
+	integrationsService Service,
+	projectsService projects.Service,
+	accountStatusProvider auth.AccountStatusProvider,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionPostAnnouncements)
+	if err != nil {
+		return err
+	}
+
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
+	if err != nil {
+		return err
+	}
+
+	dto := NewAnnouncementDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = integrationsService.PostAnnouncement(request.Context(), projectId, dto, accountStatus.ShadowHidden)
+	if err != nil {
+		return err
+	}
+
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
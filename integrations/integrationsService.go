@@ -0,0 +1,464 @@
+package integrations
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/applications"
+	"github.com/open-collaboration/server/breaker"
+	"github.com/open-collaboration/server/encryption"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrIntegrationNotFound = errors.New("integration not found")
+var ErrWebhookFailed = errors.New("webhook post failed")
+
+// Integrations are disabled after this many consecutive failed posts.
+const maxFailures = 5
+
+// Maximum length of user written text quoted in messages.
+const maxQuoteLength = 500
+
+type Service interface {
+	// List a project's integrations, oldest to newest.
+	ListIntegrations(ctx context.Context, projectId uint) ([]IntegrationDto, error)
+
+	// Connect a webhook to a project.
+	// Returns ErrInvalidWebhookUrl if the URL isn't a webhook of the provider.
+	CreateIntegration(ctx context.Context, projectId uint, dto NewIntegrationDto) (IntegrationDto, error)
+
+	// Change which events an integration posts, or enable or disable it.
+	// Returns ErrIntegrationNotFound if the project doesn't have the integration.
+	UpdateIntegration(ctx context.Context, projectId uint, integrationId uint, dto UpdateIntegrationDto) (IntegrationDto, error)
+
+	// Returns ErrIntegrationNotFound if the project doesn't have the integration.
+	DeleteIntegration(ctx context.Context, projectId uint, integrationId uint) error
+
+	// Post a test message to an integration's webhook, even if it's disabled.
+	// Failed tests don't count towards disabling the integration.
+	// Returns ErrIntegrationNotFound if the project doesn't have the integration,
+	// ErrWebhookFailed if the post failed and breaker.ErrOpen if the provider's
+	// circuit breaker is open.
+	TestIntegration(ctx context.Context, projectId uint, integrationId uint) error
+
+	// Post an event to the project's enabled integrations that post it, in the
+	// background. Failed posts are recorded on the integration, which is
+	// disabled after too many consecutive failures and its project's owner
+	// notified.
+	Post(ctx context.Context, projectId uint, event Event, message Message) error
+
+	// Post an announcement of the project to its integrations that post
+	// announcements, like Post. Announcements of shadow hidden authors are
+	// accepted but not posted.
+	// Returns projects.ErrProjectNotFound if the project doesn't exist.
+	PostAnnouncement(ctx context.Context, projectId uint, dto NewAnnouncementDto, shadowHidden bool) error
+
+	// Post the application to the project's chat. Implements
+	// applications.ApplicationListener.
+	ApplicationCreated(ctx context.Context, project projects.ProjectDto, application applications.ApplicationDto)
+}
+
+type serviceImpl struct {
+	Db                   *gorm.DB
+	ProjectsService      projects.Service
+	UsersService         users.Service
+	NotificationsService notifications.Service
+	FrontendUrl          string
+
+	// Webhook URLs are credentials, they're stored encrypted
+	EncryptionService encryption.Service
+
+	// Guard the posts to each provider's webhooks
+	Breakers map[Provider]*breaker.Breaker
+}
+
+func NewService(
+	db *gorm.DB,
+	projectsService projects.Service,
+	usersService users.Service,
+	notificationsService notifications.Service,
+	frontendUrl string,
+	encryptionService encryption.Service,
+	breakers map[Provider]*breaker.Breaker,
+) Service {
+	return &serviceImpl{
+		Db:                   db,
+		ProjectsService:      projectsService,
+		UsersService:         usersService,
+		NotificationsService: notificationsService,
+		FrontendUrl:          frontendUrl,
+		EncryptionService:    encryptionService,
+		Breakers:             breakers,
+	}
+}
+
+func (s *serviceImpl) ListIntegrations(ctx context.Context, projectId uint) ([]IntegrationDto, error) {
+	var integrations []Integration
+	result := s.Db.WithContext(ctx).
+		Where("project_id = ?", projectId).
+		Order("created_at asc").
+		Find(&integrations)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list integrations")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]IntegrationDto, len(integrations))
+	for i, integration := range integrations {
+		dtos[i] = integrationToDto(integration)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) CreateIntegration(ctx context.Context, projectId uint, dto NewIntegrationDto) (IntegrationDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return IntegrationDto{}, err
+	}
+
+	err = checkWebhookUrl(dto.Provider, dto.WebhookUrl)
+	if err != nil {
+		return IntegrationDto{}, err
+	}
+
+	webhookUrl, err := s.EncryptionService.Encrypt(dto.WebhookUrl)
+	if err != nil {
+		return IntegrationDto{}, err
+	}
+
+	integration := Integration{
+		ProjectId:    projectId,
+		Provider:     dto.Provider,
+		WebhookUrl:   webhookUrl,
+		NewApplicant: dto.Events.NewApplicant,
+		NewComment:   dto.Events.NewComment,
+		Announcement: dto.Events.Announcement,
+		Enabled:      true,
+	}
+
+	result := s.Db.WithContext(ctx).Create(&integration)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to create integration")
+
+		return IntegrationDto{}, result.Error
+	}
+
+	return integrationToDto(integration), nil
+}
+
+func (s *serviceImpl) UpdateIntegration(
+	ctx context.Context,
+	projectId uint,
+	integrationId uint,
+	dto UpdateIntegrationDto,
+) (IntegrationDto, error) {
+	integration, err := s.getIntegration(ctx, projectId, integrationId)
+	if err != nil {
+		return IntegrationDto{}, err
+	}
+
+	if dto.Events != nil {
+		integration.NewApplicant = dto.Events.NewApplicant
+		integration.NewComment = dto.Events.NewComment
+		integration.Announcement = dto.Events.Announcement
+	}
+
+	if dto.Enabled != nil {
+		if *dto.Enabled && !integration.Enabled {
+			integration.FailureCount = 0
+			integration.LastError = ""
+			integration.LastFailureAt = nil
+		}
+
+		integration.Enabled = *dto.Enabled
+	}
+
+	result := s.Db.WithContext(ctx).Save(&integration)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to update integration")
+
+		return IntegrationDto{}, result.Error
+	}
+
+	return integrationToDto(integration), nil
+}
+
+func (s *serviceImpl) DeleteIntegration(ctx context.Context, projectId uint, integrationId uint) error {
+	result := s.Db.WithContext(ctx).
+		Where("id = ? AND project_id = ?", integrationId, projectId).
+		Delete(&Integration{})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete integration")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrIntegrationNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) TestIntegration(ctx context.Context, projectId uint, integrationId uint) error {
+	integration, err := s.getIntegration(ctx, projectId, integrationId)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return err
+	}
+
+	webhookUrl, err := s.EncryptionService.Decrypt(integration.WebhookUrl)
+	if err != nil {
+		return err
+	}
+
+	err = s.postWebhook(ctx, integration.Provider, webhookUrl, Message{
+		Title: "Test message from " + project.Name,
+		Text:  "This channel is connected to the project.",
+		Url:   s.projectUrl(projectId),
+	})
+	if errors.Is(err, breaker.ErrOpen) {
+		return err
+	} else if err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookFailed, err)
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) Post(ctx context.Context, projectId uint, event Event, message Message) error {
+	var integrations []Integration
+	result := s.Db.WithContext(ctx).
+		Where("project_id = ? AND enabled = true", projectId).
+		Find(&integrations)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list integrations")
+
+		return result.Error
+	}
+
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId": projectId,
+		"event":     event,
+	})
+
+	for _, integration := range integrations {
+		if !integration.posts(event) {
+			continue
+		}
+
+		integration := integration
+		go func() {
+			ctx := log.NewContext(context.Background(), logger.WithField("integrationId", integration.ID))
+			s.post(ctx, integration, message)
+		}()
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) PostAnnouncement(ctx context.Context, projectId uint, dto NewAnnouncementDto, shadowHidden bool) error {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return err
+	}
+
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return err
+	}
+
+	if shadowHidden {
+		return nil
+	}
+
+	return s.Post(ctx, projectId, EventAnnouncement, Message{
+		Title: project.Name + ": " + dto.Title,
+		Text:  dto.Text,
+		Url:   s.projectUrl(projectId),
+	})
+}
+
+func (s *serviceImpl) ApplicationCreated(ctx context.Context, project projects.ProjectDto, application applications.ApplicationDto) {
+	applicant, err := s.UsersService.GetUser(ctx, application.ApplicantId)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to get applicant")
+
+		return
+	}
+
+	err = s.Post(ctx, project.Id, EventNewApplicant, Message{
+		Title: "New applicant for " + project.Name,
+		Text:  applicant.Username + " applied:\n" + truncate(application.Message, maxQuoteLength),
+		Url:   s.projectUrl(project.Id),
+	})
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to post application")
+	}
+}
+
+// Post a message to an integration and record the outcome.
+func (s *serviceImpl) post(ctx context.Context, integration Integration, message Message) {
+	logger := log.FromContext(ctx)
+
+	webhookUrl, err := s.EncryptionService.Decrypt(integration.WebhookUrl)
+	if err != nil {
+		logger.WithError(err).Error("Failed to decrypt integration webhook url")
+
+		return
+	}
+
+	err = s.postWebhook(ctx, integration.Provider, webhookUrl, message)
+	if errors.Is(err, breaker.ErrOpen) {
+		// The provider is down, not the webhook
+		logger.WithError(err).WithField("provider", integration.Provider).Warn("Skipped posting to integration")
+
+		return
+	}
+
+	if err == nil {
+		if integration.FailureCount > 0 {
+			result := s.Db.WithContext(ctx).
+				Model(&Integration{}).
+				Where("id = ?", integration.ID).
+				Update("failure_count", 0)
+
+			if result.Error != nil {
+				logger.WithError(result.Error).Error("Failed to reset integration failures")
+			}
+		}
+
+		return
+	}
+
+	logger.WithError(err).Warn("Failed to post to integration")
+
+	failureCount := gorm.Expr("failure_count + 1")
+	if errors.Is(err, errWebhookGone) {
+		// Retrying won't bring the webhook back
+		failureCount = gorm.Expr("GREATEST(failure_count + 1, ?)", maxFailures)
+	}
+
+	result := s.Db.WithContext(ctx).
+		Model(&Integration{}).
+		Where("id = ?", integration.ID).
+		Updates(map[string]interface{}{
+			"failure_count":   failureCount,
+			"last_error":      err.Error(),
+			"last_failure_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to record integration failure")
+
+		return
+	}
+
+	// Only one of concurrent failing posts disables the integration
+	result = s.Db.WithContext(ctx).
+		Model(&Integration{}).
+		Where("id = ? AND enabled = true AND failure_count >= ?", integration.ID, maxFailures).
+		Update("enabled", false)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to disable integration")
+
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		logger.Info("Integration disabled after too many failures")
+
+		s.notifyDisabled(ctx, integration, err)
+	}
+}
+
+// Let a project's owner know that one of its integrations was disabled.
+func (s *serviceImpl) notifyDisabled(ctx context.Context, integration Integration, cause error) {
+	logger := log.FromContext(ctx)
+
+	project, err := s.ProjectsService.GetProject(ctx, integration.ProjectId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the project of a disabled integration")
+
+		return
+	}
+
+	err = s.NotificationsService.Notify(ctx, project.OwnerId, notifications.NewNotificationDto{
+		Type:  notifications.TypeIntegrationDisabled,
+		Title: fmt.Sprintf("The %s integration of %s was disabled", integration.Provider, project.Name),
+		Body:  "Posting to the webhook failed too many times: " + cause.Error(),
+		Data: map[string]interface{}{
+			"projectId":     project.Id,
+			"integrationId": integration.ID,
+		},
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to notify project owner of disabled integration")
+	}
+}
+
+func (s *serviceImpl) getIntegration(ctx context.Context, projectId uint, integrationId uint) (Integration, error) {
+	var integration Integration
+	result := s.Db.WithContext(ctx).
+		Where("id = ? AND project_id = ?", integrationId, projectId).
+		First(&integration)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return Integration{}, ErrIntegrationNotFound
+	} else if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to get integration")
+
+		return Integration{}, result.Error
+	}
+
+	return integration, nil
+}
+
+func (s *serviceImpl) projectUrl(projectId uint) string {
+	return fmt.Sprintf("%s/projects/%d", s.FrontendUrl, projectId)
+}
+
+func integrationToDto(integration Integration) IntegrationDto {
+	return IntegrationDto{
+		Id:       integration.ID,
+		Provider: integration.Provider,
+		Events: EventsDto{
+			NewApplicant: integration.NewApplicant,
+			NewComment:   integration.NewComment,
+			Announcement: integration.Announcement,
+		},
+		Enabled:       integration.Enabled,
+		FailureCount:  integration.FailureCount,
+		LastError:     integration.LastError,
+		LastFailureAt: integration.LastFailureAt,
+		CreatedAt:     integration.CreatedAt,
+	}
+}
+
+// Shorten text to at most maxLength characters.
+func truncate(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+
+	return string(runes[:maxLength-1]) + "…"
+}
@@ -0,0 +1,150 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: integrationsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	applications "github.com/open-collaboration/server/applications"
+	integrations "github.com/open-collaboration/server/integrations"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// ListIntegrations mocks base method
+func (m *MockService) ListIntegrations(ctx context.Context, projectId uint) ([]integrations.IntegrationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIntegrations", ctx, projectId)
+	ret0, _ := ret[0].([]integrations.IntegrationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIntegrations indicates an expected call of ListIntegrations
+func (mr *MockServiceMockRecorder) ListIntegrations(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIntegrations", reflect.TypeOf((*MockService)(nil).ListIntegrations), ctx, projectId)
+}
+
+// CreateIntegration mocks base method
+func (m *MockService) CreateIntegration(ctx context.Context, projectId uint, dto integrations.NewIntegrationDto) (integrations.IntegrationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIntegration", ctx, projectId, dto)
+	ret0, _ := ret[0].(integrations.IntegrationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIntegration indicates an expected call of CreateIntegration
+func (mr *MockServiceMockRecorder) CreateIntegration(ctx, projectId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIntegration", reflect.TypeOf((*MockService)(nil).CreateIntegration), ctx, projectId, dto)
+}
+
+// UpdateIntegration mocks base method
+func (m *MockService) UpdateIntegration(ctx context.Context, projectId, integrationId uint, dto integrations.UpdateIntegrationDto) (integrations.IntegrationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIntegration", ctx, projectId, integrationId, dto)
+	ret0, _ := ret[0].(integrations.IntegrationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateIntegration indicates an expected call of UpdateIntegration
+func (mr *MockServiceMockRecorder) UpdateIntegration(ctx, projectId, integrationId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIntegration", reflect.TypeOf((*MockService)(nil).UpdateIntegration), ctx, projectId, integrationId, dto)
+}
+
+// DeleteIntegration mocks base method
+func (m *MockService) DeleteIntegration(ctx context.Context, projectId, integrationId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIntegration", ctx, projectId, integrationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIntegration indicates an expected call of DeleteIntegration
+func (mr *MockServiceMockRecorder) DeleteIntegration(ctx, projectId, integrationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIntegration", reflect.TypeOf((*MockService)(nil).DeleteIntegration), ctx, projectId, integrationId)
+}
+
+// TestIntegration mocks base method
+func (m *MockService) TestIntegration(ctx context.Context, projectId, integrationId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TestIntegration", ctx, projectId, integrationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TestIntegration indicates an expected call of TestIntegration
+func (mr *MockServiceMockRecorder) TestIntegration(ctx, projectId, integrationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TestIntegration", reflect.TypeOf((*MockService)(nil).TestIntegration), ctx, projectId, integrationId)
+}
+
+// Post mocks base method
+func (m *MockService) Post(ctx context.Context, projectId uint, event integrations.Event, message integrations.Message) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Post", ctx, projectId, event, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Post indicates an expected call of Post
+func (mr *MockServiceMockRecorder) Post(ctx, projectId, event, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Post", reflect.TypeOf((*MockService)(nil).Post), ctx, projectId, event, message)
+}
+
+// PostAnnouncement mocks base method
+func (m *MockService) PostAnnouncement(ctx context.Context, projectId uint, dto integrations.NewAnnouncementDto, shadowHidden bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PostAnnouncement", ctx, projectId, dto, shadowHidden)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PostAnnouncement indicates an expected call of PostAnnouncement
+func (mr *MockServiceMockRecorder) PostAnnouncement(ctx, projectId, dto, shadowHidden interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostAnnouncement", reflect.TypeOf((*MockService)(nil).PostAnnouncement), ctx, projectId, dto, shadowHidden)
+}
+
+// ApplicationCreated mocks base method
+func (m *MockService) ApplicationCreated(ctx context.Context, project projects.ProjectDto, application applications.ApplicationDto) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ApplicationCreated", ctx, project, application)
+}
+
+// ApplicationCreated indicates an expected call of ApplicationCreated
+func (mr *MockServiceMockRecorder) ApplicationCreated(ctx, project, application interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplicationCreated", reflect.TypeOf((*MockService)(nil).ApplicationCreated), ctx, project, application)
+}
@@ -0,0 +1,149 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrInvalidWebhookUrl = errors.New("invalid webhook url")
+
+// Returned when the chat service rejects a post because the webhook was
+// deleted.
+var errWebhookGone = errors.New("webhook was deleted")
+
+// How long to wait for the chat service to accept a post.
+const webhookTimeout = 10 * time.Second
+
+// Prefixes of the webhook URLs of each provider. Webhooks are restricted to
+// the providers' hosts so that projects can't make the server send requests
+// to arbitrary URLs.
+var webhookPrefixes = map[Provider][]string{
+	ProviderSlack:   {"https://hooks.slack.com/"},
+	ProviderDiscord: {"https://discord.com/api/webhooks/", "https://discordapp.com/api/webhooks/"},
+}
+
+// A message posted to a chat.
+type Message struct {
+	Title string
+	Text  string
+
+	// Link to the event's page, can be empty.
+	Url string
+}
+
+// Returns ErrInvalidWebhookUrl if the URL isn't a webhook of the provider.
+func checkWebhookUrl(provider Provider, webhookUrl string) error {
+	for _, prefix := range webhookPrefixes[provider] {
+		if strings.HasPrefix(webhookUrl, prefix) {
+			return nil
+		}
+	}
+
+	return ErrInvalidWebhookUrl
+}
+
+// Post a message to a webhook.
+// Returns errWebhookGone if the webhook doesn't exist anymore.
+func postWebhook(ctx context.Context, provider Provider, webhookUrl string, message Message) error {
+	var payload interface{}
+
+	switch provider {
+	case ProviderSlack:
+		payload = slackPayload(message)
+	case ProviderDiscord:
+		payload = discordPayload(message)
+	default:
+		return fmt.Errorf("unknown integration provider %q", provider)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, "POST", webhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode < 300:
+		return nil
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		return errWebhookGone
+	default:
+		return fmt.Errorf("%s webhook failed with status %d", provider, response.StatusCode)
+	}
+}
+
+// Post a message to a webhook through its provider's circuit breaker, so that
+// posts fail fast while the provider is down. Deleted webhooks are the
+// project's fault and don't count as failures.
+// Returns breaker.ErrOpen without posting if the breaker is open.
+func (s *serviceImpl) postWebhook(ctx context.Context, provider Provider, webhookUrl string, message Message) error {
+	providerBreaker, ok := s.Breakers[provider]
+	if !ok {
+		return postWebhook(ctx, provider, webhookUrl, message)
+	}
+
+	err := providerBreaker.Allow()
+	if err != nil {
+		return err
+	}
+
+	err = postWebhook(ctx, provider, webhookUrl, message)
+	if err != nil && !errors.Is(err, errWebhookGone) && !errors.Is(err, context.Canceled) {
+		providerBreaker.Failure()
+	} else {
+		providerBreaker.Success()
+	}
+
+	return err
+}
+
+func slackPayload(message Message) map[string]interface{} {
+	// Slack's mrkdwn only requires &, < and > to be escaped
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+	title := "*" + escape(message.Title) + "*"
+	if message.Url != "" {
+		title = "*<" + message.Url + "|" + escape(message.Title) + ">*"
+	}
+
+	return map[string]interface{}{
+		"text": title + "\n" + escape(message.Text),
+	}
+}
+
+func discordPayload(message Message) map[string]interface{} {
+	embed := map[string]interface{}{
+		"title":       message.Title,
+		"description": message.Text,
+	}
+	if message.Url != "" {
+		embed["url"] = message.Url
+	}
+
+	return map[string]interface{}{
+		"embeds": []interface{}{embed},
+		// Users' text shouldn't be able to ping the channel
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	}
+}
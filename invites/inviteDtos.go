@@ -0,0 +1,16 @@
+package invites
+
+import "time"
+
+type InviteDto struct {
+	Code      string     `json:"code"`
+	InviterId uint       `json:"inviterId"`
+	InviteeId *uint      `json:"inviteeId"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+}
+
+type InviteQuotaDto struct {
+	// How many invites the user can still create. -1 means unlimited.
+	Remaining int `json:"remaining"`
+}
@@ -0,0 +1,20 @@
+package invites
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Invite struct {
+	gorm.Model
+
+	Code string
+
+	// The user that created the invite.
+	InviterId uint
+
+	// The user that registered with the invite. Nil while
+	// the invite hasn't been used.
+	InviteeId *uint
+	UsedAt    *time.Time
+}
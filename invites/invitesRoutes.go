@@ -0,0 +1,127 @@
+package invites
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Create an invite code
+// @Tags invites
+// @Router /invites [post]
+// @Success 201 {object} invites.InviteDto
+// @Failure 401
+// @Failure 403 "The user's invite quota is exhausted"
+func RouteCreateInvite(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	invitesService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := usersService.GetUser(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	invite, err := invitesService.CreateInvite(request.Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, invite)
+}
+
+// @Summary List the invites created by the current user
+// @Tags invites
+// @Router /invites [get]
+// @Success 200 {array} invites.InviteDto
+// @Failure 401
+func RouteListUserInvites(
+	writer http.ResponseWriter,
+	request *http.Request,
+	invitesService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	invites, err := invitesService.ListUserInvites(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, invites)
+}
+
+// @Summary Get the current user's remaining invite quota
+// @Tags invites
+// @Router /invites/quota [get]
+// @Success 200 {object} invites.InviteQuotaDto
+// @Failure 401
+func RouteGetInviteQuota(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	invitesService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := usersService.GetUser(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	remaining, err := invitesService.GetRemainingQuota(request.Context(), user)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, InviteQuotaDto{Remaining: remaining})
+}
+
+// @Summary List all invites
+// @Tags admin
+// @Router /admin/invites [get]
+// @Param pageSize query int false "Maximum amount of invites in the response. Default is 50, max is 100."
+// @Param pageOffset query int false "Response page number."
+// @Success 200 {array} invites.InviteDto
+// @Failure 403
+func RouteListInvites(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	invitesService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 50)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	if pageOffset < 0 {
+		pageOffset = 0
+	}
+
+	invites, err := invitesService.ListInvites(request.Context(), uint(pageSize), uint(pageOffset))
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, invites)
+}
@@ -0,0 +1,234 @@
+package invites
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var ErrInvalidInviteCode = errors.New("invalid invite code")
+var ErrInviteQuotaExceeded = errors.New("invite quota exceeded")
+
+type Service interface {
+	// Create an invite code on behalf of a user.
+	// Returns ErrInviteQuotaExceeded if the user already created as many
+	// invites as their quota allows. Moderators and admins have no quota.
+	CreateInvite(ctx context.Context, inviter *users.User) (InviteDto, error)
+
+	// List the invites created by a user, newest to oldest.
+	ListUserInvites(ctx context.Context, inviterId uint) ([]InviteDto, error)
+
+	// List all invites, newest to oldest.
+	ListInvites(ctx context.Context, pageSize uint, pageOffset uint) ([]InviteDto, error)
+
+	// Get how many invites a user can still create.
+	// Returns -1 if the user has no quota.
+	GetRemainingQuota(ctx context.Context, inviter *users.User) (int, error)
+
+	// Check that the new user has an invite code. Does nothing unless the
+	// registration mode is invite-only.
+	// Returns ErrInvalidInviteCode if the code is missing.
+	CheckRegistration(ctx context.Context, newUser users.NewUserDto) error
+
+	// Claim the new user's invite code and attribute it to them, in the
+	// transaction the user is created in, so that it can't be used by anyone
+	// else. Does nothing unless the registration mode is invite-only.
+	// Returns ErrInvalidInviteCode if the code doesn't exist or was already used.
+	ClaimRegistration(ctx context.Context, tx *gorm.DB, user *users.User, newUser users.NewUserDto) error
+}
+
+type serviceImpl struct {
+	Db    *gorm.DB
+	Mode  users.RegistrationMode
+	Quota int
+}
+
+// Create an invites service. quota is the maximum amount of invites a regular
+// user can create.
+func NewService(db *gorm.DB, mode users.RegistrationMode, quota int) Service {
+	return &serviceImpl{
+		Db:    db,
+		Mode:  mode,
+		Quota: quota,
+	}
+}
+
+func (s *serviceImpl) CreateInvite(ctx context.Context, inviter *users.User) (InviteDto, error) {
+	logger := log.FromContext(ctx).WithField("inviterId", inviter.ID)
+
+	remaining, err := s.GetRemainingQuota(ctx, inviter)
+	if err != nil {
+		return InviteDto{}, err
+	}
+
+	if remaining == 0 {
+		logger.Debug("User exceeded their invite quota")
+
+		return InviteDto{}, ErrInviteQuotaExceeded
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		logger.WithError(err).Error("Failed to generate invite code")
+
+		return InviteDto{}, err
+	}
+
+	invite := Invite{
+		Code:      code,
+		InviterId: inviter.ID,
+	}
+
+	result := s.Db.WithContext(ctx).Create(&invite)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create invite")
+
+		return InviteDto{}, result.Error
+	}
+
+	logger.Debug("Invite created")
+
+	return inviteToDto(invite), nil
+}
+
+func (s *serviceImpl) ListUserInvites(ctx context.Context, inviterId uint) ([]InviteDto, error) {
+	var invites []Invite
+	result := s.Db.WithContext(ctx).
+		Where("inviter_id = ?", inviterId).
+		Order("created_at desc").
+		Find(&invites)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list user invites")
+
+		return nil, result.Error
+	}
+
+	return invitesToDtos(invites), nil
+}
+
+func (s *serviceImpl) ListInvites(ctx context.Context, pageSize uint, pageOffset uint) ([]InviteDto, error) {
+	var invites []Invite
+	result := s.Db.WithContext(ctx).
+		Order("created_at desc").
+		Limit(int(pageSize)).
+		Offset(int(pageOffset * pageSize)).
+		Find(&invites)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list invites")
+
+		return nil, result.Error
+	}
+
+	return invitesToDtos(invites), nil
+}
+
+func (s *serviceImpl) GetRemainingQuota(ctx context.Context, inviter *users.User) (int, error) {
+	logger := log.FromContext(ctx).WithField("inviterId", inviter.ID)
+
+	if inviter.Role.Includes(users.RoleModerator) {
+		return -1, nil
+	}
+
+	var count int64
+	result := s.Db.WithContext(ctx).Model(&Invite{}).Where("inviter_id = ?", inviter.ID).Count(&count)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to count user invites")
+
+		return 0, result.Error
+	}
+
+	remaining := s.Quota - int(count)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return remaining, nil
+}
+
+func (s *serviceImpl) CheckRegistration(ctx context.Context, newUser users.NewUserDto) error {
+	if s.Mode != users.RegistrationModeInviteOnly {
+		return nil
+	}
+
+	if strings.TrimSpace(newUser.InviteCode) == "" {
+		log.FromContext(ctx).Debug("Registration without invite code")
+
+		return ErrInvalidInviteCode
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ClaimRegistration(ctx context.Context, tx *gorm.DB, user *users.User, newUser users.NewUserDto) error {
+	if s.Mode != users.RegistrationModeInviteOnly {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+
+	code := strings.ToUpper(strings.TrimSpace(newUser.InviteCode))
+
+	// Claim the invite in a single statement so that two
+	// registrations can't use the same code.
+	result := tx.
+		Model(&Invite{}).
+		Where("code = ? AND used_at IS NULL", code).
+		Updates(map[string]interface{}{
+			"used_at":    time.Now(),
+			"invitee_id": user.ID,
+		})
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to claim invite")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		logger.Debug("Invite code doesn't exist or was already used")
+
+		return ErrInvalidInviteCode
+	}
+
+	return nil
+}
+
+// Generate a random, human friendly invite code (e.g. "MFRGGZDFMZTWQ2LK").
+func generateCode() (string, error) {
+	bytes := make([]byte, 10)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return base32.StdEncoding.EncodeToString(bytes), nil
+}
+
+func inviteToDto(invite Invite) InviteDto {
+	return InviteDto{
+		Code:      invite.Code,
+		InviterId: invite.InviterId,
+		InviteeId: invite.InviteeId,
+		CreatedAt: invite.CreatedAt,
+		UsedAt:    invite.UsedAt,
+	}
+}
+
+func invitesToDtos(invites []Invite) []InviteDto {
+	dtos := make([]InviteDto, len(invites))
+	for i, invite := range invites {
+		dtos[i] = inviteToDto(invite)
+	}
+
+	return dtos
+}
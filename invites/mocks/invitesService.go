@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: invitesService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	invites "github.com/open-collaboration/server/invites"
+	users "github.com/open-collaboration/server/users"
+	gorm "gorm.io/gorm"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateInvite mocks base method
+func (m *MockService) CreateInvite(ctx context.Context, inviter *users.User) (invites.InviteDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvite", ctx, inviter)
+	ret0, _ := ret[0].(invites.InviteDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvite indicates an expected call of CreateInvite
+func (mr *MockServiceMockRecorder) CreateInvite(ctx, inviter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvite", reflect.TypeOf((*MockService)(nil).CreateInvite), ctx, inviter)
+}
+
+// ListUserInvites mocks base method
+func (m *MockService) ListUserInvites(ctx context.Context, inviterId uint) ([]invites.InviteDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserInvites", ctx, inviterId)
+	ret0, _ := ret[0].([]invites.InviteDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserInvites indicates an expected call of ListUserInvites
+func (mr *MockServiceMockRecorder) ListUserInvites(ctx, inviterId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserInvites", reflect.TypeOf((*MockService)(nil).ListUserInvites), ctx, inviterId)
+}
+
+// ListInvites mocks base method
+func (m *MockService) ListInvites(ctx context.Context, pageSize, pageOffset uint) ([]invites.InviteDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInvites", ctx, pageSize, pageOffset)
+	ret0, _ := ret[0].([]invites.InviteDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInvites indicates an expected call of ListInvites
+func (mr *MockServiceMockRecorder) ListInvites(ctx, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInvites", reflect.TypeOf((*MockService)(nil).ListInvites), ctx, pageSize, pageOffset)
+}
+
+// GetRemainingQuota mocks base method
+func (m *MockService) GetRemainingQuota(ctx context.Context, inviter *users.User) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRemainingQuota", ctx, inviter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRemainingQuota indicates an expected call of GetRemainingQuota
+func (mr *MockServiceMockRecorder) GetRemainingQuota(ctx, inviter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRemainingQuota", reflect.TypeOf((*MockService)(nil).GetRemainingQuota), ctx, inviter)
+}
+
+// CheckRegistration mocks base method
+func (m *MockService) CheckRegistration(ctx context.Context, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRegistration", ctx, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRegistration indicates an expected call of CheckRegistration
+func (mr *MockServiceMockRecorder) CheckRegistration(ctx, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRegistration", reflect.TypeOf((*MockService)(nil).CheckRegistration), ctx, newUser)
+}
+
+// ClaimRegistration mocks base method
+func (m *MockService) ClaimRegistration(ctx context.Context, tx *gorm.DB, user *users.User, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimRegistration", ctx, tx, user, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimRegistration indicates an expected call of ClaimRegistration
+func (mr *MockServiceMockRecorder) ClaimRegistration(ctx, tx, user, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimRegistration", reflect.TypeOf((*MockService)(nil).ClaimRegistration), ctx, tx, user, newUser)
+}
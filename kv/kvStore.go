@@ -0,0 +1,56 @@
+// Package kv stores short lived values (caches, one-time tokens, cooldowns
+// and rate limits) and relays messages between server instances. Values are
+// stored in Redis or, for installs with a single database, in Postgres. See
+// docs/redis.md for the keys.
+package kv
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("key not found")
+
+// A Store holds string values by key. Keys can expire: a ttl of 0 keeps a key
+// until it's deleted.
+type Store interface {
+	// Returns ErrNotFound if the key doesn't exist.
+	Get(ctx context.Context, key string) (string, error)
+
+	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+
+	// Set a key only if it doesn't exist. Returns whether it was set, which
+	// makes it usable as a lock, a cooldown or a rate limit.
+	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
+
+	// Get and delete a key atomically, for values that can only be used once.
+	// Returns ErrNotFound if the key doesn't exist.
+	Take(ctx context.Context, key string) (string, error)
+
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// Delete keys, including lists. Keys that don't exist are ignored.
+	Del(ctx context.Context, keys ...string) error
+
+	// Delete all keys matching a pattern, in which "*" matches any characters,
+	// e.g. "project:*:similar".
+	DelMatching(ctx context.Context, pattern string) error
+
+	// Prepend a value to a list, keep its first maxLen values and reset its
+	// expiry to ttl.
+	PushList(ctx context.Context, key string, value string, maxLen int, ttl time.Duration) error
+
+	// The values of a list, newest first. Empty if the list doesn't exist.
+	List(ctx context.Context, key string) ([]string, error)
+
+	// Send a message to the subscribers of a channel, on every server instance.
+	Publish(ctx context.Context, channel string, message string) error
+
+	// Receive the messages published on a channel until ctx is done, when the
+	// returned channel is closed. Messages published while the store is
+	// unreachable are lost.
+	Subscribe(ctx context.Context, channel string) (<-chan string, error)
+}
@@ -0,0 +1,320 @@
+package kv
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/stdlib"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// How often expired keys are deleted. Expired keys are ignored until then.
+const pruneInterval = time.Minute
+
+// How long to wait before listening again after the listening connection
+// failed.
+const relistenDelay = 5 * time.Second
+
+// Stores keys in the kv_entries and kv_list_values tables and relays messages
+// with LISTEN/NOTIFY, for installs without Redis. Expiry times are computed by
+// the database, so server instances don't depend on their clocks agreeing.
+type postgresStore struct {
+	Db *gorm.DB
+}
+
+type kvEntry struct {
+	Key       string
+	Value     string
+	ExpiresAt *time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *postgresStore {
+	return &postgresStore{Db: db}
+}
+
+// The condition of keys that haven't expired.
+const notExpired = "(expires_at IS NULL OR expires_at > now())"
+
+// The expiry time of a key set now with ttl, to be passed as a parameter to
+// expiresAtSql. Nil if the key doesn't expire.
+func ttlMilliseconds(ttl time.Duration) interface{} {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return ttl.Milliseconds()
+}
+
+const expiresAtSql = "now() + ?::BIGINT * INTERVAL '1 millisecond'"
+
+func (s *postgresStore) Get(ctx context.Context, key string) (string, error) {
+	var entries []kvEntry
+	err := s.Db.WithContext(ctx).
+		Raw("SELECT * FROM kv_entries WHERE key = ? AND "+notExpired, key).
+		Scan(&entries).
+		Error
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return "", ErrNotFound
+	}
+
+	return entries[0].Value, nil
+}
+
+func (s *postgresStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return s.Db.WithContext(ctx).Exec(`
+		INSERT INTO kv_entries (key, value, expires_at) VALUES (?, ?, `+expiresAtSql+`)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		key, value, ttlMilliseconds(ttl),
+	).Error
+}
+
+func (s *postgresStore) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	// An expired key that wasn't pruned yet is replaced
+	result := s.Db.WithContext(ctx).Exec(`
+		INSERT INTO kv_entries (key, value, expires_at) VALUES (?, ?, `+expiresAtSql+`)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at
+		WHERE kv_entries.expires_at <= now()`,
+		key, value, ttlMilliseconds(ttl),
+	)
+
+	return result.RowsAffected == 1, result.Error
+}
+
+func (s *postgresStore) Take(ctx context.Context, key string) (string, error) {
+	var entries []kvEntry
+	err := s.Db.WithContext(ctx).
+		Raw("DELETE FROM kv_entries WHERE key = ? AND "+notExpired+" RETURNING *", key).
+		Scan(&entries).
+		Error
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return "", ErrNotFound
+	}
+
+	return entries[0].Value, nil
+}
+
+func (s *postgresStore) Exists(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	err := s.Db.WithContext(ctx).
+		Raw(`SELECT
+			EXISTS (SELECT 1 FROM kv_entries WHERE key = ? AND `+notExpired+`) OR
+			EXISTS (SELECT 1 FROM kv_list_values WHERE key = ? AND `+notExpired+`)`,
+			key, key,
+		).
+		Scan(&exists).
+		Error
+
+	return exists, err
+}
+
+func (s *postgresStore) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM kv_entries WHERE key IN ?", keys).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec("DELETE FROM kv_list_values WHERE key IN ?", keys).Error
+	})
+}
+
+func (s *postgresStore) DelMatching(ctx context.Context, pattern string) error {
+	// Escape LIKE's wildcards, then turn the pattern's into LIKE's
+	like := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(pattern)
+	like = strings.ReplaceAll(like, "*", "%")
+
+	return s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM kv_entries WHERE key LIKE ?", like).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec("DELETE FROM kv_list_values WHERE key LIKE ?", like).Error
+	})
+}
+
+func (s *postgresStore) PushList(ctx context.Context, key string, value string, maxLen int, ttl time.Duration) error {
+	return s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Expired values would otherwise be kept by the new expiry
+		err := tx.Exec("DELETE FROM kv_list_values WHERE key = ? AND NOT "+notExpired, key).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(
+			"INSERT INTO kv_list_values (key, value, expires_at) VALUES (?, ?, "+expiresAtSql+")",
+			key, value, ttlMilliseconds(ttl),
+		).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec(
+			"UPDATE kv_list_values SET expires_at = "+expiresAtSql+" WHERE key = ?",
+			ttlMilliseconds(ttl), key,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`
+			DELETE FROM kv_list_values
+			WHERE key = ? AND id NOT IN (SELECT id FROM kv_list_values WHERE key = ? ORDER BY id DESC LIMIT ?)`,
+			key, key, maxLen,
+		).Error
+	})
+}
+
+func (s *postgresStore) List(ctx context.Context, key string) ([]string, error) {
+	var values []string
+	err := s.Db.WithContext(ctx).
+		Raw("SELECT value FROM kv_list_values WHERE key = ? AND "+notExpired+" ORDER BY id DESC", key).
+		Scan(&values).
+		Error
+
+	return values, err
+}
+
+func (s *postgresStore) Publish(ctx context.Context, channel string, message string) error {
+	return s.Db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", channel, message).Error
+}
+
+// Messages are received on a connection of the database's pool, reserved
+// for the subscription. If it fails another one is taken after
+// relistenDelay.
+func (s *postgresStore) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	sqlDb, err := s.Db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.listen(ctx, sqlDb, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make(chan string)
+
+	go func() {
+		defer close(messages)
+
+		logger := log.FromContext(ctx).WithField("channel", channel)
+
+		for {
+			err := s.receive(ctx, conn, messages)
+			_ = conn.Close()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			logger.WithError(err).Warn("Lost the connection listening for notifications")
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(relistenDelay):
+				}
+
+				conn, err = s.listen(ctx, sqlDb, channel)
+				if err == nil {
+					break
+				}
+
+				logger.WithError(err).Warn("Failed to listen for notifications")
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+// Take a connection from the pool and listen for a channel's notifications
+// on it.
+func (s *postgresStore) listen(ctx context.Context, sqlDb *sql.DB, channel string) (*sql.Conn, error) {
+	conn, err := sqlDb.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = conn.ExecContext(ctx, fmt.Sprintf(`LISTEN "%s"`, strings.ReplaceAll(channel, `"`, `""`)))
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Forward the notifications received on conn until ctx is done or the
+// connection fails.
+func (s *postgresStore) receive(ctx context.Context, conn *sql.Conn, messages chan<- string) error {
+	var err error
+
+	// The connection is still listening, or broken, when this returns:
+	// driver.ErrBadConn makes the pool discard it instead of reusing it.
+	_ = conn.Raw(func(driverConn interface{}) error {
+		pgxConn := driverConn.(*stdlib.Conn).Conn()
+
+		for {
+			var notification *pgconn.Notification
+			notification, err = pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return driver.ErrBadConn
+			}
+
+			select {
+			case messages <- notification.Payload:
+			case <-ctx.Done():
+				err = ctx.Err()
+
+				return driver.ErrBadConn
+			}
+		}
+	})
+
+	return err
+}
+
+// Delete expired keys periodically until ctx is done. Should be run in its
+// own goroutine.
+func (s *postgresStore) Run(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, table := range []string{"kv_entries", "kv_list_values"} {
+			err := s.Db.WithContext(ctx).Exec(fmt.Sprintf("DELETE FROM %s WHERE expires_at <= now()", table)).Error
+			if err != nil && ctx.Err() == nil {
+				logger.WithError(err).WithField("table", table).Warn("Failed to delete expired keys")
+			}
+		}
+	}
+}
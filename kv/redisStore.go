@@ -0,0 +1,184 @@
+package kv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+// The version of the format of the values in the store. Bump it when a value
+// changes incompatibly, so that during a deploy the old and the new version
+// of the server each use their own keys instead of reading each other's.
+const KeySchemaVersion = 1
+
+// The namespace of keys with prefix (see NewRedisStore) and a schema
+// version. Version 1 has no version segment, so that keys written before
+// versioning stay valid.
+func KeyNamespace(prefix string, version int) string {
+	if version > 1 {
+		prefix += fmt.Sprintf("v%d:", version)
+	}
+
+	return prefix
+}
+
+type redisStore struct {
+	Redis *redis.Client
+
+	// Prepended to keys
+	namespace string
+
+	// Prepended to channels. Channels aren't versioned, messages have to
+	// reach the instances of both versions during a deploy.
+	channelPrefix string
+}
+
+// Create a store whose keys and channels start with prefix (e.g.
+// "opencollab:"), so that several installs can share a Redis database. Keys
+// are also namespaced by KeySchemaVersion.
+func NewRedisStore(redisDb *redis.Client, prefix string) Store {
+	return &redisStore{
+		Redis:         redisDb,
+		namespace:     KeyNamespace(prefix, KeySchemaVersion),
+		channelPrefix: prefix,
+	}
+}
+
+func (s *redisStore) key(key string) string {
+	return s.namespace + key
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (string, error) {
+	value, err := s.Redis.Get(ctx, s.key(key)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+
+	return value, err
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return s.Redis.Set(ctx, s.key(key), value, ttl).Err()
+}
+
+func (s *redisStore) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	return s.Redis.SetNX(ctx, s.key(key), value, ttl).Result()
+}
+
+func (s *redisStore) Take(ctx context.Context, key string) (string, error) {
+	key = s.key(key)
+
+	var getCmd *redis.StringCmd
+	_, err := s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		getCmd = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return "", err
+	}
+
+	value, err := getCmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrNotFound
+	}
+
+	return value, err
+}
+
+func (s *redisStore) Exists(ctx context.Context, key string) (bool, error) {
+	count, err := s.Redis.Exists(ctx, s.key(key)).Result()
+
+	return count > 0, err
+}
+
+func (s *redisStore) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	namespaced := make([]string, len(keys))
+	for i, key := range keys {
+		namespaced[i] = s.key(key)
+	}
+
+	return s.Redis.Del(ctx, namespaced...).Err()
+}
+
+func (s *redisStore) DelMatching(ctx context.Context, pattern string) error {
+	iter := s.Redis.Scan(ctx, 0, s.key(pattern), 100).Iterator()
+	for iter.Next(ctx) {
+		err := s.Redis.Del(ctx, iter.Val()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
+
+func (s *redisStore) PushList(ctx context.Context, key string, value string, maxLen int, ttl time.Duration) error {
+	key = s.key(key)
+
+	_, err := s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, key, value)
+		pipe.LTrim(ctx, key, 0, int64(maxLen-1))
+		if ttl > 0 {
+			pipe.Expire(ctx, key, ttl)
+		}
+
+		return nil
+	})
+
+	return err
+}
+
+func (s *redisStore) List(ctx context.Context, key string) ([]string, error) {
+	return s.Redis.LRange(ctx, s.key(key), 0, -1).Result()
+}
+
+func (s *redisStore) Publish(ctx context.Context, channel string, message string) error {
+	return s.Redis.Publish(ctx, s.channelPrefix+channel, message).Err()
+}
+
+func (s *redisStore) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	pubsub := s.Redis.Subscribe(ctx, s.channelPrefix+channel)
+
+	// Wait for the subscription to be confirmed
+	_, err := pubsub.Receive(ctx)
+	if err != nil {
+		_ = pubsub.Close()
+
+		return nil, err
+	}
+
+	messages := make(chan string)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		received := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-received:
+				if !ok {
+					return
+				}
+
+				select {
+				case messages <- message.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/apex/log/handlers/json"
+	"github.com/open-collaboration/server/utils"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Configure the global logger:
+//
+// LOG_LEVEL is the minimum level logged (debug, info, warn or error).
+// LOG_FORMAT is "text" for colored terminal output or "json" for one JSON
+// object per line. LOG_OUTPUT is "stdout", "stderr" or a file path, which
+// logs are appended to. LOG_DEBUG_SAMPLE_RATE is the fraction of debug
+// entries to keep. LOG_REDACT_FIELDS is a comma separated list of field
+// names (or parts of them) whose values are redacted.
+func setupLogging() error {
+	level, err := log.ParseLevel(utils.GetEnvOrDefault("LOG_LEVEL", "debug"))
+	if err != nil {
+		return err
+	}
+
+	var output io.Writer
+	switch outputName := utils.GetEnvOrDefault("LOG_OUTPUT", "stdout"); outputName {
+	case "stdout":
+		output = os.Stdout
+	case "stderr":
+		output = os.Stderr
+	default:
+		output, err = os.OpenFile(outputName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+		if err != nil {
+			return err
+		}
+	}
+
+	var handler log.Handler
+	switch format := utils.GetEnvOrDefault("LOG_FORMAT", "text"); format {
+	case "text":
+		handler = utils.NewTerminalLogger(output)
+	case "json":
+		handler = json.New(output)
+	default:
+		return fmt.Errorf("unknown LOG_FORMAT %q", format)
+	}
+
+	debugSampleRate, err := strconv.ParseFloat(utils.GetEnvOrDefault("LOG_DEBUG_SAMPLE_RATE", "1"), 64)
+	if err != nil {
+		return err
+	}
+
+	if debugSampleRate < 1 {
+		handler = &utils.SamplingHandler{
+			Handler:   handler,
+			DebugRate: debugSampleRate,
+		}
+	}
+
+	redactedFields := strings.Split(utils.GetEnvOrDefault("LOG_REDACT_FIELDS", utils.DefaultRedactedFields), ",")
+	handler = utils.NewRedactingHandler(handler, redactedFields)
+
+	log.SetLevel(level)
+	log.SetHandler(handler)
+
+	return nil
+}
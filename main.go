@@ -4,27 +4,21 @@ import (
 	"context"
 	"fmt"
 	"github.com/apex/log"
-	"github.com/go-redis/redis/v8"
 	"github.com/joho/godotenv"
-	"github.com/open-collaboration/server/auth"
-	"github.com/open-collaboration/server/migrations"
-	"github.com/open-collaboration/server/projects"
-	router2 "github.com/open-collaboration/server/router"
-	"github.com/open-collaboration/server/users"
-	"github.com/open-collaboration/server/utils"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"github.com/open-collaboration/server/app"
+	"github.com/open-collaboration/server/diagnostics"
+	"github.com/open-collaboration/server/metrics"
+	"github.com/open-collaboration/server/router"
 	"net/http"
 	"os"
+
+	// Time zones are validated against the IANA database, which isn't
+	// installed in every container image
+	_ "time/tzdata"
 )
 
 func main() {
 
-	// Setup logging
-	log.SetLevel(log.DebugLevel)
-	log.SetHandler(utils.NewTerminalLogger(os.Stdout))
-
 	// Load env variables
 	err := godotenv.Load()
 	if err != nil {
@@ -32,69 +26,76 @@ func main() {
 		panic(err)
 	}
 
-	// Setup db connection
-	pgHost := os.Getenv("PG_HOST")
-	pgPort := os.Getenv("PG_PORT")
-	pgUser := os.Getenv("PG_USER")
-	pgPassword := os.Getenv("PG_PASSWORD")
-	pgDbName := os.Getenv("PG_DB_NAME")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable", pgHost, pgPort, pgUser, pgPassword, pgDbName)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Interface(&utils.GormLogger{}),
-	})
+	// Setup logging
+	err = setupLogging()
 	if err != nil {
-		log.WithError(err).Error("Failed to connect to database.")
+		log.WithError(err).Error("Failed to setup logging.")
 		panic(err)
 	}
 
-	db = db.Debug()
+	// Commands, e.g. `server search reindex`, run instead of the server
+	if len(os.Args) > 1 {
+		err = runCommand(os.Args[1:])
+		if err != nil {
+			log.WithError(err).Error("Command failed.")
+			os.Exit(1)
+		}
 
-	// Run db migrations
-	migration := migrations.GetMigration(db)
-	err = migration.Migrate()
-	if err != nil {
-		log.WithError(err).Error("Failed to run database migrations.")
-		panic(err)
+		return
 	}
 
-	// Setup redisDb connection
-	redisHost := utils.GetEnvOrPanic("REDIS_HOST")
-	redisPort := utils.GetEnvOrPanic("REDIS_PORT")
-	redisDb := redis.NewClient(&redis.Options{
-		Addr: fmt.Sprintf("%s:%s", redisHost, redisPort),
-	})
-
-	// Test redisDb connection
-	_, err = redisDb.Ping(context.Background()).Result()
+	err = runServer(app.LoadConfig())
 	if err != nil {
+		log.WithError(err).Error("Failed to start the server.")
 		panic(err)
 	}
+}
 
-	// Setup server
-	usersService := users.NewService(db)
-
-	providers := []interface{}{
-		auth.NewService(db, redisDb, usersService),
-		usersService,
-		projects.NewService(db),
+// Setup connections, services and routes and serve until the server fails.
+func runServer(config app.Config) error {
+	application, err := app.New(config)
+	if err != nil {
+		log.WithError(err).Error("Failed to setup the application.")
+		return err
 	}
 
-	router := router2.SetupRoutes(providers[:])
+	application.Start(context.Background())
 
-	host := utils.GetEnvOrPanic("HOST")
-	port := utils.GetEnvOrPanic("PORT")
 	server := &http.Server{
-		Addr:    fmt.Sprintf("%s:%s", host, port),
-		Handler: router,
+		Addr:    fmt.Sprintf("%s:%s", config.Host, config.Port),
+		Handler: application.Router,
 	}
 
-	log.Infof("Serving at %s", server.Addr)
+	if config.AdminAddr != "" {
+		publicHandler, adminHandler := router.SplitAdminRoutes(application.Router)
+		server.Handler = publicHandler
 
-	// Start server
-	err = server.ListenAndServe()
-	if err != nil {
-		log.WithError(err).Error("Failed to start the server.")
-		panic(err)
+		// pprof, expvar and metrics aren't authenticated, they're only served here
+		adminMux := http.NewServeMux()
+		adminMux.Handle("/debug/", diagnostics.DebugHandler())
+		adminMux.Handle("/metrics", metrics.Handler())
+		adminMux.Handle("/", adminHandler)
+
+		serveAdmin(&http.Server{
+			Addr:    config.AdminAddr,
+			Handler: adminMux,
+		})
 	}
+
+	// Start server
+	return serve(server)
+}
+
+// Serve the admin routes in the background. The admin listener serves plain
+// HTTP, it's meant to be bound to a private interface.
+func serveAdmin(server *http.Server) {
+	go func() {
+		log.Infof("Serving admin routes at http://%s", server.Addr)
+
+		err := server.ListenAndServe()
+		if err != nil {
+			log.WithError(err).Error("Failed to serve admin routes")
+			panic(err)
+		}
+	}()
 }
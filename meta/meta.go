@@ -0,0 +1,65 @@
+package meta
+
+import (
+	"github.com/open-collaboration/server/users"
+	"sort"
+)
+
+// Features reported by instances depending on their configuration.
+const (
+	FeatureGithubLogin    = "github-login"
+	FeatureGoogleLogin    = "google-login"
+	FeatureWebPush        = "web-push"
+	FeatureMobilePush     = "mobile-push"
+	FeatureSemanticSearch = "semantic-search"
+	FeatureAnalytics      = "analytics"
+)
+
+// What the frontend needs to know about the instance it runs against, e.g. a
+// self-hosted one: its branding and what's enabled.
+type Instance struct {
+	Name    string
+	LogoUrl string
+
+	// Optional features that are enabled: the Feature constants, derived from
+	// the configuration, and any flags set by the operator (INSTANCE_FEATURES)
+	Features []string
+
+	RegistrationMode users.RegistrationMode
+
+	// The version of the terms of service, e.g. "2024-05-01". Empty if there
+	// are none.
+	TosVersion string
+}
+
+// Enable features. Features already enabled are ignored.
+func (i *Instance) Enable(features ...string) {
+	for _, feature := range features {
+		if !i.Enabled(feature) {
+			i.Features = append(i.Features, feature)
+		}
+	}
+}
+
+func (i *Instance) Enabled(feature string) bool {
+	for _, enabled := range i.Features {
+		if enabled == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Instance) ToDto() MetaDto {
+	features := append([]string{}, i.Features...)
+	sort.Strings(features)
+
+	return MetaDto{
+		Name:             i.Name,
+		LogoUrl:          i.LogoUrl,
+		Features:         features,
+		RegistrationMode: string(i.RegistrationMode),
+		TosVersion:       i.TosVersion,
+	}
+}
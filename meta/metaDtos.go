@@ -0,0 +1,15 @@
+package meta
+
+type MetaDto struct {
+	Name    string `json:"name"`
+	LogoUrl string `json:"logoUrl,omitempty"`
+
+	// Optional features that are enabled, see Instance.Features
+	Features []string `json:"features"`
+
+	// "open", "invite-only" or "waitlist"
+	RegistrationMode string `json:"registrationMode"`
+
+	// Empty if the instance has no terms of service
+	TosVersion string `json:"tosVersion,omitempty"`
+}
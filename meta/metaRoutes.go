@@ -0,0 +1,17 @@
+package meta
+
+import (
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Get the instance's branding and configuration
+// @Description Lets the frontend configure itself against any instance: its name and logo, the optional features
+// @Description that are enabled (e.g. "github-login", "web-push" or flags set by the operator), how users register
+// @Description and the version of the terms of service.
+// @Tags meta
+// @Router /meta [get]
+// @Success 200 {object} meta.MetaDto
+func RouteGetMeta(writer http.ResponseWriter, request *http.Request, instance *Instance) error {
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, instance.ToDto())
+}
@@ -0,0 +1,222 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Business metrics (e.g. signups or applications), served in the Prometheus
+// text format by Handler. Counters are kept by each instance, Prometheus sums
+// them across instances. Gauges are computed when they're scraped, usually
+// from the database, so that they're the same on every instance.
+
+// How long computing all gauges can take on a scrape.
+const gaugeTimeout = 10 * time.Second
+
+var registry = struct {
+	mu       sync.Mutex
+	counters map[string]*Counter
+	gauges   map[string]gauge
+}{
+	counters: map[string]*Counter{},
+	gauges:   map[string]gauge{},
+}
+
+// A counter that only goes up, optionally split by labels, e.g. reviewed
+// applications by status.
+type Counter struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu sync.Mutex
+	// By the label values, joined with \xff
+	values map[string]float64
+}
+
+type gauge struct {
+	help       string
+	labelNames []string
+	compute    func(ctx context.Context) ([]Sample, error)
+}
+
+// A value of a gauge with labels, see RegisterGaugeVec.
+type Sample struct {
+	// One for each of the gauge's labels
+	LabelValues []string
+	Value       float64
+}
+
+// Create and register a counter. Meant to be called once per metric, when
+// the package that updates it is initialized.
+func NewCounter(name string, help string, labelNames ...string) *Counter {
+	counter := &Counter{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		values:     map[string]float64{},
+	}
+
+	// Counters without labels are exported as 0 before they're incremented
+	if len(labelNames) == 0 {
+		counter.values[""] = 0
+	}
+
+	registry.mu.Lock()
+	registry.counters[name] = counter
+	registry.mu.Unlock()
+
+	return counter
+}
+
+// Increment the counter, with a value for each of its labels.
+func (c *Counter) Inc(labelValues ...string) {
+	if len(labelValues) != len(c.labelNames) {
+		panic(fmt.Sprintf("metric %s has %d labels, got %d values", c.name, len(c.labelNames), len(labelValues)))
+	}
+
+	c.mu.Lock()
+	c.values[strings.Join(labelValues, "\xff")]++
+	c.mu.Unlock()
+}
+
+// Register a gauge computed by compute on every scrape, replacing the gauge
+// registered with the same name, if any. Gauges that fail or time out are
+// left out of the scrape.
+func RegisterGauge(name string, help string, compute func(ctx context.Context) (float64, error)) {
+	RegisterGaugeVec(name, help, nil, func(ctx context.Context) ([]Sample, error) {
+		value, err := compute(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return []Sample{{Value: value}}, nil
+	})
+}
+
+// Register a gauge split by labels, e.g. the state of each circuit breaker,
+// whose samples are computed by compute on every scrape. Same as
+// RegisterGauge otherwise.
+func RegisterGaugeVec(name string, help string, labelNames []string, compute func(ctx context.Context) ([]Sample, error)) {
+	registry.mu.Lock()
+	registry.gauges[name] = gauge{help: help, labelNames: labelNames, compute: compute}
+	registry.mu.Unlock()
+}
+
+// Serves all metrics in the Prometheus text format. It isn't authenticated,
+// so it must only be served on the admin listener.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ctx, cancel := context.WithTimeout(request.Context(), gaugeTimeout)
+		defer cancel()
+
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+		_, err := writer.Write([]byte(render(ctx)))
+		if err != nil {
+			log.WithError(err).Warn("Failed to write metrics")
+		}
+	})
+}
+
+func render(ctx context.Context) string {
+	registry.mu.Lock()
+	counters := make([]*Counter, 0, len(registry.counters))
+	for _, counter := range registry.counters {
+		counters = append(counters, counter)
+	}
+	gauges := make(map[string]gauge, len(registry.gauges))
+	for name, gauge := range registry.gauges {
+		gauges[name] = gauge
+	}
+	registry.mu.Unlock()
+
+	builder := strings.Builder{}
+
+	sort.Slice(counters, func(i, j int) bool {
+		return counters[i].name < counters[j].name
+	})
+
+	for _, counter := range counters {
+		writeHeader(&builder, counter.name, counter.help, "counter")
+
+		counter.mu.Lock()
+		keys := make([]string, 0, len(counter.values))
+		for key := range counter.values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			builder.WriteString(counter.name)
+			if len(counter.labelNames) > 0 {
+				writeLabels(&builder, counter.labelNames, strings.Split(key, "\xff"))
+			}
+			builder.WriteString(" " + formatValue(counter.values[key]) + "\n")
+		}
+		counter.mu.Unlock()
+	}
+
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		gauge := gauges[name]
+
+		samples, err := gauge.compute(ctx)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).WithField("metric", name).Warn("Failed to compute gauge")
+
+			continue
+		}
+
+		writeHeader(&builder, name, gauge.help, "gauge")
+		for _, sample := range samples {
+			if len(sample.LabelValues) != len(gauge.labelNames) {
+				panic(fmt.Sprintf("metric %s has %d labels, got %d values", name, len(gauge.labelNames), len(sample.LabelValues)))
+			}
+
+			builder.WriteString(name)
+			if len(gauge.labelNames) > 0 {
+				writeLabels(&builder, gauge.labelNames, sample.LabelValues)
+			}
+			builder.WriteString(" " + formatValue(sample.Value) + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+func writeHeader(builder *strings.Builder, name string, help string, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+
+	builder.WriteString("# HELP " + name + " " + help + "\n")
+	builder.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+func writeLabels(builder *strings.Builder, names []string, values []string) {
+	escaper := strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+	builder.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(name + `="` + escaper.Replace(values[i]) + `"`)
+	}
+	builder.WriteString("}")
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
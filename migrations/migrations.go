@@ -1,9 +1,12 @@
 package migrations
 
 import (
+	"github.com/apex/log"
 	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/lib/pq"
+	"github.com/open-collaboration/server/database"
 	"gorm.io/gorm"
+	"time"
 )
 
 var usersTable = gormigrate.Migration{
@@ -26,7 +29,7 @@ var usersTable = gormigrate.Migration{
 var projectsTable = gormigrate.Migration{
 	ID: "1",
 	Migrate: func(db *gorm.DB) error {
-		type Project struct {
+		type ProjectColumns struct {
 			gorm.Model
 
 			Name             string         `gorm:"type: VARCHAR(32)"`
@@ -34,7 +37,21 @@ var projectsTable = gormigrate.Migration{
 			LongDescription  string         `gorm:"type: VARCHAR(10000)"`
 			ShortDescription string         `gorm:"type: VARCHAR(200)"`
 			GithubLink       string
-			LinkUid          int `gorm:"autoIncrement"`
+		}
+
+		// SQLite only auto increments primary keys, link_uid is unused anyway
+		if database.IsSqlite(db) {
+			type Project struct {
+				ProjectColumns
+				LinkUid int
+			}
+
+			return db.AutoMigrate(&Project{})
+		}
+
+		type Project struct {
+			ProjectColumns
+			LinkUid int `gorm:"autoIncrement"`
 		}
 
 		return db.AutoMigrate(&Project{})
@@ -44,9 +61,1584 @@ var projectsTable = gormigrate.Migration{
 	},
 }
 
+var userRoles = gormigrate.Migration{
+	ID: "2",
+	Migrate: func(db *gorm.DB) error {
+		type User struct {
+			Role string `gorm:"type: VARCHAR(16); not null; default: 'user'"`
+		}
+
+		return db.AutoMigrate(&User{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropColumn("users", "role")
+	},
+}
+
+var blocklistEntriesTable = gormigrate.Migration{
+	ID: "3",
+	Migrate: func(db *gorm.DB) error {
+		type BlocklistEntry struct {
+			gorm.Model
+
+			Kind   string `gorm:"type: VARCHAR(16); not null; index"`
+			Value  string `gorm:"type: VARCHAR(255); not null"`
+			Reason string `gorm:"type: VARCHAR(500)"`
+		}
+
+		return db.AutoMigrate(&BlocklistEntry{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("blocklist_entries")
+	},
+}
+
+var invitesTable = gormigrate.Migration{
+	ID: "4",
+	Migrate: func(db *gorm.DB) error {
+		type Invite struct {
+			gorm.Model
+
+			Code      string `gorm:"type: VARCHAR(32); not null; uniqueIndex"`
+			InviterId uint   `gorm:"not null; index"`
+			InviteeId *uint
+			UsedAt    *time.Time
+		}
+
+		return db.AutoMigrate(&Invite{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("invites")
+	},
+}
+
+var waitlistEntriesTable = gormigrate.Migration{
+	ID: "5",
+	Migrate: func(db *gorm.DB) error {
+		type WaitlistEntry struct {
+			gorm.Model
+
+			Email       string `gorm:"type: VARCHAR(255); not null; uniqueIndex"`
+			TokenHash   string `gorm:"type: CHAR(64)"`
+			ActivatedAt *time.Time
+			SignedUpAt  *time.Time
+		}
+
+		return db.AutoMigrate(&WaitlistEntry{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("waitlist_entries")
+	},
+}
+
+var identitiesTable = gormigrate.Migration{
+	ID: "6",
+	Migrate: func(db *gorm.DB) error {
+		type Identity struct {
+			gorm.Model
+
+			UserId   uint   `gorm:"not null; index"`
+			Provider string `gorm:"type: VARCHAR(32); not null; uniqueIndex:idx_identities_provider_subject"`
+			Subject  string `gorm:"type: VARCHAR(255); not null; uniqueIndex:idx_identities_provider_subject"`
+			Email    string `gorm:"type: VARCHAR(255)"`
+		}
+
+		return db.AutoMigrate(&Identity{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("identities")
+	},
+}
+
+var auditLogTable = gormigrate.Migration{
+	ID: "7",
+	Migrate: func(db *gorm.DB) error {
+		type AuditLogEntry struct {
+			gorm.Model
+
+			ActorId    uint   `gorm:"not null; index"`
+			Action     string `gorm:"type: VARCHAR(64); not null; index"`
+			TargetType string `gorm:"type: VARCHAR(32); index:idx_audit_log_entries_target"`
+			TargetId   uint   `gorm:"index:idx_audit_log_entries_target"`
+			Details    string `gorm:"type: JSONB; not null; default: '{}'"`
+			Ip         string `gorm:"type: VARCHAR(45)"`
+		}
+
+		return db.AutoMigrate(&AuditLogEntry{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("audit_log_entries")
+	},
+}
+
+var restrictionsTable = gormigrate.Migration{
+	ID: "8",
+	Migrate: func(db *gorm.DB) error {
+		type Restriction struct {
+			gorm.Model
+
+			UserId          uint   `gorm:"not null; index"`
+			Kind            string `gorm:"type: VARCHAR(16); not null"`
+			CooldownSeconds uint   `gorm:"not null; default: 0"`
+			Reason          string `gorm:"type: VARCHAR(500)"`
+			CreatedById     uint   `gorm:"not null"`
+			ExpiresAt       *time.Time
+		}
+
+		return db.AutoMigrate(&Restriction{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("restrictions")
+	},
+}
+
+var projectOwnersAndReview = gormigrate.Migration{
+	ID: "9",
+	Migrate: func(db *gorm.DB) error {
+		type Project struct {
+			OwnerId       uint `gorm:"index"`
+			PendingReview bool `gorm:"not null; default: false"`
+		}
+
+		return db.AutoMigrate(&Project{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropColumn("projects", "owner_id")
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropColumn("projects", "pending_review")
+	},
+}
+
+var notificationsTable = gormigrate.Migration{
+	ID: "10",
+	Migrate: func(db *gorm.DB) error {
+		type Notification struct {
+			gorm.Model
+
+			UserId uint   `gorm:"not null; index"`
+			Type   string `gorm:"type: VARCHAR(64); not null"`
+			Title  string `gorm:"type: VARCHAR(200); not null"`
+			Body   string `gorm:"type: TEXT"`
+			Data   string `gorm:"type: JSONB; not null; default: '{}'"`
+			ReadAt *time.Time
+		}
+
+		return db.AutoMigrate(&Notification{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("notifications")
+	},
+}
+
+var applicationsTable = gormigrate.Migration{
+	ID: "11",
+	Migrate: func(db *gorm.DB) error {
+		type Application struct {
+			gorm.Model
+
+			ProjectId   uint   `gorm:"not null; index"`
+			ApplicantId uint   `gorm:"not null; index:idx_applications_applicant_message"`
+			Message     string `gorm:"type: VARCHAR(5000); not null"`
+			Status      string `gorm:"type: VARCHAR(16); not null; default: 'pending'"`
+			MessageHash string `gorm:"type: CHAR(64); not null; index:idx_applications_applicant_message"`
+			Flagged     bool   `gorm:"not null; default: false"`
+		}
+
+		return db.AutoMigrate(&Application{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("applications")
+	},
+}
+
+var projectQualityAndRoles = gormigrate.Migration{
+	ID: "12",
+	Migrate: func(db *gorm.DB) error {
+		type Project struct {
+			CoverImageUrl string `gorm:"type: VARCHAR(500)"`
+			QualityScore  int    `gorm:"not null; default: 0; index"`
+		}
+
+		type Role struct {
+			gorm.Model
+
+			ProjectId   uint           `gorm:"not null; index"`
+			Title       string         `gorm:"type: VARCHAR(64); not null"`
+			Description string         `gorm:"type: VARCHAR(2000)"`
+			Skills      pq.StringArray `gorm:"type: TEXT[]"`
+		}
+
+		err := db.AutoMigrate(&Project{})
+		if err != nil {
+			return err
+		}
+
+		return db.Table("project_roles").AutoMigrate(&Role{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropTable("project_roles")
+		if err != nil {
+			return err
+		}
+
+		err = db.Migrator().DropColumn("projects", "cover_image_url")
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropColumn("projects", "quality_score")
+	},
+}
+
+var projectTagsIndex = gormigrate.Migration{
+	ID: "13",
+	Migrate: func(db *gorm.DB) error {
+		// SQLite has no GIN indexes, its array conditions scan the table
+		if database.IsSqlite(db) {
+			return nil
+		}
+
+		return db.Exec("CREATE INDEX IF NOT EXISTS idx_projects_tags ON projects USING GIN (tags)").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Exec("DROP INDEX IF EXISTS idx_projects_tags").Error
+	},
+}
+
+var projectSimilarityIndexes = gormigrate.Migration{
+	ID: "14",
+	Migrate: func(db *gorm.DB) error {
+		if database.IsSqlite(db) {
+			return nil
+		}
+
+		statements := []string{
+			"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+			"CREATE INDEX IF NOT EXISTS idx_projects_short_description_trgm ON projects USING GIN (short_description gin_trgm_ops)",
+			"CREATE INDEX IF NOT EXISTS idx_project_roles_skills ON project_roles USING GIN (skills)",
+		}
+
+		for _, statement := range statements {
+			err := db.Exec(statement).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Exec("DROP INDEX IF EXISTS idx_project_roles_skills").Error
+		if err != nil {
+			return err
+		}
+
+		return db.Exec("DROP INDEX IF EXISTS idx_projects_short_description_trgm").Error
+	},
+}
+
+var projectSearch = gormigrate.Migration{
+	ID: "15",
+	Migrate: func(db *gorm.DB) error {
+		// Search is unavailable on SQLite
+		if database.IsSqlite(db) {
+			return nil
+		}
+
+		err := db.Exec(
+			"CREATE INDEX IF NOT EXISTS idx_projects_search ON projects USING GIN " +
+				"(to_tsvector('english', name || ' ' || short_description || ' ' || long_description))",
+		).Error
+		if err != nil {
+			return err
+		}
+
+		// Semantic search is optional, so the embeddings table is only created
+		// if pgvector is available. The migration can be rolled back and run
+		// again after installing it.
+		var available int64
+		err = db.Raw("SELECT count(*) FROM pg_available_extensions WHERE name = 'vector'").Scan(&available).Error
+		if err != nil {
+			return err
+		}
+
+		if available < 1 {
+			log.Warn("pgvector is not available, semantic search won't work")
+
+			return nil
+		}
+
+		statements := []string{
+			"CREATE EXTENSION IF NOT EXISTS vector",
+			// The embedding has no fixed dimensions so that the embedding
+			// provider can be changed without a migration.
+			`CREATE TABLE IF NOT EXISTS project_embeddings (
+				project_id BIGINT PRIMARY KEY REFERENCES projects (id) ON DELETE CASCADE,
+				embedding  vector NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+		}
+
+		for _, statement := range statements {
+			err = db.Exec(statement).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Exec("DROP TABLE IF EXISTS project_embeddings").Error
+		if err != nil {
+			return err
+		}
+
+		return db.Exec("DROP INDEX IF EXISTS idx_projects_search").Error
+	},
+}
+
+var savedSearchesTables = gormigrate.Migration{
+	ID: "16",
+	Migrate: func(db *gorm.DB) error {
+		type SavedSearch struct {
+			gorm.Model
+
+			UserId        uint           `gorm:"not null; index"`
+			Name          string         `gorm:"type: VARCHAR(64); not null"`
+			Query         string         `gorm:"type: VARCHAR(200); not null; default: ''"`
+			Tags          pq.StringArray `gorm:"type: TEXT[]; not null; default: '{}'"`
+			NotifyByEmail bool           `gorm:"not null; default: false"`
+		}
+
+		type SavedSearchMatch struct {
+			SavedSearchId uint `gorm:"primaryKey"`
+			ProjectId     uint `gorm:"primaryKey"`
+		}
+
+		return db.AutoMigrate(&SavedSearch{}, &SavedSearchMatch{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("saved_search_matches", "saved_searches")
+	},
+}
+
+var homepageTables = gormigrate.Migration{
+	ID: "17",
+	Migrate: func(db *gorm.DB) error {
+		type HomepageSection struct {
+			gorm.Model
+
+			Position int    `gorm:"not null"`
+			Kind     string `gorm:"type: VARCHAR(16); not null"`
+			Title    string `gorm:"type: VARCHAR(64); not null"`
+			Tag      string `gorm:"type: VARCHAR(32)"`
+			Size     int    `gorm:"not null"`
+		}
+
+		type FeaturedProject struct {
+			gorm.Model
+
+			ProjectId uint `gorm:"not null; uniqueIndex"`
+			Position  int  `gorm:"not null"`
+		}
+
+		return db.AutoMigrate(&HomepageSection{}, &FeaturedProject{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("homepage_sections", "featured_projects")
+	},
+}
+
+var bannedTagsTable = gormigrate.Migration{
+	ID: "18",
+	Migrate: func(db *gorm.DB) error {
+		type BannedTag struct {
+			gorm.Model
+
+			Name   string `gorm:"type: VARCHAR(40); not null; uniqueIndex"`
+			Reason string `gorm:"type: VARCHAR(500)"`
+		}
+
+		return db.AutoMigrate(&BannedTag{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("banned_tags")
+	},
+}
+
+var notificationPreferencesTable = gormigrate.Migration{
+	ID: "19",
+	Migrate: func(db *gorm.DB) error {
+		type NotificationPreference struct {
+			UserId    uint      `gorm:"primaryKey"`
+			Type      string    `gorm:"primaryKey; type: VARCHAR(64)"`
+			Channel   string    `gorm:"primaryKey; type: VARCHAR(16)"`
+			Enabled   bool      `gorm:"not null"`
+			UpdatedAt time.Time `gorm:"not null"`
+		}
+
+		return db.AutoMigrate(&NotificationPreference{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("notification_preferences")
+	},
+}
+
+var pushSubscriptionsTable = gormigrate.Migration{
+	ID: "20",
+	Migrate: func(db *gorm.DB) error {
+		type PushSubscription struct {
+			gorm.Model
+
+			UserId   uint   `gorm:"not null; index"`
+			Endpoint string `gorm:"type: VARCHAR(1000); not null; uniqueIndex"`
+			P256dh   string `gorm:"type: VARCHAR(200); not null"`
+			Auth     string `gorm:"type: VARCHAR(100); not null"`
+		}
+
+		return db.AutoMigrate(&PushSubscription{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("push_subscriptions")
+	},
+}
+
+var pushDevicesTable = gormigrate.Migration{
+	ID: "21",
+	Migrate: func(db *gorm.DB) error {
+		type PushDevice struct {
+			gorm.Model
+
+			UserId   uint   `gorm:"not null; index"`
+			Platform string `gorm:"type: VARCHAR(16); not null"`
+			Token    string `gorm:"type: VARCHAR(500); not null; uniqueIndex"`
+		}
+
+		return db.AutoMigrate(&PushDevice{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("push_devices")
+	},
+}
+
+var projectIntegrationsTable = gormigrate.Migration{
+	ID: "22",
+	Migrate: func(db *gorm.DB) error {
+		type ProjectIntegration struct {
+			gorm.Model
+
+			ProjectId  uint   `gorm:"not null; index"`
+			Provider   string `gorm:"type: VARCHAR(16); not null"`
+			WebhookUrl string `gorm:"type: VARCHAR(500); not null"`
+
+			NewApplicant bool `gorm:"not null; default: false"`
+			NewComment   bool `gorm:"not null; default: false"`
+			Announcement bool `gorm:"not null; default: false"`
+
+			Enabled       bool `gorm:"not null; default: true"`
+			FailureCount  int  `gorm:"not null; default: 0"`
+			LastError     string
+			LastFailureAt *time.Time
+		}
+
+		return db.AutoMigrate(&ProjectIntegration{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("project_integrations")
+	},
+}
+
+var calendarTables = gormigrate.Migration{
+	ID: "23",
+	Migrate: func(db *gorm.DB) error {
+		type ProjectEvent struct {
+			gorm.Model
+
+			ProjectId   uint      `gorm:"not null; index"`
+			Kind        string    `gorm:"type: VARCHAR(16); not null"`
+			Title       string    `gorm:"type: VARCHAR(100); not null"`
+			Description string    `gorm:"type: VARCHAR(2000); not null; default: ''"`
+			Location    string    `gorm:"type: VARCHAR(200); not null; default: ''"`
+			StartsAt    time.Time `gorm:"not null"`
+			EndsAt      *time.Time
+		}
+
+		type CalendarFeed struct {
+			gorm.Model
+
+			UserId    uint   `gorm:"not null; uniqueIndex"`
+			TokenHash string `gorm:"type: CHAR(64); not null; uniqueIndex"`
+		}
+
+		return db.AutoMigrate(&ProjectEvent{}, &CalendarFeed{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("project_events", "calendar_feeds")
+	},
+}
+
+var userSettings = gormigrate.Migration{
+	ID: "24",
+	Migrate: func(db *gorm.DB) error {
+		type User struct {
+			Timezone string `gorm:"type: VARCHAR(64); not null; default: 'UTC'"`
+			Locale   string `gorm:"type: VARCHAR(35); not null; default: 'en'"`
+		}
+
+		return db.AutoMigrate(&User{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropColumn("users", "timezone")
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropColumn("users", "locale")
+	},
+}
+
+var projectLicenses = gormigrate.Migration{
+	ID: "25",
+	Migrate: func(db *gorm.DB) error {
+		type Project struct {
+			License          string `gorm:"type: VARCHAR(64); not null; default: ''; index"`
+			CodeOfConductUrl string `gorm:"type: VARCHAR(500); not null; default: ''"`
+			ContributingUrl  string `gorm:"type: VARCHAR(500); not null; default: ''"`
+		}
+
+		return db.AutoMigrate(&Project{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		for _, column := range []string{"license", "code_of_conduct_url", "contributing_url"} {
+			err := db.Migrator().DropColumn("projects", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var projectTechStack = gormigrate.Migration{
+	ID: "26",
+	Migrate: func(db *gorm.DB) error {
+		type Project struct {
+			Languages  pq.StringArray `gorm:"type: TEXT[]; not null; default: '{}'"`
+			Frameworks pq.StringArray `gorm:"type: TEXT[]; not null; default: '{}'"`
+			Platforms  pq.StringArray `gorm:"type: TEXT[]; not null; default: '{}'"`
+		}
+
+		err := db.AutoMigrate(&Project{})
+		if err != nil || database.IsSqlite(db) {
+			return err
+		}
+
+		for _, column := range []string{"languages", "frameworks", "platforms"} {
+			err = db.Exec("CREATE INDEX IF NOT EXISTS idx_projects_" + column + " ON projects USING GIN (" + column + ")").Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Rollback: func(db *gorm.DB) error {
+		for _, column := range []string{"languages", "frameworks", "platforms"} {
+			err := db.Migrator().DropColumn("projects", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var projectStatus = gormigrate.Migration{
+	ID: "27",
+	Migrate: func(db *gorm.DB) error {
+		// Existing projects are assumed to be active
+		type Project struct {
+			Status string `gorm:"type: VARCHAR(16); not null; default: 'active'; index"`
+		}
+
+		type ProjectStatusChange struct {
+			gorm.Model
+
+			ProjectId uint   `gorm:"not null; index"`
+			From      string `gorm:"type: VARCHAR(16); not null"`
+			To        string `gorm:"type: VARCHAR(16); not null"`
+			ChangedBy uint   `gorm:"not null"`
+		}
+
+		return db.AutoMigrate(&Project{}, &ProjectStatusChange{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropTable("project_status_changes")
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropColumn("projects", "status")
+	},
+}
+
+var roleCommitment = gormigrate.Migration{
+	ID: "28",
+	Migrate: func(db *gorm.DB) error {
+		type Role struct {
+			WeeklyHours int    `gorm:"not null; default: 0"`
+			Seniority   string `gorm:"type: VARCHAR(16); not null; default: ''"`
+			Mentorship  bool   `gorm:"not null; default: false"`
+		}
+
+		return db.Table("project_roles").AutoMigrate(&Role{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		for _, column := range []string{"weekly_hours", "seniority", "mentorship"} {
+			err := db.Migrator().DropColumn("project_roles", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var screeningQuestions = gormigrate.Migration{
+	ID: "29",
+	Migrate: func(db *gorm.DB) error {
+		type RoleQuestion struct {
+			gorm.Model
+
+			RoleId   uint   `gorm:"not null; index"`
+			Text     string `gorm:"type: VARCHAR(500); not null"`
+			Required bool   `gorm:"not null; default: false"`
+		}
+
+		type Application struct {
+			RoleId uint `gorm:"not null; default: 0"`
+		}
+
+		type ApplicationAnswer struct {
+			gorm.Model
+
+			ApplicationId uint   `gorm:"not null; index"`
+			QuestionId    uint   `gorm:"not null"`
+			Question      string `gorm:"type: VARCHAR(500); not null"`
+			Answer        string `gorm:"type: VARCHAR(2000); not null"`
+		}
+
+		return db.AutoMigrate(&RoleQuestion{}, &Application{}, &ApplicationAnswer{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropTable("role_questions", "application_answers")
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropColumn("applications", "role_id")
+	},
+}
+
+var interviewScheduling = gormigrate.Migration{
+	ID: "30",
+	Migrate: func(db *gorm.DB) error {
+		type Application struct {
+			InterviewStatus   string `gorm:"type: VARCHAR(16); not null; default: ''"`
+			InterviewAt       *time.Time
+			InterviewMinutes  int    `gorm:"not null; default: 0"`
+			InterviewLocation string `gorm:"type: VARCHAR(200); not null; default: ''"`
+			InterviewReminded bool   `gorm:"not null; default: false"`
+		}
+
+		type InterviewSlot struct {
+			gorm.Model
+
+			ApplicationId uint      `gorm:"not null; index"`
+			StartsAt      time.Time `gorm:"not null"`
+		}
+
+		err := db.AutoMigrate(&Application{}, &InterviewSlot{})
+		if err != nil {
+			return err
+		}
+
+		// Reminders look for scheduled interviews starting soon
+		return db.Exec("CREATE INDEX IF NOT EXISTS idx_applications_interview_at ON applications (interview_at) WHERE interview_status = 'scheduled'").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropTable("interview_slots")
+		if err != nil {
+			return err
+		}
+
+		for _, column := range []string{"interview_status", "interview_at", "interview_minutes", "interview_location", "interview_reminded"} {
+			err = db.Migrator().DropColumn("applications", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var contributionsTable = gormigrate.Migration{
+	ID: "31",
+	Migrate: func(db *gorm.DB) error {
+		type Contribution struct {
+			gorm.Model
+
+			ProjectId   uint      `gorm:"not null; index; uniqueIndex:idx_contributions_external_id"`
+			UserId      uint      `gorm:"not null; index"`
+			Source      string    `gorm:"type: VARCHAR(16); not null; uniqueIndex:idx_contributions_external_id"`
+			Title       string    `gorm:"type: VARCHAR(300); not null"`
+			Description string    `gorm:"type: VARCHAR(2000); not null; default: ''"`
+			Url         string    `gorm:"type: VARCHAR(500); not null; default: ''"`
+			OccurredAt  time.Time `gorm:"not null"`
+			ExternalId  *string   `gorm:"type: VARCHAR(64); uniqueIndex:idx_contributions_external_id"`
+		}
+
+		return db.AutoMigrate(&Contribution{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("contributions")
+	},
+}
+
+var projectDrafts = gormigrate.Migration{
+	ID: "32",
+	Migrate: func(db *gorm.DB) error {
+		type Project struct {
+			Draft bool `gorm:"not null; default: false"`
+		}
+
+		return db.AutoMigrate(&Project{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropColumn("projects", "draft")
+	},
+}
+
+var collectionsTables = gormigrate.Migration{
+	ID: "33",
+	Migrate: func(db *gorm.DB) error {
+		type Collection struct {
+			gorm.Model
+
+			Name           string `gorm:"type: VARCHAR(64); not null"`
+			Description    string `gorm:"type: VARCHAR(5000); not null; default: ''"`
+			BannerImageUrl string `gorm:"type: VARCHAR(500); not null; default: ''"`
+			WebsiteUrl     string `gorm:"type: VARCHAR(500); not null; default: ''"`
+			StartsAt       *time.Time
+			EndsAt         *time.Time
+			JoinOpensAt    *time.Time
+			JoinClosesAt   *time.Time
+			OrganizerId    uint `gorm:"not null; index"`
+		}
+
+		type CollectionProject struct {
+			CollectionId uint `gorm:"primaryKey"`
+			ProjectId    uint `gorm:"primaryKey; index"`
+			CreatedAt    time.Time
+		}
+
+		return db.AutoMigrate(&Collection{}, &CollectionProject{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("collection_projects", "collections")
+	},
+}
+
+var projectFundingLinks = gormigrate.Migration{
+	ID: "34",
+	Migrate: func(db *gorm.DB) error {
+		type ProjectFundingLink struct {
+			gorm.Model
+
+			ProjectId uint   `gorm:"not null; index"`
+			Platform  string `gorm:"type: VARCHAR(16); not null"`
+			Url       string `gorm:"type: VARCHAR(500); not null"`
+			Label     string `gorm:"type: VARCHAR(64); not null; default: ''"`
+			Position  int    `gorm:"not null"`
+			Clicks    int64  `gorm:"not null; default: 0"`
+		}
+
+		return db.AutoMigrate(&ProjectFundingLink{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("project_funding_links")
+	},
+}
+
+var projectExternalLinks = gormigrate.Migration{
+	ID: "35",
+	Migrate: func(db *gorm.DB) error {
+		type ProjectExternalLink struct {
+			gorm.Model
+
+			ProjectId uint   `gorm:"not null; index"`
+			Label     string `gorm:"type: VARCHAR(40); not null"`
+			Url       string `gorm:"type: VARCHAR(500); not null"`
+			Position  int    `gorm:"not null"`
+		}
+
+		return db.AutoMigrate(&ProjectExternalLink{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("project_external_links")
+	},
+}
+
+var reportsTable = gormigrate.Migration{
+	ID: "36",
+	Migrate: func(db *gorm.DB) error {
+		type Report struct {
+			gorm.Model
+
+			Kind        string `gorm:"type: VARCHAR(32); not null"`
+			Status      string `gorm:"type: VARCHAR(16); not null; index"`
+			RequestedBy uint   `gorm:"not null"`
+			Since       *time.Time
+			Until       *time.Time
+			Content     []byte
+			Error       string `gorm:"type: VARCHAR(1000); not null; default: ''"`
+			CompletedAt *time.Time
+		}
+
+		return db.AutoMigrate(&Report{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("reports")
+	},
+}
+
+// Analytics events are partitioned by month, the database sink creates the
+// monthly partitions. Events it can't create a partition for end up in the
+// default one.
+var analyticsEventsTable = gormigrate.Migration{
+	ID: "37",
+	Migrate: func(db *gorm.DB) error {
+		// SQLite has no partitions, retention deletes old events instead of
+		// dropping partitions
+		if database.IsSqlite(db) {
+			err := db.Exec(`
+				CREATE TABLE analytics_events (
+					type VARCHAR(32) NOT NULL,
+					project_id BIGINT,
+					query VARCHAR(200) NOT NULL DEFAULT '',
+					occurred_at TIMESTAMPTZ NOT NULL
+				)`).Error
+			if err != nil {
+				return err
+			}
+
+			return db.Exec("CREATE INDEX idx_analytics_events_project ON analytics_events (project_id, occurred_at)").Error
+		}
+
+		err := db.Exec(`
+			CREATE TABLE analytics_events (
+				type VARCHAR(32) NOT NULL,
+				project_id BIGINT,
+				query VARCHAR(200) NOT NULL DEFAULT '',
+				occurred_at TIMESTAMPTZ NOT NULL
+			) PARTITION BY RANGE (occurred_at)`).Error
+		if err != nil {
+			return err
+		}
+
+		err = db.Exec("CREATE TABLE analytics_events_default PARTITION OF analytics_events DEFAULT").Error
+		if err != nil {
+			return err
+		}
+
+		return db.Exec("CREATE INDEX idx_analytics_events_project ON analytics_events (project_id, occurred_at)").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Exec("DROP TABLE analytics_events").Error
+	},
+}
+
+var experimentsTables = gormigrate.Migration{
+	ID: "38",
+	Migrate: func(db *gorm.DB) error {
+		type Experiment struct {
+			gorm.Model
+
+			Key         string         `gorm:"type: VARCHAR(64); not null; uniqueIndex"`
+			Description string         `gorm:"type: VARCHAR(1000); not null; default: ''"`
+			Enabled     bool           `gorm:"not null; default: false"`
+			Variants    pq.StringArray `gorm:"type: TEXT[]; not null"`
+			Weights     pq.Int64Array  `gorm:"type: BIGINT[]; not null"`
+		}
+
+		type ExperimentExposure struct {
+			ExperimentId uint      `gorm:"primaryKey"`
+			Subject      string    `gorm:"type: VARCHAR(80); primaryKey"`
+			Variant      string    `gorm:"type: VARCHAR(32); not null"`
+			ExposedAt    time.Time `gorm:"not null"`
+		}
+
+		return db.AutoMigrate(&Experiment{}, &ExperimentExposure{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("experiment_exposures", "experiments")
+	},
+}
+
+// Encrypted values are longer than their plaintext.
+var encryptedColumns = gormigrate.Migration{
+	ID: "39",
+	Migrate: func(db *gorm.DB) error {
+		// SQLite doesn't enforce VARCHAR lengths
+		if database.IsSqlite(db) {
+			return nil
+		}
+
+		return db.Exec(`
+			ALTER TABLE project_integrations ALTER COLUMN webhook_url TYPE TEXT;
+			ALTER TABLE push_subscriptions ALTER COLUMN p256dh TYPE TEXT, ALTER COLUMN auth TYPE TEXT`).Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		// Encrypted values may not fit the previous types
+		return nil
+	},
+}
+
+// Sessions and the kv store's keys of installs without Redis, see
+// app.Config.KeyValueStore. Created in every install so that it can switch.
+var keyValueTables = gormigrate.Migration{
+	ID: "40",
+	Migrate: func(db *gorm.DB) error {
+		// SQLite installs need Redis, see app.Config.SetDatabaseUrl
+		if database.IsSqlite(db) {
+			return nil
+		}
+
+		return db.Exec(`
+			CREATE TABLE kv_entries (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				expires_at TIMESTAMPTZ
+			);
+			CREATE INDEX idx_kv_entries_expires_at ON kv_entries (expires_at);
+
+			CREATE TABLE kv_list_values (
+				id BIGSERIAL PRIMARY KEY,
+				key TEXT NOT NULL,
+				value TEXT NOT NULL,
+				expires_at TIMESTAMPTZ
+			);
+			CREATE INDEX idx_kv_list_values_key ON kv_list_values (key, id);
+			CREATE INDEX idx_kv_list_values_expires_at ON kv_list_values (expires_at);
+
+			CREATE TABLE sessions (
+				token VARCHAR(36) PRIMARY KEY,
+				user_id BIGINT NOT NULL,
+				impersonator_id BIGINT,
+				created_at TIMESTAMPTZ NOT NULL,
+				expires_at TIMESTAMPTZ NOT NULL
+			);
+			CREATE INDEX idx_sessions_user_id ON sessions (user_id);
+			CREATE INDEX idx_sessions_expires_at ON sessions (expires_at);
+
+			CREATE TABLE session_epochs (
+				user_id BIGINT PRIMARY KEY,
+				epoch TIMESTAMPTZ NOT NULL
+			)`).Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("session_epochs", "sessions", "kv_list_values", "kv_entries")
+	},
+}
+
+var userDirectory = gormigrate.Migration{
+	ID: "41",
+	Migrate: func(db *gorm.DB) error {
+		type User struct {
+			Skills           pq.StringArray `gorm:"type: TEXT[]"`
+			AvailableForWork bool           `gorm:"not null; default: false"`
+			Discoverable     bool           `gorm:"not null; default: false"`
+		}
+
+		err := db.AutoMigrate(&User{})
+		if err != nil {
+			return err
+		}
+
+		if database.IsSqlite(db) {
+			return nil
+		}
+
+		// The directory only lists discoverable users
+		return db.Exec("CREATE INDEX IF NOT EXISTS idx_users_skills ON users USING GIN (skills) WHERE discoverable").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Exec("DROP INDEX IF EXISTS idx_users_skills").Error
+		if err != nil {
+			return err
+		}
+
+		for _, column := range []string{"skills", "available_for_work", "discoverable"} {
+			err = db.Migrator().DropColumn("users", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+// Replaces available_for_work with an availability that can expire. Users
+// who were available for work are open to offers.
+var userAvailability = gormigrate.Migration{
+	ID: "42",
+	Migrate: func(db *gorm.DB) error {
+		type User struct {
+			AvailableForWork      bool
+			Availability          string `gorm:"type: VARCHAR(20); not null; default: unavailable"`
+			AvailabilityExpiresAt *time.Time
+		}
+
+		err := db.AutoMigrate(&User{})
+		if err != nil {
+			return err
+		}
+
+		err = db.Exec("UPDATE users SET availability = 'open-to-offers' WHERE available_for_work").Error
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropColumn(&User{}, "available_for_work")
+	},
+	Rollback: func(db *gorm.DB) error {
+		type User struct {
+			AvailableForWork      bool `gorm:"not null; default: false"`
+			Availability          string
+			AvailabilityExpiresAt *time.Time
+		}
+
+		err := db.AutoMigrate(&User{})
+		if err != nil {
+			return err
+		}
+
+		err = db.Exec("UPDATE users SET available_for_work = true WHERE availability <> 'unavailable'").Error
+		if err != nil {
+			return err
+		}
+
+		for _, column := range []string{"availability", "availability_expires_at"} {
+			err = db.Migrator().DropColumn(&User{}, column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var portfolioItemsTable = gormigrate.Migration{
+	ID: "43",
+	Migrate: func(db *gorm.DB) error {
+		type PortfolioItem struct {
+			gorm.Model
+
+			UserId       uint   `gorm:"not null; index"`
+			Title        string `gorm:"type: VARCHAR(100); not null"`
+			Description  string `gorm:"type: VARCHAR(1000); not null; default: ''"`
+			Url          string `gorm:"type: VARCHAR(500); not null"`
+			ImageUrl     string `gorm:"type: VARCHAR(500); not null; default: ''"`
+			Position     int    `gorm:"not null"`
+			Hidden       bool   `gorm:"not null; default: false"`
+			HiddenReason string `gorm:"type: VARCHAR(500); not null; default: ''"`
+		}
+
+		return db.AutoMigrate(&PortfolioItem{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("portfolio_items")
+	},
+}
+
+var userPrivacySettings = gormigrate.Migration{
+	ID: "44",
+	Migrate: func(db *gorm.DB) error {
+		type User struct {
+			HideEmail                bool `gorm:"not null; default: true"`
+			HideProfileFromAnonymous bool `gorm:"not null; default: false"`
+			HideMemberships          bool `gorm:"not null; default: false"`
+		}
+
+		return db.AutoMigrate(&User{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		for _, column := range []string{"hide_email", "hide_profile_from_anonymous", "hide_memberships"} {
+			err := db.Migrator().DropColumn("users", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var projectReadmes = gormigrate.Migration{
+	ID: "45",
+	Migrate: func(db *gorm.DB) error {
+		type Project struct {
+			ReadmeSync     bool   `gorm:"not null; default: false"`
+			Readme         string `gorm:"not null; default: ''"`
+			ReadmeSyncedAt *time.Time
+		}
+
+		return db.AutoMigrate(&Project{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		for _, column := range []string{"readme_sync", "readme", "readme_synced_at"} {
+			err := db.Migrator().DropColumn("projects", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var applicationExpiry = gormigrate.Migration{
+	ID: "46",
+	Migrate: func(db *gorm.DB) error {
+		type Application struct {
+			ExpiresAt  *time.Time
+			ReviewedAt *time.Time
+		}
+
+		err := db.AutoMigrate(&Application{})
+		if err != nil {
+			return err
+		}
+
+		// Applications were last updated when they were reviewed, unless an
+		// interview was scheduled after
+		err = db.Exec("UPDATE applications SET reviewed_at = updated_at WHERE status IN ('accepted', 'rejected')").Error
+		if err != nil {
+			return err
+		}
+
+		// The expiry job looks for pending applications past their expiry
+		return db.Exec("CREATE INDEX IF NOT EXISTS idx_applications_expires_at ON applications (expires_at) WHERE status = 'pending'").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		for _, column := range []string{"expires_at", "reviewed_at"} {
+			err := db.Migrator().DropColumn("applications", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var analyticsEventRoles = gormigrate.Migration{
+	ID: "47",
+	Migrate: func(db *gorm.DB) error {
+		// Added to the partitions too
+		return db.Exec("ALTER TABLE analytics_events ADD COLUMN role_id BIGINT").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropColumn("analytics_events", "role_id")
+	},
+}
+
+var projectSpokenLanguages = gormigrate.Migration{
+	ID: "48",
+	Migrate: func(db *gorm.DB) error {
+		// Existing projects don't have spoken languages until they're saved
+		// again, when they're detected if their owner doesn't declare them
+		type Project struct {
+			SpokenLanguages         pq.StringArray `gorm:"type: TEXT[]; not null; default: '{}'"`
+			SpokenLanguagesDetected bool           `gorm:"not null; default: false"`
+		}
+
+		err := db.AutoMigrate(&Project{})
+		if err != nil || database.IsSqlite(db) {
+			return err
+		}
+
+		return db.Exec("CREATE INDEX IF NOT EXISTS idx_projects_spoken_languages ON projects USING GIN (spoken_languages)").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		for _, column := range []string{"spoken_languages", "spoken_languages_detected"} {
+			err := db.Migrator().DropColumn("projects", column)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
+
+var projectTimeZones = gormigrate.Migration{
+	ID: "49",
+	Migrate: func(db *gorm.DB) error {
+		type Project struct {
+			TimeZones pq.StringArray `gorm:"type: TEXT[]; not null; default: '{}'"`
+		}
+
+		return db.AutoMigrate(&Project{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropColumn("projects", "time_zones")
+	},
+}
+
+var broadcastsTable = gormigrate.Migration{
+	ID: "50",
+	Migrate: func(db *gorm.DB) error {
+		type Broadcast struct {
+			gorm.Model
+
+			CreatedBy   uint   `gorm:"not null"`
+			Audience    string `gorm:"type: VARCHAR(16); not null"`
+			Target      string `gorm:"type: VARCHAR(40); not null; default: ''"`
+			Title       string `gorm:"not null"`
+			Body        string `gorm:"not null"`
+			Email       bool   `gorm:"not null; default: false"`
+			Status      string `gorm:"type: VARCHAR(16); not null; index"`
+			Recipients  int    `gorm:"not null; default: 0"`
+			LastUserId  uint   `gorm:"not null; default: 0"`
+			Delivered   int    `gorm:"not null; default: 0"`
+			CancelledBy *uint
+			CompletedAt *time.Time
+		}
+
+		return db.AutoMigrate(&Broadcast{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("broadcasts")
+	},
+}
+
+var usageTables = gormigrate.Migration{
+	ID: "51",
+	Migrate: func(db *gorm.DB) error {
+		type DailyUsage struct {
+			UserId   uint   `gorm:"primaryKey"`
+			Day      string `gorm:"type: VARCHAR(10); primaryKey; index"`
+			Requests int64  `gorm:"not null; default: 0"`
+		}
+
+		type Quota struct {
+			UserId        uint  `gorm:"primaryKey"`
+			DailyRequests int64 `gorm:"not null"`
+			SetBy         uint  `gorm:"not null"`
+			UpdatedAt     time.Time
+		}
+
+		err := db.Table("daily_usage").AutoMigrate(&DailyUsage{})
+		if err != nil {
+			return err
+		}
+
+		return db.Table("usage_quotas").AutoMigrate(&Quota{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropTable("usage_quotas")
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropTable("daily_usage")
+	},
+}
+
+var chatTables = gormigrate.Migration{
+	ID: "52",
+	Migrate: func(db *gorm.DB) error {
+		type Channel struct {
+			ProjectId uint `gorm:"primaryKey"`
+			Enabled   bool `gorm:"not null; default: false"`
+			UpdatedAt time.Time
+		}
+
+		type Message struct {
+			gorm.Model
+			ProjectId uint          `gorm:"not null; index"`
+			AuthorId  uint          `gorm:"not null"`
+			Body      string        `gorm:"type: TEXT; not null"`
+			Mentions  pq.Int64Array `gorm:"type: BIGINT[]; not null"`
+			Hidden    bool          `gorm:"not null; default: false"`
+			DeletedBy *uint
+		}
+
+		type Mute struct {
+			ProjectId uint `gorm:"primaryKey"`
+			UserId    uint `gorm:"primaryKey"`
+			MutedBy   uint `gorm:"not null"`
+			Until     *time.Time
+			CreatedAt time.Time
+		}
+
+		err := db.Table("chat_channels").AutoMigrate(&Channel{})
+		if err != nil {
+			return err
+		}
+
+		err = db.Table("chat_messages").AutoMigrate(&Message{})
+		if err != nil {
+			return err
+		}
+
+		return db.Table("chat_mutes").AutoMigrate(&Mute{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		err := db.Migrator().DropTable("chat_mutes")
+		if err != nil {
+			return err
+		}
+
+		err = db.Migrator().DropTable("chat_messages")
+		if err != nil {
+			return err
+		}
+
+		return db.Migrator().DropTable("chat_channels")
+	},
+}
+
+var projectMemberPermissionsTable = gormigrate.Migration{
+	ID: "53",
+	Migrate: func(db *gorm.DB) error {
+		type MemberPermissions struct {
+			ProjectId   uint           `gorm:"primaryKey"`
+			UserId      uint           `gorm:"primaryKey"`
+			Permissions pq.StringArray `gorm:"type: TEXT[]; not null"`
+			GrantedBy   uint           `gorm:"not null"`
+			UpdatedAt   time.Time
+		}
+
+		return db.Table("project_member_permissions").AutoMigrate(&MemberPermissions{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("project_member_permissions")
+	},
+}
+
+var projectExportsTable = gormigrate.Migration{
+	ID: "54",
+	Migrate: func(db *gorm.DB) error {
+		type Export struct {
+			gorm.Model
+			ProjectId   uint   `gorm:"not null; index"`
+			RequestedBy uint   `gorm:"not null"`
+			Format      string `gorm:"type: VARCHAR(8); not null"`
+			Status      string `gorm:"type: VARCHAR(16); not null; index"`
+			Content     []byte
+			Error       string `gorm:"type: TEXT; not null; default: ''"`
+			CompletedAt *time.Time
+		}
+
+		return db.Table("project_exports").AutoMigrate(&Export{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("project_exports")
+	},
+}
+
+var sockpuppetsTables = gormigrate.Migration{
+	ID: "55",
+	Migrate: func(db *gorm.DB) error {
+		type Sighting struct {
+			UserId      uint      `gorm:"primaryKey"`
+			Kind        string    `gorm:"primaryKey; type: VARCHAR(16)"`
+			ValueHash   string    `gorm:"primaryKey; type: VARCHAR(64)"`
+			FirstSeenAt time.Time `gorm:"not null"`
+			LastSeenAt  time.Time `gorm:"not null; index"`
+		}
+
+		type Review struct {
+			UserId      uint   `gorm:"primaryKey"`
+			OtherUserId uint   `gorm:"primaryKey"`
+			Verdict     string `gorm:"type: VARCHAR(16); not null"`
+			Note        string `gorm:"type: TEXT; not null; default: ''"`
+			ReviewedBy  uint   `gorm:"not null"`
+			UpdatedAt   time.Time
+		}
+
+		err := db.Table("account_sightings").AutoMigrate(&Sighting{})
+		if err != nil {
+			return err
+		}
+
+		// Accounts sharing a value are found by it
+		err = db.Exec("CREATE INDEX IF NOT EXISTS idx_account_sightings_value ON account_sightings (kind, value_hash)").Error
+		if err != nil {
+			return err
+		}
+
+		return db.Table("sockpuppet_reviews").AutoMigrate(&Review{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("account_sightings", "sockpuppet_reviews")
+	},
+}
+
+var projectQuestionsTable = gormigrate.Migration{
+	ID: "56",
+	Migrate: func(db *gorm.DB) error {
+		type ProjectQuestion struct {
+			gorm.Model
+			ProjectId uint   `gorm:"not null; index"`
+			Text      string `gorm:"type: TEXT; not null"`
+			Required  bool   `gorm:"not null; default: false"`
+		}
+
+		return db.Table("project_questions").AutoMigrate(&ProjectQuestion{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropTable("project_questions")
+	},
+}
+
+var hiddenApplications = gormigrate.Migration{
+	ID: "57",
+	Migrate: func(db *gorm.DB) error {
+		type Application struct {
+			Hidden bool `gorm:"not null; default: false"`
+		}
+
+		return db.AutoMigrate(&Application{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropColumn("applications", "hidden")
+	},
+}
+
+var portfolioItemsPendingReview = gormigrate.Migration{
+	ID: "58",
+	Migrate: func(db *gorm.DB) error {
+		type PortfolioItem struct {
+			PendingReview bool `gorm:"not null; default: false"`
+		}
+
+		return db.AutoMigrate(&PortfolioItem{})
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Migrator().DropColumn("portfolio_items", "pending_review")
+	},
+}
+
+var identitiesUserProviderIndex = gormigrate.Migration{
+	ID: "59",
+	Migrate: func(db *gorm.DB) error {
+		// Linking used to only reject identities linked to another user, so
+		// users could link several accounts of a provider. Keep the first.
+		err := db.Exec(`
+			DELETE FROM identities
+			WHERE id NOT IN (SELECT min(id) FROM identities GROUP BY user_id, provider)`,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		return db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_identities_user_provider ON identities (user_id, provider)").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Exec("DROP INDEX IF EXISTS idx_identities_user_provider").Error
+	},
+}
+
+var qualityScoreWithoutActivity = gormigrate.Migration{
+	ID: "60",
+	Migrate: func(db *gorm.DB) error {
+		// Stored scores included the activity points, which projects always
+		// got when they were saved. They're now added when ranking.
+		return db.Exec("UPDATE projects SET quality_score = quality_score - 10 WHERE quality_score >= 10").Error
+	},
+	Rollback: func(db *gorm.DB) error {
+		return db.Exec("UPDATE projects SET quality_score = quality_score + 10").Error
+	},
+}
+
+// All migrations, in the order they're run.
+var allMigrations = []*gormigrate.Migration{
+	&usersTable,
+	&projectsTable,
+	&userRoles,
+	&blocklistEntriesTable,
+	&invitesTable,
+	&waitlistEntriesTable,
+	&identitiesTable,
+	&auditLogTable,
+	&restrictionsTable,
+	&projectOwnersAndReview,
+	&notificationsTable,
+	&applicationsTable,
+	&projectQualityAndRoles,
+	&projectTagsIndex,
+	&projectSimilarityIndexes,
+	&projectSearch,
+	&savedSearchesTables,
+	&homepageTables,
+	&bannedTagsTable,
+	&notificationPreferencesTable,
+	&pushSubscriptionsTable,
+	&pushDevicesTable,
+	&projectIntegrationsTable,
+	&calendarTables,
+	&userSettings,
+	&projectLicenses,
+	&projectTechStack,
+	&projectStatus,
+	&roleCommitment,
+	&screeningQuestions,
+	&interviewScheduling,
+	&contributionsTable,
+	&projectDrafts,
+	&collectionsTables,
+	&projectFundingLinks,
+	&projectExternalLinks,
+	&reportsTable,
+	&analyticsEventsTable,
+	&experimentsTables,
+	&encryptedColumns,
+	&keyValueTables,
+	&userDirectory,
+	&userAvailability,
+	&portfolioItemsTable,
+	&userPrivacySettings,
+	&projectReadmes,
+	&applicationExpiry,
+	&analyticsEventRoles,
+	&projectSpokenLanguages,
+	&projectTimeZones,
+	&broadcastsTable,
+	&usageTables,
+	&chatTables,
+	&projectMemberPermissionsTable,
+	&projectExportsTable,
+	&sockpuppetsTables,
+	&projectQuestionsTable,
+	&hiddenApplications,
+	&portfolioItemsPendingReview,
+	&identitiesUserProviderIndex,
+	&qualityScoreWithoutActivity,
+}
+
 func GetMigration(db *gorm.DB) *gormigrate.Gormigrate {
-	return gormigrate.New(db, gormigrate.DefaultOptions, []*gormigrate.Migration{
-		&usersTable,
-		&projectsTable,
-	})
+	return gormigrate.New(db, gormigrate.DefaultOptions, allMigrations)
 }
@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"strings"
+)
+
+var ErrSchemaMismatch = errors.New("database schema doesn't match this version")
+
+// Check that the database was migrated by exactly this version's migrations.
+// Returns ErrSchemaMismatch if some migrations weren't run, or if the
+// database was migrated by a newer version (e.g. after a rollback).
+func CheckSchema(ctx context.Context, db *gorm.DB) error {
+	var applied []string
+	result := db.WithContext(ctx).
+		Table(gormigrate.DefaultOptions.TableName).
+		Pluck(gormigrate.DefaultOptions.IDColumnName, &applied)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	known := map[string]bool{}
+	for _, migration := range allMigrations {
+		known[migration.ID] = true
+	}
+
+	var unknown []string
+	for _, id := range applied {
+		if !known[id] {
+			unknown = append(unknown, id)
+		}
+		delete(known, id)
+	}
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("%w: the database has migrations this version doesn't know (%s)", ErrSchemaMismatch, strings.Join(unknown, ", "))
+	}
+
+	if len(known) > 0 {
+		var missing []string
+		for _, migration := range allMigrations {
+			if known[migration.ID] {
+				missing = append(missing, migration.ID)
+			}
+		}
+
+		return fmt.Errorf("%w: these migrations weren't run: %s", ErrSchemaMismatch, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
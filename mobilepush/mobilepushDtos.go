@@ -0,0 +1,11 @@
+package mobilepush
+
+type NewDeviceDto struct {
+	Platform Platform `json:"platform" validate:"required,oneof=android ios"`
+	Token    string   `json:"token" validate:"required,max=500"`
+}
+
+type DeviceDto struct {
+	Id       uint     `json:"id"`
+	Platform Platform `json:"platform"`
+}
@@ -0,0 +1,32 @@
+package mobilepush
+
+import "gorm.io/gorm"
+
+// The platform of a mobile device, which decides the provider its push
+// notifications are sent through.
+type Platform string
+
+const (
+	// Android devices, through Firebase Cloud Messaging.
+	PlatformAndroid Platform = "android"
+
+	// iOS devices, through the Apple Push Notification service.
+	PlatformIos Platform = "ios"
+)
+
+// A mobile app installation's push token, registered by the app when the
+// user logs in.
+type Device struct {
+	gorm.Model
+
+	UserId   uint
+	Platform Platform
+
+	// The FCM registration token or APNs device token. Unique per app
+	// installation.
+	Token string
+}
+
+func (Device) TableName() string {
+	return "push_devices"
+}
@@ -0,0 +1,71 @@
+package mobilepush
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Register a mobile device for push notifications
+// @Description Notifications the user enabled for the "push" channel are sent to the device.
+// @Tags notifications
+// @Router /users/me/devices [post]
+// @Param device body mobilepush.NewDeviceDto true "The device's platform and push token"
+// @Success 201 {object} mobilepush.DeviceDto
+// @Failure 400 "Push notifications are disabled for the platform"
+// @Failure 401
+func RouteRegisterDevice(
+	writer http.ResponseWriter,
+	request *http.Request,
+	mobilepushService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := NewDeviceDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	device, err := mobilepushService.RegisterDevice(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, device)
+}
+
+// @Summary Unregister a mobile device
+// @Tags notifications
+// @Router /users/me/devices/{deviceId} [delete]
+// @Param deviceId path int true "The device ID"
+// @Success 204
+// @Failure 401
+// @Failure 404
+func RouteUnregisterDevice(
+	writer http.ResponseWriter,
+	request *http.Request,
+	mobilepushService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	deviceId, err := utils.UintFromVars(request, "deviceId")
+	if err != nil {
+		return err
+	}
+
+	err = mobilepushService.UnregisterDevice(request.Context(), session.UserId(), deviceId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package mobilepush
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrPlatformDisabled = errors.New("push notifications are disabled for the platform")
+var ErrDeviceNotFound = errors.New("device not found")
+
+type Service interface {
+	// The name of the channel the service delivers notifications on,
+	// notifications.ChannelPush.
+	Name() string
+
+	// Push a notification to all of a user's devices. Devices whose token
+	// was rejected are unregistered.
+	Deliver(ctx context.Context, userId uint, notification notifications.NewNotificationDto) error
+
+	// Register a device for a user. A token registered again (e.g. after
+	// another user logged in on the device) is moved to the user.
+	// Returns ErrPlatformDisabled if no provider is configured for the
+	// device's platform.
+	RegisterDevice(ctx context.Context, userId uint, dto NewDeviceDto) (DeviceDto, error)
+
+	// Unregister one of a user's devices, e.g. when they log out.
+	// Returns ErrDeviceNotFound if the user doesn't have the device.
+	UnregisterDevice(ctx context.Context, userId uint, deviceId uint) error
+}
+
+type serviceImpl struct {
+	Db        *gorm.DB
+	Providers map[Platform]Provider
+}
+
+// Create a mobile push service sending notifications through the given
+// providers, at most one per platform.
+func NewService(db *gorm.DB, providers ...Provider) Service {
+	service := &serviceImpl{
+		Db:        db,
+		Providers: map[Platform]Provider{},
+	}
+
+	for _, provider := range providers {
+		service.Providers[provider.Platform()] = provider
+	}
+
+	return service
+}
+
+func (s *serviceImpl) Name() string {
+	return notifications.ChannelPush
+}
+
+func (s *serviceImpl) RegisterDevice(ctx context.Context, userId uint, dto NewDeviceDto) (DeviceDto, error) {
+	if _, ok := s.Providers[dto.Platform]; !ok {
+		return DeviceDto{}, ErrPlatformDisabled
+	}
+
+	device := Device{
+		UserId:   userId,
+		Platform: dto.Platform,
+		Token:    dto.Token,
+	}
+
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "token"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "updated_at"}),
+		}).
+		Create(&device)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to register device")
+
+		return DeviceDto{}, result.Error
+	}
+
+	return DeviceDto{
+		Id:       device.ID,
+		Platform: device.Platform,
+	}, nil
+}
+
+func (s *serviceImpl) UnregisterDevice(ctx context.Context, userId uint, deviceId uint) error {
+	result := s.Db.WithContext(ctx).
+		Unscoped().
+		Where("id = ? AND user_id = ?", deviceId, userId).
+		Delete(&Device{})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to unregister device")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrDeviceNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) Deliver(ctx context.Context, userId uint, notification notifications.NewNotificationDto) error {
+	logger := log.FromContext(ctx)
+
+	var devices []Device
+	result := s.Db.WithContext(ctx).Where("user_id = ?", userId).Find(&devices)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	data := map[string]interface{}{}
+	for key, value := range notification.Data {
+		data[key] = value
+	}
+	data["type"] = notification.Type
+
+	message := Message{
+		Title: notification.Title,
+		Body:  notification.Body,
+		Data:  data,
+	}
+
+	for _, device := range devices {
+		deviceLogger := logger.WithFields(log.Fields{
+			"deviceId": device.ID,
+			"platform": device.Platform,
+		})
+
+		provider, ok := s.Providers[device.Platform]
+		if !ok {
+			continue
+		}
+
+		err := provider.Send(ctx, device.Token, message)
+		if errors.Is(err, ErrInvalidToken) {
+			deviceLogger.Info("Device token was rejected, unregistering the device")
+
+			result = s.Db.WithContext(ctx).Unscoped().Delete(&Device{}, device.ID)
+			if result.Error != nil {
+				deviceLogger.WithError(result.Error).Error("Failed to unregister device")
+			}
+		} else if err != nil {
+			// Keep sending to the other devices
+			deviceLogger.WithError(err).Error("Failed to send push notification")
+		}
+	}
+
+	return nil
+}
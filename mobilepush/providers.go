@@ -0,0 +1,404 @@
+package mobilepush
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Returned by providers when a device token is no longer valid, e.g. because
+// the app was uninstalled.
+var ErrInvalidToken = errors.New("invalid device token")
+
+// A push notification.
+type Message struct {
+	Title string
+	Body  string
+
+	// Custom data for the app
+	Data map[string]interface{}
+}
+
+// A Provider sends push notifications to the devices of a platform.
+type Provider interface {
+	Platform() Platform
+
+	// Send a notification to a device.
+	// Returns ErrInvalidToken if the provider rejects the device's token.
+	Send(ctx context.Context, token string, message Message) error
+}
+
+// Sends notifications through the Firebase Cloud Messaging HTTP v1 API,
+// authenticated with a service account.
+type fcmProvider struct {
+	ProjectId   string
+	ClientEmail string
+	TokenUri    string
+	PrivateKey  *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// Create an FCM provider from a service account key file, as downloaded from
+// the Firebase console.
+func NewFcmProvider(credentialsFile string) (Provider, error) {
+	content, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := struct {
+		ProjectId   string `json:"project_id"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenUri    string `json:"token_uri"`
+	}{}
+
+	err = json.Unmarshal(content, &credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parsePrivateKey([]byte(credentials.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("fcm private key isn't an RSA key")
+	}
+
+	return &fcmProvider{
+		ProjectId:   credentials.ProjectId,
+		ClientEmail: credentials.ClientEmail,
+		TokenUri:    credentials.TokenUri,
+		PrivateKey:  rsaKey,
+	}, nil
+}
+
+func (p *fcmProvider) Platform() Platform {
+	return PlatformAndroid
+}
+
+func (p *fcmProvider) Send(ctx context.Context, token string, message Message) error {
+	accessToken, err := p.getAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	// FCM data values have to be strings
+	data := map[string]string{}
+	for key, value := range message.Data {
+		data[key] = fmt.Sprint(value)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token": token,
+			"notification": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+			"data": data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	sendUrl := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", p.ProjectId)
+	request, err := http.NewRequestWithContext(ctx, "POST", sendUrl, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusOK:
+		return nil
+	case response.StatusCode == http.StatusNotFound:
+		// UNREGISTERED: the app was uninstalled or the token expired
+		return ErrInvalidToken
+	default:
+		return fmt.Errorf("fcm request failed with status %d", response.StatusCode)
+	}
+}
+
+// Get an OAuth access token for the service account, cached until shortly
+// before it expires.
+func (p *fcmProvider) getAccessToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.accessToken != "" && time.Now().Before(p.expiresAt) {
+		return p.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := signJwt(
+		map[string]interface{}{"alg": "RS256", "typ": "JWT"},
+		map[string]interface{}{
+			"iss":   p.ClientEmail,
+			"scope": "https://www.googleapis.com/auth/firebase.messaging",
+			"aud":   p.TokenUri,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		},
+		func(hash []byte) ([]byte, error) {
+			return rsa.SignPKCS1v15(rand.Reader, p.PrivateKey, crypto.SHA256, hash)
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", p.TokenUri, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token request failed with status %d", response.StatusCode)
+	}
+
+	result := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+
+	p.accessToken = result.AccessToken
+	p.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+
+	return p.accessToken, nil
+}
+
+// APNs accepts a provider token for up to an hour, and rejects tokens that
+// are refreshed more often than every 20 minutes.
+const apnsTokenDuration = 50 * time.Minute
+
+// Sends notifications through the Apple Push Notification service,
+// authenticated with a token signing key (.p8 file).
+type apnsProvider struct {
+	Host   string
+	KeyId  string
+	TeamId string
+
+	// The app's bundle id
+	Topic string
+
+	PrivateKey *ecdsa.PrivateKey
+
+	mu          sync.Mutex
+	token       string
+	tokenIssued time.Time
+}
+
+// Create an APNs provider. If sandbox is true notifications are sent to
+// development builds of the app.
+func NewApnsProvider(keyFile string, keyId string, teamId string, topic string, sandbox bool) (Provider, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := parsePrivateKey(content)
+	if err != nil {
+		return nil, err
+	}
+
+	ecdsaKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("apns key isn't an ECDSA key")
+	}
+
+	host := "https://api.push.apple.com"
+	if sandbox {
+		host = "https://api.sandbox.push.apple.com"
+	}
+
+	return &apnsProvider{
+		Host:       host,
+		KeyId:      keyId,
+		TeamId:     teamId,
+		Topic:      topic,
+		PrivateKey: ecdsaKey,
+	}, nil
+}
+
+func (p *apnsProvider) Platform() Platform {
+	return PlatformIos
+}
+
+func (p *apnsProvider) Send(ctx context.Context, token string, message Message) error {
+	providerToken, err := p.getProviderToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{
+				"title": message.Title,
+				"body":  message.Body,
+			},
+		},
+	}
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	// APNs only accepts HTTP/2, which net/http negotiates over TLS
+	request, err := http.NewRequestWithContext(ctx, "POST", p.Host+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "bearer "+providerToken)
+	request.Header.Set("apns-topic", p.Topic)
+	request.Header.Set("apns-push-type", "alert")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	result := struct {
+		Reason string `json:"reason"`
+	}{}
+	_ = json.NewDecoder(response.Body).Decode(&result)
+
+	if response.StatusCode == http.StatusGone || result.Reason == "BadDeviceToken" || result.Reason == "DeviceTokenNotForTopic" {
+		return ErrInvalidToken
+	}
+
+	return fmt.Errorf("apns request failed with status %d: %s", response.StatusCode, result.Reason)
+}
+
+func (p *apnsProvider) getProviderToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.token != "" && now.Sub(p.tokenIssued) < apnsTokenDuration {
+		return p.token, nil
+	}
+
+	token, err := signJwt(
+		map[string]interface{}{"alg": "ES256", "kid": p.KeyId},
+		map[string]interface{}{"iss": p.TeamId, "iat": now.Unix()},
+		func(hash []byte) ([]byte, error) {
+			r, s, err := ecdsa.Sign(rand.Reader, p.PrivateKey, hash)
+			if err != nil {
+				return nil, err
+			}
+
+			// ES256 signatures are r and s as 32 byte big endian integers
+			signature := make([]byte, 64)
+			r.FillBytes(signature[:32])
+			s.FillBytes(signature[32:])
+
+			return signature, nil
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	p.token = token
+	p.tokenIssued = now
+
+	return token, nil
+}
+
+// Create a JWT signed by sign, which receives the SHA-256 hash of the signed
+// content.
+func signJwt(header map[string]interface{}, claims map[string]interface{}, sign func(hash []byte) ([]byte, error)) (string, error) {
+	headerJson, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+
+	claimsJson, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(headerJson) + "." + base64.RawURLEncoding.EncodeToString(claimsJson)
+	hash := sha256.Sum256([]byte(unsigned))
+
+	signature, err := sign(hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Parse a PEM encoded PKCS #8 private key.
+func parsePrivateKey(content []byte) (interface{}, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("private key isn't PEM encoded")
+	}
+
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
@@ -0,0 +1,124 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: moderationService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	auth "github.com/open-collaboration/server/auth"
+	moderation "github.com/open-collaboration/server/moderation"
+	reflect "reflect"
+	time "time"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// GetAccountStatus mocks base method
+func (m *MockService) GetAccountStatus(ctx context.Context, userId uint) (auth.AccountStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountStatus", ctx, userId)
+	ret0, _ := ret[0].(auth.AccountStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAccountStatus indicates an expected call of GetAccountStatus
+func (mr *MockServiceMockRecorder) GetAccountStatus(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountStatus", reflect.TypeOf((*MockService)(nil).GetAccountStatus), ctx, userId)
+}
+
+// CheckPostingCooldown mocks base method
+func (m *MockService) CheckPostingCooldown(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckPostingCooldown", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckPostingCooldown indicates an expected call of CheckPostingCooldown
+func (mr *MockServiceMockRecorder) CheckPostingCooldown(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckPostingCooldown", reflect.TypeOf((*MockService)(nil).CheckPostingCooldown), ctx, userId)
+}
+
+// StartPostingCooldown mocks base method
+func (m *MockService) StartPostingCooldown(ctx context.Context, userId uint, cooldown time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartPostingCooldown", ctx, userId, cooldown)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartPostingCooldown indicates an expected call of StartPostingCooldown
+func (mr *MockServiceMockRecorder) StartPostingCooldown(ctx, userId, cooldown interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartPostingCooldown", reflect.TypeOf((*MockService)(nil).StartPostingCooldown), ctx, userId, cooldown)
+}
+
+// RestrictUser mocks base method
+func (m *MockService) RestrictUser(ctx context.Context, moderatorId, userId uint, dto moderation.NewRestrictionDto) (moderation.RestrictionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestrictUser", ctx, moderatorId, userId, dto)
+	ret0, _ := ret[0].(moderation.RestrictionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestrictUser indicates an expected call of RestrictUser
+func (mr *MockServiceMockRecorder) RestrictUser(ctx, moderatorId, userId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestrictUser", reflect.TypeOf((*MockService)(nil).RestrictUser), ctx, moderatorId, userId, dto)
+}
+
+// ListRestrictions mocks base method
+func (m *MockService) ListRestrictions(ctx context.Context, userId uint) ([]moderation.RestrictionDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRestrictions", ctx, userId)
+	ret0, _ := ret[0].([]moderation.RestrictionDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRestrictions indicates an expected call of ListRestrictions
+func (mr *MockServiceMockRecorder) ListRestrictions(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRestrictions", reflect.TypeOf((*MockService)(nil).ListRestrictions), ctx, userId)
+}
+
+// LiftRestriction mocks base method
+func (m *MockService) LiftRestriction(ctx context.Context, moderatorId, restrictionId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LiftRestriction", ctx, moderatorId, restrictionId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LiftRestriction indicates an expected call of LiftRestriction
+func (mr *MockServiceMockRecorder) LiftRestriction(ctx, moderatorId, restrictionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LiftRestriction", reflect.TypeOf((*MockService)(nil).LiftRestriction), ctx, moderatorId, restrictionId)
+}
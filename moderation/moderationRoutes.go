@@ -0,0 +1,115 @@
+package moderation
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Restrict a user
+// @Description Places a read-only, posting cooldown or shadow restriction on a user.
+// @Tags moderation
+// @Router /moderation/users/{userId}/restrictions [post]
+// @Param userId path int true "The user ID"
+// @Param restriction body moderation.NewRestrictionDto true "The restriction"
+// @Success 201 {object} moderation.RestrictionDto
+// @Failure 403
+func RouteRestrictUser(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	moderationService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	// Make sure the user exists
+	_, err = usersService.GetUser(request.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	dto := NewRestrictionDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	restriction, err := moderationService.RestrictUser(request.Context(), session.UserId(), userId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, restriction)
+}
+
+// @Summary List a user's active restrictions
+// @Tags moderation
+// @Router /moderation/users/{userId}/restrictions [get]
+// @Param userId path int true "The user ID"
+// @Success 200 {array} moderation.RestrictionDto
+// @Failure 403
+func RouteListRestrictions(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	moderationService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	restrictions, err := moderationService.ListRestrictions(request.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, restrictions)
+}
+
+// @Summary Lift a restriction
+// @Tags moderation
+// @Router /moderation/restrictions/{restrictionId} [delete]
+// @Param restrictionId path int true "The restriction ID"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteLiftRestriction(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	moderationService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	restrictionId, err := utils.UintFromVars(request, "restrictionId")
+	if err != nil {
+		return err
+	}
+
+	err = moderationService.LiftRestriction(request.Context(), session.UserId(), restrictionId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
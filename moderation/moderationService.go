@@ -0,0 +1,299 @@
+// NOTE: take a look at the projects redis documentation (docs/redis.md)
+// to better understand how account statuses and cooldowns are stored.
+
+package moderation
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/kv"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrRestrictionNotFound = errors.New("restriction not found")
+
+type Service interface {
+	// Get the restrictions currently placed on a user, combined.
+	GetAccountStatus(ctx context.Context, userId uint) (auth.AccountStatus, error)
+
+	// Returns auth.ErrPostingCooldown if the user's posting cooldown has not
+	// elapsed yet.
+	CheckPostingCooldown(ctx context.Context, userId uint) error
+
+	// Start a user's posting cooldown.
+	// Returns auth.ErrPostingCooldown if the previous cooldown has not elapsed yet.
+	StartPostingCooldown(ctx context.Context, userId uint, cooldown time.Duration) error
+
+	// Restrict a user. The action is recorded in the audit log.
+	RestrictUser(ctx context.Context, moderatorId uint, userId uint, dto NewRestrictionDto) (RestrictionDto, error)
+
+	// List a user's active restrictions, newest to oldest.
+	ListRestrictions(ctx context.Context, userId uint) ([]RestrictionDto, error)
+
+	// Lift a restriction before it expires. The action is recorded in the audit log.
+	// Returns ErrRestrictionNotFound if the restriction doesn't exist.
+	LiftRestriction(ctx context.Context, moderatorId uint, restrictionId uint) error
+}
+
+type serviceImpl struct {
+	Db           *gorm.DB
+	Kv           kv.Store
+	AuditService audit.Service
+}
+
+func NewService(db *gorm.DB, kvStore kv.Store, auditService audit.Service) Service {
+	return &serviceImpl{
+		Db:           db,
+		Kv:           kvStore,
+		AuditService: auditService,
+	}
+}
+
+// How long account statuses are cached. Statuses are checked on
+// every request that changes data, so we don't want to hit the
+// database every time.
+const statusCacheDuration = 5 * time.Minute
+
+func (s *serviceImpl) GetAccountStatus(ctx context.Context, userId uint) (auth.AccountStatus, error) {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	status := auth.AccountStatus{}
+
+	cached, err := s.Kv.Get(ctx, accountStatusRedisKey(userId))
+	if err == nil {
+		err = json.Unmarshal([]byte(cached), &status)
+		if err == nil {
+			return status, nil
+		}
+
+		logger.WithError(err).Warn("Failed to unmarshal cached account status")
+	} else if !errors.Is(err, kv.ErrNotFound) {
+		logger.WithError(err).Warn("Failed to get cached account status, falling back to the database")
+	}
+
+	restrictions, err := s.activeRestrictions(ctx, userId)
+	if err != nil {
+		return auth.AccountStatus{}, err
+	}
+
+	// The cache must not outlive the restriction that expires first
+	cacheDuration := statusCacheDuration
+
+	for _, restriction := range restrictions {
+		switch restriction.Kind {
+		case RestrictionReadOnly:
+			status.ReadOnly = true
+		case RestrictionShadow:
+			status.ShadowHidden = true
+		case RestrictionCooldown:
+			cooldown := time.Duration(restriction.CooldownSeconds) * time.Second
+			if cooldown > status.PostingCooldown {
+				status.PostingCooldown = cooldown
+			}
+		}
+
+		if restriction.ExpiresAt != nil && time.Until(*restriction.ExpiresAt) < cacheDuration {
+			cacheDuration = time.Until(*restriction.ExpiresAt)
+		}
+	}
+
+	encoded, err := json.Marshal(status)
+	if err == nil && cacheDuration > 0 {
+		err = s.Kv.Set(ctx, accountStatusRedisKey(userId), string(encoded), cacheDuration)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to cache account status")
+	}
+
+	return status, nil
+}
+
+func (s *serviceImpl) CheckPostingCooldown(ctx context.Context, userId uint) error {
+	exists, err := s.Kv.Exists(ctx, postingCooldownRedisKey(userId))
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to check posting cooldown")
+
+		return err
+	}
+
+	if exists {
+		return auth.ErrPostingCooldown
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) StartPostingCooldown(ctx context.Context, userId uint, cooldown time.Duration) error {
+	// SETNX fails if the key exists, i.e. if the previous cooldown hasn't expired
+	started, err := s.Kv.SetNX(ctx, postingCooldownRedisKey(userId), "1", cooldown)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to start posting cooldown")
+
+		return err
+	}
+
+	if !started {
+		return auth.ErrPostingCooldown
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) RestrictUser(ctx context.Context, moderatorId uint, userId uint, dto NewRestrictionDto) (RestrictionDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"moderatorId": moderatorId,
+		"userId":      userId,
+		"kind":        dto.Kind,
+	})
+
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return RestrictionDto{}, err
+	}
+
+	restriction := Restriction{
+		UserId:      userId,
+		Kind:        dto.Kind,
+		Reason:      dto.Reason,
+		CreatedById: moderatorId,
+		ExpiresAt:   dto.ExpiresAt,
+	}
+
+	if dto.Kind == RestrictionCooldown {
+		restriction.CooldownSeconds = dto.CooldownSeconds
+	}
+
+	result := s.Db.WithContext(ctx).Create(&restriction)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create restriction")
+
+		return RestrictionDto{}, result.Error
+	}
+
+	s.invalidateStatus(ctx, userId)
+
+	err = s.AuditService.Record(ctx, moderatorId, "moderation.restrict", "user", userId, map[string]interface{}{
+		"restrictionId":   restriction.ID,
+		"kind":            restriction.Kind,
+		"cooldownSeconds": restriction.CooldownSeconds,
+		"reason":          restriction.Reason,
+		"expiresAt":       restriction.ExpiresAt,
+	})
+	if err != nil {
+		return RestrictionDto{}, err
+	}
+
+	logger.Info("User restricted")
+
+	return restrictionToDto(restriction), nil
+}
+
+func (s *serviceImpl) ListRestrictions(ctx context.Context, userId uint) ([]RestrictionDto, error) {
+	restrictions, err := s.activeRestrictions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]RestrictionDto, len(restrictions))
+	for i, restriction := range restrictions {
+		dtos[i] = restrictionToDto(restriction)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) LiftRestriction(ctx context.Context, moderatorId uint, restrictionId uint) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"moderatorId":   moderatorId,
+		"restrictionId": restrictionId,
+	})
+
+	restriction := Restriction{}
+	result := s.Db.WithContext(ctx).First(&restriction, restrictionId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrRestrictionNotFound
+		}
+
+		logger.WithError(result.Error).Error("Failed to query for restriction")
+
+		return result.Error
+	}
+
+	result = s.Db.WithContext(ctx).Delete(&restriction)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to delete restriction")
+
+		return result.Error
+	}
+
+	s.invalidateStatus(ctx, restriction.UserId)
+
+	err := s.AuditService.Record(ctx, moderatorId, "moderation.lift-restriction", "user", restriction.UserId, map[string]interface{}{
+		"restrictionId": restriction.ID,
+		"kind":          restriction.Kind,
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Restriction lifted")
+
+	return nil
+}
+
+// Get a user's restrictions that haven't expired, newest to oldest.
+func (s *serviceImpl) activeRestrictions(ctx context.Context, userId uint) ([]Restriction, error) {
+	var restrictions []Restriction
+	result := s.Db.WithContext(ctx).
+		Where("user_id = ? AND (expires_at IS NULL OR expires_at > ?)", userId, time.Now()).
+		Order("created_at desc").
+		Find(&restrictions)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for restrictions")
+
+		return nil, result.Error
+	}
+
+	return restrictions, nil
+}
+
+func (s *serviceImpl) invalidateStatus(ctx context.Context, userId uint) {
+	err := s.Kv.Del(ctx, accountStatusRedisKey(userId))
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Warn("Failed to invalidate cached account status")
+	}
+}
+
+func restrictionToDto(restriction Restriction) RestrictionDto {
+	return RestrictionDto{
+		Id:              restriction.ID,
+		UserId:          restriction.UserId,
+		Kind:            restriction.Kind,
+		CooldownSeconds: restriction.CooldownSeconds,
+		Reason:          restriction.Reason,
+		CreatedById:     restriction.CreatedById,
+		CreatedAt:       restriction.CreatedAt,
+		ExpiresAt:       restriction.ExpiresAt,
+	}
+}
+
+// Caches a user's account status as json.
+func accountStatusRedisKey(userId uint) string {
+	return fmt.Sprintf("user:%d:account.status", userId)
+}
+
+// Exists while a user's posting cooldown hasn't elapsed.
+func postingCooldownRedisKey(userId uint) string {
+	return fmt.Sprintf("user:%d:posting.cooldown", userId)
+}
@@ -0,0 +1,26 @@
+package moderation
+
+import "time"
+
+type NewRestrictionDto struct {
+	Kind RestrictionKind `json:"kind" validate:"required,oneof=read-only cooldown shadow"`
+
+	// Required for cooldown restrictions.
+	CooldownSeconds uint   `json:"cooldownSeconds" validate:"required_if=Kind cooldown,max=604800"`
+	Reason          string `json:"reason" validate:"required,max=500"`
+
+	// When the restriction is lifted automatically. Omit for a
+	// restriction that lasts until a moderator lifts it.
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type RestrictionDto struct {
+	Id              uint            `json:"id"`
+	UserId          uint            `json:"userId"`
+	Kind            RestrictionKind `json:"kind"`
+	CooldownSeconds uint            `json:"cooldownSeconds"`
+	Reason          string          `json:"reason"`
+	CreatedById     uint            `json:"createdById"`
+	CreatedAt       time.Time       `json:"createdAt"`
+	ExpiresAt       *time.Time      `json:"expiresAt"`
+}
@@ -0,0 +1,35 @@
+package moderation
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type RestrictionKind string
+
+const (
+	// The user can't change anything, only read.
+	RestrictionReadOnly RestrictionKind = "read-only"
+
+	// The user has to wait between creating two pieces of content.
+	RestrictionCooldown RestrictionKind = "cooldown"
+
+	// Content created by the user is hidden until a moderator reviews it.
+	RestrictionShadow RestrictionKind = "shadow"
+)
+
+type Restriction struct {
+	gorm.Model
+
+	UserId uint
+	Kind   RestrictionKind
+
+	// Only used by cooldown restrictions.
+	CooldownSeconds uint
+
+	Reason      string
+	CreatedById uint
+
+	// Nil if the restriction doesn't expire.
+	ExpiresAt *time.Time
+}
@@ -0,0 +1,188 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: notificationsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	notifications "github.com/open-collaboration/server/notifications"
+	users "github.com/open-collaboration/server/users"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method
+func (m *MockService) Notify(ctx context.Context, userId uint, notification notifications.NewNotificationDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, userId, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Notify indicates an expected call of Notify
+func (mr *MockServiceMockRecorder) Notify(ctx, userId, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockService)(nil).Notify), ctx, userId, notification)
+}
+
+// NotifyRole mocks base method
+func (m *MockService) NotifyRole(ctx context.Context, role users.Role, notification notifications.NewNotificationDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyRole", ctx, role, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyRole indicates an expected call of NotifyRole
+func (mr *MockServiceMockRecorder) NotifyRole(ctx, role, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyRole", reflect.TypeOf((*MockService)(nil).NotifyRole), ctx, role, notification)
+}
+
+// ListNotifications mocks base method
+func (m *MockService) ListNotifications(ctx context.Context, userId uint, unreadOnly bool, pageSize, pageOffset uint) ([]notifications.NotificationDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", ctx, userId, unreadOnly, pageSize, pageOffset)
+	ret0, _ := ret[0].([]notifications.NotificationDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications
+func (mr *MockServiceMockRecorder) ListNotifications(ctx, userId, unreadOnly, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockService)(nil).ListNotifications), ctx, userId, unreadOnly, pageSize, pageOffset)
+}
+
+// MarkAsRead mocks base method
+func (m *MockService) MarkAsRead(ctx context.Context, userId, notificationId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkAsRead", ctx, userId, notificationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkAsRead indicates an expected call of MarkAsRead
+func (mr *MockServiceMockRecorder) MarkAsRead(ctx, userId, notificationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAsRead", reflect.TypeOf((*MockService)(nil).MarkAsRead), ctx, userId, notificationId)
+}
+
+// GetPreferences mocks base method
+func (m *MockService) GetPreferences(ctx context.Context, userId uint) ([]notifications.PreferenceDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPreferences", ctx, userId)
+	ret0, _ := ret[0].([]notifications.PreferenceDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPreferences indicates an expected call of GetPreferences
+func (mr *MockServiceMockRecorder) GetPreferences(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPreferences", reflect.TypeOf((*MockService)(nil).GetPreferences), ctx, userId)
+}
+
+// UpdatePreferences mocks base method
+func (m *MockService) UpdatePreferences(ctx context.Context, userId uint, preferences []notifications.PreferenceDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", ctx, userId, preferences)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences
+func (mr *MockServiceMockRecorder) UpdatePreferences(ctx, userId, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockService)(nil).UpdatePreferences), ctx, userId, preferences)
+}
+
+// IsEnabled mocks base method
+func (m *MockService) IsEnabled(ctx context.Context, userId uint, notificationType, channel string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEnabled", ctx, userId, notificationType, channel)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEnabled indicates an expected call of IsEnabled
+func (mr *MockServiceMockRecorder) IsEnabled(ctx, userId, notificationType, channel interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEnabled", reflect.TypeOf((*MockService)(nil).IsEnabled), ctx, userId, notificationType, channel)
+}
+
+// MockChannel is a mock of Channel interface
+type MockChannel struct {
+	ctrl     *gomock.Controller
+	recorder *MockChannelMockRecorder
+}
+
+// MockChannelMockRecorder is the mock recorder for MockChannel
+type MockChannelMockRecorder struct {
+	mock *MockChannel
+}
+
+// NewMockChannel creates a new mock instance
+func NewMockChannel(ctrl *gomock.Controller) *MockChannel {
+	mock := &MockChannel{ctrl: ctrl}
+	mock.recorder = &MockChannelMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockChannel) EXPECT() *MockChannelMockRecorder {
+	return m.recorder
+}
+
+// Name mocks base method
+func (m *MockChannel) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name
+func (mr *MockChannelMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockChannel)(nil).Name))
+}
+
+// Deliver mocks base method
+func (m *MockChannel) Deliver(ctx context.Context, userId uint, notification notifications.NewNotificationDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliver", ctx, userId, notification)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deliver indicates an expected call of Deliver
+func (mr *MockChannelMockRecorder) Deliver(ctx, userId, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliver", reflect.TypeOf((*MockChannel)(nil).Deliver), ctx, userId, notification)
+}
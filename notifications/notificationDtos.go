@@ -0,0 +1,36 @@
+package notifications
+
+import "time"
+
+type NotificationDto struct {
+	Id        uint                   `json:"id"`
+	Type      string                 `json:"type"`
+	Title     string                 `json:"title"`
+	Body      string                 `json:"body"`
+	Data      map[string]interface{} `json:"data"`
+	CreatedAt time.Time              `json:"createdAt"`
+	ReadAt    *time.Time             `json:"readAt"`
+}
+
+// A notification to be sent to one or more users.
+type NewNotificationDto struct {
+	Type  string
+	Title string
+	Body  string
+
+	// Can be nil.
+	Data map[string]interface{}
+}
+
+// Whether a notification type is sent on each channel.
+type PreferenceDto struct {
+	Type string `json:"type" validate:"required"`
+
+	// Channel name to whether it's enabled. Channels missing from updates are
+	// left unchanged.
+	Channels map[string]bool `json:"channels" validate:"required"`
+}
+
+type PreferencesDto struct {
+	Preferences []PreferenceDto `json:"preferences" validate:"required,dive"`
+}
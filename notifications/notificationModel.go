@@ -0,0 +1,34 @@
+package notifications
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Notification struct {
+	gorm.Model
+
+	UserId uint
+
+	// What the notification is about, e.g. "application.spam".
+	Type  string
+	Title string
+	Body  string
+
+	// Type specific data, stored as a json object.
+	Data string `gorm:"type: JSONB"`
+
+	// Nil while the notification is unread.
+	ReadAt *time.Time
+}
+
+// A user's choice of whether a notification type is sent on a channel. Users
+// without a preference for a type and channel get the default (see
+// defaultPreferences).
+type NotificationPreference struct {
+	UserId    uint   `gorm:"primaryKey"`
+	Type      string `gorm:"primaryKey"`
+	Channel   string `gorm:"primaryKey"`
+	Enabled   bool
+	UpdatedAt time.Time
+}
@@ -0,0 +1,80 @@
+package notifications
+
+// Channels notifications are sent on.
+const (
+	// Listed by GET /notifications.
+	ChannelInApp = "in-app"
+	ChannelEmail = "email"
+	ChannelPush  = "push"
+)
+
+// Notification types.
+const (
+	// A new project matches one of the user's saved searches.
+	TypeSavedSearchMatch = "saved-search-match"
+
+	// A user's applications were flagged as spam, sent to moderators.
+	TypeApplicationSpam = "application.spam"
+
+	// One of the user's project integrations was disabled after too many
+	// failed posts.
+	TypeIntegrationDisabled = "integration.disabled"
+
+	// The status of a project the user owns or is a member of changed.
+	TypeProjectStatusChanged = "project.status-changed"
+
+	// A project's owner proposed interview slots to the applicant.
+	TypeInterviewProposed = "interview.proposed"
+
+	// The applicant picked an interview slot, sent to the project's owner.
+	TypeInterviewScheduled = "interview.scheduled"
+
+	// The applicant or the project's owner cancelled an interview, sent to
+	// the other one.
+	TypeInterviewCancelled = "interview.cancelled"
+
+	// An interview starts soon, sent to both participants.
+	TypeInterviewReminder = "interview.reminder"
+
+	// An application expired before the project's owner reviewed it, sent to
+	// both the applicant and the owner.
+	TypeApplicationExpired = "application.expired"
+
+	// An announcement an admin sent to many users, e.g. a maintenance notice
+	// (see the broadcasts package).
+	TypeBroadcast = "broadcast"
+
+	// A member mentioned the user in a project's chat.
+	TypeChatMention = "chat.mention"
+
+	// A project export the user requested can be downloaded.
+	TypeProjectExportReady = "project.export-ready"
+)
+
+var channels = []string{ChannelInApp, ChannelEmail, ChannelPush}
+
+// Whether each notification type is sent on each channel when the user didn't
+// set a preference. Types missing here are only sent in-app by default.
+var defaultPreferences = map[string]map[string]bool{
+	TypeSavedSearchMatch:     {ChannelInApp: true, ChannelEmail: true, ChannelPush: true},
+	TypeApplicationSpam:      {ChannelInApp: true, ChannelEmail: false, ChannelPush: false},
+	TypeIntegrationDisabled:  {ChannelInApp: true, ChannelEmail: false, ChannelPush: true},
+	TypeProjectStatusChanged: {ChannelInApp: true, ChannelEmail: false, ChannelPush: true},
+	TypeInterviewProposed:    {ChannelInApp: true, ChannelEmail: true, ChannelPush: true},
+	TypeInterviewScheduled:   {ChannelInApp: true, ChannelEmail: true, ChannelPush: true},
+	TypeInterviewCancelled:   {ChannelInApp: true, ChannelEmail: true, ChannelPush: true},
+	TypeInterviewReminder:    {ChannelInApp: true, ChannelEmail: true, ChannelPush: true},
+	TypeApplicationExpired:   {ChannelInApp: true, ChannelEmail: true, ChannelPush: false},
+	TypeBroadcast:            {ChannelInApp: true, ChannelEmail: true, ChannelPush: false},
+	TypeChatMention:          {ChannelInApp: true, ChannelEmail: false, ChannelPush: true},
+	TypeProjectExportReady:   {ChannelInApp: true, ChannelEmail: true, ChannelPush: false},
+}
+
+func isDefaultEnabled(notificationType string, channel string) bool {
+	defaults, ok := defaultPreferences[notificationType]
+	if !ok {
+		return channel == ChannelInApp
+	}
+
+	return defaults[channel]
+}
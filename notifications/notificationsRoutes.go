@@ -0,0 +1,148 @@
+package notifications
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List the current user's notifications
+// @Tags notifications
+// @Router /notifications [get]
+// @Param unread query bool false "Only list unread notifications"
+// @Param pageSize query int false "Maximum amount of notifications in the response. Default is 20, max is 100."
+// @Param pageOffset query int false "Response page number."
+// @Success 200 {array} notifications.NotificationDto
+// @Failure 401
+func RouteListNotifications(
+	writer http.ResponseWriter,
+	request *http.Request,
+	notificationsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 20)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+	unreadOnly := request.URL.Query().Get("unread") == "true"
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if pageOffset < 0 {
+		pageOffset = 0
+	}
+
+	notifications, err := notificationsService.ListNotifications(
+		request.Context(),
+		session.UserId(),
+		unreadOnly,
+		uint(pageSize),
+		uint(pageOffset),
+	)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, notifications)
+}
+
+// @Summary Mark a notification as read
+// @Tags notifications
+// @Router /notifications/{notificationId}/read [post]
+// @Param notificationId path int true "The notification ID"
+// @Success 204
+// @Failure 401
+// @Failure 404
+func RouteMarkAsRead(
+	writer http.ResponseWriter,
+	request *http.Request,
+	notificationsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	notificationId, err := utils.UintFromVars(request, "notificationId")
+	if err != nil {
+		return err
+	}
+
+	err = notificationsService.MarkAsRead(request.Context(), session.UserId(), notificationId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Get the current user's notification preferences
+// @Description Whether each notification type is sent on each channel ("in-app", "email" and "push").
+// @Tags notifications
+// @Router /users/me/notification-preferences [get]
+// @Success 200 {object} notifications.PreferencesDto
+// @Failure 401
+func RouteGetPreferences(
+	writer http.ResponseWriter,
+	request *http.Request,
+	notificationsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	preferences, err := notificationsService.GetPreferences(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, PreferencesDto{
+		Preferences: preferences,
+	})
+}
+
+// @Summary Update the current user's notification preferences
+// @Description Types and channels missing from the body are left unchanged.
+// @Tags notifications
+// @Router /users/me/notification-preferences [put]
+// @Param preferences body notifications.PreferencesDto true "The preferences to change"
+// @Success 200 {object} notifications.PreferencesDto
+// @Failure 400 "Unknown notification type or channel"
+// @Failure 401
+func RouteUpdatePreferences(
+	writer http.ResponseWriter,
+	request *http.Request,
+	notificationsService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := PreferencesDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = notificationsService.UpdatePreferences(request.Context(), session.UserId(), dto.Preferences)
+	if err != nil {
+		return err
+	}
+
+	preferences, err := notificationsService.GetPreferences(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, PreferencesDto{
+		Preferences: preferences,
+	})
+}
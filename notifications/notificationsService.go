@@ -0,0 +1,384 @@
+package notifications
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"time"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+var ErrUnknownNotificationType = errors.New("unknown notification type")
+var ErrUnknownChannel = errors.New("unknown notification channel")
+
+type Service interface {
+	// Send a notification to a user, on the channels the user enabled for
+	// the notification's type.
+	Notify(ctx context.Context, userId uint, notification NewNotificationDto) error
+
+	// Send a notification to all users whose role includes role
+	// (e.g. all moderators and admins).
+	NotifyRole(ctx context.Context, role users.Role, notification NewNotificationDto) error
+
+	// List a user's notifications, newest to oldest.
+	ListNotifications(ctx context.Context, userId uint, unreadOnly bool, pageSize uint, pageOffset uint) ([]NotificationDto, error)
+
+	// Mark one of a user's notifications as read.
+	// Returns ErrNotificationNotFound if the user doesn't have the notification.
+	MarkAsRead(ctx context.Context, userId uint, notificationId uint) error
+
+	// Get whether each notification type is sent to a user on each channel.
+	GetPreferences(ctx context.Context, userId uint) ([]PreferenceDto, error)
+
+	// Update a user's preferences. Types and channels missing from the update
+	// are left unchanged.
+	// Returns ErrUnknownNotificationType or ErrUnknownChannel if the update
+	// contains a type or channel that doesn't exist.
+	UpdatePreferences(ctx context.Context, userId uint, preferences []PreferenceDto) error
+
+	// Whether a user wants notifications of a type on a channel. Used by
+	// services that send notifications outside of Notify, e.g. emails with
+	// their own template.
+	IsEnabled(ctx context.Context, userId uint, notificationType string, channel string) (bool, error)
+}
+
+// A Channel delivers notifications outside of the app, e.g. push
+// notifications.
+type Channel interface {
+	// The channel's name, e.g. ChannelPush. Users' preferences for the channel
+	// decide which notifications it delivers.
+	Name() string
+
+	// Deliver a notification to a user.
+	Deliver(ctx context.Context, userId uint, notification NewNotificationDto) error
+}
+
+type serviceImpl struct {
+	Db           *gorm.DB
+	UsersService users.Service
+	Channels     []Channel
+}
+
+// Create a notifications service. Notifications are always stored to be
+// listed in-app, and delivered on the given channels in the background.
+func NewService(db *gorm.DB, usersService users.Service, channels ...Channel) Service {
+	return &serviceImpl{
+		Db:           db,
+		UsersService: usersService,
+		Channels:     channels,
+	}
+}
+
+func (s *serviceImpl) Notify(ctx context.Context, userId uint, notification NewNotificationDto) error {
+	return s.notifyUsers(ctx, []uint{userId}, notification)
+}
+
+func (s *serviceImpl) NotifyRole(ctx context.Context, role users.Role, notification NewNotificationDto) error {
+	recipients, err := s.UsersService.ListUsersWithRole(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]uint, len(recipients))
+	for i, recipient := range recipients {
+		userIds[i] = recipient.ID
+	}
+
+	return s.notifyUsers(ctx, userIds, notification)
+}
+
+func (s *serviceImpl) ListNotifications(
+	ctx context.Context,
+	userId uint,
+	unreadOnly bool,
+	pageSize uint,
+	pageOffset uint,
+) ([]NotificationDto, error) {
+	query := s.Db.WithContext(ctx).Where("user_id = ?", userId)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []Notification
+	result := query.
+		Order("created_at desc").
+		Limit(int(pageSize)).
+		Offset(int(pageOffset * pageSize)).
+		Find(&notifications)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list notifications")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]NotificationDto, len(notifications))
+	for i, notification := range notifications {
+		data := map[string]interface{}{}
+
+		// Data is always serialized by notifyUsers, so this shouldn't fail
+		_ = json.Unmarshal([]byte(notification.Data), &data)
+
+		dtos[i] = NotificationDto{
+			Id:        notification.ID,
+			Type:      notification.Type,
+			Title:     notification.Title,
+			Body:      notification.Body,
+			Data:      data,
+			CreatedAt: notification.CreatedAt,
+			ReadAt:    notification.ReadAt,
+		}
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) MarkAsRead(ctx context.Context, userId uint, notificationId uint) error {
+	result := s.Db.WithContext(ctx).
+		Model(&Notification{}).
+		Where("id = ? AND user_id = ?", notificationId, userId).
+		Update("read_at", time.Now())
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to mark notification as read")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) GetPreferences(ctx context.Context, userId uint) ([]PreferenceDto, error) {
+	var overrides []NotificationPreference
+	result := s.Db.WithContext(ctx).Where("user_id = ?", userId).Find(&overrides)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to get notification preferences")
+
+		return nil, result.Error
+	}
+
+	types := make([]string, 0, len(defaultPreferences))
+	for notificationType := range defaultPreferences {
+		types = append(types, notificationType)
+	}
+	sort.Strings(types)
+
+	preferences := make([]PreferenceDto, len(types))
+	indexes := map[string]int{}
+	for i, notificationType := range types {
+		preferences[i] = PreferenceDto{
+			Type:     notificationType,
+			Channels: map[string]bool{},
+		}
+
+		for _, channel := range channels {
+			preferences[i].Channels[channel] = isDefaultEnabled(notificationType, channel)
+		}
+
+		indexes[notificationType] = i
+	}
+
+	for _, override := range overrides {
+		if i, ok := indexes[override.Type]; ok {
+			preferences[i].Channels[override.Channel] = override.Enabled
+		}
+	}
+
+	return preferences, nil
+}
+
+func (s *serviceImpl) UpdatePreferences(ctx context.Context, userId uint, preferences []PreferenceDto) error {
+	var rows []NotificationPreference
+	for _, preference := range preferences {
+		if _, ok := defaultPreferences[preference.Type]; !ok {
+			return ErrUnknownNotificationType
+		}
+
+		for channel, enabled := range preference.Channels {
+			if !isChannel(channel) {
+				return ErrUnknownChannel
+			}
+
+			rows = append(rows, NotificationPreference{
+				UserId:  userId,
+				Type:    preference.Type,
+				Channel: channel,
+				Enabled: enabled,
+			})
+		}
+	}
+
+	if len(rows) < 1 {
+		return nil
+	}
+
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).
+		Create(&rows)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to update notification preferences")
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) IsEnabled(ctx context.Context, userId uint, notificationType string, channel string) (bool, error) {
+	userIds, err := s.enabledRecipients(ctx, []uint{userId}, notificationType, channel)
+	if err != nil {
+		return false, err
+	}
+
+	return len(userIds) > 0, nil
+}
+
+// Filter the users who want notifications of a type on a channel.
+func (s *serviceImpl) enabledRecipients(ctx context.Context, userIds []uint, notificationType string, channel string) ([]uint, error) {
+	var overrides []NotificationPreference
+	result := s.Db.WithContext(ctx).
+		Where("user_id IN ? AND type = ? AND channel = ?", userIds, notificationType, channel).
+		Find(&overrides)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	enabled := map[uint]bool{}
+	for _, override := range overrides {
+		enabled[override.UserId] = override.Enabled
+	}
+
+	var recipients []uint
+	for _, userId := range userIds {
+		userEnabled, ok := enabled[userId]
+		if !ok {
+			userEnabled = isDefaultEnabled(notificationType, channel)
+		}
+
+		if userEnabled {
+			recipients = append(recipients, userId)
+		}
+	}
+
+	return recipients, nil
+}
+
+func (s *serviceImpl) notifyUsers(ctx context.Context, userIds []uint, notification NewNotificationDto) error {
+	logger := log.FromContext(ctx).WithField("type", notification.Type)
+
+	if len(userIds) < 1 {
+		return nil
+	}
+
+	for _, channel := range s.Channels {
+		err := s.deliver(ctx, channel, userIds, notification)
+		if err != nil {
+			logger.WithError(err).WithField("channel", channel.Name()).Error("Failed to deliver notifications")
+
+			return err
+		}
+	}
+
+	userIds, err := s.enabledRecipients(ctx, userIds, notification.Type, ChannelInApp)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get notification preferences")
+
+		return err
+	}
+
+	if len(userIds) < 1 {
+		return nil
+	}
+
+	data := notification.Data
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	dataJson, err := json.Marshal(data)
+	if err != nil {
+		logger.WithError(err).Error("Failed to serialize notification data")
+
+		return err
+	}
+
+	rows := make([]Notification, len(userIds))
+	for i, userId := range userIds {
+		rows[i] = Notification{
+			UserId: userId,
+			Type:   notification.Type,
+			Title:  notification.Title,
+			Body:   notification.Body,
+			Data:   string(dataJson),
+		}
+	}
+
+	result := s.Db.WithContext(ctx).Create(&rows)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create notifications")
+
+		return result.Error
+	}
+
+	logger.Debugf("Sent notification to %d users", len(userIds))
+
+	return nil
+}
+
+// Deliver a notification on a channel to the users who enabled it, in the
+// background so that slow channels don't delay the request.
+func (s *serviceImpl) deliver(ctx context.Context, channel Channel, userIds []uint, notification NewNotificationDto) error {
+	recipients, err := s.enabledRecipients(ctx, userIds, notification.Type, channel.Name())
+	if err != nil {
+		return err
+	}
+
+	if len(recipients) < 1 {
+		return nil
+	}
+
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"type":    notification.Type,
+		"channel": channel.Name(),
+	})
+
+	go func() {
+		ctx := log.NewContext(context.Background(), logger)
+
+		for _, userId := range recipients {
+			err := channel.Deliver(ctx, userId, notification)
+			if err != nil {
+				// Keep delivering to the other users
+				logger.WithError(err).WithField("userId", userId).Error("Failed to deliver notification")
+			}
+		}
+	}()
+
+	return nil
+}
+
+func isChannel(name string) bool {
+	for _, channel := range channels {
+		if channel == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+package ogimage
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+// A 5x7 bitmap font, scaled up when drawn, so that images are rendered
+// without font files. Each glyph is 7 rows of 5 bits, the leftmost pixel
+// being the highest bit. Lowercase letters are drawn as uppercase ones.
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+
+	// Space between glyphs and between lines, in pixels before scaling
+	glyphSpacing = 1
+	lineSpacing  = 3
+)
+
+var glyphs = map[rune][glyphHeight]uint8{
+	'A':  {0x0E, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'B':  {0x1E, 0x11, 0x11, 0x1E, 0x11, 0x11, 0x1E},
+	'C':  {0x0E, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0E},
+	'D':  {0x1C, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1C},
+	'E':  {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x1F},
+	'F':  {0x1F, 0x10, 0x10, 0x1E, 0x10, 0x10, 0x10},
+	'G':  {0x0E, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0F},
+	'H':  {0x11, 0x11, 0x11, 0x1F, 0x11, 0x11, 0x11},
+	'I':  {0x0E, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'J':  {0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0C},
+	'K':  {0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11},
+	'L':  {0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1F},
+	'M':  {0x11, 0x1B, 0x15, 0x15, 0x11, 0x11, 0x11},
+	'N':  {0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11},
+	'O':  {0x0E, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'P':  {0x1E, 0x11, 0x11, 0x1E, 0x10, 0x10, 0x10},
+	'Q':  {0x0E, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0D},
+	'R':  {0x1E, 0x11, 0x11, 0x1E, 0x14, 0x12, 0x11},
+	'S':  {0x0F, 0x10, 0x10, 0x0E, 0x01, 0x01, 0x1E},
+	'T':  {0x1F, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04},
+	'U':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0E},
+	'V':  {0x11, 0x11, 0x11, 0x11, 0x11, 0x0A, 0x04},
+	'W':  {0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0A},
+	'X':  {0x11, 0x11, 0x0A, 0x04, 0x0A, 0x11, 0x11},
+	'Y':  {0x11, 0x11, 0x0A, 0x04, 0x04, 0x04, 0x04},
+	'Z':  {0x1F, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1F},
+	'0':  {0x0E, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0E},
+	'1':  {0x04, 0x0C, 0x04, 0x04, 0x04, 0x04, 0x0E},
+	'2':  {0x0E, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1F},
+	'3':  {0x1F, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0E},
+	'4':  {0x02, 0x06, 0x0A, 0x12, 0x1F, 0x02, 0x02},
+	'5':  {0x1F, 0x10, 0x1E, 0x01, 0x01, 0x11, 0x0E},
+	'6':  {0x06, 0x08, 0x10, 0x1E, 0x11, 0x11, 0x0E},
+	'7':  {0x1F, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	'8':  {0x0E, 0x11, 0x11, 0x0E, 0x11, 0x11, 0x0E},
+	'9':  {0x0E, 0x11, 0x11, 0x0F, 0x01, 0x02, 0x0C},
+	' ':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	'.':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x0C, 0x0C},
+	',':  {0x00, 0x00, 0x00, 0x00, 0x0C, 0x04, 0x08},
+	':':  {0x00, 0x0C, 0x0C, 0x00, 0x0C, 0x0C, 0x00},
+	'-':  {0x00, 0x00, 0x00, 0x1F, 0x00, 0x00, 0x00},
+	'_':  {0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1F},
+	'\'': {0x04, 0x04, 0x08, 0x00, 0x00, 0x00, 0x00},
+	'!':  {0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04},
+	'?':  {0x0E, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04},
+	'&':  {0x0C, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0D},
+	'/':  {0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00},
+	'#':  {0x0A, 0x0A, 0x1F, 0x0A, 0x1F, 0x0A, 0x0A},
+	'+':  {0x00, 0x04, 0x04, 0x1F, 0x04, 0x04, 0x00},
+	'(':  {0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02},
+	')':  {0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08},
+	'·':  {0x00, 0x00, 0x00, 0x0C, 0x0C, 0x00, 0x00},
+}
+
+// Map text to runes the font has: accents are stripped, lowercase letters
+// become uppercase and any other missing rune becomes '?'.
+func toFont(text string) []rune {
+	var runes []rune
+	for _, r := range norm.NFD.String(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		r = unicode.ToUpper(r)
+		if _, ok := glyphs[r]; !ok {
+			r = '?'
+		}
+
+		runes = append(runes, r)
+	}
+
+	return runes
+}
+
+// Split text into at most maxLines lines of at most maxRunes runes, breaking
+// between words (split at spaces) when possible. Text that doesn't fit ends
+// with "...".
+func wrap(text []rune, maxRunes int, maxLines int) [][]rune {
+	var words [][]rune
+	for _, word := range strings.Fields(string(text)) {
+		words = append(words, []rune(word))
+	}
+
+	return wrapWords(words, maxRunes, maxLines)
+}
+
+// Like wrap, for words that may contain spaces but shouldn't be broken unless
+// they're longer than a line.
+func wrapWords(words [][]rune, maxRunes int, maxLines int) [][]rune {
+	var lines [][]rune
+	var line []rune
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		switch {
+		case len(line) == 0 && len(word) <= maxRunes:
+			line = append([]rune{}, word...)
+			continue
+		case len(line) > 0 && len(line)+1+len(word) <= maxRunes:
+			line = append(append(line, ' '), word...)
+			continue
+		case len(line) == 0:
+			// The word is longer than a line, split it
+			line = append([]rune{}, word[:maxRunes]...)
+			words[i] = word[maxRunes:]
+			i--
+		default:
+			i--
+		}
+
+		lines = append(lines, line)
+		line = nil
+
+		if len(lines) == maxLines {
+			return ellipsize(lines, maxRunes)
+		}
+	}
+
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// End the last line with "...", for text that doesn't fit.
+func ellipsize(lines [][]rune, maxRunes int) [][]rune {
+	last := lines[len(lines)-1]
+	if len(last) > maxRunes-3 {
+		last = last[:maxRunes-3]
+	}
+	lines[len(lines)-1] = append(last, '.', '.', '.')
+
+	return lines
+}
@@ -0,0 +1,72 @@
+package ogimage
+
+import (
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// How long clients and social networks' crawlers can cache an image.
+const imageMaxAge = "3600"
+
+// @Summary Get a project's Open Graph image
+// @Description A 1200x630 PNG with the project's name, tags and stats, meant for the og:image tag of the project's
+// @Description page so that shared links get a rich preview.
+// @Tags projects
+// @Router /projects/{projectId}/og-image.png [get]
+// @Param projectId path int true "The project ID"
+// @Produce png
+// @Success 200
+// @Failure 404 "Project not found"
+func RouteGetProjectImage(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService projects.Service,
+	usersService users.Service,
+	ogImageService Service,
+) error {
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	cacheControl := "public, max-age=" + imageMaxAge
+
+	// Like the project itself, see projects.RouteGetProject
+	if project.PendingReview || project.Draft {
+		session, err := auth.CheckSession(request)
+		if err == nil && session.UserId() != project.OwnerId {
+			_, err = auth.CheckRole(request, usersService, users.RoleModerator)
+		}
+
+		if err != nil {
+			return projects.ErrProjectNotFound
+		}
+
+		cacheControl = "private, no-store"
+	}
+
+	image, err := ogImageService.ProjectImage(request.Context(), project)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("Content-Type", "image/png")
+	writer.Header().Set("Cache-Control", cacheControl)
+	writer.WriteHeader(http.StatusOK)
+
+	_, err = writer.Write(image)
+	if err != nil {
+		log.FromContext(request.Context()).WithError(err).Warn("Failed to write og image")
+	}
+
+	return nil
+}
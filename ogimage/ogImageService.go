@@ -0,0 +1,143 @@
+package ogimage
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/projects"
+	"gorm.io/gorm"
+	"image"
+	"image/png"
+	"os"
+	"time"
+)
+
+// How long rendered images are cached. Images are cached by what's drawn on
+// them, so a changed project gets a new image right away.
+const imageCacheDuration = 24 * time.Hour
+
+// How many tags are drawn.
+const maxTags = 6
+
+type Service interface {
+	// Render a project's Open Graph image: its name, tags and stats.
+	ProjectImage(ctx context.Context, project projects.ProjectDto) ([]byte, error)
+}
+
+type serviceImpl struct {
+	Db *gorm.DB
+	Kv kv.Store
+
+	// Drawn under the text, nil for a plain background
+	Template image.Image
+
+	InstanceName string
+}
+
+// Create a service rendering images over the PNG at templatePath, or over a
+// plain background if it's empty.
+func NewService(db *gorm.DB, kvStore kv.Store, templatePath string, instanceName string) (Service, error) {
+	service := &serviceImpl{
+		Db:           db,
+		Kv:           kvStore,
+		InstanceName: instanceName,
+	}
+
+	if templatePath != "" {
+		file, err := os.Open(templatePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		service.Template, err = png.Decode(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the og image template: %w", err)
+		}
+	}
+
+	return service, nil
+}
+
+func (s *serviceImpl) ProjectImage(ctx context.Context, project projects.ProjectDto) ([]byte, error) {
+	logger := log.FromContext(ctx).WithField("projectId", project.Id)
+
+	card, err := s.projectCard(ctx, project)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get the project's stats")
+
+		return nil, err
+	}
+
+	encodedCard, err := json.Marshal(card)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("project:%d:og.image:%x", project.Id, sha256.Sum256(encodedCard))
+
+	cached, err := s.Kv.Get(ctx, cacheKey)
+	if err == nil {
+		decoded, err := base64.StdEncoding.DecodeString(cached)
+		if err == nil {
+			return decoded, nil
+		}
+
+		logger.WithError(err).Warn("Failed to decode cached og image")
+	} else if !errors.Is(err, kv.ErrNotFound) {
+		logger.WithError(err).Warn("Failed to get cached og image")
+	}
+
+	rendered, err := render(card, s.Template)
+	if err != nil {
+		logger.WithError(err).Error("Failed to render og image")
+
+		return nil, err
+	}
+
+	err = s.Kv.Set(ctx, cacheKey, base64.StdEncoding.EncodeToString(rendered), imageCacheDuration)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to cache og image")
+	}
+
+	return rendered, nil
+}
+
+func (s *serviceImpl) projectCard(ctx context.Context, project projects.ProjectDto) (card, error) {
+	tags := project.Tags
+	if len(tags) > maxTags {
+		tags = tags[:maxTags]
+	}
+
+	card := card{
+		Name:         project.Name,
+		Tags:         tags,
+		Status:       string(project.Status),
+		OpenRoles:    len(project.Roles),
+		InstanceName: s.InstanceName,
+	}
+
+	result := s.Db.WithContext(ctx).
+		Table("applications").
+		Where("project_id = ? AND status = ? AND deleted_at IS NULL", project.Id, "accepted").
+		Distinct("applicant_id").
+		Count(&card.Members)
+
+	if result.Error != nil {
+		return card, result.Error
+	}
+
+	result = s.Db.WithContext(ctx).
+		Table("contributions").
+		Where("project_id = ? AND deleted_at IS NULL", project.Id).
+		Count(&card.Contributions)
+
+	return card, result.Error
+}
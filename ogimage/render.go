@@ -0,0 +1,124 @@
+package ogimage
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// The size recommended for Open Graph images.
+const (
+	imageWidth  = 1200
+	imageHeight = 630
+
+	margin = 80
+)
+
+var (
+	backgroundColor = color.RGBA{R: 0x1E, G: 0x29, B: 0x3B, A: 0xFF}
+	accentColor     = color.RGBA{R: 0x63, G: 0x66, B: 0xF1, A: 0xFF}
+	titleColor      = color.RGBA{R: 0xF8, G: 0xFA, B: 0xFC, A: 0xFF}
+	mutedColor      = color.RGBA{R: 0x94, G: 0xA3, B: 0xB8, A: 0xFF}
+)
+
+// What's drawn on a project's image.
+type card struct {
+	Name          string
+	Tags          []string
+	Status        string
+	OpenRoles     int
+	Members       int64
+	Contributions int64
+	InstanceName  string
+}
+
+// Render a card as a PNG, over template if it isn't nil (it's drawn from the
+// top left corner, it should be imageWidth by imageHeight).
+func render(card card, template image.Image) ([]byte, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, imageWidth, imageHeight))
+
+	if template != nil {
+		draw.Draw(canvas, canvas.Bounds(), template, template.Bounds().Min, draw.Src)
+	} else {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(backgroundColor), image.Point{}, draw.Src)
+		draw.Draw(canvas, image.Rect(0, 0, 24, imageHeight), image.NewUniform(accentColor), image.Point{}, draw.Src)
+	}
+
+	drawLines(canvas, [][]rune{toFont(card.InstanceName)}, margin, 70, 4, mutedColor)
+
+	titleScale := 8
+	title := wrap(toFont(card.Name), maxRunes(titleScale), 2)
+	drawLines(canvas, title, margin, 170, titleScale, titleColor)
+
+	tags := make([]string, len(card.Tags))
+	for i, tag := range card.Tags {
+		tags[i] = "#" + tag
+	}
+	drawLines(canvas, wrap(toFont(strings.Join(tags, " ")), maxRunes(4), 2), margin, 400, 4, accentColor)
+
+	stats := []string{
+		card.Status,
+		plural(card.Members, "member", "members"),
+		plural(int64(card.OpenRoles), "open role", "open roles"),
+		plural(card.Contributions, "contribution", "contributions"),
+	}
+
+	// A stat isn't split across lines
+	statWords := make([][]rune, len(stats))
+	for i, stat := range stats {
+		if i < len(stats)-1 {
+			stat += " ·"
+		}
+		statWords[i] = toFont(stat)
+	}
+	drawLines(canvas, wrapWords(statWords, maxRunes(4), 2), margin, 500, 4, mutedColor)
+
+	buffer := bytes.Buffer{}
+	err := png.Encode(&buffer, canvas)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// How many runes fit between the margins at scale.
+func maxRunes(scale int) int {
+	return (imageWidth - 2*margin) / ((glyphWidth + glyphSpacing) * scale)
+}
+
+func drawLines(canvas *image.RGBA, lines [][]rune, x int, y int, scale int, textColor color.Color) {
+	fill := image.NewUniform(textColor)
+
+	for _, line := range lines {
+		for i, r := range line {
+			glyph := glyphs[r]
+			left := x + i*(glyphWidth+glyphSpacing)*scale
+
+			for row, bits := range glyph {
+				for column := 0; column < glyphWidth; column++ {
+					if bits&(1<<(glyphWidth-1-column)) == 0 {
+						continue
+					}
+
+					pixel := image.Rect(left+column*scale, y+row*scale, left+(column+1)*scale, y+(row+1)*scale)
+					draw.Draw(canvas, pixel, fill, image.Point{}, draw.Src)
+				}
+			}
+		}
+
+		y += (glyphHeight + lineSpacing) * scale
+	}
+}
+
+func plural(count int64, singular string, plural string) string {
+	if count == 1 {
+		return "1 " + singular
+	}
+
+	return strconv.FormatInt(count, 10) + " " + plural
+}
@@ -0,0 +1,203 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: portfolioService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	portfolio "github.com/open-collaboration/server/portfolio"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateItem mocks base method
+func (m *MockService) CreateItem(ctx context.Context, userId uint, dto portfolio.NewItemDto, pendingReview bool) (portfolio.ItemDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateItem", ctx, userId, dto, pendingReview)
+	ret0, _ := ret[0].(portfolio.ItemDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateItem indicates an expected call of CreateItem
+func (mr *MockServiceMockRecorder) CreateItem(ctx, userId, dto, pendingReview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockService)(nil).CreateItem), ctx, userId, dto, pendingReview)
+}
+
+// ListItems mocks base method
+func (m *MockService) ListItems(ctx context.Context, userId uint, includeHidden bool) ([]portfolio.ItemDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListItems", ctx, userId, includeHidden)
+	ret0, _ := ret[0].([]portfolio.ItemDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListItems indicates an expected call of ListItems
+func (mr *MockServiceMockRecorder) ListItems(ctx, userId, includeHidden interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListItems", reflect.TypeOf((*MockService)(nil).ListItems), ctx, userId, includeHidden)
+}
+
+// UpdateItem mocks base method
+func (m *MockService) UpdateItem(ctx context.Context, userId, itemId uint, dto portfolio.NewItemDto, pendingReview bool) (portfolio.ItemDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateItem", ctx, userId, itemId, dto, pendingReview)
+	ret0, _ := ret[0].(portfolio.ItemDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateItem indicates an expected call of UpdateItem
+func (mr *MockServiceMockRecorder) UpdateItem(ctx, userId, itemId, dto, pendingReview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockService)(nil).UpdateItem), ctx, userId, itemId, dto, pendingReview)
+}
+
+// DeleteItem mocks base method
+func (m *MockService) DeleteItem(ctx context.Context, userId, itemId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItem", ctx, userId, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteItem indicates an expected call of DeleteItem
+func (mr *MockServiceMockRecorder) DeleteItem(ctx, userId, itemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockService)(nil).DeleteItem), ctx, userId, itemId)
+}
+
+// ReorderItems mocks base method
+func (m *MockService) ReorderItems(ctx context.Context, userId uint, itemIds []uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderItems", ctx, userId, itemIds)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReorderItems indicates an expected call of ReorderItems
+func (mr *MockServiceMockRecorder) ReorderItems(ctx, userId, itemIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderItems", reflect.TypeOf((*MockService)(nil).ReorderItems), ctx, userId, itemIds)
+}
+
+// HideItem mocks base method
+func (m *MockService) HideItem(ctx context.Context, moderatorId, itemId uint, dto portfolio.HideItemDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HideItem", ctx, moderatorId, itemId, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HideItem indicates an expected call of HideItem
+func (mr *MockServiceMockRecorder) HideItem(ctx, moderatorId, itemId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HideItem", reflect.TypeOf((*MockService)(nil).HideItem), ctx, moderatorId, itemId, dto)
+}
+
+// UnhideItem mocks base method
+func (m *MockService) UnhideItem(ctx context.Context, moderatorId, itemId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnhideItem", ctx, moderatorId, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnhideItem indicates an expected call of UnhideItem
+func (mr *MockServiceMockRecorder) UnhideItem(ctx, moderatorId, itemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnhideItem", reflect.TypeOf((*MockService)(nil).UnhideItem), ctx, moderatorId, itemId)
+}
+
+// ListPendingItems mocks base method
+func (m *MockService) ListPendingItems(ctx context.Context, pageSize, pageOffset uint) ([]portfolio.PendingItemDto, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingItems", ctx, pageSize, pageOffset)
+	ret0, _ := ret[0].([]portfolio.PendingItemDto)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPendingItems indicates an expected call of ListPendingItems
+func (mr *MockServiceMockRecorder) ListPendingItems(ctx, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingItems", reflect.TypeOf((*MockService)(nil).ListPendingItems), ctx, pageSize, pageOffset)
+}
+
+// ApproveItem mocks base method
+func (m *MockService) ApproveItem(ctx context.Context, moderatorId, itemId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveItem", ctx, moderatorId, itemId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveItem indicates an expected call of ApproveItem
+func (mr *MockServiceMockRecorder) ApproveItem(ctx, moderatorId, itemId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveItem", reflect.TypeOf((*MockService)(nil).ApproveItem), ctx, moderatorId, itemId)
+}
+
+// MockGuard is a mock of Guard interface
+type MockGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockGuardMockRecorder
+}
+
+// MockGuardMockRecorder is the mock recorder for MockGuard
+type MockGuardMockRecorder struct {
+	mock *MockGuard
+}
+
+// NewMockGuard creates a new mock instance
+func NewMockGuard(ctrl *gomock.Controller) *MockGuard {
+	mock := &MockGuard{ctrl: ctrl}
+	mock.recorder = &MockGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockGuard) EXPECT() *MockGuardMockRecorder {
+	return m.recorder
+}
+
+// CheckItem mocks base method
+func (m *MockGuard) CheckItem(ctx context.Context, userId uint, item portfolio.NewItemDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckItem", ctx, userId, item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckItem indicates an expected call of CheckItem
+func (mr *MockGuardMockRecorder) CheckItem(ctx, userId, item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckItem", reflect.TypeOf((*MockGuard)(nil).CheckItem), ctx, userId, item)
+}
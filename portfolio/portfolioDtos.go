@@ -0,0 +1,38 @@
+package portfolio
+
+type NewItemDto struct {
+	Title       string `json:"title" validate:"required,max=100"`
+	Description string `json:"description" validate:"max=1000"`
+	Url         string `json:"url" validate:"required,url,max=500"`
+	ImageUrl    string `json:"imageUrl" validate:"omitempty,url,max=500"`
+}
+
+type ItemDto struct {
+	Id          uint   `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Url         string `json:"url"`
+	ImageUrl    string `json:"imageUrl"`
+	Position    int    `json:"position"`
+
+	// Only listed to the item's owner and moderators
+	Hidden       bool   `json:"hidden"`
+	HiddenReason string `json:"hiddenReason,omitempty"`
+}
+
+// A new order for a user's portfolio: the ids of all their items, in the new
+// order.
+type ReorderDto struct {
+	Ids []uint `json:"ids" validate:"required"`
+}
+
+// An item pending review, with its owner.
+type PendingItemDto struct {
+	ItemDto
+	UserId uint `json:"userId"`
+}
+
+type HideItemDto struct {
+	// Shown to the item's owner
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,34 @@
+package portfolio
+
+import (
+	"gorm.io/gorm"
+)
+
+// Something a user worked on, shown on their profile so that project owners
+// can evaluate them, e.g. when they apply to a project.
+type Item struct {
+	gorm.Model
+
+	UserId      uint
+	Title       string
+	Description string
+	Url         string
+	ImageUrl    string
+
+	// Items are shown in the order the user lists them
+	Position int
+
+	// Hidden by a moderator. Hidden items are only shown to their owner and
+	// to moderators.
+	Hidden       bool
+	HiddenReason string
+
+	// Added or changed by a shadow restricted user. Items pending review are
+	// only shown to their owner, who can't tell, and to moderators until a
+	// moderator approves them.
+	PendingReview bool
+}
+
+func (Item) TableName() string {
+	return "portfolio_items"
+}
@@ -0,0 +1,330 @@
+package portfolio
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Add an item to the current user's portfolio
+// @Description Items are added to the end of the portfolio. Users can have at most 12 items. Items of shadow
+// @Description restricted users are only shown to others once a moderator approves them.
+// @Tags portfolio
+// @Router /users/me/portfolio [post]
+// @Param item body portfolio.NewItemDto true "The item"
+// @Success 201 {object} portfolio.ItemDto
+// @Failure 400
+// @Failure 401
+// @Failure 409 "The user already has the maximum amount of portfolio items"
+func RouteCreateItem(
+	writer http.ResponseWriter,
+	request *http.Request,
+	portfolioService Service,
+	accountStatusProvider auth.AccountStatusProvider,
+) error {
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
+	if err != nil {
+		return err
+	}
+
+	dto := NewItemDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	item, err := portfolioService.CreateItem(request.Context(), session.UserId(), dto, accountStatus.ShadowHidden)
+	if err != nil {
+		return err
+	}
+
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, item)
+}
+
+// @Summary List a user's portfolio
+// @Description Items hidden by moderators or pending review are only listed to their owner and to moderators. Users
+// @Description who hide their profile from anonymous visitors look like they don't exist to them.
+// @Tags portfolio
+// @Router /users/{userId}/portfolio [get]
+// @Param userId path int true "The user ID"
+// @Success 200 {array} portfolio.ItemDto
+// @Failure 404
+func RouteListItems(
+	writer http.ResponseWriter,
+	request *http.Request,
+	portfolioService Service,
+	usersService users.Service,
+) error {
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	_, viewerId, err := auth.CheckProfileVisible(request, usersService, userId)
+	if err != nil {
+		return err
+	}
+
+	includeHidden := viewerId == userId
+	if !includeHidden && viewerId != 0 {
+		_, err = auth.CheckRole(request, usersService, users.RoleModerator)
+		includeHidden = err == nil
+	}
+
+	items, err := portfolioService.ListItems(request.Context(), userId, includeHidden)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, items)
+}
+
+// @Summary Update an item of the current user's portfolio
+// @Description Items hidden by moderators stay hidden. Items of shadow restricted users are reviewed again.
+// @Tags portfolio
+// @Router /users/me/portfolio/{itemId} [put]
+// @Param itemId path int true "The item ID"
+// @Param item body portfolio.NewItemDto true "The item"
+// @Success 200 {object} portfolio.ItemDto
+// @Failure 400
+// @Failure 401
+// @Failure 404
+func RouteUpdateItem(
+	writer http.ResponseWriter,
+	request *http.Request,
+	portfolioService Service,
+	accountStatusProvider auth.AccountStatusProvider,
+) error {
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
+	if err != nil {
+		return err
+	}
+
+	itemId, err := utils.UintFromVars(request, "itemId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewItemDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	item, err := portfolioService.UpdateItem(request.Context(), session.UserId(), itemId, dto, accountStatus.ShadowHidden)
+	if err != nil {
+		return err
+	}
+
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, item)
+}
+
+// @Summary Delete an item of the current user's portfolio
+// @Tags portfolio
+// @Router /users/me/portfolio/{itemId} [delete]
+// @Param itemId path int true "The item ID"
+// @Success 204
+// @Failure 401
+// @Failure 404
+func RouteDeleteItem(writer http.ResponseWriter, request *http.Request, portfolioService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	itemId, err := utils.UintFromVars(request, "itemId")
+	if err != nil {
+		return err
+	}
+
+	err = portfolioService.DeleteItem(request.Context(), session.UserId(), itemId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Reorder the current user's portfolio
+// @Tags portfolio
+// @Router /users/me/portfolio/order [post]
+// @Param order body portfolio.ReorderDto true "The ids of all the user's items in their new order"
+// @Success 204
+// @Failure 400
+// @Failure 401
+func RouteReorderItems(writer http.ResponseWriter, request *http.Request, portfolioService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := ReorderDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = portfolioService.ReorderItems(request.Context(), session.UserId(), dto.Ids)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Hide a portfolio item
+// @Description The item is only shown to its owner, along with the reason, and to moderators.
+// @Tags moderation
+// @Router /moderation/portfolio-items/{itemId}/hidden [put]
+// @Param itemId path int true "The item ID"
+// @Param hide body portfolio.HideItemDto true "Why the item is hidden"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteHideItem(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	portfolioService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	itemId, err := utils.UintFromVars(request, "itemId")
+	if err != nil {
+		return err
+	}
+
+	dto := HideItemDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = portfolioService.HideItem(request.Context(), session.UserId(), itemId, dto)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Unhide a portfolio item
+// @Tags moderation
+// @Router /moderation/portfolio-items/{itemId}/hidden [delete]
+// @Param itemId path int true "The item ID"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteUnhideItem(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	portfolioService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	itemId, err := utils.UintFromVars(request, "itemId")
+	if err != nil {
+		return err
+	}
+
+	err = portfolioService.UnhideItem(request.Context(), session.UserId(), itemId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary List portfolio items pending review
+// @Description Items added or changed by shadow restricted users, oldest first.
+// @Tags moderation
+// @Router /moderation/portfolio-items/pending [get]
+// @Param pageSize query int false "Maximum amount of items in the response. Default is 20, max is 100."
+// @Param pageOffset query int false "Response page number."
+// @Success 200 {array} portfolio.PendingItemDto
+// @Header 200 {int} X-Total-Count "Total amount of items pending review"
+// @Failure 403
+func RouteListPendingItems(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	portfolioService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 20)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if pageOffset < 0 {
+		pageOffset = 0
+	}
+
+	items, totalCount, err := portfolioService.ListPendingItems(request.Context(), uint(pageSize), uint(pageOffset))
+	if err != nil {
+		return err
+	}
+
+	utils.WritePaginationHeaders(writer, pageOffset, pageSize, totalCount)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, items)
+}
+
+// @Summary Approve a portfolio item pending review
+// @Tags moderation
+// @Router /moderation/portfolio-items/{itemId}/approve [post]
+// @Param itemId path int true "The item ID"
+// @Success 204
+// @Failure 403
+// @Failure 404 "The item doesn't exist or isn't pending review"
+func RouteApproveItem(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	portfolioService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	itemId, err := utils.UintFromVars(request, "itemId")
+	if err != nil {
+		return err
+	}
+
+	err = portfolioService.ApproveItem(request.Context(), session.UserId(), itemId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
@@ -0,0 +1,421 @@
+package portfolio
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/audit"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+)
+
+var ErrItemNotFound = errors.New("portfolio item not found")
+var ErrTooManyItems = errors.New("too many portfolio items")
+var ErrInvalidLink = errors.New("invalid portfolio link")
+var ErrInvalidOrder = errors.New("the new order must contain every id exactly once")
+
+// Maximum amount of portfolio items per user.
+const maxItems = 12
+
+type Service interface {
+	// Add an item to the end of a user's portfolio. Items pending review (of
+	// shadow restricted users) aren't shown to others until approved.
+	// Returns ErrTooManyItems if the user already has the maximum amount of
+	// items, ErrInvalidLink if the item's links aren't http or https links and
+	// the error of the first Guard that rejects the item.
+	CreateItem(ctx context.Context, userId uint, dto NewItemDto, pendingReview bool) (ItemDto, error)
+
+	// List a user's portfolio in its order. Hidden items and items pending
+	// review are only listed if includeHidden is true.
+	ListItems(ctx context.Context, userId uint, includeHidden bool) ([]ItemDto, error)
+
+	// Update one of a user's items. Hidden items stay hidden, and items
+	// pending review stay pending; pendingReview puts the item back in review.
+	// Returns ErrItemNotFound if the user doesn't have the item, and the same
+	// errors as CreateItem if the item is invalid.
+	UpdateItem(ctx context.Context, userId uint, itemId uint, dto NewItemDto, pendingReview bool) (ItemDto, error)
+
+	// Delete one of a user's items.
+	// Returns ErrItemNotFound if the user doesn't have the item.
+	DeleteItem(ctx context.Context, userId uint, itemId uint) error
+
+	// Reorder a user's portfolio. itemIds must contain the ids of all the
+	// user's items in their new order, otherwise ErrInvalidOrder is returned.
+	ReorderItems(ctx context.Context, userId uint, itemIds []uint) error
+
+	// Hide an item from everyone but its owner and moderators. The action is
+	// recorded in the audit log.
+	// Returns ErrItemNotFound if the item doesn't exist.
+	HideItem(ctx context.Context, moderatorId uint, itemId uint, dto HideItemDto) error
+
+	// Show a hidden item again. The action is recorded in the audit log.
+	// Returns ErrItemNotFound if the item doesn't exist.
+	UnhideItem(ctx context.Context, moderatorId uint, itemId uint) error
+
+	// List the items pending review, oldest to newest. Also returns the total
+	// amount of items pending review.
+	ListPendingItems(ctx context.Context, pageSize uint, pageOffset uint) ([]PendingItemDto, int64, error)
+
+	// Approve an item pending review, showing it to everyone. The action is
+	// recorded in the audit log.
+	// Returns ErrItemNotFound if the item doesn't exist or isn't pending
+	// review.
+	ApproveItem(ctx context.Context, moderatorId uint, itemId uint) error
+}
+
+// A Guard is consulted before an item is created or updated. If it returns an
+// error the item isn't saved and the error is returned, e.g. to reject links
+// to known spam domains.
+type Guard interface {
+	CheckItem(ctx context.Context, userId uint, item NewItemDto) error
+}
+
+type serviceImpl struct {
+	Db           *gorm.DB
+	AuditService audit.Service
+	Guards       []Guard
+}
+
+func NewService(db *gorm.DB, auditService audit.Service, guards ...Guard) Service {
+	return &serviceImpl{
+		Db:           db,
+		AuditService: auditService,
+		Guards:       guards,
+	}
+}
+
+func (s *serviceImpl) CreateItem(ctx context.Context, userId uint, dto NewItemDto, pendingReview bool) (ItemDto, error) {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	dto, err := s.checkItem(ctx, userId, dto)
+	if err != nil {
+		return ItemDto{}, err
+	}
+
+	item := Item{
+		UserId:        userId,
+		Title:         dto.Title,
+		Description:   dto.Description,
+		Url:           dto.Url,
+		ImageUrl:      dto.ImageUrl,
+		PendingReview: pendingReview,
+	}
+
+	err = s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		result := tx.Model(&Item{}).Where("user_id = ?", userId).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count >= maxItems {
+			return ErrTooManyItems
+		}
+
+		var lastPosition int
+		result = tx.Model(&Item{}).Where("user_id = ?", userId).Select("coalesce(max(position), -1)").Scan(&lastPosition)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		item.Position = lastPosition + 1
+
+		return tx.Create(&item).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrTooManyItems) {
+			logger.WithError(err).Error("Failed to create portfolio item")
+		}
+
+		return ItemDto{}, err
+	}
+
+	return itemToDto(item), nil
+}
+
+func (s *serviceImpl) ListItems(ctx context.Context, userId uint, includeHidden bool) ([]ItemDto, error) {
+	query := s.Db.WithContext(ctx).Where("user_id = ?", userId)
+	if !includeHidden {
+		query = query.Where("hidden = false AND pending_review = false")
+	}
+
+	var items []Item
+	result := query.Order("position asc").Find(&items)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list portfolio items")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]ItemDto, len(items))
+	for i, item := range items {
+		dtos[i] = itemToDto(item)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) UpdateItem(ctx context.Context, userId uint, itemId uint, dto NewItemDto, pendingReview bool) (ItemDto, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"userId": userId,
+		"itemId": itemId,
+	})
+
+	dto, err := s.checkItem(ctx, userId, dto)
+	if err != nil {
+		return ItemDto{}, err
+	}
+
+	item := Item{}
+	err = s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", itemId, userId).First(&item)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrItemNotFound
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		item.Title = dto.Title
+		item.Description = dto.Description
+		item.Url = dto.Url
+		item.ImageUrl = dto.ImageUrl
+		item.PendingReview = item.PendingReview || pendingReview
+
+		return tx.Save(&item).Error
+	})
+	if err != nil {
+		if !errors.Is(err, ErrItemNotFound) {
+			logger.WithError(err).Error("Failed to update portfolio item")
+		}
+
+		return ItemDto{}, err
+	}
+
+	return itemToDto(item), nil
+}
+
+func (s *serviceImpl) DeleteItem(ctx context.Context, userId uint, itemId uint) error {
+	result := s.Db.WithContext(ctx).Where("id = ? AND user_id = ?", itemId, userId).Delete(&Item{})
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete portfolio item")
+
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrItemNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ReorderItems(ctx context.Context, userId uint, itemIds []uint) error {
+	err := s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingIds []uint
+		result := tx.Model(&Item{}).Where("user_id = ?", userId).Pluck("id", &existingIds)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(existingIds) != len(itemIds) {
+			return ErrInvalidOrder
+		}
+
+		positions := make(map[uint]int, len(itemIds))
+		for i, id := range itemIds {
+			positions[id] = i
+		}
+
+		for _, id := range existingIds {
+			if _, ok := positions[id]; !ok {
+				return ErrInvalidOrder
+			}
+		}
+
+		for id, position := range positions {
+			result = tx.Model(&Item{}).Where("id = ?", id).Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrInvalidOrder) {
+		log.FromContext(ctx).WithError(err).Error("Failed to reorder portfolio items")
+	}
+
+	return err
+}
+
+func (s *serviceImpl) HideItem(ctx context.Context, moderatorId uint, itemId uint, dto HideItemDto) error {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return err
+	}
+
+	return s.setHidden(ctx, moderatorId, itemId, true, dto.Reason)
+}
+
+func (s *serviceImpl) UnhideItem(ctx context.Context, moderatorId uint, itemId uint) error {
+	return s.setHidden(ctx, moderatorId, itemId, false, "")
+}
+
+func (s *serviceImpl) setHidden(ctx context.Context, moderatorId uint, itemId uint, hidden bool, reason string) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"moderatorId": moderatorId,
+		"itemId":      itemId,
+	})
+
+	item := Item{}
+	result := s.Db.WithContext(ctx).First(&item, itemId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return ErrItemNotFound
+	} else if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get portfolio item")
+
+		return result.Error
+	}
+
+	result = s.Db.WithContext(ctx).Model(&item).Updates(map[string]interface{}{
+		"hidden":        hidden,
+		"hidden_reason": reason,
+	})
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to hide or unhide portfolio item")
+
+		return result.Error
+	}
+
+	action := "portfolio.unhide"
+	details := map[string]interface{}{"userId": item.UserId}
+	if hidden {
+		action = "portfolio.hide"
+		details["reason"] = reason
+	}
+
+	err := s.AuditService.Record(ctx, moderatorId, action, "portfolio-item", itemId, details)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record portfolio moderation in the audit log")
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ListPendingItems(ctx context.Context, pageSize uint, pageOffset uint) ([]PendingItemDto, int64, error) {
+	query := s.Db.WithContext(ctx).Model(&Item{}).Where("pending_review = true")
+
+	var totalCount int64
+	result := query.Session(&gorm.Session{}).Count(&totalCount)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to count portfolio items pending review")
+
+		return nil, 0, result.Error
+	}
+
+	var items []Item
+	result = query.
+		Order("created_at asc").
+		Limit(int(pageSize)).
+		Offset(int(pageOffset * pageSize)).
+		Find(&items)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list portfolio items pending review")
+
+		return nil, 0, result.Error
+	}
+
+	dtos := make([]PendingItemDto, len(items))
+	for i, item := range items {
+		dtos[i] = PendingItemDto{
+			ItemDto: itemToDto(item),
+			UserId:  item.UserId,
+		}
+	}
+
+	return dtos, totalCount, nil
+}
+
+func (s *serviceImpl) ApproveItem(ctx context.Context, moderatorId uint, itemId uint) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"moderatorId": moderatorId,
+		"itemId":      itemId,
+	})
+
+	item := Item{}
+	result := s.Db.WithContext(ctx).Where("pending_review = true").First(&item, itemId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return ErrItemNotFound
+	} else if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get portfolio item")
+
+		return result.Error
+	}
+
+	result = s.Db.WithContext(ctx).Model(&item).Update("pending_review", false)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to approve portfolio item")
+
+		return result.Error
+	}
+
+	details := map[string]interface{}{"userId": item.UserId}
+	err := s.AuditService.Record(ctx, moderatorId, "portfolio.approve", "portfolio-item", itemId, details)
+	if err != nil {
+		logger.WithError(err).Error("Failed to record portfolio moderation in the audit log")
+	}
+
+	return nil
+}
+
+// Validate an item, check that its links are web links and consult the
+// guards. Returns the item with its texts trimmed.
+func (s *serviceImpl) checkItem(ctx context.Context, userId uint, dto NewItemDto) (NewItemDto, error) {
+	dto.Title = strings.TrimSpace(dto.Title)
+	dto.Description = strings.TrimSpace(dto.Description)
+
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return NewItemDto{}, err
+	}
+
+	for _, link := range []string{dto.Url, dto.ImageUrl} {
+		if link == "" {
+			continue
+		}
+
+		// The url validator accepts any scheme, e.g. javascript:
+		parsed, err := url.Parse(link)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return NewItemDto{}, fmt.Errorf("%w: %s must be an http or https url", ErrInvalidLink, link)
+		}
+	}
+
+	for _, guard := range s.Guards {
+		err = guard.CheckItem(ctx, userId, dto)
+		if err != nil {
+			return NewItemDto{}, err
+		}
+	}
+
+	return dto, nil
+}
+
+func itemToDto(item Item) ItemDto {
+	return ItemDto{
+		Id:           item.ID,
+		Title:        item.Title,
+		Description:  item.Description,
+		Url:          item.Url,
+		ImageUrl:     item.ImageUrl,
+		Position:     item.Position,
+		Hidden:       item.Hidden,
+		HiddenReason: item.HiddenReason,
+	}
+}
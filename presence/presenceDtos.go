@@ -0,0 +1,13 @@
+package presence
+
+import "time"
+
+type PresenceDto struct {
+	// Whether the user has the site open, i.e. is connected to
+	// /presence/socket
+	Online bool `json:"online"`
+
+	// When the user was last connected, null if they haven't been for a
+	// month
+	LastSeenAt *time.Time `json:"lastSeenAt"`
+}
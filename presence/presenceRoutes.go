@@ -0,0 +1,108 @@
+package presence
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"golang.org/x/net/websocket"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Sent to clients every HeartbeatInterval, which also tells closed
+// connections apart.
+var heartbeatMessage = map[string]string{"type": "heartbeat"}
+
+// @Summary Connect to track presence
+// @Description A WebSocket that keeps the user online while it's open, e.g. while the site is open in a tab. The
+// @Description server sends {"type": "heartbeat"} every 25 seconds, clients don't need to send anything. Users are
+// @Description offline a minute after their last connection is closed at most. Connections must come from
+// @Description CORS_ORIGIN, and impersonation sessions can't connect so that admins don't make users look online.
+// @Tags presence
+// @Router /presence/socket [get]
+// @Success 101
+// @Failure 400 "The request isn't a WebSocket handshake"
+// @Failure 401
+// @Failure 403
+func RouteConnect(writer http.ResponseWriter, request *http.Request, presenceService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	if _, impersonated := session.ImpersonatorId(); impersonated {
+		return auth.ErrForbidden
+	}
+
+	server := websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(conn *websocket.Conn) {
+			serveConnection(request.Context(), conn, presenceService, session.UserId())
+		},
+	}
+
+	server.ServeHTTP(writer, request)
+
+	return nil
+}
+
+// Rejects handshakes from other sites, see utils.IsAllowedWebsocketOrigin.
+func checkOrigin(config *websocket.Config, request *http.Request) error {
+	if !utils.IsAllowedWebsocketOrigin(request) {
+		return errors.New("origin not allowed")
+	}
+
+	return nil
+}
+
+// Keep the user online until the connection is closed.
+func serveConnection(ctx context.Context, conn *websocket.Conn, presenceService Service, userId uint) {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	err := presenceService.Connect(ctx, userId)
+	if err != nil {
+		return
+	}
+
+	logger.Debug("Presence connection opened")
+
+	defer func() {
+		_ = presenceService.Disconnect(ctx, userId)
+
+		logger.Debug("Presence connection closed")
+	}()
+
+	// Clients don't send anything but closing frames, reading only tells
+	// when the connection is closed
+	closed := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, conn)
+		close(closed)
+	}()
+
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+
+		case <-ticker.C:
+			// Connections whose client went away without closing them
+			// fail to write
+			_ = conn.SetWriteDeadline(time.Now().Add(HeartbeatInterval))
+
+			err = websocket.JSON.Send(conn, heartbeatMessage)
+			if err != nil {
+				return
+			}
+
+			_ = presenceService.Heartbeat(ctx, userId)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package presence
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/kv"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// How often connected users' presence is refreshed. Under the idle timeout
+// of most proxies, so that the heartbeats keep sockets open.
+const HeartbeatInterval = 25 * time.Second
+
+// How long users stay online after their last heartbeat, e.g. when their
+// instance goes away without disconnecting them.
+const presenceTtl = 60 * time.Second
+
+// How long users' last connection is remembered.
+const lastSeenTtl = 30 * 24 * time.Hour
+
+type Service interface {
+	// Mark a user online, for a new connection of theirs. Connect and
+	// Disconnect must be called once per connection.
+	Connect(ctx context.Context, userId uint) error
+
+	// Keep a user online, every HeartbeatInterval while they're connected.
+	Heartbeat(ctx context.Context, userId uint) error
+
+	// Mark a user offline once their last connection to this instance is
+	// closed. Their connections to other instances mark them online again
+	// with their next heartbeat.
+	Disconnect(ctx context.Context, userId uint) error
+
+	// Get whether users are online and when they were last connected, by
+	// user id.
+	GetPresence(ctx context.Context, userIds []uint) (map[uint]PresenceDto, error)
+}
+
+type serviceImpl struct {
+	Kv kv.Store
+
+	mutex sync.Mutex
+
+	// How many connections users have to this instance
+	connections map[uint]int
+}
+
+func NewService(kvStore kv.Store) Service {
+	return &serviceImpl{
+		Kv:          kvStore,
+		connections: map[uint]int{},
+	}
+}
+
+func presenceKey(userId uint) string {
+	return fmt.Sprintf("user:%d:presence", userId)
+}
+
+func lastSeenKey(userId uint) string {
+	return fmt.Sprintf("user:%d:last.seen", userId)
+}
+
+func (s *serviceImpl) Connect(ctx context.Context, userId uint) error {
+	s.mutex.Lock()
+	s.connections[userId]++
+	s.mutex.Unlock()
+
+	return s.Heartbeat(ctx, userId)
+}
+
+func (s *serviceImpl) Heartbeat(ctx context.Context, userId uint) error {
+	now := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+
+	err := s.Kv.Set(ctx, presenceKey(userId), now, presenceTtl)
+	if err == nil {
+		err = s.Kv.Set(ctx, lastSeenKey(userId), now, lastSeenTtl)
+	}
+
+	if err != nil {
+		log.FromContext(ctx).WithError(err).WithField("userId", userId).Error("Failed to refresh presence")
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) Disconnect(ctx context.Context, userId uint) error {
+	s.mutex.Lock()
+	s.connections[userId]--
+	last := s.connections[userId] <= 0
+	if last {
+		delete(s.connections, userId)
+	}
+	s.mutex.Unlock()
+
+	if !last {
+		return nil
+	}
+
+	now := strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10)
+
+	err := s.Kv.Set(ctx, lastSeenKey(userId), now, lastSeenTtl)
+	if err == nil {
+		err = s.Kv.Del(ctx, presenceKey(userId))
+	}
+
+	if err != nil {
+		log.FromContext(ctx).WithError(err).WithField("userId", userId).Error("Failed to mark user offline")
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) GetPresence(ctx context.Context, userIds []uint) (map[uint]PresenceDto, error) {
+	presence := make(map[uint]PresenceDto, len(userIds))
+
+	for _, userId := range userIds {
+		online, err := s.Kv.Exists(ctx, presenceKey(userId))
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("Failed to get presence")
+
+			return nil, err
+		}
+
+		dto := PresenceDto{Online: online}
+
+		lastSeen, err := s.Kv.Get(ctx, lastSeenKey(userId))
+		if err != nil && !errors.Is(err, kv.ErrNotFound) {
+			log.FromContext(ctx).WithError(err).Error("Failed to get last seen time")
+
+			return nil, err
+		}
+
+		if milliseconds, err := strconv.ParseInt(lastSeen, 10, 64); err == nil {
+			lastSeenAt := time.Unix(0, milliseconds*int64(time.Millisecond)).UTC()
+			dto.LastSeenAt = &lastSeenAt
+		}
+
+		presence[userId] = dto
+	}
+
+	return presence, nil
+}
@@ -0,0 +1,938 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type gormRepository struct {
+	Db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &gormRepository{Db: db}
+}
+
+func (r *gormRepository) CreateProject(ctx context.Context, project *Project) error {
+	// Roles and links are created along with the project
+	return r.Db.WithContext(ctx).Create(project).Error
+}
+
+func (r *gormRepository) UpdateProject(ctx context.Context, projectId uint, project *Project) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only update the fields that can be edited, so that fields like
+		// the owner and the creation date are left untouched.
+		result := tx.
+			Model(&Project{Model: gorm.Model{ID: projectId}}).
+			Select("name", "tags", "long_description", "short_description", "github_link", "cover_image_url", "license", "code_of_conduct_url", "contributing_url", "languages", "frameworks", "platforms", "spoken_languages", "spoken_languages_detected", "time_zones", "quality_score").
+			Updates(project)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if result.RowsAffected < 1 {
+			return ErrProjectNotFound
+		}
+
+		err := updateRoles(tx, projectId, project.Roles)
+		if err != nil {
+			return err
+		}
+
+		err = updateProjectQuestions(tx, projectId, project.Questions)
+		if err != nil {
+			return err
+		}
+
+		// Replace the project's external links
+		result = tx.Where("project_id = ?", projectId).Delete(&ExternalLink{})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		for i := range project.ExternalLinks {
+			project.ExternalLinks[i].ProjectId = projectId
+		}
+
+		if len(project.ExternalLinks) > 0 {
+			result = tx.Create(&project.ExternalLinks)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+
+		return replaceFundingLinks(tx, projectId, project.FundingLinks)
+	})
+}
+
+// Replace a project's funding links. Links whose url didn't change keep their
+// click counters.
+// Update the project's roles to the given ones by id: roles with an id are
+// updated, the others created and the project's roles that aren't given are
+// deleted. Role ids stay the same, so that applications to a role and
+// applicants' forms still point to it after the project is edited.
+func updateRoles(tx *gorm.DB, projectId uint, roles []Role) error {
+	var existing []uint
+	result := tx.Model(&Role{}).Where("project_id = ?", projectId).Pluck("id", &existing)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	known := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		known[id] = true
+	}
+
+	kept := map[uint]bool{}
+	for i := range roles {
+		roles[i].ProjectId = projectId
+
+		if roles[i].ID != 0 {
+			if !known[roles[i].ID] {
+				return ErrRoleNotFound
+			}
+
+			kept[roles[i].ID] = true
+		}
+	}
+
+	var removed []uint
+	for _, id := range existing {
+		if !kept[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	if len(removed) > 0 {
+		result = tx.Delete(&Role{}, removed)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	for i := range roles {
+		if roles[i].ID != 0 {
+			result = tx.Model(&roles[i]).Select("title", "description", "skills", "weekly_hours", "seniority", "mentorship").Updates(&roles[i])
+		} else {
+			result = tx.Omit("Questions").Create(&roles[i])
+		}
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		err := updateRoleQuestions(tx, roles[i].ID, roles[i].Questions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Update a role's screening questions by id, like updateRoles.
+func updateRoleQuestions(tx *gorm.DB, roleId uint, questions []RoleQuestion) error {
+	var existing []uint
+	result := tx.Model(&RoleQuestion{}).Where("role_id = ?", roleId).Pluck("id", &existing)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	removed, err := keptQuestions(existing, len(questions), func(i int) uint { return questions[i].ID })
+	if err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		result = tx.Delete(&RoleQuestion{}, removed)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	for i := range questions {
+		questions[i].RoleId = roleId
+
+		if questions[i].ID != 0 {
+			result = tx.Model(&questions[i]).Select("text", "required").Updates(&questions[i])
+		} else {
+			result = tx.Create(&questions[i])
+		}
+
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	return nil
+}
+
+// Update a project's screening questions by id, like updateRoles.
+func updateProjectQuestions(tx *gorm.DB, projectId uint, questions []ProjectQuestion) error {
+	var existing []uint
+	result := tx.Model(&ProjectQuestion{}).Where("project_id = ?", projectId).Pluck("id", &existing)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	removed, err := keptQuestions(existing, len(questions), func(i int) uint { return questions[i].ID })
+	if err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		result = tx.Delete(&ProjectQuestion{}, removed)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	for i := range questions {
+		questions[i].ProjectId = projectId
+
+		if questions[i].ID != 0 {
+			result = tx.Model(&questions[i]).Select("text", "required").Updates(&questions[i])
+		} else {
+			result = tx.Create(&questions[i])
+		}
+
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	return nil
+}
+
+// Get the ids of the existing questions that aren't among the count given
+// ones, whose ids are returned by id (0 for new questions).
+// Returns ErrQuestionNotFound if a given id isn't one of the existing ones.
+func keptQuestions(existing []uint, count int, id func(i int) uint) ([]uint, error) {
+	known := make(map[uint]bool, len(existing))
+	for _, questionId := range existing {
+		known[questionId] = true
+	}
+
+	kept := map[uint]bool{}
+	for i := 0; i < count; i++ {
+		questionId := id(i)
+		if questionId == 0 {
+			continue
+		}
+
+		if !known[questionId] {
+			return nil, ErrQuestionNotFound
+		}
+
+		kept[questionId] = true
+	}
+
+	var removed []uint
+	for _, questionId := range existing {
+		if !kept[questionId] {
+			removed = append(removed, questionId)
+		}
+	}
+
+	return removed, nil
+}
+
+func replaceFundingLinks(tx *gorm.DB, projectId uint, links []FundingLink) error {
+	var existing []FundingLink
+	result := tx.Where("project_id = ?", projectId).Find(&existing)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	existingByUrl := make(map[string]FundingLink, len(existing))
+	for _, link := range existing {
+		existingByUrl[link.Url] = link
+	}
+
+	kept := map[uint]bool{}
+	for i := range links {
+		links[i].ProjectId = projectId
+
+		if previous, ok := existingByUrl[links[i].Url]; ok {
+			links[i].ID = previous.ID
+			kept[previous.ID] = true
+		}
+	}
+
+	var removed []uint
+	for _, link := range existing {
+		if !kept[link.ID] {
+			removed = append(removed, link.ID)
+		}
+	}
+
+	if len(removed) > 0 {
+		result = tx.Delete(&FundingLink{}, removed)
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	for i := range links {
+		if links[i].ID != 0 {
+			// Clicks are left alone, they may be counted concurrently
+			result = tx.Model(&links[i]).Select("platform", "label", "position").Updates(&links[i])
+		} else {
+			result = tx.Create(&links[i])
+		}
+
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	return nil
+}
+
+func (r *gormRepository) GetProject(ctx context.Context, projectId uint) (*Project, error) {
+	return firstProject(r.Db.WithContext(ctx).Preload("Roles"), projectId)
+}
+
+func (r *gormRepository) GetPublishedProject(ctx context.Context, projectId uint) (*Project, error) {
+	return firstProject(r.Db.WithContext(ctx).Preload("Roles").Where("pending_review = false AND draft = false"), projectId)
+}
+
+func (r *gormRepository) GetProjectDetails(ctx context.Context, projectId uint) (*Project, error) {
+	query := r.Db.WithContext(ctx).
+		Preload("Roles", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("Roles.Questions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("Questions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Preload("FundingLinks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		}).
+		Preload("ExternalLinks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position")
+		})
+
+	return firstProject(query, projectId)
+}
+
+// The project of a query. Returns ErrProjectNotFound if the query doesn't
+// match it.
+func firstProject(query *gorm.DB, projectId uint) (*Project, error) {
+	project := &Project{}
+	result := query.First(project, projectId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrProjectNotFound
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return project, nil
+}
+
+func (r *gormRepository) ListProjectsWithRoles(ctx context.Context, projectIds []uint) ([]Project, error) {
+	var projects []Project
+	result := r.Db.WithContext(ctx).Preload("Roles").Find(&projects, projectIds)
+
+	return projects, result.Error
+}
+
+func (r *gormRepository) ListProjects(
+	ctx context.Context,
+	filters ProjectFilters,
+	order ProjectOrder,
+	pageSize uint,
+	pageOffset uint,
+) ([]ProjectSummaryDto, int64, error) {
+	dialect := database.DialectOf(r.Db)
+
+	query := r.Db.WithContext(ctx).
+		Model(&Project{}).
+		Where("pending_review = false AND draft = false")
+
+	// Only filter by tags when there are tags to filter by. A condition that
+	// is always true when there are no tags (e.g. cardinality(?) < 1 OR ...)
+	// keeps postgres from using the GIN index on tags, see docs/database.md.
+	// The same goes for the other array filters.
+	if len(filters.Tags) > 0 {
+		query = query.Where(dialect.Overlaps("tags"), pq.StringArray(filters.Tags))
+	}
+
+	roles := r.filterRoles(ctx, filters)
+	if roles != nil {
+		query = query.Where("id IN (?)", roles.Select("project_id"))
+	}
+
+	if len(filters.Licenses) > 0 {
+		query = query.Where("license IN ?", filters.Licenses)
+	}
+
+	if len(filters.Statuses) > 0 {
+		query = query.Where("status IN ?", filters.Statuses)
+	}
+
+	stackFilters := []struct {
+		column string
+		values []string
+	}{
+		{"languages", filters.Languages},
+		{"frameworks", filters.Frameworks},
+		{"platforms", filters.Platforms},
+		{"spoken_languages", filters.SpokenLanguages},
+	}
+
+	for _, filter := range stackFilters {
+		if len(filter.values) > 0 {
+			query = query.Where(dialect.Overlaps(filter.column), pq.StringArray(filter.values))
+		}
+	}
+
+	if filters.Collection > 0 {
+		query = query.Where("id IN (SELECT project_id FROM collection_projects WHERE collection_id = ?)", filters.Collection)
+	}
+
+	orderBy := clause.Expr{SQL: "created_at desc"}
+	if order == OrderByQuality {
+		orderBy = clause.Expr{
+			SQL:  "quality_score + " + activityScoreSql + " desc, created_at desc",
+			Vars: activityScoreVars(),
+		}
+	} else if order == OrderByRecentlyUpdated {
+		orderBy = clause.Expr{SQL: "updated_at desc"}
+	}
+
+	return r.findProjectSummariesPage(ctx, query, orderBy, pageSize, pageOffset)
+}
+
+// A query of the roles matching the role filters, or nil if there are no role
+// filters.
+func (r *gormRepository) filterRoles(ctx context.Context, filters ProjectFilters) *gorm.DB {
+	if len(filters.Skills) < 1 && len(filters.Seniorities) < 1 && filters.MaxWeeklyHours < 1 && !filters.Mentorship {
+		return nil
+	}
+
+	query := r.Db.WithContext(ctx).Model(&Role{})
+
+	if len(filters.Skills) > 0 {
+		query = query.Where(database.DialectOf(r.Db).Overlaps("skills"), pq.StringArray(filters.Skills))
+	}
+
+	if len(filters.Seniorities) > 0 {
+		query = query.Where("seniority IN ?", filters.Seniorities)
+	}
+
+	if filters.MaxWeeklyHours > 0 {
+		query = query.Where("weekly_hours > 0 AND weekly_hours <= ?", filters.MaxWeeklyHours)
+	}
+
+	if filters.Mentorship {
+		query = query.Where("mentorship = true")
+	}
+
+	return query
+}
+
+func (r *gormRepository) ListProjectSummaries(ctx context.Context, projectIds []uint) ([]ProjectSummaryDto, error) {
+	var found []ProjectSummaryDto
+	result := r.Db.WithContext(ctx).
+		Model(&Project{}).
+		Select("name", "tags", "short_description", "id").
+		Where("id IN ? AND pending_review = false AND draft = false", projectIds).
+		Find(&found)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return found, r.addSummarySkills(ctx, found)
+}
+
+func (r *gormRepository) ListPendingProjects(ctx context.Context, pageSize uint, pageOffset uint) ([]ProjectSummaryDto, int64, error) {
+	query := r.Db.WithContext(ctx).
+		Model(&Project{}).
+		Where("pending_review = true")
+
+	return r.findProjectSummariesPage(ctx, query, clause.Expr{SQL: "created_at asc"}, pageSize, pageOffset)
+}
+
+// A project summary along with the total amount of rows matched by the query
+// it was selected by. See findProjectSummariesPage.
+type projectSummaryRow struct {
+	ProjectSummaryDto
+	TotalCount int64
+}
+
+// Select a page of project summaries matched by a query, ordered by orderBy,
+// and count all rows matched by the query. The count is computed with a window function in the same
+// query, so no extra round trip is needed unless the page is past the last
+// project, in which case no rows (and no count) are returned by it.
+func (r *gormRepository) findProjectSummariesPage(
+	ctx context.Context,
+	query *gorm.DB,
+	orderBy clause.Expr,
+	pageSize uint,
+	pageOffset uint,
+) ([]ProjectSummaryDto, int64, error) {
+	pageQuery := query.Session(&gorm.Session{}).Clauses(clause.OrderBy{Expression: orderBy})
+
+	var rows []projectSummaryRow
+	result := pageQuery.
+		Select("name", "tags", "short_description", "id", "count(*) OVER() AS total_count").
+		Limit(int(pageSize)).
+		Offset(int(pageOffset * pageSize)).
+		Find(&rows)
+
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	projectSummaries := make([]ProjectSummaryDto, len(rows))
+	for i, row := range rows {
+		projectSummaries[i] = row.ProjectSummaryDto
+	}
+
+	if len(rows) > 0 {
+		return projectSummaries, rows[0].TotalCount, r.addSummarySkills(ctx, projectSummaries)
+	}
+
+	var totalCount int64
+	if pageOffset > 0 {
+		result = query.Session(&gorm.Session{}).Count(&totalCount)
+		if result.Error != nil {
+			return nil, 0, result.Error
+		}
+	}
+
+	return projectSummaries, totalCount, nil
+}
+
+// Postgres' estimate of the table's size.
+func (r *gormRepository) EstimateProjectCount(ctx context.Context) (float64, error) {
+	var estimatedRows float64
+	result := r.Db.WithContext(ctx).Raw("SELECT reltuples FROM pg_class WHERE relname = 'projects'").Scan(&estimatedRows)
+
+	return estimatedRows, result.Error
+}
+
+// The sample is taken with TABLESAMPLE, which reads only percentage% of the
+// table's pages. Projects are picked with a weighted random order
+// (-ln(u)/weight, the exponential method) where the weight is the project's
+// quality score plus 10, so that projects without a score can be picked too.
+func (r *gormRepository) SampleProjects(
+	ctx context.Context,
+	count uint,
+	excludeOwnerId uint,
+	updatedAfter time.Time,
+	percentage float64,
+) ([]ProjectSummaryDto, error) {
+	var projectSummaries []ProjectSummaryDto
+	result := r.Db.WithContext(ctx).Raw(`
+		SELECT id, name, tags, short_description
+		FROM projects TABLESAMPLE SYSTEM (?)
+		WHERE deleted_at IS NULL
+		  AND pending_review = false
+		  AND draft = false
+		  AND updated_at > ?
+		  AND owner_id IS DISTINCT FROM ?
+		ORDER BY -ln(1 - random()) / (quality_score + `+activityScoreSql+` + 10)
+		LIMIT ?`,
+		append(append([]interface{}{percentage, updatedAfter, excludeOwnerId}, activityScoreVars()...), count)...,
+	).Scan(&projectSummaries)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return projectSummaries, r.addSummarySkills(ctx, projectSummaries)
+}
+
+// Set the summaries' skills from their projects' roles, see roleSkills.
+func (r *gormRepository) addSummarySkills(ctx context.Context, projectSummaries []ProjectSummaryDto) error {
+	if len(projectSummaries) < 1 {
+		return nil
+	}
+
+	projectIds := make([]uint, len(projectSummaries))
+	for i, projectSummary := range projectSummaries {
+		projectIds[i] = projectSummary.Id
+	}
+
+	var roles []Role
+	result := r.Db.WithContext(ctx).Select("project_id", "skills").Where("project_id IN ?", projectIds).Find(&roles)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	rolesByProject := make(map[uint][]Role, len(projectSummaries))
+	for _, role := range roles {
+		rolesByProject[role.ProjectId] = append(rolesByProject[role.ProjectId], role)
+	}
+
+	for i := range projectSummaries {
+		projectSummaries[i].Skills = roleSkills(rolesByProject[projectSummaries[i].Id])
+	}
+
+	return nil
+}
+
+func (r *gormRepository) ListProjectsSharingLabels(
+	ctx context.Context,
+	projectId uint,
+	tags []string,
+	skills []string,
+	limit int,
+) ([]Project, error) {
+	query := r.Db.WithContext(ctx).
+		Preload("Roles").
+		Where("pending_review = false AND draft = false AND id <> ?", projectId)
+
+	dialect := database.DialectOf(r.Db)
+	sharesTags := dialect.Overlaps("tags")
+	sharesSkills := "id IN (SELECT project_id FROM project_roles WHERE deleted_at IS NULL AND " + dialect.Overlaps("skills") + ")"
+
+	if len(tags) > 0 && len(skills) > 0 {
+		query = query.Where(sharesTags+" OR "+sharesSkills, pq.StringArray(tags), pq.StringArray(skills))
+	} else if len(tags) > 0 {
+		query = query.Where(sharesTags, pq.StringArray(tags))
+	} else {
+		query = query.Where(sharesSkills, pq.StringArray(skills))
+	}
+
+	var projects []Project
+	result := query.Limit(limit).Find(&projects)
+
+	return projects, result.Error
+}
+
+func (r *gormRepository) SetPendingReview(ctx context.Context, projectId uint, pendingReview bool) error {
+	return r.updateProjectColumn(ctx, projectId, "pending_review", pendingReview)
+}
+
+func (r *gormRepository) SetDraft(ctx context.Context, projectId uint, draft bool) error {
+	return r.updateProjectColumn(ctx, projectId, "draft", draft)
+}
+
+func (r *gormRepository) SetReadmeSync(ctx context.Context, projectId uint, enabled bool) error {
+	if enabled {
+		return r.updateProjectColumn(ctx, projectId, "readme_sync", true)
+	}
+
+	return r.updateProjectColumns(ctx, projectId, map[string]interface{}{
+		"readme_sync":      false,
+		"readme":           "",
+		"readme_synced_at": nil,
+	})
+}
+
+func (r *gormRepository) SaveReadme(ctx context.Context, projectId uint, readme string) error {
+	return r.updateProjectColumns(ctx, projectId, map[string]interface{}{
+		"readme":           readme,
+		"readme_synced_at": time.Now(),
+	})
+}
+
+func (r *gormRepository) GetTimeZones(ctx context.Context, projectIds []uint) (map[uint][]string, error) {
+	var projects []Project
+	result := r.Db.WithContext(ctx).
+		Select("id", "time_zones").
+		Where("id IN ?", projectIds).
+		Find(&projects)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	timeZones := make(map[uint][]string, len(projects))
+	for _, project := range projects {
+		timeZones[project.ID] = project.TimeZones
+	}
+
+	return timeZones, nil
+}
+
+func (r *gormRepository) ListMembers(ctx context.Context, projectId uint) ([]Member, error) {
+	acceptedApplicants := r.Db.
+		Table("applications").
+		Select("applicant_id").
+		Where("project_id = ? AND status = ? AND deleted_at IS NULL", projectId, "accepted")
+
+	var members []Member
+	result := r.Db.WithContext(ctx).
+		Table("users").
+		Select("users.id AS user_id, users.username, users.hide_memberships, users.id = projects.owner_id AS owner").
+		Joins("JOIN projects ON projects.id = ? AND projects.deleted_at IS NULL", projectId).
+		Where("users.deleted_at IS NULL AND (users.id = projects.owner_id OR users.id IN (?))", acceptedApplicants).
+		Order("owner desc, users.username").
+		Scan(&members)
+
+	return members, result.Error
+}
+
+func (r *gormRepository) ListMemberPermissions(ctx context.Context, projectId uint) ([]MemberPermissions, error) {
+	var permissions []MemberPermissions
+	result := r.Db.WithContext(ctx).Where("project_id = ?", projectId).Find(&permissions)
+
+	return permissions, result.Error
+}
+
+func (r *gormRepository) SaveMemberPermissions(ctx context.Context, permissions *MemberPermissions) error {
+	result := r.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "project_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"permissions", "granted_by", "updated_at"}),
+		}).
+		Create(permissions)
+
+	return result.Error
+}
+
+func (r *gormRepository) updateProjectColumn(ctx context.Context, projectId uint, column string, value interface{}) error {
+	return r.updateProjectColumns(ctx, projectId, map[string]interface{}{column: value})
+}
+
+func (r *gormRepository) updateProjectColumns(ctx context.Context, projectId uint, values map[string]interface{}) error {
+	result := r.Db.WithContext(ctx).
+		Model(&Project{}).
+		Where("id = ?", projectId).
+		Updates(values)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
+
+func (r *gormRepository) EditTags(ctx context.Context, projectId uint, edit func(project *Project) error) (*Project, error) {
+	project := &Project{}
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the project so that concurrent edits don't overwrite each other
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Roles").First(project, projectId)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrProjectNotFound
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		err := edit(project)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(project).Select("tags", "quality_score").Updates(project).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (r *gormRepository) EditRoleSkills(ctx context.Context, projectId uint, roleId uint, edit func(role *Role) error) (*Role, error) {
+	role := &Role{}
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND project_id = ?", roleId, projectId).
+			First(role)
+
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		err := edit(role)
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(role).Update("skills", role.Skills).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+func (r *gormRepository) ChangeStatus(
+	ctx context.Context,
+	projectId uint,
+	status ProjectStatus,
+	changedBy uint,
+	check func(project *Project) error,
+) (*Project, StatusChange, error) {
+	project := &Project{}
+	change := StatusChange{}
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the project so that concurrent changes can't both pass the
+		// check
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(project, projectId)
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return ErrProjectNotFound
+		} else if result.Error != nil {
+			return result.Error
+		}
+
+		err := check(project)
+		if err != nil {
+			return err
+		}
+
+		change = StatusChange{
+			ProjectId: projectId,
+			From:      project.Status,
+			To:        status,
+			ChangedBy: changedBy,
+		}
+
+		result = tx.Model(project).Update("status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Create(&change).Error
+	})
+	if err != nil {
+		return nil, StatusChange{}, err
+	}
+
+	return project, change, nil
+}
+
+func (r *gormRepository) ListStatusChanges(ctx context.Context, projectId uint) ([]StatusChange, error) {
+	var changes []StatusChange
+	result := r.Db.WithContext(ctx).
+		Where("project_id = ?", projectId).
+		Order("created_at desc").
+		Find(&changes)
+
+	return changes, result.Error
+}
+
+func (r *gormRepository) ListMemberIds(ctx context.Context, projectId uint) ([]uint, error) {
+	var memberIds []uint
+	result := r.Db.WithContext(ctx).
+		Table("applications").
+		Where("project_id = ? AND status = ? AND deleted_at IS NULL", projectId, "accepted").
+		Distinct().
+		Pluck("applicant_id", &memberIds)
+
+	return memberIds, result.Error
+}
+
+func (r *gormRepository) MergeTags(ctx context.Context, sources []string, target string) (int64, error) {
+	var affected int64
+	err := r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		result := tx.Model(&BannedTag{}).Where("name = ?", target).Count(&count)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if count > 0 {
+			return ErrTagBanned
+		}
+
+		// Replace the tags and drop the duplicates, keeping the
+		// position of each tag's first occurrence. updated_at is left
+		// untouched, the projects' owners didn't update them.
+		dialect := database.DialectOf(tx)
+		result = tx.Exec(
+			"UPDATE projects SET tags = "+dialect.ReplaceValues("tags")+" WHERE deleted_at IS NULL AND "+dialect.Overlaps("tags"),
+			pq.StringArray(sources),
+			target,
+			pq.StringArray(sources),
+		)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		affected = result.RowsAffected
+
+		return nil
+	})
+
+	return affected, err
+}
+
+func (r *gormRepository) BanTag(ctx context.Context, tag string, reason string) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("name = ?", tag).FirstOrCreate(&BannedTag{Name: tag, Reason: reason})
+		if result.Error != nil {
+			return result.Error
+		}
+
+		return tx.Exec(
+			"UPDATE projects SET tags = array_remove(tags, ?) WHERE deleted_at IS NULL AND "+database.DialectOf(tx).Overlaps("tags"),
+			tag,
+			pq.StringArray{tag},
+		).Error
+	})
+}
+
+func (r *gormRepository) UnbanTag(ctx context.Context, tag string) error {
+	// Deleted for good, so that the tag can be banned again
+	result := r.Db.WithContext(ctx).Unscoped().Where("name = ?", tag).Delete(&BannedTag{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrTagNotBanned
+	}
+
+	return nil
+}
+
+func (r *gormRepository) ListBannedTags(ctx context.Context) ([]BannedTag, error) {
+	var bannedTags []BannedTag
+	result := r.Db.WithContext(ctx).Order("name asc").Find(&bannedTags)
+
+	return bannedTags, result.Error
+}
+
+func (r *gormRepository) FindBannedTag(ctx context.Context, tags []string) (string, error) {
+	var banned []string
+	result := r.Db.WithContext(ctx).Model(&BannedTag{}).Where("name IN ?", tags).Limit(1).Pluck("name", &banned)
+	if result.Error != nil || len(banned) < 1 {
+		return "", result.Error
+	}
+
+	return banned[0], nil
+}
+
+func (r *gormRepository) GetFundingLink(ctx context.Context, projectId uint, fundingLinkId uint) (*FundingLink, error) {
+	link := &FundingLink{}
+	result := r.Db.WithContext(ctx).
+		Where("id = ? AND project_id = ?", fundingLinkId, projectId).
+		First(link)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrFundingLinkNotFound
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return link, nil
+}
+
+func (r *gormRepository) IncrementFundingClicks(ctx context.Context, fundingLinkId uint) error {
+	return r.Db.WithContext(ctx).
+		Model(&FundingLink{Model: gorm.Model{ID: fundingLinkId}}).
+		UpdateColumn("clicks", gorm.Expr("clicks + 1")).
+		Error
+}
@@ -0,0 +1,103 @@
+package projects_test
+
+import (
+	"context"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/testsupport"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+// A query run by the repository, with its parameters.
+type capturedQuery struct {
+	sql  string
+	vars []interface{}
+}
+
+// Listing projects by tags must be able to use the GIN index on tags, see
+// docs/database.md.
+func TestListProjectsUsesTagsIndex(t *testing.T) {
+	env := testsupport.NewEnv(t)
+	if database.IsSqlite(env.Db) {
+		t.Skip("the tags index only exists on Postgres")
+	}
+
+	alice := env.CreateUser(t, "alice", "password", users.RoleUser)
+	env.CreateProject(t, alice.ID, "Go web", "go", "web")
+	env.CreateProject(t, alice.ID, "Rust cli", "rust", "cli")
+
+	var queries []capturedQuery
+	err := env.Db.Callback().Query().After("gorm:query").Register("test:capture", func(db *gorm.DB) {
+		sql := db.Statement.SQL.String()
+		if strings.Contains(sql, "tags &&") {
+			queries = append(queries, capturedQuery{sql: sql, vars: append([]interface{}{}, db.Statement.Vars...)})
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository := projects.NewGormRepository(env.Db)
+	filters := projects.ProjectFilters{Tags: []string{"go"}}
+
+	// A page past the results also counts the projects
+	for _, pageOffset := range []uint{0, 1} {
+		_, _, err = repository.ListProjects(context.Background(), filters, projects.OrderByNewest, 20, pageOffset)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(queries) == 0 {
+		t.Fatal("no query filtered projects by tags")
+	}
+
+	for _, query := range queries {
+		plan := explain(t, env.Db, query)
+		if !strings.Contains(plan, "idx_projects_tags") {
+			t.Errorf("expected the plan of %s to use idx_projects_tags, got:\n%s", query.sql, plan)
+		}
+	}
+}
+
+// The plan of a query when the planner avoids sequential scans, as on a
+// table large enough for the index to be cheaper.
+func explain(t *testing.T, db *gorm.DB, query capturedQuery) string {
+	t.Helper()
+
+	var plan []string
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("SET LOCAL enable_seqscan = off").Error
+		if err != nil {
+			return err
+		}
+
+		// The captured SQL already has Postgres' placeholders ($1, ...),
+		// bypass gorm's
+		rows, err := tx.Statement.ConnPool.QueryContext(context.Background(), "EXPLAIN "+query.sql, query.vars...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var line string
+			err = rows.Scan(&line)
+			if err != nil {
+				return err
+			}
+
+			plan = append(plan, line)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		t.Fatalf("failed to explain %s: %v", query.sql, err)
+	}
+
+	return strings.Join(plan, "\n")
+}
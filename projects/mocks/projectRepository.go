@@ -0,0 +1,509 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: projectRepository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+	time "time"
+)
+
+// MockRepository is a mock of Repository interface
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateProject mocks base method
+func (m *MockRepository) CreateProject(ctx context.Context, project *projects.Project) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProject", ctx, project)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProject indicates an expected call of CreateProject
+func (mr *MockRepositoryMockRecorder) CreateProject(ctx, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProject", reflect.TypeOf((*MockRepository)(nil).CreateProject), ctx, project)
+}
+
+// UpdateProject mocks base method
+func (m *MockRepository) UpdateProject(ctx context.Context, projectId uint, project *projects.Project) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProject", ctx, projectId, project)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProject indicates an expected call of UpdateProject
+func (mr *MockRepositoryMockRecorder) UpdateProject(ctx, projectId, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProject", reflect.TypeOf((*MockRepository)(nil).UpdateProject), ctx, projectId, project)
+}
+
+// GetProject mocks base method
+func (m *MockRepository) GetProject(ctx context.Context, projectId uint) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProject", ctx, projectId)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProject indicates an expected call of GetProject
+func (mr *MockRepositoryMockRecorder) GetProject(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProject", reflect.TypeOf((*MockRepository)(nil).GetProject), ctx, projectId)
+}
+
+// GetPublishedProject mocks base method
+func (m *MockRepository) GetPublishedProject(ctx context.Context, projectId uint) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublishedProject", ctx, projectId)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublishedProject indicates an expected call of GetPublishedProject
+func (mr *MockRepositoryMockRecorder) GetPublishedProject(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublishedProject", reflect.TypeOf((*MockRepository)(nil).GetPublishedProject), ctx, projectId)
+}
+
+// GetProjectDetails mocks base method
+func (m *MockRepository) GetProjectDetails(ctx context.Context, projectId uint) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectDetails", ctx, projectId)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProjectDetails indicates an expected call of GetProjectDetails
+func (mr *MockRepositoryMockRecorder) GetProjectDetails(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectDetails", reflect.TypeOf((*MockRepository)(nil).GetProjectDetails), ctx, projectId)
+}
+
+// ListProjectsWithRoles mocks base method
+func (m *MockRepository) ListProjectsWithRoles(ctx context.Context, projectIds []uint) ([]projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectsWithRoles", ctx, projectIds)
+	ret0, _ := ret[0].([]projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjectsWithRoles indicates an expected call of ListProjectsWithRoles
+func (mr *MockRepositoryMockRecorder) ListProjectsWithRoles(ctx, projectIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectsWithRoles", reflect.TypeOf((*MockRepository)(nil).ListProjectsWithRoles), ctx, projectIds)
+}
+
+// ListProjects mocks base method
+func (m *MockRepository) ListProjects(ctx context.Context, filters projects.ProjectFilters, order projects.ProjectOrder, pageSize, pageOffset uint) ([]projects.ProjectSummaryDto, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjects", ctx, filters, order, pageSize, pageOffset)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjects indicates an expected call of ListProjects
+func (mr *MockRepositoryMockRecorder) ListProjects(ctx, filters, order, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjects", reflect.TypeOf((*MockRepository)(nil).ListProjects), ctx, filters, order, pageSize, pageOffset)
+}
+
+// ListProjectSummaries mocks base method
+func (m *MockRepository) ListProjectSummaries(ctx context.Context, projectIds []uint) ([]projects.ProjectSummaryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectSummaries", ctx, projectIds)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjectSummaries indicates an expected call of ListProjectSummaries
+func (mr *MockRepositoryMockRecorder) ListProjectSummaries(ctx, projectIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectSummaries", reflect.TypeOf((*MockRepository)(nil).ListProjectSummaries), ctx, projectIds)
+}
+
+// ListPendingProjects mocks base method
+func (m *MockRepository) ListPendingProjects(ctx context.Context, pageSize, pageOffset uint) ([]projects.ProjectSummaryDto, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingProjects", ctx, pageSize, pageOffset)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPendingProjects indicates an expected call of ListPendingProjects
+func (mr *MockRepositoryMockRecorder) ListPendingProjects(ctx, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingProjects", reflect.TypeOf((*MockRepository)(nil).ListPendingProjects), ctx, pageSize, pageOffset)
+}
+
+// EstimateProjectCount mocks base method
+func (m *MockRepository) EstimateProjectCount(ctx context.Context) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EstimateProjectCount", ctx)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EstimateProjectCount indicates an expected call of EstimateProjectCount
+func (mr *MockRepositoryMockRecorder) EstimateProjectCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateProjectCount", reflect.TypeOf((*MockRepository)(nil).EstimateProjectCount), ctx)
+}
+
+// SampleProjects mocks base method
+func (m *MockRepository) SampleProjects(ctx context.Context, count, excludeOwnerId uint, updatedAfter time.Time, percentage float64) ([]projects.ProjectSummaryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SampleProjects", ctx, count, excludeOwnerId, updatedAfter, percentage)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SampleProjects indicates an expected call of SampleProjects
+func (mr *MockRepositoryMockRecorder) SampleProjects(ctx, count, excludeOwnerId, updatedAfter, percentage interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SampleProjects", reflect.TypeOf((*MockRepository)(nil).SampleProjects), ctx, count, excludeOwnerId, updatedAfter, percentage)
+}
+
+// ListProjectsSharingLabels mocks base method
+func (m *MockRepository) ListProjectsSharingLabels(ctx context.Context, projectId uint, tags, skills []string, limit int) ([]projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectsSharingLabels", ctx, projectId, tags, skills, limit)
+	ret0, _ := ret[0].([]projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjectsSharingLabels indicates an expected call of ListProjectsSharingLabels
+func (mr *MockRepositoryMockRecorder) ListProjectsSharingLabels(ctx, projectId, tags, skills, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectsSharingLabels", reflect.TypeOf((*MockRepository)(nil).ListProjectsSharingLabels), ctx, projectId, tags, skills, limit)
+}
+
+// SetPendingReview mocks base method
+func (m *MockRepository) SetPendingReview(ctx context.Context, projectId uint, pendingReview bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPendingReview", ctx, projectId, pendingReview)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPendingReview indicates an expected call of SetPendingReview
+func (mr *MockRepositoryMockRecorder) SetPendingReview(ctx, projectId, pendingReview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPendingReview", reflect.TypeOf((*MockRepository)(nil).SetPendingReview), ctx, projectId, pendingReview)
+}
+
+// SetDraft mocks base method
+func (m *MockRepository) SetDraft(ctx context.Context, projectId uint, draft bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDraft", ctx, projectId, draft)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDraft indicates an expected call of SetDraft
+func (mr *MockRepositoryMockRecorder) SetDraft(ctx, projectId, draft interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDraft", reflect.TypeOf((*MockRepository)(nil).SetDraft), ctx, projectId, draft)
+}
+
+// EditTags mocks base method
+func (m *MockRepository) EditTags(ctx context.Context, projectId uint, edit func(*projects.Project) error) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditTags", ctx, projectId, edit)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditTags indicates an expected call of EditTags
+func (mr *MockRepositoryMockRecorder) EditTags(ctx, projectId, edit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditTags", reflect.TypeOf((*MockRepository)(nil).EditTags), ctx, projectId, edit)
+}
+
+// EditRoleSkills mocks base method
+func (m *MockRepository) EditRoleSkills(ctx context.Context, projectId, roleId uint, edit func(*projects.Role) error) (*projects.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditRoleSkills", ctx, projectId, roleId, edit)
+	ret0, _ := ret[0].(*projects.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditRoleSkills indicates an expected call of EditRoleSkills
+func (mr *MockRepositoryMockRecorder) EditRoleSkills(ctx, projectId, roleId, edit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditRoleSkills", reflect.TypeOf((*MockRepository)(nil).EditRoleSkills), ctx, projectId, roleId, edit)
+}
+
+// ChangeStatus mocks base method
+func (m *MockRepository) ChangeStatus(ctx context.Context, projectId uint, status projects.ProjectStatus, changedBy uint, check func(*projects.Project) error) (*projects.Project, projects.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, projectId, status, changedBy, check)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(projects.StatusChange)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus
+func (mr *MockRepositoryMockRecorder) ChangeStatus(ctx, projectId, status, changedBy, check interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockRepository)(nil).ChangeStatus), ctx, projectId, status, changedBy, check)
+}
+
+// ListStatusChanges mocks base method
+func (m *MockRepository) ListStatusChanges(ctx context.Context, projectId uint) ([]projects.StatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatusChanges", ctx, projectId)
+	ret0, _ := ret[0].([]projects.StatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatusChanges indicates an expected call of ListStatusChanges
+func (mr *MockRepositoryMockRecorder) ListStatusChanges(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatusChanges", reflect.TypeOf((*MockRepository)(nil).ListStatusChanges), ctx, projectId)
+}
+
+// ListMemberIds mocks base method
+func (m *MockRepository) ListMemberIds(ctx context.Context, projectId uint) ([]uint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMemberIds", ctx, projectId)
+	ret0, _ := ret[0].([]uint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMemberIds indicates an expected call of ListMemberIds
+func (mr *MockRepositoryMockRecorder) ListMemberIds(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMemberIds", reflect.TypeOf((*MockRepository)(nil).ListMemberIds), ctx, projectId)
+}
+
+// MergeTags mocks base method
+func (m *MockRepository) MergeTags(ctx context.Context, sources []string, target string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", ctx, sources, target)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeTags indicates an expected call of MergeTags
+func (mr *MockRepositoryMockRecorder) MergeTags(ctx, sources, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockRepository)(nil).MergeTags), ctx, sources, target)
+}
+
+// BanTag mocks base method
+func (m *MockRepository) BanTag(ctx context.Context, tag, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanTag", ctx, tag, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanTag indicates an expected call of BanTag
+func (mr *MockRepositoryMockRecorder) BanTag(ctx, tag, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanTag", reflect.TypeOf((*MockRepository)(nil).BanTag), ctx, tag, reason)
+}
+
+// UnbanTag mocks base method
+func (m *MockRepository) UnbanTag(ctx context.Context, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanTag", ctx, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanTag indicates an expected call of UnbanTag
+func (mr *MockRepositoryMockRecorder) UnbanTag(ctx, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanTag", reflect.TypeOf((*MockRepository)(nil).UnbanTag), ctx, tag)
+}
+
+// ListBannedTags mocks base method
+func (m *MockRepository) ListBannedTags(ctx context.Context) ([]projects.BannedTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBannedTags", ctx)
+	ret0, _ := ret[0].([]projects.BannedTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBannedTags indicates an expected call of ListBannedTags
+func (mr *MockRepositoryMockRecorder) ListBannedTags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBannedTags", reflect.TypeOf((*MockRepository)(nil).ListBannedTags), ctx)
+}
+
+// FindBannedTag mocks base method
+func (m *MockRepository) FindBannedTag(ctx context.Context, tags []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindBannedTag", ctx, tags)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindBannedTag indicates an expected call of FindBannedTag
+func (mr *MockRepositoryMockRecorder) FindBannedTag(ctx, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindBannedTag", reflect.TypeOf((*MockRepository)(nil).FindBannedTag), ctx, tags)
+}
+
+// GetFundingLink mocks base method
+func (m *MockRepository) GetFundingLink(ctx context.Context, projectId, fundingLinkId uint) (*projects.FundingLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFundingLink", ctx, projectId, fundingLinkId)
+	ret0, _ := ret[0].(*projects.FundingLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFundingLink indicates an expected call of GetFundingLink
+func (mr *MockRepositoryMockRecorder) GetFundingLink(ctx, projectId, fundingLinkId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFundingLink", reflect.TypeOf((*MockRepository)(nil).GetFundingLink), ctx, projectId, fundingLinkId)
+}
+
+// IncrementFundingClicks mocks base method
+func (m *MockRepository) IncrementFundingClicks(ctx context.Context, fundingLinkId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementFundingClicks", ctx, fundingLinkId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementFundingClicks indicates an expected call of IncrementFundingClicks
+func (mr *MockRepositoryMockRecorder) IncrementFundingClicks(ctx, fundingLinkId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementFundingClicks", reflect.TypeOf((*MockRepository)(nil).IncrementFundingClicks), ctx, fundingLinkId)
+}
+
+// SetReadmeSync mocks base method
+func (m *MockRepository) SetReadmeSync(ctx context.Context, projectId uint, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadmeSync", ctx, projectId, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReadmeSync indicates an expected call of SetReadmeSync
+func (mr *MockRepositoryMockRecorder) SetReadmeSync(ctx, projectId, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadmeSync", reflect.TypeOf((*MockRepository)(nil).SetReadmeSync), ctx, projectId, enabled)
+}
+
+// SaveReadme mocks base method
+func (m *MockRepository) SaveReadme(ctx context.Context, projectId uint, readme string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReadme", ctx, projectId, readme)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReadme indicates an expected call of SaveReadme
+func (mr *MockRepositoryMockRecorder) SaveReadme(ctx, projectId, readme interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReadme", reflect.TypeOf((*MockRepository)(nil).SaveReadme), ctx, projectId, readme)
+}
+
+// GetTimeZones mocks base method
+func (m *MockRepository) GetTimeZones(ctx context.Context, projectIds []uint) (map[uint][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTimeZones", ctx, projectIds)
+	ret0, _ := ret[0].(map[uint][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeZones indicates an expected call of GetTimeZones
+func (mr *MockRepositoryMockRecorder) GetTimeZones(ctx, projectIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeZones", reflect.TypeOf((*MockRepository)(nil).GetTimeZones), ctx, projectIds)
+}
+
+// ListMembers mocks base method
+func (m *MockRepository) ListMembers(ctx context.Context, projectId uint) ([]projects.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, projectId)
+	ret0, _ := ret[0].([]projects.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers
+func (mr *MockRepositoryMockRecorder) ListMembers(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockRepository)(nil).ListMembers), ctx, projectId)
+}
+
+// ListMemberPermissions mocks base method
+func (m *MockRepository) ListMemberPermissions(ctx context.Context, projectId uint) ([]projects.MemberPermissions, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMemberPermissions", ctx, projectId)
+	ret0, _ := ret[0].([]projects.MemberPermissions)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMemberPermissions indicates an expected call of ListMemberPermissions
+func (mr *MockRepositoryMockRecorder) ListMemberPermissions(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMemberPermissions", reflect.TypeOf((*MockRepository)(nil).ListMemberPermissions), ctx, projectId)
+}
+
+// SaveMemberPermissions mocks base method
+func (m *MockRepository) SaveMemberPermissions(ctx context.Context, permissions *projects.MemberPermissions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMemberPermissions", ctx, permissions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMemberPermissions indicates an expected call of SaveMemberPermissions
+func (mr *MockRepositoryMockRecorder) SaveMemberPermissions(ctx, permissions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMemberPermissions", reflect.TypeOf((*MockRepository)(nil).SaveMemberPermissions), ctx, permissions)
+}
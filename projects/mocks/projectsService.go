@@ -0,0 +1,527 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: projectsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateProject mocks base method
+func (m *MockService) CreateProject(ctx context.Context, ownerId uint, newProject projects.NewProjectDto, pendingReview bool) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProject", ctx, ownerId, newProject, pendingReview)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProject indicates an expected call of CreateProject
+func (mr *MockServiceMockRecorder) CreateProject(ctx, ownerId, newProject, pendingReview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProject", reflect.TypeOf((*MockService)(nil).CreateProject), ctx, ownerId, newProject, pendingReview)
+}
+
+// UpdateProject mocks base method
+func (m *MockService) UpdateProject(ctx context.Context, projectId uint, projectData projects.NewProjectDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProject", ctx, projectId, projectData)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProject indicates an expected call of UpdateProject
+func (mr *MockServiceMockRecorder) UpdateProject(ctx, projectId, projectData interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProject", reflect.TypeOf((*MockService)(nil).UpdateProject), ctx, projectId, projectData)
+}
+
+// GetProjectSummary mocks base method
+func (m *MockService) GetProjectSummary(project *projects.Project) projects.ProjectSummaryDto {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectSummary", project)
+	ret0, _ := ret[0].(projects.ProjectSummaryDto)
+	return ret0
+}
+
+// GetProjectSummary indicates an expected call of GetProjectSummary
+func (mr *MockServiceMockRecorder) GetProjectSummary(project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectSummary", reflect.TypeOf((*MockService)(nil).GetProjectSummary), project)
+}
+
+// GetProject mocks base method
+func (m *MockService) GetProject(ctx context.Context, projectId uint) (projects.ProjectDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProject", ctx, projectId)
+	ret0, _ := ret[0].(projects.ProjectDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProject indicates an expected call of GetProject
+func (mr *MockServiceMockRecorder) GetProject(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProject", reflect.TypeOf((*MockService)(nil).GetProject), ctx, projectId)
+}
+
+// GetProjectQuality mocks base method
+func (m *MockService) GetProjectQuality(ctx context.Context, projectId uint) (projects.QualityDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProjectQuality", ctx, projectId)
+	ret0, _ := ret[0].(projects.QualityDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProjectQuality indicates an expected call of GetProjectQuality
+func (mr *MockServiceMockRecorder) GetProjectQuality(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProjectQuality", reflect.TypeOf((*MockService)(nil).GetProjectQuality), ctx, projectId)
+}
+
+// ListProjects mocks base method
+func (m *MockService) ListProjects(ctx context.Context, pageSize, pageOffset uint, filters projects.ProjectFilters, order projects.ProjectOrder) ([]projects.ProjectSummaryDto, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjects", ctx, pageSize, pageOffset, filters, order)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListProjects indicates an expected call of ListProjects
+func (mr *MockServiceMockRecorder) ListProjects(ctx, pageSize, pageOffset, filters, order interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjects", reflect.TypeOf((*MockService)(nil).ListProjects), ctx, pageSize, pageOffset, filters, order)
+}
+
+// ListProjectsById mocks base method
+func (m *MockService) ListProjectsById(ctx context.Context, projectIds []uint) ([]projects.ProjectSummaryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListProjectsById", ctx, projectIds)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListProjectsById indicates an expected call of ListProjectsById
+func (mr *MockServiceMockRecorder) ListProjectsById(ctx, projectIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListProjectsById", reflect.TypeOf((*MockService)(nil).ListProjectsById), ctx, projectIds)
+}
+
+// ListPendingProjects mocks base method
+func (m *MockService) ListPendingProjects(ctx context.Context, pageSize, pageOffset uint) ([]projects.ProjectSummaryDto, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPendingProjects", ctx, pageSize, pageOffset)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListPendingProjects indicates an expected call of ListPendingProjects
+func (mr *MockServiceMockRecorder) ListPendingProjects(ctx, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPendingProjects", reflect.TypeOf((*MockService)(nil).ListPendingProjects), ctx, pageSize, pageOffset)
+}
+
+// DiscoverProjects mocks base method
+func (m *MockService) DiscoverProjects(ctx context.Context, count, excludeOwnerId uint) ([]projects.ProjectSummaryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiscoverProjects", ctx, count, excludeOwnerId)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiscoverProjects indicates an expected call of DiscoverProjects
+func (mr *MockServiceMockRecorder) DiscoverProjects(ctx, count, excludeOwnerId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiscoverProjects", reflect.TypeOf((*MockService)(nil).DiscoverProjects), ctx, count, excludeOwnerId)
+}
+
+// SimilarProjects mocks base method
+func (m *MockService) SimilarProjects(ctx context.Context, projectId uint) ([]projects.ProjectSummaryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimilarProjects", ctx, projectId)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimilarProjects indicates an expected call of SimilarProjects
+func (mr *MockServiceMockRecorder) SimilarProjects(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimilarProjects", reflect.TypeOf((*MockService)(nil).SimilarProjects), ctx, projectId)
+}
+
+// MergeTags mocks base method
+func (m *MockService) MergeTags(ctx context.Context, sources []string, target string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", ctx, sources, target)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeTags indicates an expected call of MergeTags
+func (mr *MockServiceMockRecorder) MergeTags(ctx, sources, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockService)(nil).MergeTags), ctx, sources, target)
+}
+
+// BanTag mocks base method
+func (m *MockService) BanTag(ctx context.Context, tag, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanTag", ctx, tag, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanTag indicates an expected call of BanTag
+func (mr *MockServiceMockRecorder) BanTag(ctx, tag, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanTag", reflect.TypeOf((*MockService)(nil).BanTag), ctx, tag, reason)
+}
+
+// UnbanTag mocks base method
+func (m *MockService) UnbanTag(ctx context.Context, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanTag", ctx, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanTag indicates an expected call of UnbanTag
+func (mr *MockServiceMockRecorder) UnbanTag(ctx, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanTag", reflect.TypeOf((*MockService)(nil).UnbanTag), ctx, tag)
+}
+
+// ListBannedTags mocks base method
+func (m *MockService) ListBannedTags(ctx context.Context) ([]projects.BannedTagDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBannedTags", ctx)
+	ret0, _ := ret[0].([]projects.BannedTagDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBannedTags indicates an expected call of ListBannedTags
+func (mr *MockServiceMockRecorder) ListBannedTags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBannedTags", reflect.TypeOf((*MockService)(nil).ListBannedTags), ctx)
+}
+
+// ApproveProject mocks base method
+func (m *MockService) ApproveProject(ctx context.Context, projectId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveProject", ctx, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApproveProject indicates an expected call of ApproveProject
+func (mr *MockServiceMockRecorder) ApproveProject(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveProject", reflect.TypeOf((*MockService)(nil).ApproveProject), ctx, projectId)
+}
+
+// ChangeStatus mocks base method
+func (m *MockService) ChangeStatus(ctx context.Context, userId, projectId uint, status projects.ProjectStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStatus", ctx, userId, projectId, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeStatus indicates an expected call of ChangeStatus
+func (mr *MockServiceMockRecorder) ChangeStatus(ctx, userId, projectId, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStatus", reflect.TypeOf((*MockService)(nil).ChangeStatus), ctx, userId, projectId, status)
+}
+
+// ListStatusChanges mocks base method
+func (m *MockService) ListStatusChanges(ctx context.Context, projectId uint) ([]projects.StatusChangeDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStatusChanges", ctx, projectId)
+	ret0, _ := ret[0].([]projects.StatusChangeDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStatusChanges indicates an expected call of ListStatusChanges
+func (mr *MockServiceMockRecorder) ListStatusChanges(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStatusChanges", reflect.TypeOf((*MockService)(nil).ListStatusChanges), ctx, projectId)
+}
+
+// EditTags mocks base method
+func (m *MockService) EditTags(ctx context.Context, projectId uint, dto projects.BulkEditDto) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditTags", ctx, projectId, dto)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditTags indicates an expected call of EditTags
+func (mr *MockServiceMockRecorder) EditTags(ctx, projectId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditTags", reflect.TypeOf((*MockService)(nil).EditTags), ctx, projectId, dto)
+}
+
+// EditRoleSkills mocks base method
+func (m *MockService) EditRoleSkills(ctx context.Context, projectId, roleId uint, dto projects.BulkEditDto) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditRoleSkills", ctx, projectId, roleId, dto)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditRoleSkills indicates an expected call of EditRoleSkills
+func (mr *MockServiceMockRecorder) EditRoleSkills(ctx, projectId, roleId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditRoleSkills", reflect.TypeOf((*MockService)(nil).EditRoleSkills), ctx, projectId, roleId, dto)
+}
+
+// CloneProject mocks base method
+func (m *MockService) CloneProject(ctx context.Context, ownerId, projectId uint, pendingReview bool) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloneProject", ctx, ownerId, projectId, pendingReview)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloneProject indicates an expected call of CloneProject
+func (mr *MockServiceMockRecorder) CloneProject(ctx, ownerId, projectId, pendingReview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloneProject", reflect.TypeOf((*MockService)(nil).CloneProject), ctx, ownerId, projectId, pendingReview)
+}
+
+// ImportProject mocks base method
+func (m *MockService) ImportProject(ctx context.Context, ownerId uint, imported projects.ImportedProjectDto, pendingReview bool) (*projects.Project, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportProject", ctx, ownerId, imported, pendingReview)
+	ret0, _ := ret[0].(*projects.Project)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportProject indicates an expected call of ImportProject
+func (mr *MockServiceMockRecorder) ImportProject(ctx, ownerId, imported, pendingReview interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportProject", reflect.TypeOf((*MockService)(nil).ImportProject), ctx, ownerId, imported, pendingReview)
+}
+
+// PublishProject mocks base method
+func (m *MockService) PublishProject(ctx context.Context, projectId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishProject", ctx, projectId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishProject indicates an expected call of PublishProject
+func (mr *MockServiceMockRecorder) PublishProject(ctx, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishProject", reflect.TypeOf((*MockService)(nil).PublishProject), ctx, projectId)
+}
+
+// RecordFundingClick mocks base method
+func (m *MockService) RecordFundingClick(ctx context.Context, projectId, fundingLinkId uint, clientIp string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordFundingClick", ctx, projectId, fundingLinkId, clientIp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordFundingClick indicates an expected call of RecordFundingClick
+func (mr *MockServiceMockRecorder) RecordFundingClick(ctx, projectId, fundingLinkId, clientIp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFundingClick", reflect.TypeOf((*MockService)(nil).RecordFundingClick), ctx, projectId, fundingLinkId, clientIp)
+}
+
+// SetReadmeSync mocks base method
+func (m *MockService) SetReadmeSync(ctx context.Context, projectId uint, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReadmeSync", ctx, projectId, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReadmeSync indicates an expected call of SetReadmeSync
+func (mr *MockServiceMockRecorder) SetReadmeSync(ctx, projectId, enabled interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReadmeSync", reflect.TypeOf((*MockService)(nil).SetReadmeSync), ctx, projectId, enabled)
+}
+
+// SaveReadme mocks base method
+func (m *MockService) SaveReadme(ctx context.Context, projectId uint, readme string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReadme", ctx, projectId, readme)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReadme indicates an expected call of SaveReadme
+func (mr *MockServiceMockRecorder) SaveReadme(ctx, projectId, readme interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReadme", reflect.TypeOf((*MockService)(nil).SaveReadme), ctx, projectId, readme)
+}
+
+// GetOverlapHours mocks base method
+func (m *MockService) GetOverlapHours(ctx context.Context, userId uint, projectIds []uint) (map[uint]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverlapHours", ctx, userId, projectIds)
+	ret0, _ := ret[0].(map[uint]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverlapHours indicates an expected call of GetOverlapHours
+func (mr *MockServiceMockRecorder) GetOverlapHours(ctx, userId, projectIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverlapHours", reflect.TypeOf((*MockService)(nil).GetOverlapHours), ctx, userId, projectIds)
+}
+
+// ListMembers mocks base method
+func (m *MockService) ListMembers(ctx context.Context, viewerId, projectId uint) ([]projects.MemberDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMembers", ctx, viewerId, projectId)
+	ret0, _ := ret[0].([]projects.MemberDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMembers indicates an expected call of ListMembers
+func (mr *MockServiceMockRecorder) ListMembers(ctx, viewerId, projectId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMembers", reflect.TypeOf((*MockService)(nil).ListMembers), ctx, viewerId, projectId)
+}
+
+// IsMember mocks base method
+func (m *MockService) IsMember(ctx context.Context, projectId, userId uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMember", ctx, projectId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsMember indicates an expected call of IsMember
+func (mr *MockServiceMockRecorder) IsMember(ctx, projectId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMember", reflect.TypeOf((*MockService)(nil).IsMember), ctx, projectId, userId)
+}
+
+// GetPermissions mocks base method
+func (m *MockService) GetPermissions(ctx context.Context, projectId, userId uint) ([]projects.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPermissions", ctx, projectId, userId)
+	ret0, _ := ret[0].([]projects.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPermissions indicates an expected call of GetPermissions
+func (mr *MockServiceMockRecorder) GetPermissions(ctx, projectId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissions", reflect.TypeOf((*MockService)(nil).GetPermissions), ctx, projectId, userId)
+}
+
+// HasPermission mocks base method
+func (m *MockService) HasPermission(ctx context.Context, projectId, userId uint, permission projects.Permission) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPermission", ctx, projectId, userId, permission)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPermission indicates an expected call of HasPermission
+func (mr *MockServiceMockRecorder) HasPermission(ctx, projectId, userId, permission interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPermission", reflect.TypeOf((*MockService)(nil).HasPermission), ctx, projectId, userId, permission)
+}
+
+// SetPermissions mocks base method
+func (m *MockService) SetPermissions(ctx context.Context, grantedBy, projectId, userId uint, dto projects.MemberPermissionsDto) ([]projects.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPermissions", ctx, grantedBy, projectId, userId, dto)
+	ret0, _ := ret[0].([]projects.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPermissions indicates an expected call of SetPermissions
+func (mr *MockServiceMockRecorder) SetPermissions(ctx, grantedBy, projectId, userId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPermissions", reflect.TypeOf((*MockService)(nil).SetPermissions), ctx, grantedBy, projectId, userId, dto)
+}
+
+// MockProjectListener is a mock of ProjectListener interface
+type MockProjectListener struct {
+	ctrl     *gomock.Controller
+	recorder *MockProjectListenerMockRecorder
+}
+
+// MockProjectListenerMockRecorder is the mock recorder for MockProjectListener
+type MockProjectListenerMockRecorder struct {
+	mock *MockProjectListener
+}
+
+// NewMockProjectListener creates a new mock instance
+func NewMockProjectListener(ctrl *gomock.Controller) *MockProjectListener {
+	mock := &MockProjectListener{ctrl: ctrl}
+	mock.recorder = &MockProjectListenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockProjectListener) EXPECT() *MockProjectListenerMockRecorder {
+	return m.recorder
+}
+
+// ProjectSaved mocks base method
+func (m *MockProjectListener) ProjectSaved(ctx context.Context, project *projects.Project) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ProjectSaved", ctx, project)
+}
+
+// ProjectSaved indicates an expected call of ProjectSaved
+func (mr *MockProjectListenerMockRecorder) ProjectSaved(ctx, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectSaved", reflect.TypeOf((*MockProjectListener)(nil).ProjectSaved), ctx, project)
+}
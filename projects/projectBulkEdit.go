@@ -0,0 +1,138 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"strings"
+)
+
+// Limits of NewProjectDto, which bulk edits must respect too.
+const (
+	minTags       = 1
+	maxTags       = 6
+	maxRoleSkills = 10
+)
+
+var ErrRoleNotFound = errors.New("role not found")
+var ErrTagCount = fmt.Errorf("a project must have between %d and %d tags", minTags, maxTags)
+var ErrSkillCount = fmt.Errorf("a role can have at most %d skills", maxRoleSkills)
+
+func (s *serviceImpl) EditTags(ctx context.Context, projectId uint, dto BulkEditDto) ([]string, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	added := normalizeLabels(dto.Add)
+
+	err = s.checkBannedTags(ctx, added)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	project, err := s.Repository.EditTags(ctx, projectId, func(project *Project) error {
+		project.Tags = editLabels(project.Tags, added, normalizeLabels(dto.Remove))
+		if len(project.Tags) < minTags || len(project.Tags) > maxTags {
+			return ErrTagCount
+		}
+
+		project.QualityScore = storedQualityScore(project)
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) && !errors.Is(err, ErrTagCount) {
+			logger.WithError(err).Error("Failed to edit project tags")
+		}
+
+		return nil, err
+	}
+
+	s.notifyListeners(ctx, projectId)
+
+	return project.Tags, nil
+}
+
+func (s *serviceImpl) EditRoleSkills(ctx context.Context, projectId uint, roleId uint, dto BulkEditDto) ([]string, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId": projectId,
+		"roleId":    roleId,
+	})
+
+	role, err := s.Repository.EditRoleSkills(ctx, projectId, roleId, func(role *Role) error {
+		role.Skills = editLabels(role.Skills, normalizeLabels(dto.Add), normalizeLabels(dto.Remove))
+		if len(role.Skills) > maxRoleSkills {
+			return ErrSkillCount
+		}
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrRoleNotFound) && !errors.Is(err, ErrSkillCount) {
+			logger.WithError(err).Error("Failed to edit role skills")
+		}
+
+		return nil, err
+	}
+
+	s.notifyListeners(ctx, projectId)
+
+	return role.Skills, nil
+}
+
+// Trim labels (tags or skills) and collapse their inner whitespace.
+func normalizeLabels(labels []string) []string {
+	normalized := make([]string, 0, len(labels))
+	for _, label := range labels {
+		label = strings.Join(strings.Fields(label), " ")
+		if label != "" {
+			normalized = append(normalized, label)
+		}
+	}
+
+	return normalized
+}
+
+// Remove labels from a list and append others to it. Labels are compared
+// case-insensitively and each ends up in the list at most once, keeping the
+// first occurrence.
+func editLabels(labels pq.StringArray, add []string, remove []string) pq.StringArray {
+	removed := map[string]bool{}
+	for _, label := range remove {
+		removed[strings.ToLower(label)] = true
+	}
+
+	seen := map[string]bool{}
+	edited := pq.StringArray{}
+
+	keep := func(label string) {
+		key := strings.ToLower(label)
+		if !seen[key] {
+			seen[key] = true
+			edited = append(edited, label)
+		}
+	}
+
+	for _, label := range labels {
+		if !removed[strings.ToLower(label)] {
+			keep(label)
+		}
+	}
+
+	for _, label := range add {
+		keep(label)
+	}
+
+	return edited
+}
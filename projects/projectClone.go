@@ -0,0 +1,160 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+func (s *serviceImpl) CloneProject(ctx context.Context, ownerId uint, projectId uint, pendingReview bool) (*Project, error) {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	source, err := s.Repository.GetProjectDetails(ctx, projectId)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to query for project")
+		}
+
+		return nil, err
+	}
+
+	// Only the project's structure is copied, the clone is a new project: it
+	// starts as an idea, without a repository or a cover image of its own.
+	project := Project{
+		Name:             source.Name,
+		Tags:             copyLabels(source.Tags),
+		LongDescription:  source.LongDescription,
+		ShortDescription: source.ShortDescription,
+		License:          source.License,
+		CodeOfConductUrl: source.CodeOfConductUrl,
+		ContributingUrl:  source.ContributingUrl,
+		Languages:        copyLabels(source.Languages),
+		Frameworks:       copyLabels(source.Frameworks),
+		Platforms:        copyLabels(source.Platforms),
+		Status:           StatusIdea,
+		Roles:            cloneRoles(source.Roles),
+		Questions:        cloneProjectQuestions(source.Questions),
+		OwnerId:          ownerId,
+		PendingReview:    pendingReview,
+		Draft:            true,
+
+		SpokenLanguages:         copyLabels(source.SpokenLanguages),
+		SpokenLanguagesDetected: source.SpokenLanguagesDetected,
+		TimeZones:               copyLabels(source.TimeZones),
+	}
+
+	project.QualityScore = storedQualityScore(&project)
+
+	// Roles and their questions are created along with the project
+	err = s.Repository.CreateProject(ctx, &project)
+	if err != nil {
+		logger.WithError(err).Error("Failed to clone project")
+
+		return nil, err
+	}
+
+	logger.WithField("cloneId", project.ID).Info("Project cloned")
+	createdCounter.Inc()
+
+	for _, listener := range s.Listeners {
+		listener.ProjectSaved(ctx, &project)
+	}
+
+	return &project, nil
+}
+
+// Drafts aren't checked when they're created (imports are drafts precisely
+// so that owners can complete them), they're checked like a project update
+// when they're published.
+func (s *serviceImpl) PublishProject(ctx context.Context, projectId uint) error {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	project, err := s.Repository.GetProjectDetails(ctx, projectId)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to query for project")
+		}
+
+		return err
+	}
+
+	projectData := projectToNewDto(project)
+
+	err = validator.New().Struct(projectData)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkRules(ctx, project.OwnerId, projectData)
+	if err != nil {
+		return err
+	}
+
+	err = s.checkBannedTags(ctx, projectData.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = s.Repository.SetDraft(ctx, projectId, false)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to publish project")
+		}
+
+		return err
+	}
+
+	logger.Info("Project published")
+
+	s.notifyListeners(ctx, projectId)
+
+	return nil
+}
+
+// Copy roles, with their questions, so that they can be created for another
+// project.
+func cloneRoles(roles []Role) []Role {
+	clones := make([]Role, len(roles))
+	for i, role := range roles {
+		questions := make([]RoleQuestion, len(role.Questions))
+		for j, question := range role.Questions {
+			questions[j] = RoleQuestion{
+				Text:     question.Text,
+				Required: question.Required,
+			}
+		}
+
+		clones[i] = Role{
+			Title:       role.Title,
+			Description: role.Description,
+			Skills:      copyLabels(role.Skills),
+			WeeklyHours: role.WeeklyHours,
+			Seniority:   role.Seniority,
+			Mentorship:  role.Mentorship,
+			Questions:   questions,
+		}
+	}
+
+	return clones
+}
+
+func cloneProjectQuestions(questions []ProjectQuestion) []ProjectQuestion {
+	clones := make([]ProjectQuestion, len(questions))
+	for i, question := range questions {
+		clones[i] = ProjectQuestion{
+			Text:     question.Text,
+			Required: question.Required,
+		}
+	}
+
+	return clones
+}
+
+func copyLabels(labels pq.StringArray) pq.StringArray {
+	copied := make(pq.StringArray, len(labels))
+	copy(copied, labels)
+
+	return copied
+}
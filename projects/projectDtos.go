@@ -1,13 +1,126 @@
 package projects
 
-import "github.com/lib/pq"
+import (
+	"github.com/lib/pq"
+	"time"
+)
 
 type NewProjectDto struct {
 	Name             string   `json:"name" validate:"required,min=4,max=32"`
 	Tags             []string `json:"tags" validate:"required,min=1,max=6,dive,min=1,max=40"`
-	LongDescription  string   `json:"longDescription" validate:"required,min=200,max=10000"`
+	LongDescription  string   `json:"longDescription" validate:"required,min=200,max=20000"`
 	ShortDescription string   `json:"shortDescription" validate:"required,min=10,max=200"`
 	GithubLink       string   `json:"githubLink" validate:"required"`
+	CoverImageUrl    string   `json:"coverImageUrl" validate:"omitempty,url,max=500"`
+
+	// SPDX identifier of the project's license, one of GET /licenses
+	License          string `json:"license" validate:"max=64"`
+	CodeOfConductUrl string `json:"codeOfConductUrl" validate:"omitempty,url,max=500"`
+	ContributingUrl  string `json:"contributingUrl" validate:"omitempty,url,max=500"`
+
+	// The project's tech stack, ids, names or aliases of technologies of the
+	// skills taxonomy (GET /skills). Stored as ids.
+	Languages  []string `json:"languages" validate:"max=10,dive,min=1,max=40"`
+	Frameworks []string `json:"frameworks" validate:"max=10,dive,min=1,max=40"`
+	Platforms  []string `json:"platforms" validate:"max=10,dive,min=1,max=40"`
+
+	// The languages the project communicates in, ids or names of
+	// GET /spoken-languages. Detected from the descriptions if empty.
+	SpokenLanguages []string `json:"spokenLanguages" validate:"max=5,dive,min=1,max=40"`
+
+	// IANA names of the time zones the project prefers to collaborate in,
+	// e.g. "Europe/Lisbon"
+	TimeZones []string `json:"timeZones" validate:"max=3,dive,min=1,max=64"`
+
+	// The status the project is created with, idea by default. Ignored when
+	// updating a project, see PUT /projects/{projectId}/status.
+	Status ProjectStatus `json:"status" validate:"omitempty,oneof=idea planning active"`
+
+	// The project's roles. When updating a project, its roles are
+	// replaced with these.
+	Roles []NewRoleDto `json:"roles" validate:"max=20,dive"`
+
+	// Screening questions applicants answer when the project has no roles,
+	// in order. Applicants to a role answer the role's questions instead.
+	// When updating a project, its questions are replaced with these.
+	Questions []NewQuestionDto `json:"questions" validate:"max=10,dive"`
+
+	// Where people can support the project financially, in the order they're
+	// shown. When updating a project, its links are replaced with these.
+	Funding []NewFundingLinkDto `json:"funding" validate:"max=5,dive"`
+
+	// Links to the project's resources besides its repository, e.g. docs,
+	// designs, roadmap or chat invite, in the order they're shown. When
+	// updating a project, its links are replaced with these.
+	ExternalLinks []NewExternalLinkDto `json:"externalLinks" validate:"max=10,dive"`
+}
+
+type NewExternalLinkDto struct {
+	// e.g. "Docs" or "Discord"
+	Label string `json:"label" validate:"required,max=40"`
+
+	// Must be an http or https url
+	Url string `json:"url" validate:"required,url,max=500"`
+}
+
+type NewFundingLinkDto struct {
+	// open-collective, github-sponsors or custom. Open Collective links must
+	// point to a collective (https://opencollective.com/<slug>) and GitHub
+	// Sponsors links to a profile (https://github.com/sponsors/<user>).
+	Platform FundingPlatform `json:"platform" validate:"required,oneof=open-collective github-sponsors custom"`
+	Url      string          `json:"url" validate:"required,url,max=500"`
+
+	// Required for custom links, e.g. "Patreon"
+	Label string `json:"label" validate:"max=64"`
+}
+
+type NewRoleDto struct {
+	// The id of the role to update when editing a project, 0 or missing for
+	// a new role
+	Id uint `json:"id"`
+
+	Title       string   `json:"title" validate:"required,min=2,max=64"`
+	Description string   `json:"description" validate:"max=2000"`
+	Skills      []string `json:"skills" validate:"max=10,dive,min=1,max=40"`
+
+	// Expected hours per week, 0 or missing if unknown
+	WeeklyHours int `json:"weeklyHours" validate:"min=0,max=60"`
+
+	// beginner, intermediate or experienced. Empty or missing if the role is
+	// open to any level.
+	Seniority  Seniority `json:"seniority" validate:"omitempty,oneof=beginner intermediate experienced"`
+	Mentorship bool      `json:"mentorship"`
+
+	// Screening questions applicants to the role answer, in order
+	Questions []NewQuestionDto `json:"questions" validate:"max=10,dive"`
+}
+
+type NewQuestionDto struct {
+	// The id of the question to update when editing a project, 0 or missing
+	// for a new question
+	Id uint `json:"id"`
+
+	Text string `json:"text" validate:"required,min=5,max=500"`
+
+	// Whether applicants must answer the question
+	Required bool `json:"required"`
+}
+
+type QuestionDto struct {
+	Id       uint   `json:"id"`
+	Text     string `json:"text"`
+	Required bool   `json:"required"`
+}
+
+type RoleDto struct {
+	Id          uint           `json:"id"`
+	Title       string         `json:"title"`
+	Description string         `json:"description"`
+	Skills      pq.StringArray `json:"skills" swaggertype:"array,string"`
+	WeeklyHours int            `json:"weeklyHours"`
+	Seniority   Seniority      `json:"seniority"`
+	Mentorship  bool           `json:"mentorship"`
+	Questions   []QuestionDto  `json:"questions"`
 }
 
 type ProjectSummaryDto struct {
@@ -16,15 +129,82 @@ type ProjectSummaryDto struct {
 	Tags             pq.StringArray `json:"tags" validate:"required" gorm:"type: TEXT[]" swaggertype:"array,string"`
 	ShortDescription string         `json:"shortDescription" validate:"required"`
 	Skills           pq.StringArray `json:"skills" validate:"required" gorm:"type: TEXT[]" swaggertype:"array,string"`
+
+	// Hours of the working day the authenticated user shares with the
+	// project's time zones. Null if there's no session or the project didn't
+	// declare time zones.
+	OverlapHours *float64 `json:"overlapHours" gorm:"-"`
 }
 
 type ProjectDto struct {
-	Id               uint           `json:"id"`
-	Name             string         `json:"name"`
-	Tags             pq.StringArray `json:"tags" swaggertype:"array,string"`
-	ShortDescription string         `json:"shortDescription"`
-	LongDescription  string         `json:"fullDescription"`
-	GithubLink       string         `json:"githubLink"`
+	Id               uint              `json:"id"`
+	Name             string            `json:"name"`
+	Tags             pq.StringArray    `json:"tags" swaggertype:"array,string"`
+	ShortDescription string            `json:"shortDescription"`
+	LongDescription  string            `json:"fullDescription"`
+	GithubLink       string            `json:"githubLink"`
+	CoverImageUrl    string            `json:"coverImageUrl"`
+	License          string            `json:"license"`
+	CodeOfConductUrl string            `json:"codeOfConductUrl"`
+	ContributingUrl  string            `json:"contributingUrl"`
+	Languages        pq.StringArray    `json:"languages" swaggertype:"array,string"`
+	Frameworks       pq.StringArray    `json:"frameworks" swaggertype:"array,string"`
+	Platforms        pq.StringArray    `json:"platforms" swaggertype:"array,string"`
+	Status           ProjectStatus     `json:"status"`
+	Roles            []RoleDto         `json:"roles"`
+	Questions        []QuestionDto     `json:"questions"`
+	OwnerId          uint              `json:"ownerId"`
+	PendingReview    bool              `json:"pendingReview"`
+	Draft            bool              `json:"draft"`
+	Funding          []FundingLinkDto  `json:"funding"`
+	ExternalLinks    []ExternalLinkDto `json:"externalLinks"`
+
+	// Whether fullDescription is the README of the project's repository,
+	// see PUT /projects/{projectId}/readme
+	ReadmeSync     bool       `json:"readmeSync"`
+	ReadmeSyncedAt *time.Time `json:"readmeSyncedAt"`
+
+	// ISO 639-1 codes of the languages the project communicates in, and
+	// whether they were detected from its descriptions rather than declared
+	SpokenLanguages         pq.StringArray `json:"spokenLanguages" swaggertype:"array,string"`
+	SpokenLanguagesDetected bool           `json:"spokenLanguagesDetected"`
+
+	// IANA names of the time zones the project prefers to collaborate in,
+	// and the hours of the working day the authenticated user shares with
+	// them. Null if there's no session or the project didn't declare any.
+	TimeZones    pq.StringArray `json:"timeZones" swaggertype:"array,string"`
+	OverlapHours *float64       `json:"overlapHours"`
+}
+
+type ExternalLinkDto struct {
+	Label string `json:"label"`
+	Url   string `json:"url"`
+}
+
+type FundingLinkDto struct {
+	Id       uint            `json:"id"`
+	Platform FundingPlatform `json:"platform"`
+	Url      string          `json:"url"`
+	Label    string          `json:"label"`
+
+	// How many times people followed the link, see
+	// POST /projects/{projectId}/funding/{fundingLinkId}/clicks
+	Clicks int64 `json:"clicks"`
+}
+
+type QualityHintDto struct {
+	// Identifies the hint, e.g. "cover-image".
+	Key     string `json:"key"`
+	Message string `json:"message"`
+
+	// How much the score would increase if the hint was followed.
+	Points int `json:"points"`
+}
+
+type QualityDto struct {
+	// From 0 to 100.
+	Score int              `json:"score"`
+	Hints []QualityHintDto `json:"hints"`
 }
 
 type ListProjectsParamsDto struct {
@@ -32,4 +212,39 @@ type ListProjectsParamsDto struct {
 	PageOffset uint     `form:"pageOffset"`
 	Tags       []string `form:"tags"`
 	Skills     []string `form:"skills"`
+	Licenses   []string `form:"licenses"`
+	Languages  []string `form:"languages"`
+	Frameworks []string `form:"frameworks"`
+	Platforms  []string `form:"platforms"`
+	Statuses   []string `form:"statuses"`
+
+	SpokenLanguages []string `form:"spokenLanguages"`
+
+	Seniorities    []string `form:"seniorities"`
+	MaxWeeklyHours uint     `form:"maxWeeklyHours"`
+	Mentorship     bool     `form:"mentorship"`
+}
+
+type BannedTagDto struct {
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Labels (tags or skills) to add to and remove from a list. Removals are
+// applied first, so a label in both ends up in the list.
+type BulkEditDto struct {
+	Add    []string `json:"add" validate:"max=20,dive,min=1,max=40"`
+	Remove []string `json:"remove" validate:"max=20,dive,min=1,max=40"`
+}
+
+type StatusDto struct {
+	Status ProjectStatus `json:"status" validate:"required"`
+}
+
+type StatusChangeDto struct {
+	From      ProjectStatus `json:"from"`
+	To        ProjectStatus `json:"to"`
+	ChangedBy uint          `json:"changedBy"`
+	CreatedAt time.Time     `json:"createdAt"`
 }
@@ -0,0 +1,60 @@
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"net/url"
+	"strings"
+)
+
+var ErrInvalidExternalLink = errors.New("invalid external link")
+
+// A labeled link to one of the project's resources outside the platform, e.g.
+// its docs, designs, roadmap or chat.
+type ExternalLink struct {
+	gorm.Model
+
+	ProjectId uint
+	Label     string
+	Url       string
+
+	// Links are shown in the order the project lists them
+	Position int
+}
+
+func (ExternalLink) TableName() string {
+	return "project_external_links"
+}
+
+// Check that external links are web links, each listed once, and turn them
+// into models. Returns ErrInvalidExternalLink if they aren't.
+func newExternalLinksToModels(dtos []NewExternalLinkDto) ([]ExternalLink, error) {
+	links := make([]ExternalLink, len(dtos))
+	seen := map[string]bool{}
+
+	for i, dto := range dtos {
+		// The url validator accepts any scheme, e.g. javascript:
+		link, err := url.Parse(dto.Url)
+		if err != nil || (link.Scheme != "https" && link.Scheme != "http") || link.Host == "" {
+			return nil, fmt.Errorf("%w: %s must be an http or https url", ErrInvalidExternalLink, dto.Url)
+		}
+
+		if strings.TrimSpace(dto.Label) == "" {
+			return nil, fmt.Errorf("%w: %s needs a label", ErrInvalidExternalLink, dto.Url)
+		}
+
+		if seen[dto.Url] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidExternalLink, dto.Url)
+		}
+		seen[dto.Url] = true
+
+		links[i] = ExternalLink{
+			Label:    strings.TrimSpace(dto.Label),
+			Url:      dto.Url,
+			Position: i,
+		}
+	}
+
+	return links, nil
+}
@@ -0,0 +1,140 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"gorm.io/gorm"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrInvalidFundingLink = errors.New("invalid funding link")
+var ErrFundingLinkNotFound = errors.New("funding link not found")
+
+// Clicks on a funding link from the same IP address are only counted once
+// per window, so that counters can't be inflated by clicking repeatedly.
+const fundingClickWindow = time.Hour
+
+// Where a project receives funding.
+type FundingPlatform string
+
+const (
+	FundingOpenCollective FundingPlatform = "open-collective"
+	FundingGithubSponsors FundingPlatform = "github-sponsors"
+
+	// Any other page, e.g. a Patreon or a donations page. Custom links must
+	// have a label.
+	FundingCustom FundingPlatform = "custom"
+)
+
+// Paths of Open Collective collectives and GitHub Sponsors profiles.
+var openCollectivePath = regexp.MustCompile(`^/[A-Za-z0-9-]+/?$`)
+var githubSponsorsPath = regexp.MustCompile(`^/sponsors/[A-Za-z0-9-]+/?$`)
+
+// A page where people can support a project financially.
+type FundingLink struct {
+	gorm.Model
+
+	ProjectId uint
+	Platform  FundingPlatform
+	Url       string
+	Label     string
+
+	// Links are shown in the order the project lists them
+	Position int
+
+	// How many times people followed the link, see RecordFundingClick
+	Clicks int64
+}
+
+func (FundingLink) TableName() string {
+	return "project_funding_links"
+}
+
+func (s *serviceImpl) RecordFundingClick(ctx context.Context, projectId uint, fundingLinkId uint, clientIp string) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId":     projectId,
+		"fundingLinkId": fundingLinkId,
+	})
+
+	_, err := s.Repository.GetFundingLink(ctx, projectId, fundingLinkId)
+	if errors.Is(err, ErrFundingLinkNotFound) {
+		return err
+	} else if err != nil {
+		logger.WithError(err).Error("Failed to query for funding link")
+
+		return err
+	}
+
+	if clientIp != "" {
+		first, err := s.Kv.SetNX(ctx, fundingClickRedisKey(fundingLinkId, clientIp), "1", fundingClickWindow)
+		if err != nil {
+			// Counting a click twice is better than failing the request
+			logger.WithError(err).Warn("Failed to check for a recent click, counting it")
+		} else if !first {
+			return nil
+		}
+	}
+
+	err = s.Repository.IncrementFundingClicks(ctx, fundingLinkId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to count funding link click")
+
+		return err
+	}
+
+	return nil
+}
+
+func fundingClickRedisKey(fundingLinkId uint, clientIp string) string {
+	return fmt.Sprintf("funding.link:%d:click:%s", fundingLinkId, clientIp)
+}
+
+// Check that funding links point to their platform and turn them into
+// models. Returns ErrInvalidFundingLink if a link doesn't.
+func newFundingLinksToModels(dtos []NewFundingLinkDto) ([]FundingLink, error) {
+	links := make([]FundingLink, len(dtos))
+	seen := map[string]bool{}
+
+	for i, dto := range dtos {
+		link, err := url.Parse(dto.Url)
+		if err != nil || link.Scheme != "https" {
+			return nil, fmt.Errorf("%w: %s must be an https url", ErrInvalidFundingLink, dto.Url)
+		}
+
+		host := strings.TrimPrefix(strings.ToLower(link.Host), "www.")
+
+		switch dto.Platform {
+		case FundingOpenCollective:
+			if host != "opencollective.com" || !openCollectivePath.MatchString(link.Path) {
+				return nil, fmt.Errorf("%w: %s isn't an Open Collective page", ErrInvalidFundingLink, dto.Url)
+			}
+		case FundingGithubSponsors:
+			if host != "github.com" || !githubSponsorsPath.MatchString(link.Path) {
+				return nil, fmt.Errorf("%w: %s isn't a GitHub Sponsors page", ErrInvalidFundingLink, dto.Url)
+			}
+		case FundingCustom:
+			if strings.TrimSpace(dto.Label) == "" {
+				return nil, fmt.Errorf("%w: custom link %s needs a label", ErrInvalidFundingLink, dto.Url)
+			}
+		}
+
+		if seen[dto.Url] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidFundingLink, dto.Url)
+		}
+		seen[dto.Url] = true
+
+		links[i] = FundingLink{
+			Platform: dto.Platform,
+			Url:      dto.Url,
+			Label:    strings.TrimSpace(dto.Label),
+			Position: i,
+		}
+	}
+
+	return links, nil
+}
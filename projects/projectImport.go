@@ -0,0 +1,173 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/validation"
+	"strings"
+	"unicode/utf8"
+)
+
+// A project's details as read from where it's hosted, e.g. its GitHub
+// repository. Unlike NewProjectDto nothing is required: whatever doesn't fit
+// the project's rules is left out for the owner to fill in.
+type ImportedProjectDto struct {
+	Name        string
+	Description string
+	GithubLink  string
+
+	// Free text labels, e.g. a repository's topics
+	Topics []string
+
+	// SPDX identifier
+	License string
+
+	// Names of the programming languages the project is written in, the most
+	// used first
+	Languages []string
+}
+
+func (s *serviceImpl) ImportProject(ctx context.Context, ownerId uint, imported ImportedProjectDto, pendingReview bool) (*Project, error) {
+	logger := log.FromContext(ctx).WithField("githubLink", imported.GithubLink)
+
+	tags, err := s.importedTags(ctx, imported.Topics)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := NewProjectDto{
+		Name:             truncateRunes(strings.TrimSpace(imported.Name), 32),
+		Tags:             tags,
+		LongDescription:  truncateRunes(strings.TrimSpace(imported.Description), 20000),
+		ShortDescription: truncateRunes(strings.TrimSpace(imported.Description), 200),
+		GithubLink:       imported.GithubLink,
+		Languages:        importedLanguages(imported.Languages),
+	}
+
+	// Licenses outside of the catalog, e.g. GitHub's NOASSERTION, are left
+	// for the owner to pick
+	license, err := NormalizeLicense(imported.License)
+	if err == nil {
+		dto.License = license
+	}
+
+	// The draft's other fields are checked once the owner saves it, but a name
+	// with banned words isn't worth creating a project for
+	violations := validation.Errors{}
+	s.Rules.checkName(dto.Name, &violations)
+	err = violations.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := newProjectToModel(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repository exists already, so the project isn't only an idea
+	project.Status = StatusActive
+	project.OwnerId = ownerId
+	project.PendingReview = pendingReview
+	project.Draft = true
+	project.QualityScore = storedQualityScore(&project)
+
+	err = s.Repository.CreateProject(ctx, &project)
+	if err != nil {
+		logger.WithError(err).Error("Failed to create imported project")
+
+		return nil, err
+	}
+
+	logger.WithField("projectId", project.ID).Info("Project imported")
+	createdCounter.Inc()
+
+	for _, listener := range s.Listeners {
+		listener.ProjectSaved(ctx, &project)
+	}
+
+	return &project, nil
+}
+
+// Turn topics into at most maxTags tags, leaving out duplicates, banned tags
+// and topics too long to be tags.
+func (s *serviceImpl) importedTags(ctx context.Context, topics []string) ([]string, error) {
+	tags := make([]string, 0, maxTags)
+	seen := make(map[string]bool, len(topics))
+
+	for _, topic := range topics {
+		tag := strings.ToLower(strings.TrimSpace(topic))
+		if tag == "" || utf8.RuneCountInString(tag) > 40 || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+
+	// Drop banned tags one by one, FindBannedTag only reports the first
+	for len(tags) > 0 {
+		banned, err := s.Repository.FindBannedTag(ctx, tags)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).Error("Failed to check for banned tags")
+
+			return nil, err
+		}
+
+		if banned == "" {
+			break
+		}
+
+		kept := tags[:0]
+		for _, tag := range tags {
+			if !strings.EqualFold(tag, banned) {
+				kept = append(kept, tag)
+			}
+		}
+
+		if len(kept) == len(tags) {
+			return nil, fmt.Errorf("%w: %s", ErrTagBanned, banned)
+		}
+
+		tags = kept
+	}
+
+	if len(tags) > maxTags {
+		tags = tags[:maxTags]
+	}
+
+	return tags, nil
+}
+
+// The taxonomy ids of the languages the taxonomy has, at most 10. Languages
+// it doesn't have, e.g. Shell or Makefile, are left out.
+func importedLanguages(names []string) []string {
+	ids := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		id, ok := findTechnology(TechCategoryLanguage, name)
+		if !ok || seen[id] {
+			continue
+		}
+
+		seen[id] = true
+		ids = append(ids, id)
+
+		if len(ids) == 10 {
+			break
+		}
+	}
+
+	return ids
+}
+
+func truncateRunes(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+
+	return strings.TrimSpace(string(runes[:length]))
+}
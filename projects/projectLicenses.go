@@ -0,0 +1,86 @@
+package projects
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnknownLicense = errors.New("unknown license")
+
+// An open source license, identified by its SPDX identifier
+// (https://spdx.org/licenses/).
+type License struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// The licenses projects can declare. Only OSI approved licenses commonly
+// used by open source projects are listed, so that filtering by license
+// isn't split across rarely used variants.
+var licenses = []License{
+	{"0BSD", "BSD Zero Clause License"},
+	{"AGPL-3.0-only", "GNU Affero General Public License v3.0 only"},
+	{"AGPL-3.0-or-later", "GNU Affero General Public License v3.0 or later"},
+	{"Apache-2.0", "Apache License 2.0"},
+	{"BSD-2-Clause", "BSD 2-Clause \"Simplified\" License"},
+	{"BSD-3-Clause", "BSD 3-Clause \"New\" or \"Revised\" License"},
+	{"BSL-1.0", "Boost Software License 1.0"},
+	{"CC0-1.0", "Creative Commons Zero v1.0 Universal"},
+	{"EPL-2.0", "Eclipse Public License 2.0"},
+	{"EUPL-1.2", "European Union Public License 1.2"},
+	{"GPL-2.0-only", "GNU General Public License v2.0 only"},
+	{"GPL-2.0-or-later", "GNU General Public License v2.0 or later"},
+	{"GPL-3.0-only", "GNU General Public License v3.0 only"},
+	{"GPL-3.0-or-later", "GNU General Public License v3.0 or later"},
+	{"ISC", "ISC License"},
+	{"LGPL-2.1-only", "GNU Lesser General Public License v2.1 only"},
+	{"LGPL-2.1-or-later", "GNU Lesser General Public License v2.1 or later"},
+	{"LGPL-3.0-only", "GNU Lesser General Public License v3.0 only"},
+	{"LGPL-3.0-or-later", "GNU Lesser General Public License v3.0 or later"},
+	{"MIT", "MIT License"},
+	{"MPL-2.0", "Mozilla Public License 2.0"},
+	{"Unlicense", "The Unlicense"},
+	{"Zlib", "zlib License"},
+}
+
+// All licenses in the catalog, ordered by id.
+func Licenses() []License {
+	return append([]License{}, licenses...)
+}
+
+// Get the catalog's id of a license. SPDX identifiers are case insensitive,
+// so e.g. "apache-2.0" is Apache-2.0.
+// Returns ErrUnknownLicense if the license isn't in the catalog.
+func NormalizeLicense(id string) (string, error) {
+	for _, license := range licenses {
+		if strings.EqualFold(license.Id, id) {
+			return license.Id, nil
+		}
+	}
+
+	return "", ErrUnknownLicense
+}
+
+// Get the catalog's ids of licenses.
+// Returns ErrUnknownLicense if any of the licenses isn't in the catalog.
+func NormalizeLicenses(ids []string) ([]string, error) {
+	normalized := make([]string, len(ids))
+	for i, id := range ids {
+		var err error
+		normalized[i], err = NormalizeLicense(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return normalized, nil
+}
+
+// A project's license is optional.
+func normalizeProjectLicense(id string) (string, error) {
+	if id == "" {
+		return "", nil
+	}
+
+	return NormalizeLicense(id)
+}
@@ -0,0 +1,67 @@
+package projects
+
+import (
+	"fmt"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// The HAL links of a project: the project itself and its related resources.
+func ProjectLinks(projectId uint) utils.Links {
+	href := fmt.Sprintf("/projects/%d", projectId)
+
+	return utils.Links{
+		"self":          {Href: href},
+		"similar":       {Href: href + "/similar"},
+		"statusHistory": {Href: href + "/status-history"},
+		"contributions": {Href: href + "/contributions"},
+		"events":        {Href: href + "/events"},
+		"applications":  {Href: href + "/applications"},
+	}
+}
+
+// Write a list of project summaries (or the result of utils.SelectFields on
+// them), as a HAL collection with the given links if the client asked for HAL.
+func WriteProjectSummaries(writer http.ResponseWriter, request *http.Request, projectSummaries interface{}, links utils.Links) error {
+	// The response depends on the Accept header, caches must know
+	writer.Header().Add("Vary", "Accept")
+
+	if !utils.AcceptsHal(request) {
+		return utils.WriteJson(writer, request.Context(), http.StatusOK, projectSummaries)
+	}
+
+	collection, err := utils.HalCollection("projects", projectSummaries, links, ProjectLinks)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteHal(writer, request.Context(), http.StatusOK, collection)
+}
+
+// Write a project (or the result of utils.SelectFields on it) as a HAL
+// resource if the client asked for HAL. The project's roles are embedded and
+// its owner's profile is linked to.
+func writeProject(writer http.ResponseWriter, request *http.Request, project ProjectDto, response interface{}) error {
+	writer.Header().Add("Vary", "Accept")
+
+	if !utils.AcceptsHal(request) {
+		return utils.WriteJson(writer, request.Context(), http.StatusOK, response)
+	}
+
+	links := ProjectLinks(project.Id)
+	links["owner"] = utils.Link{Href: fmt.Sprintf("/users/%d/contributions", project.OwnerId)}
+
+	resource, err := utils.HalResource(response, links)
+	if err != nil {
+		return err
+	}
+
+	if roles, ok := resource["roles"]; ok {
+		delete(resource, "roles")
+		resource["_embedded"] = map[string]interface{}{
+			"roles": roles,
+		}
+	}
+
+	return utils.WriteHal(writer, request.Context(), http.StatusOK, resource)
+}
@@ -0,0 +1,374 @@
+package projects
+
+import (
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+	"sort"
+)
+
+// Conversions between projects' models and DTOs. Every route and service
+// maps projects through these, so that a field added to a model or DTO only
+// has to be mapped once.
+
+// The project of a NewProjectDto, without its owner, status and review state,
+// which the DTO doesn't decide. The license, stack and links are normalized.
+// Returns ErrUnknownLicense, ErrUnknownTechnology, ErrUnknownSpokenLanguage,
+// users.ErrInvalidTimezone, ErrInvalidFundingLink or ErrInvalidExternalLink.
+func newProjectToModel(dto NewProjectDto) (Project, error) {
+	license, err := normalizeProjectLicense(dto.License)
+	if err != nil {
+		return Project{}, err
+	}
+
+	stack, err := normalizeStack(dto)
+	if err != nil {
+		return Project{}, err
+	}
+
+	spokenLanguages, detected, err := projectSpokenLanguages(dto)
+	if err != nil {
+		return Project{}, err
+	}
+
+	timeZones, err := normalizeTimeZones(dto.TimeZones)
+	if err != nil {
+		return Project{}, err
+	}
+
+	fundingLinks, err := newFundingLinksToModels(dto.Funding)
+	if err != nil {
+		return Project{}, err
+	}
+
+	externalLinks, err := newExternalLinksToModels(dto.ExternalLinks)
+	if err != nil {
+		return Project{}, err
+	}
+
+	return Project{
+		Name:             dto.Name,
+		Tags:             dto.Tags,
+		LongDescription:  dto.LongDescription,
+		ShortDescription: dto.ShortDescription,
+		GithubLink:       dto.GithubLink,
+		CoverImageUrl:    dto.CoverImageUrl,
+		License:          license,
+		CodeOfConductUrl: dto.CodeOfConductUrl,
+		ContributingUrl:  dto.ContributingUrl,
+		Languages:        stack.Languages,
+		Frameworks:       stack.Frameworks,
+		Platforms:        stack.Platforms,
+		Roles:            newRolesToModels(dto.Roles),
+		Questions:        newProjectQuestionsToModels(dto.Questions),
+		FundingLinks:     fundingLinks,
+		ExternalLinks:    externalLinks,
+
+		SpokenLanguages:         spokenLanguages,
+		SpokenLanguagesDetected: detected,
+		TimeZones:               timeZones,
+	}, nil
+}
+
+// The NewProjectDto a saved project would be submitted as, to check projects
+// that weren't submitted through one, e.g. imported drafts. The project's
+// roles, questions and links have to be loaded.
+func projectToNewDto(project *Project) NewProjectDto {
+	roles := make([]NewRoleDto, len(project.Roles))
+	for i, role := range project.Roles {
+		questions := make([]NewQuestionDto, len(role.Questions))
+		for j, question := range role.Questions {
+			questions[j] = NewQuestionDto{
+				Id:       question.ID,
+				Text:     question.Text,
+				Required: question.Required,
+			}
+		}
+
+		roles[i] = NewRoleDto{
+			Id:          role.ID,
+			Title:       role.Title,
+			Description: role.Description,
+			Skills:      role.Skills,
+			WeeklyHours: role.WeeklyHours,
+			Seniority:   role.Seniority,
+			Mentorship:  role.Mentorship,
+			Questions:   questions,
+		}
+	}
+
+	questions := make([]NewQuestionDto, len(project.Questions))
+	for i, question := range project.Questions {
+		questions[i] = NewQuestionDto{
+			Id:       question.ID,
+			Text:     question.Text,
+			Required: question.Required,
+		}
+	}
+
+	funding := make([]NewFundingLinkDto, len(project.FundingLinks))
+	for i, link := range project.FundingLinks {
+		funding[i] = NewFundingLinkDto{
+			Platform: link.Platform,
+			Url:      link.Url,
+			Label:    link.Label,
+		}
+	}
+
+	externalLinks := make([]NewExternalLinkDto, len(project.ExternalLinks))
+	for i, link := range project.ExternalLinks {
+		externalLinks[i] = NewExternalLinkDto{
+			Label: link.Label,
+			Url:   link.Url,
+		}
+	}
+
+	return NewProjectDto{
+		Name:             project.Name,
+		Tags:             project.Tags,
+		LongDescription:  project.LongDescription,
+		ShortDescription: project.ShortDescription,
+		GithubLink:       project.GithubLink,
+		CoverImageUrl:    project.CoverImageUrl,
+		License:          project.License,
+		CodeOfConductUrl: project.CodeOfConductUrl,
+		ContributingUrl:  project.ContributingUrl,
+		Languages:        project.Languages,
+		Frameworks:       project.Frameworks,
+		Platforms:        project.Platforms,
+		SpokenLanguages:  project.SpokenLanguages,
+		TimeZones:        project.TimeZones,
+		Roles:            roles,
+		Questions:        questions,
+		Funding:          funding,
+		ExternalLinks:    externalLinks,
+	}
+}
+
+func projectToDto(project *Project) ProjectDto {
+	longDescription := project.LongDescription
+	if project.ReadmeSync && project.Readme != "" {
+		longDescription = project.Readme
+	}
+
+	return ProjectDto{
+		Id:               project.ID,
+		Name:             project.Name,
+		Tags:             project.Tags,
+		ShortDescription: project.ShortDescription,
+		LongDescription:  longDescription,
+		GithubLink:       project.GithubLink,
+		CoverImageUrl:    project.CoverImageUrl,
+		License:          project.License,
+		CodeOfConductUrl: project.CodeOfConductUrl,
+		ContributingUrl:  project.ContributingUrl,
+		Languages:        project.Languages,
+		Frameworks:       project.Frameworks,
+		Platforms:        project.Platforms,
+		Status:           project.Status,
+		Roles:            rolesToDtos(project.Roles),
+		Questions:        projectQuestionsToDtos(project.Questions),
+		OwnerId:          project.OwnerId,
+		PendingReview:    project.PendingReview,
+		Draft:            project.Draft,
+		Funding:          fundingLinksToDtos(project.FundingLinks),
+		ExternalLinks:    externalLinksToDtos(project.ExternalLinks),
+		ReadmeSync:       project.ReadmeSync,
+		ReadmeSyncedAt:   project.ReadmeSyncedAt,
+
+		SpokenLanguages:         project.SpokenLanguages,
+		SpokenLanguagesDetected: project.SpokenLanguagesDetected,
+		TimeZones:               project.TimeZones,
+	}
+}
+
+// The project's roles have to be loaded for its skills.
+func projectToSummaryDto(project *Project) ProjectSummaryDto {
+	return ProjectSummaryDto{
+		Id:               project.ID,
+		Name:             project.Name,
+		Tags:             project.Tags,
+		ShortDescription: project.ShortDescription,
+		Skills:           roleSkills(project.Roles),
+	}
+}
+
+func projectsToSummaryDtos(projects []Project) []ProjectSummaryDto {
+	dtos := make([]ProjectSummaryDto, len(projects))
+	for i := range projects {
+		dtos[i] = projectToSummaryDto(&projects[i])
+	}
+
+	return dtos
+}
+
+// The distinct skills of roles, alphabetically.
+func roleSkills(roles []Role) pq.StringArray {
+	seen := map[string]bool{}
+	skills := pq.StringArray{}
+	for _, role := range roles {
+		for _, skill := range role.Skills {
+			if !seen[skill] {
+				seen[skill] = true
+				skills = append(skills, skill)
+			}
+		}
+	}
+
+	sort.Strings(skills)
+
+	return skills
+}
+
+func newRolesToModels(dtos []NewRoleDto) []Role {
+	roles := make([]Role, len(dtos))
+	for i, dto := range dtos {
+		skills := dto.Skills
+		if skills == nil {
+			skills = []string{}
+		}
+
+		questions := make([]RoleQuestion, len(dto.Questions))
+		for j, question := range dto.Questions {
+			questions[j] = RoleQuestion{
+				Model:    gorm.Model{ID: question.Id},
+				Text:     question.Text,
+				Required: question.Required,
+			}
+		}
+
+		roles[i] = Role{
+			Model:       gorm.Model{ID: dto.Id},
+			Title:       dto.Title,
+			Description: dto.Description,
+			Skills:      skills,
+			WeeklyHours: dto.WeeklyHours,
+			Seniority:   dto.Seniority,
+			Mentorship:  dto.Mentorship,
+			Questions:   questions,
+		}
+	}
+
+	return roles
+}
+
+// Clear the ids of a project's roles and screening questions, which a
+// NewProjectDto only sets to update them, so that they're all created.
+func clearRoleAndQuestionIds(project *Project) {
+	for i := range project.Roles {
+		project.Roles[i].ID = 0
+		for j := range project.Roles[i].Questions {
+			project.Roles[i].Questions[j].ID = 0
+		}
+	}
+
+	for i := range project.Questions {
+		project.Questions[i].ID = 0
+	}
+}
+
+func newProjectQuestionsToModels(dtos []NewQuestionDto) []ProjectQuestion {
+	questions := make([]ProjectQuestion, len(dtos))
+	for i, dto := range dtos {
+		questions[i] = ProjectQuestion{
+			Model:    gorm.Model{ID: dto.Id},
+			Text:     dto.Text,
+			Required: dto.Required,
+		}
+	}
+
+	return questions
+}
+
+func projectQuestionsToDtos(questions []ProjectQuestion) []QuestionDto {
+	dtos := make([]QuestionDto, len(questions))
+	for i, question := range questions {
+		dtos[i] = QuestionDto{
+			Id:       question.ID,
+			Text:     question.Text,
+			Required: question.Required,
+		}
+	}
+
+	return dtos
+}
+
+func rolesToDtos(roles []Role) []RoleDto {
+	dtos := make([]RoleDto, len(roles))
+	for i, role := range roles {
+		questions := make([]QuestionDto, len(role.Questions))
+		for j, question := range role.Questions {
+			questions[j] = QuestionDto{
+				Id:       question.ID,
+				Text:     question.Text,
+				Required: question.Required,
+			}
+		}
+
+		dtos[i] = RoleDto{
+			Id:          role.ID,
+			Title:       role.Title,
+			Description: role.Description,
+			Skills:      role.Skills,
+			WeeklyHours: role.WeeklyHours,
+			Seniority:   role.Seniority,
+			Mentorship:  role.Mentorship,
+			Questions:   questions,
+		}
+	}
+
+	return dtos
+}
+
+func fundingLinksToDtos(links []FundingLink) []FundingLinkDto {
+	dtos := make([]FundingLinkDto, len(links))
+	for i, link := range links {
+		dtos[i] = FundingLinkDto{
+			Id:       link.ID,
+			Platform: link.Platform,
+			Url:      link.Url,
+			Label:    link.Label,
+			Clicks:   link.Clicks,
+		}
+	}
+
+	return dtos
+}
+
+func externalLinksToDtos(links []ExternalLink) []ExternalLinkDto {
+	dtos := make([]ExternalLinkDto, len(links))
+	for i, link := range links {
+		dtos[i] = ExternalLinkDto{
+			Label: link.Label,
+			Url:   link.Url,
+		}
+	}
+
+	return dtos
+}
+
+func statusChangesToDtos(changes []StatusChange) []StatusChangeDto {
+	dtos := make([]StatusChangeDto, len(changes))
+	for i, change := range changes {
+		dtos[i] = StatusChangeDto{
+			From:      change.From,
+			To:        change.To,
+			ChangedBy: change.ChangedBy,
+			CreatedAt: change.CreatedAt,
+		}
+	}
+
+	return dtos
+}
+
+func bannedTagsToDtos(bannedTags []BannedTag) []BannedTagDto {
+	dtos := make([]BannedTagDto, len(bannedTags))
+	for i, bannedTag := range bannedTags {
+		dtos[i] = BannedTagDto{
+			Name:      bannedTag.Name,
+			Reason:    bannedTag.Reason,
+			CreatedAt: bannedTag.CreatedAt,
+		}
+	}
+
+	return dtos
+}
@@ -0,0 +1,176 @@
+package projects
+
+import (
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/testsupport/golden"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+var createdAt = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testNewProjectDto() NewProjectDto {
+	return NewProjectDto{
+		Name:             "Open Collaboration",
+		Tags:             []string{"collaboration", "open-source"},
+		LongDescription:  "A platform where people find open source projects to contribute to.",
+		ShortDescription: "Find projects to contribute to",
+		GithubLink:       "https://github.com/open-collaboration/server",
+		CoverImageUrl:    "https://example.com/cover.png",
+		License:          "mit",
+		CodeOfConductUrl: "https://example.com/code-of-conduct",
+		ContributingUrl:  "https://example.com/contributing",
+		Languages:        []string{"golang", "Go"},
+		Frameworks:       []string{"reactjs"},
+		Platforms:        []string{"linux", "web"},
+		SpokenLanguages:  []string{"English", "pt"},
+		TimeZones:        []string{"Europe/Lisbon", "UTC"},
+		Roles: []NewRoleDto{
+			{
+				Id:          12,
+				Title:       "Backend developer",
+				Description: "Works on the API",
+				Skills:      []string{"go", "postgres"},
+				WeeklyHours: 5,
+				Seniority:   SeniorityIntermediate,
+				Mentorship:  true,
+				Questions: []NewQuestionDto{
+					{Id: 3, Text: "What did you build with Go?", Required: true},
+					{Text: "Anything else?"},
+				},
+			},
+			{
+				Title: "Designer",
+			},
+		},
+		Questions: []NewQuestionDto{
+			{Id: 7, Text: "Why do you want to join?", Required: true},
+		},
+		Funding: []NewFundingLinkDto{
+			{Platform: FundingOpenCollective, Url: "https://opencollective.com/open-collaboration"},
+			{Platform: FundingCustom, Url: "https://example.com/donate", Label: " Donate "},
+		},
+		ExternalLinks: []NewExternalLinkDto{
+			{Label: "Docs", Url: "https://example.com/docs"},
+		},
+	}
+}
+
+func testProject() Project {
+	return Project{
+		Model:            gorm.Model{ID: 1, CreatedAt: createdAt, UpdatedAt: createdAt},
+		Name:             "Open Collaboration",
+		Tags:             pq.StringArray{"collaboration", "open-source"},
+		LongDescription:  "A platform where people find open source projects to contribute to.",
+		ShortDescription: "Find projects to contribute to",
+		GithubLink:       "https://github.com/open-collaboration/server",
+		License:          "MIT",
+		Languages:        pq.StringArray{"go"},
+		Frameworks:       pq.StringArray{"react"},
+		Platforms:        pq.StringArray{"web"},
+		SpokenLanguages:  pq.StringArray{"en"},
+		TimeZones:        pq.StringArray{"Europe/Lisbon"},
+		Status:           StatusActive,
+		OwnerId:          2,
+		Roles: []Role{
+			{
+				Model:       gorm.Model{ID: 12},
+				ProjectId:   1,
+				Title:       "Backend developer",
+				Skills:      pq.StringArray{"postgres", "go"},
+				WeeklyHours: 5,
+				Seniority:   SeniorityIntermediate,
+				Questions: []RoleQuestion{
+					{Model: gorm.Model{ID: 3}, RoleId: 12, Text: "What did you build with Go?", Required: true},
+				},
+			},
+			{
+				Model:     gorm.Model{ID: 13},
+				ProjectId: 1,
+				Title:     "Frontend developer",
+				Skills:    pq.StringArray{"go", "typescript"},
+			},
+		},
+		Questions: []ProjectQuestion{
+			{Model: gorm.Model{ID: 7}, ProjectId: 1, Text: "Why do you want to join?"},
+		},
+		FundingLinks: []FundingLink{
+			{Model: gorm.Model{ID: 4}, ProjectId: 1, Platform: FundingCustom, Url: "https://example.com/donate", Label: "Donate", Clicks: 9},
+		},
+		ExternalLinks: []ExternalLink{
+			{Model: gorm.Model{ID: 5}, ProjectId: 1, Label: "Docs", Url: "https://example.com/docs"},
+		},
+		Readme: "# Open Collaboration",
+	}
+}
+
+func TestNewProjectToModel(t *testing.T) {
+	project, err := newProjectToModel(testNewProjectDto())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Check(t, "new_project_to_model", project)
+}
+
+func TestNewProjectToModelDetectsSpokenLanguages(t *testing.T) {
+	dto := testNewProjectDto()
+	dto.SpokenLanguages = nil
+	dto.LongDescription = "Um projeto para encontrar pessoas que querem contribuir para projetos de código aberto, " +
+		"com uma lista de papéis e as competências que cada um pede. Os donos dos projetos respondem às " +
+		"candidaturas e as pessoas que entram na equipa trabalham com eles no repositório do projeto."
+	dto.Roles = nil
+	dto.Questions = nil
+	dto.Funding = nil
+	dto.ExternalLinks = nil
+
+	project, err := newProjectToModel(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	golden.Check(t, "new_project_to_model_detected", project)
+}
+
+func TestProjectToDto(t *testing.T) {
+	project := testProject()
+	golden.Check(t, "project_to_dto", projectToDto(&project))
+
+	// The README replaces the long description only when it's synced
+	syncedAt := createdAt.Add(time.Hour)
+	project.ReadmeSync = true
+	project.ReadmeSyncedAt = &syncedAt
+	golden.Check(t, "project_to_dto_readme_sync", projectToDto(&project))
+}
+
+func TestProjectsToSummaryDtos(t *testing.T) {
+	withoutRoles := testProject()
+	withoutRoles.ID = 2
+	withoutRoles.Roles = nil
+
+	golden.Check(t, "projects_to_summary_dtos", projectsToSummaryDtos([]Project{testProject(), withoutRoles}))
+}
+
+func TestStatusChangesToDtos(t *testing.T) {
+	changes := []StatusChange{
+		{Model: gorm.Model{ID: 1, CreatedAt: createdAt}, ProjectId: 1, From: StatusIdea, To: StatusActive, ChangedBy: 2},
+		{Model: gorm.Model{ID: 2, CreatedAt: createdAt.Add(24 * time.Hour)}, ProjectId: 1, From: StatusActive, To: StatusCompleted, ChangedBy: 3},
+	}
+
+	golden.Check(t, "status_changes_to_dtos", statusChangesToDtos(changes))
+}
+
+func TestBannedTagsToDtos(t *testing.T) {
+	bannedTags := []BannedTag{
+		{Model: gorm.Model{ID: 1, CreatedAt: createdAt}, Name: "crypto", Reason: "Spam"},
+	}
+
+	golden.Check(t, "banned_tags_to_dtos", bannedTagsToDtos(bannedTags))
+}
+
+func TestProjectToNewDto(t *testing.T) {
+	project := testProject()
+
+	golden.Check(t, "project_to_new_dto", projectToNewDto(&project))
+}
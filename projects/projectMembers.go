@@ -0,0 +1,81 @@
+package projects
+
+import (
+	"context"
+	"github.com/apex/log"
+	"time"
+)
+
+// A user taking part in a project: its owner or an accepted applicant.
+type Member struct {
+	UserId          uint
+	Username        string
+	Owner           bool
+	HideMemberships bool
+}
+
+type MemberDto struct {
+	UserId   uint   `json:"userId"`
+	Username string `json:"username"`
+
+	// The project's owner rather than an accepted applicant
+	Owner bool `json:"owner"`
+
+	// What the member can do besides contributing, see Permission
+	Permissions []Permission `json:"permissions"`
+
+	// Whether the member has the site open and when they last had it open,
+	// so that users can tell whether a maintainer is likely to respond now.
+	// Set by the route, see presence.Service.
+	Online     bool       `json:"online"`
+	LastSeenAt *time.Time `json:"lastSeenAt"`
+}
+
+func (s *serviceImpl) ListMembers(ctx context.Context, viewerId uint, projectId uint) ([]MemberDto, error) {
+	members, err := s.Repository.ListMembers(ctx, projectId)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).WithField("projectId", projectId).Error("Failed to list project members")
+
+		return nil, err
+	}
+
+	permissions, err := s.membersPermissions(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]MemberDto, 0, len(members))
+	for _, member := range members {
+		// The owner is public anyway, see ProjectDto.OwnerId
+		if member.HideMemberships && !member.Owner && member.UserId != viewerId {
+			continue
+		}
+
+		dtos = append(dtos, MemberDto{
+			UserId:   member.UserId,
+			Username: member.Username,
+			Owner:    member.Owner,
+
+			Permissions: permissions[member.UserId],
+		})
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) IsMember(ctx context.Context, projectId uint, userId uint) (bool, error) {
+	members, err := s.Repository.ListMembers(ctx, projectId)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).WithField("projectId", projectId).Error("Failed to list project members")
+
+		return false, err
+	}
+
+	for _, member := range members {
+		if member.UserId == userId {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
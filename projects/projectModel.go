@@ -3,6 +3,7 @@ package projects
 import (
 	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Project struct {
@@ -13,4 +14,125 @@ type Project struct {
 	LongDescription  string
 	ShortDescription string
 	GithubLink       string
+	CoverImageUrl    string
+
+	// SPDX identifier from the license catalog (see Licenses), can be empty
+	License          string
+	CodeOfConductUrl string
+	ContributingUrl  string
+
+	// The project's tech stack, ids of the skills taxonomy (see Technologies)
+	Languages  pq.StringArray `gorm:"type: TEXT[]"`
+	Frameworks pq.StringArray `gorm:"type: TEXT[]"`
+	Platforms  pq.StringArray `gorm:"type: TEXT[]"`
+
+	// ISO 639-1 codes of the languages the project communicates in (see
+	// SpokenLanguages). Detected from the project's descriptions when the
+	// owner didn't declare them, in which case SpokenLanguagesDetected is set.
+	SpokenLanguages         pq.StringArray `gorm:"type: TEXT[]"`
+	SpokenLanguagesDetected bool
+
+	// IANA names of the time zones the project prefers to collaborate in,
+	// matched against contributors' time zones (see OverlapHours)
+	TimeZones pq.StringArray `gorm:"type: TEXT[]"`
+
+	// Changed through ChangeStatus so that the transitions are checked
+	Status ProjectStatus
+
+	OwnerId uint
+	Roles   []Role
+
+	// Screening questions applicants to the project without a role answer,
+	// i.e. applicants to projects without roles
+	Questions []ProjectQuestion
+
+	// Where people can support the project financially
+	FundingLinks []FundingLink
+
+	// Links to the project's docs, designs, chat and so on, besides its
+	// repository (GithubLink)
+	ExternalLinks []ExternalLink
+
+	// Computed from the project's completeness whenever the project
+	// is saved (see storedQualityScore), used to rank projects along with
+	// the project's activity.
+	QualityScore int
+
+	// Projects created by shadow restricted users are hidden from
+	// everyone but their owner until a moderator approves them.
+	PendingReview bool
+
+	// Clones of other projects start as drafts, hidden from everyone but
+	// their owner until the owner publishes them.
+	Draft bool
+
+	// Whether the README of the project's GitHub repository is served as
+	// its long description, instead of the one written on the platform. The
+	// README is imported and refreshed by the readme package, LongDescription
+	// is kept so that turning this off restores it.
+	ReadmeSync     bool
+	Readme         string
+	ReadmeSyncedAt *time.Time
+}
+
+// A role the project needs someone to fill, e.g. "Backend developer".
+type Role struct {
+	gorm.Model
+
+	ProjectId   uint
+	Title       string
+	Description string
+	Skills      pq.StringArray `gorm:"type: TEXT[]"`
+
+	// Expected hours per week, 0 if the project didn't say
+	WeeklyHours int
+
+	// Empty if the role is open to any level
+	Seniority Seniority
+
+	// Whether someone on the project is available to mentor whoever takes
+	// the role
+	Mentorship bool
+
+	// Screening questions applicants to the role answer
+	Questions []RoleQuestion
+}
+
+func (Role) TableName() string {
+	return "project_roles"
+}
+
+// A screening question applicants to a role answer, e.g. "What's your
+// experience with Go?".
+type RoleQuestion struct {
+	gorm.Model
+
+	RoleId   uint
+	Text     string
+	Required bool
+}
+
+func (RoleQuestion) TableName() string {
+	return "role_questions"
+}
+
+// A screening question applicants to a project without roles answer.
+type ProjectQuestion struct {
+	gorm.Model
+
+	ProjectId uint
+	Text      string
+	Required  bool
+}
+
+func (ProjectQuestion) TableName() string {
+	return "project_questions"
+}
+
+// A tag that can't be used by projects. Banned by moderators.
+type BannedTag struct {
+	gorm.Model
+
+	Name   string
+	Reason string
 }
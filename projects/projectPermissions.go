@@ -0,0 +1,244 @@
+package projects
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/kv"
+	"time"
+)
+
+var ErrNotMember = errors.New("user isn't a member of the project")
+var ErrOwnerPermissions = errors.New("the project's owner has every permission")
+
+// What a project's member can do besides contributing. The owner has every
+// permission, other members only the ones the owner grants them.
+type Permission string
+
+const (
+	// Edit the project's open roles and review the applications to them.
+	PermissionManageRoles Permission = "manage-roles"
+
+	// Edit the project's description, tags, status, README, events and
+	// contributions, and publish it.
+	PermissionEditProject Permission = "edit-project"
+
+	// Delete messages of the project's chat and mute its members.
+	PermissionModerateComments Permission = "moderate-comments"
+
+	// Post announcements of the project to its chat integrations.
+	PermissionPostAnnouncements Permission = "post-announcements"
+
+	// Set up the project's team chat integrations.
+	PermissionManageIntegrations Permission = "manage-integrations"
+)
+
+// Every permission, as the owner has them.
+var AllPermissions = []Permission{
+	PermissionManageRoles,
+	PermissionEditProject,
+	PermissionModerateComments,
+	PermissionPostAnnouncements,
+	PermissionManageIntegrations,
+}
+
+// How long members' permissions are cached. Bounds how long members keep
+// their permissions after they leave a project; granting and revoking
+// permissions clears the cache right away.
+const permissionsCacheDuration = 10 * time.Minute
+
+// The permissions the owner granted to a member.
+type MemberPermissions struct {
+	ProjectId   uint           `gorm:"primaryKey"`
+	UserId      uint           `gorm:"primaryKey"`
+	Permissions pq.StringArray `gorm:"type: TEXT[]"`
+	GrantedBy   uint
+	UpdatedAt   time.Time
+}
+
+func (MemberPermissions) TableName() string {
+	return "project_member_permissions"
+}
+
+type MemberPermissionsDto struct {
+	// The permissions to grant, replacing the member's permissions. Empty
+	// revokes all of them.
+	Permissions []Permission `json:"permissions" validate:"max=5,dive,oneof=manage-roles edit-project moderate-comments post-announcements manage-integrations"`
+}
+
+func permissionsRedisKey(projectId uint) string {
+	return fmt.Sprintf("project:%d:permissions", projectId)
+}
+
+func (s *serviceImpl) GetPermissions(ctx context.Context, projectId uint, userId uint) ([]Permission, error) {
+	permissions, err := s.membersPermissions(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	if permissions[userId] == nil {
+		return []Permission{}, nil
+	}
+
+	return permissions[userId], nil
+}
+
+func (s *serviceImpl) HasPermission(ctx context.Context, projectId uint, userId uint, permission Permission) (bool, error) {
+	permissions, err := s.GetPermissions(ctx, projectId, userId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, granted := range permissions {
+		if granted == permission {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// The permissions of each of a project's members, by user id.
+func (s *serviceImpl) membersPermissions(ctx context.Context, projectId uint) (map[uint][]Permission, error) {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	var permissions map[uint][]Permission
+
+	cached, err := s.Kv.Get(ctx, permissionsRedisKey(projectId))
+	if err == nil {
+		err = json.Unmarshal([]byte(cached), &permissions)
+		if err == nil {
+			return permissions, nil
+		}
+
+		logger.WithError(err).Warn("Failed to unmarshal cached permissions")
+	} else if !errors.Is(err, kv.ErrNotFound) {
+		logger.WithError(err).Warn("Failed to get cached permissions, falling back to the database")
+	}
+
+	_, err = s.Repository.GetProject(ctx, projectId)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to get project")
+		}
+
+		return nil, err
+	}
+
+	members, err := s.Repository.ListMembers(ctx, projectId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list project members")
+
+		return nil, err
+	}
+
+	granted, err := s.Repository.ListMemberPermissions(ctx, projectId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list project permissions")
+
+		return nil, err
+	}
+
+	grantedByUser := make(map[uint][]string, len(granted))
+	for _, memberPermissions := range granted {
+		grantedByUser[memberPermissions.UserId] = memberPermissions.Permissions
+	}
+
+	// Members who left keep their grants, but they only apply to members
+	permissions = make(map[uint][]Permission, len(members))
+	for _, member := range members {
+		if member.Owner {
+			permissions[member.UserId] = AllPermissions
+			continue
+		}
+
+		permissions[member.UserId] = []Permission{}
+		for _, permission := range grantedByUser[member.UserId] {
+			permissions[member.UserId] = append(permissions[member.UserId], Permission(permission))
+		}
+	}
+
+	encoded, err := json.Marshal(permissions)
+	if err == nil {
+		err = s.Kv.Set(ctx, permissionsRedisKey(projectId), string(encoded), permissionsCacheDuration)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to cache permissions")
+	}
+
+	return permissions, nil
+}
+
+func (s *serviceImpl) SetPermissions(ctx context.Context, grantedBy uint, projectId uint, userId uint, dto MemberPermissionsDto) ([]Permission, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", projectId).WithField("userId", userId)
+
+	members, err := s.Repository.ListMembers(ctx, projectId)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list project members")
+
+		return nil, err
+	}
+
+	var member *Member
+	for i := range members {
+		if members[i].UserId == userId {
+			member = &members[i]
+		}
+	}
+
+	if member == nil {
+		return nil, ErrNotMember
+	}
+
+	if member.Owner {
+		return nil, ErrOwnerPermissions
+	}
+
+	// Listed in the order of AllPermissions, without duplicates
+	permissions := []Permission{}
+	for _, permission := range AllPermissions {
+		for _, wanted := range dto.Permissions {
+			if wanted == permission {
+				permissions = append(permissions, permission)
+				break
+			}
+		}
+	}
+
+	memberPermissions := MemberPermissions{
+		ProjectId:   projectId,
+		UserId:      userId,
+		Permissions: pq.StringArray{},
+		GrantedBy:   grantedBy,
+	}
+
+	for _, permission := range permissions {
+		memberPermissions.Permissions = append(memberPermissions.Permissions, string(permission))
+	}
+
+	err = s.Repository.SaveMemberPermissions(ctx, &memberPermissions)
+	if err != nil {
+		logger.WithError(err).Error("Failed to save member permissions")
+
+		return nil, err
+	}
+
+	// Revoked permissions must not outlive the request
+	err = s.Kv.Del(ctx, permissionsRedisKey(projectId))
+	if err != nil {
+		logger.WithError(err).Error("Failed to clear cached permissions")
+
+		return nil, err
+	}
+
+	return permissions, nil
+}
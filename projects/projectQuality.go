@@ -0,0 +1,129 @@
+package projects
+
+import "time"
+
+// How long a project counts as active after it was last updated, and the
+// points it gets for it. Activity depends on the time a project is ranked at,
+// not on when it's saved, so it isn't part of the stored quality score:
+// ranking queries add it from updated_at (see activityScoreSql).
+const qualityActivityWindow = 30 * 24 * time.Hour
+const qualityActivityPoints = 10
+
+// A criterion of a project's quality score. A project gets the
+// criterion's points if it meets the criterion, otherwise the
+// criterion's hint is suggested to the project's owner.
+type qualityCriterion struct {
+	Key     string
+	Points  int
+	Message string
+	Met     func(project *Project) bool
+
+	// Whether the criterion is left out of the stored score, see
+	// storedQualityScore
+	Dynamic bool
+}
+
+// The points of all criteria add up to 100.
+var qualityCriteria = []qualityCriterion{
+	{
+		Key:     "roles",
+		Points:  25,
+		Message: "Define at least one role so contributors know how they can help.",
+		Met: func(project *Project) bool {
+			return len(project.Roles) > 0
+		},
+	},
+	{
+		Key:     "long-description",
+		Points:  20,
+		Message: "Write a longer description (at least 1000 characters) explaining the project's goals and current state.",
+		Met: func(project *Project) bool {
+			return len(project.LongDescription) >= 1000
+		},
+	},
+	{
+		Key:     "cover-image",
+		Points:  15,
+		Message: "Add a cover image, projects with images get more attention in listings.",
+		Met: func(project *Project) bool {
+			return project.CoverImageUrl != ""
+		},
+	},
+	{
+		Key:     "short-description",
+		Points:  10,
+		Message: "Write a short description of at least 50 characters, it's what people see in listings.",
+		Met: func(project *Project) bool {
+			return len(project.ShortDescription) >= 50
+		},
+	},
+	{
+		Key:     "tags",
+		Points:  10,
+		Message: "Add at least 3 tags so people can find the project when filtering.",
+		Met: func(project *Project) bool {
+			return len(project.Tags) >= 3
+		},
+	},
+	{
+		Key:     "github-link",
+		Points:  10,
+		Message: "Link the project's repository.",
+		Met: func(project *Project) bool {
+			return project.GithubLink != ""
+		},
+	},
+	{
+		Key:     "activity",
+		Points:  qualityActivityPoints,
+		Message: "Update the project, it hasn't been updated in the last 30 days.",
+		Met: func(project *Project) bool {
+			return project.UpdatedAt.IsZero() || time.Since(project.UpdatedAt) < qualityActivityWindow
+		},
+		Dynamic: true,
+	},
+}
+
+// Compute a project's quality score and the hints for
+// the criteria the project doesn't meet.
+func computeQuality(project *Project) QualityDto {
+	quality := QualityDto{
+		Hints: []QualityHintDto{},
+	}
+
+	for _, criterion := range qualityCriteria {
+		if criterion.Met(project) {
+			quality.Score += criterion.Points
+		} else {
+			quality.Hints = append(quality.Hints, QualityHintDto{
+				Key:     criterion.Key,
+				Message: criterion.Message,
+				Points:  criterion.Points,
+			})
+		}
+	}
+
+	return quality
+}
+
+// The score stored with a project (Project.QualityScore), without the points
+// of the criteria that change over time.
+func storedQualityScore(project *Project) int {
+	score := 0
+	for _, criterion := range qualityCriteria {
+		if !criterion.Dynamic && criterion.Met(project) {
+			score += criterion.Points
+		}
+	}
+
+	return score
+}
+
+// The points of the activity criterion of the projects table's row, to be
+// added to quality_score when ranking projects. Its parameters are
+// activityScoreVars.
+const activityScoreSql = "CASE WHEN updated_at > ? THEN ? ELSE 0 END"
+
+func activityScoreVars() []interface{} {
+	return []interface{}{time.Now().Add(-qualityActivityWindow), qualityActivityPoints}
+}
@@ -0,0 +1,49 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+)
+
+func (s *serviceImpl) SetReadmeSync(ctx context.Context, projectId uint, enabled bool) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId": projectId,
+		"enabled":   enabled,
+	})
+
+	err := s.Repository.SetReadmeSync(ctx, projectId, enabled)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to set README sync")
+		}
+
+		return err
+	}
+
+	logger.Info("README sync set")
+
+	s.notifyListeners(ctx, projectId)
+
+	return nil
+}
+
+func (s *serviceImpl) SaveReadme(ctx context.Context, projectId uint, readme string) error {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	err := s.Repository.SaveReadme(ctx, projectId, readme)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to save README")
+		}
+
+		return err
+	}
+
+	logger.Debug("README saved")
+
+	// The project's page changed, e.g. the CDN has to purge it
+	s.notifyListeners(ctx, projectId)
+
+	return nil
+}
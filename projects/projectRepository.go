@@ -0,0 +1,155 @@
+package projects
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"time"
+)
+
+// Persists projects, their roles and links, banned tags and status changes.
+// The service keeps the business rules (validation, normalization, quality
+// scores, tag limits, ...), so that they can be tested with a mocked
+// repository.
+//
+// Published projects are the ones that aren't pending review nor drafts.
+type Repository interface {
+	// Store a new project along with its roles, their questions and its
+	// links, setting their ids.
+	CreateProject(ctx context.Context, project *Project) error
+
+	// Update a project's editable fields (not its owner, status, ...) and
+	// replace its roles, screening questions, external links and funding
+	// links with the project's. Roles and questions with an id are updated,
+	// the others are created, and funding links whose url didn't change keep
+	// their click counters.
+	// Returns ErrProjectNotFound if the project doesn't exist, ErrRoleNotFound
+	// or ErrQuestionNotFound if an id isn't one of the project's.
+	UpdateProject(ctx context.Context, projectId uint, project *Project) error
+
+	// Get a project with its roles.
+	// Returns ErrProjectNotFound if the project doesn't exist.
+	GetProject(ctx context.Context, projectId uint) (*Project, error)
+
+	// Get a published project with its roles.
+	// Returns ErrProjectNotFound if the project doesn't exist or isn't
+	// published.
+	GetPublishedProject(ctx context.Context, projectId uint) (*Project, error)
+
+	// Get a project with its roles, their questions, its funding links and
+	// its external links, all in order.
+	// Returns ErrProjectNotFound if the project doesn't exist.
+	GetProjectDetails(ctx context.Context, projectId uint) (*Project, error)
+
+	// Get projects with their roles. Projects that don't exist are left out.
+	ListProjectsWithRoles(ctx context.Context, projectIds []uint) ([]Project, error)
+
+	// Get a page of the published projects matching filters, whose values
+	// have to be normalized already, and the total amount of matching
+	// projects. Summaries include their skills, like the other summaries.
+	ListProjects(ctx context.Context, filters ProjectFilters, order ProjectOrder, pageSize uint, pageOffset uint) ([]ProjectSummaryDto, int64, error)
+
+	// Get the summaries of the published projects with the given ids, in no
+	// particular order.
+	ListProjectSummaries(ctx context.Context, projectIds []uint) ([]ProjectSummaryDto, error)
+
+	// Get a page of the projects pending review, oldest to newest, and the
+	// total amount of projects pending review.
+	ListPendingProjects(ctx context.Context, pageSize uint, pageOffset uint) ([]ProjectSummaryDto, int64, error)
+
+	// A cheap estimate of the amount of projects.
+	EstimateProjectCount(ctx context.Context) (float64, error)
+
+	// Get at most count published projects updated after updatedAfter, from a
+	// sample of percentage% of the projects, in a random order weighted by
+	// quality score. Projects owned by excludeOwnerId are left out.
+	SampleProjects(ctx context.Context, count uint, excludeOwnerId uint, updatedAfter time.Time, percentage float64) ([]ProjectSummaryDto, error)
+
+	// Get at most limit published projects, with their roles, that have any
+	// of the tags or a role with any of the skills. The project is left out.
+	ListProjectsSharingLabels(ctx context.Context, projectId uint, tags []string, skills []string, limit int) ([]Project, error)
+
+	// Returns ErrProjectNotFound if the project doesn't exist.
+	SetPendingReview(ctx context.Context, projectId uint, pendingReview bool) error
+
+	// Returns ErrProjectNotFound if the project doesn't exist.
+	SetDraft(ctx context.Context, projectId uint, draft bool) error
+
+	// Lock a project, with its roles, and save its tags and quality score
+	// after edit changed them, in a transaction. Nothing is saved if edit
+	// fails. Returns the edited project.
+	// Returns ErrProjectNotFound if the project doesn't exist, or edit's error.
+	EditTags(ctx context.Context, projectId uint, edit func(project *Project) error) (*Project, error)
+
+	// Lock a project's role and save its skills after edit changed them, like
+	// EditTags. Returns the edited role.
+	// Returns ErrRoleNotFound if the project doesn't have the role, or edit's
+	// error.
+	EditRoleSkills(ctx context.Context, projectId uint, roleId uint, edit func(role *Role) error) (*Role, error)
+
+	// Lock a project, check that it can move to another status and record the
+	// change, in a transaction. Returns the project as it was before the
+	// change and the change.
+	// Returns ErrProjectNotFound if the project doesn't exist, or check's
+	// error.
+	ChangeStatus(
+		ctx context.Context,
+		projectId uint,
+		status ProjectStatus,
+		changedBy uint,
+		check func(project *Project) error,
+	) (*Project, StatusChange, error)
+
+	// List a project's status changes, newest to oldest.
+	ListStatusChanges(ctx context.Context, projectId uint) ([]StatusChange, error)
+
+	// List the ids of a project's accepted members.
+	ListMemberIds(ctx context.Context, projectId uint) ([]uint, error)
+
+	// Replace the source tags with the target tag in all projects. Returns the
+	// amount of affected projects.
+	// Returns ErrTagBanned if the target tag is banned, which is checked in
+	// the same transaction.
+	MergeTags(ctx context.Context, sources []string, target string) (int64, error)
+
+	// Ban a tag and remove it from all projects, in a transaction. Banning a
+	// banned tag keeps its reason.
+	BanTag(ctx context.Context, tag string, reason string) error
+
+	// Returns ErrTagNotBanned if the tag isn't banned.
+	UnbanTag(ctx context.Context, tag string) error
+
+	// List the banned tags, alphabetically.
+	ListBannedTags(ctx context.Context) ([]BannedTag, error)
+
+	// Get one of the tags that's banned, "" if none is.
+	FindBannedTag(ctx context.Context, tags []string) (string, error)
+
+	// Returns ErrFundingLinkNotFound if the project doesn't have the link.
+	GetFundingLink(ctx context.Context, projectId uint, fundingLinkId uint) (*FundingLink, error)
+
+	// Count a click on a funding link.
+	IncrementFundingClicks(ctx context.Context, fundingLinkId uint) error
+
+	// Turn README sync on or off. Turning it off forgets the imported README.
+	// Returns ErrProjectNotFound if the project doesn't exist.
+	SetReadmeSync(ctx context.Context, projectId uint, enabled bool) error
+
+	// Save a project's imported README, as synced now.
+	// Returns ErrProjectNotFound if the project doesn't exist.
+	SaveReadme(ctx context.Context, projectId uint, readme string) error
+
+	// Get the time zones of projects, by project id. Projects that don't
+	// exist are left out.
+	GetTimeZones(ctx context.Context, projectIds []uint) (map[uint][]string, error)
+
+	// List a project's owner and accepted members.
+	ListMembers(ctx context.Context, projectId uint) ([]Member, error)
+
+	// List the permissions granted to a project's members, including members
+	// who left.
+	ListMemberPermissions(ctx context.Context, projectId uint) ([]MemberPermissions, error)
+
+	// Create or replace a member's permissions.
+	SaveMemberPermissions(ctx context.Context, permissions *MemberPermissions) error
+}
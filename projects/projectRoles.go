@@ -0,0 +1,36 @@
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnknownSeniority = errors.New("unknown seniority")
+
+// The experience a role expects.
+type Seniority string
+
+const (
+	SeniorityBeginner     Seniority = "beginner"
+	SeniorityIntermediate Seniority = "intermediate"
+	SeniorityExperienced  Seniority = "experienced"
+)
+
+// Lowercase and check seniorities, e.g. from a query string filter.
+// Returns ErrUnknownSeniority if a seniority doesn't exist.
+func NormalizeSeniorities(seniorities []string) ([]string, error) {
+	normalized := make([]string, len(seniorities))
+	for i, seniority := range seniorities {
+		seniority = strings.ToLower(strings.TrimSpace(seniority))
+
+		switch Seniority(seniority) {
+		case SeniorityBeginner, SeniorityIntermediate, SeniorityExperienced:
+			normalized[i] = seniority
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownSeniority, seniority)
+		}
+	}
+
+	return normalized, nil
+}
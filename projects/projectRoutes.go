@@ -2,11 +2,12 @@ package projects
 
 import (
 	"errors"
-	"fmt"
 	"github.com/apex/log"
 	"github.com/gorilla/mux"
-	"github.com/lib/pq"
+	"github.com/open-collaboration/server/audit"
 	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/presence"
+	"github.com/open-collaboration/server/users"
 	"github.com/open-collaboration/server/utils"
 	"net/http"
 	"strconv"
@@ -25,8 +26,9 @@ func RouteCreateProject(
 	writer http.ResponseWriter,
 	request *http.Request,
 	projectsService Service,
+	accountStatusProvider auth.AccountStatusProvider,
 ) error {
-	_, err := auth.CheckSession(request)
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
 	if err != nil {
 		return err
 	}
@@ -40,11 +42,13 @@ func RouteCreateProject(
 		return err
 	}
 
-	createdProject, err := projectsService.CreateProject(dto)
+	createdProject, err := projectsService.CreateProject(request.Context(), session.UserId(), dto, accountStatus.ShadowHidden)
 	if err != nil {
 		return err
 	}
 
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
 	projectSummary := projectsService.GetProjectSummary(createdProject)
 
 	writer.Header().Set("Location", "/projects/"+strconv.Itoa(int(createdProject.ID)))
@@ -64,7 +68,7 @@ func RouteUpdateProject(
 ) error {
 	logger := log.FromContext(request.Context())
 
-	_, err := auth.CheckSession(request)
+	session, err := auth.CheckSession(request)
 	if err != nil {
 		return err
 	}
@@ -99,18 +103,34 @@ func RouteUpdateProject(
 		return err
 	}
 
-	// TODO: check if the user owns the project
+	allowed, err := projectsService.HasPermission(request.Context(), projectId, session.UserId(), PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return auth.ErrForbidden
+	}
+
+	// Roles and screening questions decide who can apply, editing them
+	// takes the manage-roles permission too
+	saved, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	if rolesChanged(saved, dto) {
+		allowed, err = projectsService.HasPermission(request.Context(), projectId, session.UserId(), PermissionManageRoles)
+		if err != nil {
+			return err
+		}
 
-	project := NewProjectDto{
-		Name:             dto.Name,
-		Tags:             dto.Tags,
-		ShortDescription: dto.ShortDescription,
-		LongDescription:  dto.LongDescription,
-		GithubLink:       dto.GithubLink,
+		if !allowed {
+			return auth.ErrForbidden
+		}
 	}
-	fmt.Printf("%#v", project)
 
-	err = projectsService.UpdateProject(projectId, project)
+	err = projectsService.UpdateProject(request.Context(), projectId, dto)
 	if err != nil {
 		logger.WithError(err).Error("Failed to update project")
 		return err
@@ -121,12 +141,86 @@ func RouteUpdateProject(
 	return nil
 }
 
+// Whether updating the project to the dto would change its roles or
+// screening questions, including their order.
+func rolesChanged(saved ProjectDto, dto NewProjectDto) bool {
+	if len(saved.Roles) != len(dto.Roles) {
+		return true
+	}
+
+	for i, role := range dto.Roles {
+		savedRole := saved.Roles[i]
+		if role.Id != savedRole.Id ||
+			role.Title != savedRole.Title ||
+			role.Description != savedRole.Description ||
+			role.WeeklyHours != savedRole.WeeklyHours ||
+			role.Seniority != savedRole.Seniority ||
+			role.Mentorship != savedRole.Mentorship ||
+			!stringsEqual(role.Skills, savedRole.Skills) ||
+			questionsChanged(savedRole.Questions, role.Questions) {
+			return true
+		}
+	}
+
+	return questionsChanged(saved.Questions, dto.Questions)
+}
+
+func questionsChanged(saved []QuestionDto, questions []NewQuestionDto) bool {
+	if len(saved) != len(questions) {
+		return true
+	}
+
+	for i, question := range questions {
+		if question.Id != saved[i].Id || question.Text != saved[i].Text || question.Required != saved[i].Required {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stringsEqual(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 // @Summary List all projects
+// @Description Role filters (skills, seniorities, maxWeeklyHours and mentorship) must all match the same role,
+// @Description e.g. seniorities=beginner&maxWeeklyHours=5 lists projects with a beginner role of at most 5 hours per week.
+// @Description With a session, overlapHours is how many hours of the working day (9:00 to 18:00) the authenticated
+// @Description user shares with each project's time zones.
 // @Tags projects
 // @Router /projects [get]
 // @Param pageSize query int false "Maximum amount of projects in the response. Default is 20, max is 20."
 // @Param pageOffset query int false "Response page number. If pageSize is 20 and pageOffset is 2, the first 40 projects will be skipped."
+// @Param orderBy query string false "Either newest (default), quality or updated."
+// @Param licenses query string false "Comma separated SPDX license identifiers. Only projects with one of the licenses are listed."
+// @Param skills query string false "Comma separated skills. Only projects with a role requiring one of the skills are listed."
+// @Param seniorities query string false "Comma separated role seniorities (beginner, intermediate, experienced). Only projects with a role of one of them are listed."
+// @Param maxWeeklyHours query int false "Only projects with a role expecting at most this many hours per week are listed."
+// @Param mentorship query bool false "Only projects with a role offering mentorship are listed."
+// @Param languages query string false "Comma separated languages of the skills taxonomy. Only projects using one of them are listed."
+// @Param frameworks query string false "Comma separated frameworks of the skills taxonomy. Only projects using one of them are listed."
+// @Param platforms query string false "Comma separated platforms of the skills taxonomy. Only projects using one of them are listed."
+// @Param statuses query string false "Comma separated statuses (idea, planning, active, maintenance, completed, abandoned). Only projects with one of them are listed."
+// @Param spokenLanguages query string false "Comma separated ISO 639-1 codes or names of spoken languages (see GET /spoken-languages). Only projects communicating in one of them are listed."
+// @Param collection query int false "Only projects in the collection (e.g. a hackathon) are listed."
+// @Param fields query string false "Comma separated fields of the projects to include in the response, e.g. name,shortDescription. All fields by default."
+// @Produce json,application/hal+json
 // @Success 200 {object} dtos.ProjectSummaryDto.
+// @Header 200 {int} X-Total-Count "Total amount of projects matching the filters"
+// @Header 200 {int} X-Page "The current page (pageOffset)"
+// @Header 200 {int} X-Page-Size "The page size"
+// @Header 200 {bool} X-Has-Next-Page "Whether there are more projects after this page"
 func RouteListProjects(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
 	// TODO: move hardcoded maximum and default page size values to
 	// 	an env variable
@@ -140,6 +234,31 @@ func RouteListProjects(writer http.ResponseWriter, request *http.Request, projec
 		tags = strings.Split(tagsRaw, ",")
 	}
 
+	filters := ProjectFilters{
+		Tags:       tags,
+		Skills:     utils.StringsFromQuery(request, "skills"),
+		Licenses:   utils.StringsFromQuery(request, "licenses"),
+		Languages:  utils.StringsFromQuery(request, "languages"),
+		Frameworks: utils.StringsFromQuery(request, "frameworks"),
+		Platforms:  utils.StringsFromQuery(request, "platforms"),
+		Statuses:   utils.StringsFromQuery(request, "statuses"),
+
+		SpokenLanguages: utils.StringsFromQuery(request, "spokenLanguages"),
+
+		Seniorities: utils.StringsFromQuery(request, "seniorities"),
+		Mentorship:  request.URL.Query().Get("mentorship") == "true",
+	}
+
+	maxWeeklyHours, _ := utils.IntFromQuery(request, "maxWeeklyHours", 0)
+	if maxWeeklyHours > 0 {
+		filters.MaxWeeklyHours = uint(maxWeeklyHours)
+	}
+
+	collection, _ := utils.IntFromQuery(request, "collection", 0)
+	if collection > 0 {
+		filters.Collection = uint(collection)
+	}
+
 	if pageSize < 1 || pageSize > 20 {
 		pageSize = 20
 	}
@@ -148,29 +267,88 @@ func RouteListProjects(writer http.ResponseWriter, request *http.Request, projec
 		pageOffset = 0
 	}
 
-	projectSummaries, err := projectsService.ListProjects(request.Context(), uint(pageSize), uint(pageOffset), tags, []string{})
+	order := ProjectOrder(request.URL.Query().Get("orderBy"))
+	if order != OrderByQuality && order != OrderByRecentlyUpdated {
+		order = OrderByNewest
+	}
+
+	projectSummaries, totalCount, err := projectsService.ListProjects(request.Context(), uint(pageSize), uint(pageOffset), filters, order)
 	if err != nil {
 		return err
 	}
 
-	for i := range projectSummaries {
-		projectSummaries[i].Skills = pq.StringArray{}
+	err = setOverlapHours(request, projectsService, projectSummaries)
+	if err != nil {
+		return err
 	}
 
-	err = utils.WriteJson(writer, request.Context(), http.StatusOK, projectSummaries)
+	utils.WritePaginationHeaders(writer, pageOffset, pageSize, totalCount)
+
+	response, err := utils.SelectFields(projectSummaries, utils.FieldsFromQuery(request))
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return WriteProjectSummaries(writer, request, response, utils.PageLinks(request, pageOffset, pageSize, totalCount))
+}
+
+// @Summary Discover projects
+// @Description Get a random sample of active projects, weighted by quality. Projects
+// @Description owned by the authenticated user, if any, are excluded, and overlapHours is
+// @Description how many hours of the working day (9:00 to 18:00) the user shares with each project.
+// @Tags projects
+// @Router /projects/discover [get]
+// @Param count query int false "Maximum amount of projects in the response. Default is 10, max is 20."
+// @Param fields query string false "Comma separated fields of the projects to include in the response, e.g. name,shortDescription. All fields by default."
+// @Produce json,application/hal+json
+// @Success 200 {array} dtos.ProjectSummaryDto
+func RouteDiscoverProjects(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	count, _ := utils.IntFromQuery(request, "count", 10)
+	if count < 1 || count > 20 {
+		count = 10
+	}
+
+	var excludeOwnerId uint
+	session, err := auth.CheckSession(request)
+	if err == nil {
+		excludeOwnerId = session.UserId()
+	}
+
+	projectSummaries, err := projectsService.DiscoverProjects(request.Context(), uint(count), excludeOwnerId)
+	if err != nil {
+		return err
+	}
+
+	err = setOverlapHours(request, projectsService, projectSummaries)
+	if err != nil {
+		return err
+	}
+
+	response, err := utils.SelectFields(projectSummaries, utils.FieldsFromQuery(request))
+	if err != nil {
+		return err
+	}
+
+	return WriteProjectSummaries(writer, request, response, utils.Links{
+		"self": {Href: request.URL.RequestURI()},
+	})
 }
 
 // @Summary Get project
+// @Description With a session, overlapHours is how many hours of the working day (9:00 to 18:00) the
+// @Description authenticated user shares with the project's time zones.
 // @Tags projects
 // @Router /projects/{id} [get]
 // @Param id path int true "The project ID"
+// @Param fields query string false "Comma separated fields of the project to include in the response, e.g. name,roles. All fields by default."
+// @Produce json,application/hal+json
 // @Success 200 {object} dtos.ProjectDto.
-func RouteGetProject(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+func RouteGetProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService Service,
+	usersService users.Service,
+) error {
 	var projectId uint
 	vars := mux.Vars(request)
 	if idStr, ok := vars["projectId"]; ok {
@@ -192,10 +370,673 @@ func RouteGetProject(writer http.ResponseWriter, request *http.Request, projects
 		}
 	}
 
-	err = utils.WriteJson(writer, request.Context(), http.StatusOK, dto)
+	// Projects pending review and drafts are only visible to their owner and
+	// moderators
+	if dto.PendingReview || dto.Draft {
+		session, err := auth.CheckSession(request)
+		if err == nil && session.UserId() != dto.OwnerId {
+			_, err = auth.CheckRole(request, usersService, users.RoleModerator)
+		}
+
+		if err != nil {
+			writer.WriteHeader(404)
+			return nil
+		}
+	}
+
+	session, err := auth.CheckSession(request)
+	if err == nil && len(dto.TimeZones) > 0 {
+		overlaps, err := projectsService.GetOverlapHours(request.Context(), session.UserId(), []uint{dto.Id})
+		if err != nil {
+			return err
+		}
+
+		if overlap, ok := overlaps[dto.Id]; ok {
+			dto.OverlapHours = &overlap
+		}
+	}
+
+	response, err := utils.SelectFields(dto, utils.FieldsFromQuery(request))
+	if err != nil {
+		return err
+	}
+
+	return writeProject(writer, request, dto, response)
+}
+
+// @Summary Get a project's quality score and completeness hints
+// @Tags projects
+// @Router /projects/{projectId}/quality [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {object} dtos.QualityDto
+// @Failure 403 "User doesn't have the edit-project permission"
+// @Failure 404 "Project not found"
+func RouteGetProjectQuality(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	session, err := auth.CheckSession(request)
 	if err != nil {
 		return err
 	}
 
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	_, err = projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		if errors.Is(err, ErrProjectNotFound) {
+			writer.WriteHeader(404)
+			return nil
+		}
+
+		return err
+	}
+
+	// Hints are only meant for the project's editors
+	allowed, err := projectsService.HasPermission(request.Context(), projectId, session.UserId(), PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return auth.ErrForbidden
+	}
+
+	quality, err := projectsService.GetProjectQuality(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, quality)
+}
+
+// @Summary Get similar projects
+// @Description Projects similar to the given one, based on shared tags and role skills and on
+// @Description description similarity. Meant for a "you might also like" section.
+// @Tags projects
+// @Router /projects/{projectId}/similar [get]
+// @Param projectId path int true "The project ID"
+// @Param fields query string false "Comma separated fields of the projects to include in the response, e.g. name,shortDescription. All fields by default."
+// @Produce json,application/hal+json
+// @Success 200 {array} dtos.ProjectSummaryDto
+// @Failure 404 "Project not found"
+func RouteGetSimilarProjects(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	projectSummaries, err := projectsService.SimilarProjects(request.Context(), projectId)
+	if err != nil {
+		if errors.Is(err, ErrProjectNotFound) {
+			writer.WriteHeader(404)
+			return nil
+		}
+
+		return err
+	}
+
+	err = setOverlapHours(request, projectsService, projectSummaries)
+	if err != nil {
+		return err
+	}
+
+	response, err := utils.SelectFields(projectSummaries, utils.FieldsFromQuery(request))
+	if err != nil {
+		return err
+	}
+
+	return WriteProjectSummaries(writer, request, response, utils.Links{
+		"self": {Href: request.URL.RequestURI()},
+	})
+}
+
+// Set the hours of the working day the authenticated user shares with each
+// of the projects. Leaves them null if there's no session.
+func setOverlapHours(request *http.Request, projectsService Service, projectSummaries []ProjectSummaryDto) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return nil
+	}
+
+	projectIds := make([]uint, len(projectSummaries))
+	for i, projectSummary := range projectSummaries {
+		projectIds[i] = projectSummary.Id
+	}
+
+	overlaps, err := projectsService.GetOverlapHours(request.Context(), session.UserId(), projectIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range projectSummaries {
+		if overlap, ok := overlaps[projectSummaries[i].Id]; ok {
+			projectSummaries[i].OverlapHours = &overlap
+		}
+	}
+
+	return nil
+}
+
+// @Summary List projects pending review
+// @Tags moderation
+// @Router /moderation/projects/pending [get]
+// @Param pageSize query int false "Maximum amount of projects in the response. Default is 20, max is 100."
+// @Param pageOffset query int false "Response page number."
+// @Success 200 {array} dtos.ProjectSummaryDto
+// @Header 200 {int} X-Total-Count "Total amount of projects pending review"
+// @Failure 403
+func RouteListPendingProjects(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService Service,
+	usersService users.Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 20)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	if pageOffset < 0 {
+		pageOffset = 0
+	}
+
+	projectSummaries, totalCount, err := projectsService.ListPendingProjects(request.Context(), uint(pageSize), uint(pageOffset))
+	if err != nil {
+		return err
+	}
+
+	utils.WritePaginationHeaders(writer, pageOffset, pageSize, totalCount)
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, projectSummaries)
+}
+
+// @Summary Approve a project pending review
+// @Tags moderation
+// @Router /moderation/projects/{projectId}/approve [post]
+// @Param projectId path int true "The project ID"
+// @Success 204
+// @Failure 403
+// @Failure 404
+func RouteApproveProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService Service,
+	usersService users.Service,
+	auditService audit.Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	err = projectsService.ApproveProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	err = auditService.Record(request.Context(), session.UserId(), "moderation.approve-project", "project", projectId, nil)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
 	return nil
 }
+
+// @Summary Change a project's status
+// @Description Move the project to another status of its lifecycle. The project's members are notified.
+// @Description idea: planning, active, abandoned. planning: idea, active, abandoned.
+// @Description active: maintenance, completed, abandoned. maintenance: active, completed, abandoned.
+// @Description completed: active, maintenance. abandoned: idea, planning, active.
+// @Tags projects
+// @Router /projects/{projectId}/status [put]
+// @Param projectId path int true "The project ID"
+// @Param status body dtos.StatusDto true "The new status"
+// @Success 204
+// @Failure 400 "Unknown status"
+// @Failure 403 "User doesn't have the edit-project permission"
+// @Failure 404 "Project not found"
+// @Failure 409 "The project can't move from its current status to the new one"
+func RouteChangeProjectStatus(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	allowed, err := projectsService.HasPermission(request.Context(), projectId, session.UserId(), PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	if !allowed {
+		return auth.ErrForbidden
+	}
+
+	dto := StatusDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = projectsService.ChangeStatus(request.Context(), session.UserId(), projectId, dto.Status)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Get a project's status history
+// @Tags projects
+// @Router /projects/{projectId}/status-history [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {array} dtos.StatusChangeDto
+// @Failure 404 "Project not found"
+func RouteListProjectStatusChanges(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService Service,
+	usersService users.Service,
+) error {
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	// Same visibility as the project itself
+	if project.PendingReview || project.Draft {
+		session, err := auth.CheckSession(request)
+		if err == nil && session.UserId() != project.OwnerId {
+			_, err = auth.CheckRole(request, usersService, users.RoleModerator)
+		}
+
+		if err != nil {
+			return ErrProjectNotFound
+		}
+	}
+
+	changes, err := projectsService.ListStatusChanges(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, changes)
+}
+
+// @Summary List a project's members
+// @Description The project's owner and accepted members, the owner first, with their permissions and whether they're
+// @Description online (see /presence/socket) so that users can tell whether a maintainer is likely to respond now.
+// @Description Members who hide their memberships are left out.
+// @Tags projects
+// @Router /projects/{projectId}/members [get]
+// @Param projectId path int true "The project ID"
+// @Success 200 {array} projects.MemberDto
+// @Failure 401
+// @Failure 404 "Project not found"
+func RouteListProjectMembers(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService Service,
+	usersService users.Service,
+	presenceService presence.Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	// Same visibility as the project itself
+	if (project.PendingReview || project.Draft) && session.UserId() != project.OwnerId {
+		_, err = auth.CheckRole(request, usersService, users.RoleModerator)
+		if err != nil {
+			return ErrProjectNotFound
+		}
+	}
+
+	members, err := projectsService.ListMembers(request.Context(), session.UserId(), projectId)
+	if err != nil {
+		return err
+	}
+
+	userIds := make([]uint, len(members))
+	for i, member := range members {
+		userIds[i] = member.UserId
+	}
+
+	membersPresence, err := presenceService.GetPresence(request.Context(), userIds)
+	if err != nil {
+		return err
+	}
+
+	for i := range members {
+		members[i].Online = membersPresence[members[i].UserId].Online
+		members[i].LastSeenAt = membersPresence[members[i].UserId].LastSeenAt
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, members)
+}
+
+// @Summary Set a member's permissions
+// @Description Replace what one of the project's members can do besides contributing: manage-roles (edit the open
+// @Description roles and review applications), edit-project (edit the project, its README, events and contributions),
+// @Description moderate-comments (moderate the project's chat), post-announcements (POST
+// @Description /projects/{projectId}/announcements) and manage-integrations. Only the project's owner can, and has
+// @Description every permission.
+// @Tags projects
+// @Router /projects/{projectId}/members/{userId}/permissions [put]
+// @Param projectId path int true "The project ID"
+// @Param userId path int true "The member's user ID"
+// @Param permissions body dtos.MemberPermissionsDto true "The member's permissions"
+// @Success 200 {array} string "The member's permissions"
+// @Failure 400 "The user isn't a member of the project (not-member-error) or is its owner"
+// @Failure 401
+// @Failure 403 "User does not own the project"
+// @Failure 404 "Project not found"
+func RouteSetMemberPermissions(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := checkProjectOwner(request, projectsService)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	dto := MemberPermissionsDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := projectsService.SetPermissions(request.Context(), session.UserId(), projectId, userId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, permissions)
+}
+
+// @Summary List the license catalog
+// @Description The licenses projects can declare, by SPDX identifier.
+// @Tags projects
+// @Router /licenses [get]
+// @Success 200 {array} projects.License
+func RouteListLicenses(writer http.ResponseWriter, request *http.Request) error {
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, Licenses())
+}
+
+// @Summary List the spoken language catalog
+// @Description The languages projects can declare they communicate in, by ISO 639-1 code.
+// @Tags projects
+// @Router /spoken-languages [get]
+// @Success 200 {array} projects.SpokenLanguage
+func RouteListSpokenLanguages(writer http.ResponseWriter, request *http.Request) error {
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, SpokenLanguages())
+}
+
+// @Summary List the skills taxonomy
+// @Description The technologies projects can declare in their tech stack.
+// @Tags projects
+// @Router /skills [get]
+// @Param category query string false "Only list the technologies of a category: language, framework or platform."
+// @Success 200 {array} projects.Technology
+func RouteListTechnologies(writer http.ResponseWriter, request *http.Request) error {
+	category := TechCategory(request.URL.Query().Get("category"))
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, Technologies(category))
+}
+
+// @Summary Add and remove tags of a project
+// @Description Edits the project's tags in a single transaction. Tags are trimmed and compared
+// @Description case-insensitively, duplicates are dropped. Removals are applied before additions.
+// @Tags projects
+// @Router /projects/{projectId}/tags [patch]
+// @Param projectId path int true "The project ID"
+// @Param tags body dtos.BulkEditDto true "The tags to add and remove"
+// @Success 200 {array} string "The project's new tags"
+// @Failure 400 "A tag is banned or the project would have less than 1 or more than 6 tags"
+// @Failure 403 "User doesn't have the edit-project permission"
+// @Failure 404 "Project not found"
+func RouteEditProjectTags(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	projectId, err := CheckPermission(request, projectsService, PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	dto := BulkEditDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	tags, err := projectsService.EditTags(request.Context(), projectId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, tags)
+}
+
+// @Summary Add and remove skills of a project's role
+// @Description Edits the role's skills in a single transaction, like the project's tags.
+// @Tags projects
+// @Router /projects/{projectId}/roles/{roleId}/skills [patch]
+// @Param projectId path int true "The project ID"
+// @Param roleId path int true "The role ID"
+// @Param skills body dtos.BulkEditDto true "The skills to add and remove"
+// @Success 200 {array} string "The role's new skills"
+// @Failure 400 "The role would have more than 10 skills"
+// @Failure 403 "User doesn't have the manage-roles permission"
+// @Failure 404 "Project or role not found"
+func RouteEditRoleSkills(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	projectId, err := CheckPermission(request, projectsService, PermissionManageRoles)
+	if err != nil {
+		return err
+	}
+
+	roleId, err := utils.UintFromVars(request, "roleId")
+	if err != nil {
+		return err
+	}
+
+	dto := BulkEditDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	skills, err := projectsService.EditRoleSkills(request.Context(), projectId, roleId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, skills)
+}
+
+// @Summary Clone a project
+// @Description Creates a draft project with the project's structure: its tags, descriptions, tech stack and roles,
+// @Description including their screening questions. The draft starts as an idea, without a GitHub link or a cover image,
+// @Description and is only visible to its owner until it's published (POST /projects/{projectId}/publish).
+// @Tags projects
+// @Router /projects/{projectId}/clone [post]
+// @Param projectId path int true "The project ID"
+// @Success 201 {object} dtos.ProjectSummaryDto
+// @Failure 403 "User does not own the project"
+// @Failure 404 "Project not found"
+func RouteCloneProject(
+	writer http.ResponseWriter,
+	request *http.Request,
+	projectsService Service,
+	accountStatusProvider auth.AccountStatusProvider,
+) error {
+	session, accountStatus, err := auth.CheckPostingSession(request, accountStatusProvider)
+	if err != nil {
+		return err
+	}
+
+	projectId, err := checkProjectOwner(request, projectsService)
+	if err != nil {
+		return err
+	}
+
+	clone, err := projectsService.CloneProject(request.Context(), session.UserId(), projectId, accountStatus.ShadowHidden)
+	if err != nil {
+		return err
+	}
+
+	auth.StartPostingCooldown(request, accountStatusProvider, session, accountStatus)
+
+	writer.Header().Set("Location", "/projects/"+strconv.Itoa(int(clone.ID)))
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, projectsService.GetProjectSummary(clone))
+}
+
+// @Summary Publish a draft project
+// @Description Makes a draft (see POST /projects/{projectId}/clone and POST /projects/import-from-github) visible to everyone.
+// @Description The draft is checked like an update of the project, e.g. an imported draft needs a long enough
+// @Description description and at least one tag.
+// @Tags projects
+// @Router /projects/{projectId}/publish [post]
+// @Param projectId path int true "The project ID"
+// @Success 204
+// @Failure 400 "The draft isn't a valid project or breaks the rules of its owner's plan"
+// @Failure 403 "User doesn't have the edit-project permission"
+// @Failure 404 "Project not found"
+func RoutePublishProject(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	projectId, err := CheckPermission(request, projectsService, PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	err = projectsService.PublishProject(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// @Summary Count a click on a funding link
+// @Description Clients call this when someone follows one of the project's funding links. Clicks from the same
+// @Description IP address are counted once an hour.
+// @Tags projects
+// @Router /projects/{projectId}/funding/{fundingLinkId}/clicks [post]
+// @Param projectId path int true "The project ID"
+// @Param fundingLinkId path int true "The funding link ID"
+// @Success 204
+// @Failure 404 "Project or funding link not found"
+func RouteRecordFundingClick(writer http.ResponseWriter, request *http.Request, projectsService Service) error {
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return err
+	}
+
+	fundingLinkId, err := utils.UintFromVars(request, "fundingLinkId")
+	if err != nil {
+		return err
+	}
+
+	err = projectsService.RecordFundingClick(request.Context(), projectId, fundingLinkId, utils.ClientIp(request.Context()))
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// Check that the request's session belongs to a member of the project in the
+// projectId route variable who has the permission, see
+// Service.HasPermission. Returns the project's id.
+func CheckPermission(request *http.Request, projectsService Service, permission Permission) (uint, error) {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return 0, err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return 0, err
+	}
+
+	allowed, err := projectsService.HasPermission(request.Context(), projectId, session.UserId(), permission)
+	if err != nil {
+		return 0, err
+	}
+
+	if !allowed {
+		return 0, auth.ErrForbidden
+	}
+
+	return projectId, nil
+}
+
+// Check that the request's session belongs to the owner of the project in
+// the projectId route variable. Returns the project's id.
+func checkProjectOwner(request *http.Request, projectsService Service) (uint, error) {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return 0, err
+	}
+
+	projectId, err := utils.UintFromVars(request, "projectId")
+	if err != nil {
+		return 0, err
+	}
+
+	project, err := projectsService.GetProject(request.Context(), projectId)
+	if err != nil {
+		return 0, err
+	}
+
+	if project.OwnerId != session.UserId() {
+		return 0, auth.ErrForbidden
+	}
+
+	return projectId, nil
+}
@@ -0,0 +1,144 @@
+package projects_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/open-collaboration/server/auth"
+	authMocks "github.com/open-collaboration/server/auth/mocks"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/projects/mocks"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validNewProjectDto() projects.NewProjectDto {
+	return projects.NewProjectDto{
+		Name:             "Open Collaboration",
+		Tags:             []string{"go"},
+		LongDescription:  strings.Repeat("A platform where people find projects to contribute to. ", 4),
+		ShortDescription: "Find projects to contribute to",
+		GithubLink:       "https://github.com/open-collaboration/server",
+	}
+}
+
+// A request to create a project, made by user 7.
+func newCreateProjectRequest(t *testing.T, dto projects.NewProjectDto) *http.Request {
+	body, err := json.Marshal(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest("POST", "/projects", bytes.NewReader(body))
+
+	return auth.WithSession(request, "session-token", 7)
+}
+
+func TestRouteCreateProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	dto := validNewProjectDto()
+	status := auth.AccountStatus{PostingCooldown: time.Minute, ShadowHidden: true}
+	project := &projects.Project{Model: gorm.Model{ID: 12}, Name: dto.Name}
+
+	accountStatusProvider := authMocks.NewMockAccountStatusProvider(ctrl)
+	accountStatusProvider.EXPECT().GetAccountStatus(gomock.Any(), uint(7)).Return(status, nil)
+	accountStatusProvider.EXPECT().CheckPostingCooldown(gomock.Any(), uint(7)).Return(nil)
+
+	projectsService := mocks.NewMockService(ctrl)
+	created := projectsService.EXPECT().
+		CreateProject(gomock.Any(), uint(7), gomock.Any(), true).
+		DoAndReturn(func(_ interface{}, _ uint, newProject projects.NewProjectDto, _ bool) (*projects.Project, error) {
+			if newProject.Name != dto.Name {
+				t.Errorf("expected the project %q, got %q", dto.Name, newProject.Name)
+			}
+
+			return project, nil
+		})
+	projectsService.EXPECT().
+		GetProjectSummary(project).
+		Return(projects.ProjectSummaryDto{Id: 12, Name: dto.Name})
+
+	// Only once the project exists
+	accountStatusProvider.EXPECT().
+		StartPostingCooldown(gomock.Any(), uint(7), time.Minute).
+		After(created).
+		Return(nil)
+
+	recorder := httptest.NewRecorder()
+	err := projects.RouteCreateProject(recorder, newCreateProjectRequest(t, dto), projectsService, accountStatusProvider)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("expected status 201, got %d", recorder.Code)
+	}
+
+	if location := recorder.Header().Get("Location"); location != "/projects/12" {
+		t.Errorf("expected the location /projects/12, got %q", location)
+	}
+
+	var summary projects.ProjectSummaryDto
+	err = json.NewDecoder(recorder.Body).Decode(&summary)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Id != 12 || summary.Name != dto.Name {
+		t.Errorf("expected the created project's summary, got %+v", summary)
+	}
+}
+
+func TestRouteCreateProjectFailureKeepsCooldown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failure := errors.New("project could not be saved")
+
+	accountStatusProvider := authMocks.NewMockAccountStatusProvider(ctrl)
+	accountStatusProvider.EXPECT().
+		GetAccountStatus(gomock.Any(), uint(7)).
+		Return(auth.AccountStatus{PostingCooldown: time.Minute}, nil)
+	accountStatusProvider.EXPECT().CheckPostingCooldown(gomock.Any(), uint(7)).Return(nil)
+
+	// StartPostingCooldown isn't expected
+	projectsService := mocks.NewMockService(ctrl)
+	projectsService.EXPECT().
+		CreateProject(gomock.Any(), uint(7), gomock.Any(), false).
+		Return(nil, failure)
+
+	recorder := httptest.NewRecorder()
+	err := projects.RouteCreateProject(recorder, newCreateProjectRequest(t, validNewProjectDto()), projectsService, accountStatusProvider)
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the service's error, got %v", err)
+	}
+}
+
+func TestRouteCreateProjectDuringCooldown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountStatusProvider := authMocks.NewMockAccountStatusProvider(ctrl)
+	accountStatusProvider.EXPECT().
+		GetAccountStatus(gomock.Any(), uint(7)).
+		Return(auth.AccountStatus{PostingCooldown: time.Minute}, nil)
+	accountStatusProvider.EXPECT().
+		CheckPostingCooldown(gomock.Any(), uint(7)).
+		Return(auth.ErrPostingCooldown)
+
+	// The project isn't created
+	projectsService := mocks.NewMockService(ctrl)
+
+	recorder := httptest.NewRecorder()
+	err := projects.RouteCreateProject(recorder, newCreateProjectRequest(t, validNewProjectDto()), projectsService, accountStatusProvider)
+	if !errors.Is(err, auth.ErrPostingCooldown) {
+		t.Errorf("expected ErrPostingCooldown, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package projects
+
+import (
+	"fmt"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/validation"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Business rules of created and updated projects that NewProjectDto's struct
+// tags can't express. Broken rules are returned as validation.Errors.
+type Rules struct {
+	// Words project names can't contain, matched as whole words regardless of
+	// case, so that e.g. "class" doesn't match "ass"
+	BannedNameWords []string
+
+	// Hosts of the repositories' links, e.g. a GitHub Enterprise install's
+	GithubHosts []string
+
+	// The descriptions' lengths by the owner's plan, which is their role:
+	// organizers describe events and hackathons and get more room. Owners
+	// get the limits of the highest role they include.
+	DescriptionLimits map[users.Role]DescriptionLimits
+}
+
+// Lengths in characters. NewProjectDto's struct tags are the bounds of all
+// plans.
+type DescriptionLimits struct {
+	MinLong  int
+	MaxLong  int
+	MaxShort int
+}
+
+var DefaultGithubHosts = []string{"github.com", "www.github.com"}
+
+var DefaultDescriptionLimits = map[users.Role]DescriptionLimits{
+	users.RoleUser:      {MinLong: 200, MaxLong: 10000, MaxShort: 200},
+	users.RoleOrganizer: {MinLong: 200, MaxLong: 20000, MaxShort: 200},
+}
+
+// Roles from the highest to the lowest, to find an owner's plan
+var planRoles = []users.Role{users.RoleAdmin, users.RoleModerator, users.RoleOrganizer, users.RoleUser}
+
+// Check a project created or updated by an owner with the given role.
+// Returns validation.Errors with every broken rule.
+func (r Rules) Check(project NewProjectDto, ownerRole users.Role) error {
+	violations := validation.Errors{}
+
+	r.checkTags(project.Tags, &violations)
+	r.checkName(project.Name, &violations)
+	r.checkGithubLink(project.GithubLink, &violations)
+	r.checkDescriptions(project, ownerRole, &violations)
+
+	return violations.Err()
+}
+
+// Tags that only differ in case are the same tag, so duplicates don't count
+// towards the minimum.
+func (r Rules) checkTags(tags []string, violations *validation.Errors) {
+	distinct := map[string]bool{}
+	for _, tag := range tags {
+		distinct[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+
+	if len(distinct) < minTags || len(distinct) > maxTags {
+		violations.Add("tags", "tag-count", ErrTagCount.Error(), map[string]interface{}{
+			"min":   minTags,
+			"max":   maxTags,
+			"count": len(distinct),
+		})
+	}
+}
+
+func (r Rules) checkName(name string, violations *validation.Errors) {
+	words := strings.FieldsFunc(strings.ToLower(name), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+
+	for _, word := range words {
+		for _, banned := range r.BannedNameWords {
+			if word == strings.ToLower(banned) {
+				violations.Add("name", "banned-word", fmt.Sprintf("the name can't contain %q", word), map[string]interface{}{
+					"word": word,
+				})
+
+				return
+			}
+		}
+	}
+}
+
+// The link must be a repository's, https://<host>/<owner>/<repository>. Like
+// in contributions, the scheme is optional.
+func (r Rules) checkGithubLink(githubLink string, violations *validation.Errors) {
+	link := strings.TrimSpace(githubLink)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		violations.Add("githubLink", "repository-url", "the link isn't a repository's url", nil)
+
+		return
+	}
+
+	allowed := false
+	for _, host := range r.GithubHosts {
+		if strings.EqualFold(parsed.Host, host) {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		violations.Add("githubLink", "repository-host", fmt.Sprintf("repositories can't be hosted on %s", parsed.Host), map[string]interface{}{
+			"hosts": r.GithubHosts,
+		})
+
+		return
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		violations.Add("githubLink", "repository-url", "the link isn't a repository's url", nil)
+	}
+}
+
+func (r Rules) checkDescriptions(project NewProjectDto, ownerRole users.Role, violations *validation.Errors) {
+	limits, plan := r.planLimits(ownerRole)
+
+	longLength := utf8.RuneCountInString(project.LongDescription)
+	if longLength < limits.MinLong || longLength > limits.MaxLong {
+		violations.Add("longDescription", "description-length", fmt.Sprintf("the description must have between %d and %d characters", limits.MinLong, limits.MaxLong), map[string]interface{}{
+			"min":    limits.MinLong,
+			"max":    limits.MaxLong,
+			"length": longLength,
+			"plan":   plan,
+		})
+	}
+
+	shortLength := utf8.RuneCountInString(project.ShortDescription)
+	if shortLength > limits.MaxShort {
+		violations.Add("shortDescription", "description-length", fmt.Sprintf("the short description can have at most %d characters", limits.MaxShort), map[string]interface{}{
+			"max":    limits.MaxShort,
+			"length": shortLength,
+			"plan":   plan,
+		})
+	}
+}
+
+// The limits of the highest role in DescriptionLimits that role includes, and
+// that role.
+func (r Rules) planLimits(role users.Role) (DescriptionLimits, users.Role) {
+	for _, plan := range planRoles {
+		limits, ok := r.DescriptionLimits[plan]
+		if ok && role.Includes(plan) {
+			return limits, plan
+		}
+	}
+
+	return DefaultDescriptionLimits[users.RoleUser], users.RoleUser
+}
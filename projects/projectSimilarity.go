@@ -0,0 +1,117 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+// Scores how similar the descriptions of projects are to a project's
+// description. Implementations could compare text directly (e.g. trigrams)
+// or compare embeddings of the descriptions.
+type DescriptionSimilarity interface {
+	// Find at most limit listed projects (not pending review) whose
+	// descriptions are the most similar to the given project's. Returns a map
+	// of project ids to a similarity between 0 and 1. The given project is
+	// never included.
+	FindSimilarDescriptions(ctx context.Context, project *Project, limit int) (map[uint]float64, error)
+}
+
+type trigramSimilarity struct {
+	Db *gorm.DB
+}
+
+// Create a DescriptionSimilarity that compares the projects' short
+// descriptions with postgres' pg_trgm extension.
+func NewTrigramSimilarity(db *gorm.DB) DescriptionSimilarity {
+	return &trigramSimilarity{
+		Db: db,
+	}
+}
+
+func (t *trigramSimilarity) FindSimilarDescriptions(
+	ctx context.Context,
+	project *Project,
+	limit int,
+) (map[uint]float64, error) {
+	var rows []struct {
+		Id         uint
+		Similarity float64
+	}
+
+	// The % operator uses the trigram index on short_description, while
+	// similarity() is only computed for the rows it matches.
+	result := t.Db.WithContext(ctx).Raw(`
+		SELECT id, similarity(short_description, ?) AS similarity
+		FROM projects
+		WHERE deleted_at IS NULL
+		  AND pending_review = false
+		  AND draft = false
+		  AND id <> ?
+		  AND short_description % ?
+		ORDER BY similarity DESC
+		LIMIT ?`,
+		project.ShortDescription,
+		project.ID,
+		project.ShortDescription,
+		limit,
+	).Scan(&rows)
+
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	similarities := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		similarities[row.Id] = row.Similarity
+	}
+
+	return similarities, nil
+}
+
+// How much each signal weighs in a project's similarity score.
+const (
+	similarTagsWeight        = 0.3
+	similarSkillsWeight      = 0.2
+	similarStackWeight       = 0.2
+	similarDescriptionWeight = 0.3
+)
+
+// Maximum amount of candidates fetched by each signal before scoring.
+const similarCandidatesLimit = 100
+
+// The jaccard index of two sets of strings: the size of their intersection
+// divided by the size of their union.
+func jaccardIndex(a []string, b []string) float64 {
+	set := make(map[string]bool, len(a))
+	for _, value := range a {
+		set[value] = true
+	}
+
+	union := len(set)
+	intersection := 0
+	seen := make(map[string]bool, len(b))
+	for _, value := range b {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+
+		if set[value] {
+			intersection++
+		} else {
+			union++
+		}
+	}
+
+	if union == 0 {
+		return 0
+	}
+
+	return float64(intersection) / float64(union)
+}
+
+// Caches a project's similar projects.
+func similarProjectsRedisKey(projectId uint) string {
+	return fmt.Sprintf("project:%d:similar", projectId)
+}
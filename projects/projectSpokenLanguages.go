@@ -0,0 +1,239 @@
+package projects
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrUnknownSpokenLanguage = errors.New("unknown spoken language")
+
+// A language people speak, identified by its ISO 639-1 code. Projects declare
+// the languages they communicate in, which has nothing to do with the
+// programming languages of their tech stack.
+type SpokenLanguage struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// The spoken languages projects can declare.
+var spokenLanguages = []SpokenLanguage{
+	{"ar", "Arabic"},
+	{"bn", "Bengali"},
+	{"cs", "Czech"},
+	{"de", "German"},
+	{"el", "Greek"},
+	{"en", "English"},
+	{"es", "Spanish"},
+	{"fa", "Persian"},
+	{"fr", "French"},
+	{"he", "Hebrew"},
+	{"hi", "Hindi"},
+	{"id", "Indonesian"},
+	{"it", "Italian"},
+	{"ja", "Japanese"},
+	{"ko", "Korean"},
+	{"nl", "Dutch"},
+	{"pl", "Polish"},
+	{"pt", "Portuguese"},
+	{"ro", "Romanian"},
+	{"ru", "Russian"},
+	{"sv", "Swedish"},
+	{"th", "Thai"},
+	{"tr", "Turkish"},
+	{"uk", "Ukrainian"},
+	{"vi", "Vietnamese"},
+	{"zh", "Chinese"},
+}
+
+// All spoken languages in the catalog, ordered by id.
+func SpokenLanguages() []SpokenLanguage {
+	return append([]SpokenLanguage{}, spokenLanguages...)
+}
+
+// Get the catalog's id of a spoken language, given its id or its English
+// name, case insensitively, so e.g. "EN" and "english" are en.
+// Returns ErrUnknownSpokenLanguage if the language isn't in the catalog.
+func NormalizeSpokenLanguage(language string) (string, error) {
+	language = strings.TrimSpace(language)
+
+	for _, spokenLanguage := range spokenLanguages {
+		if strings.EqualFold(spokenLanguage.Id, language) || strings.EqualFold(spokenLanguage.Name, language) {
+			return spokenLanguage.Id, nil
+		}
+	}
+
+	return "", ErrUnknownSpokenLanguage
+}
+
+// Get the catalog's ids of spoken languages, without duplicates.
+// Returns ErrUnknownSpokenLanguage if any of the languages isn't in the
+// catalog.
+func NormalizeSpokenLanguages(languages []string) ([]string, error) {
+	normalized := make([]string, 0, len(languages))
+	seen := map[string]bool{}
+
+	for _, language := range languages {
+		id, err := NormalizeSpokenLanguage(language)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			normalized = append(normalized, id)
+		}
+	}
+
+	return normalized, nil
+}
+
+// Texts with fewer letters than this aren't detected, there's too little to
+// go by.
+const minDetectionLetters = 40
+
+// A language written in its own script is detected when at least this share
+// of the text's letters are of the script.
+const minScriptShare = 0.3
+
+// A language written in the Latin script is detected when at least this share
+// of the text's words are among its most common words, and it has at least
+// twice as many of them as any other language.
+const minStopwordShare = 0.08
+
+// Scripts only (or mostly) used by one of the catalog's languages.
+var languageScripts = []struct {
+	language string
+	script   *unicode.RangeTable
+}{
+	{"ar", unicode.Arabic},
+	{"bn", unicode.Bengali},
+	{"el", unicode.Greek},
+	{"he", unicode.Hebrew},
+	{"hi", unicode.Devanagari},
+	{"ja", unicode.Hiragana},
+	{"ja", unicode.Katakana},
+	{"ko", unicode.Hangul},
+	{"ru", unicode.Cyrillic},
+	{"th", unicode.Thai},
+	{"zh", unicode.Han},
+}
+
+// The most common words of languages written in the Latin script, that
+// aren't common words of the others.
+var languageStopwords = map[string][]string{
+	"de": {"der", "die", "und", "ist", "nicht", "mit", "das", "ein", "eine", "für", "auf", "wir", "sich", "auch", "oder", "zu"},
+	"en": {"the", "and", "is", "are", "with", "for", "to", "of", "this", "that", "it", "we", "you", "on", "be", "an"},
+	"es": {"el", "los", "las", "y", "es", "una", "para", "con", "por", "que", "del", "se", "como", "su", "al", "más"},
+	"fr": {"le", "les", "et", "est", "une", "des", "pour", "avec", "dans", "sur", "nous", "vous", "qui", "du", "au", "pas"},
+	"id": {"dan", "yang", "untuk", "dengan", "ini", "itu", "adalah", "dari", "kami", "pada", "akan", "tidak", "bisa", "ke"},
+	"it": {"il", "gli", "e", "è", "una", "per", "con", "che", "di", "della", "sono", "non", "questo", "nel", "alla"},
+	"nl": {"het", "een", "en", "van", "is", "voor", "met", "dat", "niet", "wij", "zijn", "ook", "naar", "bij", "op"},
+	"pl": {"i", "jest", "nie", "się", "na", "w", "z", "do", "że", "jak", "dla", "oraz", "który", "to", "są"},
+	"pt": {"o", "os", "e", "é", "uma", "para", "com", "por", "que", "não", "do", "da", "dos", "em", "são"},
+	"sv": {"och", "är", "att", "det", "som", "för", "med", "inte", "vi", "på", "av", "en", "till", "den"},
+	"tr": {"ve", "bir", "bu", "için", "ile", "da", "de", "çok", "olan", "gibi", "daha", "değil", "biz", "ne"},
+}
+
+// Detect the spoken language of a text, e.g. a project's description. Only
+// the most likely language is returned, nothing if the text is too short or
+// no language stands out. Detection is a best guess for owners that didn't
+// declare their project's languages, it's never as good as declaring them.
+func DetectSpokenLanguages(text string) []string {
+	letters := 0
+	scriptLetters := map[string]int{}
+
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+		for _, languageScript := range languageScripts {
+			if unicode.Is(languageScript.script, r) {
+				scriptLetters[languageScript.language]++
+
+				break
+			}
+		}
+	}
+
+	if letters < minDetectionLetters {
+		return []string{}
+	}
+
+	// Japanese uses Han characters too, kana tell it apart from Chinese
+	if scriptLetters["ja"] > 0 {
+		scriptLetters["ja"] += scriptLetters["zh"]
+		delete(scriptLetters, "zh")
+	}
+
+	if language, count := mostCommon(scriptLetters); float64(count) >= minScriptShare*float64(letters) {
+		// Cyrillic is also Ukrainian's script, its own letters tell it apart
+		if language == "ru" && strings.ContainsAny(strings.ToLower(text), "їєіґ") {
+			language = "uk"
+		}
+
+		return []string{language}
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	stopwordCounts := map[string]int{}
+	for language, stopwords := range languageStopwords {
+		for _, word := range words {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					stopwordCounts[language]++
+
+					break
+				}
+			}
+		}
+	}
+
+	language, count := mostCommon(stopwordCounts)
+	if count < 1 || float64(count) < minStopwordShare*float64(len(words)) {
+		return []string{}
+	}
+
+	for other, otherCount := range stopwordCounts {
+		if other != language && otherCount*2 > count {
+			return []string{}
+		}
+	}
+
+	return []string{language}
+}
+
+// The key with the highest count, the first in alphabetical order on ties so
+// that detection is deterministic.
+func mostCommon(counts map[string]int) (string, int) {
+	best, bestCount := "", 0
+	for key, count := range counts {
+		if count > bestCount || (count == bestCount && key < best) {
+			best, bestCount = key, count
+		}
+	}
+
+	return best, bestCount
+}
+
+// The spoken languages of a new or updated project: the ones the owner
+// declared, or the ones detected from its name and descriptions if they
+// didn't declare any. The second value is whether they were detected.
+// Returns ErrUnknownSpokenLanguage if a declared language isn't in the
+// catalog.
+func projectSpokenLanguages(dto NewProjectDto) ([]string, bool, error) {
+	if len(dto.SpokenLanguages) > 0 {
+		languages, err := NormalizeSpokenLanguages(dto.SpokenLanguages)
+
+		return languages, false, err
+	}
+
+	text := strings.Join([]string{dto.Name, dto.ShortDescription, dto.LongDescription}, "\n")
+
+	return DetectSpokenLanguages(text), true, nil
+}
@@ -0,0 +1,163 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/notifications"
+	"gorm.io/gorm"
+	"strings"
+)
+
+var ErrUnknownStatus = errors.New("unknown project status")
+var ErrInvalidStatusTransition = errors.New("invalid project status transition")
+
+// Where a project is in its lifecycle.
+type ProjectStatus string
+
+const (
+	StatusIdea        ProjectStatus = "idea"
+	StatusPlanning    ProjectStatus = "planning"
+	StatusActive      ProjectStatus = "active"
+	StatusMaintenance ProjectStatus = "maintenance"
+	StatusCompleted   ProjectStatus = "completed"
+	StatusAbandoned   ProjectStatus = "abandoned"
+)
+
+// The statuses a project can move to from each status. Completed and
+// abandoned projects can be picked up again.
+var statusTransitions = map[ProjectStatus][]ProjectStatus{
+	StatusIdea:        {StatusPlanning, StatusActive, StatusAbandoned},
+	StatusPlanning:    {StatusIdea, StatusActive, StatusAbandoned},
+	StatusActive:      {StatusMaintenance, StatusCompleted, StatusAbandoned},
+	StatusMaintenance: {StatusActive, StatusCompleted, StatusAbandoned},
+	StatusCompleted:   {StatusActive, StatusMaintenance},
+	StatusAbandoned:   {StatusIdea, StatusPlanning, StatusActive},
+}
+
+// A change of a project's status. A project's changes make up its status
+// history.
+type StatusChange struct {
+	gorm.Model
+
+	ProjectId uint
+	From      ProjectStatus
+	To        ProjectStatus
+
+	// The user who changed the status
+	ChangedBy uint
+}
+
+func (StatusChange) TableName() string {
+	return "project_status_changes"
+}
+
+// The statuses a project can move to from the given status.
+func StatusTransitions(from ProjectStatus) []ProjectStatus {
+	return append([]ProjectStatus{}, statusTransitions[from]...)
+}
+
+func canTransition(from ProjectStatus, to ProjectStatus) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Lowercase and check statuses, e.g. from a query string filter.
+// Returns ErrUnknownStatus if a status doesn't exist.
+func NormalizeStatuses(statuses []string) ([]string, error) {
+	normalized := make([]string, len(statuses))
+	for i, status := range statuses {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if _, ok := statusTransitions[ProjectStatus(status)]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownStatus, status)
+		}
+
+		normalized[i] = status
+	}
+
+	return normalized, nil
+}
+
+func (s *serviceImpl) ChangeStatus(ctx context.Context, userId uint, projectId uint, status ProjectStatus) error {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"projectId": projectId,
+		"status":    status,
+	})
+
+	if _, ok := statusTransitions[status]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownStatus, status)
+	}
+
+	project, change, err := s.Repository.ChangeStatus(ctx, projectId, status, userId, func(project *Project) error {
+		if !canTransition(project.Status, status) {
+			return fmt.Errorf("%w: from %s to %s", ErrInvalidStatusTransition, project.Status, status)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) && !errors.Is(err, ErrInvalidStatusTransition) {
+			logger.WithError(err).Error("Failed to change project status")
+		}
+
+		return err
+	}
+
+	logger.WithField("from", change.From).Info("Project status changed")
+
+	s.notifyStatusChange(ctx, project, change)
+	s.notifyListeners(ctx, projectId)
+
+	return nil
+}
+
+func (s *serviceImpl) ListStatusChanges(ctx context.Context, projectId uint) ([]StatusChangeDto, error) {
+	changes, err := s.Repository.ListStatusChanges(ctx, projectId)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to list project status changes")
+
+		return nil, err
+	}
+
+	return statusChangesToDtos(changes), nil
+}
+
+// Notify the project's owner and accepted members of a status change, except
+// the user who made it. Failures are only logged, the status has already
+// changed.
+func (s *serviceImpl) notifyStatusChange(ctx context.Context, project *Project, change StatusChange) {
+	logger := log.FromContext(ctx).WithField("projectId", project.ID)
+
+	memberIds, err := s.Repository.ListMemberIds(ctx, project.ID)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list project members, status change won't be notified")
+
+		return
+	}
+
+	for _, userId := range append([]uint{project.OwnerId}, memberIds...) {
+		if userId == change.ChangedBy {
+			continue
+		}
+
+		err := s.NotificationsService.Notify(ctx, userId, notifications.NewNotificationDto{
+			Type:  notifications.TypeProjectStatusChanged,
+			Title: fmt.Sprintf("%s is now %s", project.Name, change.To),
+			Body:  fmt.Sprintf("The project's status changed from %s to %s.", change.From, change.To),
+			Data: map[string]interface{}{
+				"projectId": project.ID,
+				"from":      change.From,
+				"to":        change.To,
+			},
+		})
+		if err != nil {
+			logger.WithError(err).WithField("userId", userId).Error("Failed to notify of project status change")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+)
+
+func (s *serviceImpl) MergeTags(ctx context.Context, sources []string, target string) (int64, error) {
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"sources": sources,
+		"target":  target,
+	})
+
+	affected, err := s.Repository.MergeTags(ctx, sources, target)
+	if errors.Is(err, ErrTagBanned) {
+		return 0, fmt.Errorf("%w: %s", ErrTagBanned, target)
+	} else if err != nil {
+		logger.WithError(err).Error("Failed to merge tags")
+
+		return 0, err
+	}
+
+	logger.Infof("Merged tags of %d projects", affected)
+
+	s.invalidateSimilarProjects(ctx)
+
+	return affected, nil
+}
+
+func (s *serviceImpl) BanTag(ctx context.Context, tag string, reason string) error {
+	logger := log.FromContext(ctx).WithField("tag", tag)
+
+	err := s.Repository.BanTag(ctx, tag, reason)
+	if err != nil {
+		logger.WithError(err).Error("Failed to ban tag")
+
+		return err
+	}
+
+	s.invalidateSimilarProjects(ctx)
+
+	return nil
+}
+
+func (s *serviceImpl) UnbanTag(ctx context.Context, tag string) error {
+	err := s.Repository.UnbanTag(ctx, tag)
+	if err != nil && !errors.Is(err, ErrTagNotBanned) {
+		log.FromContext(ctx).WithError(err).Error("Failed to unban tag")
+	}
+
+	return err
+}
+
+func (s *serviceImpl) ListBannedTags(ctx context.Context) ([]BannedTagDto, error) {
+	bannedTags, err := s.Repository.ListBannedTags(ctx)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to list banned tags")
+
+		return nil, err
+	}
+
+	return bannedTagsToDtos(bannedTags), nil
+}
+
+// Returns ErrTagBanned, wrapped with the tag's name, if any of the
+// tags is banned.
+func (s *serviceImpl) checkBannedTags(ctx context.Context, tags []string) error {
+	if len(tags) < 1 {
+		return nil
+	}
+
+	banned, err := s.Repository.FindBannedTag(ctx, tags)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to check for banned tags")
+
+		return err
+	}
+
+	if banned != "" {
+		return fmt.Errorf("%w: %s", ErrTagBanned, banned)
+	}
+
+	return nil
+}
+
+// Delete all cached similar projects, since they depend on the projects' tags.
+func (s *serviceImpl) invalidateSimilarProjects(ctx context.Context) {
+	// Matches the keys of similarProjectsRedisKey
+	err := s.Kv.DelMatching(ctx, "project:*:similar")
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Warn("Failed to delete cached similar projects")
+	}
+}
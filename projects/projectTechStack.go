@@ -0,0 +1,189 @@
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnknownTechnology = errors.New("unknown technology")
+
+// The part of a project's tech stack a technology belongs to.
+type TechCategory string
+
+const (
+	TechCategoryLanguage  TechCategory = "language"
+	TechCategoryFramework TechCategory = "framework"
+	TechCategoryPlatform  TechCategory = "platform"
+)
+
+// A technology of the skills taxonomy.
+type Technology struct {
+	// Lowercase identifier, stored in projects' tech stacks
+	Id       string       `json:"id"`
+	Name     string       `json:"name"`
+	Category TechCategory `json:"category"`
+
+	// Other names the technology is known by, e.g. "golang"
+	Aliases []string `json:"aliases"`
+}
+
+// The skills taxonomy: the technologies projects can declare in their tech
+// stack. Unlike tags, which are free text and describe what a project is
+// about, the taxonomy only has technologies, each under one id, so that
+// projects can be filtered and matched by what they're built with.
+var technologies = []Technology{
+	{"c", "C", TechCategoryLanguage, nil},
+	{"cpp", "C++", TechCategoryLanguage, []string{"c++"}},
+	{"csharp", "C#", TechCategoryLanguage, []string{"c#", "cs"}},
+	{"dart", "Dart", TechCategoryLanguage, nil},
+	{"elixir", "Elixir", TechCategoryLanguage, nil},
+	{"go", "Go", TechCategoryLanguage, []string{"golang"}},
+	{"haskell", "Haskell", TechCategoryLanguage, nil},
+	{"java", "Java", TechCategoryLanguage, nil},
+	{"javascript", "JavaScript", TechCategoryLanguage, []string{"js", "ecmascript"}},
+	{"kotlin", "Kotlin", TechCategoryLanguage, nil},
+	{"lua", "Lua", TechCategoryLanguage, nil},
+	{"php", "PHP", TechCategoryLanguage, nil},
+	{"python", "Python", TechCategoryLanguage, []string{"py"}},
+	{"ruby", "Ruby", TechCategoryLanguage, nil},
+	{"rust", "Rust", TechCategoryLanguage, nil},
+	{"scala", "Scala", TechCategoryLanguage, nil},
+	{"sql", "SQL", TechCategoryLanguage, nil},
+	{"swift", "Swift", TechCategoryLanguage, nil},
+	{"typescript", "TypeScript", TechCategoryLanguage, []string{"ts"}},
+	{"zig", "Zig", TechCategoryLanguage, nil},
+
+	{"angular", "Angular", TechCategoryFramework, nil},
+	{"aspnet", "ASP.NET", TechCategoryFramework, []string{"asp.net", "asp.net core"}},
+	{"django", "Django", TechCategoryFramework, nil},
+	{"express", "Express", TechCategoryFramework, []string{"expressjs", "express.js"}},
+	{"fastapi", "FastAPI", TechCategoryFramework, nil},
+	{"flask", "Flask", TechCategoryFramework, nil},
+	{"flutter", "Flutter", TechCategoryFramework, nil},
+	{"laravel", "Laravel", TechCategoryFramework, nil},
+	{"nextjs", "Next.js", TechCategoryFramework, []string{"next.js", "next"}},
+	{"phoenix", "Phoenix", TechCategoryFramework, nil},
+	{"pytorch", "PyTorch", TechCategoryFramework, nil},
+	{"rails", "Ruby on Rails", TechCategoryFramework, []string{"ruby on rails", "ror"}},
+	{"react", "React", TechCategoryFramework, []string{"reactjs", "react.js"}},
+	{"react-native", "React Native", TechCategoryFramework, []string{"react native"}},
+	{"spring", "Spring", TechCategoryFramework, []string{"spring boot"}},
+	{"svelte", "Svelte", TechCategoryFramework, []string{"sveltekit"}},
+	{"tensorflow", "TensorFlow", TechCategoryFramework, nil},
+	{"vue", "Vue", TechCategoryFramework, []string{"vuejs", "vue.js"}},
+
+	{"android", "Android", TechCategoryPlatform, nil},
+	{"aws", "AWS", TechCategoryPlatform, []string{"amazon web services"}},
+	{"azure", "Azure", TechCategoryPlatform, nil},
+	{"browser-extension", "Browser extension", TechCategoryPlatform, nil},
+	{"cli", "Command line", TechCategoryPlatform, []string{"command line", "terminal"}},
+	{"desktop", "Desktop", TechCategoryPlatform, nil},
+	{"docker", "Docker", TechCategoryPlatform, nil},
+	{"embedded", "Embedded", TechCategoryPlatform, []string{"iot"}},
+	{"gcp", "Google Cloud", TechCategoryPlatform, []string{"google cloud"}},
+	{"ios", "iOS", TechCategoryPlatform, nil},
+	{"kubernetes", "Kubernetes", TechCategoryPlatform, []string{"k8s"}},
+	{"linux", "Linux", TechCategoryPlatform, nil},
+	{"macos", "macOS", TechCategoryPlatform, nil},
+	{"web", "Web", TechCategoryPlatform, nil},
+	{"windows", "Windows", TechCategoryPlatform, nil},
+}
+
+// The technologies of the skills taxonomy, optionally only the ones of a
+// category, ordered by category and id.
+func Technologies(category TechCategory) []Technology {
+	var found []Technology
+	for _, technology := range technologies {
+		if category == "" || technology.Category == category {
+			found = append(found, technology)
+		}
+	}
+
+	return found
+}
+
+// Get the taxonomy ids of technologies of a category, by id, name or alias,
+// case insensitively. Duplicates are removed.
+// Returns ErrUnknownTechnology if a technology isn't in the category.
+func NormalizeTechnologies(category TechCategory, names []string) ([]string, error) {
+	ids := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		id, ok := findTechnology(category, name)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s %q", ErrUnknownTechnology, category, name)
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func findTechnology(category TechCategory, name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for _, technology := range technologies {
+		if technology.Category != category {
+			continue
+		}
+
+		if technology.Id == name || strings.ToLower(technology.Name) == name {
+			return technology.Id, true
+		}
+
+		for _, alias := range technology.Aliases {
+			if alias == name {
+				return technology.Id, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// All technologies of a project's tech stack.
+func projectStack(project *Project) []string {
+	stack := make([]string, 0, len(project.Languages)+len(project.Frameworks)+len(project.Platforms))
+	stack = append(stack, project.Languages...)
+	stack = append(stack, project.Frameworks...)
+	stack = append(stack, project.Platforms...)
+
+	return stack
+}
+
+// A project's tech stack as taxonomy ids.
+type stackIds struct {
+	Languages  []string
+	Frameworks []string
+	Platforms  []string
+}
+
+// Returns ErrUnknownTechnology if a technology of the project's stack isn't
+// in the skills taxonomy.
+func normalizeStack(dto NewProjectDto) (stackIds, error) {
+	var stack stackIds
+	var err error
+
+	stack.Languages, err = NormalizeTechnologies(TechCategoryLanguage, dto.Languages)
+	if err != nil {
+		return stackIds{}, err
+	}
+
+	stack.Frameworks, err = NormalizeTechnologies(TechCategoryFramework, dto.Frameworks)
+	if err != nil {
+		return stackIds{}, err
+	}
+
+	stack.Platforms, err = NormalizeTechnologies(TechCategoryPlatform, dto.Platforms)
+	if err != nil {
+		return stackIds{}, err
+	}
+
+	return stack, nil
+}
@@ -0,0 +1,119 @@
+package projects
+
+import (
+	"context"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/users"
+	"time"
+)
+
+// The working day overlap is computed over, in local time: people are
+// assumed to collaborate between 9:00 and 18:00 in their time zone.
+const workdayStartHour = 9
+const workdayEndHour = 18
+
+// Get the IANA names of a project's time zones, without duplicates.
+// Returns users.ErrInvalidTimezone if a time zone isn't in the IANA database.
+func normalizeTimeZones(timeZones []string) ([]string, error) {
+	normalized := make([]string, 0, len(timeZones))
+	seen := map[string]bool{}
+
+	for _, timeZone := range timeZones {
+		// LoadLocation also accepts "Local", the server's time zone
+		location, err := time.LoadLocation(timeZone)
+		if err != nil || timeZone == "Local" || timeZone == "" {
+			return nil, users.ErrInvalidTimezone
+		}
+
+		if !seen[location.String()] {
+			seen[location.String()] = true
+			normalized = append(normalized, location.String())
+		}
+	}
+
+	return normalized, nil
+}
+
+// The hours of the working day at a time shared by someone in the time zone
+// and a project, the most shared with any of the project's time zones.
+// Invalid time zones are treated as UTC. Time zones with half or quarter
+// hour offsets have fractional overlaps, e.g. 3.5.
+func OverlapHours(projectTimeZones []string, timeZone string, at time.Time) float64 {
+	start, end := workday(timeZone, at)
+
+	overlap := time.Duration(0)
+	for _, projectTimeZone := range projectTimeZones {
+		// The project's workday can be on the day before or after in its time
+		// zone, e.g. for UTC+12 and UTC-10
+		for days := -1; days <= 1; days++ {
+			projectStart, projectEnd := workday(projectTimeZone, at.AddDate(0, 0, days))
+
+			shared := earliest(end, projectEnd).Sub(latest(start, projectStart))
+			if shared > overlap {
+				overlap = shared
+			}
+		}
+	}
+
+	return overlap.Hours()
+}
+
+// When the working day on the day of at starts and ends in the time zone.
+func workday(timeZone string, at time.Time) (time.Time, time.Time) {
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	local := at.In(location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), workdayStartHour, 0, 0, 0, location)
+	end := time.Date(local.Year(), local.Month(), local.Day(), workdayEndHour, 0, 0, 0, location)
+
+	return start, end
+}
+
+func earliest(a time.Time, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func (s *serviceImpl) GetOverlapHours(ctx context.Context, userId uint, projectIds []uint) (map[uint]float64, error) {
+	overlaps := map[uint]float64{}
+	if len(projectIds) < 1 {
+		return overlaps, nil
+	}
+
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	user, err := s.UsersService.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	timeZones, err := s.Repository.GetTimeZones(ctx, projectIds)
+	if err != nil {
+		logger.WithError(err).Error("Failed to get projects' time zones")
+
+		return nil, err
+	}
+
+	now := time.Now()
+	for projectId, projectTimeZones := range timeZones {
+		if len(projectTimeZones) > 0 {
+			overlaps[projectId] = OverlapHours(projectTimeZones, user.Timezone, now)
+		}
+	}
+
+	return overlaps, nil
+}
@@ -1,17 +1,44 @@
 package projects
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/apex/log"
 	"github.com/go-playground/validator/v10"
-	"github.com/lib/pq"
-	"gorm.io/gorm"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/metrics"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/users"
+	"math"
+	"sort"
+	"time"
 )
 
 type Service interface {
-	CreateProject(newProject NewProjectDto) (*Project, error)
-	UpdateProject(projectId uint, projectData NewProjectDto) error
+	// Create a project owned by the given user. If pendingReview is true the project
+	// is hidden from everyone but its owner until a moderator approves it.
+	// Spoken languages are detected from the project's descriptions if it
+	// doesn't declare any.
+	// Returns ErrUnknownLicense if the project's license isn't in the catalog,
+	// ErrUnknownSpokenLanguage if a spoken language isn't in the catalog,
+	// users.ErrInvalidTimezone if a time zone isn't in the IANA database,
+	// ErrInvalidFundingLink if a funding link doesn't point to its platform,
+	// ErrInvalidExternalLink if an external link isn't a web link and
+	// validation.Errors if the project breaks the service's Rules for its
+	// owner.
+	CreateProject(ctx context.Context, ownerId uint, newProject NewProjectDto, pendingReview bool) (*Project, error)
+
+	// Roles and screening questions with an id keep it, the project's roles
+	// and questions that aren't given are deleted. Funding links whose url
+	// didn't change keep their click counters.
+	// Returns ErrUnknownLicense, ErrUnknownSpokenLanguage,
+	// users.ErrInvalidTimezone, ErrInvalidFundingLink, ErrInvalidExternalLink
+	// and validation.Errors like CreateProject, ErrProjectNotFound, and
+	// ErrRoleNotFound or ErrQuestionNotFound if an id isn't the project's.
+	UpdateProject(ctx context.Context, projectId uint, projectData NewProjectDto) error
 
 	// Get the given project's summary
 	GetProjectSummary(project *Project) ProjectSummaryDto
@@ -20,7 +47,14 @@ type Service interface {
 	// Returns ErrProjectNotFound if the project can't be found.
 	GetProject(ctx context.Context, projectId uint) (ProjectDto, error)
 
-	// List all projects ordered by creation date, newest to oldest.
+	// Get a project's quality score and hints on how to improve it.
+	// Returns ErrProjectNotFound if the project can't be found.
+	GetProjectQuality(ctx context.Context, projectId uint) (QualityDto, error)
+
+	// List all projects ordered by creation date, newest to oldest, or by
+	// quality score and then creation date if order is OrderByQuality, or by
+	// last update if order is OrderByRecentlyUpdated.
+	// Projects pending review and drafts are not listed.
 	//
 	// Results are returned in "pages". A page is determined by the pageSize and
 	// pageOffset parameters. pageSize determines the maximum amount of projects
@@ -28,88 +62,385 @@ type Service interface {
 	// to skip. For example: if pageSize is 20 and pageOffset is 3, a maximum of 20
 	// projects will be returned and 60 (3x20) projects will be skipped.
 	//
-	// You can also filter the results by tags and skills. If tags is specified
-	// (non-nil and non-empty), any projects that have at least one of the specified
-	// tags will be returned. If skills is specified (non-nil and non-empty), any projects
-	// that have at least one role that require at least one of the specified skills will
-	// be returned.
+	// The results can also be filtered, see ProjectFilters.
+	//
+	// Also returns the total amount of projects matching the filters, regardless
+	// of pagination.
+	// Returns ErrUnknownLicense if a license isn't in the catalog,
+	// ErrUnknownTechnology if a technology isn't in the skills taxonomy,
+	// ErrUnknownStatus if a status doesn't exist, ErrUnknownSeniority if a
+	// seniority doesn't exist and ErrUnknownSpokenLanguage if a spoken language
+	// isn't in the catalog.
 	ListProjects(
 		ctx context.Context,
 		pageSize uint,
 		pageOffset uint,
-		tags []string,
-		skills []string,
-	) ([]ProjectSummaryDto, error)
+		filters ProjectFilters,
+		order ProjectOrder,
+	) ([]ProjectSummaryDto, int64, error)
+
+	// Get the summaries of the projects with the given ids, in the same order.
+	// Projects that don't exist, are pending review or are drafts are left out.
+	ListProjectsById(ctx context.Context, projectIds []uint) ([]ProjectSummaryDto, error)
+
+	// List projects pending review, oldest to newest. Also returns the total
+	// amount of projects pending review.
+	ListPendingProjects(ctx context.Context, pageSize uint, pageOffset uint) ([]ProjectSummaryDto, int64, error)
+
+	// Get a random sample of at most count active projects (updated in the last
+	// 90 days), weighted by quality score so that better projects show up more
+	// often. Projects owned by excludeOwnerId are left out, pass 0 to not
+	// exclude any.
+	DiscoverProjects(ctx context.Context, count uint, excludeOwnerId uint) ([]ProjectSummaryDto, error)
+
+	// Get the projects most similar to a project, based on overlapping tags,
+	// overlapping role skills and description similarity. Results are cached
+	// for an hour.
+	// Returns ErrProjectNotFound if the project can't be found, is pending review
+	// or is a draft.
+	SimilarProjects(ctx context.Context, projectId uint) ([]ProjectSummaryDto, error)
+
+	// Replace the source tags with the target tag in all projects, in a single
+	// transaction. A project that had more than one of the tags ends up with
+	// the target tag once. Returns the amount of affected projects.
+	MergeTags(ctx context.Context, sources []string, target string) (int64, error)
+
+	// Ban a tag, removing it from all projects. Projects can't be created or
+	// updated with banned tags (see ErrTagBanned).
+	BanTag(ctx context.Context, tag string, reason string) error
+
+	// Unban a tag. Returns ErrTagNotBanned if the tag isn't banned.
+	UnbanTag(ctx context.Context, tag string) error
+
+	// List the banned tags, alphabetically.
+	ListBannedTags(ctx context.Context) ([]BannedTagDto, error)
+
+	// Approve a project pending review, making it visible to everyone.
+	// Returns ErrProjectNotFound if the project can't be found.
+	ApproveProject(ctx context.Context, projectId uint) error
+
+	// Move a project to another status of its lifecycle, recording the change
+	// in the project's status history and notifying the project's owner and
+	// members, except the user who made the change.
+	// Returns ErrProjectNotFound if the project can't be found, ErrUnknownStatus
+	// if the status doesn't exist and ErrInvalidStatusTransition if the project
+	// can't move from its current status to the new one (see StatusTransitions).
+	ChangeStatus(ctx context.Context, userId uint, projectId uint, status ProjectStatus) error
+
+	// List a project's status changes, newest to oldest.
+	ListStatusChanges(ctx context.Context, projectId uint) ([]StatusChangeDto, error)
+
+	// Add and remove tags of a project in a single transaction, without
+	// resubmitting the whole project. Tags are normalized and deduplicated
+	// (see BulkEditDto). Returns the project's new tags.
+	// Returns ErrProjectNotFound if the project can't be found, ErrTagBanned if
+	// an added tag is banned and ErrTagCount if the project would end up with
+	// too few or too many tags.
+	EditTags(ctx context.Context, projectId uint, dto BulkEditDto) ([]string, error)
+
+	// Add and remove skills of a project's role in a single transaction, like
+	// EditTags. Returns the role's new skills.
+	// Returns ErrRoleNotFound if the project doesn't have the role and
+	// ErrSkillCount if the role would end up with too many skills.
+	EditRoleSkills(ctx context.Context, projectId uint, roleId uint, dto BulkEditDto) ([]string, error)
+
+	// Create a draft project owned by the given user with the structure of
+	// another project: its tags, descriptions, tech stack and roles, including
+	// their screening questions. The clone starts as an idea, its repository
+	// and cover image are left empty. If pendingReview is true the clone also
+	// needs a moderator's approval, see CreateProject.
+	// Returns ErrProjectNotFound if the project can't be found.
+	CloneProject(ctx context.Context, ownerId uint, projectId uint, pendingReview bool) (*Project, error)
+
+	// Create a draft project owned by the given user from the details of
+	// where it's hosted. Only what fits the project's rules is kept: topics
+	// become tags, the license and languages are kept if they're in the
+	// catalog and the taxonomy. The draft starts as active, to be completed
+	// by its owner before it's published. If pendingReview is true the draft
+	// also needs a moderator's approval, see CreateProject.
+	// Returns validation.Errors if the name has banned words.
+	ImportProject(ctx context.Context, ownerId uint, imported ImportedProjectDto, pendingReview bool) (*Project, error)
+
+	// Publish a draft project, making it visible to everyone (unless it's
+	// pending review). Publishing a project that isn't a draft does nothing.
+	// Returns ErrProjectNotFound if the project can't be found.
+	PublishProject(ctx context.Context, projectId uint) error
+
+	// Count a click on one of a project's funding links. Clicks from the same
+	// IP address are only counted once an hour, pass an empty clientIp to
+	// always count the click.
+	// Returns ErrFundingLinkNotFound if the project doesn't have the link.
+	RecordFundingClick(ctx context.Context, projectId uint, fundingLinkId uint, clientIp string) error
+
+	// Turn on or off serving the README of the project's repository as its
+	// long description. Turning it off forgets the imported README and serves
+	// the written long description again.
+	// Returns ErrProjectNotFound if the project can't be found.
+	SetReadmeSync(ctx context.Context, projectId uint, enabled bool) error
+
+	// Save a project's README, imported and sanitized by the readme package.
+	// Returns ErrProjectNotFound if the project can't be found.
+	SaveReadme(ctx context.Context, projectId uint, readme string) error
+
+	// Get the hours of the working day the user shares with each project, see
+	// OverlapHours. Projects that didn't declare time zones are left out.
+	// Returns users.ErrUserNotFound if the user can't be found.
+	GetOverlapHours(ctx context.Context, userId uint, projectIds []uint) (map[uint]float64, error)
+
+	// List a project's owner and accepted members, the owner first and the
+	// members by username. Members who hide their memberships are left out,
+	// unless the viewer is the member.
+	ListMembers(ctx context.Context, viewerId uint, projectId uint) ([]MemberDto, error)
+
+	// Whether the user is the project's owner or one of its accepted members.
+	IsMember(ctx context.Context, projectId uint, userId uint) (bool, error)
+
+	// Get what the user can do in the project: every permission for its
+	// owner, the granted ones for its members and none for anyone else.
+	// Permissions are cached, see permissionsCacheDuration.
+	// Returns ErrProjectNotFound if the project can't be found.
+	GetPermissions(ctx context.Context, projectId uint, userId uint) ([]Permission, error)
+
+	// Whether the user has the permission in the project, see GetPermissions.
+	// Returns ErrProjectNotFound if the project can't be found.
+	HasPermission(ctx context.Context, projectId uint, userId uint, permission Permission) (bool, error)
+
+	// Replace the permissions of one of the project's members. Returns the
+	// member's permissions.
+	// Returns ErrNotMember if the user isn't a member of the project and
+	// ErrOwnerPermissions if they're its owner.
+	SetPermissions(ctx context.Context, grantedBy uint, projectId uint, userId uint, dto MemberPermissionsDto) ([]Permission, error)
+}
+
+// Filters of project listings. Each filter is only applied if it's non-nil
+// and non-empty.
+type ProjectFilters struct {
+	// Projects that have at least one of the tags
+	Tags []string
+
+	// Projects that have at least one role that requires one of the skills.
+	// This and the other role filters must all match the same role, so that
+	// e.g. "beginner roles of at most 5 hours per week" can be listed.
+	Skills []string
+
+	// Projects that have a role with one of the seniorities
+	Seniorities []string
+
+	// Projects that have a role expecting at most this many hours per week.
+	// Roles that don't say how many hours they expect don't match.
+	MaxWeeklyHours uint
+
+	// Projects that have a role with mentorship available
+	Mentorship bool
+
+	// Projects with one of the licenses
+	Licenses []string
+
+	// Projects whose tech stack has at least one of the languages, at least
+	// one of the frameworks and at least one of the platforms
+	Languages  []string
+	Frameworks []string
+	Platforms  []string
+
+	// Projects with one of the statuses
+	Statuses []string
+
+	// Projects communicating in at least one of the spoken languages
+	SpokenLanguages []string
+
+	// Projects in the collection, 0 for any (see the collections package)
+	Collection uint
 }
 
-func NewService(db *gorm.DB) Service {
-	return &serviceImpl{Db: db}
+// How projects are ordered in listings.
+type ProjectOrder string
+
+const (
+	OrderByNewest          ProjectOrder = "newest"
+	OrderByQuality         ProjectOrder = "quality"
+	OrderByRecentlyUpdated ProjectOrder = "updated"
+)
+
+// Projects not updated within this window are not discovered.
+const discoveryActivityWindow = 90 * 24 * time.Hour
+
+// How many projects are sampled per project returned by DiscoverProjects.
+// Sampled projects are filtered and then weighted, so the sample must be
+// bigger than the amount of projects returned.
+const discoverySampleFactor = 10
+
+// How many similar projects are returned by SimilarProjects.
+const similarProjectsCount = 6
+
+// How long a project's similar projects are cached.
+const similarProjectsCacheDuration = time.Hour
+
+// A ProjectListener is notified after a project is created or updated, with
+// the project as it is stored (roles included). Listeners can't fail the save,
+// so anything slow or fallible should be done in the background.
+type ProjectListener interface {
+	ProjectSaved(ctx context.Context, project *Project)
+}
+
+func NewService(
+	repository Repository,
+	kvStore kv.Store,
+	rules Rules,
+	usersService users.Service,
+	descriptionSimilarity DescriptionSimilarity,
+	notificationsService notifications.Service,
+	listeners ...ProjectListener,
+) Service {
+	return &serviceImpl{
+		Repository:            repository,
+		Kv:                    kvStore,
+		Rules:                 rules,
+		UsersService:          usersService,
+		DescriptionSimilarity: descriptionSimilarity,
+		NotificationsService:  notificationsService,
+		Listeners:             listeners,
+	}
 }
 
 type serviceImpl struct {
-	Db *gorm.DB
+	Repository            Repository
+	Kv                    kv.Store
+	Rules                 Rules
+	UsersService          users.Service
+	DescriptionSimilarity DescriptionSimilarity
+	NotificationsService  notifications.Service
+	Listeners             []ProjectListener
 }
 
 var ErrProjectNotFound = errors.New("project not found")
+var ErrQuestionNotFound = errors.New("question not found")
+var createdCounter = metrics.NewCounter(
+	"opencollab_projects_created_total",
+	"Projects created, including clones.",
+)
+
+var ErrTagBanned = errors.New("tag is banned")
+var ErrTagNotBanned = errors.New("tag is not banned")
 
-func (s *serviceImpl) CreateProject(newProject NewProjectDto) (*Project, error) {
+func (s *serviceImpl) CreateProject(ctx context.Context, ownerId uint, newProject NewProjectDto, pendingReview bool) (*Project, error) {
 	err := validator.New().Struct(newProject)
 	if err != nil {
 		return nil, err
 	}
 
-	project := Project{
-		Name:             newProject.Name,
-		Tags:             newProject.Tags,
-		LongDescription:  newProject.LongDescription,
-		ShortDescription: newProject.ShortDescription,
-		GithubLink:       newProject.GithubLink,
+	err = s.checkRules(ctx, ownerId, newProject)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkBannedTags(ctx, newProject.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := newProjectToModel(newProject)
+	if err != nil {
+		return nil, err
 	}
 
-	result := s.Db.Create(&project)
-	if result.Error != nil {
-		return nil, result.Error
+	clearRoleAndQuestionIds(&project)
+
+	project.OwnerId = ownerId
+	project.PendingReview = pendingReview
+	project.Status = newProject.Status
+	if project.Status == "" {
+		project.Status = StatusIdea
+	}
+
+	project.QualityScore = storedQualityScore(&project)
+
+	err = s.Repository.CreateProject(ctx, &project)
+	if err != nil {
+		return nil, err
+	}
+
+	createdCounter.Inc()
+
+	for _, listener := range s.Listeners {
+		listener.ProjectSaved(ctx, &project)
 	}
 
 	return &project, nil
 }
 
-func (s *serviceImpl) UpdateProject(projectId uint, projectData NewProjectDto) error {
+func (s *serviceImpl) UpdateProject(ctx context.Context, projectId uint, projectData NewProjectDto) error {
 	err := validator.New().Struct(projectData)
 	if err != nil {
 		return err
 	}
 
-	project := Project{
-		Model: gorm.Model{
-			ID: projectId,
-		},
-		Name:             projectData.Name,
-		Tags:             projectData.Tags,
-		LongDescription:  projectData.LongDescription,
-		ShortDescription: projectData.ShortDescription,
-		GithubLink:       projectData.GithubLink,
+	// The rules depend on the project's owner
+	saved, err := s.Repository.GetProject(ctx, projectId)
+	if err != nil {
+		return err
 	}
 
-	result := s.Db.Save(&project)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return ErrProjectNotFound
-		} else {
-			return result.Error
-		}
+	err = s.checkRules(ctx, saved.OwnerId, projectData)
+	if err != nil {
+		return err
 	}
 
+	err = s.checkBannedTags(ctx, projectData.Tags)
+	if err != nil {
+		return err
+	}
+
+	project, err := newProjectToModel(projectData)
+	if err != nil {
+		return err
+	}
+
+	project.QualityScore = storedQualityScore(&project)
+
+	err = s.Repository.UpdateProject(ctx, projectId, &project)
+	if err != nil {
+		return err
+	}
+
+	s.notifyListeners(ctx, projectId)
+
 	return nil
 }
 
-func (s *serviceImpl) GetProjectSummary(project *Project) ProjectSummaryDto {
-	return ProjectSummaryDto{
-		Id:               project.ID,
-		Name:             project.Name,
-		Tags:             project.Tags,
-		ShortDescription: project.ShortDescription,
+// Check a project against the rules of its owner's plan.
+func (s *serviceImpl) checkRules(ctx context.Context, ownerId uint, project NewProjectDto) error {
+	owner, err := s.UsersService.GetUser(ctx, ownerId)
+	if err != nil {
+		return err
 	}
+
+	return s.Rules.Check(project, owner.Role)
+}
+
+// Load a saved project and notify the listeners about it. Listeners get the
+// whole project, not only the fields that were changed.
+func (s *serviceImpl) notifyListeners(ctx context.Context, projectId uint) {
+	if len(s.Listeners) < 1 {
+		return
+	}
+
+	project, err := s.Repository.GetProject(ctx, projectId)
+	if err != nil {
+		log.FromContext(ctx).
+			WithError(err).
+			WithField("projectId", projectId).
+			Error("Failed to load saved project, listeners won't be notified")
+
+		return
+	}
+
+	for _, listener := range s.Listeners {
+		listener.ProjectSaved(ctx, project)
+	}
+}
+
+func (s *serviceImpl) GetProjectSummary(project *Project) ProjectSummaryDto {
+	return projectToSummaryDto(project)
 }
 
 func (s *serviceImpl) GetProject(ctx context.Context, projectId uint) (ProjectDto, error) {
@@ -117,73 +448,334 @@ func (s *serviceImpl) GetProject(ctx context.Context, projectId uint) (ProjectDt
 
 	logger.Debugf("Querying for project of id %d", projectId)
 
-	project := Project{}
-	result := s.Db.First(&project, projectId)
-
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	project, err := s.Repository.GetProjectDetails(ctx, projectId)
+	if err != nil {
+		if errors.Is(err, ErrProjectNotFound) {
 			logger.Debugf("Project of id %d was not found", projectId)
-			return ProjectDto{}, ErrProjectNotFound
 		} else {
-			logger.WithError(result.Error).Errorf("Failed to query for project of id %d", projectId)
-			return ProjectDto{}, result.Error
+			logger.WithError(err).Errorf("Failed to query for project of id %d", projectId)
 		}
+
+		return ProjectDto{}, err
 	}
 
 	logger.Debugf("Project of id %d was found", projectId)
 
-	return ProjectDto{
-		Id:               project.ID,
-		Name:             project.Name,
-		Tags:             project.Tags,
-		ShortDescription: project.ShortDescription,
-		LongDescription:  project.LongDescription,
-		GithubLink:       project.GithubLink,
-	}, nil
+	return projectToDto(project), nil
 }
 
 func (s *serviceImpl) ListProjects(
 	ctx context.Context,
 	pageSize uint,
 	pageOffset uint,
-	tags []string,
-	skills []string,
-) ([]ProjectSummaryDto, error) {
+	filters ProjectFilters,
+	order ProjectOrder,
+) ([]ProjectSummaryDto, int64, error) {
 	logger := log.FromContext(ctx)
 
 	logger.WithFields(log.Fields{
 		"page_size":   pageSize,
 		"page_offset": pageOffset,
-		"tags":        tags,
-		"skills":      skills,
+		"filters":     filters,
+		"order":       order,
 	}).
 		Debug("Listing projects")
 
-	if tags == nil {
-		tags = []string{}
+	filters, err := normalizeFilters(filters)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if skills == nil {
-		skills = []string{}
+	projectSummaries, totalCount, err := s.Repository.ListProjects(ctx, filters, order, pageSize, pageOffset)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list projects")
+
+		return nil, 0, err
 	}
 
-	projectSummaries := make([]ProjectSummaryDto, pageSize)
-	result := s.Db.
-		Model(&Project{}).
-		Select("name", "tags", "short_description", "id").
-		Where("cardinality(?::TEXT[]) < 1 OR tags && ?", pq.StringArray(tags), pq.StringArray(tags)).
-		Order("created_at desc").
-		Limit(int(pageSize)).
-		Offset(int(pageOffset * pageSize)).
-		Find(&projectSummaries)
+	logger.Debugf("Found %d projects out of %d", len(projectSummaries), totalCount)
+
+	return projectSummaries, totalCount, nil
+}
+
+// Normalize the values of filters that are checked against a catalog.
+// Returns ErrUnknownSeniority, ErrUnknownLicense, ErrUnknownStatus,
+// ErrUnknownTechnology or ErrUnknownSpokenLanguage if a value doesn't exist.
+func normalizeFilters(filters ProjectFilters) (ProjectFilters, error) {
+	var err error
+
+	if len(filters.Seniorities) > 0 {
+		filters.Seniorities, err = NormalizeSeniorities(filters.Seniorities)
+		if err != nil {
+			return ProjectFilters{}, err
+		}
+	}
 
-	if result.Error != nil {
-		logger.WithError(result.Error).Error("Failed to list projects")
+	if len(filters.Licenses) > 0 {
+		filters.Licenses, err = NormalizeLicenses(filters.Licenses)
+		if err != nil {
+			return ProjectFilters{}, err
+		}
+	}
 
-		return nil, result.Error
+	if len(filters.Statuses) > 0 {
+		filters.Statuses, err = NormalizeStatuses(filters.Statuses)
+		if err != nil {
+			return ProjectFilters{}, err
+		}
 	}
 
-	logger.Debugf("Found %d projects", result.RowsAffected)
+	if len(filters.SpokenLanguages) > 0 {
+		filters.SpokenLanguages, err = NormalizeSpokenLanguages(filters.SpokenLanguages)
+		if err != nil {
+			return ProjectFilters{}, err
+		}
+	}
+
+	stackFilters := []struct {
+		category TechCategory
+		values   *[]string
+	}{
+		{TechCategoryLanguage, &filters.Languages},
+		{TechCategoryFramework, &filters.Frameworks},
+		{TechCategoryPlatform, &filters.Platforms},
+	}
+
+	for _, filter := range stackFilters {
+		if len(*filter.values) < 1 {
+			continue
+		}
+
+		*filter.values, err = NormalizeTechnologies(filter.category, *filter.values)
+		if err != nil {
+			return ProjectFilters{}, err
+		}
+	}
+
+	return filters, nil
+}
+
+func (s *serviceImpl) ListProjectsById(ctx context.Context, projectIds []uint) ([]ProjectSummaryDto, error) {
+	if len(projectIds) < 1 {
+		return []ProjectSummaryDto{}, nil
+	}
+
+	found, err := s.Repository.ListProjectSummaries(ctx, projectIds)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to list projects by id")
+
+		return nil, err
+	}
+
+	foundById := make(map[uint]ProjectSummaryDto, len(found))
+	for _, projectSummary := range found {
+		foundById[projectSummary.Id] = projectSummary
+	}
+
+	projectSummaries := make([]ProjectSummaryDto, 0, len(found))
+	for _, projectId := range projectIds {
+		if projectSummary, ok := foundById[projectId]; ok {
+			projectSummaries = append(projectSummaries, projectSummary)
+		}
+	}
+
+	return projectSummaries, nil
+}
+
+func (s *serviceImpl) ListPendingProjects(ctx context.Context, pageSize uint, pageOffset uint) ([]ProjectSummaryDto, int64, error) {
+	logger := log.FromContext(ctx)
+
+	projectSummaries, totalCount, err := s.Repository.ListPendingProjects(ctx, pageSize, pageOffset)
+	if err != nil {
+		logger.WithError(err).Error("Failed to list projects pending review")
+
+		return nil, 0, err
+	}
+
+	return projectSummaries, totalCount, nil
+}
+
+func (s *serviceImpl) ApproveProject(ctx context.Context, projectId uint) error {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	err := s.Repository.SetPendingReview(ctx, projectId, false)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to approve project")
+		}
+
+		return err
+	}
+
+	logger.Info("Project approved")
+
+	s.notifyListeners(ctx, projectId)
+
+	return nil
+}
+
+func (s *serviceImpl) GetProjectQuality(ctx context.Context, projectId uint) (QualityDto, error) {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	project, err := s.Repository.GetProject(ctx, projectId)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to query for project")
+		}
+
+		return QualityDto{}, err
+	}
+
+	return computeQuality(project), nil
+}
+
+func (s *serviceImpl) DiscoverProjects(ctx context.Context, count uint, excludeOwnerId uint) ([]ProjectSummaryDto, error) {
+	logger := log.FromContext(ctx).WithField("count", count)
+
+	// ORDER BY random() would read and sort the whole table, so only a
+	// percentage of the table's pages is sampled with TABLESAMPLE. The
+	// percentage is computed from postgres' estimate of the table's size,
+	// which is cheap to get.
+	estimatedRows, err := s.Repository.EstimateProjectCount(ctx)
+	if err != nil {
+		logger.WithError(err).Error("Failed to estimate the amount of projects")
+
+		return nil, err
+	}
+
+	percentage := 100.0
+	if estimatedRows > 0 {
+		percentage = math.Min(100, float64(count*discoverySampleFactor)*100/estimatedRows)
+	}
+
+	projectSummaries, err := s.sampleProjects(ctx, count, excludeOwnerId, percentage)
+	if err != nil {
+		logger.WithError(err).Error("Failed to sample projects")
+
+		return nil, err
+	}
+
+	// The sample may come up short if it happened to contain mostly inactive
+	// projects or the size estimate is off, in which case the whole table is
+	// sampled instead.
+	if uint(len(projectSummaries)) < count && percentage < 100 {
+		projectSummaries, err = s.sampleProjects(ctx, count, excludeOwnerId, 100)
+		if err != nil {
+			logger.WithError(err).Error("Failed to sample projects")
+
+			return nil, err
+		}
+	}
+
+	return projectSummaries, nil
+}
+
+// Get at most count active projects from a sample of percentage% of the
+// projects table, see Repository.SampleProjects.
+func (s *serviceImpl) sampleProjects(ctx context.Context, count uint, excludeOwnerId uint, percentage float64) ([]ProjectSummaryDto, error) {
+	return s.Repository.SampleProjects(ctx, count, excludeOwnerId, time.Now().Add(-discoveryActivityWindow), percentage)
+}
+
+func (s *serviceImpl) SimilarProjects(ctx context.Context, projectId uint) ([]ProjectSummaryDto, error) {
+	logger := log.FromContext(ctx).WithField("projectId", projectId)
+
+	var projectSummaries []ProjectSummaryDto
+
+	cached, err := s.Kv.Get(ctx, similarProjectsRedisKey(projectId))
+	if err == nil {
+		err = json.Unmarshal([]byte(cached), &projectSummaries)
+		if err == nil {
+			return projectSummaries, nil
+		}
+
+		logger.WithError(err).Warn("Failed to unmarshal cached similar projects")
+	} else if !errors.Is(err, kv.ErrNotFound) {
+		logger.WithError(err).Warn("Failed to get cached similar projects, falling back to the database")
+	}
+
+	project, err := s.Repository.GetPublishedProject(ctx, projectId)
+	if err != nil {
+		if !errors.Is(err, ErrProjectNotFound) {
+			logger.WithError(err).Error("Failed to query for project")
+		}
+
+		return nil, err
+	}
+
+	skills := roleSkills(project.Roles)
+
+	// Candidates are the projects sharing tags or skills with the project and the
+	// projects with the most similar descriptions.
+	var candidates []Project
+	if len(project.Tags) > 0 || len(skills) > 0 {
+		candidates, err = s.Repository.ListProjectsSharingLabels(ctx, project.ID, project.Tags, skills, similarCandidatesLimit)
+		if err != nil {
+			logger.WithError(err).Error("Failed to query for similar projects")
+
+			return nil, err
+		}
+	}
+
+	descriptionSimilarities, err := s.DescriptionSimilarity.FindSimilarDescriptions(ctx, project, similarCandidatesLimit)
+	if err != nil {
+		// Tags and skills are still a decent signal on their own
+		logger.WithError(err).Warn("Failed to find projects with similar descriptions")
+
+		descriptionSimilarities = map[uint]float64{}
+	}
+
+	// Load the projects with similar descriptions that aren't candidates yet
+	candidateIds := make(map[uint]bool, len(candidates))
+	for _, candidate := range candidates {
+		candidateIds[candidate.ID] = true
+	}
+
+	var missingIds []uint
+	for id := range descriptionSimilarities {
+		if !candidateIds[id] {
+			missingIds = append(missingIds, id)
+		}
+	}
+
+	if len(missingIds) > 0 {
+		missing, err := s.Repository.ListProjectsWithRoles(ctx, missingIds)
+		if err != nil {
+			logger.WithError(err).Error("Failed to query for similar projects")
+
+			return nil, err
+		}
+
+		candidates = append(candidates, missing...)
+	}
+
+	scores := make(map[uint]float64, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate.ID] = similarTagsWeight*jaccardIndex(project.Tags, candidate.Tags) +
+			similarSkillsWeight*jaccardIndex(skills, roleSkills(candidate.Roles)) +
+			similarStackWeight*jaccardIndex(projectStack(project), projectStack(&candidate)) +
+			similarDescriptionWeight*descriptionSimilarities[candidate.ID]
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return scores[candidates[i].ID] > scores[candidates[j].ID]
+	})
+
+	if len(candidates) > similarProjectsCount {
+		candidates = candidates[:similarProjectsCount]
+	}
+
+	projectSummaries = make([]ProjectSummaryDto, len(candidates))
+	for i := range candidates {
+		projectSummaries[i] = s.GetProjectSummary(&candidates[i])
+	}
+
+	encoded, err := json.Marshal(projectSummaries)
+	if err == nil {
+		err = s.Kv.Set(ctx, similarProjectsRedisKey(projectId), string(encoded), similarProjectsCacheDuration)
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Failed to cache similar projects")
+	}
 
-	return projectSummaries[:result.RowsAffected], nil
+	return projectSummaries, nil
 }
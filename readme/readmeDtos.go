@@ -0,0 +1,16 @@
+package readme
+
+import "time"
+
+type SetSyncDto struct {
+	// Whether to serve the repository's README as the project's long
+	// description
+	Sync bool `json:"sync"`
+}
+
+type ReadmeDto struct {
+	Sync bool `json:"sync"`
+
+	// When the README was last imported, null if it isn't synced
+	SyncedAt *time.Time `json:"syncedAt"`
+}
@@ -0,0 +1,78 @@
+package readme
+
+import (
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Sync a project's description with its README
+// @Description Turn on or off serving the README of the project's GitHub repository as its full description.
+// @Description Turning it on imports the README right away, and it's refreshed periodically afterwards. The README
+// @Description is sanitized: HTML is removed, relative links point to the repository and it's cut to 20000
+// @Description characters. The written description is kept and served again once sync is turned off. Only the
+// @Description project's owner and members with the edit-project permission can change it.
+// @Tags projects
+// @Router /projects/{projectId}/readme [put]
+// @Param projectId path int true "The project ID"
+// @Param sync body readme.SetSyncDto true "Whether to sync the README"
+// @Success 200 {object} readme.ReadmeDto
+// @Failure 400 "The project's link isn't a GitHub repository, or the repository doesn't have a README"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+func RouteSetSync(
+	writer http.ResponseWriter,
+	request *http.Request,
+	readmeService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	dto := SetSyncDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	readme, err := readmeService.SetSync(request.Context(), projectId, dto.Sync)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, readme)
+}
+
+// @Summary Import a project's README again
+// @Description Import the README of the project's GitHub repository now instead of waiting for the periodic
+// @Description refresh. Only the project's owner and members with the edit-project permission can refresh it.
+// @Tags projects
+// @Router /projects/{projectId}/readme/refresh [post]
+// @Param projectId path int true "The project ID"
+// @Success 200 {object} readme.ReadmeDto
+// @Failure 400 "The project's link isn't a GitHub repository, or the repository doesn't have a README"
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409 "The project doesn't sync its README"
+func RouteRefresh(
+	writer http.ResponseWriter,
+	request *http.Request,
+	readmeService Service,
+	projectsService projects.Service,
+) error {
+	projectId, err := projects.CheckPermission(request, projectsService, projects.PermissionEditProject)
+	if err != nil {
+		return err
+	}
+
+	readme, err := readmeService.Refresh(request.Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, readme)
+}
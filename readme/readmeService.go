@@ -0,0 +1,218 @@
+package readme
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/contributions"
+	"github.com/open-collaboration/server/projects"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrSyncDisabled = errors.New("the project doesn't sync its README")
+
+// How often Run looks for READMEs to refresh.
+const refreshCheckInterval = 15 * time.Minute
+
+// At most this many READMEs are refreshed per check, so that refreshing
+// doesn't exhaust the GitHub API's rate limit.
+const refreshBatchSize = 30
+
+type Service interface {
+	// Turn on or off serving the README of the project's GitHub repository as
+	// its long description. Turning it on imports the README right away, and
+	// leaves it off if the README can't be imported.
+	// Returns ErrProjectNotFound if the project can't be found, and when
+	// turning it on ErrNotGithubRepository if the project's link isn't a
+	// GitHub repository and ErrReadmeNotFound if the repository doesn't have
+	// a README.
+	SetSync(ctx context.Context, projectId uint, enabled bool) (ReadmeDto, error)
+
+	// Import the project's README again, without waiting for Run to.
+	// Returns ErrSyncDisabled if the project doesn't sync its README, and the
+	// errors of SetSync.
+	Refresh(ctx context.Context, projectId uint) (ReadmeDto, error)
+
+	// Refresh the READMEs imported longer than the refresh interval ago
+	// periodically until ctx is done. Should be run in its own goroutine. Does
+	// nothing if the refresh interval is 0.
+	Run(ctx context.Context)
+}
+
+type serviceImpl struct {
+	Db              *gorm.DB
+	ProjectsService projects.Service
+	GithubClient    contributions.GithubClient
+	RefreshInterval time.Duration
+
+	// When Run last failed to refresh a project's README, so that failing
+	// projects are only retried after the refresh interval
+	failedAt map[uint]time.Time
+}
+
+func NewService(
+	db *gorm.DB,
+	projectsService projects.Service,
+	githubClient contributions.GithubClient,
+	refreshInterval time.Duration,
+) Service {
+	return &serviceImpl{
+		Db:              db,
+		ProjectsService: projectsService,
+		GithubClient:    githubClient,
+		RefreshInterval: refreshInterval,
+		failedAt:        map[uint]time.Time{},
+	}
+}
+
+func (s *serviceImpl) SetSync(ctx context.Context, projectId uint, enabled bool) (ReadmeDto, error) {
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return ReadmeDto{}, err
+	}
+
+	if enabled {
+		err = s.importReadme(ctx, project)
+		if err != nil {
+			return ReadmeDto{}, err
+		}
+	}
+
+	err = s.ProjectsService.SetReadmeSync(ctx, projectId, enabled)
+	if err != nil {
+		return ReadmeDto{}, err
+	}
+
+	return s.getReadme(ctx, projectId)
+}
+
+func (s *serviceImpl) Refresh(ctx context.Context, projectId uint) (ReadmeDto, error) {
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return ReadmeDto{}, err
+	}
+
+	if !project.ReadmeSync {
+		return ReadmeDto{}, ErrSyncDisabled
+	}
+
+	err = s.importReadme(ctx, project)
+	if err != nil {
+		return ReadmeDto{}, err
+	}
+
+	return s.getReadme(ctx, projectId)
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	if s.RefreshInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(refreshCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.refreshStale(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh a batch of the READMEs imported longer than the refresh interval
+// ago, oldest first.
+func (s *serviceImpl) refreshStale(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	var failed []uint
+	for projectId, failedAt := range s.failedAt {
+		if time.Since(failedAt) < s.RefreshInterval {
+			failed = append(failed, projectId)
+		} else {
+			delete(s.failedAt, projectId)
+		}
+	}
+
+	query := s.Db.WithContext(ctx).
+		Model(&projects.Project{}).
+		Where("readme_sync = true").
+		Where("readme_synced_at IS NULL OR readme_synced_at < ?", time.Now().Add(-s.RefreshInterval))
+
+	if len(failed) > 0 {
+		query = query.Where("id NOT IN ?", failed)
+	}
+
+	var projectIds []uint
+	result := query.
+		Order("readme_synced_at").
+		Limit(refreshBatchSize).
+		Pluck("id", &projectIds)
+
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			logger.WithError(result.Error).Error("Failed to list READMEs to refresh")
+		}
+
+		return
+	}
+
+	for _, projectId := range projectIds {
+		_, err := s.Refresh(ctx, projectId)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			// The README keeps its previous contents until it can be imported
+			logger.WithError(err).WithField("projectId", projectId).Warn("Failed to refresh README")
+			s.failedAt[projectId] = time.Now()
+		}
+	}
+}
+
+// Fetch the README of the project's repository, sanitize it and save it.
+func (s *serviceImpl) importReadme(ctx context.Context, project projects.ProjectDto) error {
+	logger := log.FromContext(ctx).WithField("projectId", project.Id)
+
+	owner, repository, err := contributions.ParseRepositoryUrl(project.GithubLink)
+	if err != nil {
+		return err
+	}
+
+	readme, err := s.GithubClient.GetReadme(ctx, owner, repository)
+	if err != nil {
+		if !errors.Is(err, contributions.ErrReadmeNotFound) {
+			logger.WithError(err).Error("Failed to fetch the repository's README")
+		}
+
+		return err
+	}
+
+	err = s.ProjectsService.SaveReadme(ctx, project.Id, Sanitize(readme, owner, repository))
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("README imported")
+
+	return nil
+}
+
+func (s *serviceImpl) getReadme(ctx context.Context, projectId uint) (ReadmeDto, error) {
+	project, err := s.ProjectsService.GetProject(ctx, projectId)
+	if err != nil {
+		return ReadmeDto{}, err
+	}
+
+	return ReadmeDto{
+		Sync:     project.ReadmeSync,
+		SyncedAt: project.ReadmeSyncedAt,
+	}, nil
+}
@@ -0,0 +1,265 @@
+package readme
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// READMEs are cut to the maximum length of written long descriptions (see
+// projects.NewProjectDto).
+const MaxLength = 20000
+
+// Code blocks and spans, which are left as they are.
+var codePattern = regexp.MustCompile("(?ms)^[ \t]*(?:```|~~~)[^\n]*\n.*?^[ \t]*(?:```|~~~)[ \t]*$|`[^`\n]+`")
+
+var commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+// HTML elements removed with their content, since their content isn't text.
+var unsafeElementPatterns = compileElementPatterns(
+	"script", "style", "iframe", "object", "embed", "form", "textarea", "noscript", "svg", "math", "template",
+)
+
+var imagePattern = regexp.MustCompile(`(?is)<img\b[^>]*>`)
+var anchorPattern = regexp.MustCompile(`(?is)<a\b([^>]*)>(.*?)</a\s*>`)
+var lineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>`)
+var tagPattern = regexp.MustCompile(`(?s)</?[a-zA-Z][a-zA-Z0-9-]*(?:\s[^>]*)?/?>`)
+var srcAttributePattern = regexp.MustCompile(`(?is)\bsrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+var hrefAttributePattern = regexp.MustCompile(`(?is)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+var altAttributePattern = regexp.MustCompile(`(?is)\balt\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// Destinations of inline links and images, e.g. the end of [text](url "title")
+// and ![alt](url)
+var destinationPattern = regexp.MustCompile(`\]\(\s*<?([^()\s>]*(?:\([^()\s]*\)[^()\s>]*)*)>?((?:\s+"[^"\n]*")?)\s*\)`)
+
+// Autolinks, e.g. <https://example.com>
+var autolinkPattern = regexp.MustCompile(`<[a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\s]*>`)
+
+// Link reference definitions, e.g. [id]: url "title"
+var referencePattern = regexp.MustCompile(`(?m)^( {0,3}\[[^\]\n]+\]:[ \t]*)<?([^\s>]+)>?(.*)$`)
+
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+func compileElementPatterns(names ...string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(names))
+	for i, name := range names {
+		patterns[i] = regexp.MustCompile(`(?is)<` + name + `\b.*?</` + name + `\s*>`)
+	}
+
+	return patterns
+}
+
+// Make a README of a GitHub repository safe to serve as a project's long
+// description: HTML is removed, except images and links which are turned into
+// markdown, links that aren't web or mail links are removed, relative links
+// are made to point to the repository and the README is cut to MaxLength.
+// Code blocks and spans are left untouched.
+func Sanitize(readme string, owner string, repository string) string {
+	readme = strings.ToValidUTF8(readme, "")
+	readme = strings.ReplaceAll(readme, "\r\n", "\n")
+	readme = strings.ReplaceAll(readme, "\x00", "")
+
+	var sanitized strings.Builder
+	start := 0
+	for _, code := range codePattern.FindAllStringIndex(readme, -1) {
+		sanitized.WriteString(sanitizeText(readme[start:code[0]], owner, repository))
+		sanitized.WriteString(readme[code[0]:code[1]])
+		start = code[1]
+	}
+	sanitized.WriteString(sanitizeText(readme[start:], owner, repository))
+
+	result := blankLinesPattern.ReplaceAllString(sanitized.String(), "\n\n")
+	result = strings.TrimSpace(result)
+
+	return truncate(result, MaxLength)
+}
+
+// Sanitize markdown that isn't code.
+func sanitizeText(text string, owner string, repository string) string {
+	text = commentPattern.ReplaceAllString(text, "")
+	for _, pattern := range unsafeElementPatterns {
+		text = pattern.ReplaceAllString(text, "")
+	}
+
+	text = imagePattern.ReplaceAllStringFunc(text, func(image string) string {
+		src := attribute(srcAttributePattern, image)
+		if src == "" {
+			return ""
+		}
+
+		return "![" + escapeLinkText(attribute(altAttributePattern, image)) + "](" + src + ")"
+	})
+
+	text = anchorPattern.ReplaceAllStringFunc(text, func(anchor string) string {
+		parts := anchorPattern.FindStringSubmatch(anchor)
+		href := attribute(hrefAttributePattern, parts[1])
+		if href == "" {
+			return parts[2]
+		}
+
+		return "[" + parts[2] + "](" + href + ")"
+	})
+
+	text = lineBreakPattern.ReplaceAllString(text, "\n")
+	text = tagPattern.ReplaceAllString(text, "")
+
+	// Link texts can have brackets, e.g. badges are images in links, so
+	// destinations are matched on their own
+	text = replaceAllSubmatchIndexFunc(destinationPattern, text, func(match []int) string {
+		image := isImage(text, match[0])
+
+		target, ok := resolveLink(text[match[2]:match[3]], owner, repository, image)
+		if !ok {
+			target = "#"
+		}
+
+		return "](" + target + text[match[4]:match[5]] + ")"
+	})
+
+	text = autolinkPattern.ReplaceAllStringFunc(text, func(autolink string) string {
+		target, ok := resolveLink(autolink[1:len(autolink)-1], owner, repository, false)
+		if !ok {
+			return ""
+		}
+
+		return "<" + target + ">"
+	})
+
+	text = referencePattern.ReplaceAllStringFunc(text, func(reference string) string {
+		parts := referencePattern.FindStringSubmatch(reference)
+
+		target, ok := resolveLink(parts[2], owner, repository, false)
+		if !ok {
+			return ""
+		}
+
+		return parts[1] + target + parts[3]
+	})
+
+	return text
+}
+
+// The value of the first attribute matching pattern, "" if there's none.
+func attribute(pattern *regexp.Regexp, tag string) string {
+	parts := pattern.FindStringSubmatch(tag)
+	if parts == nil {
+		return ""
+	}
+
+	for _, value := range parts[1:] {
+		if value != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return ""
+}
+
+// Replace the matches of pattern in text with the result of replace, which
+// gets the match's submatch indexes.
+func replaceAllSubmatchIndexFunc(pattern *regexp.Regexp, text string, replace func(match []int) string) string {
+	var replaced strings.Builder
+	start := 0
+	for _, match := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		replaced.WriteString(text[start:match[0]])
+		replaced.WriteString(replace(match))
+		start = match[1]
+	}
+	replaced.WriteString(text[start:])
+
+	return replaced.String()
+}
+
+// Whether the link text closed at end (the index of its "]") is an image's,
+// i.e. its "[" follows a "!".
+func isImage(text string, end int) bool {
+	depth := 0
+	for i := end - 1; i >= 0; i-- {
+		switch text[i] {
+		case ']':
+			depth++
+		case '[':
+			if depth == 0 {
+				return i > 0 && text[i-1] == '!'
+			}
+			depth--
+		case '\n':
+			if i > 0 && text[i-1] == '\n' {
+				// Links don't span paragraphs
+				return false
+			}
+		}
+	}
+
+	return false
+}
+
+func escapeLinkText(text string) string {
+	return strings.NewReplacer("[", "", "]", "", "\n", " ").Replace(text)
+}
+
+// Make a link of the README safe to serve. Relative links point to the file
+// in the repository, or to its raw contents for images. Returns false if the
+// link must be removed.
+func resolveLink(link string, owner string, repository string, image bool) (string, bool) {
+	if link == "" {
+		return "", false
+	}
+
+	if strings.HasPrefix(link, "#") {
+		return link, true
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+
+	if parsed.Scheme != "" || parsed.Host != "" {
+		switch strings.ToLower(parsed.Scheme) {
+		case "http", "https":
+			return link, parsed.Host != ""
+		case "mailto":
+			return link, !image
+		default:
+			return "", false
+		}
+	}
+
+	filePath := path.Clean("/" + parsed.Path)
+	if filePath == "/" {
+		return "", false
+	}
+
+	base := "https://github.com/" + url.PathEscape(owner) + "/" + url.PathEscape(repository) + "/blob/HEAD"
+	if image {
+		base = "https://raw.githubusercontent.com/" + url.PathEscape(owner) + "/" + url.PathEscape(repository) + "/HEAD"
+	}
+
+	resolved := base + (&url.URL{Path: filePath}).EscapedPath()
+	if parsed.RawQuery != "" {
+		resolved += "?" + parsed.RawQuery
+	}
+	if parsed.Fragment != "" {
+		resolved += "#" + parsed.EscapedFragment()
+	}
+
+	return resolved, true
+}
+
+// Cut text to at most maxLength runes, at the end of a line if there's one.
+func truncate(text string, maxLength int) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(text)
+	cut := string(runes[:maxLength])
+	if newline := strings.LastIndex(cut, "\n"); newline > 0 {
+		cut = cut[:newline]
+	}
+
+	return strings.TrimSpace(cut)
+}
@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reportsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reports "github.com/open-collaboration/server/reports"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// RequestReport mocks base method
+func (m *MockService) RequestReport(ctx context.Context, requestedBy uint, dto reports.NewReportDto) (reports.ReportDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestReport", ctx, requestedBy, dto)
+	ret0, _ := ret[0].(reports.ReportDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequestReport indicates an expected call of RequestReport
+func (mr *MockServiceMockRecorder) RequestReport(ctx, requestedBy, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReport", reflect.TypeOf((*MockService)(nil).RequestReport), ctx, requestedBy, dto)
+}
+
+// ListReports mocks base method
+func (m *MockService) ListReports(ctx context.Context) ([]reports.ReportDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReports", ctx)
+	ret0, _ := ret[0].([]reports.ReportDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReports indicates an expected call of ListReports
+func (mr *MockServiceMockRecorder) ListReports(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReports", reflect.TypeOf((*MockService)(nil).ListReports), ctx)
+}
+
+// GetReport mocks base method
+func (m *MockService) GetReport(ctx context.Context, reportId uint) (reports.ReportDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", ctx, reportId)
+	ret0, _ := ret[0].(reports.ReportDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport
+func (mr *MockServiceMockRecorder) GetReport(ctx, reportId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockService)(nil).GetReport), ctx, reportId)
+}
+
+// GetReportContent mocks base method
+func (m *MockService) GetReportContent(ctx context.Context, reportId uint) (reports.ReportDto, []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportContent", ctx, reportId)
+	ret0, _ := ret[0].(reports.ReportDto)
+	ret1, _ := ret[1].([]byte)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetReportContent indicates an expected call of GetReportContent
+func (mr *MockServiceMockRecorder) GetReportContent(ctx, reportId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportContent", reflect.TypeOf((*MockService)(nil).GetReportContent), ctx, reportId)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
+
+// CountPendingReports mocks base method
+func (m *MockService) CountPendingReports(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPendingReports", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPendingReports indicates an expected call of CountPendingReports
+func (mr *MockServiceMockRecorder) CountPendingReports(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPendingReports", reflect.TypeOf((*MockService)(nil).CountPendingReports), ctx)
+}
@@ -0,0 +1,25 @@
+package reports
+
+import "time"
+
+type NewReportDto struct {
+	// signups-per-week, projects-per-tag or application-funnel
+	Kind Kind `json:"kind" validate:"required,oneof=signups-per-week projects-per-tag application-funnel"`
+
+	// Only data created from Since (inclusive) until Until (exclusive) is
+	// reported. Both are optional.
+	Since *time.Time `json:"since"`
+	Until *time.Time `json:"until"`
+}
+
+type ReportDto struct {
+	Id          uint       `json:"id"`
+	Kind        Kind       `json:"kind"`
+	Status      Status     `json:"status"`
+	RequestedBy uint       `json:"requestedBy"`
+	Since       *time.Time `json:"since"`
+	Until       *time.Time `json:"until"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
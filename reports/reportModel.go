@@ -0,0 +1,53 @@
+package reports
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// What a report is about.
+type Kind string
+
+const (
+	// Users who signed up each week
+	KindSignupsPerWeek Kind = "signups-per-week"
+
+	// Projects created with each tag
+	KindProjectsPerTag Kind = "projects-per-tag"
+
+	// Applications sent each week and how many of them got to an interview
+	// and were accepted
+	KindApplicationFunnel Kind = "application-funnel"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusReady   Status = "ready"
+	StatusFailed  Status = "failed"
+)
+
+// A CSV report requested by an admin. Reports are generated in the
+// background (see Service.Run) and stored in the database along with their
+// request, so that any instance can serve them.
+type Report struct {
+	gorm.Model
+
+	Kind        Kind
+	Status      Status
+	RequestedBy uint
+
+	// Only data created in [Since, Until) is reported, nil bounds are unbounded
+	Since *time.Time
+	Until *time.Time
+
+	// The CSV, once the report is ready
+	Content []byte
+
+	// Why the report failed, if it did
+	Error string
+
+	CompletedAt *time.Time
+}
@@ -0,0 +1,146 @@
+package reports
+
+import (
+	"fmt"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+)
+
+// @Summary Request a report
+// @Description Reports are generated in the background, poll GET /admin/reports/{reportId} until the report is
+// @Description ready and download it from GET /admin/reports/{reportId}/download.
+// @Description signups-per-week: users who signed up each week. projects-per-tag: projects created with each tag.
+// @Description application-funnel: applications sent each week, how many got an interview and how many were accepted.
+// @Tags admin
+// @Router /admin/reports [post]
+// @Param report body reports.NewReportDto true "The report"
+// @Success 202 {object} reports.ReportDto
+// @Failure 400 "The report's range ends before it starts"
+// @Failure 403
+func RouteRequestReport(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	reportsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := NewReportDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	report, err := reportsService.RequestReport(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	writer.Header().Set("Location", "/admin/reports/"+strconv.Itoa(int(report.Id)))
+
+	return utils.WriteJson(writer, request.Context(), http.StatusAccepted, report)
+}
+
+// @Summary List reports
+// @Description The latest 100 reports, newest to oldest.
+// @Tags admin
+// @Router /admin/reports [get]
+// @Success 200 {array} reports.ReportDto
+// @Failure 403
+func RouteListReports(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	reportsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	reports, err := reportsService.ListReports(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, reports)
+}
+
+// @Summary Get a report
+// @Tags admin
+// @Router /admin/reports/{reportId} [get]
+// @Param reportId path int true "The report ID"
+// @Success 200 {object} reports.ReportDto
+// @Failure 403
+// @Failure 404
+func RouteGetReport(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	reportsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	reportId, err := utils.UintFromVars(request, "reportId")
+	if err != nil {
+		return err
+	}
+
+	report, err := reportsService.GetReport(request.Context(), reportId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, report)
+}
+
+// @Summary Download a report
+// @Tags admin
+// @Router /admin/reports/{reportId}/download [get]
+// @Param reportId path int true "The report ID"
+// @Produce text/csv
+// @Success 200 {string} string "The report as CSV"
+// @Failure 403
+// @Failure 404
+// @Failure 409 "The report isn't ready"
+func RouteDownloadReport(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	reportsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	reportId, err := utils.UintFromVars(request, "reportId")
+	if err != nil {
+		return err
+	}
+
+	report, content, err := reportsService.GetReportContent(request.Context(), reportId)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("%s-%d.csv", report.Kind, report.Id)
+
+	writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	writer.Header().Set("Cache-Control", "private, no-store")
+	writer.WriteHeader(http.StatusOK)
+
+	_, err = writer.Write(content)
+
+	return err
+}
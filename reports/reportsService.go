@@ -0,0 +1,415 @@
+package reports
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+	"strconv"
+	"time"
+)
+
+var ErrReportNotFound = errors.New("report not found")
+var ErrReportNotReady = errors.New("report is not ready")
+var ErrInvalidRange = errors.New("a report's range can't end before it starts")
+
+// How often pending reports are looked for, besides right after a report is
+// requested on this instance.
+const reportPollInterval = 30 * time.Second
+
+// Reports running for longer than this are assumed to have been abandoned,
+// e.g. because the instance generating them stopped, and are generated again.
+const reportTimeout = 10 * time.Minute
+
+// How many reports are listed.
+const reportsListLimit = 100
+
+type Service interface {
+	// Request a report, which is generated in the background by Run.
+	// Returns ErrInvalidRange if the report's range ends before it starts.
+	RequestReport(ctx context.Context, requestedBy uint, dto NewReportDto) (ReportDto, error)
+
+	// List the latest reports, newest to oldest.
+	ListReports(ctx context.Context) ([]ReportDto, error)
+
+	// Returns ErrReportNotFound if the report can't be found.
+	GetReport(ctx context.Context, reportId uint) (ReportDto, error)
+
+	// Get a report's CSV.
+	// Returns ErrReportNotFound if the report can't be found and
+	// ErrReportNotReady if it wasn't generated (yet).
+	GetReportContent(ctx context.Context, reportId uint) (ReportDto, []byte, error)
+
+	// Generate requested reports until ctx is done. Should be run in its own
+	// goroutine. Each report is generated by a single instance.
+	Run(ctx context.Context)
+
+	// How many reports are waiting to be generated.
+	CountPendingReports(ctx context.Context) (int64, error)
+}
+
+type serviceImpl struct {
+	Db *gorm.DB
+
+	// Wakes Run up when a report is requested
+	wake chan struct{}
+}
+
+func NewService(db *gorm.DB) Service {
+	return &serviceImpl{
+		Db:   db,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+func (s *serviceImpl) RequestReport(ctx context.Context, requestedBy uint, dto NewReportDto) (ReportDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return ReportDto{}, err
+	}
+
+	if dto.Since != nil && dto.Until != nil && dto.Until.Before(*dto.Since) {
+		return ReportDto{}, ErrInvalidRange
+	}
+
+	report := Report{
+		Kind:        dto.Kind,
+		Status:      StatusPending,
+		RequestedBy: requestedBy,
+		Since:       dto.Since,
+		Until:       dto.Until,
+	}
+
+	result := s.Db.WithContext(ctx).Create(&report)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to request report")
+
+		return ReportDto{}, result.Error
+	}
+
+	// Run may already be awake, in which case it will find the report anyway
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return reportToDto(report), nil
+}
+
+func (s *serviceImpl) ListReports(ctx context.Context) ([]ReportDto, error) {
+	var reports []Report
+	result := s.Db.WithContext(ctx).
+		Omit("content").
+		Order("created_at desc").
+		Limit(reportsListLimit).
+		Find(&reports)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list reports")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]ReportDto, len(reports))
+	for i, report := range reports {
+		dtos[i] = reportToDto(report)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) GetReport(ctx context.Context, reportId uint) (ReportDto, error) {
+	report, err := s.findReport(s.Db.WithContext(ctx).Omit("content"), reportId)
+	if err != nil {
+		return ReportDto{}, err
+	}
+
+	return reportToDto(report), nil
+}
+
+func (s *serviceImpl) GetReportContent(ctx context.Context, reportId uint) (ReportDto, []byte, error) {
+	report, err := s.findReport(s.Db.WithContext(ctx), reportId)
+	if err != nil {
+		return ReportDto{}, nil, err
+	}
+
+	if report.Status != StatusReady {
+		return ReportDto{}, nil, ErrReportNotReady
+	}
+
+	return reportToDto(report), report.Content, nil
+}
+
+func (s *serviceImpl) findReport(query *gorm.DB, reportId uint) (Report, error) {
+	report := Report{}
+	result := query.First(&report, reportId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return Report{}, ErrReportNotFound
+		}
+
+		log.FromContext(query.Statement.Context).WithError(result.Error).Error("Failed to query for report")
+
+		return Report{}, result.Error
+	}
+
+	return report, nil
+}
+
+func (s *serviceImpl) CountPendingReports(ctx context.Context) (int64, error) {
+	var count int64
+	result := s.Db.WithContext(ctx).Model(&Report{}).Where("status = ?", StatusPending).Count(&count)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to count pending reports")
+
+		return 0, result.Error
+	}
+
+	return count, nil
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(reportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Generate reports until there are none left
+		for s.generateNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// Claim the oldest pending report and generate it. Returns whether a report
+// was claimed.
+func (s *serviceImpl) generateNext(ctx context.Context) bool {
+	logger := log.FromContext(ctx)
+
+	// SKIP LOCKED keeps instances from claiming the same report
+	var reports []Report
+	result := s.Db.WithContext(ctx).Raw(`
+		UPDATE reports
+		SET status = ?, updated_at = now()
+		WHERE id = (
+			SELECT id
+			FROM reports
+			WHERE deleted_at IS NULL
+			  AND (status = ? OR (status = ? AND updated_at < ?))
+			ORDER BY id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, kind, since, until`,
+		StatusRunning,
+		StatusPending,
+		StatusRunning,
+		time.Now().Add(-reportTimeout),
+	).Scan(&reports)
+
+	if result.Error != nil {
+		if ctx.Err() == nil {
+			logger.WithError(result.Error).Error("Failed to claim a pending report")
+		}
+
+		return false
+	}
+
+	if len(reports) < 1 {
+		return false
+	}
+
+	report := reports[0]
+	logger = logger.WithFields(log.Fields{
+		"reportId": report.ID,
+		"kind":     report.Kind,
+	})
+
+	content, err := s.generate(ctx, report)
+	now := time.Now()
+
+	update := map[string]interface{}{
+		"status":       StatusReady,
+		"content":      content,
+		"completed_at": now,
+	}
+	if err != nil {
+		logger.WithError(err).Error("Failed to generate report")
+
+		update = map[string]interface{}{
+			"status":       StatusFailed,
+			"error":        err.Error(),
+			"completed_at": now,
+		}
+	}
+
+	result = s.Db.WithContext(ctx).Model(&Report{}).Where("id = ?", report.ID).Updates(update)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to save report")
+	} else if err == nil {
+		logger.Info("Report generated")
+	}
+
+	return true
+}
+
+// Generate a report's CSV.
+func (s *serviceImpl) generate(ctx context.Context, report Report) ([]byte, error) {
+	db := s.Db.WithContext(ctx)
+
+	// created_at is compared to open bounds when the report's are nil
+	since := "created_at >= coalesce(?::TIMESTAMPTZ, '-infinity')"
+	until := "created_at < coalesce(?::TIMESTAMPTZ, 'infinity')"
+
+	var header []string
+	var records [][]string
+
+	switch report.Kind {
+	case KindSignupsPerWeek:
+		var rows []struct {
+			Week    time.Time
+			Signups int64
+		}
+
+		result := db.Raw(`
+			SELECT date_trunc('week', created_at) AS week, count(*) AS signups
+			FROM users
+			WHERE deleted_at IS NULL AND `+since+` AND `+until+`
+			GROUP BY week
+			ORDER BY week`,
+			report.Since,
+			report.Until,
+		).Scan(&rows)
+
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		header = []string{"week", "signups"}
+		for _, row := range rows {
+			records = append(records, []string{formatWeek(row.Week), strconv.FormatInt(row.Signups, 10)})
+		}
+
+	case KindProjectsPerTag:
+		var rows []struct {
+			Tag      string
+			Projects int64
+		}
+
+		result := db.Raw(`
+			SELECT tag, count(*) AS projects
+			FROM projects CROSS JOIN unnest(tags) AS tag
+			WHERE deleted_at IS NULL AND `+since+` AND `+until+`
+			GROUP BY tag
+			ORDER BY projects DESC, tag`,
+			report.Since,
+			report.Until,
+		).Scan(&rows)
+
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		header = []string{"tag", "projects"}
+		for _, row := range rows {
+			records = append(records, []string{row.Tag, strconv.FormatInt(row.Projects, 10)})
+		}
+
+	case KindApplicationFunnel:
+		var rows []struct {
+			Week         time.Time
+			Applications int64
+			Interviews   int64
+			Accepted     int64
+			Rejected     int64
+		}
+
+		result := db.Raw(`
+			SELECT date_trunc('week', created_at) AS week,
+			       count(*) AS applications,
+			       count(*) FILTER (WHERE interview_status = 'scheduled') AS interviews,
+			       count(*) FILTER (WHERE status = 'accepted') AS accepted,
+			       count(*) FILTER (WHERE status = 'rejected') AS rejected
+			FROM applications
+			WHERE deleted_at IS NULL AND `+since+` AND `+until+`
+			GROUP BY week
+			ORDER BY week`,
+			report.Since,
+			report.Until,
+		).Scan(&rows)
+
+		if result.Error != nil {
+			return nil, result.Error
+		}
+
+		header = []string{"week", "applications", "interviews_scheduled", "accepted", "rejected", "interview_rate", "acceptance_rate"}
+		for _, row := range rows {
+			records = append(records, []string{
+				formatWeek(row.Week),
+				strconv.FormatInt(row.Applications, 10),
+				strconv.FormatInt(row.Interviews, 10),
+				strconv.FormatInt(row.Accepted, 10),
+				strconv.FormatInt(row.Rejected, 10),
+				formatRate(row.Interviews, row.Applications),
+				formatRate(row.Accepted, row.Applications),
+			})
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown report kind %q", report.Kind)
+	}
+
+	buffer := bytes.Buffer{}
+	writer := csv.NewWriter(&buffer)
+
+	err := writer.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.WriteAll(records)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Weeks are identified by the date of their Monday.
+func formatWeek(week time.Time) string {
+	return week.Format("2006-01-02")
+}
+
+// Format part/total as a ratio with 3 decimal places.
+func formatRate(part int64, total int64) string {
+	if total < 1 {
+		return "0.000"
+	}
+
+	return strconv.FormatFloat(float64(part)/float64(total), 'f', 3, 64)
+}
+
+func reportToDto(report Report) ReportDto {
+	return ReportDto{
+		Id:          report.ID,
+		Kind:        report.Kind,
+		Status:      report.Status,
+		RequestedBy: report.RequestedBy,
+		Since:       report.Since,
+		Until:       report.Until,
+		Error:       report.Error,
+		CreatedAt:   report.CreatedAt,
+		CompletedAt: report.CompletedAt,
+	}
+}
@@ -0,0 +1,20 @@
+package retention
+
+import "time"
+
+type StatsDto struct {
+	// audit-log, analytics-events, notifications or soft-deleted
+	Policy string `json:"policy"`
+
+	// Rows older than this are removed, 0 if they're kept forever
+	RetentionDays int `json:"retentionDays"`
+
+	// Rows removed since the server started
+	TotalRemoved int64 `json:"totalRemoved"`
+
+	LastRunAt   *time.Time `json:"lastRunAt"`
+	LastRemoved int64      `json:"lastRemoved"`
+
+	// Empty if the last run succeeded
+	LastError string `json:"lastError,omitempty"`
+}
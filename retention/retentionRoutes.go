@@ -0,0 +1,29 @@
+package retention
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List retention policies
+// @Description How long audit log entries, analytics events, notifications and soft deleted rows are kept, and how
+// @Description many rows each policy pruned since the server started. Expired rows are pruned every hour.
+// @Tags admin
+// @Router /admin/retention [get]
+// @Success 200 {array} retention.StatsDto
+// @Failure 403
+func RouteListPolicies(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	retentionService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, retentionService.Stats())
+}
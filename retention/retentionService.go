@@ -0,0 +1,285 @@
+package retention
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/database"
+	"gorm.io/gorm"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// How often expired rows are pruned.
+const pruneInterval = time.Hour
+
+// Rows are deleted in batches of this many rows, so that pruning a large
+// backlog doesn't lock a table for long.
+const deleteBatchSize = 5000
+
+// Monthly analytics events partitions, see analytics.NewDatabaseSink.
+var analyticsPartitionPattern = regexp.MustCompile(`^analytics_events_(\d{4})_(\d{2})$`)
+
+// How long rows are kept, in days. 0 keeps them forever.
+type Policies struct {
+	AuditLogDays        int
+	AnalyticsEventsDays int
+	NotificationsDays   int
+
+	// What accounts were seen with, see the sockpuppets package
+	AccountSightingsDays int
+
+	// Rows soft deleted this long ago are deleted for good, in every table
+	// with soft deletes
+	SoftDeletedDays int
+}
+
+type Service interface {
+	// Prune expired rows periodically until ctx is done. Should be run in its
+	// own goroutine. Pruning is idempotent, so it's fine for several
+	// instances to run it.
+	Run(ctx context.Context)
+
+	// How many rows each policy removed, ordered by policy.
+	Stats() []StatsDto
+}
+
+// A retention policy: prune removes the rows older than cutoff and returns
+// how many it removed.
+type policy struct {
+	name  string
+	days  int
+	prune func(ctx context.Context, cutoff time.Time) (int64, error)
+
+	totalRemoved int64
+	lastRunAt    *time.Time
+	lastRemoved  int64
+	lastError    string
+}
+
+type serviceImpl struct {
+	Db *gorm.DB
+
+	mu       sync.Mutex
+	policies []*policy
+}
+
+func NewService(db *gorm.DB, policies Policies) Service {
+	s := &serviceImpl{Db: db}
+
+	s.policies = []*policy{
+		{name: "analytics-events", days: policies.AnalyticsEventsDays, prune: s.pruneAnalyticsEvents},
+		{name: "audit-log", days: policies.AuditLogDays, prune: s.pruneTable("audit_log_entries", "created_at")},
+		{name: "notifications", days: policies.NotificationsDays, prune: s.pruneTable("notifications", "created_at")},
+		{name: "account-sightings", days: policies.AccountSightingsDays, prune: s.pruneTable("account_sightings", "last_seen_at")},
+		{name: "soft-deleted", days: policies.SoftDeletedDays, prune: s.pruneSoftDeleted},
+	}
+
+	return s
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, policy := range s.policies {
+			if policy.days > 0 {
+				s.runPolicy(ctx, policy)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *serviceImpl) runPolicy(ctx context.Context, policy *policy) {
+	logger := log.FromContext(ctx).WithField("policy", policy.name)
+
+	cutoff := time.Now().AddDate(0, 0, -policy.days)
+	removed, err := policy.prune(ctx, cutoff)
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+
+		logger.WithError(err).Error("Failed to prune expired rows")
+	} else if removed > 0 {
+		logger.WithField("removed", removed).Info("Pruned expired rows")
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policy.totalRemoved += removed
+	policy.lastRunAt = &now
+	policy.lastRemoved = removed
+	policy.lastError = ""
+	if err != nil {
+		policy.lastError = err.Error()
+	}
+}
+
+func (s *serviceImpl) Stats() []StatsDto {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]StatsDto, len(s.policies))
+	for i, policy := range s.policies {
+		stats[i] = StatsDto{
+			Policy:        policy.name,
+			RetentionDays: policy.days,
+			TotalRemoved:  policy.totalRemoved,
+			LastRunAt:     policy.lastRunAt,
+			LastRemoved:   policy.lastRemoved,
+			LastError:     policy.lastError,
+		}
+	}
+
+	return stats
+}
+
+// Prune the rows of table whose column is older than the cutoff.
+func (s *serviceImpl) pruneTable(table string, column string) func(ctx context.Context, cutoff time.Time) (int64, error) {
+	return func(ctx context.Context, cutoff time.Time) (int64, error) {
+		return s.deleteInBatches(ctx, table, column+" < ?", cutoff)
+	}
+}
+
+// Drop the monthly partitions that ended before the cutoff and prune the
+// default partition. Events of the cutoff's month are removed once the whole
+// month expired.
+func (s *serviceImpl) pruneAnalyticsEvents(ctx context.Context, cutoff time.Time) (int64, error) {
+	// SQLite has no partitions
+	if database.IsSqlite(s.Db) {
+		return s.deleteInBatches(ctx, "analytics_events", "occurred_at < ?", cutoff)
+	}
+
+	db := s.Db.WithContext(ctx)
+
+	var partitions []string
+	result := db.Raw(`
+		SELECT child.relname
+		FROM pg_inherits
+		JOIN pg_class parent ON parent.oid = pg_inherits.inhparent
+		JOIN pg_class child ON child.oid = pg_inherits.inhrelid
+		WHERE parent.relname = 'analytics_events'`,
+	).Scan(&partitions)
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	var removed int64
+	for _, partition := range partitions {
+		match := analyticsPartitionPattern.FindStringSubmatch(partition)
+		if match == nil {
+			continue
+		}
+
+		month, err := time.Parse("2006-01", match[1]+"-"+match[2])
+		if err != nil || month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+
+		var count int64
+		result = db.Table(partition).Count(&count)
+		if result.Error != nil {
+			return removed, result.Error
+		}
+
+		// The name comes from the catalog and matched the pattern
+		result = db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", partition))
+		if result.Error != nil {
+			return removed, result.Error
+		}
+
+		removed += count
+	}
+
+	defaultRemoved, err := s.deleteInBatches(ctx, "analytics_events_default", "occurred_at < ?", cutoff)
+
+	return removed + defaultRemoved, err
+}
+
+// Delete the rows soft deleted before the cutoff in every table with soft
+// deletes. Tables whose rows can't be deleted, e.g. because other rows
+// reference them, are skipped and the last error is returned.
+func (s *serviceImpl) pruneSoftDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `
+		SELECT columns.table_name
+		FROM information_schema.columns
+		JOIN information_schema.tables USING (table_schema, table_name)
+		WHERE columns.table_schema = 'public'
+		  AND columns.column_name = 'deleted_at'
+		  AND tables.table_type = 'BASE TABLE'
+		ORDER BY columns.table_name`
+
+	if database.IsSqlite(s.Db) {
+		query = `
+			SELECT tables.name
+			FROM sqlite_master AS tables
+			JOIN pragma_table_info(tables.name) AS columns
+			WHERE tables.type = 'table'
+			  AND columns.name = 'deleted_at'
+			ORDER BY tables.name`
+	}
+
+	var tables []string
+	result := s.Db.WithContext(ctx).Raw(query).Scan(&tables)
+
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	var removed int64
+	var lastErr error
+	for _, table := range tables {
+		count, err := s.deleteInBatches(ctx, table, "deleted_at < ?", cutoff)
+		removed += count
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return removed, err
+			}
+
+			log.FromContext(ctx).WithError(err).WithField("table", table).Warn("Failed to prune soft deleted rows")
+			lastErr = fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	return removed, lastErr
+}
+
+// Delete the rows of table matching condition, deleteBatchSize rows at a
+// time. Returns how many rows were deleted.
+func (s *serviceImpl) deleteInBatches(ctx context.Context, table string, condition string, args ...interface{}) (int64, error) {
+	var removed int64
+	rowId := database.DialectOf(s.Db).RowId()
+
+	for {
+		result := s.Db.WithContext(ctx).Exec(
+			fmt.Sprintf("DELETE FROM %s WHERE %s IN (SELECT %s FROM %s WHERE %s LIMIT %d)", table, rowId, rowId, table, condition, deleteBatchSize),
+			args...,
+		)
+
+		if result.Error != nil {
+			return removed, result.Error
+		}
+
+		removed += result.RowsAffected
+		if result.RowsAffected < deleteBatchSize {
+			return removed, nil
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package router
+
+import "github.com/open-collaboration/server/auth"
+
+// Routes that can be called without a session, see auth.AnonymousAccessMiddleware.
+// All other routes require a session. ANONYMOUS_ACCESS overrides entries for
+// specific routes.
+var defaultAccessPolicy = auth.AccessPolicy{
+	// The public API: projects, their details and user profiles. Projects
+	// pending review are hidden from anonymous users by the handlers.
+	"GET /projects":                            auth.AccessPublic,
+	"GET /projects/search":                     auth.AccessPublic,
+	"GET /projects/discover":                   auth.AccessPublic,
+	"GET /projects/{projectId}":                auth.AccessPublic,
+	"GET /projects/{projectId}/similar":        auth.AccessPublic,
+	"GET /projects/{projectId}/og-image.png":   auth.AccessPublic,
+	"GET /projects/{projectId}/status-history": auth.AccessPublic,
+	"GET /projects/{projectId}/contributions":  auth.AccessPublic,
+	"GET /projects/{projectId}/events":         auth.AccessPublic,
+	"GET /projects/{projectId}/response-time":  auth.AccessPublic,
+	"GET /users/{userId}/contributions":        auth.AccessPublic,
+	"GET /users/{userId}/portfolio":            auth.AccessPublic,
+	"GET /licenses":                            auth.AccessPublic,
+	"GET /spoken-languages":                    auth.AccessPublic,
+	"GET /skills":                              auth.AccessPublic,
+	"GET /homepage":                            auth.AccessPublic,
+	"GET /collections":                         auth.AccessPublic,
+	"GET /collections/{collectionId}":          auth.AccessPublic,
+
+	// Signing up and logging in
+	"POST /users":                          auth.AccessAnonymous,
+	"POST /login":                          auth.AccessAnonymous,
+	"POST /auth/login":                     auth.AccessAnonymous,
+	"POST /auth/logout":                    auth.AccessAnonymous,
+	"POST /auth/oauth/{provider}/start":    auth.AccessAnonymous,
+	"POST /auth/oauth/{provider}/callback": auth.AccessAnonymous,
+	"POST /waitlist":                       auth.AccessAnonymous,
+	"GET /push/public-key":                 auth.AccessAnonymous,
+	"GET /swagger-ui":                      auth.AccessAnonymous,
+	"GET /health/startup":                  auth.AccessAnonymous,
+	"GET /meta":                            auth.AccessAnonymous,
+
+	// Anyone following a project's funding link
+	"POST /projects/{projectId}/funding/{fundingLinkId}/clicks": auth.AccessAnonymous,
+
+	// Events are anonymized, visitors' included
+	"POST /analytics/events": auth.AccessAnonymous,
+
+	// Visitors are assigned to experiments too
+	"GET /experiments": auth.AccessAnonymous,
+	"POST /experiments/{experimentKey}/exposures": auth.AccessAnonymous,
+
+	// Authenticated by a gateway key and a feed token respectively
+	"POST /internal/sessions/validate": auth.AccessAnonymous,
+	"GET /calendar/{token}.ics":        auth.AccessAnonymous,
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Routes under this prefix are admin routes: admin tools, metrics (e.g.
+// circuit breakers and retention) and debugging.
+const adminPathPrefix = "/admin"
+
+// Split the router's handler for serving the admin routes on their own
+// listener, which operators can firewall off from the public API. The public
+// handler responds 404 to admin routes and the admin handler to all others.
+// Both still check sessions and roles.
+func SplitAdminRoutes(handler http.Handler) (public http.Handler, admin http.Handler) {
+	public = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if isAdminPath(request.URL.Path) {
+			http.NotFound(writer, request)
+
+			return
+		}
+
+		handler.ServeHTTP(writer, request)
+	})
+
+	admin = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !isAdminPath(request.URL.Path) {
+			http.NotFound(writer, request)
+
+			return
+		}
+
+		handler.ServeHTTP(writer, request)
+	})
+
+	return public, admin
+}
+
+// Whether a request path is an admin route's. Paths are cleaned first, like
+// the router does, so that e.g. "//admin" isn't let through.
+func isAdminPath(requestPath string) bool {
+	cleaned := path.Clean("/" + requestPath)
+
+	return cleaned == adminPathPrefix || strings.HasPrefix(cleaned, adminPathPrefix+"/")
+}
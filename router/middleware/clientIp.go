@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"github.com/open-collaboration/server/consts"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Adds the client's IP address to the request's context. You can
+// get it with utils.ClientIp.
+//
+// If the environment variable TRUST_PROXY is "true", or the number of reverse
+// proxies in front of the server (e.g. 2 for a CDN and a load balancer), the
+// address is read from the X-Forwarded-For header. Each proxy appends the
+// address it received the request from, so the client's address is the one
+// appended by the outermost proxy, that many entries from the right; the
+// entries before it are sent by the client and can be spoofed. Only enable it
+// when the server is behind reverse proxies.
+func ClientIpMiddleware(next http.Handler) http.Handler {
+	trustedProxies := trustedProxyCount(os.Getenv("TRUST_PROXY"))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := r.RemoteAddr
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			ip = host
+		}
+
+		if trustedProxies > 0 {
+			if forwarded := forwardedIp(r.Header.Values("X-Forwarded-For"), trustedProxies); forwarded != "" {
+				ip = forwarded
+			}
+		}
+
+		ctx := context.WithValue(r.Context(), consts.ClientIpKey, ip)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// The number of trusted proxies of a TRUST_PROXY value: 1 for "true", 0 for
+// anything but a positive number.
+func trustedProxyCount(value string) int {
+	if value == "true" {
+		return 1
+	}
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return 0
+	}
+
+	return count
+}
+
+// The address the outermost of trustedProxies proxies received the request
+// from, given the X-Forwarded-For headers. If the headers have fewer entries,
+// the request didn't go through every proxy and the leftmost entry is used.
+// Empty if there are no entries.
+func forwardedIp(headers []string, trustedProxies int) string {
+	var entries []string
+	for _, header := range headers {
+		for _, entry := range strings.Split(header, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return ""
+	}
+
+	index := len(entries) - trustedProxies
+	if index < 0 {
+		index = 0
+	}
+
+	return entries[index]
+}
@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Supported content encodings, in order of preference.
+var encodings = []string{"br", "gzip"}
+
+// Compresses JSON responses of at least minSize bytes with brotli or gzip,
+// whichever the client accepts (Accept-Encoding), preferring brotli.
+//
+// routeMinSizes overrides minSize for specific routes, a size of 0 disables
+// compression for the route. Its keys are the route's method and path
+// template, e.g. "GET /projects/{projectId}".
+func CompressionMiddleware(minSize int, routeMinSizes map[string]int) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			routeMinSize := minSize
+
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					if size, ok := routeMinSizes[r.Method+" "+template]; ok {
+						routeMinSize = size
+					}
+				}
+			}
+
+			if routeMinSize <= 0 || r.Method == "HEAD" || utils.IsWebsocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// The response depends on Accept-Encoding even if it ends up
+			// not being compressed
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			response := &compressedResponse{
+				ResponseWriter: w,
+				encoding:       encoding,
+				minSize:        routeMinSize,
+			}
+
+			next.ServeHTTP(response, r)
+
+			err := response.close()
+			if err != nil {
+				log.FromContext(r.Context()).WithError(err).Error("Failed to write compressed response")
+			}
+		})
+	}
+}
+
+// Pick the preferred encoding the client accepts, or "" if it accepts none.
+func negotiateEncoding(acceptEncoding string) string {
+	qualities := map[string]float64{}
+
+	for _, entry := range strings.Split(acceptEncoding, ",") {
+		parts := strings.Split(entry, ";")
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if name == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(param[2:], 64)
+				if err == nil {
+					quality = q
+				}
+			}
+		}
+
+		qualities[name] = quality
+	}
+
+	best := ""
+	bestQuality := 0.0
+
+	for _, encoding := range encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+
+		if ok && quality > bestQuality {
+			best = encoding
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// Buffers the beginning of a response until it's known whether it's large
+// enough to be compressed, then either compresses it or writes it as is.
+type compressedResponse struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status int
+	buffer bytes.Buffer
+
+	started bool
+
+	// nil if the response isn't compressed
+	encoder io.WriteCloser
+}
+
+func (r *compressedResponse) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *compressedResponse) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	if r.started {
+		if r.encoder != nil {
+			return r.encoder.Write(data)
+		}
+
+		return r.ResponseWriter.Write(data)
+	}
+
+	r.buffer.Write(data)
+
+	if r.buffer.Len() >= r.minSize {
+		err := r.start(true)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(data), nil
+}
+
+// Write the status and the buffered data, compressed if the response can be
+// compressed and compress is true.
+func (r *compressedResponse) start(compress bool) error {
+	r.started = true
+
+	header := r.Header()
+	if compress && r.compressible() {
+		header.Set("Content-Encoding", r.encoding)
+		header.Del("Content-Length")
+
+		switch r.encoding {
+		case "br":
+			r.encoder = brotli.NewWriterLevel(r.ResponseWriter, brotli.DefaultCompression)
+		case "gzip":
+			r.encoder = gzip.NewWriter(r.ResponseWriter)
+		default:
+			return fmt.Errorf("unknown encoding %q", r.encoding)
+		}
+	}
+
+	r.ResponseWriter.WriteHeader(r.status)
+
+	if r.encoder != nil {
+		_, err := r.encoder.Write(r.buffer.Bytes())
+		return err
+	}
+
+	_, err := r.ResponseWriter.Write(r.buffer.Bytes())
+	return err
+}
+
+// Only successful JSON responses that aren't already encoded are compressed.
+func (r *compressedResponse) compressible() bool {
+	header := r.Header()
+
+	return r.status >= 200 && r.status < 300 && r.status != http.StatusNoContent &&
+		header.Get("Content-Encoding") == "" &&
+		(strings.HasPrefix(header.Get("Content-Type"), "application/json") ||
+			strings.HasPrefix(header.Get("Content-Type"), "application/hal+json"))
+}
+
+// Finish the response. Responses smaller than minSize are written
+// uncompressed.
+func (r *compressedResponse) close() error {
+	if !r.started {
+		if r.status == 0 {
+			// The handler didn't write anything
+			return nil
+		}
+
+		return r.start(false)
+	}
+
+	if r.encoder != nil {
+		return r.encoder.Close()
+	}
+
+	return nil
+}
+
+// Parse route compression thresholds in the format "METHOD /path/template=bytes",
+// separated by commas, e.g. "GET /projects=512,GET /calendar/{token}.ics=0".
+// A threshold of 0 disables compression for the route.
+func ParseRouteCompression(value string) (map[string]int, error) {
+	routeMinSizes := map[string]int{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		separator := strings.LastIndex(entry, "=")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid route compression %q: missing =", entry)
+		}
+
+		size, err := strconv.Atoi(entry[separator+1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid route compression %q: threshold must be a number of bytes", entry)
+		}
+
+		routeMinSizes[strings.TrimSpace(entry[:separator])] = size
+	}
+
+	return routeMinSizes, nil
+}
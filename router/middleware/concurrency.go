@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How important a route is when the server is overloaded. Requests of lower
+// priority routes wait behind and are shed before higher priority ones.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+var priorityNames = map[string]Priority{
+	"low":    PriorityLow,
+	"normal": PriorityNormal,
+	"high":   PriorityHigh,
+}
+
+func (p Priority) String() string {
+	for name, priority := range priorityNames {
+		if priority == p {
+			return name
+		}
+	}
+
+	return strconv.Itoa(int(p))
+}
+
+// Limits how many requests are handled at the same time, to protect the
+// database from traffic spikes. Requests over the limit wait in a queue for
+// up to queueTimeout, highest priority first, and are shed with a 503 and a
+// Retry-After header if the queue is full or they time out. When the queue is
+// full a request can take the place of a queued request of lower priority,
+// which is shed instead.
+type ConcurrencyLimiter struct {
+	maxInFlight  int
+	maxQueued    int
+	queueTimeout time.Duration
+
+	mu       sync.Mutex
+	inFlight int
+	queue    []*queuedRequest
+}
+
+type queuedRequest struct {
+	priority Priority
+
+	// Receives true when the request gets a slot, false when it's shed
+	done chan bool
+}
+
+func NewConcurrencyLimiter(maxInFlight int, maxQueued int, queueTimeout time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		maxInFlight:  maxInFlight,
+		maxQueued:    maxQueued,
+		queueTimeout: queueTimeout,
+	}
+}
+
+// Wait for a slot. Returns false if the request is shed.
+func (l *ConcurrencyLimiter) acquire(priority Priority) bool {
+	l.mu.Lock()
+
+	if l.inFlight < l.maxInFlight {
+		l.inFlight++
+		l.mu.Unlock()
+
+		return true
+	}
+
+	if len(l.queue) >= l.maxQueued {
+		victim := l.lowestQueued()
+		if victim < 0 || l.queue[victim].priority >= priority {
+			l.mu.Unlock()
+
+			return false
+		}
+
+		l.queue[victim].done <- false
+		l.queue = append(l.queue[:victim], l.queue[victim+1:]...)
+	}
+
+	request := &queuedRequest{priority: priority, done: make(chan bool, 1)}
+	l.queue = append(l.queue, request)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case acquired := <-request.done:
+		return acquired
+	case <-timer.C:
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, queued := range l.queue {
+		if queued == request {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+
+			return false
+		}
+	}
+
+	// Got a slot or was shed while timing out
+	return <-request.done
+}
+
+// Free a slot, handing it to the highest priority queued request.
+func (l *ConcurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next := -1
+	for i, queued := range l.queue {
+		if next < 0 || queued.priority > l.queue[next].priority {
+			next = i
+		}
+	}
+
+	if next < 0 {
+		l.inFlight--
+
+		return
+	}
+
+	l.queue[next].done <- true
+	l.queue = append(l.queue[:next], l.queue[next+1:]...)
+}
+
+// The index of the queued request that's shed first: the newest of the lowest
+// priority. -1 if the queue is empty.
+func (l *ConcurrencyLimiter) lowestQueued() int {
+	lowest := -1
+	for i, queued := range l.queue {
+		if lowest < 0 || queued.priority <= l.queue[lowest].priority {
+			lowest = i
+		}
+	}
+
+	return lowest
+}
+
+// Limit the requests handled at the same time with limiter. Routes have
+// PriorityNormal unless routePriorities overrides it, its keys are the route's
+// method and path template, e.g. "GET /projects".
+func ConcurrencyMiddleware(limiter *ConcurrencyLimiter, routePriorities map[string]Priority) mux.MiddlewareFunc {
+	retryAfterSeconds := int((limiter.queueTimeout + time.Second - 1) / time.Second)
+	if retryAfterSeconds < 1 {
+		retryAfterSeconds = 1
+	}
+
+	retryAfter := strconv.Itoa(retryAfterSeconds)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if utils.IsWebsocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			priority := PriorityNormal
+
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					if routePriority, ok := routePriorities[r.Method+" "+template]; ok {
+						priority = routePriority
+					}
+				}
+			}
+
+			if !limiter.acquire(priority) {
+				log.FromContext(r.Context()).WithField("priority", priority.String()).Warn("Request shed, too many requests in flight")
+
+				w.Header().Set("Retry-After", retryAfter)
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+			defer limiter.release()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Parse route priorities in the format "METHOD /path/template=priority",
+// separated by commas, where priority is "low", "normal" or "high", e.g.
+// "GET /projects=low,POST /auth/login=high".
+func ParseRoutePriorities(value string) (map[string]Priority, error) {
+	routePriorities := map[string]Priority{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		separator := strings.LastIndex(entry, "=")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid route priority %q: missing =", entry)
+		}
+
+		priority, ok := priorityNames[strings.TrimSpace(entry[separator+1:])]
+		if !ok {
+			return nil, fmt.Errorf("invalid route priority %q: priority must be low, normal or high", entry)
+		}
+
+		routePriorities[strings.TrimSpace(entry[:separator])] = priority
+	}
+
+	return routePriorities, nil
+}
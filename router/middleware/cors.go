@@ -8,6 +8,8 @@ import (
 // Enables CORS for requests.
 // Only the origins specified in the environment variable CORS_ORIGIN are allowed
 // All methods and all headers are allowed.
+// The X-Impersonated-By, X-Experiments and pagination (see utils.WritePaginationHeaders)
+// headers are exposed to the client.
 func CorsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -23,6 +25,10 @@ func CorsMiddleware(next http.Handler) http.Handler {
 
 		w.Header().Set("Access-Control-Allow-Origin", allowedOrigins)
 
+		// Custom response headers are only visible to the
+		// browser's javascript if they are exposed.
+		w.Header().Set("Access-Control-Expose-Headers", "X-Impersonated-By, X-Experiments, X-Total-Count, X-Page, X-Page-Size, X-Has-Next-Page")
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Applies a deadline to the context of every request. Handlers (and the
+// services they call) must pass the request's context down to gorm and redis,
+// so that queries are cancelled once the deadline is exceeded. Route errors
+// after the deadline is exceeded result in a 504 (see router.handleRouteError).
+//
+// routeTimeouts overrides defaultTimeout for specific routes. Its keys are
+// the route's method and path template, e.g. "GET /projects/{projectId}".
+func TimeoutMiddleware(defaultTimeout time.Duration, routeTimeouts map[string]time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if utils.IsWebsocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			timeout := defaultTimeout
+
+			if route := mux.CurrentRoute(r); route != nil {
+				if template, err := route.GetPathTemplate(); err == nil {
+					if routeTimeout, ok := routeTimeouts[r.Method+" "+template]; ok {
+						timeout = routeTimeout
+					}
+				}
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Parse route timeouts in the format "METHOD /path/template=seconds", separated
+// by commas, e.g. "POST /admin/waitlist/activate=60,GET /projects/search=5".
+func ParseRouteTimeouts(value string) (map[string]time.Duration, error) {
+	routeTimeouts := map[string]time.Duration{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		separator := strings.LastIndex(entry, "=")
+		if separator < 0 {
+			return nil, fmt.Errorf("invalid route timeout %q: missing =", entry)
+		}
+
+		seconds, err := strconv.Atoi(entry[separator+1:])
+		if err != nil || seconds < 1 {
+			return nil, fmt.Errorf("invalid route timeout %q: timeout must be a positive number of seconds", entry)
+		}
+
+		routeTimeouts[strings.TrimSpace(entry[:separator])] = time.Duration(seconds) * time.Second
+	}
+
+	return routeTimeouts, nil
+}
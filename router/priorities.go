@@ -0,0 +1,28 @@
+package router
+
+import "github.com/open-collaboration/server/router/middleware"
+
+// Priorities of routes when requests are queued or shed because too many are
+// in flight, see middleware.ConcurrencyMiddleware. All other routes have
+// normal priority. ROUTE_PRIORITIES overrides entries for specific routes.
+var defaultRoutePriorities = map[string]middleware.Priority{
+	// Listings are the most expensive queries and the bulk of traffic spikes,
+	// they're shed first
+	"GET /projects":                     middleware.PriorityLow,
+	"GET /projects/search":              middleware.PriorityLow,
+	"GET /projects/discover":            middleware.PriorityLow,
+	"GET /projects/{projectId}/similar": middleware.PriorityLow,
+	"GET /collections":                  middleware.PriorityLow,
+	"GET /homepage":                     middleware.PriorityLow,
+
+	// Logged in users keep their sessions and gateways keep validating them
+	"POST /login":                      middleware.PriorityHigh,
+	"POST /auth/login":                 middleware.PriorityHigh,
+	"POST /auth/logout":                middleware.PriorityHigh,
+	"GET /auth/session":                middleware.PriorityHigh,
+	"POST /internal/sessions/validate": middleware.PriorityHigh,
+
+	// Operators can still act during a spike
+	"GET /admin/circuit-breakers":     middleware.PriorityHigh,
+	"POST /admin/sessions/invalidate": middleware.PriorityHigh,
+}
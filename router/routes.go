@@ -9,13 +9,59 @@ import (
 	"github.com/apex/log"
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/analytics"
+	"github.com/open-collaboration/server/applications"
+	"github.com/open-collaboration/server/audit"
 	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/blocklist"
+	"github.com/open-collaboration/server/breaker"
+	"github.com/open-collaboration/server/broadcasts"
+	"github.com/open-collaboration/server/calendar"
+	"github.com/open-collaboration/server/capture"
+	"github.com/open-collaboration/server/cdn"
+	"github.com/open-collaboration/server/chat"
+	"github.com/open-collaboration/server/collections"
+	"github.com/open-collaboration/server/contributions"
+	"github.com/open-collaboration/server/diagnostics"
+	"github.com/open-collaboration/server/directory"
+	"github.com/open-collaboration/server/email"
+	"github.com/open-collaboration/server/emailtemplates"
+	"github.com/open-collaboration/server/experiments"
+	"github.com/open-collaboration/server/exports"
+	"github.com/open-collaboration/server/homepage"
+	"github.com/open-collaboration/server/identities"
+	"github.com/open-collaboration/server/impersonation"
+	"github.com/open-collaboration/server/integrations"
+	"github.com/open-collaboration/server/invites"
+	"github.com/open-collaboration/server/meta"
+	"github.com/open-collaboration/server/mobilepush"
+	"github.com/open-collaboration/server/moderation"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/ogimage"
+	"github.com/open-collaboration/server/portfolio"
+	"github.com/open-collaboration/server/presence"
 	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/readme"
+	"github.com/open-collaboration/server/reports"
+	"github.com/open-collaboration/server/retention"
 	"github.com/open-collaboration/server/router/middleware"
+	"github.com/open-collaboration/server/savedsearches"
+	"github.com/open-collaboration/server/search"
+	"github.com/open-collaboration/server/selfcheck"
+	"github.com/open-collaboration/server/sockpuppets"
+	"github.com/open-collaboration/server/tags"
+	"github.com/open-collaboration/server/undo"
+	"github.com/open-collaboration/server/usage"
 	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/usersettings"
 	"github.com/open-collaboration/server/utils"
+	"github.com/open-collaboration/server/validation"
+	"github.com/open-collaboration/server/waitlist"
+	"github.com/open-collaboration/server/webpush"
 	"net/http"
+	"os"
 	"reflect"
+	"time"
 )
 
 type RouteResponse struct {
@@ -28,18 +74,280 @@ func SetupRoutes(providers []interface{}) *mux.Router {
 	rootRouter := mux.NewRouter()
 
 	rootRouter.Use(middleware.LoggingMiddleware)
+
+	slowRequestThreshold := time.Duration(utils.GetIntEnvOrDefault("SLOW_REQUEST_THRESHOLD_MS", 0)) * time.Millisecond
+	if slowRequestThreshold > 0 {
+		rootRouter.Use(diagnostics.SlowRequestMiddleware(slowRequestThreshold))
+	}
+
+	// Before the timeout, so that queued requests don't use up their time
+	maxInFlight := utils.GetIntEnvOrDefault("MAX_IN_FLIGHT_REQUESTS", 0)
+	if maxInFlight > 0 {
+		routePriorities, err := middleware.ParseRoutePriorities(os.Getenv("ROUTE_PRIORITIES"))
+		if err != nil {
+			log.WithError(err).Error("Failed to parse ROUTE_PRIORITIES")
+			panic("Failed to parse ROUTE_PRIORITIES")
+		}
+
+		for route, priority := range defaultRoutePriorities {
+			if _, ok := routePriorities[route]; !ok {
+				routePriorities[route] = priority
+			}
+		}
+
+		limiter := middleware.NewConcurrencyLimiter(
+			maxInFlight,
+			utils.GetIntEnvOrDefault("MAX_QUEUED_REQUESTS", 100),
+			time.Duration(utils.GetIntEnvOrDefault("REQUEST_QUEUE_TIMEOUT_MS", 2000))*time.Millisecond,
+		)
+		rootRouter.Use(middleware.ConcurrencyMiddleware(limiter, routePriorities))
+	}
+
+	routeTimeouts, err := middleware.ParseRouteTimeouts(os.Getenv("ROUTE_TIMEOUTS"))
+	if err != nil {
+		log.WithError(err).Error("Failed to parse ROUTE_TIMEOUTS")
+		panic("Failed to parse ROUTE_TIMEOUTS")
+	}
+
+	defaultTimeout := time.Duration(utils.GetIntEnvOrDefault("REQUEST_TIMEOUT_SECONDS", 10)) * time.Second
+	rootRouter.Use(middleware.TimeoutMiddleware(defaultTimeout, routeTimeouts))
+
+	rootRouter.Use(middleware.ClientIpMiddleware)
 	rootRouter.Use(middleware.CorsMiddleware)
 
+	startupReport := getProvider(providers, (*selfcheck.Report)(nil)).(*selfcheck.Report)
+	rootRouter.Use(selfcheck.DegradedMiddleware(startupReport))
+
+	routeCompression, err := middleware.ParseRouteCompression(os.Getenv("ROUTE_COMPRESSION"))
+	if err != nil {
+		log.WithError(err).Error("Failed to parse ROUTE_COMPRESSION")
+		panic("Failed to parse ROUTE_COMPRESSION")
+	}
+
+	compressionMinSize := utils.GetIntEnvOrDefault("COMPRESSION_MIN_SIZE", 1024)
+	rootRouter.Use(middleware.CompressionMiddleware(compressionMinSize, routeCompression))
+
 	authService := getProvider(providers, (*auth.Service)(nil)).(auth.Service)
-	rootRouter.Use(auth.SessionMiddleware(authService))
+	sessionFailurePolicy := auth.SessionFailurePolicy(utils.GetEnvOrDefault("SESSION_FAILURE_POLICY", string(auth.SessionFailureReject)))
+	rootRouter.Use(auth.SessionMiddleware(authService, sessionFailurePolicy))
+
+	captureService := getProvider(providers, (*capture.Service)(nil)).(capture.Service)
+	rootRouter.Use(capture.CaptureMiddleware(captureService))
+
+	impersonationService := getProvider(providers, (*impersonation.Service)(nil)).(impersonation.Service)
+	rootRouter.Use(impersonation.ImpersonationMiddleware(impersonationService))
+
+	accountStatusProvider := getProvider(providers, (*auth.AccountStatusProvider)(nil)).(auth.AccountStatusProvider)
+	rootRouter.Use(auth.ReadOnlyMiddleware(accountStatusProvider))
+
+	usageService := getProvider(providers, (*usage.Service)(nil)).(usage.Service)
+	rootRouter.Use(usage.UsageMiddleware(usageService))
+
+	sockpuppetsService := getProvider(providers, (*sockpuppets.Service)(nil)).(sockpuppets.Service)
+	rootRouter.Use(sockpuppets.SightingsMiddleware(sockpuppetsService))
+
+	accessOverrides, err := auth.ParseAccessPolicy(os.Getenv("ANONYMOUS_ACCESS"))
+	if err != nil {
+		log.WithError(err).Error("Failed to parse ANONYMOUS_ACCESS")
+		panic("Failed to parse ANONYMOUS_ACCESS")
+	}
+
+	publicApi := utils.GetEnvOrDefault("PUBLIC_API", "enabled") == "enabled"
+	rootRouter.Use(auth.AnonymousAccessMiddleware(defaultAccessPolicy.With(accessOverrides), publicApi))
+
+	experimentsService := getProvider(providers, (*experiments.Service)(nil)).(experiments.Service)
+	rootRouter.Use(experiments.ExperimentsMiddleware(experimentsService))
+
+	cdnMaxAge := time.Duration(utils.GetIntEnvOrDefault("CDN_CACHE_MAX_AGE_SECONDS", 0)) * time.Second
+	rootRouter.Use(cdn.CacheMiddleware(cdnMaxAge))
 
 	// Setup routes
 	rootRouter.HandleFunc("/users", createRouteHandler(users.RouteRegisterUser, providers)).Methods("POST")
-	rootRouter.HandleFunc("/login", createRouteHandler(auth.RouteAuthenticateUser, providers)).Methods("POST")
+	rootRouter.HandleFunc("/login", createRouteHandler(auth.RouteLogin, providers)).Methods("POST")
+	rootRouter.HandleFunc("/auth/login", createRouteHandler(auth.RouteLogin, providers)).Methods("POST")
+	rootRouter.HandleFunc("/auth/logout", createRouteHandler(auth.RouteLogout, providers)).Methods("POST")
+	rootRouter.HandleFunc("/auth/session", createRouteHandler(auth.RouteGetSession, providers)).Methods("GET")
+	rootRouter.HandleFunc("/internal/sessions/validate", createRouteHandler(auth.RouteValidateSessions, providers)).Methods("POST")
+	rootRouter.HandleFunc("/auth/reauth", createRouteHandler(identities.RouteReauthenticate, providers)).Methods("POST")
+	rootRouter.HandleFunc("/auth/oauth/{provider}/start", createRouteHandler(identities.RouteStartOAuth, providers)).Methods("POST")
+	rootRouter.HandleFunc("/auth/oauth/{provider}/callback", createRouteHandler(identities.RouteCompleteOAuth, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/identities", createRouteHandler(identities.RouteListIdentities, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/identities/{provider}", createRouteHandler(identities.RouteUnlinkIdentity, providers)).Methods("DELETE")
 	rootRouter.HandleFunc("/projects", createRouteHandler(projects.RouteListProjects, providers)).Methods("GET")
 	rootRouter.HandleFunc("/projects", createRouteHandler(projects.RouteCreateProject, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/search", createRouteHandler(search.RouteSearchProjects, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/discover", createRouteHandler(projects.RouteDiscoverProjects, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/import-from-github", createRouteHandler(contributions.RouteImportGithubProject, providers)).Methods("POST")
+	rootRouter.HandleFunc("/licenses", createRouteHandler(projects.RouteListLicenses, providers)).Methods("GET")
+	rootRouter.HandleFunc("/spoken-languages", createRouteHandler(projects.RouteListSpokenLanguages, providers)).Methods("GET")
+	rootRouter.HandleFunc("/skills", createRouteHandler(projects.RouteListTechnologies, providers)).Methods("GET")
 	rootRouter.HandleFunc("/projects/{projectId}", createRouteHandler(projects.RouteUpdateProject, providers)).Methods("POST")
 	rootRouter.HandleFunc("/projects/{projectId}", createRouteHandler(projects.RouteGetProject, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/quality", createRouteHandler(projects.RouteGetProjectQuality, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/similar", createRouteHandler(projects.RouteGetSimilarProjects, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/og-image.png", createRouteHandler(ogimage.RouteGetProjectImage, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/status", createRouteHandler(projects.RouteChangeProjectStatus, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/status-history", createRouteHandler(projects.RouteListProjectStatusChanges, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/tags", createRouteHandler(projects.RouteEditProjectTags, providers)).Methods("PATCH")
+	rootRouter.HandleFunc("/projects/{projectId}/roles/{roleId}/skills", createRouteHandler(projects.RouteEditRoleSkills, providers)).Methods("PATCH")
+	rootRouter.HandleFunc("/projects/{projectId}/clone", createRouteHandler(projects.RouteCloneProject, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/publish", createRouteHandler(projects.RoutePublishProject, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/funding/{fundingLinkId}/clicks", createRouteHandler(projects.RouteRecordFundingClick, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/applications", createRouteHandler(applications.RouteApply, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/applications", createRouteHandler(applications.RouteListProjectApplications, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/applications/{applicationId}/review", createRouteHandler(applications.RouteReviewApplication, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/applications/{applicationId}/interview", createRouteHandler(applications.RouteProposeInterview, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/response-time", createRouteHandler(applications.RouteGetResponseTime, providers)).Methods("GET")
+	rootRouter.HandleFunc("/applications/{applicationId}/interview/schedule", createRouteHandler(applications.RouteScheduleInterview, providers)).Methods("POST")
+	rootRouter.HandleFunc("/applications/{applicationId}/interview", createRouteHandler(applications.RouteCancelInterview, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/users/me/applications", createRouteHandler(applications.RouteListUserApplications, providers)).Methods("GET")
+
+	rootRouter.HandleFunc("/projects/{projectId}/contributions", createRouteHandler(contributions.RouteListProjectContributions, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/contributions", createRouteHandler(contributions.RouteLogContribution, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/contributions/import", createRouteHandler(contributions.RouteImportGithubContributions, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/contributions/{contributionId}", createRouteHandler(contributions.RouteDeleteContribution, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/projects/{projectId}/readme", createRouteHandler(readme.RouteSetSync, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/readme/refresh", createRouteHandler(readme.RouteRefresh, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/{userId}/contributions", createRouteHandler(contributions.RouteListUserContributions, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/reports", createRouteHandler(reports.RouteListReports, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/reports", createRouteHandler(reports.RouteRequestReport, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/reports/{reportId}", createRouteHandler(reports.RouteGetReport, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/reports/{reportId}/download", createRouteHandler(reports.RouteDownloadReport, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/broadcasts", createRouteHandler(broadcasts.RouteListBroadcasts, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/broadcasts", createRouteHandler(broadcasts.RouteCreateBroadcast, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/broadcasts/{broadcastId}", createRouteHandler(broadcasts.RouteGetBroadcast, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/broadcasts/{broadcastId}/cancel", createRouteHandler(broadcasts.RouteCancelBroadcast, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/members", createRouteHandler(projects.RouteListProjectMembers, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/members/{userId}/permissions", createRouteHandler(projects.RouteSetMemberPermissions, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/export", createRouteHandler(exports.RouteExportProject, providers)).Methods("GET")
+	rootRouter.HandleFunc("/presence/socket", createRouteHandler(presence.RouteConnect, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/usage", createRouteHandler(usage.RouteGetMyUsage, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/usage", createRouteHandler(usage.RouteListTopUsers, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/users/{userId}/usage", createRouteHandler(usage.RouteGetUserUsage, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/users/{userId}/usage-quota", createRouteHandler(usage.RouteSetQuota, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/chat", createRouteHandler(chat.RouteGetChannel, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/chat", createRouteHandler(chat.RouteSetChannel, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/chat/messages", createRouteHandler(chat.RouteListMessages, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/chat/messages", createRouteHandler(chat.RoutePostMessage, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/chat/messages/{messageId}", createRouteHandler(chat.RouteDeleteMessage, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/projects/{projectId}/chat/mutes", createRouteHandler(chat.RouteListMutes, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/chat/mutes/{userId}", createRouteHandler(chat.RouteMuteMember, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/chat/mutes/{userId}", createRouteHandler(chat.RouteUnmuteMember, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/projects/{projectId}/chat/socket", createRouteHandler(chat.RouteConnect, providers)).Methods("GET")
+
+	rootRouter.HandleFunc("/undo", createRouteHandler(undo.RouteUndo, providers)).Methods("POST")
+
+	rootRouter.HandleFunc("/analytics/events", createRouteHandler(analytics.RouteRecordEvents, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/funnel", createRouteHandler(analytics.RouteGetProjectFunnel, providers)).Methods("GET")
+
+	rootRouter.HandleFunc("/experiments", createRouteHandler(experiments.RouteGetAssignments, providers)).Methods("GET")
+	rootRouter.HandleFunc("/experiments/{experimentKey}/exposures", createRouteHandler(experiments.RouteRecordExposure, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/experiments", createRouteHandler(experiments.RouteListExperiments, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/experiments", createRouteHandler(experiments.RouteCreateExperiment, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/experiments/{experimentId}", createRouteHandler(experiments.RouteUpdateExperiment, providers)).Methods("PATCH")
+	rootRouter.HandleFunc("/collections", createRouteHandler(collections.RouteListCollections, providers)).Methods("GET")
+	rootRouter.HandleFunc("/collections", createRouteHandler(collections.RouteCreateCollection, providers)).Methods("POST")
+	rootRouter.HandleFunc("/collections/{collectionId}", createRouteHandler(collections.RouteGetCollection, providers)).Methods("GET")
+	rootRouter.HandleFunc("/collections/{collectionId}", createRouteHandler(collections.RouteUpdateCollection, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/collections/{collectionId}", createRouteHandler(collections.RouteDeleteCollection, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/collections/{collectionId}/projects", createRouteHandler(collections.RouteAddProject, providers)).Methods("POST")
+	rootRouter.HandleFunc("/collections/{collectionId}/projects/{projectId}", createRouteHandler(collections.RouteRemoveProject, providers)).Methods("DELETE")
+
+	rootRouter.HandleFunc("/users/me/settings", createRouteHandler(usersettings.RouteGetSettings, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/settings", createRouteHandler(usersettings.RouteUpdateSettings, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/users/me/privacy-settings", createRouteHandler(usersettings.RouteGetPrivacySettings, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/privacy-settings", createRouteHandler(usersettings.RouteUpdatePrivacySettings, providers)).Methods("PUT")
+
+	rootRouter.HandleFunc("/users", createRouteHandler(directory.RouteListUsers, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/directory-profile", createRouteHandler(directory.RouteGetDirectoryProfile, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/directory-profile", createRouteHandler(directory.RouteUpdateDirectoryProfile, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/users/me/portfolio", createRouteHandler(portfolio.RouteCreateItem, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/portfolio/order", createRouteHandler(portfolio.RouteReorderItems, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/portfolio/{itemId}", createRouteHandler(portfolio.RouteUpdateItem, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/users/me/portfolio/{itemId}", createRouteHandler(portfolio.RouteDeleteItem, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/users/{userId}/portfolio", createRouteHandler(portfolio.RouteListItems, providers)).Methods("GET")
+
+	rootRouter.HandleFunc("/projects/{projectId}/events", createRouteHandler(calendar.RouteListEvents, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/events", createRouteHandler(calendar.RouteCreateEvent, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/events/{eventId}", createRouteHandler(calendar.RouteUpdateEvent, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/events/{eventId}", createRouteHandler(calendar.RouteDeleteEvent, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/users/me/calendar-feed", createRouteHandler(calendar.RouteCreateFeed, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/calendar-feed", createRouteHandler(calendar.RouteDeleteFeed, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/calendar/{token}.ics", createRouteHandler(calendar.RouteGetFeed, providers)).Methods("GET")
+
+	rootRouter.HandleFunc("/projects/{projectId}/integrations", createRouteHandler(integrations.RouteListIntegrations, providers)).Methods("GET")
+	rootRouter.HandleFunc("/projects/{projectId}/integrations", createRouteHandler(integrations.RouteCreateIntegration, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/integrations/{integrationId}", createRouteHandler(integrations.RouteUpdateIntegration, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/projects/{projectId}/integrations/{integrationId}", createRouteHandler(integrations.RouteDeleteIntegration, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/projects/{projectId}/integrations/{integrationId}/test", createRouteHandler(integrations.RouteTestIntegration, providers)).Methods("POST")
+	rootRouter.HandleFunc("/projects/{projectId}/announcements", createRouteHandler(integrations.RoutePostAnnouncement, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/saved-searches", createRouteHandler(savedsearches.RouteCreateSavedSearch, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/saved-searches", createRouteHandler(savedsearches.RouteListSavedSearches, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/saved-searches/{savedSearchId}", createRouteHandler(savedsearches.RouteUpdateSavedSearch, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/saved-searches/{savedSearchId}", createRouteHandler(savedsearches.RouteDeleteSavedSearch, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/notifications", createRouteHandler(notifications.RouteListNotifications, providers)).Methods("GET")
+	rootRouter.HandleFunc("/notifications/{notificationId}/read", createRouteHandler(notifications.RouteMarkAsRead, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/notification-preferences", createRouteHandler(notifications.RouteGetPreferences, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/notification-preferences", createRouteHandler(notifications.RouteUpdatePreferences, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/push/public-key", createRouteHandler(webpush.RouteGetPublicKey, providers)).Methods("GET")
+	rootRouter.HandleFunc("/users/me/push-subscriptions", createRouteHandler(webpush.RouteSubscribe, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/push-subscriptions/{subscriptionId}", createRouteHandler(webpush.RouteUnsubscribe, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/users/me/devices", createRouteHandler(mobilepush.RouteRegisterDevice, providers)).Methods("POST")
+	rootRouter.HandleFunc("/users/me/devices/{deviceId}", createRouteHandler(mobilepush.RouteUnregisterDevice, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/moderation/tags/rename", createRouteHandler(tags.RouteRenameTag, providers)).Methods("POST")
+	rootRouter.HandleFunc("/moderation/tags/merge", createRouteHandler(tags.RouteMergeTags, providers)).Methods("POST")
+	rootRouter.HandleFunc("/moderation/tags/banned", createRouteHandler(tags.RouteListBannedTags, providers)).Methods("GET")
+	rootRouter.HandleFunc("/moderation/tags/banned", createRouteHandler(tags.RouteBanTag, providers)).Methods("POST")
+	rootRouter.HandleFunc("/moderation/tags/banned/{tag}", createRouteHandler(tags.RouteUnbanTag, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/homepage", createRouteHandler(homepage.RouteGetHomepage, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/homepage/sections", createRouteHandler(homepage.RouteListSections, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/homepage/sections", createRouteHandler(homepage.RouteCreateSection, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/homepage/sections/order", createRouteHandler(homepage.RouteReorderSections, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/homepage/sections/{sectionId}", createRouteHandler(homepage.RouteUpdateSection, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/homepage/sections/{sectionId}", createRouteHandler(homepage.RouteDeleteSection, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/admin/homepage/featured", createRouteHandler(homepage.RouteListFeaturedProjects, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/homepage/featured", createRouteHandler(homepage.RouteFeatureProject, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/homepage/featured/order", createRouteHandler(homepage.RouteReorderFeaturedProjects, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/homepage/featured/{projectId}", createRouteHandler(homepage.RouteUnfeatureProject, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/invites", createRouteHandler(invites.RouteCreateInvite, providers)).Methods("POST")
+	rootRouter.HandleFunc("/invites", createRouteHandler(invites.RouteListUserInvites, providers)).Methods("GET")
+	rootRouter.HandleFunc("/invites/quota", createRouteHandler(invites.RouteGetInviteQuota, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/invites", createRouteHandler(invites.RouteListInvites, providers)).Methods("GET")
+	rootRouter.HandleFunc("/waitlist", createRouteHandler(waitlist.RouteJoinWaitlist, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/waitlist", createRouteHandler(waitlist.RouteListEntries, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/waitlist/activate", createRouteHandler(waitlist.RouteActivateBatch, providers)).Methods("POST")
+	rootRouter.HandleFunc("/moderation/users/{userId}/restrictions", createRouteHandler(moderation.RouteRestrictUser, providers)).Methods("POST")
+	rootRouter.HandleFunc("/moderation/users/{userId}/restrictions", createRouteHandler(moderation.RouteListRestrictions, providers)).Methods("GET")
+	rootRouter.HandleFunc("/moderation/restrictions/{restrictionId}", createRouteHandler(moderation.RouteLiftRestriction, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/moderation/sockpuppets", createRouteHandler(sockpuppets.RouteListSuspects, providers)).Methods("GET")
+	rootRouter.HandleFunc("/moderation/users/{userId}/sockpuppets", createRouteHandler(sockpuppets.RouteListUserSuspects, providers)).Methods("GET")
+	rootRouter.HandleFunc("/moderation/users/{userId}/sockpuppets/{otherUserId}/review", createRouteHandler(sockpuppets.RouteReviewSuspect, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/moderation/portfolio-items/{itemId}/hidden", createRouteHandler(portfolio.RouteHideItem, providers)).Methods("PUT")
+	rootRouter.HandleFunc("/moderation/portfolio-items/{itemId}/hidden", createRouteHandler(portfolio.RouteUnhideItem, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/moderation/portfolio-items/pending", createRouteHandler(portfolio.RouteListPendingItems, providers)).Methods("GET")
+	rootRouter.HandleFunc("/moderation/portfolio-items/{itemId}/approve", createRouteHandler(portfolio.RouteApproveItem, providers)).Methods("POST")
+	rootRouter.HandleFunc("/moderation/applications/flagged", createRouteHandler(applications.RouteListFlaggedApplications, providers)).Methods("GET")
+	rootRouter.HandleFunc("/moderation/projects/pending", createRouteHandler(projects.RouteListPendingProjects, providers)).Methods("GET")
+	rootRouter.HandleFunc("/moderation/projects/{projectId}/approve", createRouteHandler(projects.RouteApproveProject, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/debug/capture", createRouteHandler(capture.RouteStartCapture, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/debug/capture", createRouteHandler(capture.RouteStopCapture, providers)).Methods("DELETE")
+	rootRouter.HandleFunc("/admin/debug/capture", createRouteHandler(capture.RouteGetCapture, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/debug/snapshots", createRouteHandler(diagnostics.RouteCreateSnapshot, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/circuit-breakers", createRouteHandler(breaker.RouteListBreakers, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/retention", createRouteHandler(retention.RouteListPolicies, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/email-templates", createRouteHandler(emailtemplates.RouteListTemplates, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/email-templates/{template}/preview", createRouteHandler(emailtemplates.RoutePreviewTemplate, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/email-templates/{template}/test", createRouteHandler(emailtemplates.RouteSendTestEmail, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/audit-log", createRouteHandler(audit.RouteListEntries, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/users/{userId}/sessions/invalidate", createRouteHandler(auth.RouteInvalidateUserSessions, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/sessions/invalidate", createRouteHandler(auth.RouteInvalidateAllSessions, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/impersonations", createRouteHandler(impersonation.RouteStartImpersonation, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/blocklist", createRouteHandler(blocklist.RouteListEntries, providers)).Methods("GET")
+	rootRouter.HandleFunc("/admin/blocklist", createRouteHandler(blocklist.RouteAddEntry, providers)).Methods("POST")
+	rootRouter.HandleFunc("/admin/blocklist/{entryId}", createRouteHandler(blocklist.RouteRemoveEntry, providers)).Methods("DELETE")
+
+	rootRouter.HandleFunc("/health/startup", createRouteHandler(selfcheck.RouteGetReport, providers)).Methods("GET")
+	rootRouter.HandleFunc("/meta", createRouteHandler(meta.RouteGetMeta, providers)).Methods("GET")
 
 	// Swagger
 	swaggerUi := http.FileServer(http.Dir("swagger-ui/"))
@@ -49,7 +357,7 @@ func SetupRoutes(providers []interface{}) *mux.Router {
 		Methods("GET")
 
 	// Log routes
-	err := rootRouter.Walk(logRouteDeclaration)
+	err = rootRouter.Walk(logRouteDeclaration)
 	if err != nil {
 		log.WithError(err).Error("Failed to log routes")
 		panic("Failed to log routes")
@@ -111,11 +419,320 @@ func handleRouteError(writer http.ResponseWriter, ctx context.Context, routeErr
 
 		var status int
 
+		// Whatever the error is, it's most likely caused by the request's
+		// deadline (see middleware.TimeoutMiddleware) being exceeded.
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.WithError(routeErr).Warn("Request deadline exceeded")
+
+			routeErr = context.DeadlineExceeded
+		}
+
 		switch e := routeErr.(type) {
 		default:
-			if errors.Is(routeErr, auth.ErrUnauthenticated) {
+			if errors.Is(routeErr, context.DeadlineExceeded) {
+				status = http.StatusGatewayTimeout
+				code = "timeout-error"
+			} else if errors.Is(routeErr, breaker.ErrOpen) {
+				status = http.StatusServiceUnavailable
+				code = "service-unavailable-error"
+			} else if errors.Is(routeErr, auth.ErrUnauthenticated) || errors.Is(routeErr, auth.ErrInvalidSessionToken) {
 				status = http.StatusUnauthorized
 				code = "unauthenticated-error"
+			} else if errors.Is(routeErr, auth.ErrForbidden) {
+				status = http.StatusForbidden
+				code = "forbidden-error"
+			} else if errors.Is(routeErr, utils.ErrInvalidParam) {
+				status = http.StatusBadRequest
+				code = "invalid-param-error"
+			} else if errors.Is(routeErr, invites.ErrInvalidInviteCode) {
+				status = http.StatusForbidden
+				code = "invalid-invite-code-error"
+			} else if errors.Is(routeErr, invites.ErrInviteQuotaExceeded) {
+				status = http.StatusForbidden
+				code = "invite-quota-exceeded-error"
+			} else if errors.Is(routeErr, waitlist.ErrInvalidSignupToken) {
+				status = http.StatusForbidden
+				code = "invalid-signup-token-error"
+			} else if errors.Is(routeErr, auth.ErrWrongPassword) {
+				status = http.StatusUnauthorized
+				code = "wrong-password-error"
+			} else if errors.Is(routeErr, auth.ErrInvalidCredentials) {
+				status = http.StatusUnauthorized
+				code = "invalid-credentials-error"
+			} else if errors.Is(routeErr, users.ErrUsernameTaken) {
+				status = http.StatusConflict
+				code = "username-taken-error"
+			} else if errors.Is(routeErr, users.ErrEmailTaken) {
+				status = http.StatusConflict
+				code = "email-taken-error"
+			} else if errors.Is(routeErr, users.ErrInvalidTimezone) {
+				status = http.StatusBadRequest
+				code = "invalid-timezone-error"
+			} else if errors.Is(routeErr, users.ErrInvalidLocale) {
+				status = http.StatusBadRequest
+				code = "invalid-locale-error"
+			} else if errors.Is(routeErr, users.ErrInvalidAvailability) {
+				status = http.StatusBadRequest
+				code = "invalid-availability-error"
+			} else if errors.Is(routeErr, identities.ErrReauthRequired) {
+				status = http.StatusForbidden
+				code = "reauth-required-error"
+			} else if errors.Is(routeErr, identities.ErrLastAuthMethod) {
+				status = http.StatusConflict
+				code = "last-auth-method-error"
+			} else if errors.Is(routeErr, identities.ErrIdentityAlreadyLinked) {
+				status = http.StatusConflict
+				code = "identity-already-linked-error"
+			} else if errors.Is(routeErr, identities.ErrProviderAlreadyLinked) {
+				status = http.StatusConflict
+				code = "provider-already-linked-error"
+			} else if errors.Is(routeErr, identities.ErrIdentityNotLinked) {
+				status = http.StatusNotFound
+				code = "identity-not-linked-error"
+			} else if errors.Is(routeErr, identities.ErrUnknownProvider) {
+				status = http.StatusNotFound
+				code = "unknown-provider-error"
+			} else if errors.Is(routeErr, identities.ErrInvalidOAuthState) {
+				status = http.StatusBadRequest
+				code = "invalid-oauth-state-error"
+			} else if errors.Is(routeErr, impersonation.ErrCannotImpersonate) {
+				status = http.StatusForbidden
+				code = "cannot-impersonate-error"
+			} else if errors.Is(routeErr, users.ErrUserNotFound) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, auth.ErrAccountReadOnly) {
+				status = http.StatusForbidden
+				code = "account-read-only-error"
+			} else if errors.Is(routeErr, auth.ErrPostingCooldown) {
+				status = http.StatusTooManyRequests
+				code = "posting-cooldown-error"
+			} else if errors.Is(routeErr, moderation.ErrRestrictionNotFound) || errors.Is(routeErr, projects.ErrProjectNotFound) || errors.Is(routeErr, projects.ErrRoleNotFound) || errors.Is(routeErr, projects.ErrQuestionNotFound) || errors.Is(routeErr, projects.ErrFundingLinkNotFound) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, applications.ErrApplicationNotFound) || errors.Is(routeErr, notifications.ErrNotificationNotFound) || errors.Is(routeErr, webpush.ErrSubscriptionNotFound) || errors.Is(routeErr, mobilepush.ErrDeviceNotFound) || errors.Is(routeErr, integrations.ErrIntegrationNotFound) || errors.Is(routeErr, calendar.ErrEventNotFound) || errors.Is(routeErr, calendar.ErrFeedNotFound) || errors.Is(routeErr, contributions.ErrContributionNotFound) || errors.Is(routeErr, collections.ErrCollectionNotFound) || errors.Is(routeErr, collections.ErrProjectNotInCollection) || errors.Is(routeErr, reports.ErrReportNotFound) || errors.Is(routeErr, broadcasts.ErrBroadcastNotFound) || errors.Is(routeErr, experiments.ErrExperimentNotFound) || errors.Is(routeErr, chat.ErrMessageNotFound) || errors.Is(routeErr, exports.ErrExportNotFound) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, applications.ErrAlreadyApplied) {
+				status = http.StatusConflict
+				code = "already-applied-error"
+			} else if errors.Is(routeErr, applications.ErrOwnProject) {
+				status = http.StatusBadRequest
+				code = "own-project-error"
+			} else if errors.Is(routeErr, applications.ErrApplicationThrottled) {
+				status = http.StatusTooManyRequests
+				code = "application-throttled-error"
+			} else if errors.Is(routeErr, applications.ErrInvalidInterviewTransition) {
+				status = http.StatusConflict
+				code = "invalid-interview-transition-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, applications.ErrInvalidInterviewTime) {
+				status = http.StatusBadRequest
+				code = "invalid-interview-time-error"
+			} else if errors.Is(routeErr, applications.ErrSlotNotFound) {
+				status = http.StatusBadRequest
+				code = "slot-not-found-error"
+			} else if errors.Is(routeErr, contributions.ErrNotMember) {
+				status = http.StatusBadRequest
+				code = "not-member-error"
+			} else if errors.Is(routeErr, contributions.ErrNotGithubRepository) {
+				status = http.StatusBadRequest
+				code = "not-github-repository-error"
+			} else if errors.Is(routeErr, contributions.ErrReadmeNotFound) {
+				status = http.StatusBadRequest
+				code = "readme-not-found-error"
+			} else if errors.Is(routeErr, readme.ErrSyncDisabled) {
+				status = http.StatusConflict
+				code = "readme-sync-disabled-error"
+			} else if errors.Is(routeErr, analytics.ErrFunnelUnavailable) {
+				status = http.StatusNotImplemented
+				code = "analytics-unavailable-error"
+			} else if errors.Is(routeErr, collections.ErrJoinWindowClosed) {
+				status = http.StatusConflict
+				code = "join-window-closed-error"
+			} else if errors.Is(routeErr, collections.ErrInvalidDates) {
+				status = http.StatusBadRequest
+				code = "invalid-dates-error"
+			} else if errors.Is(routeErr, reports.ErrReportNotReady) {
+				status = http.StatusConflict
+				code = "report-not-ready-error"
+			} else if errors.Is(routeErr, exports.ErrExportNotReady) {
+				status = http.StatusConflict
+				code = "export-not-ready-error"
+			} else if errors.Is(routeErr, exports.ErrUnknownFormat) {
+				status = http.StatusBadRequest
+				code = "unknown-format-error"
+			} else if errors.Is(routeErr, reports.ErrInvalidRange) {
+				status = http.StatusBadRequest
+				code = "invalid-range-error"
+			} else if errors.Is(routeErr, broadcasts.ErrBroadcastFinished) {
+				status = http.StatusConflict
+				code = "broadcast-finished-error"
+			} else if errors.Is(routeErr, broadcasts.ErrInvalidAudience) {
+				status = http.StatusBadRequest
+				code = "invalid-audience-error"
+			} else if errors.Is(routeErr, usage.ErrInvalidDay) {
+				status = http.StatusBadRequest
+				code = "invalid-day-error"
+			} else if errors.Is(routeErr, chat.ErrChatDisabled) {
+				status = http.StatusNotFound
+				code = "chat-disabled-error"
+			} else if errors.Is(routeErr, chat.ErrMuted) {
+				status = http.StatusForbidden
+				code = "chat-muted-error"
+			} else if errors.Is(routeErr, chat.ErrNotMember) || errors.Is(routeErr, projects.ErrNotMember) {
+				status = http.StatusBadRequest
+				code = "not-member-error"
+			} else if errors.Is(routeErr, chat.ErrCannotMuteOwner) {
+				status = http.StatusBadRequest
+				code = "cannot-mute-owner-error"
+			} else if errors.Is(routeErr, undo.ErrInvalidToken) {
+				status = http.StatusGone
+				code = "invalid-undo-token-error"
+			} else if errors.Is(routeErr, projects.ErrOwnerPermissions) {
+				status = http.StatusBadRequest
+				code = "owner-permissions-error"
+			} else if errors.Is(routeErr, sockpuppets.ErrSameAccount) {
+				status = http.StatusBadRequest
+				code = "same-account-error"
+			} else if errors.Is(routeErr, experiments.ErrKeyTaken) {
+				status = http.StatusConflict
+				code = "experiment-key-taken-error"
+			} else if errors.Is(routeErr, experiments.ErrInvalidKey) {
+				status = http.StatusBadRequest
+				code = "invalid-experiment-key-error"
+			} else if errors.Is(routeErr, experiments.ErrInvalidWeights) {
+				status = http.StatusBadRequest
+				code = "invalid-weights-error"
+			} else if errors.Is(routeErr, experiments.ErrNoSubject) {
+				status = http.StatusBadRequest
+				code = "no-subject-error"
+			} else if errors.Is(routeErr, applications.ErrRoleNotFound) {
+				status = http.StatusBadRequest
+				code = "role-not-found-error"
+			} else if errors.Is(routeErr, applications.ErrRoleRequired) {
+				status = http.StatusBadRequest
+				code = "role-required-error"
+			} else if errors.Is(routeErr, applications.ErrInvalidAnswers) {
+				status = http.StatusBadRequest
+				code = "invalid-answers-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, blocklist.ErrBlocked) {
+				status = http.StatusForbidden
+				code = "blocked-error"
+			} else if errors.Is(routeErr, blocklist.ErrInvalidEntry) {
+				status = http.StatusBadRequest
+				code = "invalid-blocklist-entry-error"
+			} else if errors.Is(routeErr, blocklist.ErrEntryNotFound) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, savedsearches.ErrSavedSearchNotFound) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, savedsearches.ErrTooManySavedSearches) {
+				status = http.StatusConflict
+				code = "too-many-saved-searches-error"
+			} else if errors.Is(routeErr, homepage.ErrSectionNotFound) || errors.Is(routeErr, homepage.ErrProjectNotFeatured) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, homepage.ErrProjectAlreadyFeatured) {
+				status = http.StatusConflict
+				code = "project-already-featured-error"
+			} else if errors.Is(routeErr, portfolio.ErrItemNotFound) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, portfolio.ErrTooManyItems) {
+				status = http.StatusConflict
+				code = "too-many-portfolio-items-error"
+			} else if errors.Is(routeErr, portfolio.ErrInvalidLink) {
+				status = http.StatusBadRequest
+				code = "invalid-portfolio-link-error"
+			} else if errors.Is(routeErr, homepage.ErrInvalidOrder) || errors.Is(routeErr, portfolio.ErrInvalidOrder) {
+				status = http.StatusBadRequest
+				code = "invalid-order-error"
+			} else if errors.Is(routeErr, projects.ErrTagBanned) {
+				status = http.StatusBadRequest
+				code = "tag-banned-error"
+				details["message"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrTagNotBanned) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, capture.ErrCaptureDisabled) {
+				status = http.StatusBadRequest
+				code = "capture-disabled-error"
+			} else if errors.Is(routeErr, notifications.ErrUnknownNotificationType) {
+				status = http.StatusBadRequest
+				code = "unknown-notification-type-error"
+			} else if errors.Is(routeErr, notifications.ErrUnknownChannel) {
+				status = http.StatusBadRequest
+				code = "unknown-notification-channel-error"
+			} else if errors.Is(routeErr, webpush.ErrPushDisabled) {
+				status = http.StatusBadRequest
+				code = "push-disabled-error"
+			} else if errors.Is(routeErr, mobilepush.ErrPlatformDisabled) {
+				status = http.StatusBadRequest
+				code = "push-disabled-error"
+			} else if errors.Is(routeErr, webpush.ErrInvalidKey) || errors.Is(routeErr, webpush.ErrInvalidEndpoint) {
+				status = http.StatusBadRequest
+				code = "invalid-push-subscription-error"
+			} else if errors.Is(routeErr, projects.ErrUnknownLicense) {
+				status = http.StatusBadRequest
+				code = "unknown-license-error"
+			} else if errors.Is(routeErr, projects.ErrUnknownSpokenLanguage) {
+				status = http.StatusBadRequest
+				code = "unknown-spoken-language-error"
+			} else if errors.Is(routeErr, projects.ErrInvalidFundingLink) {
+				status = http.StatusBadRequest
+				code = "invalid-funding-link-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrInvalidExternalLink) {
+				status = http.StatusBadRequest
+				code = "invalid-external-link-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrUnknownStatus) {
+				status = http.StatusBadRequest
+				code = "unknown-status-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrUnknownSeniority) {
+				status = http.StatusBadRequest
+				code = "unknown-seniority-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrTagCount) {
+				status = http.StatusBadRequest
+				code = "tag-count-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrSkillCount) {
+				status = http.StatusBadRequest
+				code = "skill-count-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrInvalidStatusTransition) {
+				status = http.StatusConflict
+				code = "invalid-status-transition-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, projects.ErrUnknownTechnology) {
+				status = http.StatusBadRequest
+				code = "unknown-technology-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, calendar.ErrInvalidEventTime) {
+				status = http.StatusBadRequest
+				code = "invalid-event-time-error"
+			} else if errors.Is(routeErr, integrations.ErrInvalidWebhookUrl) {
+				status = http.StatusBadRequest
+				code = "invalid-webhook-url-error"
+			} else if errors.Is(routeErr, integrations.ErrWebhookFailed) {
+				status = http.StatusBadRequest
+				code = "webhook-failed-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, email.ErrTemplateNotFound) {
+				status = http.StatusNotFound
+				code = "not-found-error"
+			} else if errors.Is(routeErr, email.ErrInvalidTemplate) || errors.Is(routeErr, broadcasts.ErrInvalidTemplate) {
+				status = http.StatusBadRequest
+				code = "invalid-template-error"
+				details["error"] = routeErr.Error()
+			} else if errors.Is(routeErr, search.ErrSemanticSearchDisabled) {
+				status = http.StatusBadRequest
+				code = "semantic-search-disabled-error"
 			} else {
 				status = http.StatusInternalServerError
 			}
@@ -136,6 +753,11 @@ func handleRouteError(writer http.ResponseWriter, ctx context.Context, routeErr
 				details[fieldError.StructField()] = fieldError.Tag()
 			}
 			status = http.StatusBadRequest
+
+		case validation.Errors:
+			code = "rule-violation-error"
+			details["violations"] = e
+			status = http.StatusBadRequest
 		}
 
 		err := utils.WriteJson(writer, ctx, status, map[string]interface{}{
@@ -0,0 +1,120 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: savedSearchesService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	projects "github.com/open-collaboration/server/projects"
+	savedsearches "github.com/open-collaboration/server/savedsearches"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateSavedSearch mocks base method
+func (m *MockService) CreateSavedSearch(ctx context.Context, userId uint, dto savedsearches.NewSavedSearchDto) (savedsearches.SavedSearchDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSavedSearch", ctx, userId, dto)
+	ret0, _ := ret[0].(savedsearches.SavedSearchDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSavedSearch indicates an expected call of CreateSavedSearch
+func (mr *MockServiceMockRecorder) CreateSavedSearch(ctx, userId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSavedSearch", reflect.TypeOf((*MockService)(nil).CreateSavedSearch), ctx, userId, dto)
+}
+
+// ListSavedSearches mocks base method
+func (m *MockService) ListSavedSearches(ctx context.Context, userId uint) ([]savedsearches.SavedSearchDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSavedSearches", ctx, userId)
+	ret0, _ := ret[0].([]savedsearches.SavedSearchDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSavedSearches indicates an expected call of ListSavedSearches
+func (mr *MockServiceMockRecorder) ListSavedSearches(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSavedSearches", reflect.TypeOf((*MockService)(nil).ListSavedSearches), ctx, userId)
+}
+
+// UpdateSavedSearch mocks base method
+func (m *MockService) UpdateSavedSearch(ctx context.Context, userId, savedSearchId uint, dto savedsearches.NewSavedSearchDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSavedSearch", ctx, userId, savedSearchId, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSavedSearch indicates an expected call of UpdateSavedSearch
+func (mr *MockServiceMockRecorder) UpdateSavedSearch(ctx, userId, savedSearchId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSavedSearch", reflect.TypeOf((*MockService)(nil).UpdateSavedSearch), ctx, userId, savedSearchId, dto)
+}
+
+// DeleteSavedSearch mocks base method
+func (m *MockService) DeleteSavedSearch(ctx context.Context, userId, savedSearchId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSavedSearch", ctx, userId, savedSearchId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSavedSearch indicates an expected call of DeleteSavedSearch
+func (mr *MockServiceMockRecorder) DeleteSavedSearch(ctx, userId, savedSearchId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSavedSearch", reflect.TypeOf((*MockService)(nil).DeleteSavedSearch), ctx, userId, savedSearchId)
+}
+
+// MergeTags mocks base method
+func (m *MockService) MergeTags(ctx context.Context, sources []string, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", ctx, sources, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeTags indicates an expected call of MergeTags
+func (mr *MockServiceMockRecorder) MergeTags(ctx, sources, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockService)(nil).MergeTags), ctx, sources, target)
+}
+
+// ProjectSaved mocks base method
+func (m *MockService) ProjectSaved(ctx context.Context, project *projects.Project) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ProjectSaved", ctx, project)
+}
+
+// ProjectSaved indicates an expected call of ProjectSaved
+func (mr *MockServiceMockRecorder) ProjectSaved(ctx, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectSaved", reflect.TypeOf((*MockService)(nil).ProjectSaved), ctx, project)
+}
@@ -0,0 +1,22 @@
+package savedsearches
+
+import (
+	"github.com/lib/pq"
+	"time"
+)
+
+type NewSavedSearchDto struct {
+	Name          string   `json:"name" validate:"required,max=64"`
+	Query         string   `json:"query" validate:"max=200"`
+	Tags          []string `json:"tags" validate:"max=20,dive,max=32"`
+	NotifyByEmail bool     `json:"notifyByEmail"`
+}
+
+type SavedSearchDto struct {
+	Id            uint           `json:"id"`
+	Name          string         `json:"name"`
+	Query         string         `json:"query"`
+	Tags          pq.StringArray `json:"tags" swaggertype:"array,string"`
+	NotifyByEmail bool           `json:"notifyByEmail"`
+	CreatedAt     time.Time      `json:"createdAt"`
+}
@@ -0,0 +1,34 @@
+package savedsearches
+
+import (
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// A search saved by a user. The user is notified whenever a new project
+// matches it.
+type SavedSearch struct {
+	gorm.Model
+
+	UserId uint
+	Name   string
+
+	// Keywords matched against the project's name and descriptions. Empty
+	// matches every project.
+	Query string
+
+	// A project matches if it has at least one of the tags. Empty matches
+	// every project.
+	Tags pq.StringArray `gorm:"type: TEXT[]"`
+
+	// Also send an email, not only an in-app notification. Unless the user
+	// disabled saved search emails in their notification preferences.
+	NotifyByEmail bool
+}
+
+// A project that matched a saved search. Used to notify the search's
+// owner only once per project, even if the project is saved again.
+type SavedSearchMatch struct {
+	SavedSearchId uint `gorm:"primaryKey"`
+	ProjectId     uint `gorm:"primaryKey"`
+}
@@ -0,0 +1,117 @@
+package savedsearches
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Save a search
+// @Description The user is notified whenever a new project matches the search.
+// @Tags saved-searches
+// @Router /users/me/saved-searches [post]
+// @Param savedSearch body savedsearches.NewSavedSearchDto true "The search"
+// @Success 201 {object} savedsearches.SavedSearchDto
+// @Failure 401
+// @Failure 409 "The user already has the maximum amount of saved searches"
+func RouteCreateSavedSearch(writer http.ResponseWriter, request *http.Request, savedSearchesService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := NewSavedSearchDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	savedSearch, err := savedSearchesService.CreateSavedSearch(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, savedSearch)
+}
+
+// @Summary List the current user's saved searches
+// @Tags saved-searches
+// @Router /users/me/saved-searches [get]
+// @Success 200 {array} savedsearches.SavedSearchDto
+// @Failure 401
+func RouteListSavedSearches(writer http.ResponseWriter, request *http.Request, savedSearchesService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	savedSearches, err := savedSearchesService.ListSavedSearches(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, savedSearches)
+}
+
+// @Summary Update a saved search
+// @Tags saved-searches
+// @Router /users/me/saved-searches/{savedSearchId} [post]
+// @Param savedSearchId path int true "The saved search ID"
+// @Param savedSearch body savedsearches.NewSavedSearchDto true "The search"
+// @Success 200
+// @Failure 401
+// @Failure 404
+func RouteUpdateSavedSearch(writer http.ResponseWriter, request *http.Request, savedSearchesService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	savedSearchId, err := utils.UintFromVars(request, "savedSearchId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewSavedSearchDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = savedSearchesService.UpdateSavedSearch(request.Context(), session.UserId(), savedSearchId, dto)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// @Summary Delete a saved search
+// @Tags saved-searches
+// @Router /users/me/saved-searches/{savedSearchId} [delete]
+// @Param savedSearchId path int true "The saved search ID"
+// @Success 204
+// @Failure 401
+// @Failure 404
+func RouteDeleteSavedSearch(writer http.ResponseWriter, request *http.Request, savedSearchesService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	savedSearchId, err := utils.UintFromVars(request, "savedSearchId")
+	if err != nil {
+		return err
+	}
+
+	err = savedSearchesService.DeleteSavedSearch(request.Context(), session.UserId(), savedSearchId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
@@ -0,0 +1,330 @@
+package savedsearches
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/email"
+	"github.com/open-collaboration/server/notifications"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+)
+
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+var ErrTooManySavedSearches = errors.New("too many saved searches")
+
+// Maximum amount of saved searches per user.
+const maxSavedSearches = 20
+
+type Service interface {
+	// Save a search for a user.
+	// Returns ErrTooManySavedSearches if the user already has the maximum
+	// amount of saved searches.
+	CreateSavedSearch(ctx context.Context, userId uint, dto NewSavedSearchDto) (SavedSearchDto, error)
+
+	// List a user's saved searches, oldest to newest.
+	ListSavedSearches(ctx context.Context, userId uint) ([]SavedSearchDto, error)
+
+	// Update one of a user's saved searches.
+	// Returns ErrSavedSearchNotFound if the user doesn't have the saved search.
+	UpdateSavedSearch(ctx context.Context, userId uint, savedSearchId uint, dto NewSavedSearchDto) error
+
+	// Delete one of a user's saved searches.
+	// Returns ErrSavedSearchNotFound if the user doesn't have the saved search.
+	DeleteSavedSearch(ctx context.Context, userId uint, savedSearchId uint) error
+
+	// Replace the source tags with the target tag in all saved searches, used
+	// when tags are merged.
+	MergeTags(ctx context.Context, sources []string, target string) error
+
+	// Match a saved project against all saved searches in the background and
+	// notify the owners of the searches it matches for the first time.
+	// Projects pending review are not matched until they are approved.
+	ProjectSaved(ctx context.Context, project *projects.Project)
+}
+
+type serviceImpl struct {
+	Db                   *gorm.DB
+	NotificationsService notifications.Service
+	UsersService         users.Service
+	EmailSender          email.Sender
+	EmailTemplates       *email.Templates
+	FrontendUrl          string
+}
+
+func NewService(
+	db *gorm.DB,
+	notificationsService notifications.Service,
+	usersService users.Service,
+	emailSender email.Sender,
+	emailTemplates *email.Templates,
+	frontendUrl string,
+) Service {
+	return &serviceImpl{
+		Db:                   db,
+		NotificationsService: notificationsService,
+		UsersService:         usersService,
+		EmailSender:          emailSender,
+		EmailTemplates:       emailTemplates,
+		FrontendUrl:          frontendUrl,
+	}
+}
+
+func (s *serviceImpl) CreateSavedSearch(ctx context.Context, userId uint, dto NewSavedSearchDto) (SavedSearchDto, error) {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	var count int64
+	result := s.Db.WithContext(ctx).Model(&SavedSearch{}).Where("user_id = ?", userId).Count(&count)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to count saved searches")
+
+		return SavedSearchDto{}, result.Error
+	}
+
+	if count >= maxSavedSearches {
+		return SavedSearchDto{}, ErrTooManySavedSearches
+	}
+
+	tags := dto.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	savedSearch := SavedSearch{
+		UserId:        userId,
+		Name:          dto.Name,
+		Query:         dto.Query,
+		Tags:          tags,
+		NotifyByEmail: dto.NotifyByEmail,
+	}
+
+	result = s.Db.WithContext(ctx).Create(&savedSearch)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to create saved search")
+
+		return SavedSearchDto{}, result.Error
+	}
+
+	return savedSearchToDto(savedSearch), nil
+}
+
+func (s *serviceImpl) ListSavedSearches(ctx context.Context, userId uint) ([]SavedSearchDto, error) {
+	var savedSearches []SavedSearch
+	result := s.Db.WithContext(ctx).Where("user_id = ?", userId).Order("created_at asc").Find(&savedSearches)
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list saved searches")
+
+		return nil, result.Error
+	}
+
+	dtos := make([]SavedSearchDto, len(savedSearches))
+	for i, savedSearch := range savedSearches {
+		dtos[i] = savedSearchToDto(savedSearch)
+	}
+
+	return dtos, nil
+}
+
+func (s *serviceImpl) UpdateSavedSearch(ctx context.Context, userId uint, savedSearchId uint, dto NewSavedSearchDto) error {
+	tags := dto.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	result := s.Db.WithContext(ctx).
+		Model(&SavedSearch{}).
+		Where("id = ? AND user_id = ?", savedSearchId, userId).
+		Select("name", "query", "tags", "notify_by_email").
+		Updates(&SavedSearch{
+			Name:          dto.Name,
+			Query:         dto.Query,
+			Tags:          tags,
+			NotifyByEmail: dto.NotifyByEmail,
+		})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to update saved search")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrSavedSearchNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) DeleteSavedSearch(ctx context.Context, userId uint, savedSearchId uint) error {
+	result := s.Db.WithContext(ctx).Where("id = ? AND user_id = ?", savedSearchId, userId).Delete(&SavedSearch{})
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete saved search")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrSavedSearchNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) MergeTags(ctx context.Context, sources []string, target string) error {
+	// Same as projects.Service.MergeTags
+	dialect := database.DialectOf(s.Db)
+	result := s.Db.WithContext(ctx).Exec(
+		"UPDATE saved_searches SET tags = "+dialect.ReplaceValues("tags")+" WHERE deleted_at IS NULL AND "+dialect.Overlaps("tags"),
+		pq.StringArray(sources),
+		target,
+		pq.StringArray(sources),
+	)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to merge saved search tags")
+
+		return result.Error
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ProjectSaved(ctx context.Context, project *projects.Project) {
+	if project.PendingReview || project.Draft {
+		return
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", project.ID)
+	saved := *project
+
+	// Matching runs a query over all saved searches, don't make the request wait
+	go func() {
+		ctx := log.NewContext(context.Background(), logger)
+
+		err := s.matchProject(ctx, &saved)
+		if err != nil {
+			logger.WithError(err).Error("Failed to match project against saved searches")
+		}
+	}()
+}
+
+// Find the saved searches a project matches for the first time and notify
+// their owners.
+func (s *serviceImpl) matchProject(ctx context.Context, project *projects.Project) error {
+	logger := log.FromContext(ctx)
+
+	// Queries are matched with Postgres' full text search
+	if database.IsSqlite(s.Db) {
+		logger.Debug("Saved searches aren't matched on SQLite")
+
+		return nil
+	}
+
+	text := project.Name + " " + project.ShortDescription + " " + project.LongDescription
+
+	// Recording the matches and finding the new ones is done in a single
+	// statement, so a project saved twice concurrently can't notify twice.
+	var matches []SavedSearch
+	result := s.Db.WithContext(ctx).Raw(`
+		WITH new_matches AS (
+			INSERT INTO saved_search_matches (saved_search_id, project_id)
+			SELECT id, ?
+			FROM saved_searches
+			WHERE deleted_at IS NULL
+			  AND user_id <> ?
+			  AND (coalesce(cardinality(tags), 0) = 0 OR tags && ?)
+			  AND (query = '' OR to_tsvector('english', ?) @@ plainto_tsquery('english', query))
+			ON CONFLICT DO NOTHING
+			RETURNING saved_search_id
+		)
+		SELECT saved_searches.*
+		FROM saved_searches
+		JOIN new_matches ON new_matches.saved_search_id = saved_searches.id`,
+		project.ID,
+		project.OwnerId,
+		pq.StringArray(project.Tags),
+		text,
+	).Scan(&matches)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	logger.Debugf("Project matched %d saved searches", len(matches))
+
+	projectUrl := fmt.Sprintf("%s/projects/%d", s.FrontendUrl, project.ID)
+
+	for _, match := range matches {
+		err := s.NotificationsService.Notify(ctx, match.UserId, notifications.NewNotificationDto{
+			Type:  notifications.TypeSavedSearchMatch,
+			Title: fmt.Sprintf("New project matching \"%s\"", match.Name),
+			Body:  project.Name,
+			Data: map[string]interface{}{
+				"savedSearchId": match.ID,
+				"projectId":     project.ID,
+			},
+		})
+		if err != nil {
+			// Keep notifying the other users
+			logger.WithError(err).WithField("savedSearchId", match.ID).Error("Failed to notify saved search match")
+		}
+
+		if !match.NotifyByEmail {
+			continue
+		}
+
+		emailEnabled, err := s.NotificationsService.IsEnabled(ctx, match.UserId, notifications.TypeSavedSearchMatch, notifications.ChannelEmail)
+		if err != nil {
+			logger.WithError(err).WithField("userId", match.UserId).Error("Failed to get notification preferences")
+
+			continue
+		}
+
+		if !emailEnabled {
+			continue
+		}
+
+		user, err := s.UsersService.GetUser(ctx, match.UserId)
+		if err != nil {
+			logger.WithError(err).WithField("userId", match.UserId).Error("Failed to get saved search owner")
+
+			continue
+		}
+
+		message, err := s.EmailTemplates.Render(email.TemplateSavedSearchMatch, user.Email, map[string]interface{}{
+			"SearchName":         match.Name,
+			"ProjectName":        project.Name,
+			"ProjectDescription": project.ShortDescription,
+			"ProjectUrl":         projectUrl,
+		})
+		if err != nil {
+			logger.WithError(err).Error("Failed to render saved search match email")
+
+			continue
+		}
+
+		err = s.EmailSender.SendEmail(ctx, message)
+		if err != nil {
+			logger.WithError(err).WithField("userId", match.UserId).Error("Failed to email saved search match")
+		}
+	}
+
+	return nil
+}
+
+func savedSearchToDto(savedSearch SavedSearch) SavedSearchDto {
+	return SavedSearchDto{
+		Id:            savedSearch.ID,
+		Name:          savedSearch.Name,
+		Query:         savedSearch.Query,
+		Tags:          savedSearch.Tags,
+		NotifyByEmail: savedSearch.NotifyByEmail,
+		CreatedAt:     savedSearch.CreatedAt,
+	}
+}
@@ -0,0 +1,117 @@
+package search
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/open-collaboration/server/breaker"
+	"net/http"
+)
+
+// An EmbeddingProvider turns texts into embeddings, vectors whose distance
+// reflects how semantically similar the texts are. All embeddings of a
+// provider must have the same dimensions.
+type EmbeddingProvider interface {
+	// Generate an embedding for each text, in the same order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Generates embeddings with an OpenAI compatible embeddings API.
+type openAiEmbeddingProvider struct {
+	Url    string
+	ApiKey string
+	Model  string
+}
+
+// Create an EmbeddingProvider for an OpenAI compatible embeddings API, e.g.
+// https://api.openai.com/v1/embeddings.
+func NewOpenAiEmbeddingProvider(url string, apiKey string, model string) EmbeddingProvider {
+	return &openAiEmbeddingProvider{
+		Url:    url,
+		ApiKey: apiKey,
+		Model:  model,
+	}
+}
+
+func (p *openAiEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": p.Model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", p.Url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+p.ApiKey)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings request failed with status %d", response.StatusCode)
+	}
+
+	result := struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}{}
+
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Data))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, data := range result.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding has an invalid index %d", data.Index)
+		}
+
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return embeddings, nil
+}
+
+// Guards another embedding provider with a circuit breaker.
+type breakerEmbeddingProvider struct {
+	Provider EmbeddingProvider
+	Breaker  *breaker.Breaker
+}
+
+func NewBreakerEmbeddingProvider(provider EmbeddingProvider, breaker *breaker.Breaker) EmbeddingProvider {
+	return &breakerEmbeddingProvider{
+		Provider: provider,
+		Breaker:  breaker,
+	}
+}
+
+func (p *breakerEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var embeddings [][]float32
+	err := p.Breaker.Do(func() error {
+		var err error
+		embeddings, err = p.Provider.Embed(ctx, texts)
+
+		return err
+	})
+
+	return embeddings, err
+}
@@ -0,0 +1,49 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: embeddingProvider.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	reflect "reflect"
+)
+
+// MockEmbeddingProvider is a mock of EmbeddingProvider interface
+type MockEmbeddingProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmbeddingProviderMockRecorder
+}
+
+// MockEmbeddingProviderMockRecorder is the mock recorder for MockEmbeddingProvider
+type MockEmbeddingProviderMockRecorder struct {
+	mock *MockEmbeddingProvider
+}
+
+// NewMockEmbeddingProvider creates a new mock instance
+func NewMockEmbeddingProvider(ctrl *gomock.Controller) *MockEmbeddingProvider {
+	mock := &MockEmbeddingProvider{ctrl: ctrl}
+	mock.recorder = &MockEmbeddingProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockEmbeddingProvider) EXPECT() *MockEmbeddingProviderMockRecorder {
+	return m.recorder
+}
+
+// Embed mocks base method
+func (m *MockEmbeddingProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Embed", ctx, texts)
+	ret0, _ := ret[0].([][]float32)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Embed indicates an expected call of Embed
+func (mr *MockEmbeddingProviderMockRecorder) Embed(ctx, texts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Embed", reflect.TypeOf((*MockEmbeddingProvider)(nil).Embed), ctx, texts)
+}
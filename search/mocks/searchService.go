@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: searchService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	projects "github.com/open-collaboration/server/projects"
+	search "github.com/open-collaboration/server/search"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// SearchProjects mocks base method
+func (m *MockService) SearchProjects(ctx context.Context, query string, semantic bool, filters search.SearchFilters, limit int) ([]projects.ProjectSummaryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProjects", ctx, query, semantic, filters, limit)
+	ret0, _ := ret[0].([]projects.ProjectSummaryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProjects indicates an expected call of SearchProjects
+func (mr *MockServiceMockRecorder) SearchProjects(ctx, query, semantic, filters, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProjects", reflect.TypeOf((*MockService)(nil).SearchProjects), ctx, query, semantic, filters, limit)
+}
+
+// ProjectSaved mocks base method
+func (m *MockService) ProjectSaved(ctx context.Context, project *projects.Project) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ProjectSaved", ctx, project)
+}
+
+// ProjectSaved indicates an expected call of ProjectSaved
+func (mr *MockServiceMockRecorder) ProjectSaved(ctx, project interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectSaved", reflect.TypeOf((*MockService)(nil).ProjectSaved), ctx, project)
+}
+
+// Reindex mocks base method
+func (m *MockService) Reindex(ctx context.Context, options search.ReindexOptions, progress func(search.ReindexProgress)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reindex", ctx, options, progress)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reindex indicates an expected call of Reindex
+func (mr *MockServiceMockRecorder) Reindex(ctx, options, progress interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reindex", reflect.TypeOf((*MockService)(nil).Reindex), ctx, options, progress)
+}
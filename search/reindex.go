@@ -0,0 +1,223 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"github.com/apex/log"
+	"gorm.io/gorm"
+	"strings"
+	"sync"
+)
+
+type ReindexOptions struct {
+	// How many batches are embedded at the same time
+	Concurrency int
+
+	// How many projects are embedded per embedding provider request
+	BatchSize int
+
+	// Only projects with a greater id are indexed, to resume a previous
+	// reindex from its cursor
+	AfterId uint
+}
+
+type ReindexProgress struct {
+	// Projects indexed so far, and how many there are to index in total
+	Indexed int64
+	Total   int64
+
+	// Every project with an id up to the cursor was indexed, a reindex
+	// resumed from it skips them
+	Cursor uint
+}
+
+// A batch of projects queued to be embedded.
+type reindexBatch struct {
+	// Position of the batch in the queue, batches complete out of order
+	sequence int
+
+	projectIds []uint
+	texts      []string
+}
+
+// Projects are read in batches, in id order, and queued to
+// options.Concurrency workers that embed them.
+func (s *serviceImpl) Reindex(ctx context.Context, options ReindexOptions, progress func(ReindexProgress)) error {
+	if s.EmbeddingProvider == nil {
+		return ErrSemanticSearchDisabled
+	}
+
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+
+	if options.BatchSize < 1 {
+		options.BatchSize = 1
+	}
+
+	var total int64
+	result := s.Db.WithContext(ctx).Table("projects").
+		Where("deleted_at IS NULL AND id > ?", options.AfterId).
+		Count(&total)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan reindexBatch, options.Concurrency)
+	done := make(chan reindexBatch)
+	errs := make(chan error, options.Concurrency+1)
+
+	// Read batches of projects into the queue
+	go func() {
+		defer close(queue)
+
+		err := s.queueBatches(ctx, options, queue)
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	workers := sync.WaitGroup{}
+	for i := 0; i < options.Concurrency; i++ {
+		workers.Add(1)
+
+		go func() {
+			defer workers.Done()
+
+			for batch := range queue {
+				err := s.embedBatch(ctx, batch)
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				select {
+				case done <- batch:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	// The cursor only moves past a batch once all batches queued before it
+	// completed, so that resuming from it never skips a project
+	current := ReindexProgress{Total: total, Cursor: options.AfterId}
+	completed := map[int]reindexBatch{}
+	next := 0
+
+	for {
+		select {
+		case err := <-errs:
+			return err
+
+		case batch, ok := <-done:
+			if !ok {
+				// Workers stop early only on errors or when ctx is done
+				select {
+				case err := <-errs:
+					return err
+				default:
+				}
+
+				return ctx.Err()
+			}
+
+			completed[batch.sequence] = batch
+			current.Indexed += int64(len(batch.projectIds))
+
+			for {
+				batch, ok := completed[next]
+				if !ok {
+					break
+				}
+
+				current.Cursor = batch.projectIds[len(batch.projectIds)-1]
+				delete(completed, next)
+				next++
+			}
+
+			if progress != nil {
+				progress(current)
+			}
+		}
+	}
+}
+
+// Read the projects to index in batches and queue them, until all projects
+// were queued or ctx is done.
+func (s *serviceImpl) queueBatches(ctx context.Context, options ReindexOptions, queue chan<- reindexBatch) error {
+	afterId := options.AfterId
+
+	for sequence := 0; ; sequence++ {
+		var rows []struct {
+			Id               uint
+			Name             string
+			ShortDescription string
+			LongDescription  string
+		}
+
+		result := s.Db.WithContext(ctx).Table("projects").
+			Select("id, name, short_description, long_description").
+			Where("deleted_at IS NULL AND id > ?", afterId).
+			Order("id").
+			Limit(options.BatchSize).
+			Scan(&rows)
+
+		if result.Error != nil {
+			return result.Error
+		}
+
+		if len(rows) < 1 {
+			return nil
+		}
+
+		batch := reindexBatch{sequence: sequence}
+		for _, row := range rows {
+			batch.projectIds = append(batch.projectIds, row.Id)
+			batch.texts = append(batch.texts, strings.Join([]string{row.Name, row.ShortDescription, row.LongDescription}, "\n\n"))
+		}
+
+		select {
+		case queue <- batch:
+		case <-ctx.Done():
+			return nil
+		}
+
+		afterId = rows[len(rows)-1].Id
+	}
+}
+
+// Embed a batch of projects and store their embeddings.
+func (s *serviceImpl) embedBatch(ctx context.Context, batch reindexBatch) error {
+	embeddings, err := s.EmbeddingProvider.Embed(ctx, batch.texts)
+	if err != nil {
+		return err
+	}
+
+	if len(embeddings) != len(batch.texts) {
+		return fmt.Errorf("embedding provider returned %d embeddings for %d projects", len(embeddings), len(batch.texts))
+	}
+
+	return s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, projectId := range batch.projectIds {
+			err := storeEmbedding(tx, projectId, embeddings[i])
+			if err != nil {
+				log.FromContext(ctx).WithError(err).WithField("projectId", projectId).Error("Failed to store project embedding")
+
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package search
+
+import (
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strings"
+)
+
+// @Summary Search projects
+// @Description Keyword search over the projects' names and descriptions. With semantic=true
+// @Description the results are blended with the projects semantically closest to the query.
+// @Tags projects
+// @Router /projects/search [get]
+// @Param q query string true "The search query"
+// @Param semantic query bool false "Blend keyword results with semantic results. Default is false."
+// @Param licenses query string false "Comma separated SPDX license identifiers. Only projects with one of the licenses are returned."
+// @Param statuses query string false "Comma separated project statuses. Only projects with one of the statuses are returned."
+// @Param spokenLanguages query string false "Comma separated ISO 639-1 codes or names of spoken languages. Only projects communicating in one of them are returned."
+// @Param pageSize query int false "Maximum amount of projects in the response. Default is 20, max is 50."
+// @Param fields query string false "Comma separated fields of the projects to include in the response, e.g. name,shortDescription. All fields by default."
+// @Produce json,application/hal+json
+// @Success 200 {array} dtos.ProjectSummaryDto
+// @Failure 400 "Missing query or semantic search is disabled"
+func RouteSearchProjects(writer http.ResponseWriter, request *http.Request, searchService Service) error {
+	query := strings.TrimSpace(request.URL.Query().Get("q"))
+	if query == "" {
+		return utils.ErrInvalidParam
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 20)
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	semantic := request.URL.Query().Get("semantic") == "true"
+
+	filters := SearchFilters{
+		Licenses: utils.StringsFromQuery(request, "licenses"),
+		Statuses: utils.StringsFromQuery(request, "statuses"),
+
+		SpokenLanguages: utils.StringsFromQuery(request, "spokenLanguages"),
+	}
+
+	projectSummaries, err := searchService.SearchProjects(request.Context(), query, semantic, filters, pageSize)
+	if err != nil {
+		return err
+	}
+
+	response, err := utils.SelectFields(projectSummaries, utils.FieldsFromQuery(request))
+	if err != nil {
+		return err
+	}
+
+	return projects.WriteProjectSummaries(writer, request, response, utils.Links{
+		"self": {Href: request.URL.RequestURI()},
+	})
+}
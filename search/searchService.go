@@ -0,0 +1,263 @@
+package search
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/projects"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrSemanticSearchDisabled = errors.New("semantic search is disabled")
+
+// The text searched by keyword search. Must match the expression of the
+// idx_projects_search index (migration 15) for the index to be used.
+const projectDocumentSql = "to_tsvector('english', name || ' ' || short_description || ' ' || long_description)"
+
+// The skills of a project summary, the distinct skills of the project's roles
+// alphabetically like the projects service's summaries. project is the
+// projects table's name or alias in the query.
+func summarySkillsSql(project string) string {
+	return "ARRAY(SELECT DISTINCT unnest(skills) FROM project_roles WHERE project_id = " + project + ".id AND deleted_at IS NULL ORDER BY 1) AS skills"
+}
+
+// Constant of the reciprocal rank fusion used to blend keyword and semantic
+// results. Higher values flatten the difference between top and bottom ranks.
+const rankFusionK = 60
+
+type Service interface {
+	// Search listed projects (not pending review) by keywords in their name and
+	// descriptions. If semantic is true, the results are blended with the projects
+	// whose descriptions are semantically closest to the query.
+	// Returns ErrSemanticSearchDisabled if semantic is true but no embedding
+	// provider is configured.
+	// The results can also be filtered, see SearchFilters.
+	// Returns projects.ErrUnknownLicense if a license isn't in the catalog,
+	// projects.ErrUnknownStatus if a status doesn't exist and
+	// projects.ErrUnknownSpokenLanguage if a spoken language isn't in the
+	// catalog.
+	SearchProjects(ctx context.Context, query string, semantic bool, filters SearchFilters, limit int) ([]projects.ProjectSummaryDto, error)
+
+	// Generates a project's embedding in the background so that it can be
+	// found by semantic search. Does nothing if semantic search is disabled.
+	ProjectSaved(ctx context.Context, project *projects.Project)
+
+	// Regenerate the embeddings of all projects, e.g. after changing the
+	// embedding model. progress is called after each batch with the cursor up
+	// to which every project was indexed.
+	// Returns ErrSemanticSearchDisabled if semantic search is disabled.
+	Reindex(ctx context.Context, options ReindexOptions, progress func(ReindexProgress)) error
+}
+
+// Filters of project searches. Each filter is only applied if it's non-nil
+// and non-empty.
+type SearchFilters struct {
+	// Projects with one of the licenses
+	Licenses []string
+
+	// Projects with one of the statuses
+	Statuses []string
+
+	// Projects communicating in at least one of the spoken languages
+	SpokenLanguages []string
+}
+
+type serviceImpl struct {
+	Db                *gorm.DB
+	EmbeddingProvider EmbeddingProvider
+}
+
+// Create a search service. embeddingProvider may be nil, in which case
+// semantic search is disabled.
+func NewService(db *gorm.DB, embeddingProvider EmbeddingProvider) Service {
+	return &serviceImpl{
+		Db:                db,
+		EmbeddingProvider: embeddingProvider,
+	}
+}
+
+func (s *serviceImpl) SearchProjects(
+	ctx context.Context,
+	query string,
+	semantic bool,
+	filters SearchFilters,
+	limit int,
+) ([]projects.ProjectSummaryDto, error) {
+	logger := log.FromContext(ctx).WithField("semantic", semantic)
+
+	if semantic && s.EmbeddingProvider == nil {
+		return nil, ErrSemanticSearchDisabled
+	}
+
+	// Empty lists match every project
+	licenses, err := projects.NormalizeLicenses(filters.Licenses)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses, err := projects.NormalizeStatuses(filters.Statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	spokenLanguages, err := projects.NormalizeSpokenLanguages(filters.SpokenLanguages)
+	if err != nil {
+		return nil, err
+	}
+
+	var keywordResults []projects.ProjectSummaryDto
+	result := s.Db.WithContext(ctx).Raw(`
+		SELECT id, name, tags, short_description, `+summarySkillsSql("projects")+`
+		FROM projects
+		WHERE deleted_at IS NULL
+		  AND pending_review = false
+		  AND draft = false
+		  AND (cardinality(?::TEXT[]) < 1 OR license = ANY(?))
+		  AND (cardinality(?::TEXT[]) < 1 OR status = ANY(?))
+		  AND (cardinality(?::TEXT[]) < 1 OR spoken_languages && ?)
+		  AND `+projectDocumentSql+` @@ plainto_tsquery('english', ?)
+		ORDER BY ts_rank(`+projectDocumentSql+`, plainto_tsquery('english', ?)) DESC
+		LIMIT ?`,
+		pq.StringArray(licenses),
+		pq.StringArray(licenses),
+		pq.StringArray(statuses),
+		pq.StringArray(statuses),
+		pq.StringArray(spokenLanguages),
+		pq.StringArray(spokenLanguages),
+		query,
+		query,
+		limit,
+	).Scan(&keywordResults)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to search projects by keywords")
+
+		return nil, result.Error
+	}
+
+	if !semantic {
+		return keywordResults, nil
+	}
+
+	embeddings, err := s.EmbeddingProvider.Embed(ctx, []string{query})
+	if err != nil {
+		// Keyword results are still useful on their own
+		logger.WithError(err).Warn("Failed to embed search query, returning keyword results only")
+
+		return keywordResults, nil
+	}
+
+	var semanticResults []projects.ProjectSummaryDto
+	result = s.Db.WithContext(ctx).Raw(`
+		SELECT p.id, p.name, p.tags, p.short_description, `+summarySkillsSql("p")+`
+		FROM project_embeddings e
+		JOIN projects p ON p.id = e.project_id
+		WHERE p.deleted_at IS NULL
+		  AND p.pending_review = false
+		  AND p.draft = false
+		  AND (cardinality(?::TEXT[]) < 1 OR p.license = ANY(?))
+		  AND (cardinality(?::TEXT[]) < 1 OR p.status = ANY(?))
+		  AND (cardinality(?::TEXT[]) < 1 OR p.spoken_languages && ?)
+		ORDER BY e.embedding <=> ?::vector
+		LIMIT ?`,
+		pq.StringArray(licenses),
+		pq.StringArray(licenses),
+		pq.StringArray(statuses),
+		pq.StringArray(statuses),
+		pq.StringArray(spokenLanguages),
+		pq.StringArray(spokenLanguages),
+		vectorLiteral(embeddings[0]),
+		limit,
+	).Scan(&semanticResults)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to search projects by embedding")
+
+		return nil, result.Error
+	}
+
+	blended := blendResults(keywordResults, semanticResults)
+	if len(blended) > limit {
+		blended = blended[:limit]
+	}
+
+	return blended, nil
+}
+
+func (s *serviceImpl) ProjectSaved(ctx context.Context, project *projects.Project) {
+	if s.EmbeddingProvider == nil {
+		return
+	}
+
+	logger := log.FromContext(ctx).WithField("projectId", project.ID)
+	text := strings.Join([]string{project.Name, project.ShortDescription, project.LongDescription}, "\n\n")
+	projectId := project.ID
+
+	// Embedding providers are usually remote APIs, so don't make the request wait
+	go func() {
+		ctx := log.NewContext(context.Background(), logger)
+
+		embeddings, err := s.EmbeddingProvider.Embed(ctx, []string{text})
+		if err != nil {
+			logger.WithError(err).Error("Failed to generate project embedding")
+
+			return
+		}
+
+		err = storeEmbedding(s.Db.WithContext(ctx), projectId, embeddings[0])
+		if err != nil {
+			logger.WithError(err).Error("Failed to store project embedding")
+		}
+	}()
+}
+
+// Insert or replace a project's embedding.
+func storeEmbedding(db *gorm.DB, projectId uint, embedding []float32) error {
+	return db.Exec(`
+		INSERT INTO project_embeddings (project_id, embedding, updated_at)
+		VALUES (?, ?::vector, now())
+		ON CONFLICT (project_id) DO UPDATE SET embedding = excluded.embedding, updated_at = excluded.updated_at`,
+		projectId,
+		vectorLiteral(embedding),
+	).Error
+}
+
+// Blend two ranked result lists with reciprocal rank fusion: each project
+// scores 1/(k + rank) for every list it's in, and projects are sorted by their
+// total score.
+func blendResults(lists ...[]projects.ProjectSummaryDto) []projects.ProjectSummaryDto {
+	scores := map[uint]float64{}
+	var blended []projects.ProjectSummaryDto
+
+	for _, list := range lists {
+		for rank, project := range list {
+			if _, ok := scores[project.Id]; !ok {
+				blended = append(blended, project)
+			}
+
+			scores[project.Id] += 1 / float64(rankFusionK+rank+1)
+		}
+	}
+
+	sort.SliceStable(blended, func(i, j int) bool {
+		return scores[blended[i].Id] > scores[blended[j].Id]
+	})
+
+	return blended
+}
+
+// Format an embedding as a pgvector literal, e.g. [0.1,0.2,0.3].
+func vectorLiteral(embedding []float32) string {
+	values := make([]string, len(embedding))
+	for i, value := range embedding {
+		values[i] = strconv.FormatFloat(float64(value), 'f', -1, 32)
+	}
+
+	return "[" + strings.Join(values, ",") + "]"
+}
@@ -0,0 +1,225 @@
+package secrets
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A Provider reads secrets from a secret manager. A provider's secrets are a
+// JSON object whose keys are the names of the environment variables they
+// replace, e.g. {"PG_PASSWORD": "...", "SMTP_PASSWORD": "..."}.
+type Provider interface {
+	Name() string
+
+	// Fetch the current value of every secret, by name.
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Reads a HashiCorp Vault KV secret (version 1 or 2).
+type vaultProvider struct {
+	Addr  string
+	Token string
+
+	// The secret's API path, e.g. "secret/data/open-collaboration" for the
+	// "open-collaboration" secret of a KV v2 engine mounted at "secret"
+	Path string
+}
+
+func NewVaultProvider(addr string, token string, path string) Provider {
+	return &vaultProvider{
+		Addr:  strings.TrimSuffix(addr, "/"),
+		Token: token,
+		Path:  strings.Trim(path, "/"),
+	}
+}
+
+func (p *vaultProvider) Name() string {
+	return "vault"
+}
+
+func (p *vaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", p.Addr+"/v1/"+p.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("X-Vault-Token", p.Token)
+
+	result := struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}{}
+
+	err = doJson(request, "vault", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	// KV v2 nests the secret in data.data, next to its metadata
+	if nested, ok := result.Data["data"]; ok {
+		if _, ok := result.Data["metadata"]; ok {
+			return parseSecrets(nested)
+		}
+	}
+
+	encoded, err := json.Marshal(result.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSecrets(encoded)
+}
+
+// Reads an AWS Secrets Manager secret whose value is a JSON object.
+type awsProvider struct {
+	Region      string
+	Credentials utils.AwsCredentials
+	SecretId    string
+}
+
+func NewAwsProvider(region string, credentials utils.AwsCredentials, secretId string) Provider {
+	return &awsProvider{
+		Region:      region,
+		Credentials: credentials,
+		SecretId:    secretId,
+	}
+}
+
+func (p *awsProvider) Name() string {
+	return "aws"
+}
+
+func (p *awsProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": p.SecretId})
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", p.Region)
+	request, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	request.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	utils.SignAwsRequest(request, payload, p.Credentials, p.Region, "secretsmanager", time.Now())
+
+	result := struct {
+		SecretString string `json:"SecretString"`
+	}{}
+
+	err = doJson(request, "aws secrets manager", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSecrets([]byte(result.SecretString))
+}
+
+// Reads a Google Cloud Secret Manager secret version whose value is a JSON
+// object. Authenticates as the service account of the instance it runs on
+// (Compute Engine, GKE, Cloud Run...) through the metadata server.
+type gcpProvider struct {
+	// e.g. "projects/<project>/secrets/<secret>/versions/latest"
+	SecretVersion string
+}
+
+const gcpTokenUrl = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+func NewGcpProvider(secretVersion string) Provider {
+	return &gcpProvider{SecretVersion: strings.Trim(secretVersion, "/")}
+}
+
+func (p *gcpProvider) Name() string {
+	return "gcp"
+}
+
+func (p *gcpProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", gcpTokenUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Metadata-Flavor", "Google")
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+	}{}
+
+	err = doJson(request, "gcp metadata server", &token)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err = http.NewRequestWithContext(ctx, "GET", "https://secretmanager.googleapis.com/v1/"+p.SecretVersion+":access", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	result := struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}{}
+
+	err = doJson(request, "gcp secret manager", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(result.Payload.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSecrets(data)
+}
+
+// Send a request and decode its JSON response into result.
+func doJson(request *http.Request, service string, result interface{}) error {
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s request failed with status %d", service, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+// Parse a JSON object of secrets. Non string values, e.g. numbers, are kept
+// as their JSON encoding.
+func parseSecrets(data []byte) (map[string]string, error) {
+	var values map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &values)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSecret, err)
+	}
+
+	secrets := make(map[string]string, len(values))
+	for name, value := range values {
+		var text string
+		if json.Unmarshal(value, &text) != nil {
+			text = string(value)
+		}
+
+		secrets[name] = text
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,144 @@
+// Package secrets loads credentials and keys from a secret manager (Vault,
+// AWS Secrets Manager or Google Cloud Secret Manager) instead of environment
+// variables, and refreshes them so that rotated secrets are picked up.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrInvalidSecret = errors.New("secrets must be a JSON object of strings")
+
+// How long fetching the secrets may take.
+const fetchTimeout = 30 * time.Second
+
+// Holds the secrets of a provider and refreshes them periodically.
+//
+// Secrets replace the environment variables of the same name: they're
+// exported to the environment, so the configuration reads them like any other
+// variable, and Get returns their current value to the connections that apply
+// rotated secrets without a restart. A nil *Store reads the environment only.
+type Store struct {
+	provider        Provider
+	refreshInterval time.Duration
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func NewStore(provider Provider, refreshInterval time.Duration) *Store {
+	return &Store{
+		provider:        provider,
+		refreshInterval: refreshInterval,
+		values:          map[string]string{},
+	}
+}
+
+// Fetch the secrets and export them to the environment, overriding the
+// variables that are already set.
+func (s *Store) Load(ctx context.Context) error {
+	_, err := s.refresh(ctx)
+
+	return err
+}
+
+// The current value of a secret, or of the environment variable if it isn't
+// a secret.
+func (s *Store) Get(name string) string {
+	if s != nil {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		if value, ok := s.values[name]; ok {
+			return value
+		}
+	}
+
+	return os.Getenv(name)
+}
+
+// Refresh the secrets periodically until ctx is done. Should be run in its
+// own goroutine. If a refresh fails the previous secrets are kept. Secrets
+// aren't refreshed if the refresh interval is 0.
+func (s *Store) Run(ctx context.Context) {
+	if s.refreshInterval <= 0 {
+		return
+	}
+
+	logger := log.FromContext(ctx).WithField("provider", s.provider.Name())
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := s.refresh(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.WithError(err).Warn("Failed to refresh secrets")
+			}
+
+			continue
+		}
+
+		// Only names are logged, never values
+		if len(changed) > 0 {
+			logger.WithField("secrets", changed).Info("Secrets rotated")
+		}
+	}
+}
+
+// Fetch the secrets and export them. Returns the names of the secrets that
+// changed, were added or were removed.
+func (s *Store) refresh(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	values, err := s.provider.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed []string
+	for name, value := range values {
+		previous, ok := s.values[name]
+		if !ok || previous != value {
+			changed = append(changed, name)
+		}
+
+		err = os.Setenv(name, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range s.values {
+		if _, ok := values[name]; !ok {
+			changed = append(changed, name)
+
+			err = os.Unsetenv(name)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s.values = values
+	sort.Strings(changed)
+
+	return changed, nil
+}
@@ -0,0 +1,129 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrChecksFailed = errors.New("startup self-check failed")
+
+// How long all checks can take.
+const checkTimeout = 30 * time.Second
+
+// A check run at startup, e.g. that Redis is reachable. Run's errors are shown
+// on the public startup report, so they mustn't contain secrets or internal
+// addresses.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type Result struct {
+	Name string
+
+	// nil if the check passed
+	Err error
+}
+
+// The results of the startup checks.
+type Report struct {
+	CheckedAt time.Time
+	Results   []Result
+
+	// Whether the server serves read-only because checks failed, see
+	// DegradedMiddleware
+	Degraded bool
+}
+
+// Run checks in order. Every check runs, even after one fails.
+func Run(ctx context.Context, checks ...Check) *Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	report := &Report{CheckedAt: time.Now()}
+
+	for _, check := range checks {
+		err := check.Run(ctx)
+		if err != nil {
+			log.FromContext(ctx).WithError(err).WithField("check", check.Name).Error("Startup check failed")
+		}
+
+		report.Results = append(report.Results, Result{Name: check.Name, Err: err})
+	}
+
+	return report
+}
+
+// Returns ErrChecksFailed, wrapped with the failed checks, if any failed.
+func (r *Report) Err() error {
+	var failed []string
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", result.Name, result.Err))
+		}
+	}
+
+	if len(failed) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrChecksFailed, strings.Join(failed, "; "))
+}
+
+func (r *Report) ToDto() ReportDto {
+	dto := ReportDto{
+		Status:    StatusOk,
+		CheckedAt: r.CheckedAt,
+		Checks:    make([]CheckDto, len(r.Results)),
+	}
+
+	for i, result := range r.Results {
+		dto.Checks[i] = CheckDto{Name: result.Name, Status: StatusOk}
+
+		if result.Err != nil {
+			dto.Status = StatusFailed
+			dto.Checks[i].Status = StatusFailed
+			dto.Checks[i].Error = result.Err.Error()
+		}
+	}
+
+	if r.Degraded {
+		dto.Status = StatusDegraded
+	}
+
+	return dto
+}
+
+// Rejects requests that aren't safe (i.e. not GET, HEAD or OPTIONS) while the
+// server is degraded, so that nothing is written to a database or store that
+// failed its checks. The startup report stays available.
+func DegradedMiddleware(report *Report) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !report.Degraded || r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			logger := log.FromContext(ctx)
+
+			logger.Debug("Rejecting request while degraded")
+
+			err := utils.WriteJson(w, ctx, http.StatusServiceUnavailable, map[string]interface{}{
+				"code":    "degraded-read-only-error",
+				"details": map[string]interface{}{},
+			})
+			if err != nil {
+				logger.WithError(err).Error("Failed to write error response")
+			}
+		})
+	}
+}
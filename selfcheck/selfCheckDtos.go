@@ -0,0 +1,27 @@
+package selfcheck
+
+import "time"
+
+type Status string
+
+const (
+	StatusOk     Status = "ok"
+	StatusFailed Status = "failed"
+
+	// Checks failed, the server serves read-only
+	StatusDegraded Status = "degraded"
+)
+
+type ReportDto struct {
+	Status    Status     `json:"status"`
+	CheckedAt time.Time  `json:"checkedAt"`
+	Checks    []CheckDto `json:"checks"`
+}
+
+type CheckDto struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+
+	// Why the check failed
+	Error string `json:"error,omitempty"`
+}
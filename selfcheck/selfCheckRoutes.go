@@ -0,0 +1,24 @@
+package selfcheck
+
+import (
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Get the results of the startup checks
+// @Description Checks that the database schema matches this version, that Redis is reachable, that the
+// @Description configuration is valid and that the encryption keys decrypt stored values. If any failed the
+// @Description server either refused to start or serves read-only (status "degraded"), see
+// @Description STARTUP_CHECK_POLICY.
+// @Tags health
+// @Router /health/startup [get]
+// @Success 200 {object} selfcheck.ReportDto
+// @Failure 503 {object} selfcheck.ReportDto "Checks failed"
+func RouteGetReport(writer http.ResponseWriter, request *http.Request, report *Report) error {
+	status := http.StatusOK
+	if report.Err() != nil {
+		status = http.StatusServiceUnavailable
+	}
+
+	return utils.WriteJson(writer, request.Context(), status, report.ToDto())
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sockpuppetsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	sockpuppets "github.com/open-collaboration/server/sockpuppets"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// RecordSighting mocks base method
+func (m *MockService) RecordSighting(ctx context.Context, userId uint, deviceFingerprint string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordSighting", ctx, userId, deviceFingerprint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordSighting indicates an expected call of RecordSighting
+func (mr *MockServiceMockRecorder) RecordSighting(ctx, userId, deviceFingerprint interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordSighting", reflect.TypeOf((*MockService)(nil).RecordSighting), ctx, userId, deviceFingerprint)
+}
+
+// ListSuspects mocks base method
+func (m *MockService) ListSuspects(ctx context.Context) ([]sockpuppets.SuspectDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSuspects", ctx)
+	ret0, _ := ret[0].([]sockpuppets.SuspectDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSuspects indicates an expected call of ListSuspects
+func (mr *MockServiceMockRecorder) ListSuspects(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSuspects", reflect.TypeOf((*MockService)(nil).ListSuspects), ctx)
+}
+
+// ListUserSuspects mocks base method
+func (m *MockService) ListUserSuspects(ctx context.Context, userId uint) ([]sockpuppets.SuspectDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSuspects", ctx, userId)
+	ret0, _ := ret[0].([]sockpuppets.SuspectDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSuspects indicates an expected call of ListUserSuspects
+func (mr *MockServiceMockRecorder) ListUserSuspects(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSuspects", reflect.TypeOf((*MockService)(nil).ListUserSuspects), ctx, userId)
+}
+
+// ReviewSuspect mocks base method
+func (m *MockService) ReviewSuspect(ctx context.Context, moderatorId, userId, otherUserId uint, dto sockpuppets.NewReviewDto) (sockpuppets.ReviewDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewSuspect", ctx, moderatorId, userId, otherUserId, dto)
+	ret0, _ := ret[0].(sockpuppets.ReviewDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewSuspect indicates an expected call of ReviewSuspect
+func (mr *MockServiceMockRecorder) ReviewSuspect(ctx, moderatorId, userId, otherUserId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewSuspect", reflect.TypeOf((*MockService)(nil).ReviewSuspect), ctx, moderatorId, userId, otherUserId, dto)
+}
@@ -0,0 +1,68 @@
+package sockpuppets
+
+import "time"
+
+// What two accounts can have in common.
+type SignalKind string
+
+const (
+	// An IP address both accounts made requests from.
+	SignalIp SignalKind = "ip"
+
+	// A device fingerprint both accounts' clients sent, see
+	// DeviceFingerprintHeader.
+	SignalDevice SignalKind = "device"
+
+	// The same email address once normalized, e.g. j.doe+1@gmail.com and
+	// jdoe@gmail.com. Covers the accounts' emails and the emails of the
+	// identities they linked, which their providers verified.
+	SignalEmail SignalKind = "email"
+
+	// An email domain that isn't a public email provider's, e.g. a small
+	// company's or a personal domain.
+	SignalEmailDomain SignalKind = "email-domain"
+)
+
+// Something seen on an account's requests or in its details. Values are
+// hashed: two accounts sharing a value is all the heuristics need, the
+// addresses themselves aren't kept.
+type Sighting struct {
+	UserId uint       `gorm:"primaryKey"`
+	Kind   SignalKind `gorm:"primaryKey"`
+
+	// Hex SHA-256 of the value
+	ValueHash string `gorm:"primaryKey"`
+
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+func (Sighting) TableName() string {
+	return "account_sightings"
+}
+
+// A moderator's verdict on two accounts. UserId is always the lower id.
+type Review struct {
+	UserId      uint `gorm:"primaryKey"`
+	OtherUserId uint `gorm:"primaryKey"`
+
+	Verdict    Verdict
+	Note       string
+	ReviewedBy uint
+	UpdatedAt  time.Time
+}
+
+func (Review) TableName() string {
+	return "sockpuppet_reviews"
+}
+
+type Verdict string
+
+const (
+	// The accounts belong to the same person.
+	VerdictDuplicate Verdict = "duplicate"
+
+	// The accounts belong to different people, e.g. housemates or
+	// colleagues. They aren't suggested again.
+	VerdictDistinct Verdict = "distinct"
+)
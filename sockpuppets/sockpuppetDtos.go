@@ -0,0 +1,45 @@
+package sockpuppets
+
+import "time"
+
+type AccountDto struct {
+	Id        uint      `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Whether the account has active restrictions, i.e. whether the other
+	// account may be evading them
+	Restricted bool `json:"restricted"`
+}
+
+type SignalDto struct {
+	Kind SignalKind `json:"kind"`
+
+	// How many values of the kind the accounts share, e.g. IP addresses
+	Shared int `json:"shared"`
+}
+
+// Two accounts that likely belong to the same person.
+type SuspectDto struct {
+	Account AccountDto `json:"account"`
+	Other   AccountDto `json:"other"`
+
+	// The weighted sum of the signals, the higher the likelier
+	Score   int         `json:"score"`
+	Signals []SignalDto `json:"signals"`
+
+	// Nil if no moderator reviewed the accounts yet
+	Review *ReviewDto `json:"review"`
+}
+
+type NewReviewDto struct {
+	Verdict Verdict `json:"verdict" validate:"required,oneof=duplicate distinct"`
+	Note    string  `json:"note" validate:"max=1000"`
+}
+
+type ReviewDto struct {
+	Verdict    Verdict   `json:"verdict"`
+	Note       string    `json:"note"`
+	ReviewedBy uint      `json:"reviewedBy"`
+	ReviewedAt time.Time `json:"reviewedAt"`
+}
@@ -0,0 +1,51 @@
+package sockpuppets
+
+import (
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"net/http"
+	"strings"
+)
+
+// The header clients send a hash of the device they run on in, e.g. of the
+// browser's characteristics. Optional, it's only one of the signals.
+const DeviceFingerprintHeader = "X-Device-Fingerprint"
+
+// Fingerprints longer than this are ignored, hashes are much shorter.
+const maxFingerprintLength = 128
+
+// Records where users with a session make requests from, see
+// Service.RecordSighting. Requests are served even if the sighting can't be
+// recorded. Impersonated requests aren't recorded, since they're made by
+// admins.
+//
+// Must be used after auth.SessionMiddleware.
+func SightingsMiddleware(sockpuppetsService Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := auth.CheckSession(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if _, impersonated := session.ImpersonatorId(); impersonated {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			fingerprint := strings.TrimSpace(r.Header.Get(DeviceFingerprintHeader))
+			if len(fingerprint) > maxFingerprintLength {
+				fingerprint = ""
+			}
+
+			err = sockpuppetsService.RecordSighting(r.Context(), session.UserId(), fingerprint)
+			if err != nil {
+				log.FromContext(r.Context()).WithError(err).Warn("Failed to record sighting")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
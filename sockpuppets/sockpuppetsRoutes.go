@@ -0,0 +1,117 @@
+package sockpuppets
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary List suspected duplicate accounts
+// @Description Pairs of accounts that likely belong to the same person, e.g. to evade a restriction, that no moderator
+// @Description reviewed yet, the likeliest first. Accounts are scored by the device fingerprints (X-Device-Fingerprint
+// @Description header), IP addresses, normalized email addresses and uncommon email domains they shared in the last
+// @Description 90 days. Values shared by many accounts, e.g. a university's network, don't count. At most 100 pairs.
+// @Tags moderation
+// @Router /moderation/sockpuppets [get]
+// @Success 200 {array} sockpuppets.SuspectDto
+// @Failure 403
+func RouteListSuspects(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	sockpuppetsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	suspects, err := sockpuppetsService.ListSuspects(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, suspects)
+}
+
+// @Summary List a user's suspected duplicate accounts
+// @Description The accounts that shared anything with the user in the last 90 days, reviewed or not, the likeliest
+// @Description duplicates first. The user is each pair's account.
+// @Tags moderation
+// @Router /moderation/users/{userId}/sockpuppets [get]
+// @Param userId path int true "The user ID"
+// @Success 200 {array} sockpuppets.SuspectDto
+// @Failure 403
+// @Failure 404
+func RouteListUserSuspects(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	sockpuppetsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	suspects, err := sockpuppetsService.ListUserSuspects(request.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, suspects)
+}
+
+// @Summary Review suspected duplicate accounts
+// @Description Records whether two accounts belong to the same person, replacing the previous verdict. Accounts
+// @Description reviewed as distinct aren't suggested again. Duplicates aren't restricted automatically, see
+// @Description POST /moderation/users/{userId}/restrictions.
+// @Tags moderation
+// @Router /moderation/users/{userId}/sockpuppets/{otherUserId}/review [put]
+// @Param userId path int true "The user ID"
+// @Param otherUserId path int true "The other user's ID"
+// @Param review body sockpuppets.NewReviewDto true "The verdict"
+// @Success 200 {object} sockpuppets.ReviewDto
+// @Failure 400 "Both ids are the same"
+// @Failure 403
+// @Failure 404
+func RouteReviewSuspect(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	sockpuppetsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	otherUserId, err := utils.UintFromVars(request, "otherUserId")
+	if err != nil {
+		return err
+	}
+
+	dto := NewReviewDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	review, err := sockpuppetsService.ReviewSuspect(request.Context(), session.UserId(), userId, otherUserId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, review)
+}
@@ -0,0 +1,573 @@
+// NOTE: take a look at the redis documentation (docs/redis.md) to better
+// understand how sightings are throttled.
+
+package sockpuppets
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/identities"
+	"github.com/open-collaboration/server/kv"
+	"github.com/open-collaboration/server/moderation"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrSameAccount = errors.New("an account can't be a duplicate of itself")
+
+// A user's sightings are recorded at most this often for the same IP
+// address and device.
+const sightingInterval = time.Hour
+
+// Only sightings this recent are signals.
+const signalWindow = 90 * 24 * time.Hour
+
+// Values shared by more accounts than this aren't signals, e.g. a
+// university's IP address or a company's email domain.
+const maxAccountsPerValue = 10
+
+// Pairs of accounts scoring this much or more are listed for review.
+const suspectThreshold = 4
+
+// How many pairs of accounts are listed for review at most.
+const maxSuspects = 100
+
+// How much each shared value adds to a pair's score, see scoreSignal.
+var signalWeights = map[SignalKind]int{
+	SignalDevice:      4,
+	SignalEmail:       4,
+	SignalIp:          2,
+	SignalEmailDomain: 1,
+}
+
+// Email providers anyone can sign up to, whose domains say nothing about
+// who owns an address.
+var publicEmailDomains = map[string]bool{
+	"163.com":        true,
+	"aol.com":        true,
+	"fastmail.com":   true,
+	"gmail.com":      true,
+	"gmx.com":        true,
+	"gmx.de":         true,
+	"hey.com":        true,
+	"hotmail.com":    true,
+	"icloud.com":     true,
+	"live.com":       true,
+	"mail.ru":        true,
+	"me.com":         true,
+	"outlook.com":    true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"qq.com":         true,
+	"yahoo.com":      true,
+	"yandex.ru":      true,
+	"zoho.com":       true,
+}
+
+type Service interface {
+	// Record the IP address of the request in ctx and the device fingerprint
+	// its client sent (empty if it didn't) as seen on the user's account,
+	// along with the user's email addresses. Recorded at most once per
+	// sightingInterval for the same address and device.
+	RecordSighting(ctx context.Context, userId uint, deviceFingerprint string) error
+
+	// List pairs of accounts that likely belong to the same person and that
+	// no moderator reviewed yet, the likeliest first.
+	ListSuspects(ctx context.Context) ([]SuspectDto, error)
+
+	// List the accounts that share anything with the user, reviewed or not,
+	// the likeliest duplicates first. The user is each pair's Account.
+	// Returns users.ErrUserNotFound if the user can't be found.
+	ListUserSuspects(ctx context.Context, userId uint) ([]SuspectDto, error)
+
+	// Record a moderator's verdict on two accounts, replacing the previous
+	// one, and record it in the audit log. Accounts reviewed as distinct
+	// aren't listed by ListSuspects again.
+	// Returns ErrSameAccount if both ids are the same and
+	// users.ErrUserNotFound if either user can't be found.
+	ReviewSuspect(ctx context.Context, moderatorId uint, userId uint, otherUserId uint, dto NewReviewDto) (ReviewDto, error)
+}
+
+type serviceImpl struct {
+	Db           *gorm.DB
+	Kv           kv.Store
+	AuditService audit.Service
+
+	// Key of the HMAC values are hashed with, so that e.g. IP addresses can't
+	// be recovered by hashing every address. Plain SHA-256 if empty.
+	HashKey []byte
+}
+
+func NewService(db *gorm.DB, kvStore kv.Store, auditService audit.Service, hashKey string) Service {
+	return &serviceImpl{
+		Db:           db,
+		Kv:           kvStore,
+		AuditService: auditService,
+		HashKey:      []byte(hashKey),
+	}
+}
+
+func sightingRedisKey(userId uint, sightingHash string) string {
+	return fmt.Sprintf("user:%d:sighting:%s", userId, sightingHash)
+}
+
+func (s *serviceImpl) RecordSighting(ctx context.Context, userId uint, deviceFingerprint string) error {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	ip := utils.ClientIp(ctx)
+
+	// Only the first request of the interval from the address and device is
+	// recorded, so that sightings don't cost a write per request
+	first, err := s.Kv.SetNX(ctx, sightingRedisKey(userId, s.hash(ip+"\n"+deviceFingerprint)), "1", sightingInterval)
+	if err != nil {
+		logger.WithError(err).Error("Failed to throttle sighting")
+
+		return err
+	}
+
+	if !first {
+		return nil
+	}
+
+	values := map[SignalKind][]string{}
+	if ip != "" {
+		values[SignalIp] = append(values[SignalIp], ip)
+	}
+	if deviceFingerprint != "" {
+		values[SignalDevice] = append(values[SignalDevice], deviceFingerprint)
+	}
+
+	emails, err := s.userEmails(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, email := range emails {
+		normalized, domain := normalizeEmail(email)
+		if normalized == "" {
+			continue
+		}
+
+		values[SignalEmail] = append(values[SignalEmail], normalized)
+		if !publicEmailDomains[domain] {
+			values[SignalEmailDomain] = append(values[SignalEmailDomain], domain)
+		}
+	}
+
+	now := time.Now()
+
+	var sightings []Sighting
+	for kind, kindValues := range values {
+		for _, value := range kindValues {
+			sightings = append(sightings, Sighting{
+				UserId:      userId,
+				Kind:        kind,
+				ValueHash:   s.hash(value),
+				FirstSeenAt: now,
+				LastSeenAt:  now,
+			})
+		}
+	}
+
+	if len(sightings) < 1 {
+		return nil
+	}
+
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}, {Name: "value_hash"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+		}).
+		Create(&sightings)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to record sightings")
+
+		return result.Error
+	}
+
+	return nil
+}
+
+// The user's email and the emails of the identities they linked.
+func (s *serviceImpl) userEmails(ctx context.Context, userId uint) ([]string, error) {
+	user := users.User{}
+	result := s.Db.WithContext(ctx).Select("email").First(&user, userId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, users.ErrUserNotFound
+	} else if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for user")
+
+		return nil, result.Error
+	}
+
+	var identityEmails []string
+	result = s.Db.WithContext(ctx).
+		Model(&identities.Identity{}).
+		Where("user_id = ? AND email <> ''", userId).
+		Pluck("email", &identityEmails)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for identities")
+
+		return nil, result.Error
+	}
+
+	return append([]string{user.Email}, identityEmails...), nil
+}
+
+func (s *serviceImpl) ListSuspects(ctx context.Context) ([]SuspectDto, error) {
+	suspects, err := s.listSuspects(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	unreviewed := make([]SuspectDto, 0, len(suspects))
+	for _, suspect := range suspects {
+		if suspect.Review == nil && suspect.Score >= suspectThreshold {
+			unreviewed = append(unreviewed, suspect)
+		}
+	}
+
+	if len(unreviewed) > maxSuspects {
+		unreviewed = unreviewed[:maxSuspects]
+	}
+
+	return unreviewed, nil
+}
+
+func (s *serviceImpl) ListUserSuspects(ctx context.Context, userId uint) ([]SuspectDto, error) {
+	result := s.Db.WithContext(ctx).First(&users.User{}, userId)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, users.ErrUserNotFound
+	} else if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to query for user")
+
+		return nil, result.Error
+	}
+
+	suspects, err := s.listSuspects(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range suspects {
+		if suspects[i].Account.Id != userId {
+			suspects[i].Account, suspects[i].Other = suspects[i].Other, suspects[i].Account
+		}
+	}
+
+	return suspects, nil
+}
+
+// The values two accounts share, of a kind.
+type sharedValues struct {
+	UserId      uint
+	OtherUserId uint
+	Kind        SignalKind
+	Shared      int
+}
+
+// List the pairs of accounts that share recently seen values, only the
+// user's if userId isn't 0, ordered by score. Pairs with deleted accounts
+// are left out.
+func (s *serviceImpl) listSuspects(ctx context.Context, userId uint) ([]SuspectDto, error) {
+	logger := log.FromContext(ctx)
+	db := s.Db.WithContext(ctx)
+
+	since := time.Now().Add(-signalWindow)
+
+	// Values are only signals if few accounts share them
+	query := `
+		SELECT a.user_id AS user_id, b.user_id AS other_user_id, a.kind AS kind, COUNT(*) AS shared
+		FROM account_sightings a
+		JOIN account_sightings b ON b.kind = a.kind AND b.value_hash = a.value_hash AND b.user_id > a.user_id
+		JOIN (
+			SELECT kind, value_hash
+			FROM account_sightings
+			WHERE last_seen_at > ?
+			GROUP BY kind, value_hash
+			HAVING COUNT(*) <= ?
+		) uncommon ON uncommon.kind = a.kind AND uncommon.value_hash = a.value_hash
+		WHERE a.last_seen_at > ? AND b.last_seen_at > ?`
+	params := []interface{}{since, maxAccountsPerValue, since, since}
+
+	if userId != 0 {
+		query += " AND (a.user_id = ? OR b.user_id = ?)"
+		params = append(params, userId, userId)
+	}
+
+	query += " GROUP BY a.user_id, b.user_id, a.kind"
+
+	var shared []sharedValues
+	result := db.Raw(query, params...).Scan(&shared)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to query for shared sightings")
+
+		return nil, result.Error
+	}
+
+	type pair struct {
+		userId      uint
+		otherUserId uint
+	}
+
+	suspects := map[pair]*SuspectDto{}
+	var pairs []pair
+	userIds := map[uint]bool{}
+
+	for _, values := range shared {
+		key := pair{values.UserId, values.OtherUserId}
+
+		suspect := suspects[key]
+		if suspect == nil {
+			suspect = &SuspectDto{
+				Account: AccountDto{Id: values.UserId},
+				Other:   AccountDto{Id: values.OtherUserId},
+				Signals: []SignalDto{},
+			}
+			suspects[key] = suspect
+			pairs = append(pairs, key)
+			userIds[values.UserId] = true
+			userIds[values.OtherUserId] = true
+		}
+
+		suspect.Score += scoreSignal(values.Kind, values.Shared)
+		suspect.Signals = append(suspect.Signals, SignalDto{
+			Kind:   values.Kind,
+			Shared: values.Shared,
+		})
+	}
+
+	if len(pairs) < 1 {
+		return []SuspectDto{}, nil
+	}
+
+	ids := make([]uint, 0, len(userIds))
+	for id := range userIds {
+		ids = append(ids, id)
+	}
+
+	// Deleted users are left out by the soft delete
+	var accounts []users.User
+	result = db.Select("id", "username", "created_at").Where("id IN ?", ids).Find(&accounts)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to query for users")
+
+		return nil, result.Error
+	}
+
+	var restricted []uint
+	result = db.Model(&moderation.Restriction{}).
+		Where("user_id IN ? AND (expires_at IS NULL OR expires_at > ?)", ids, time.Now()).
+		Distinct().
+		Pluck("user_id", &restricted)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to query for restrictions")
+
+		return nil, result.Error
+	}
+
+	var reviews []Review
+	result = db.Where("user_id IN ? AND other_user_id IN ?", ids, ids).Find(&reviews)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to query for reviews")
+
+		return nil, result.Error
+	}
+
+	accountsById := make(map[uint]AccountDto, len(accounts))
+	for _, account := range accounts {
+		accountsById[account.ID] = AccountDto{
+			Id:        account.ID,
+			Username:  account.Username,
+			CreatedAt: account.CreatedAt,
+		}
+	}
+
+	for _, id := range restricted {
+		account, ok := accountsById[id]
+		if ok {
+			account.Restricted = true
+			accountsById[id] = account
+		}
+	}
+
+	for _, review := range reviews {
+		suspect := suspects[pair{review.UserId, review.OtherUserId}]
+		if suspect != nil {
+			dto := reviewToDto(review)
+			suspect.Review = &dto
+		}
+	}
+
+	list := make([]SuspectDto, 0, len(pairs))
+	for _, key := range pairs {
+		account, ok := accountsById[key.userId]
+		other, otherOk := accountsById[key.otherUserId]
+		if !ok || !otherOk {
+			continue
+		}
+
+		suspect := suspects[key]
+		suspect.Account = account
+		suspect.Other = other
+
+		list = append(list, *suspect)
+	}
+
+	// Newer accounts first among equals, since they're the likelier evasions
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+
+		return list[i].Other.Id > list[j].Other.Id
+	})
+
+	return list, nil
+}
+
+// How much sharing values of a kind adds to a pair's score. Sharing more
+// than 3 values adds nothing more, e.g. two accounts on the same mobile
+// network share many addresses.
+func scoreSignal(kind SignalKind, shared int) int {
+	if shared > 3 {
+		shared = 3
+	}
+
+	return signalWeights[kind] * shared
+}
+
+func (s *serviceImpl) ReviewSuspect(ctx context.Context, moderatorId uint, userId uint, otherUserId uint, dto NewReviewDto) (ReviewDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return ReviewDto{}, err
+	}
+
+	if userId == otherUserId {
+		return ReviewDto{}, ErrSameAccount
+	}
+
+	// Pairs are stored with the lower id first
+	if otherUserId < userId {
+		userId, otherUserId = otherUserId, userId
+	}
+
+	logger := log.FromContext(ctx).WithFields(log.Fields{
+		"userId":      userId,
+		"otherUserId": otherUserId,
+	})
+
+	var count int64
+	result := s.Db.WithContext(ctx).Model(&users.User{}).Where("id IN ?", []uint{userId, otherUserId}).Count(&count)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to query for users")
+
+		return ReviewDto{}, result.Error
+	}
+
+	if count < 2 {
+		return ReviewDto{}, users.ErrUserNotFound
+	}
+
+	review := Review{
+		UserId:      userId,
+		OtherUserId: otherUserId,
+		Verdict:     dto.Verdict,
+		Note:        dto.Note,
+		ReviewedBy:  moderatorId,
+		UpdatedAt:   time.Now(),
+	}
+
+	result = s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "other_user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"verdict", "note", "reviewed_by", "updated_at"}),
+		}).
+		Create(&review)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to save review")
+
+		return ReviewDto{}, result.Error
+	}
+
+	err = s.AuditService.Record(ctx, moderatorId, "sockpuppets.review", "user", userId, map[string]interface{}{
+		"otherUserId": otherUserId,
+		"verdict":     review.Verdict,
+		"note":        review.Note,
+	})
+	if err != nil {
+		return ReviewDto{}, err
+	}
+
+	logger.WithField("verdict", review.Verdict).Info("Suspected duplicate accounts reviewed")
+
+	return reviewToDto(review), nil
+}
+
+func reviewToDto(review Review) ReviewDto {
+	return ReviewDto{
+		Verdict:    review.Verdict,
+		Note:       review.Note,
+		ReviewedBy: review.ReviewedBy,
+		ReviewedAt: review.UpdatedAt,
+	}
+}
+
+// Hex SHA-256 of a value, keyed with HashKey if it's set.
+func (s *serviceImpl) hash(value string) string {
+	if len(s.HashKey) < 1 {
+		hash := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(hash[:])
+	}
+
+	mac := hmac.New(sha256.New, s.HashKey)
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Normalize an email address so that the aliases of an address are the same:
+// lowercase and without a "+tag", and without dots for Gmail, which ignores
+// them. Returns the address and its domain, empty if it isn't an address.
+func normalizeEmail(email string) (string, string) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	at := strings.LastIndex(email, "@")
+	if at < 1 || at == len(email)-1 {
+		return "", ""
+	}
+
+	local, domain := email[:at], email[at+1:]
+
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+
+	if domain == "googlemail.com" {
+		domain = "gmail.com"
+	}
+
+	if domain == "gmail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+
+	return local + "@" + domain, domain
+}
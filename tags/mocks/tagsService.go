@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tagsService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	projects "github.com/open-collaboration/server/projects"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// RenameTag mocks base method
+func (m *MockService) RenameTag(ctx context.Context, moderatorId uint, from, to string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameTag", ctx, moderatorId, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameTag indicates an expected call of RenameTag
+func (mr *MockServiceMockRecorder) RenameTag(ctx, moderatorId, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameTag", reflect.TypeOf((*MockService)(nil).RenameTag), ctx, moderatorId, from, to)
+}
+
+// MergeTags mocks base method
+func (m *MockService) MergeTags(ctx context.Context, moderatorId uint, sources []string, target string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTags", ctx, moderatorId, sources, target)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeTags indicates an expected call of MergeTags
+func (mr *MockServiceMockRecorder) MergeTags(ctx, moderatorId, sources, target interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTags", reflect.TypeOf((*MockService)(nil).MergeTags), ctx, moderatorId, sources, target)
+}
+
+// BanTag mocks base method
+func (m *MockService) BanTag(ctx context.Context, moderatorId uint, tag, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BanTag", ctx, moderatorId, tag, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BanTag indicates an expected call of BanTag
+func (mr *MockServiceMockRecorder) BanTag(ctx, moderatorId, tag, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BanTag", reflect.TypeOf((*MockService)(nil).BanTag), ctx, moderatorId, tag, reason)
+}
+
+// UnbanTag mocks base method
+func (m *MockService) UnbanTag(ctx context.Context, moderatorId uint, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnbanTag", ctx, moderatorId, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnbanTag indicates an expected call of UnbanTag
+func (mr *MockServiceMockRecorder) UnbanTag(ctx, moderatorId, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnbanTag", reflect.TypeOf((*MockService)(nil).UnbanTag), ctx, moderatorId, tag)
+}
+
+// ListBannedTags mocks base method
+func (m *MockService) ListBannedTags(ctx context.Context) ([]projects.BannedTagDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBannedTags", ctx)
+	ret0, _ := ret[0].([]projects.BannedTagDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBannedTags indicates an expected call of ListBannedTags
+func (mr *MockServiceMockRecorder) ListBannedTags(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBannedTags", reflect.TypeOf((*MockService)(nil).ListBannedTags), ctx)
+}
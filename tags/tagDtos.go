@@ -0,0 +1,16 @@
+package tags
+
+type RenameTagDto struct {
+	From string `json:"from" validate:"required,max=40"`
+	To   string `json:"to" validate:"required,max=40,nefield=From"`
+}
+
+type MergeTagsDto struct {
+	Sources []string `json:"sources" validate:"required,min=1,max=20,dive,required,max=40"`
+	Target  string   `json:"target" validate:"required,max=40"`
+}
+
+type BanTagDto struct {
+	Tag    string `json:"tag" validate:"required,max=40"`
+	Reason string `json:"reason" validate:"max=500"`
+}
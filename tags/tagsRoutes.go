@@ -0,0 +1,171 @@
+package tags
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Rename a tag
+// @Description Renames the tag in all projects, saved searches and homepage sections.
+// @Tags moderation
+// @Router /moderation/tags/rename [post]
+// @Param rename body tags.RenameTagDto true "The tag and its new name"
+// @Success 200
+// @Failure 400 "The new name is banned"
+// @Failure 403
+func RouteRenameTag(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	tagsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	dto := RenameTagDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = tagsService.RenameTag(request.Context(), session.UserId(), dto.From, dto.To)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// @Summary Merge tags
+// @Description Replaces the source tags with the target tag in all projects, saved searches
+// @Description and homepage sections.
+// @Tags moderation
+// @Router /moderation/tags/merge [post]
+// @Param merge body tags.MergeTagsDto true "The tags to merge"
+// @Success 200
+// @Failure 400 "The target tag is banned"
+// @Failure 403
+func RouteMergeTags(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	tagsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	dto := MergeTagsDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = tagsService.MergeTags(request.Context(), session.UserId(), dto.Sources, dto.Target)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// @Summary List banned tags
+// @Tags moderation
+// @Router /moderation/tags/banned [get]
+// @Success 200 {array} dtos.BannedTagDto
+// @Failure 403
+func RouteListBannedTags(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	tagsService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	bannedTags, err := tagsService.ListBannedTags(request.Context())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, bannedTags)
+}
+
+// @Summary Ban a tag
+// @Description The tag is removed from all projects and can't be used anymore.
+// @Tags moderation
+// @Router /moderation/tags/banned [post]
+// @Param ban body tags.BanTagDto true "The tag to ban"
+// @Success 201
+// @Failure 403
+func RouteBanTag(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	tagsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	dto := BanTagDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = tagsService.BanTag(request.Context(), session.UserId(), dto.Tag, dto.Reason)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusCreated)
+
+	return nil
+}
+
+// @Summary Unban a tag
+// @Tags moderation
+// @Router /moderation/tags/banned/{tag} [delete]
+// @Param tag path string true "The tag"
+// @Success 204
+// @Failure 403
+// @Failure 404 "The tag is not banned"
+func RouteUnbanTag(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	tagsService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleModerator)
+	if err != nil {
+		return err
+	}
+
+	tag, ok := mux.Vars(request)["tag"]
+	if !ok {
+		return utils.ErrInvalidParam
+	}
+
+	err = tagsService.UnbanTag(request.Context(), session.UserId(), tag)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package tags
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/homepage"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/savedsearches"
+)
+
+// Moderation tools for project tags. Tags aren't stored on their own, they
+// live in the projects (and the saved searches and homepage sections that
+// refer to them), so this service keeps all of them consistent.
+type Service interface {
+	// Rename a tag everywhere. Same as merging the tag into the new one.
+	RenameTag(ctx context.Context, moderatorId uint, from string, to string) error
+
+	// Merge the source tags into the target tag everywhere. Projects are
+	// retagged in a single transaction.
+	// Returns projects.ErrTagBanned if the target tag is banned.
+	MergeTags(ctx context.Context, moderatorId uint, sources []string, target string) error
+
+	// Ban a tag and remove it from all projects.
+	BanTag(ctx context.Context, moderatorId uint, tag string, reason string) error
+
+	// Unban a tag. Returns projects.ErrTagNotBanned if the tag isn't banned.
+	UnbanTag(ctx context.Context, moderatorId uint, tag string) error
+
+	// List the banned tags, alphabetically.
+	ListBannedTags(ctx context.Context) ([]projects.BannedTagDto, error)
+}
+
+type serviceImpl struct {
+	ProjectsService      projects.Service
+	HomepageService      homepage.Service
+	SavedSearchesService savedsearches.Service
+	AuditService         audit.Service
+}
+
+func NewService(
+	projectsService projects.Service,
+	homepageService homepage.Service,
+	savedSearchesService savedsearches.Service,
+	auditService audit.Service,
+) Service {
+	return &serviceImpl{
+		ProjectsService:      projectsService,
+		HomepageService:      homepageService,
+		SavedSearchesService: savedSearchesService,
+		AuditService:         auditService,
+	}
+}
+
+func (s *serviceImpl) RenameTag(ctx context.Context, moderatorId uint, from string, to string) error {
+	return s.mergeTags(ctx, moderatorId, "tags.rename", []string{from}, to)
+}
+
+func (s *serviceImpl) MergeTags(ctx context.Context, moderatorId uint, sources []string, target string) error {
+	return s.mergeTags(ctx, moderatorId, "tags.merge", sources, target)
+}
+
+func (s *serviceImpl) mergeTags(ctx context.Context, moderatorId uint, action string, sources []string, target string) error {
+	logger := log.FromContext(ctx)
+
+	affected, err := s.ProjectsService.MergeTags(ctx, sources, target)
+	if err != nil {
+		return err
+	}
+
+	// The projects are already retagged at this point, so failing to update
+	// the rest is logged but doesn't fail the merge. Merging again fixes it.
+	err = s.SavedSearchesService.MergeTags(ctx, sources, target)
+	if err != nil {
+		logger.WithError(err).Error("Projects were retagged but saved searches were not")
+	}
+
+	err = s.HomepageService.MergeTags(ctx, sources, target)
+	if err != nil {
+		logger.WithError(err).Error("Projects were retagged but homepage sections were not")
+	}
+
+	return s.AuditService.Record(ctx, moderatorId, action, "tag", 0, map[string]interface{}{
+		"sources":          sources,
+		"target":           target,
+		"affectedProjects": affected,
+	})
+}
+
+func (s *serviceImpl) BanTag(ctx context.Context, moderatorId uint, tag string, reason string) error {
+	err := s.ProjectsService.BanTag(ctx, tag, reason)
+	if err != nil {
+		return err
+	}
+
+	s.HomepageService.InvalidateCache(ctx)
+
+	return s.AuditService.Record(ctx, moderatorId, "tags.ban", "tag", 0, map[string]interface{}{
+		"tag":    tag,
+		"reason": reason,
+	})
+}
+
+func (s *serviceImpl) UnbanTag(ctx context.Context, moderatorId uint, tag string) error {
+	err := s.ProjectsService.UnbanTag(ctx, tag)
+	if err != nil {
+		return err
+	}
+
+	return s.AuditService.Record(ctx, moderatorId, "tags.unban", "tag", 0, map[string]interface{}{
+		"tag": tag,
+	})
+}
+
+func (s *serviceImpl) ListBannedTags(ctx context.Context) ([]projects.BannedTagDto, error) {
+	return s.ProjectsService.ListBannedTags(ctx)
+}
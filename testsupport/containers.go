@@ -0,0 +1,234 @@
+// Package testsupport helps writing integration tests that run against real
+// Postgres and Redis servers.
+//
+// The servers are started as docker containers (the docker CLI has to be
+// installed) unless TEST_PG_DSN and TEST_REDIS_ADDR point to existing ones,
+// e.g. services of a CI job. Tests are skipped if neither is available. With
+// TEST_DB=sqlite each test gets its own SQLite database instead of Postgres.
+//
+// A typical test:
+//
+//	func TestLogin(t *testing.T) {
+//		env := testsupport.NewEnv(t)
+//		user := env.CreateUser(t, "alice", "password", users.RoleUser)
+//		server := env.NewServer(t, providers...)
+//		...
+//	}
+package testsupport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/open-collaboration/server/database"
+	"github.com/open-collaboration/server/migrations"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// How long to wait for a container to accept connections.
+const startTimeout = 60 * time.Second
+
+// A migrated database and an empty redis for a test.
+type Env struct {
+	Db    *gorm.DB
+	Redis *redis.Client
+}
+
+// Start (or connect to) the database and Redis, run the migrations and
+// return the connections. Everything is cleaned up when the test ends.
+func NewEnv(t testing.TB) *Env {
+	t.Helper()
+
+	redisAddr := os.Getenv("TEST_REDIS_ADDR")
+	if redisAddr == "" {
+		redisAddr = "localhost:" + startContainer(t, "6379/tcp", "redis:6")
+	}
+
+	env := &Env{}
+
+	if os.Getenv("TEST_DB") == "sqlite" {
+		env.Db = openSqlite(t)
+	} else {
+		env.Db = openPostgres(t)
+	}
+
+	env.Redis = redis.NewClient(&redis.Options{Addr: redisAddr})
+	t.Cleanup(func() {
+		_ = env.Redis.Close()
+	})
+
+	err := waitFor(func() error {
+		return env.Redis.Ping(context.Background()).Err()
+	})
+	if err != nil {
+		t.Fatalf("redis didn't start: %v", err)
+	}
+
+	err = migrations.GetMigration(env.Db).Migrate()
+	if err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	// Existing servers are shared between tests, start from a clean state
+	if os.Getenv("TEST_PG_DSN") != "" && os.Getenv("TEST_DB") != "sqlite" {
+		env.truncateTables(t)
+	}
+	if os.Getenv("TEST_REDIS_ADDR") != "" {
+		err = env.Redis.FlushDB(context.Background()).Err()
+		if err != nil {
+			t.Fatalf("failed to flush redis: %v", err)
+		}
+	}
+
+	return env
+}
+
+// Start (or connect to) Postgres.
+func openPostgres(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_PG_DSN")
+	if dsn == "" {
+		port := startContainer(t, "5432/tcp",
+			"-e", "POSTGRES_USER=test",
+			"-e", "POSTGRES_PASSWORD=test",
+			"-e", "POSTGRES_DB=test",
+			"postgres:13",
+		)
+		dsn = fmt.Sprintf("host=localhost port=%s user=test password=test dbname=test sslmode=disable", port)
+	}
+
+	var db *gorm.DB
+	err := waitFor(func() error {
+		var err error
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+		if err != nil {
+			return err
+		}
+
+		sqlDb, err := db.DB()
+		if err != nil {
+			return err
+		}
+
+		return sqlDb.Ping()
+	})
+	if err != nil {
+		t.Fatalf("postgres didn't start: %v", err)
+	}
+
+	return db
+}
+
+// Open a new SQLite database in the test's temporary directory. The features
+// that need Postgres are unavailable, see docs/database.md.
+func openSqlite(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(database.OpenSqlite(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+
+	sqlDb, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = sqlDb.Close()
+	})
+
+	return db
+}
+
+// Delete all rows of all tables except the migrations table.
+func (e *Env) truncateTables(t testing.TB) {
+	t.Helper()
+
+	var tables []string
+	err := e.Db.Raw(`
+		SELECT tablename
+		FROM pg_tables
+		WHERE schemaname = current_schema() AND tablename <> 'migrations'`,
+	).Scan(&tables).Error
+	if err != nil {
+		t.Fatalf("failed to list tables: %v", err)
+	}
+
+	if len(tables) == 0 {
+		return
+	}
+
+	err = e.Db.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE").Error
+	if err != nil {
+		t.Fatalf("failed to truncate tables: %v", err)
+	}
+}
+
+// Start a docker container that's removed when the test ends and return the
+// host port mapped to containerPort. Skips the test if docker isn't available.
+func startContainer(t testing.TB, containerPort string, args ...string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("docker is not installed, set TEST_PG_DSN and TEST_REDIS_ADDR to use existing servers")
+	}
+
+	output, err := runDocker(append([]string{"run", "-d", "--rm", "-p", "127.0.0.1::" + containerPort}, args...)...)
+	if err != nil {
+		t.Fatalf("failed to start container: %v", err)
+	}
+
+	containerId := strings.TrimSpace(output)
+	t.Cleanup(func() {
+		_, _ = runDocker("rm", "-f", containerId)
+	})
+
+	// E.g. "127.0.0.1:49153"
+	output, err = runDocker("port", containerId, containerPort)
+	if err != nil {
+		t.Fatalf("failed to get container port: %v", err)
+	}
+
+	address := strings.TrimSpace(strings.Split(output, "\n")[0])
+
+	return address[strings.LastIndex(address, ":")+1:]
+}
+
+func runDocker(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// Call fn until it succeeds or startTimeout elapses.
+func waitFor(fn func() error) error {
+	deadline := time.Now().Add(startTimeout)
+
+	for {
+		err := fn()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -0,0 +1,60 @@
+package testsupport
+
+import (
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/projects"
+	"github.com/open-collaboration/server/users"
+	"testing"
+)
+
+// Insert a user with the given password and role.
+func (e *Env) CreateUser(t testing.TB, username string, password string, role users.Role) *users.User {
+	t.Helper()
+
+	user := &users.User{
+		Username: username,
+		Email:    username + "@example.com",
+		Role:     role,
+	}
+
+	err := user.SetPassword(password)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	err = e.Db.Create(user).Error
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	return user
+}
+
+// Insert a listed (not pending review) project owned by ownerId.
+func (e *Env) CreateProject(t testing.TB, ownerId uint, name string, tags ...string) *projects.Project {
+	t.Helper()
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	project := &projects.Project{
+		OwnerId:          ownerId,
+		Name:             name,
+		Tags:             pq.StringArray(tags),
+		ShortDescription: name + " short description",
+		LongDescription:  name + " long description",
+		Languages:        pq.StringArray{},
+		Frameworks:       pq.StringArray{},
+		Platforms:        pq.StringArray{},
+		SpokenLanguages:  pq.StringArray{},
+		TimeZones:        pq.StringArray{},
+	}
+
+	err := e.Db.Create(project).Error
+	if err != nil {
+		t.Fatalf("failed to create project: %v", err)
+	}
+
+	return project
+}
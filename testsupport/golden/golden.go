@@ -0,0 +1,55 @@
+// Package golden compares values with golden files, the expected output
+// checked into a package's testdata directory.
+//
+// After a deliberate change of the output, rewrite the files and review
+// their diff:
+//
+//	go test ./projects/ -update
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata instead of comparing with them")
+
+// Compare value, marshalled as indented JSON like API responses, with
+// testdata/<name>.golden. With -update the file is written instead.
+func Check(t testing.TB, name string, value interface{}) {
+	t.Helper()
+
+	actual, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal %s: %v", name, err)
+	}
+	actual = append(actual, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		err = os.MkdirAll("testdata", 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, actual, 0644)
+		}
+		if err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s, run the test with -update to create it: %v", path, err)
+	}
+
+	if !bytes.Equal(actual, expected) {
+		t.Errorf("%s doesn't match %s, run the test with -update if the change is expected:\n%s", name, path, actual)
+	}
+}
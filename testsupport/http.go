@@ -0,0 +1,105 @@
+package testsupport
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/open-collaboration/server/router"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+)
+
+// An HTTP server running the application's routes, with a client that keeps
+// cookies (and so sessions) between requests.
+type Server struct {
+	Url    string
+	Client *http.Client
+}
+
+// Start a server with the routes of router.SetupRoutes and the given
+// providers. The server is closed when the test ends.
+func (e *Env) NewServer(t testing.TB, providers ...interface{}) *Server {
+	t.Helper()
+
+	httpServer := httptest.NewServer(router.SetupRoutes(providers))
+	t.Cleanup(httpServer.Close)
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatalf("failed to create cookie jar: %v", err)
+	}
+
+	return &Server{
+		Url: httpServer.URL,
+		Client: &http.Client{
+			Jar: jar,
+		},
+	}
+}
+
+// Send a request with body (if not nil) marshalled as JSON. If out is not nil
+// the response body is unmarshalled into it. Returns the response, whose body
+// is already closed.
+func (s *Server) Do(t testing.TB, method string, path string, body interface{}, out interface{}) *http.Response {
+	t.Helper()
+
+	var requestBody io.Reader
+	if body != nil {
+		bodyJson, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal request body: %v", err)
+		}
+
+		requestBody = bytes.NewReader(bodyJson)
+	}
+
+	request, err := http.NewRequest(method, s.Url+path, requestBody)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := s.Client.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer response.Body.Close()
+
+	if out != nil {
+		err = json.NewDecoder(response.Body).Decode(out)
+		if err != nil {
+			t.Fatalf("failed to unmarshal response of %s %s: %v", method, path, err)
+		}
+	}
+
+	return response
+}
+
+// Log in, so that the following requests are made with the user's session.
+func (s *Server) Login(t testing.TB, username string, password string) {
+	t.Helper()
+
+	response := s.Do(t, "POST", "/auth/login", map[string]string{
+		"usernameOrEmail": username,
+		"password":        password,
+	}, nil)
+
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("failed to log in as %s: status %d", username, response.StatusCode)
+	}
+}
+
+// Fail the test if a response doesn't have the expected status.
+func ExpectStatus(t testing.TB, response *http.Response, status int) {
+	t.Helper()
+
+	if response.StatusCode != status {
+		t.Errorf("%s %s: expected status %d, got %d",
+			response.Request.Method, response.Request.URL.Path, status, response.StatusCode)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/utils"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Serve the server according to TLS_MODE:
+//
+// "off" serves plain HTTP, e.g. behind a reverse proxy that terminates TLS.
+// "files" serves HTTPS with the certificate in TLS_CERT_FILE and TLS_KEY_FILE.
+// The files are reloaded when they change, so certificates renewed by e.g.
+// certbot are picked up without a restart.
+//
+// HTTP/2 is enabled whenever TLS is. If HTTP_REDIRECT_PORT is set, plain HTTP
+// requests to it are redirected to HTTPS.
+func serve(server *http.Server) error {
+	mode := utils.GetEnvOrDefault("TLS_MODE", "off")
+
+	switch mode {
+	case "off":
+		log.Infof("Serving at http://%s", server.Addr)
+
+		return server.ListenAndServe()
+
+	case "files":
+		certificate := &certificateFiles{
+			CertFile: utils.GetEnvOrPanic("TLS_CERT_FILE"),
+			KeyFile:  utils.GetEnvOrPanic("TLS_KEY_FILE"),
+		}
+
+		_, err := certificate.GetCertificate(nil)
+		if err != nil {
+			return err
+		}
+
+		server.TLSConfig = &tls.Config{
+			GetCertificate: certificate.GetCertificate,
+		}
+
+		serveRedirects(http.HandlerFunc(redirectToHttps))
+
+		log.Infof("Serving at https://%s", server.Addr)
+
+		// The certificate comes from TLSConfig. ListenAndServeTLS enables HTTP/2.
+		return server.ListenAndServeTLS("", "")
+
+	default:
+		return fmt.Errorf("unknown TLS_MODE %q", mode)
+	}
+}
+
+// Serve handler on HTTP_REDIRECT_PORT in the background, if it's set.
+func serveRedirects(handler http.Handler) {
+	port := utils.GetEnvOrDefault("HTTP_REDIRECT_PORT", "")
+	if port == "" {
+		return
+	}
+
+	redirectServer := &http.Server{
+		Addr:    fmt.Sprintf("%s:%s", utils.GetEnvOrPanic("HOST"), port),
+		Handler: handler,
+	}
+
+	go func() {
+		log.Infof("Redirecting http://%s to https", redirectServer.Addr)
+
+		err := redirectServer.ListenAndServe()
+		if err != nil {
+			log.WithError(err).Error("Failed to serve HTTP redirects")
+		}
+	}()
+}
+
+// Redirect a plain HTTP request to the same url on HTTPS.
+func redirectToHttps(writer http.ResponseWriter, request *http.Request) {
+	host := request.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	// Redirects go to the default HTTPS port, unless PORT is another one
+	if port := utils.GetEnvOrPanic("PORT"); port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+
+	http.Redirect(writer, request, "https://"+host+request.URL.RequestURI(), http.StatusMovedPermanently)
+}
+
+// A TLS certificate loaded from files, reloaded when the certificate file
+// is modified.
+type certificateFiles struct {
+	CertFile string
+	KeyFile  string
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+	checkedAt   time.Time
+}
+
+// Get the certificate, for tls.Config.GetCertificate. The certificate file
+// is checked for changes at most once a minute.
+func (c *certificateFiles) GetCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.certificate != nil && time.Since(c.checkedAt) < time.Minute {
+		return c.certificate, nil
+	}
+
+	c.checkedAt = time.Now()
+
+	info, err := os.Stat(c.CertFile)
+	if err != nil {
+		if c.certificate != nil {
+			// Keep serving the old certificate
+			log.WithError(err).Error("Failed to check TLS certificate file")
+
+			return c.certificate, nil
+		}
+
+		return nil, err
+	}
+
+	if c.certificate != nil && !info.ModTime().After(c.modTime) {
+		return c.certificate, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		if c.certificate != nil {
+			// The key file may not be written yet, retry on the next check
+			log.WithError(err).Error("Failed to reload TLS certificate")
+
+			return c.certificate, nil
+		}
+
+		return nil, err
+	}
+
+	if c.certificate != nil {
+		log.Info("Reloaded TLS certificate")
+	}
+
+	c.certificate = &certificate
+	c.modTime = info.ModTime()
+
+	return c.certificate, nil
+}
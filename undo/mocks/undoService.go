@@ -0,0 +1,76 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: undoService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	undo "github.com/open-collaboration/server/undo"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method
+func (m *MockService) Record(ctx context.Context, userId uint, kind string, targetId uint) (undo.UndoDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, userId, kind, targetId)
+	ret0, _ := ret[0].(undo.UndoDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Record indicates an expected call of Record
+func (mr *MockServiceMockRecorder) Record(ctx, userId, kind, targetId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockService)(nil).Record), ctx, userId, kind, targetId)
+}
+
+// Undo mocks base method
+func (m *MockService) Undo(ctx context.Context, userId uint, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Undo", ctx, userId, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Undo indicates an expected call of Undo
+func (mr *MockServiceMockRecorder) Undo(ctx, userId, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Undo", reflect.TypeOf((*MockService)(nil).Undo), ctx, userId, token)
+}
+
+// Handle mocks base method
+func (m *MockService) Handle(kind string, handler undo.Handler) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Handle", kind, handler)
+}
+
+// Handle indicates an expected call of Handle
+func (mr *MockServiceMockRecorder) Handle(kind, handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Handle", reflect.TypeOf((*MockService)(nil).Handle), kind, handler)
+}
@@ -0,0 +1,13 @@
+package undo
+
+import "time"
+
+type UndoDto struct {
+	// Send to POST /undo to take the action back
+	Token     string    `json:"undoToken"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type NewUndoDto struct {
+	Token string `json:"undoToken"`
+}
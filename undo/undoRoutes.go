@@ -0,0 +1,39 @@
+package undo
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Undo an action
+// @Description Takes back an action that returned an undo token, e.g. DELETE
+// @Description /projects/{projectId}/chat/messages/{messageId}. Tokens can only be used once, by the user who did
+// @Description the action, and expire 30 seconds after it.
+// @Tags undo
+// @Router /undo [post]
+// @Param undo body undo.NewUndoDto true "The action's undo token"
+// @Success 204
+// @Failure 401
+// @Failure 410 "The token doesn't exist, expired or was already used"
+func RouteUndo(writer http.ResponseWriter, request *http.Request, undoService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := NewUndoDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = undoService.Undo(request.Context(), session.UserId(), dto.Token)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
@@ -0,0 +1,176 @@
+// Package undo lets users take back destructive actions for a short while,
+// e.g. deleting a chat message by accident. Actions are carried out right
+// away and their undo tokens are stored in the kv store (see docs/redis.md):
+// undoing an action runs the handler registered for its kind.
+package undo
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/kv"
+	"sync"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("undo token is invalid or expired")
+
+// How long an action can be undone.
+const Window = 30 * time.Second
+
+// An action that can be undone, e.g. the deletion of a chat message.
+type Action struct {
+	// What was done, see Service.Handle
+	Kind string `json:"kind"`
+
+	// Who did it, the only user who can undo it
+	UserId uint `json:"userId"`
+
+	// What it was done to, e.g. the deleted message's id
+	TargetId uint `json:"targetId"`
+}
+
+// Undoes an action of the kind it's registered for.
+type Handler func(ctx context.Context, action Action) error
+
+type Service interface {
+	// Record an action the user can undo during Window.
+	Record(ctx context.Context, userId uint, kind string, targetId uint) (UndoDto, error)
+
+	// Undo the action of a token, which can only be used once.
+	// Returns ErrInvalidToken if the token doesn't exist, expired, was
+	// already used or isn't the user's, or the handler's error.
+	Undo(ctx context.Context, userId uint, token string) error
+
+	// Register how actions of a kind are undone. Must be called before the
+	// server starts.
+	Handle(kind string, handler Handler)
+}
+
+type serviceImpl struct {
+	Kv kv.Store
+
+	mutex    sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewService(kvStore kv.Store) Service {
+	return &serviceImpl{
+		Kv:       kvStore,
+		handlers: map[string]Handler{},
+	}
+}
+
+func (s *serviceImpl) Record(ctx context.Context, userId uint, kind string, targetId uint) (UndoDto, error) {
+	token, err := generateToken()
+	if err != nil {
+		return UndoDto{}, err
+	}
+
+	value, err := json.Marshal(Action{Kind: kind, UserId: userId, TargetId: targetId})
+	if err != nil {
+		return UndoDto{}, err
+	}
+
+	expiresAt := time.Now().Add(Window)
+
+	err = s.Kv.Set(ctx, tokenKey(token), string(value), Window)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).WithField("kind", kind).Error("Failed to store undo token")
+
+		return UndoDto{}, err
+	}
+
+	return UndoDto{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+func (s *serviceImpl) Undo(ctx context.Context, userId uint, token string) error {
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	action, err := s.getAction(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	// Someone else's token is left for its user
+	if action.UserId != userId {
+		return ErrInvalidToken
+	}
+
+	// Only one of concurrent undos takes the token
+	_, err = s.Kv.Take(ctx, tokenKey(token))
+	if errors.Is(err, kv.ErrNotFound) {
+		return ErrInvalidToken
+	} else if err != nil {
+		logger.WithError(err).Error("Failed to take undo token")
+
+		return err
+	}
+
+	s.mutex.RLock()
+	handler, ok := s.handlers[action.Kind]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("no undo handler for %s", action.Kind)
+	}
+
+	err = handler(ctx, action)
+	if err != nil {
+		return err
+	}
+
+	logger.WithFields(log.Fields{"kind": action.Kind, "targetId": action.TargetId}).Info("Action undone")
+
+	return nil
+}
+
+func (s *serviceImpl) getAction(ctx context.Context, token string) (Action, error) {
+	if token == "" {
+		return Action{}, ErrInvalidToken
+	}
+
+	value, err := s.Kv.Get(ctx, tokenKey(token))
+	if errors.Is(err, kv.ErrNotFound) {
+		return Action{}, ErrInvalidToken
+	} else if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to get undo token")
+
+		return Action{}, err
+	}
+
+	var action Action
+	err = json.Unmarshal([]byte(value), &action)
+	if err != nil {
+		return Action{}, ErrInvalidToken
+	}
+
+	return action, nil
+}
+
+func (s *serviceImpl) Handle(kind string, handler Handler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.handlers[kind] = handler
+}
+
+func tokenKey(token string) string {
+	return "undo:" + token
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, 16)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
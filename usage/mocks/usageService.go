@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usageService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	usage "github.com/open-collaboration/server/usage"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CountRequest mocks base method
+func (m *MockService) CountRequest(ctx context.Context, userId uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRequest", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CountRequest indicates an expected call of CountRequest
+func (mr *MockServiceMockRecorder) CountRequest(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRequest", reflect.TypeOf((*MockService)(nil).CountRequest), ctx, userId)
+}
+
+// GetUsage mocks base method
+func (m *MockService) GetUsage(ctx context.Context, userId uint, days int) (usage.UsageDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsage", ctx, userId, days)
+	ret0, _ := ret[0].(usage.UsageDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsage indicates an expected call of GetUsage
+func (mr *MockServiceMockRecorder) GetUsage(ctx, userId, days interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsage", reflect.TypeOf((*MockService)(nil).GetUsage), ctx, userId, days)
+}
+
+// ListTopUsers mocks base method
+func (m *MockService) ListTopUsers(ctx context.Context, day string) ([]usage.UserUsageDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTopUsers", ctx, day)
+	ret0, _ := ret[0].([]usage.UserUsageDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTopUsers indicates an expected call of ListTopUsers
+func (mr *MockServiceMockRecorder) ListTopUsers(ctx, day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTopUsers", reflect.TypeOf((*MockService)(nil).ListTopUsers), ctx, day)
+}
+
+// SetQuota mocks base method
+func (m *MockService) SetQuota(ctx context.Context, setBy, userId uint, dto usage.QuotaDto) (usage.UsageDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetQuota", ctx, setBy, userId, dto)
+	ret0, _ := ret[0].(usage.UsageDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetQuota indicates an expected call of SetQuota
+func (mr *MockServiceMockRecorder) SetQuota(ctx, setBy, userId, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetQuota", reflect.TypeOf((*MockService)(nil).SetQuota), ctx, setBy, userId, dto)
+}
+
+// Run mocks base method
+func (m *MockService) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run
+func (mr *MockServiceMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockService)(nil).Run), ctx)
+}
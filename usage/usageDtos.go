@@ -0,0 +1,38 @@
+package usage
+
+type DayUsageDto struct {
+	// YYYY-MM-DD, UTC
+	Day      string `json:"day"`
+	Requests int64  `json:"requests"`
+}
+
+type UsageDto struct {
+	UserId uint `json:"userId"`
+
+	// The user's daily request quota, null if unlimited
+	Quota *int64 `json:"quota"`
+
+	// Whether the quota was set for the user by an admin rather than being
+	// the default one
+	QuotaOverridden bool `json:"quotaOverridden"`
+
+	// Requests made today, and how many are left until midnight UTC (null if
+	// unlimited)
+	Today     int64  `json:"today"`
+	Remaining *int64 `json:"remaining"`
+
+	// Every day of the period, oldest to newest, including today
+	Days []DayUsageDto `json:"days"`
+}
+
+type UserUsageDto struct {
+	UserId   uint   `json:"userId"`
+	Username string `json:"username"`
+	Requests int64  `json:"requests"`
+}
+
+type QuotaDto struct {
+	// Requests per day, 0 for unlimited. null removes the user's quota, so
+	// that the default one applies.
+	DailyRequests *int64 `json:"dailyRequests" validate:"omitempty,min=0"`
+}
@@ -0,0 +1,66 @@
+package usage
+
+import (
+	"errors"
+	"github.com/apex/log"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Counts the requests of users with a session, for their usage and quota.
+// Requests over a user's daily quota are rejected with 429 until midnight
+// UTC, except the ones to see their usage and to log out. Impersonated
+// requests aren't counted.
+//
+// Must be used after auth.SessionMiddleware.
+func UsageMiddleware(usageService Service) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := auth.CheckSession(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if _, impersonated := session.ImpersonatorId(); impersonated ||
+				r.URL.Path == "/users/me/usage" || strings.HasPrefix(r.URL.Path, "/auth/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			logger := log.FromContext(ctx)
+
+			err = usageService.CountRequest(ctx, session.UserId())
+			if errors.Is(err, ErrQuotaExceeded) {
+				logger.Debug("Rejecting request over the daily quota")
+
+				now := time.Now().UTC()
+				midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+				w.Header().Set("Retry-After", strconv.Itoa(int(midnight.Sub(now).Seconds())+1))
+
+				err = utils.WriteJson(w, ctx, http.StatusTooManyRequests, map[string]interface{}{
+					"code":    "usage-quota-exceeded-error",
+					"details": map[string]interface{}{},
+				})
+				if err != nil {
+					logger.WithError(err).Error("Failed to write error response")
+				}
+
+				return
+			} else if err != nil {
+				logger.WithError(err).Error("Failed to count request")
+				w.WriteHeader(http.StatusInternalServerError)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package usage
+
+import "time"
+
+// How many requests a user made with their sessions on a day, UTC.
+// Impersonated requests aren't counted.
+type DailyUsage struct {
+	UserId uint `gorm:"primaryKey"`
+
+	// YYYY-MM-DD
+	Day string `gorm:"primaryKey"`
+
+	Requests int64
+}
+
+func (DailyUsage) TableName() string {
+	return "daily_usage"
+}
+
+// A user's daily request quota set by an admin, overriding the default one,
+// e.g. a higher one for a trusted integration or a lower one for a script
+// that hammers the API.
+type Quota struct {
+	UserId uint `gorm:"primaryKey"`
+
+	// 0 is unlimited
+	DailyRequests int64
+
+	SetBy     uint
+	UpdatedAt time.Time
+}
+
+func (Quota) TableName() string {
+	return "usage_quotas"
+}
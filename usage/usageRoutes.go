@@ -0,0 +1,142 @@
+package usage
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Get my API usage
+// @Description How many requests the user made with their sessions each day (UTC), e.g. from scripts using their
+// @Description session, and their daily quota. Requests over the quota are rejected with 429 and the
+// @Description usage-quota-exceeded-error code until midnight UTC. Requests of other instances are counted with a
+// @Description delay of up to 30 seconds.
+// @Tags users
+// @Router /users/me/usage [get]
+// @Param days query int false "How many days to cover, including today. Default is 30, max is 90."
+// @Success 200 {object} usage.UsageDto
+// @Failure 401
+func RouteGetMyUsage(writer http.ResponseWriter, request *http.Request, usageService Service) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	days, _ := utils.IntFromQuery(request, "days", DefaultDays)
+
+	usage, err := usageService.GetUsage(request.Context(), session.UserId(), days)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, usage)
+}
+
+// @Summary List the users making the most requests
+// @Description The 100 users that made the most requests on a day, most first, e.g. to spot automation.
+// @Tags admin
+// @Router /admin/usage [get]
+// @Param day query string false "The day (YYYY-MM-DD, UTC). Default is today."
+// @Success 200 {array} usage.UserUsageDto
+// @Failure 400 "The day isn't a YYYY-MM-DD date"
+// @Failure 403
+func RouteListTopUsers(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	usageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	topUsers, err := usageService.ListTopUsers(request.Context(), request.URL.Query().Get("day"))
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, topUsers)
+}
+
+// @Summary Get a user's API usage
+// @Tags admin
+// @Router /admin/users/{userId}/usage [get]
+// @Param userId path int true "The user ID"
+// @Param days query int false "How many days to cover, including today. Default is 30, max is 90."
+// @Success 200 {object} usage.UsageDto
+// @Failure 403
+// @Failure 404
+func RouteGetUserUsage(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	usageService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	_, err = usersService.GetUser(request.Context(), userId)
+	if err != nil {
+		return err
+	}
+
+	days, _ := utils.IntFromQuery(request, "days", DefaultDays)
+
+	usage, err := usageService.GetUsage(request.Context(), userId, days)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, usage)
+}
+
+// @Summary Set a user's daily request quota
+// @Description Override the default quota (USAGE_DAILY_QUOTA) for the user, e.g. raise it for a trusted integration
+// @Description or lower it for a script that hammers the API. The user's quota applies even if they're a moderator
+// @Description or an admin. Quota changes are recorded in the audit log.
+// @Tags admin
+// @Router /admin/users/{userId}/usage-quota [put]
+// @Param userId path int true "The user ID"
+// @Param quota body usage.QuotaDto true "The quota"
+// @Success 200 {object} usage.UsageDto
+// @Failure 400
+// @Failure 403
+// @Failure 404
+func RouteSetQuota(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	usageService Service,
+) error {
+	session, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	userId, err := utils.UintFromVars(request, "userId")
+	if err != nil {
+		return err
+	}
+
+	dto := QuotaDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	usage, err := usageService.SetQuota(request.Context(), session.UserId(), userId, dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, usage)
+}
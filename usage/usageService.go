@@ -0,0 +1,487 @@
+package usage
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/audit"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sync"
+	"time"
+)
+
+var ErrQuotaExceeded = errors.New("daily request quota exceeded")
+var ErrInvalidDay = errors.New("invalid day")
+
+// How often Run writes the requests counted by the instance to the
+// database. Quotas are enforced with the other instances' requests as of
+// their last flush, so users can go over them by a few requests.
+const flushInterval = 30 * time.Second
+
+// How many days usage covers by default, and at most.
+const DefaultDays = 30
+const MaxDays = 90
+
+// How many users are listed by ListTopUsers.
+const topUsersLimit = 100
+
+type Service interface {
+	// Count a request made with one of the user's sessions. The request
+	// shouldn't be served if ErrQuotaExceeded is returned, it isn't counted.
+	// Returns ErrQuotaExceeded if the user already made as many requests
+	// today as their daily quota.
+	CountRequest(ctx context.Context, userId uint) error
+
+	// Get how many requests the user made each day of the last days (see
+	// MaxDays) and their quota.
+	GetUsage(ctx context.Context, userId uint, days int) (UsageDto, error)
+
+	// List the users that made the most requests on a day, most first.
+	// Returns ErrInvalidDay if day isn't a YYYY-MM-DD date.
+	ListTopUsers(ctx context.Context, day string) ([]UserUsageDto, error)
+
+	// Set or remove a user's own daily request quota, and record it in the
+	// audit log.
+	// Returns users.ErrUserNotFound if the user can't be found.
+	SetQuota(ctx context.Context, setBy uint, userId uint, dto QuotaDto) (UsageDto, error)
+
+	// Write the counted requests to the database, and refresh the quotas,
+	// every flushInterval until ctx is done. Should be run in its own
+	// goroutine.
+	Run(ctx context.Context)
+}
+
+type usageKey struct {
+	userId uint
+	day    string
+}
+
+type serviceImpl struct {
+	Db           *gorm.DB
+	UsersService users.Service
+	AuditService audit.Service
+
+	// The daily request quota of users without their own quota, 0 for
+	// unlimited. Moderators and admins have no default quota.
+	DefaultQuota int64
+
+	mutex sync.Mutex
+
+	// Requests counted since the last flush
+	pending map[usageKey]int64
+
+	// The day the following are of, and the requests of today's users as of
+	// the last flush, across instances
+	day       string
+	persisted map[uint]int64
+
+	// Whether users are moderators or admins, looked up once a day
+	staff map[uint]bool
+
+	// Users' own daily quotas
+	quotas map[uint]int64
+}
+
+func NewService(db *gorm.DB, usersService users.Service, auditService audit.Service, defaultQuota int64) Service {
+	return &serviceImpl{
+		Db:           db,
+		UsersService: usersService,
+		AuditService: auditService,
+		DefaultQuota: defaultQuota,
+		pending:      map[usageKey]int64{},
+		persisted:    map[uint]int64{},
+		staff:        map[uint]bool{},
+		quotas:       map[uint]int64{},
+	}
+}
+
+// The current day, UTC, as YYYY-MM-DD.
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// Forget the previous day's counts at midnight. Must be called with the
+// mutex locked.
+func (s *serviceImpl) rollOver(day string) {
+	if s.day != day {
+		s.day = day
+		s.persisted = map[uint]int64{}
+		s.staff = map[uint]bool{}
+	}
+}
+
+func (s *serviceImpl) CountRequest(ctx context.Context, userId uint) error {
+	day := today()
+	key := usageKey{userId: userId, day: day}
+
+	s.mutex.Lock()
+	s.rollOver(day)
+
+	quota, overridden := s.quotas[userId]
+	if !overridden {
+		quota = s.DefaultQuota
+	}
+
+	_, known := s.persisted[userId]
+	s.mutex.Unlock()
+
+	// The user's requests on other instances, if they weren't counted on
+	// this one yet today, refreshed by flushes afterwards
+	if quota > 0 && !known {
+		err := s.loadPersisted(ctx, userId, day)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	used := s.persisted[userId] + s.pending[key]
+
+	if quota > 0 && used >= quota {
+		staff, known := s.staff[userId]
+		s.mutex.Unlock()
+
+		if overridden {
+			return ErrQuotaExceeded
+		}
+
+		if !known {
+			var err error
+			staff, err = s.isStaff(ctx, userId)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !staff {
+			return ErrQuotaExceeded
+		}
+
+		s.mutex.Lock()
+	}
+
+	s.pending[key]++
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Get the user's requests of the day written to the database.
+func (s *serviceImpl) loadPersisted(ctx context.Context, userId uint, day string) error {
+	var requests int64
+	result := s.Db.WithContext(ctx).
+		Model(&DailyUsage{}).
+		Select("requests").
+		Where("user_id = ? AND day = ?", userId, day).
+		Scan(&requests)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("userId", userId).Error("Failed to get today's usage")
+
+		return result.Error
+	}
+
+	s.mutex.Lock()
+	if _, known := s.persisted[userId]; s.day == day && !known {
+		s.persisted[userId] = requests
+	}
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Whether the user is a moderator or an admin, who have no default quota.
+func (s *serviceImpl) isStaff(ctx context.Context, userId uint) (bool, error) {
+	user, err := s.UsersService.GetUser(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	staff := user.Role.Includes(users.RoleModerator)
+
+	s.mutex.Lock()
+	s.staff[userId] = staff
+	s.mutex.Unlock()
+
+	return staff, nil
+}
+
+func (s *serviceImpl) GetUsage(ctx context.Context, userId uint, days int) (UsageDto, error) {
+	if days < 1 || days > MaxDays {
+		days = DefaultDays
+	}
+
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1-days)
+
+	var rows []DailyUsage
+	result := s.Db.WithContext(ctx).
+		Where("user_id = ? AND day >= ?", userId, since.Format("2006-01-02")).
+		Find(&rows)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get usage")
+
+		return UsageDto{}, result.Error
+	}
+
+	var quota Quota
+	result = s.Db.WithContext(ctx).Limit(1).Find(&quota, "user_id = ?", userId)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get usage quota")
+
+		return UsageDto{}, result.Error
+	}
+
+	requests := map[string]int64{}
+	for _, row := range rows {
+		requests[row.Day] += row.Requests
+	}
+
+	// Requests that weren't flushed yet
+	s.mutex.Lock()
+	for key, count := range s.pending {
+		if key.userId == userId {
+			requests[key.day] += count
+		}
+	}
+	s.mutex.Unlock()
+
+	dto := UsageDto{
+		UserId:          userId,
+		QuotaOverridden: result.RowsAffected > 0,
+		Days:            make([]DayUsageDto, days),
+	}
+
+	for i := range dto.Days {
+		day := since.AddDate(0, 0, i).Format("2006-01-02")
+		dto.Days[i] = DayUsageDto{Day: day, Requests: requests[day]}
+	}
+
+	dto.Today = dto.Days[days-1].Requests
+
+	dailyQuota := s.DefaultQuota
+	if dto.QuotaOverridden {
+		dailyQuota = quota.DailyRequests
+	} else if dailyQuota > 0 {
+		staff, err := s.isStaff(ctx, userId)
+		if err != nil {
+			return UsageDto{}, err
+		}
+
+		if staff {
+			dailyQuota = 0
+		}
+	}
+
+	if dailyQuota > 0 {
+		remaining := dailyQuota - dto.Today
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		dto.Quota = &dailyQuota
+		dto.Remaining = &remaining
+	}
+
+	return dto, nil
+}
+
+func (s *serviceImpl) ListTopUsers(ctx context.Context, day string) ([]UserUsageDto, error) {
+	if day == "" {
+		day = today()
+	}
+
+	_, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return nil, ErrInvalidDay
+	}
+
+	topUsers := []UserUsageDto{}
+	result := s.Db.WithContext(ctx).
+		Table("daily_usage").
+		Select("daily_usage.user_id, users.username, daily_usage.requests").
+		Joins("JOIN users ON users.id = daily_usage.user_id").
+		Where("daily_usage.day = ?", day).
+		Order("daily_usage.requests desc, daily_usage.user_id").
+		Limit(topUsersLimit).
+		Scan(&topUsers)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).WithField("day", day).Error("Failed to list top users")
+
+		return nil, result.Error
+	}
+
+	return topUsers, nil
+}
+
+func (s *serviceImpl) SetQuota(ctx context.Context, setBy uint, userId uint, dto QuotaDto) (UsageDto, error) {
+	err := validator.New().Struct(dto)
+	if err != nil {
+		return UsageDto{}, err
+	}
+
+	_, err = s.UsersService.GetUser(ctx, userId)
+	if err != nil {
+		return UsageDto{}, err
+	}
+
+	logger := log.FromContext(ctx).WithField("userId", userId)
+
+	var result *gorm.DB
+	if dto.DailyRequests == nil {
+		result = s.Db.WithContext(ctx).Delete(&Quota{}, "user_id = ?", userId)
+	} else {
+		result = s.Db.WithContext(ctx).
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"daily_requests", "set_by", "updated_at"}),
+			}).
+			Create(&Quota{
+				UserId:        userId,
+				DailyRequests: *dto.DailyRequests,
+				SetBy:         setBy,
+			})
+	}
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to set usage quota")
+
+		return UsageDto{}, result.Error
+	}
+
+	s.mutex.Lock()
+	if dto.DailyRequests == nil {
+		delete(s.quotas, userId)
+	} else {
+		s.quotas[userId] = *dto.DailyRequests
+	}
+	s.mutex.Unlock()
+
+	err = s.AuditService.Record(ctx, setBy, "usage.quota", "user", userId, map[string]interface{}{
+		"dailyRequests": dto.DailyRequests,
+	})
+	if err != nil {
+		return UsageDto{}, err
+	}
+
+	return s.GetUsage(ctx, userId, DefaultDays)
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	s.flush(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// ctx is done, requests still have a moment to be written
+			flushCtx, cancel := context.WithTimeout(log.NewContext(context.Background(), log.FromContext(ctx)), flushInterval)
+			s.flush(flushCtx)
+			cancel()
+
+			return
+
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// Write the requests counted since the last flush, then get the day's
+// requests of the users counted today, and the users' quotas.
+func (s *serviceImpl) flush(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	s.mutex.Lock()
+	pending := s.pending
+	s.pending = map[usageKey]int64{}
+	s.mutex.Unlock()
+
+	if len(pending) > 0 {
+		rows := make([]DailyUsage, 0, len(pending))
+		for key, count := range pending {
+			rows = append(rows, DailyUsage{UserId: key.userId, Day: key.day, Requests: count})
+		}
+
+		result := s.Db.WithContext(ctx).
+			Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"requests": gorm.Expr("daily_usage.requests + excluded.requests"),
+				}),
+			}).
+			CreateInBatches(&rows, 500)
+
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to write usage")
+
+			// Kept for the next flush
+			s.mutex.Lock()
+			for key, count := range pending {
+				s.pending[key] += count
+			}
+			s.mutex.Unlock()
+
+			return
+		}
+	}
+
+	day := today()
+
+	s.mutex.Lock()
+	s.rollOver(day)
+	userIds := make([]uint, 0, len(s.persisted))
+	for userId := range s.persisted {
+		userIds = append(userIds, userId)
+	}
+	s.mutex.Unlock()
+
+	for key := range pending {
+		if key.day == day {
+			userIds = append(userIds, key.userId)
+		}
+	}
+
+	var rows []DailyUsage
+	if len(userIds) > 0 {
+		result := s.Db.WithContext(ctx).Where("day = ? AND user_id IN ?", day, userIds).Find(&rows)
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to get today's usage")
+
+			return
+		}
+	}
+
+	var quotas []Quota
+	result := s.Db.WithContext(ctx).Find(&quotas)
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to get usage quotas")
+
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.day == day {
+		for _, row := range rows {
+			s.persisted[row.UserId] = row.Requests
+		}
+	}
+
+	s.quotas = map[uint]int64{}
+	for _, quota := range quotas {
+		s.quotas[quota.UserId] = quota.DailyRequests
+	}
+}
@@ -0,0 +1,185 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type gormRepository struct {
+	Db *gorm.DB
+}
+
+func NewGormRepository(db *gorm.DB) Repository {
+	return &gormRepository{Db: db}
+}
+
+func (r *gormRepository) CreateUser(ctx context.Context, user *User, claims ...func(tx *gorm.DB) error) error {
+	return r.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(user).Error
+		if err != nil {
+			return err
+		}
+
+		for _, claim := range claims {
+			err = claim(tx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *gormRepository) GetUser(ctx context.Context, id uint) (*User, error) {
+	user := &User{}
+	result := r.Db.WithContext(ctx).First(user, id)
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return user, nil
+}
+
+func (r *gormRepository) FindUserByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*User, error) {
+	user := &User{}
+	result := r.Db.WithContext(ctx).
+		Where("username = ?", usernameOrEmail).
+		Or("email = ?", usernameOrEmail).
+		First(user)
+
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	} else if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return user, nil
+}
+
+func (r *gormRepository) ListUsersByUsernameOrEmail(ctx context.Context, username string, email string) ([]User, error) {
+	var users []User
+	result := r.Db.WithContext(ctx).
+		Where("username = ?", username).
+		Or("email = ?", email).
+		Find(&users)
+
+	return users, result.Error
+}
+
+func (r *gormRepository) ListUsersWithRoles(ctx context.Context, roles []Role) ([]User, error) {
+	var users []User
+	result := r.Db.WithContext(ctx).Where("role IN ?", roles).Find(&users)
+
+	return users, result.Error
+}
+
+func (r *gormRepository) UpdatePasswordHash(ctx context.Context, id uint, passwordHash string) error {
+	result := r.Db.WithContext(ctx).Model(&User{}).Where("id = ?", id).Update("password_hash", passwordHash)
+
+	return userUpdateError(result)
+}
+
+func (r *gormRepository) UpdateSettings(ctx context.Context, id uint, settings SettingsDto) error {
+	result := r.Db.WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"timezone": settings.Timezone,
+			"locale":   settings.Locale,
+		})
+
+	return userUpdateError(result)
+}
+
+func (r *gormRepository) UpdatePrivacySettings(ctx context.Context, id uint, settings PrivacySettingsDto) error {
+	result := r.Db.WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"hide_email":                  settings.HideEmail,
+			"hide_profile_from_anonymous": settings.HideProfileFromAnonymous,
+			"hide_memberships":            settings.HideMemberships,
+		})
+
+	return userUpdateError(result)
+}
+
+func (r *gormRepository) UpdateDirectoryProfile(ctx context.Context, id uint, profile DirectoryProfileDto) error {
+	result := r.Db.WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"discoverable":            profile.Discoverable,
+			"skills":                  pq.StringArray(profile.Skills),
+			"availability":            profile.Availability,
+			"availability_expires_at": profile.AvailabilityExpiresAt,
+		})
+
+	return userUpdateError(result)
+}
+
+func (r *gormRepository) ListDiscoverableUsers(
+	ctx context.Context,
+	filters DirectoryFilters,
+	now time.Time,
+	pageSize uint,
+	pageOffset uint,
+) ([]User, int64, error) {
+	query := r.Db.WithContext(ctx).Model(&User{}).Where("discoverable = true")
+
+	if len(filters.Skills) > 0 {
+		query = query.Where(database.DialectOf(r.Db).Overlaps("skills"), pq.StringArray(filters.Skills))
+	}
+
+	// Expired availabilities are unavailable, see User.AvailabilityAt
+	effectiveAvailability := "CASE WHEN availability_expires_at <= ? THEN 'unavailable' ELSE availability END"
+
+	if len(filters.Availabilities) > 0 {
+		query = query.Where(effectiveAvailability+" IN ?", now, filters.Availabilities)
+	}
+
+	var totalCount int64
+	result := query.Session(&gorm.Session{}).Count(&totalCount)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	// Users available for work first, the most available first
+	var users []User
+	result = query.
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL: "CASE " + effectiveAvailability + " WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END, updated_at desc, id desc",
+			Vars: []interface{}{
+				now,
+				AvailableForWork[0],
+				AvailableForWork[1],
+			},
+			WithoutParentheses: true,
+		}}).
+		Limit(int(pageSize)).
+		Offset(int(pageOffset * pageSize)).
+		Find(&users)
+
+	return users, totalCount, result.Error
+}
+
+// ErrUserNotFound if an update of a single user didn't match any row.
+func userUpdateError(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
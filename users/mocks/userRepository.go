@@ -0,0 +1,188 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: userRepository.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	users "github.com/open-collaboration/server/users"
+	gorm "gorm.io/gorm"
+	reflect "reflect"
+	time "time"
+)
+
+// MockRepository is a mock of Repository interface
+type MockRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRepositoryMockRecorder
+}
+
+// MockRepositoryMockRecorder is the mock recorder for MockRepository
+type MockRepositoryMockRecorder struct {
+	mock *MockRepository
+}
+
+// NewMockRepository creates a new mock instance
+func NewMockRepository(ctrl *gomock.Controller) *MockRepository {
+	mock := &MockRepository{ctrl: ctrl}
+	mock.recorder = &MockRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method
+func (m *MockRepository) CreateUser(ctx context.Context, user *users.User, claims ...func(*gorm.DB) error) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, user}
+	for _, a := range claims {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateUser", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser
+func (mr *MockRepositoryMockRecorder) CreateUser(ctx, user interface{}, claims ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, user}, claims...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), varargs...)
+}
+
+// GetUser mocks base method
+func (m *MockRepository) GetUser(ctx context.Context, id uint) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, id)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser
+func (mr *MockRepositoryMockRecorder) GetUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx, id)
+}
+
+// FindUserByUsernameOrEmail mocks base method
+func (m *MockRepository) FindUserByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByUsernameOrEmail", ctx, usernameOrEmail)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByUsernameOrEmail indicates an expected call of FindUserByUsernameOrEmail
+func (mr *MockRepositoryMockRecorder) FindUserByUsernameOrEmail(ctx, usernameOrEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByUsernameOrEmail", reflect.TypeOf((*MockRepository)(nil).FindUserByUsernameOrEmail), ctx, usernameOrEmail)
+}
+
+// ListUsersByUsernameOrEmail mocks base method
+func (m *MockRepository) ListUsersByUsernameOrEmail(ctx context.Context, username, email string) ([]users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersByUsernameOrEmail", ctx, username, email)
+	ret0, _ := ret[0].([]users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersByUsernameOrEmail indicates an expected call of ListUsersByUsernameOrEmail
+func (mr *MockRepositoryMockRecorder) ListUsersByUsernameOrEmail(ctx, username, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersByUsernameOrEmail", reflect.TypeOf((*MockRepository)(nil).ListUsersByUsernameOrEmail), ctx, username, email)
+}
+
+// ListUsersWithRoles mocks base method
+func (m *MockRepository) ListUsersWithRoles(ctx context.Context, roles []users.Role) ([]users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersWithRoles", ctx, roles)
+	ret0, _ := ret[0].([]users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersWithRoles indicates an expected call of ListUsersWithRoles
+func (mr *MockRepositoryMockRecorder) ListUsersWithRoles(ctx, roles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersWithRoles", reflect.TypeOf((*MockRepository)(nil).ListUsersWithRoles), ctx, roles)
+}
+
+// UpdatePasswordHash mocks base method
+func (m *MockRepository) UpdatePasswordHash(ctx context.Context, id uint, passwordHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, id, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash
+func (mr *MockRepositoryMockRecorder) UpdatePasswordHash(ctx, id, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockRepository)(nil).UpdatePasswordHash), ctx, id, passwordHash)
+}
+
+// UpdateSettings mocks base method
+func (m *MockRepository) UpdateSettings(ctx context.Context, id uint, settings users.SettingsDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, id, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings
+func (mr *MockRepositoryMockRecorder) UpdateSettings(ctx, id, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockRepository)(nil).UpdateSettings), ctx, id, settings)
+}
+
+// UpdatePrivacySettings mocks base method
+func (m *MockRepository) UpdatePrivacySettings(ctx context.Context, id uint, settings users.PrivacySettingsDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrivacySettings", ctx, id, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrivacySettings indicates an expected call of UpdatePrivacySettings
+func (mr *MockRepositoryMockRecorder) UpdatePrivacySettings(ctx, id, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrivacySettings", reflect.TypeOf((*MockRepository)(nil).UpdatePrivacySettings), ctx, id, settings)
+}
+
+// UpdateDirectoryProfile mocks base method
+func (m *MockRepository) UpdateDirectoryProfile(ctx context.Context, id uint, profile users.DirectoryProfileDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDirectoryProfile", ctx, id, profile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDirectoryProfile indicates an expected call of UpdateDirectoryProfile
+func (mr *MockRepositoryMockRecorder) UpdateDirectoryProfile(ctx, id, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDirectoryProfile", reflect.TypeOf((*MockRepository)(nil).UpdateDirectoryProfile), ctx, id, profile)
+}
+
+// ListDiscoverableUsers mocks base method
+func (m *MockRepository) ListDiscoverableUsers(ctx context.Context, filters users.DirectoryFilters, now time.Time, pageSize, pageOffset uint) ([]users.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDiscoverableUsers", ctx, filters, now, pageSize, pageOffset)
+	ret0, _ := ret[0].([]users.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDiscoverableUsers indicates an expected call of ListDiscoverableUsers
+func (mr *MockRepositoryMockRecorder) ListDiscoverableUsers(ctx, filters, now, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDiscoverableUsers", reflect.TypeOf((*MockRepository)(nil).ListDiscoverableUsers), ctx, filters, now, pageSize, pageOffset)
+}
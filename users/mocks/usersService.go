@@ -0,0 +1,326 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: usersService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	users "github.com/open-collaboration/server/users"
+	gorm "gorm.io/gorm"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateUser mocks base method
+func (m *MockService) CreateUser(ctx context.Context, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", ctx, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateUser indicates an expected call of CreateUser
+func (mr *MockServiceMockRecorder) CreateUser(ctx, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockService)(nil).CreateUser), ctx, newUser)
+}
+
+// GetUser mocks base method
+func (m *MockService) GetUser(ctx context.Context, id uint) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, id)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser
+func (mr *MockServiceMockRecorder) GetUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockService)(nil).GetUser), ctx, id)
+}
+
+// FindUserByUsernameOrEmail mocks base method
+func (m *MockService) FindUserByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByUsernameOrEmail", ctx, usernameOrEmail)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByUsernameOrEmail indicates an expected call of FindUserByUsernameOrEmail
+func (mr *MockServiceMockRecorder) FindUserByUsernameOrEmail(ctx, usernameOrEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByUsernameOrEmail", reflect.TypeOf((*MockService)(nil).FindUserByUsernameOrEmail), ctx, usernameOrEmail)
+}
+
+// RemovePassword mocks base method
+func (m *MockService) RemovePassword(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePassword", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePassword indicates an expected call of RemovePassword
+func (mr *MockServiceMockRecorder) RemovePassword(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePassword", reflect.TypeOf((*MockService)(nil).RemovePassword), ctx, id)
+}
+
+// ListUsersWithRole mocks base method
+func (m *MockService) ListUsersWithRole(ctx context.Context, role users.Role) ([]users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersWithRole", ctx, role)
+	ret0, _ := ret[0].([]users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersWithRole indicates an expected call of ListUsersWithRole
+func (mr *MockServiceMockRecorder) ListUsersWithRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersWithRole", reflect.TypeOf((*MockService)(nil).ListUsersWithRole), ctx, role)
+}
+
+// GetSettings mocks base method
+func (m *MockService) GetSettings(ctx context.Context, id uint) (users.SettingsDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSettings", ctx, id)
+	ret0, _ := ret[0].(users.SettingsDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSettings indicates an expected call of GetSettings
+func (mr *MockServiceMockRecorder) GetSettings(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSettings", reflect.TypeOf((*MockService)(nil).GetSettings), ctx, id)
+}
+
+// UpdateSettings mocks base method
+func (m *MockService) UpdateSettings(ctx context.Context, id uint, settings users.SettingsDto) (users.SettingsDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, id, settings)
+	ret0, _ := ret[0].(users.SettingsDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings
+func (mr *MockServiceMockRecorder) UpdateSettings(ctx, id, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockService)(nil).UpdateSettings), ctx, id, settings)
+}
+
+// GetPrivacySettings mocks base method
+func (m *MockService) GetPrivacySettings(ctx context.Context, id uint) (users.PrivacySettingsDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrivacySettings", ctx, id)
+	ret0, _ := ret[0].(users.PrivacySettingsDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrivacySettings indicates an expected call of GetPrivacySettings
+func (mr *MockServiceMockRecorder) GetPrivacySettings(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrivacySettings", reflect.TypeOf((*MockService)(nil).GetPrivacySettings), ctx, id)
+}
+
+// UpdatePrivacySettings mocks base method
+func (m *MockService) UpdatePrivacySettings(ctx context.Context, id uint, settings users.PrivacySettingsDto) (users.PrivacySettingsDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrivacySettings", ctx, id, settings)
+	ret0, _ := ret[0].(users.PrivacySettingsDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePrivacySettings indicates an expected call of UpdatePrivacySettings
+func (mr *MockServiceMockRecorder) UpdatePrivacySettings(ctx, id, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrivacySettings", reflect.TypeOf((*MockService)(nil).UpdatePrivacySettings), ctx, id, settings)
+}
+
+// GetDirectoryProfile mocks base method
+func (m *MockService) GetDirectoryProfile(ctx context.Context, id uint) (users.DirectoryProfileDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDirectoryProfile", ctx, id)
+	ret0, _ := ret[0].(users.DirectoryProfileDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDirectoryProfile indicates an expected call of GetDirectoryProfile
+func (mr *MockServiceMockRecorder) GetDirectoryProfile(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDirectoryProfile", reflect.TypeOf((*MockService)(nil).GetDirectoryProfile), ctx, id)
+}
+
+// UpdateDirectoryProfile mocks base method
+func (m *MockService) UpdateDirectoryProfile(ctx context.Context, id uint, profile users.DirectoryProfileDto) (users.DirectoryProfileDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDirectoryProfile", ctx, id, profile)
+	ret0, _ := ret[0].(users.DirectoryProfileDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDirectoryProfile indicates an expected call of UpdateDirectoryProfile
+func (mr *MockServiceMockRecorder) UpdateDirectoryProfile(ctx, id, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDirectoryProfile", reflect.TypeOf((*MockService)(nil).UpdateDirectoryProfile), ctx, id, profile)
+}
+
+// ListDirectory mocks base method
+func (m *MockService) ListDirectory(ctx context.Context, viewerId uint, filters users.DirectoryFilters, pageSize, pageOffset uint) ([]users.DirectoryUserDto, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDirectory", ctx, viewerId, filters, pageSize, pageOffset)
+	ret0, _ := ret[0].([]users.DirectoryUserDto)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListDirectory indicates an expected call of ListDirectory
+func (mr *MockServiceMockRecorder) ListDirectory(ctx, viewerId, filters, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDirectory", reflect.TypeOf((*MockService)(nil).ListDirectory), ctx, viewerId, filters, pageSize, pageOffset)
+}
+
+// MockRegistrationGuard is a mock of RegistrationGuard interface
+type MockRegistrationGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistrationGuardMockRecorder
+}
+
+// MockRegistrationGuardMockRecorder is the mock recorder for MockRegistrationGuard
+type MockRegistrationGuardMockRecorder struct {
+	mock *MockRegistrationGuard
+}
+
+// NewMockRegistrationGuard creates a new mock instance
+func NewMockRegistrationGuard(ctrl *gomock.Controller) *MockRegistrationGuard {
+	mock := &MockRegistrationGuard{ctrl: ctrl}
+	mock.recorder = &MockRegistrationGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRegistrationGuard) EXPECT() *MockRegistrationGuardMockRecorder {
+	return m.recorder
+}
+
+// CheckRegistration mocks base method
+func (m *MockRegistrationGuard) CheckRegistration(ctx context.Context, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRegistration", ctx, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRegistration indicates an expected call of CheckRegistration
+func (mr *MockRegistrationGuardMockRecorder) CheckRegistration(ctx, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRegistration", reflect.TypeOf((*MockRegistrationGuard)(nil).CheckRegistration), ctx, newUser)
+}
+
+// MockRegistrationClaim is a mock of RegistrationClaim interface
+type MockRegistrationClaim struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistrationClaimMockRecorder
+}
+
+// MockRegistrationClaimMockRecorder is the mock recorder for MockRegistrationClaim
+type MockRegistrationClaimMockRecorder struct {
+	mock *MockRegistrationClaim
+}
+
+// NewMockRegistrationClaim creates a new mock instance
+func NewMockRegistrationClaim(ctrl *gomock.Controller) *MockRegistrationClaim {
+	mock := &MockRegistrationClaim{ctrl: ctrl}
+	mock.recorder = &MockRegistrationClaimMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRegistrationClaim) EXPECT() *MockRegistrationClaimMockRecorder {
+	return m.recorder
+}
+
+// ClaimRegistration mocks base method
+func (m *MockRegistrationClaim) ClaimRegistration(ctx context.Context, tx *gorm.DB, user *users.User, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimRegistration", ctx, tx, user, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimRegistration indicates an expected call of ClaimRegistration
+func (mr *MockRegistrationClaimMockRecorder) ClaimRegistration(ctx, tx, user, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimRegistration", reflect.TypeOf((*MockRegistrationClaim)(nil).ClaimRegistration), ctx, tx, user, newUser)
+}
+
+// MockRegistrationListener is a mock of RegistrationListener interface
+type MockRegistrationListener struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistrationListenerMockRecorder
+}
+
+// MockRegistrationListenerMockRecorder is the mock recorder for MockRegistrationListener
+type MockRegistrationListenerMockRecorder struct {
+	mock *MockRegistrationListener
+}
+
+// NewMockRegistrationListener creates a new mock instance
+func NewMockRegistrationListener(ctrl *gomock.Controller) *MockRegistrationListener {
+	mock := &MockRegistrationListener{ctrl: ctrl}
+	mock.recorder = &MockRegistrationListenerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockRegistrationListener) EXPECT() *MockRegistrationListenerMockRecorder {
+	return m.recorder
+}
+
+// UserRegistered mocks base method
+func (m *MockRegistrationListener) UserRegistered(ctx context.Context, user *users.User, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserRegistered", ctx, user, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UserRegistered indicates an expected call of UserRegistered
+func (mr *MockRegistrationListenerMockRecorder) UserRegistered(ctx, user, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRegistered", reflect.TypeOf((*MockRegistrationListener)(nil).UserRegistered), ctx, user, newUser)
+}
@@ -0,0 +1,55 @@
+package users
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidAvailability = errors.New("invalid availability")
+
+// Whether a user is looking for projects to join, shown on their directory
+// profile.
+type Availability string
+
+const (
+	AvailabilityActivelyLooking Availability = "actively-looking"
+	AvailabilityOpenToOffers    Availability = "open-to-offers"
+	AvailabilityUnavailable     Availability = "unavailable"
+)
+
+// Availabilities of users that are available for work, from the most to the
+// least available. The directory lists available users first, in this
+// order.
+var AvailableForWork = []Availability{AvailabilityActivelyLooking, AvailabilityOpenToOffers}
+
+// A user's availability at a time: an availability that expired is
+// AvailabilityUnavailable, like one that was never set.
+func (user *User) AvailabilityAt(now time.Time) Availability {
+	if user.Availability == "" || (user.AvailabilityExpiresAt != nil && !user.AvailabilityExpiresAt.After(now)) {
+		return AvailabilityUnavailable
+	}
+
+	return user.Availability
+}
+
+// Check an availability and its expiry set at a time. Being unavailable
+// doesn't expire.
+// Returns ErrInvalidAvailability if the availability is unknown or expires
+// before now.
+func checkAvailability(availability Availability, expiresAt *time.Time, now time.Time) error {
+	switch availability {
+	case AvailabilityActivelyLooking, AvailabilityOpenToOffers:
+	case AvailabilityUnavailable:
+		if expiresAt != nil {
+			return ErrInvalidAvailability
+		}
+	default:
+		return ErrInvalidAvailability
+	}
+
+	if expiresAt != nil && !expiresAt.After(now) {
+		return ErrInvalidAvailability
+	}
+
+	return nil
+}
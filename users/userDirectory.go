@@ -0,0 +1,85 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"strings"
+	"time"
+)
+
+func (s *serviceImpl) GetDirectoryProfile(ctx context.Context, id uint) (DirectoryProfileDto, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return DirectoryProfileDto{}, err
+	}
+
+	return userToDirectoryProfileDto(user, time.Now()), nil
+}
+
+func (s *serviceImpl) UpdateDirectoryProfile(ctx context.Context, id uint, profile DirectoryProfileDto) (DirectoryProfileDto, error) {
+	err := validator.New().Struct(profile)
+	if err != nil {
+		return DirectoryProfileDto{}, err
+	}
+
+	if profile.Availability == "" {
+		profile.Availability = AvailabilityUnavailable
+	}
+
+	err = checkAvailability(profile.Availability, profile.AvailabilityExpiresAt, time.Now())
+	if err != nil {
+		return DirectoryProfileDto{}, err
+	}
+
+	profile.Skills = normalizeSkills(profile.Skills)
+
+	err = s.Repository.UpdateDirectoryProfile(ctx, id, profile)
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) {
+			log.FromContext(ctx).WithError(err).Error("Failed to update directory profile")
+		}
+
+		return DirectoryProfileDto{}, err
+	}
+
+	return profile, nil
+}
+
+func (s *serviceImpl) ListDirectory(
+	ctx context.Context,
+	viewerId uint,
+	filters DirectoryFilters,
+	pageSize uint,
+	pageOffset uint,
+) ([]DirectoryUserDto, int64, error) {
+	filters.Skills = normalizeSkills(filters.Skills)
+
+	now := time.Now()
+
+	users, totalCount, err := s.Repository.ListDiscoverableUsers(ctx, filters, now, pageSize, pageOffset)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to list the user directory")
+
+		return nil, 0, err
+	}
+
+	return usersToDirectoryUserDtos(users, viewerId, now), totalCount, nil
+}
+
+// Lowercase skills, collapse their whitespace and drop empty and duplicate
+// ones, so that e.g. "Go" and "go " match.
+func normalizeSkills(skills []string) []string {
+	seen := map[string]bool{}
+	normalized := make([]string, 0, len(skills))
+	for _, skill := range skills {
+		skill = strings.ToLower(strings.Join(strings.Fields(skill), " "))
+		if skill != "" && !seen[skill] {
+			seen[skill] = true
+			normalized = append(normalized, skill)
+		}
+	}
+
+	return normalized
+}
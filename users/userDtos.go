@@ -1,13 +1,79 @@
 package users
 
+import (
+	"time"
+)
+
 type NewUserDto struct {
 	Username       string `json:"username" validate:"required,min=4,max=32"`
 	Email          string `json:"email" validate:"required,email"`
 	Password       string `json:"password" validate:"required,min=6,max=255"`
 	RecaptchaToken string `json:"recaptchaToken"`
+
+	// Only required when the registration mode is invite-only.
+	InviteCode string `json:"inviteCode"`
+
+	// Only required when the registration mode is waitlist. Sent to
+	// the user in the waitlist activation email.
+	SignupToken string `json:"signupToken"`
 }
 
 type UserDataDto struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 }
+
+type SettingsDto struct {
+	// IANA time zone name, e.g. "Europe/Lisbon"
+	Timezone string `json:"timezone" validate:"required,max=64"`
+
+	// BCP 47 language tag, e.g. "pt-BR"
+	Locale string `json:"locale" validate:"required,max=35"`
+}
+
+// Who can see what about a user. Users always see everything about
+// themselves, and moderators see what they need to moderate.
+type PrivacySettingsDto struct {
+	// Never show the user's email to other users. On by default.
+	HideEmail bool `json:"hideEmail"`
+
+	// Only show the user's profile (e.g. their portfolio and contributions)
+	// to signed in users.
+	HideProfileFromAnonymous bool `json:"hideProfileFromAnonymous"`
+
+	// Don't list the projects the user is a member of, e.g. in their
+	// contributions or in the projects' contributions.
+	HideMemberships bool `json:"hideMemberships"`
+}
+
+// What the user directory (GET /users) shows about a user. Users are only
+// listed once they opt in with Discoverable.
+type DirectoryProfileDto struct {
+	Discoverable bool     `json:"discoverable"`
+	Skills       []string `json:"skills" validate:"max=20,dive,min=1,max=40"`
+
+	// actively-looking, open-to-offers or unavailable (the default). Once it
+	// expires the user is unavailable again.
+	Availability          Availability `json:"availability" validate:"omitempty,oneof=actively-looking open-to-offers unavailable"`
+	AvailabilityExpiresAt *time.Time   `json:"availabilityExpiresAt"`
+}
+
+// A user listed in the directory. Emails are only listed if the user shows
+// them, see PrivacySettingsDto.
+type DirectoryUserDto struct {
+	Id                    uint         `json:"id"`
+	Username              string       `json:"username"`
+	Email                 string       `json:"email,omitempty"`
+	Skills                []string     `json:"skills"`
+	Availability          Availability `json:"availability"`
+	AvailabilityExpiresAt *time.Time   `json:"availabilityExpiresAt"`
+}
+
+type DirectoryFilters struct {
+	// Users with at least one of the skills
+	Skills []string
+
+	// Users with one of the availabilities, e.g. AvailableForWork. Expired
+	// availabilities are unavailable.
+	Availabilities []Availability
+}
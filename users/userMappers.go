@@ -0,0 +1,93 @@
+package users
+
+import (
+	"time"
+)
+
+// Conversions between users' models and DTOs, see projects' mappers.
+
+// The user of a NewUserDto, without a password, which has to be hashed with
+// User.SetPassword.
+func newUserToModel(dto NewUserDto) User {
+	return User{
+		Username: dto.Username,
+		Email:    dto.Email,
+	}
+}
+
+func UserToDataDto(user *User) UserDataDto {
+	return UserDataDto{
+		Username: user.Username,
+		Email:    user.Email,
+	}
+}
+
+func userToPrivacySettingsDto(user *User) PrivacySettingsDto {
+	return PrivacySettingsDto{
+		HideEmail:                user.HideEmail,
+		HideProfileFromAnonymous: user.HideProfileFromAnonymous,
+		HideMemberships:          user.HideMemberships,
+	}
+}
+
+// The availability is the user's at now, see User.AvailabilityAt.
+func userToDirectoryProfileDto(user *User, now time.Time) DirectoryProfileDto {
+	availability, expiresAt := availabilityAt(user, now)
+
+	return DirectoryProfileDto{
+		Discoverable:          user.Discoverable,
+		Skills:                skillsOrEmpty(user.Skills),
+		Availability:          availability,
+		AvailabilityExpiresAt: expiresAt,
+	}
+}
+
+// Emails are only mapped if they're visible to viewerId.
+func usersToDirectoryUserDtos(users []User, viewerId uint, now time.Time) []DirectoryUserDto {
+	dtos := make([]DirectoryUserDto, len(users))
+	for i := range users {
+		availability, expiresAt := availabilityAt(&users[i], now)
+
+		email := ""
+		if users[i].EmailVisibleTo(viewerId) {
+			email = users[i].Email
+		}
+
+		dtos[i] = DirectoryUserDto{
+			Id:                    users[i].ID,
+			Username:              users[i].Username,
+			Email:                 email,
+			Skills:                skillsOrEmpty(users[i].Skills),
+			Availability:          availability,
+			AvailabilityExpiresAt: expiresAt,
+		}
+	}
+
+	return dtos
+}
+
+// A user's availability at now and when it expires, nil once it expired.
+func availabilityAt(user *User, now time.Time) (Availability, *time.Time) {
+	availability := user.AvailabilityAt(now)
+	if availability == AvailabilityUnavailable {
+		return availability, nil
+	}
+
+	return availability, user.AvailabilityExpiresAt
+}
+
+// Users that never set their skills have none, not null
+func skillsOrEmpty(skills []string) []string {
+	if skills == nil {
+		return []string{}
+	}
+
+	return skills
+}
+
+func userToSettingsDto(user *User) SettingsDto {
+	return SettingsDto{
+		Timezone: user.Timezone,
+		Locale:   user.Locale,
+	}
+}
@@ -0,0 +1,73 @@
+package users
+
+import (
+	"github.com/lib/pq"
+	"github.com/open-collaboration/server/testsupport/golden"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testUsers() []User {
+	tomorrow := now.Add(24 * time.Hour)
+	yesterday := now.Add(-24 * time.Hour)
+
+	return []User{
+		{
+			Model:                 gorm.Model{ID: 1},
+			Username:              "alice",
+			Email:                 "alice@example.com",
+			Skills:                pq.StringArray{"go", "postgres"},
+			Discoverable:          true,
+			Availability:          AvailabilityActivelyLooking,
+			AvailabilityExpiresAt: &tomorrow,
+			HideEmail:             true,
+			Timezone:              "Europe/Lisbon",
+			Locale:                "pt",
+		},
+		{
+			Model:                 gorm.Model{ID: 2},
+			Username:              "bob",
+			Email:                 "bob@example.com",
+			Discoverable:          true,
+			Availability:          AvailabilityOpenToOffers,
+			AvailabilityExpiresAt: &yesterday,
+			HideEmail:             false,
+			HideMemberships:       true,
+			Timezone:              "UTC",
+			Locale:                "en",
+		},
+	}
+}
+
+func TestNewUserToModel(t *testing.T) {
+	user := newUserToModel(NewUserDto{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "password",
+	})
+
+	golden.Check(t, "new_user_to_model", user)
+}
+
+func TestUserToDtos(t *testing.T) {
+	for _, user := range testUsers() {
+		user := user
+		t.Run(user.Username, func(t *testing.T) {
+			golden.Check(t, user.Username+"_to_data_dto", UserToDataDto(&user))
+			golden.Check(t, user.Username+"_to_privacy_settings_dto", userToPrivacySettingsDto(&user))
+			golden.Check(t, user.Username+"_to_settings_dto", userToSettingsDto(&user))
+
+			// bob's availability expired
+			golden.Check(t, user.Username+"_to_directory_profile_dto", userToDirectoryProfileDto(&user, now))
+		})
+	}
+}
+
+func TestUsersToDirectoryUserDtos(t *testing.T) {
+	// alice hides her email from everyone but herself
+	golden.Check(t, "users_to_directory_user_dtos_anonymous", usersToDirectoryUserDtos(testUsers(), 0, now))
+	golden.Check(t, "users_to_directory_user_dtos_alice", usersToDirectoryUserDtos(testUsers(), 1, now))
+}
@@ -2,16 +2,80 @@ package users
 
 import (
 	"errors"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"time"
 )
 
+// A Role determines what a user is allowed to do in the platform
+// besides the things every user can do (e.g. moderate content or
+// manage the platform's settings).
+type Role string
+
+const (
+	RoleUser Role = "user"
+
+	// Organizers run events such as hackathons, grouping projects in
+	// collections (see the collections package).
+	RoleOrganizer Role = "organizer"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+// Roles are hierarchical, a role has all the permissions of the
+// roles with a lower rank.
+var roleRanks = map[Role]int{
+	RoleUser:      0,
+	RoleOrganizer: 1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// Check whether role has all the permissions of other. E.g.
+// RoleAdmin includes RoleModerator, but RoleModerator does not
+// include RoleAdmin.
+func (role Role) Includes(other Role) bool {
+	return roleRanks[role] >= roleRanks[other]
+}
+
 type User struct {
 	gorm.Model
 
 	Username     string
 	Email        string
 	PasswordHash string
+	Role         Role `gorm:"default:user"`
+
+	// IANA time zone name, e.g. "Europe/Lisbon"
+	Timezone string `gorm:"default:UTC"`
+
+	// BCP 47 language tag, e.g. "pt-BR"
+	Locale string `gorm:"default:en"`
+
+	// The user's directory profile, see DirectoryProfileDto. Only
+	// discoverable users are listed in the directory.
+	Skills       pq.StringArray `gorm:"type: TEXT[]"`
+	Discoverable bool
+
+	// See AvailabilityAt. Nil if the availability doesn't expire.
+	Availability          Availability `gorm:"default:unavailable"`
+	AvailabilityExpiresAt *time.Time
+
+	// See PrivacySettingsDto. Emails are hidden unless the user shows them.
+	HideEmail                bool `gorm:"default:true"`
+	HideProfileFromAnonymous bool
+	HideMemberships          bool
+}
+
+// The user's time zone, or UTC if it can't be loaded.
+func (user *User) Location() *time.Location {
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
 }
 
 func (user *User) SetPassword(plainTextPassword string) error {
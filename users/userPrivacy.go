@@ -0,0 +1,45 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"github.com/apex/log"
+)
+
+// What a user shows to others, see PrivacySettingsDto. viewerId is the id of
+// the user looking, 0 for anonymous visitors. Users always see everything
+// about themselves.
+
+func (user *User) ProfileVisibleTo(viewerId uint) bool {
+	return viewerId != 0 || !user.HideProfileFromAnonymous
+}
+
+func (user *User) EmailVisibleTo(viewerId uint) bool {
+	return viewerId == user.ID || !user.HideEmail
+}
+
+func (user *User) MembershipsVisibleTo(viewerId uint) bool {
+	return viewerId == user.ID || !user.HideMemberships
+}
+
+func (s *serviceImpl) GetPrivacySettings(ctx context.Context, id uint) (PrivacySettingsDto, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return PrivacySettingsDto{}, err
+	}
+
+	return userToPrivacySettingsDto(user), nil
+}
+
+func (s *serviceImpl) UpdatePrivacySettings(ctx context.Context, id uint, settings PrivacySettingsDto) (PrivacySettingsDto, error) {
+	err := s.Repository.UpdatePrivacySettings(ctx, id, settings)
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) {
+			log.FromContext(ctx).WithError(err).Error("Failed to update privacy settings")
+		}
+
+		return PrivacySettingsDto{}, err
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,51 @@
+package users
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Persists users. The service keeps the business rules (guards, password
+// hashing, settings validation, ...), so that they can be tested with a mocked
+// repository.
+type Repository interface {
+	// Store a new user, setting its id. claims are run after the user is
+	// inserted, in the same transaction: if one fails the user isn't created
+	// and the error is returned.
+	CreateUser(ctx context.Context, user *User, claims ...func(tx *gorm.DB) error) error
+
+	// Get a user by id.
+	// Returns ErrUserNotFound if the user doesn't exist.
+	GetUser(ctx context.Context, id uint) (*User, error)
+
+	// Get a user whose username or email is usernameOrEmail.
+	// Returns ErrUserNotFound if there's no such user.
+	FindUserByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*User, error)
+
+	// List the users with the username or the email.
+	ListUsersByUsernameOrEmail(ctx context.Context, username string, email string) ([]User, error)
+
+	// List the users with any of the roles.
+	ListUsersWithRoles(ctx context.Context, roles []Role) ([]User, error)
+
+	// Returns ErrUserNotFound if the user doesn't exist.
+	UpdatePasswordHash(ctx context.Context, id uint, passwordHash string) error
+
+	// Returns ErrUserNotFound if the user doesn't exist.
+	UpdateSettings(ctx context.Context, id uint, settings SettingsDto) error
+
+	// Returns ErrUserNotFound if the user doesn't exist.
+	UpdatePrivacySettings(ctx context.Context, id uint, settings PrivacySettingsDto) error
+
+	// Returns ErrUserNotFound if the user doesn't exist.
+	UpdateDirectoryProfile(ctx context.Context, id uint, profile DirectoryProfileDto) error
+
+	// Get a page of the discoverable users matching filters, whose skills have
+	// to be normalized already, and the total amount of matching users. Users
+	// are ordered by their availability at now, in the order of
+	// AvailableForWork, then most recently updated first.
+	ListDiscoverableUsers(ctx context.Context, filters DirectoryFilters, now time.Time, pageSize uint, pageOffset uint) ([]User, int64, error)
+}
@@ -1,16 +1,36 @@
 package users
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
 import (
 	"context"
 	"errors"
 	"github.com/apex/log"
+	"github.com/go-playground/validator/v10"
+	"github.com/open-collaboration/server/email"
+	"github.com/open-collaboration/server/metrics"
+	"golang.org/x/text/language"
 	"gorm.io/gorm"
+	"strings"
+	"time"
 )
 
 var ErrUserNotFound = errors.New("user not found")
+var ErrUsernameTaken = errors.New("username is taken")
+var ErrEmailTaken = errors.New("email is taken")
+var ErrInvalidTimezone = errors.New("unknown time zone")
+var ErrInvalidLocale = errors.New("invalid locale")
+
+var signupsCounter = metrics.NewCounter(
+	"opencollab_signups_total",
+	"Users who signed up.",
+)
 
 type Service interface {
 	// Create a user.
+	// Returns ErrUsernameTaken or ErrEmailTaken if another user has the same
+	// username or email. With EnumerationProtectionStrict a taken email isn't
+	// reported: the email's owner is notified instead and nil is returned.
 	CreateUser(ctx context.Context, newUser NewUserDto) error
 
 	// Get a user by id.
@@ -18,50 +38,245 @@ type Service interface {
 	GetUser(ctx context.Context, id uint) (*User, error)
 
 	FindUserByUsernameOrEmail(ctx context.Context, usernameOrEmail string) (*User, error)
+
+	// Remove a user's password so that they can only log in
+	// through a linked identity (e.g. GitHub).
+	RemovePassword(ctx context.Context, id uint) error
+
+	// List all users whose role includes role. E.g. listing users with
+	// RoleModerator returns all moderators and admins.
+	ListUsersWithRole(ctx context.Context, role Role) ([]User, error)
+
+	// Get a user's time zone and locale.
+	// Returns ErrUserNotFound if a user with the specified id cannot be found.
+	GetSettings(ctx context.Context, id uint) (SettingsDto, error)
+
+	// Set a user's time zone and locale. The locale is stored in its canonical
+	// form, e.g. "pt_br" becomes "pt-BR".
+	// Returns ErrInvalidTimezone if the time zone isn't in the IANA database,
+	// ErrInvalidLocale if the locale isn't a valid BCP 47 tag and
+	// ErrUserNotFound if a user with the specified id cannot be found.
+	UpdateSettings(ctx context.Context, id uint, settings SettingsDto) (SettingsDto, error)
+
+	// Get who can see what about a user.
+	// Returns ErrUserNotFound if a user with the specified id cannot be found.
+	GetPrivacySettings(ctx context.Context, id uint) (PrivacySettingsDto, error)
+
+	// Set who can see what about a user.
+	// Returns ErrUserNotFound if a user with the specified id cannot be found.
+	UpdatePrivacySettings(ctx context.Context, id uint, settings PrivacySettingsDto) (PrivacySettingsDto, error)
+
+	// Get what the directory shows about a user.
+	// Returns ErrUserNotFound if a user with the specified id cannot be found.
+	GetDirectoryProfile(ctx context.Context, id uint) (DirectoryProfileDto, error)
+
+	// Set what the directory shows about a user. Skills are lowercased and
+	// deduplicated, an empty availability is AvailabilityUnavailable.
+	// Returns ErrInvalidAvailability if the availability expires before now
+	// or is unavailable with an expiry, and ErrUserNotFound if a user with
+	// the specified id cannot be found.
+	UpdateDirectoryProfile(ctx context.Context, id uint, profile DirectoryProfileDto) (DirectoryProfileDto, error)
+
+	// List a page of the discoverable users matching filters and the total
+	// amount of matching users, as seen by viewerId. Users available for work
+	// are listed first, actively looking before open to offers.
+	ListDirectory(ctx context.Context, viewerId uint, filters DirectoryFilters, pageSize uint, pageOffset uint) ([]DirectoryUserDto, int64, error)
+}
+
+// A RegistrationGuard is consulted before a user is created. If it returns
+// an error the registration is aborted and the error is returned by CreateUser.
+//
+// This interface exists so that packages that depend on the users package
+// (e.g. blocklist) can take part in the registration process without
+// creating an import cycle.
+type RegistrationGuard interface {
+	CheckRegistration(ctx context.Context, newUser NewUserDto) error
+}
+
+// A RegistrationClaim consumes something a registration needs, e.g. an invite
+// code, so that it can't be used again. Claims run in the transaction the user
+// is created in, after every guard passed and the username and email were
+// found to be free, so that a registration that fails doesn't use it up. If a
+// claim returns an error the user isn't created and the error is returned by
+// CreateUser. Guards passed to NewService that also implement
+// RegistrationClaim claim automatically.
+type RegistrationClaim interface {
+	ClaimRegistration(ctx context.Context, tx *gorm.DB, user *User, newUser NewUserDto) error
+}
+
+// A RegistrationListener is notified after a user is created. Guards passed
+// to NewService that also implement RegistrationListener are notified
+// automatically.
+type RegistrationListener interface {
+	UserRegistered(ctx context.Context, user *User, newUser NewUserDto) error
 }
 
+// Determines how new users are allowed to register.
+type RegistrationMode string
+
+const (
+	// Anyone can register.
+	RegistrationModeOpen RegistrationMode = "open"
+
+	// Users need an invite code to register.
+	RegistrationModeInviteOnly RegistrationMode = "invite-only"
+
+	// Users join a waitlist and can only register after an admin
+	// activates their waitlist entry.
+	RegistrationModeWaitlist RegistrationMode = "waitlist"
+)
+
+// How much auth endpoints hide about which users exist.
+type EnumerationProtection string
+
+const (
+	// Errors say exactly what went wrong, e.g. that a user doesn't exist.
+	EnumerationProtectionOff EnumerationProtection = "off"
+
+	// Logging in with an unknown username/email or a wrong password fails
+	// with the same error, after the same time.
+	EnumerationProtectionLogin EnumerationProtection = "login"
+
+	// Like EnumerationProtectionLogin, and registering with a taken email
+	// looks like it succeeded. The email's owner is notified instead.
+	// Usernames are public, so a taken username is still reported.
+	EnumerationProtectionStrict EnumerationProtection = "strict"
+)
+
 type serviceImpl struct {
-	Db *gorm.DB
+	Repository            Repository
+	EmailSender           email.Sender
+	EmailTemplates        *email.Templates
+	EnumerationProtection EnumerationProtection
+	FrontendUrl           string
+	Guards                []RegistrationGuard
 }
 
-func NewService(db *gorm.DB) Service {
-	return &serviceImpl{Db: db}
+func NewService(
+	repository Repository,
+	emailSender email.Sender,
+	emailTemplates *email.Templates,
+	enumerationProtection EnumerationProtection,
+	frontendUrl string,
+	guards ...RegistrationGuard,
+) Service {
+	return &serviceImpl{
+		Repository:            repository,
+		EmailSender:           emailSender,
+		EmailTemplates:        emailTemplates,
+		EnumerationProtection: enumerationProtection,
+		FrontendUrl:           frontendUrl,
+		Guards:                guards,
+	}
 }
 
 func (s *serviceImpl) CreateUser(ctx context.Context, newUser NewUserDto) error {
-	user := User{
-		Username: newUser.Username,
-		Email:    newUser.Email,
+	for _, guard := range s.Guards {
+		err := guard.CheckRegistration(ctx, newUser)
+		if err != nil {
+			return err
+		}
 	}
 
+	user := newUserToModel(newUser)
+
+	// Hashing before checking for taken emails keeps the response time the
+	// same whether the email is taken or not.
 	err := user.SetPassword(newUser.Password)
 	if err != nil {
 		return err
 	}
 
-	result := s.Db.Create(&user)
-	if result.Error != nil {
-		return result.Error
+	existingUsers, err := s.Repository.ListUsersByUsernameOrEmail(ctx, newUser.Username, newUser.Email)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to check for existing users")
+
+		return err
+	}
+
+	var emailOwner *User
+	for i, existingUser := range existingUsers {
+		if existingUser.Username == newUser.Username {
+			return ErrUsernameTaken
+		}
+
+		emailOwner = &existingUsers[i]
+	}
+
+	if emailOwner != nil {
+		if s.EnumerationProtection != EnumerationProtectionStrict {
+			return ErrEmailTaken
+		}
+
+		s.notifyEmailTaken(ctx, emailOwner)
+
+		return nil
+	}
+
+	var claims []func(tx *gorm.DB) error
+	for _, guard := range s.Guards {
+		if claim, ok := guard.(RegistrationClaim); ok {
+			claims = append(claims, func(tx *gorm.DB) error {
+				return claim.ClaimRegistration(ctx, tx, &user, newUser)
+			})
+		}
+	}
+
+	err = s.Repository.CreateUser(ctx, &user, claims...)
+	if err != nil {
+		return err
+	}
+
+	signupsCounter.Inc()
+
+	for _, guard := range s.Guards {
+		if listener, ok := guard.(RegistrationListener); ok {
+			err = listener.UserRegistered(ctx, &user, newUser)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
 
+// Tell a user that someone tried to register with their email, in the
+// background so that the response isn't slower than a real registration.
+func (s *serviceImpl) notifyEmailTaken(ctx context.Context, user *User) {
+	logger := log.FromContext(ctx).WithField("userId", user.ID)
+	message, err := s.EmailTemplates.Render(email.TemplateEmailTaken, user.Email, map[string]interface{}{
+		"Username": user.Username,
+		"LoginUrl": s.FrontendUrl + "/login",
+	})
+	if err != nil {
+		logger.WithError(err).Error("Failed to render email taken notice")
+
+		return
+	}
+
+	go func() {
+		ctx := log.NewContext(context.Background(), logger)
+
+		err := s.EmailSender.SendEmail(ctx, message)
+		if err != nil {
+			logger.WithError(err).Error("Failed to send email taken notice")
+		}
+	}()
+}
+
 func (s *serviceImpl) GetUser(ctx context.Context, id uint) (*User, error) {
 	logger := log.FromContext(ctx)
 
-	user := &User{}
-	result := s.Db.First(user, id)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	user, err := s.Repository.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
 			logger.Debugf("User not found", id)
-
-			return nil, ErrUserNotFound
 		} else {
-			logger.WithError(result.Error).Error("Database error")
-
-			return nil, result.Error
+			logger.WithError(err).Error("Database error")
 		}
+
+		return nil, err
 	}
 
 	return user, nil
@@ -72,25 +287,89 @@ func (s *serviceImpl) FindUserByUsernameOrEmail(ctx context.Context, usernameOrE
 
 	logger.Debug("Searching for user on database")
 
-	user := &User{}
-	result := s.Db.
-		Where("username = ?", usernameOrEmail).
-		Or("email = ?", usernameOrEmail).
-		First(user)
-
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	user, err := s.Repository.FindUserByUsernameOrEmail(ctx, usernameOrEmail)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
 			logger.Debug("User not found")
-
-			return nil, ErrUserNotFound
 		} else {
-			logger.WithError(result.Error).Error("Could not query for user in database")
-
-			return nil, result.Error
+			logger.WithError(err).Error("Could not query for user in database")
 		}
+
+		return nil, err
 	}
 
 	logger.Debug("User found")
 
 	return user, nil
 }
+
+func (s *serviceImpl) RemovePassword(ctx context.Context, id uint) error {
+	err := s.Repository.UpdatePasswordHash(ctx, id, "")
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		log.FromContext(ctx).WithError(err).Error("Failed to remove user's password")
+	}
+
+	return err
+}
+
+func (s *serviceImpl) ListUsersWithRole(ctx context.Context, role Role) ([]User, error) {
+	var roles []Role
+	for r := range roleRanks {
+		if r.Includes(role) {
+			roles = append(roles, r)
+		}
+	}
+
+	users, err := s.Repository.ListUsersWithRoles(ctx, roles)
+	if err != nil {
+		log.FromContext(ctx).WithError(err).Error("Failed to list users with role")
+
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *serviceImpl) GetSettings(ctx context.Context, id uint) (SettingsDto, error) {
+	user, err := s.GetUser(ctx, id)
+	if err != nil {
+		return SettingsDto{}, err
+	}
+
+	return userToSettingsDto(user), nil
+}
+
+func (s *serviceImpl) UpdateSettings(ctx context.Context, id uint, settings SettingsDto) (SettingsDto, error) {
+	err := validator.New().Struct(settings)
+	if err != nil {
+		return SettingsDto{}, err
+	}
+
+	// LoadLocation also accepts "Local", the server's time zone
+	if settings.Timezone == "Local" {
+		return SettingsDto{}, ErrInvalidTimezone
+	}
+
+	_, err = time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return SettingsDto{}, ErrInvalidTimezone
+	}
+
+	tag, err := language.Parse(strings.ReplaceAll(settings.Locale, "_", "-"))
+	if err != nil {
+		return SettingsDto{}, ErrInvalidLocale
+	}
+
+	settings.Locale = tag.String()
+
+	err = s.Repository.UpdateSettings(ctx, id, settings)
+	if err != nil {
+		if !errors.Is(err, ErrUserNotFound) {
+			log.FromContext(ctx).WithError(err).Error("Failed to update user settings")
+		}
+
+		return SettingsDto{}, err
+	}
+
+	return settings, nil
+}
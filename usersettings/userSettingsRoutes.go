@@ -0,0 +1,118 @@
+package usersettings
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Get the user's settings
+// @Tags users
+// @Router /users/me/settings [get]
+// @Success 200 {object} users.SettingsDto
+// @Failure 401
+func RouteGetSettings(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	settings, err := usersService.GetSettings(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, settings)
+}
+
+// @Summary Update the user's settings
+// @Description The time zone is an IANA time zone name (e.g. "Europe/Lisbon") and the locale a BCP 47 language tag
+// @Description (e.g. "pt-BR"), returned in its canonical form.
+// @Tags users
+// @Router /users/me/settings [put]
+// @Param settings body users.SettingsDto true "The settings"
+// @Success 200 {object} users.SettingsDto
+// @Failure 400 "The time zone or the locale is invalid"
+// @Failure 401
+func RouteUpdateSettings(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := users.SettingsDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	settings, err := usersService.UpdateSettings(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, settings)
+}
+
+// @Summary Get the user's privacy settings
+// @Tags users
+// @Router /users/me/privacy-settings [get]
+// @Success 200 {object} users.PrivacySettingsDto
+// @Failure 401
+func RouteGetPrivacySettings(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	settings, err := usersService.GetPrivacySettings(request.Context(), session.UserId())
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, settings)
+}
+
+// @Summary Update the user's privacy settings
+// @Description Applies to every route that shows users, e.g. the directory, portfolios and contributions.
+// @Tags users
+// @Router /users/me/privacy-settings [put]
+// @Param settings body users.PrivacySettingsDto true "The privacy settings"
+// @Success 200 {object} users.PrivacySettingsDto
+// @Failure 401
+func RouteUpdatePrivacySettings(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := users.PrivacySettingsDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	settings, err := usersService.UpdatePrivacySettings(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, settings)
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"os"
+	"strconv"
 )
 
 // Get an environment variable or panic if it is not set.
@@ -14,3 +15,29 @@ func GetEnvOrPanic(key string) string {
 
 	return val
 }
+
+// Get an environment variable or `def` if it is not set.
+func GetEnvOrDefault(key string, def string) string {
+	val, present := os.LookupEnv(key)
+	if !present {
+		return def
+	}
+
+	return val
+}
+
+// Get an environment variable as an int or `def` if it is not set.
+// Panics if the variable is set but is not an integer.
+func GetIntEnvOrDefault(key string, def int) int {
+	val, present := os.LookupEnv(key)
+	if !present {
+		return def
+	}
+
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		panic(fmt.Sprintf("\"%s\" environment variable is not an integer", key))
+	}
+
+	return i
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Get the sparse fieldset requested with the query parameter `fields` (e.g.
+// `?fields=name,shortDescription`): the JSON fields of the response DTOs the
+// client wants. Returns nil if the parameter was not set, i.e. all fields are
+// wanted.
+func FieldsFromQuery(request *http.Request) []string {
+	return StringsFromQuery(request, "fields")
+}
+
+// Keep only the given JSON fields of a DTO, or of each DTO of a slice of DTOs.
+// The DTOs are returned as maps, ready to be written with WriteJson. The id
+// field is always kept so that the DTOs can still be told apart.
+// If fields is empty the DTO is returned as is.
+// Returns ErrInvalidParam if one of the fields isn't a field of the DTO.
+func SelectFields(dto interface{}, fields []string) (interface{}, error) {
+	if len(fields) < 1 {
+		return dto, nil
+	}
+
+	value := reflect.ValueOf(dto)
+
+	dtoType := value.Type()
+	if dtoType.Kind() == reflect.Slice || dtoType.Kind() == reflect.Array {
+		dtoType = dtoType.Elem()
+	}
+	for dtoType.Kind() == reflect.Ptr {
+		dtoType = dtoType.Elem()
+	}
+
+	if dtoType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't select fields of a %s", dtoType)
+	}
+
+	jsonFields := jsonFieldsOf(dtoType)
+
+	selected := map[string]bool{"id": true}
+	for _, field := range fields {
+		if _, ok := jsonFields[field]; !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidParam, field)
+		}
+
+		selected[field] = true
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		items := make([]map[string]interface{}, value.Len())
+		for i := range items {
+			items[i] = selectStructFields(value.Index(i), jsonFields, selected)
+		}
+
+		return items, nil
+	}
+
+	return selectStructFields(value, jsonFields, selected), nil
+}
+
+// A struct field as encoded by encoding/json.
+type jsonField struct {
+	index     int
+	omitEmpty bool
+}
+
+// The fields of a struct by their JSON name.
+func jsonFieldsOf(structType reflect.Type) map[string]jsonField {
+	fields := map[string]jsonField{}
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			// Unexported
+			continue
+		}
+
+		options := strings.Split(field.Tag.Get("json"), ",")
+		name := options[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		omitEmpty := false
+		for _, option := range options[1:] {
+			omitEmpty = omitEmpty || option == "omitempty"
+		}
+
+		fields[name] = jsonField{index: i, omitEmpty: omitEmpty}
+	}
+
+	return fields
+}
+
+func selectStructFields(value reflect.Value, jsonFields map[string]jsonField, selected map[string]bool) map[string]interface{} {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	result := map[string]interface{}{}
+	for name, field := range jsonFields {
+		if !selected[name] {
+			continue
+		}
+
+		fieldValue := value.Field(field.index)
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		result[name] = fieldValue.Interface()
+	}
+
+	return result
+}
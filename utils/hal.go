@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media type of HAL responses (JSON Hypertext Application Language), which
+// clients get by sending it in the Accept header. HAL responses are the usual
+// JSON responses with links (_links) to the resource itself and to related
+// resources, so that clients can navigate the API without hardcoding URLs.
+const HalMediaType = "application/hal+json"
+
+// A HAL link. Hrefs are relative to the API's base url.
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Links by relation, e.g. "self" or "next".
+type Links map[string]Link
+
+// Whether the client asked for HAL responses in the request's Accept header.
+func AcceptsHal(request *http.Request) bool {
+	for _, mediaRange := range strings.Split(request.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err == nil && mediaType == HalMediaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The JSON object of a DTO with its links. The DTO can also be the result of
+// SelectFields.
+func HalResource(dto interface{}, links Links) (map[string]interface{}, error) {
+	resource := map[string]interface{}{}
+
+	err := convertJson(dto, &resource)
+	if err != nil {
+		return nil, err
+	}
+
+	resource["_links"] = links
+
+	return resource, nil
+}
+
+// A HAL collection of DTOs, embedded as rel, with the collection's links. The
+// DTOs are linked to with resourceLinks, which receives each DTO's id field.
+// The DTOs can also be the result of SelectFields, which keeps the id field.
+func HalCollection(rel string, dtos interface{}, links Links, resourceLinks func(id uint) Links) (map[string]interface{}, error) {
+	var resources []map[string]interface{}
+
+	err := convertJson(dtos, &resources)
+	if err != nil {
+		return nil, err
+	}
+
+	if resources == nil {
+		resources = []map[string]interface{}{}
+	}
+
+	for _, resource := range resources {
+		// Numbers are decoded as float64
+		id, ok := resource["id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s resource without an id", rel)
+		}
+
+		resource["_links"] = resourceLinks(uint(id))
+	}
+
+	return map[string]interface{}{
+		"_links": links,
+		"_embedded": map[string]interface{}{
+			rel: resources,
+		},
+	}, nil
+}
+
+// The links of a page of a paginated list (see WritePaginationHeaders): self,
+// first and, if there are such pages, prev and next. page is the pageOffset
+// query parameter, the other query parameters are kept.
+func PageLinks(request *http.Request, page int, pageSize int, totalCount int64) Links {
+	pageHref := func(page int) string {
+		query := request.URL.Query()
+		query.Set("pageOffset", strconv.Itoa(page))
+		query.Set("pageSize", strconv.Itoa(pageSize))
+
+		return request.URL.Path + "?" + query.Encode()
+	}
+
+	links := Links{
+		"self":  {Href: pageHref(page)},
+		"first": {Href: pageHref(0)},
+	}
+
+	if page > 0 {
+		links["prev"] = Link{Href: pageHref(page - 1)}
+	}
+
+	if int64(page+1)*int64(pageSize) < totalCount {
+		links["next"] = Link{Href: pageHref(page + 1)}
+	}
+
+	return links
+}
+
+// Like WriteJson, but with the HAL media type.
+func WriteHal(writer http.ResponseWriter, ctx context.Context, status int, data interface{}) error {
+	return writeJson(writer, ctx, status, HalMediaType, data)
+}
+
+// Convert a value to another through its JSON encoding.
+func convertJson(from interface{}, to interface{}) error {
+	content, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(content, to)
+}
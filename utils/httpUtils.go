@@ -6,11 +6,17 @@ import (
 	"errors"
 	"github.com/apex/log"
 	"github.com/go-playground/validator/v10"
+	"github.com/gorilla/mux"
+	"github.com/open-collaboration/server/consts"
 	"io"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 )
 
+var ErrInvalidParam = errors.New("invalid route parameter")
+
 // Read the request body as JSON and unmarshal it into `dto`.
 // The request body is unmarshalled with json.Unmarshal.
 func ReadJson(ctx context.Context, request *http.Request, dto interface{}) error {
@@ -41,6 +47,10 @@ func ReadJson(ctx context.Context, request *http.Request, dto interface{}) error
 // the data to be sent, it is marshaled  with json.Marshal and sent
 // with http.ResponseWriter.Write.
 func WriteJson(writer http.ResponseWriter, ctx context.Context, status int, data interface{}) error {
+	return writeJson(writer, ctx, status, "application/json", data)
+}
+
+func writeJson(writer http.ResponseWriter, ctx context.Context, status int, contentType string, data interface{}) error {
 	logger := log.FromContext(ctx)
 
 	bytes, err := json.Marshal(data)
@@ -50,7 +60,7 @@ func WriteJson(writer http.ResponseWriter, ctx context.Context, status int, data
 		return err
 	}
 
-	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set("Content-Type", contentType)
 	writer.Header().Set("Content-Length", strconv.Itoa(len(bytes)))
 	writer.WriteHeader(status)
 	_, err = writer.Write(bytes)
@@ -63,6 +73,24 @@ func WriteJson(writer http.ResponseWriter, ctx context.Context, status int, data
 	return nil
 }
 
+// Whether the request asks to upgrade its connection to a WebSocket.
+// Middleware that wraps the response writer, limits how long requests take
+// or counts requests in flight lets upgrades through untouched, their
+// connections are hijacked and stay open.
+func IsWebsocketUpgrade(request *http.Request) bool {
+	return strings.EqualFold(request.Header.Get("Upgrade"), "websocket")
+}
+
+// Whether a WebSocket handshake comes from the frontend (CORS_ORIGIN), so that
+// other sites can't connect with their visitors' session cookies. WebSockets
+// aren't subject to CORS. Clients that aren't browsers don't send an Origin.
+func IsAllowedWebsocketOrigin(request *http.Request) bool {
+	origin := request.Header.Get("Origin")
+	allowedOrigin := os.Getenv("CORS_ORIGIN")
+
+	return origin == "" || allowedOrigin == "*" || origin == allowedOrigin
+}
+
 // Get an int value from query parameter `param`.
 // Returns the value of the parameter and whether it was set. If the parameter was
 // not set, `def` is returned as the value.
@@ -83,6 +111,55 @@ func IntFromQuery(request *http.Request, param string, def int) (int, bool) {
 	}
 }
 
+// Get the values of query parameter `param`, which can be repeated or comma
+// separated (e.g. `?licenses=MIT,ISC&licenses=Apache-2.0`). Empty values are
+// left out. Returns nil if the parameter was not set.
+func StringsFromQuery(request *http.Request, param string) []string {
+	var values []string
+	for _, value := range request.URL.Query()[param] {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
+
+// Get a uint value from the route variable `param` (e.g. `{projectId}` in
+// `/projects/{projectId}`).
+// Returns ErrInvalidParam if the variable is not set or is not a
+// positive integer.
+func UintFromVars(request *http.Request, param string) (uint, error) {
+	value, ok := mux.Vars(request)[param]
+	if !ok {
+		return 0, ErrInvalidParam
+	}
+
+	val, err := strconv.ParseUint(value, 10, 0)
+	if err != nil || val < 1 {
+		return 0, ErrInvalidParam
+	}
+
+	return uint(val), nil
+}
+
+// Set the pagination headers of a paginated list response: X-Total-Count (the
+// total amount of items across all pages), X-Page (the current page, i.e. the
+// pageOffset), X-Page-Size and X-Has-Next-Page (whether there are items after
+// the current page).
+// Must be called before the response body is written.
+func WritePaginationHeaders(writer http.ResponseWriter, page int, pageSize int, totalCount int64) {
+	hasNextPage := int64(page+1)*int64(pageSize) < totalCount
+
+	writer.Header().Set("X-Total-Count", strconv.FormatInt(totalCount, 10))
+	writer.Header().Set("X-Page", strconv.Itoa(page))
+	writer.Header().Set("X-Page-Size", strconv.Itoa(pageSize))
+	writer.Header().Set("X-Has-Next-Page", strconv.FormatBool(hasNextPage))
+}
+
 // Read the request's body into a slice of bytes.
 func ReadBody(r *http.Request) ([]byte, error) {
 	bytes := make([]byte, 0)
@@ -109,3 +186,12 @@ func ReadBody(r *http.Request) ([]byte, error) {
 
 	return bytes, nil
 }
+
+// Get the client's IP address from a request context. Returns an empty
+// string if the context doesn't contain one (i.e. the request didn't go
+// through middleware.ClientIpMiddleware).
+func ClientIp(ctx context.Context) string {
+	ip, _ := ctx.Value(consts.ClientIpKey).(string)
+
+	return ip
+}
@@ -9,7 +9,9 @@ import (
 	"gorm.io/gorm/logger"
 	"gorm.io/gorm/utils"
 	"io"
+	"math/rand"
 	"os"
+	"strings"
 	"sync"
 	"time"
 )
@@ -117,3 +119,98 @@ func (h *TerminalLogger) HandleLog(e *log.Entry) error {
 
 	return nil
 }
+
+// Redacting handler -------------------------
+
+// The default LOG_REDACT_FIELDS. Fields whose name contains any of them
+// (ignoring case) are redacted.
+const DefaultRedactedFields = "password,token,secret,apikey,authorization,cookie,usernameoremail,email"
+
+// Replaces the values of sensitive fields (e.g. passwords and tokens) before
+// passing entries to another handler.
+type RedactingHandler struct {
+	Handler log.Handler
+
+	// Lower case substrings of the field names to redact
+	Fields []string
+}
+
+func NewRedactingHandler(handler log.Handler, fields []string) *RedactingHandler {
+	lowerFields := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field != "" {
+			lowerFields = append(lowerFields, field)
+		}
+	}
+
+	return &RedactingHandler{
+		Handler: handler,
+		Fields:  lowerFields,
+	}
+}
+
+// HandleLog implements log.Handler.
+func (h *RedactingHandler) HandleLog(e *log.Entry) error {
+	var redacted log.Fields
+
+	for name, value := range e.Fields {
+		if !IsSensitiveField(name, h.Fields) {
+			continue
+		}
+
+		// Entries share their fields with the logger that created them, so
+		// copy them instead of modifying them in place.
+		if redacted == nil {
+			redacted = make(log.Fields, len(e.Fields))
+			for name, value := range e.Fields {
+				redacted[name] = value
+			}
+		}
+
+		if value != nil && value != "" {
+			redacted[name] = "[redacted]"
+		}
+	}
+
+	if redacted == nil {
+		return h.Handler.HandleLog(e)
+	}
+
+	entry := *e
+	entry.Fields = redacted
+
+	return h.Handler.HandleLog(&entry)
+}
+
+// Whether a field name contains any of fields, ignoring case.
+func IsSensitiveField(name string, fields []string) bool {
+	name = strings.ToLower(name)
+	for _, field := range fields {
+		if field != "" && strings.Contains(name, strings.ToLower(field)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sampling handler -------------------------
+
+// Passes only a fraction of debug entries to another handler, to keep debug
+// logging affordable in production. Entries of other levels always pass.
+type SamplingHandler struct {
+	Handler log.Handler
+
+	// Fraction of debug entries to keep, between 0 and 1
+	DebugRate float64
+}
+
+// HandleLog implements log.Handler.
+func (h *SamplingHandler) HandleLog(e *log.Entry) error {
+	if e.Level == log.DebugLevel && h.DebugRate < 1 && rand.Float64() >= h.DebugRate {
+		return nil
+	}
+
+	return h.Handler.HandleLog(e)
+}
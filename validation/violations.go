@@ -0,0 +1,55 @@
+// Business rules that struct tags can't express (e.g. limits that depend on
+// the user or on the configuration) are checked by services, which return
+// the broken rules as Errors. Routes respond to them with a
+// rule-violation-error, see docs/api.md.
+package validation
+
+import (
+	"strings"
+)
+
+// A business rule broken by a field's value.
+type Violation struct {
+	// The field's json name, e.g. "githubLink"
+	Field string `json:"field"`
+
+	// The rule's code, e.g. "banned-word"
+	Rule string `json:"rule"`
+
+	Message string `json:"message"`
+
+	// The rule's parameters, e.g. the banned word or the maximum length
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// The business rules broken by a value. All rules are checked, so that clients
+// can show every problem at once.
+type Errors []Violation
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, violation := range e {
+		messages[i] = violation.Field + ": " + violation.Message
+	}
+
+	return "business rules violated: " + strings.Join(messages, "; ")
+}
+
+// Add a violation of a rule.
+func (e *Errors) Add(field string, rule string, message string, params map[string]interface{}) {
+	*e = append(*e, Violation{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+		Params:  params,
+	})
+}
+
+// The violations as an error, nil if there are none.
+func (e Errors) Err() error {
+	if len(e) < 1 {
+		return nil
+	}
+
+	return e
+}
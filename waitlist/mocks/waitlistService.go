@@ -0,0 +1,109 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: waitlistService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	users "github.com/open-collaboration/server/users"
+	waitlist "github.com/open-collaboration/server/waitlist"
+	gorm "gorm.io/gorm"
+	reflect "reflect"
+)
+
+// MockService is a mock of Service interface
+type MockService struct {
+	ctrl     *gomock.Controller
+	recorder *MockServiceMockRecorder
+}
+
+// MockServiceMockRecorder is the mock recorder for MockService
+type MockServiceMockRecorder struct {
+	mock *MockService
+}
+
+// NewMockService creates a new mock instance
+func NewMockService(ctrl *gomock.Controller) *MockService {
+	mock := &MockService{ctrl: ctrl}
+	mock.recorder = &MockServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockService) EXPECT() *MockServiceMockRecorder {
+	return m.recorder
+}
+
+// JoinWaitlist mocks base method
+func (m *MockService) JoinWaitlist(ctx context.Context, dto waitlist.JoinWaitlistDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JoinWaitlist", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// JoinWaitlist indicates an expected call of JoinWaitlist
+func (mr *MockServiceMockRecorder) JoinWaitlist(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JoinWaitlist", reflect.TypeOf((*MockService)(nil).JoinWaitlist), ctx, dto)
+}
+
+// ListEntries mocks base method
+func (m *MockService) ListEntries(ctx context.Context, pendingOnly bool, pageSize, pageOffset uint) ([]waitlist.EntryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEntries", ctx, pendingOnly, pageSize, pageOffset)
+	ret0, _ := ret[0].([]waitlist.EntryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEntries indicates an expected call of ListEntries
+func (mr *MockServiceMockRecorder) ListEntries(ctx, pendingOnly, pageSize, pageOffset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEntries", reflect.TypeOf((*MockService)(nil).ListEntries), ctx, pendingOnly, pageSize, pageOffset)
+}
+
+// ActivateBatch mocks base method
+func (m *MockService) ActivateBatch(ctx context.Context, count uint) ([]waitlist.EntryDto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateBatch", ctx, count)
+	ret0, _ := ret[0].([]waitlist.EntryDto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivateBatch indicates an expected call of ActivateBatch
+func (mr *MockServiceMockRecorder) ActivateBatch(ctx, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateBatch", reflect.TypeOf((*MockService)(nil).ActivateBatch), ctx, count)
+}
+
+// CheckRegistration mocks base method
+func (m *MockService) CheckRegistration(ctx context.Context, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRegistration", ctx, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRegistration indicates an expected call of CheckRegistration
+func (mr *MockServiceMockRecorder) CheckRegistration(ctx, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRegistration", reflect.TypeOf((*MockService)(nil).CheckRegistration), ctx, newUser)
+}
+
+// ClaimRegistration mocks base method
+func (m *MockService) ClaimRegistration(ctx context.Context, tx *gorm.DB, user *users.User, newUser users.NewUserDto) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimRegistration", ctx, tx, user, newUser)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClaimRegistration indicates an expected call of ClaimRegistration
+func (mr *MockServiceMockRecorder) ClaimRegistration(ctx, tx, user, newUser interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimRegistration", reflect.TypeOf((*MockService)(nil).ClaimRegistration), ctx, tx, user, newUser)
+}
@@ -0,0 +1,24 @@
+package waitlist
+
+import "time"
+
+type JoinWaitlistDto struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ActivateBatchDto struct {
+	// How many of the oldest pending entries to activate.
+	Count uint `json:"count" validate:"required,min=1,max=500"`
+}
+
+type EntryDto struct {
+	Id          uint       `json:"id"`
+	Email       string     `json:"email"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ActivatedAt *time.Time `json:"activatedAt"`
+	SignedUpAt  *time.Time `json:"signedUpAt"`
+}
+
+type ActivateBatchResultDto struct {
+	Activated []EntryDto `json:"activated"`
+}
@@ -0,0 +1,22 @@
+package waitlist
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type Entry struct {
+	gorm.Model
+
+	Email string
+
+	// Hash of the one-time signup token sent in the activation email.
+	// Empty while the entry hasn't been activated.
+	TokenHash   string
+	ActivatedAt *time.Time
+	SignedUpAt  *time.Time
+}
+
+func (Entry) TableName() string {
+	return "waitlist_entries"
+}
@@ -0,0 +1,105 @@
+package waitlist
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/users"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Join the waitlist
+// @Tags waitlist
+// @Router /waitlist [post]
+// @Param entry body waitlist.JoinWaitlistDto true "The email to add to the waitlist"
+// @Success 202
+func RouteJoinWaitlist(
+	writer http.ResponseWriter,
+	request *http.Request,
+	waitlistService Service,
+) error {
+	dto := JoinWaitlistDto{}
+	err := utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	err = waitlistService.JoinWaitlist(request.Context(), dto)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusAccepted)
+
+	return nil
+}
+
+// @Summary List waitlist entries
+// @Tags admin
+// @Router /admin/waitlist [get]
+// @Param pending query bool false "Only list entries that haven't been activated"
+// @Param pageSize query int false "Maximum amount of entries in the response. Default is 50, max is 500."
+// @Param pageOffset query int false "Response page number."
+// @Success 200 {array} waitlist.EntryDto
+// @Failure 403
+func RouteListEntries(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	waitlistService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	pageSize, _ := utils.IntFromQuery(request, "pageSize", 50)
+	pageOffset, _ := utils.IntFromQuery(request, "pageOffset", 0)
+	pendingOnly := request.URL.Query().Get("pending") == "true"
+
+	if pageSize < 1 || pageSize > 500 {
+		pageSize = 50
+	}
+
+	if pageOffset < 0 {
+		pageOffset = 0
+	}
+
+	entries, err := waitlistService.ListEntries(request.Context(), pendingOnly, uint(pageSize), uint(pageOffset))
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, entries)
+}
+
+// @Summary Activate a batch of waitlist entries
+// @Description Activates the oldest pending entries and emails each of them a one-time signup link.
+// @Tags admin
+// @Router /admin/waitlist/activate [post]
+// @Param batch body waitlist.ActivateBatchDto true "The batch size"
+// @Success 200 {object} waitlist.ActivateBatchResultDto
+// @Failure 403
+func RouteActivateBatch(
+	writer http.ResponseWriter,
+	request *http.Request,
+	usersService users.Service,
+	waitlistService Service,
+) error {
+	_, err := auth.CheckRole(request, usersService, users.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	dto := ActivateBatchDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	activated, err := waitlistService.ActivateBatch(request.Context(), dto.Count)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, ActivateBatchResultDto{Activated: activated})
+}
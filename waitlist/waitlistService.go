@@ -0,0 +1,253 @@
+package waitlist
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/email"
+	"github.com/open-collaboration/server/users"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSignupToken = errors.New("invalid signup token")
+
+type Service interface {
+	// Add an email to the waitlist. Adding an email that is already in the
+	// waitlist does nothing, so that the response doesn't reveal who joined.
+	JoinWaitlist(ctx context.Context, dto JoinWaitlistDto) error
+
+	// List waitlist entries, oldest to newest. If pendingOnly is true only
+	// entries that haven't been activated are returned.
+	ListEntries(ctx context.Context, pendingOnly bool, pageSize uint, pageOffset uint) ([]EntryDto, error)
+
+	// Activate the `count` oldest pending entries. Each activated entry
+	// receives an email with a one-time signup link.
+	ActivateBatch(ctx context.Context, count uint) ([]EntryDto, error)
+
+	// Check that the new user has a signup token. Does nothing unless the
+	// registration mode is waitlist.
+	// Returns ErrInvalidSignupToken if the token is missing.
+	CheckRegistration(ctx context.Context, newUser users.NewUserDto) error
+
+	// Consume the new user's signup token, in the transaction the user is
+	// created in. Does nothing unless the registration mode is waitlist.
+	// Returns ErrInvalidSignupToken if the token isn't the email's or was
+	// already used.
+	ClaimRegistration(ctx context.Context, tx *gorm.DB, user *users.User, newUser users.NewUserDto) error
+}
+
+type serviceImpl struct {
+	Db             *gorm.DB
+	EmailSender    email.Sender
+	EmailTemplates *email.Templates
+	Mode           users.RegistrationMode
+	SignupUrl      string
+}
+
+// Create a waitlist service. signupUrl is the frontend's signup page, the
+// signup token is appended to it as the `token` query parameter.
+func NewService(
+	db *gorm.DB,
+	emailSender email.Sender,
+	emailTemplates *email.Templates,
+	mode users.RegistrationMode,
+	signupUrl string,
+) Service {
+	return &serviceImpl{
+		Db:             db,
+		EmailSender:    emailSender,
+		EmailTemplates: emailTemplates,
+		Mode:           mode,
+		SignupUrl:      signupUrl,
+	}
+}
+
+func (s *serviceImpl) JoinWaitlist(ctx context.Context, dto JoinWaitlistDto) error {
+	logger := log.FromContext(ctx)
+
+	entry := Entry{}
+	result := s.Db.WithContext(ctx).
+		Where(Entry{Email: strings.ToLower(dto.Email)}).
+		FirstOrCreate(&entry)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to add email to the waitlist")
+
+		return result.Error
+	}
+
+	logger.Debug("Email added to the waitlist")
+
+	return nil
+}
+
+func (s *serviceImpl) ListEntries(ctx context.Context, pendingOnly bool, pageSize uint, pageOffset uint) ([]EntryDto, error) {
+	query := s.Db.WithContext(ctx).Order("created_at asc")
+	if pendingOnly {
+		query = query.Where("activated_at IS NULL")
+	}
+
+	var entries []Entry
+	result := query.
+		Limit(int(pageSize)).
+		Offset(int(pageOffset * pageSize)).
+		Find(&entries)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to list waitlist entries")
+
+		return nil, result.Error
+	}
+
+	return entriesToDtos(entries), nil
+}
+
+func (s *serviceImpl) ActivateBatch(ctx context.Context, count uint) ([]EntryDto, error) {
+	logger := log.FromContext(ctx).WithField("count", count)
+
+	logger.Info("Activating waitlist batch")
+
+	var entries []Entry
+	result := s.Db.WithContext(ctx).
+		Where("activated_at IS NULL").
+		Order("created_at asc").
+		Limit(int(count)).
+		Find(&entries)
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to query for pending waitlist entries")
+
+		return nil, result.Error
+	}
+
+	activated := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		err := s.activateEntry(ctx, &entry)
+		if err != nil {
+			// Keep going, the entry stays pending and will be
+			// picked up by the next batch.
+			logger.WithError(err).WithField("entryId", entry.ID).Error("Failed to activate waitlist entry")
+			continue
+		}
+
+		activated = append(activated, entry)
+	}
+
+	logger.Infof("Activated %d waitlist entries", len(activated))
+
+	return entriesToDtos(activated), nil
+}
+
+func (s *serviceImpl) CheckRegistration(ctx context.Context, newUser users.NewUserDto) error {
+	if s.Mode != users.RegistrationModeWaitlist {
+		return nil
+	}
+
+	if newUser.SignupToken == "" {
+		log.FromContext(ctx).Debug("Registration without signup token")
+
+		return ErrInvalidSignupToken
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) ClaimRegistration(ctx context.Context, tx *gorm.DB, user *users.User, newUser users.NewUserDto) error {
+	if s.Mode != users.RegistrationModeWaitlist {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+
+	// Consume the token in a single statement so that it can't be used twice.
+	result := tx.
+		Model(&Entry{}).
+		Where("email = ? AND token_hash = ? AND signed_up_at IS NULL", strings.ToLower(newUser.Email), hashToken(newUser.SignupToken)).
+		Update("signed_up_at", time.Now())
+
+	if result.Error != nil {
+		logger.WithError(result.Error).Error("Failed to consume signup token")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		logger.Debug("Signup token doesn't exist or was already used")
+
+		return ErrInvalidSignupToken
+	}
+
+	return nil
+}
+
+// Generate a signup token for the entry, store its hash and email the
+// signup link to the entry's email. The entry is only activated if the email
+// is sent, otherwise it stays pending.
+func (s *serviceImpl) activateEntry(ctx context.Context, entry *Entry) error {
+	token, err := generateToken()
+	if err != nil {
+		return err
+	}
+
+	message, err := s.EmailTemplates.Render(email.TemplateWaitlistActivation, entry.Email, map[string]interface{}{
+		"SignupUrl": s.SignupUrl + "?token=" + token,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.Db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		entry.TokenHash = hashToken(token)
+		entry.ActivatedAt = &now
+
+		result := tx.Save(entry)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Rolls the activation back if the email can't be sent, e.g. while
+		// the email breaker is open
+		return s.EmailSender.SendEmail(ctx, message)
+	})
+}
+
+func generateToken() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
+
+// Tokens are stored hashed so that a database leak doesn't
+// leak usable signup links.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func entriesToDtos(entries []Entry) []EntryDto {
+	dtos := make([]EntryDto, len(entries))
+	for i, entry := range entries {
+		dtos[i] = EntryDto{
+			Id:          entry.ID,
+			Email:       entry.Email,
+			CreatedAt:   entry.CreatedAt,
+			ActivatedAt: entry.ActivatedAt,
+			SignedUpAt:  entry.SignedUpAt,
+		}
+	}
+
+	return dtos
+}
@@ -0,0 +1,188 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/url"
+	"time"
+)
+
+var ErrInvalidKey = errors.New("invalid web push key")
+
+// Record size of the encrypted content. Messages are always sent as a single
+// record, push services accept payloads of up to 4096 bytes.
+const recordSize = 4096
+
+// How long VAPID tokens are valid, at most 24 hours.
+const vapidTokenDuration = 12 * time.Hour
+
+// The application server's VAPID key pair (RFC 8292), which identifies the
+// server to push services.
+type vapidKeys struct {
+	privateKey *ecdsa.PrivateKey
+
+	// Uncompressed P-256 point, base64url encoded
+	publicKey string
+}
+
+// Parse a base64url encoded P-256 private key, as generated by e.g.
+// `npx web-push generate-vapid-keys`.
+func parseVapidKeys(privateKey string) (*vapidKeys, error) {
+	d, err := decodeBase64(privateKey)
+	if err != nil || len(d) != 32 {
+		return nil, ErrInvalidKey
+	}
+
+	curve := elliptic.P256()
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	key.PublicKey.Curve = curve
+	key.PublicKey.X, key.PublicKey.Y = curve.ScalarBaseMult(d)
+
+	return &vapidKeys{
+		privateKey: key,
+		publicKey:  base64.RawURLEncoding.EncodeToString(elliptic.Marshal(curve, key.PublicKey.X, key.PublicKey.Y)),
+	}, nil
+}
+
+// The Authorization header of a request to a push service endpoint.
+func (k *vapidKeys) authorization(endpoint string, subject string, now time.Time) (string, error) {
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]interface{}{
+		"aud": endpointUrl.Scheme + "://" + endpointUrl.Host,
+		"exp": now.Add(vapidTokenDuration).Unix(),
+		"sub": subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+
+	r, s, err := ecdsa.Sign(rand.Reader, k.privateKey, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	// ES256 signatures are r and s as 32 byte big endian integers
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	token := unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	return "vapid t=" + token + ", k=" + k.publicKey, nil
+}
+
+// Encrypt a payload for a subscription with the aes128gcm content encoding
+// (RFC 8291).
+func encrypt(payload []byte, p256dh string, authSecret string) ([]byte, error) {
+	curve := elliptic.P256()
+
+	userAgentKey, err := decodeBase64(p256dh)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	userAgentX, userAgentY := elliptic.Unmarshal(curve, userAgentKey)
+	if userAgentX == nil {
+		return nil, ErrInvalidKey
+	}
+
+	auth, err := decodeBase64(authSecret)
+	if err != nil || len(auth) != 16 {
+		return nil, ErrInvalidKey
+	}
+
+	// A new key pair for each message
+	serverPrivateKey, serverX, serverY, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serverKey := elliptic.Marshal(curve, serverX, serverY)
+
+	sharedX, _ := curve.ScalarMult(userAgentX, userAgentY, serverPrivateKey)
+	sharedSecret := make([]byte, 32)
+	sharedX.FillBytes(sharedSecret)
+
+	salt := make([]byte, 16)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), userAgentKey...)
+	keyInfo = append(keyInfo, serverKey...)
+	ikm := hkdf(auth, sharedSecret, keyInfo, 32)
+
+	contentKey := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 0x02 delimits the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	if len(plaintext)+gcm.Overhead() > recordSize {
+		return nil, errors.New("web push payload is too large")
+	}
+
+	header := make([]byte, 0, 16+4+1+len(serverKey))
+	header = append(header, salt...)
+	header = append(header, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(header[16:20], recordSize)
+	header = append(header, byte(len(serverKey)))
+	header = append(header, serverKey...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// HKDF-SHA256 (RFC 5869) for outputs of up to 32 bytes.
+func hkdf(salt []byte, ikm []byte, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(ikm)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{1})
+
+	return expand.Sum(nil)[:length]
+}
+
+// Decode base64url, with or without padding, as browsers and key generators
+// differ.
+func decodeBase64(value string) ([]byte, error) {
+	for len(value)%4 != 0 {
+		value += "="
+	}
+
+	return base64.URLEncoding.DecodeString(value)
+}
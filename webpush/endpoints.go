@@ -0,0 +1,117 @@
+package webpush
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var ErrInvalidEndpoint = errors.New("invalid push endpoint")
+
+// How long to wait for a push service to accept a message.
+const pushTimeout = 30 * time.Second
+
+// Addresses push services can't have: subscriptions are sent by browsers,
+// i.e. by anyone, so their endpoints must not make the server send requests
+// to itself or to its private network. Loopback, link-local, multicast and
+// unspecified addresses are checked by allowedAddress.
+var privateNetworks = mustParseCidrs(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"fc00::/7",
+)
+
+// The client deliveries are sent with. The address of each connection is
+// checked once the endpoint's host is resolved, so that a public host name
+// can't resolve to a private address, and redirects are not followed. It
+// doesn't use the environment's proxy, whose address would be checked
+// instead of the push service's.
+var pushClient = &http.Client{
+	Timeout: pushTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: checkDialedAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+		MaxIdleConnsPerHost: 10,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// Returns ErrInvalidEndpoint if the endpoint isn't an https URL or its host
+// is an address push services can't have. Host names are checked when
+// deliveries are sent, see pushClient.
+func checkEndpoint(endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" || parsed.User != nil {
+		return ErrInvalidEndpoint
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInvalidEndpoint
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && !allowedAddress(ip) {
+		return ErrInvalidEndpoint
+	}
+
+	return nil
+}
+
+// Rejects connections to addresses push services can't have. Used as the
+// dialer's Control, i.e. after the host is resolved.
+func checkDialedAddress(_ string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !allowedAddress(ip) {
+		return fmt.Errorf("%w: connecting to %s", ErrInvalidEndpoint, host)
+	}
+
+	return nil
+}
+
+func allowedAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func mustParseCidrs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = network
+	}
+
+	return networks
+}
@@ -0,0 +1,30 @@
+package webpush
+
+// A push subscription as serialized by the browser's PushSubscription.toJSON().
+type NewSubscriptionDto struct {
+	// The push service's https URL, which can't be a private address
+	Endpoint string `json:"endpoint" validate:"required,url,max=1000"`
+	Keys     struct {
+		P256dh string `json:"p256dh" validate:"required,max=200"`
+		Auth   string `json:"auth" validate:"required,max=100"`
+	} `json:"keys"`
+}
+
+type SubscriptionDto struct {
+	Id       uint   `json:"id"`
+	Endpoint string `json:"endpoint"`
+}
+
+type PublicKeyDto struct {
+	// The VAPID public key, base64url encoded, to pass to
+	// PushManager.subscribe() as applicationServerKey.
+	PublicKey string `json:"publicKey"`
+}
+
+// The payload of a push message, received by the service worker's push event.
+type payloadDto struct {
+	Type  string                 `json:"type"`
+	Title string                 `json:"title"`
+	Body  string                 `json:"body"`
+	Data  map[string]interface{} `json:"data"`
+}
@@ -0,0 +1,23 @@
+package webpush
+
+import "gorm.io/gorm"
+
+// A browser's push subscription (see the Push API's PushSubscription).
+// Notifications of the subscription's user are pushed to it.
+type Subscription struct {
+	gorm.Model
+
+	UserId uint
+
+	// The push service URL notifications are sent to. Unique per browser.
+	Endpoint string
+
+	// The browser's P-256 public key and auth secret, base64url encoded, which
+	// notifications are encrypted with.
+	P256dh string
+	Auth   string
+}
+
+func (Subscription) TableName() string {
+	return "push_subscriptions"
+}
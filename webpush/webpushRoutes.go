@@ -0,0 +1,92 @@
+package webpush
+
+import (
+	"github.com/open-collaboration/server/auth"
+	"github.com/open-collaboration/server/utils"
+	"net/http"
+)
+
+// @Summary Get the VAPID public key
+// @Description The key to pass to PushManager.subscribe() as applicationServerKey.
+// @Tags notifications
+// @Router /push/public-key [get]
+// @Success 200 {object} webpush.PublicKeyDto
+// @Failure 400 "Web push is disabled"
+func RouteGetPublicKey(
+	writer http.ResponseWriter,
+	request *http.Request,
+	webpushService Service,
+) error {
+	publicKey, err := webpushService.PublicKey()
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusOK, PublicKeyDto{
+		PublicKey: publicKey,
+	})
+}
+
+// @Summary Register a push subscription
+// @Description Notifications the user enabled for the "push" channel are pushed to the subscription.
+// @Tags notifications
+// @Router /users/me/push-subscriptions [post]
+// @Param subscription body webpush.NewSubscriptionDto true "The browser's PushSubscription.toJSON()"
+// @Success 201 {object} webpush.SubscriptionDto
+// @Failure 400 "Web push is disabled, or the subscription's keys or endpoint are invalid"
+// @Failure 401
+func RouteSubscribe(
+	writer http.ResponseWriter,
+	request *http.Request,
+	webpushService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	dto := NewSubscriptionDto{}
+	err = utils.ReadJson(request.Context(), request, &dto)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := webpushService.Subscribe(request.Context(), session.UserId(), dto)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteJson(writer, request.Context(), http.StatusCreated, subscription)
+}
+
+// @Summary Unregister a push subscription
+// @Tags notifications
+// @Router /users/me/push-subscriptions/{subscriptionId} [delete]
+// @Param subscriptionId path int true "The subscription ID"
+// @Success 204
+// @Failure 401
+// @Failure 404
+func RouteUnsubscribe(
+	writer http.ResponseWriter,
+	request *http.Request,
+	webpushService Service,
+) error {
+	session, err := auth.CheckSession(request)
+	if err != nil {
+		return err
+	}
+
+	subscriptionId, err := utils.UintFromVars(request, "subscriptionId")
+	if err != nil {
+		return err
+	}
+
+	err = webpushService.Unsubscribe(request.Context(), session.UserId(), subscriptionId)
+	if err != nil {
+		return err
+	}
+
+	writer.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
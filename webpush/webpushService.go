@@ -0,0 +1,338 @@
+package webpush
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/$GOFILE -package=mocks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/apex/log"
+	"github.com/open-collaboration/server/encryption"
+	"github.com/open-collaboration/server/notifications"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"time"
+)
+
+var ErrPushDisabled = errors.New("web push is disabled")
+var ErrSubscriptionNotFound = errors.New("push subscription not found")
+
+// How long push services keep a message for an offline browser.
+const messageTtl = 24 * time.Hour
+
+// Maximum amount of deliveries waiting to be sent. Notifications delivered
+// while the queue is full are dropped.
+const queueSize = 1000
+
+// Delay before each retry of a failed delivery. Deliveries are dropped after
+// the last retry.
+var retryDelays = []time.Duration{10 * time.Second, time.Minute, 10 * time.Minute}
+
+type Service interface {
+	// The name of the channel the service delivers notifications on,
+	// notifications.ChannelPush.
+	Name() string
+
+	// Queue a notification to be pushed to all of a user's subscriptions.
+	Deliver(ctx context.Context, userId uint, notification notifications.NewNotificationDto) error
+
+	// The VAPID public key browsers subscribe with.
+	// Returns ErrPushDisabled if no VAPID key is configured.
+	PublicKey() (string, error)
+
+	// Register a browser's push subscription for a user. A subscription
+	// registered again (e.g. by another user on the same browser) is moved to
+	// the user.
+	// Returns ErrPushDisabled if no VAPID key is configured and
+	// ErrInvalidEndpoint if the endpoint isn't an https URL of a public host.
+	Subscribe(ctx context.Context, userId uint, dto NewSubscriptionDto) (SubscriptionDto, error)
+
+	// Remove one of a user's push subscriptions.
+	// Returns ErrSubscriptionNotFound if the user doesn't have the subscription.
+	Unsubscribe(ctx context.Context, userId uint, subscriptionId uint) error
+
+	// Send queued deliveries until ctx is done. Should be run in its own
+	// goroutine.
+	Run(ctx context.Context)
+}
+
+// A notification to push to a subscription.
+type delivery struct {
+	subscription Subscription
+	payload      []byte
+
+	// Retries done so far
+	retries int
+}
+
+type serviceImpl struct {
+	Db *gorm.DB
+
+	// nil if web push is disabled
+	Keys *vapidKeys
+
+	// Contact for push services, a mailto: or https: URL
+	Subject string
+
+	// Subscriptions' keys are stored encrypted
+	EncryptionService encryption.Service
+
+	queue chan delivery
+}
+
+// Create a web push service. privateKey is the base64url encoded VAPID
+// private key; web push is disabled if it's empty.
+func NewService(db *gorm.DB, privateKey string, subject string, encryptionService encryption.Service) (Service, error) {
+	service := &serviceImpl{
+		Db:                db,
+		Subject:           subject,
+		EncryptionService: encryptionService,
+		queue:             make(chan delivery, queueSize),
+	}
+
+	if privateKey != "" {
+		keys, err := parseVapidKeys(privateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		service.Keys = keys
+	}
+
+	return service, nil
+}
+
+func (s *serviceImpl) Name() string {
+	return notifications.ChannelPush
+}
+
+func (s *serviceImpl) PublicKey() (string, error) {
+	if s.Keys == nil {
+		return "", ErrPushDisabled
+	}
+
+	return s.Keys.publicKey, nil
+}
+
+func (s *serviceImpl) Subscribe(ctx context.Context, userId uint, dto NewSubscriptionDto) (SubscriptionDto, error) {
+	if s.Keys == nil {
+		return SubscriptionDto{}, ErrPushDisabled
+	}
+
+	err := checkEndpoint(dto.Endpoint)
+	if err != nil {
+		return SubscriptionDto{}, err
+	}
+
+	// Fail early instead of on the first delivery
+	_, err = encrypt([]byte{}, dto.Keys.P256dh, dto.Keys.Auth)
+	if err != nil {
+		return SubscriptionDto{}, err
+	}
+
+	p256dh, err := s.EncryptionService.Encrypt(dto.Keys.P256dh)
+	if err != nil {
+		return SubscriptionDto{}, err
+	}
+
+	auth, err := s.EncryptionService.Encrypt(dto.Keys.Auth)
+	if err != nil {
+		return SubscriptionDto{}, err
+	}
+
+	subscription := Subscription{
+		UserId:   userId,
+		Endpoint: dto.Endpoint,
+		P256dh:   p256dh,
+		Auth:     auth,
+	}
+
+	result := s.Db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "endpoint"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "updated_at"}),
+		}).
+		Create(&subscription)
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to save push subscription")
+
+		return SubscriptionDto{}, result.Error
+	}
+
+	return SubscriptionDto{
+		Id:       subscription.ID,
+		Endpoint: subscription.Endpoint,
+	}, nil
+}
+
+func (s *serviceImpl) Unsubscribe(ctx context.Context, userId uint, subscriptionId uint) error {
+	result := s.Db.WithContext(ctx).
+		Unscoped().
+		Where("id = ? AND user_id = ?", subscriptionId, userId).
+		Delete(&Subscription{})
+
+	if result.Error != nil {
+		log.FromContext(ctx).WithError(result.Error).Error("Failed to delete push subscription")
+
+		return result.Error
+	}
+
+	if result.RowsAffected < 1 {
+		return ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) Deliver(ctx context.Context, userId uint, notification notifications.NewNotificationDto) error {
+	if s.Keys == nil {
+		return nil
+	}
+
+	var subscriptions []Subscription
+	result := s.Db.WithContext(ctx).Where("user_id = ?", userId).Find(&subscriptions)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	payload, err := json.Marshal(payloadDto{
+		Type:  notification.Type,
+		Title: notification.Title,
+		Body:  notification.Body,
+		Data:  notification.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		s.enqueue(ctx, delivery{
+			subscription: subscription,
+			payload:      payload,
+		})
+	}
+
+	return nil
+}
+
+func (s *serviceImpl) enqueue(ctx context.Context, delivery delivery) {
+	select {
+	case s.queue <- delivery:
+	default:
+		log.FromContext(ctx).
+			WithField("subscriptionId", delivery.subscription.ID).
+			Warn("Push delivery queue is full, dropping notification")
+	}
+}
+
+func (s *serviceImpl) Run(ctx context.Context) {
+	if s.Keys == nil {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-s.queue:
+			s.send(log.NewContext(ctx, logger.WithField("subscriptionId", delivery.subscription.ID)), delivery)
+		}
+	}
+}
+
+// Push a delivery to its subscription. Subscriptions that expired are
+// deleted, deliveries that failed temporarily are retried later.
+func (s *serviceImpl) send(ctx context.Context, delivery delivery) {
+	logger := log.FromContext(ctx)
+
+	status, err := s.push(ctx, delivery)
+
+	switch {
+	case err == nil && status < 300:
+		logger.Debug("Push notification sent")
+
+	case status == http.StatusNotFound || status == http.StatusGone || errors.Is(err, ErrInvalidKey) || errors.Is(err, ErrInvalidEndpoint):
+		// The browser unsubscribed, the subscription expired or it was never
+		// a push service's
+		logger.WithError(err).Info("Push subscription expired, deleting it")
+
+		result := s.Db.WithContext(ctx).Unscoped().Delete(&Subscription{}, delivery.subscription.ID)
+		if result.Error != nil {
+			logger.WithError(result.Error).Error("Failed to delete expired push subscription")
+		}
+
+	case err != nil || status == http.StatusTooManyRequests || status >= 500:
+		if delivery.retries >= len(retryDelays) {
+			logger.WithError(err).WithField("status", status).Warn("Push delivery failed, giving up")
+
+			return
+		}
+
+		delay := retryDelays[delivery.retries]
+		delivery.retries++
+
+		logger.WithError(err).WithField("status", status).Debugf("Push delivery failed, retrying in %s", delay)
+
+		time.AfterFunc(delay, func() {
+			s.enqueue(ctx, delivery)
+		})
+
+	default:
+		// Other errors (e.g. a payload that's too large) won't go away by retrying
+		logger.WithField("status", status).Warn("Push delivery rejected")
+	}
+}
+
+// Send a delivery to its push service. Returns the response's status.
+func (s *serviceImpl) push(ctx context.Context, delivery delivery) (int, error) {
+	subscription := delivery.subscription
+
+	err := checkEndpoint(subscription.Endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	p256dh, err := s.EncryptionService.Decrypt(subscription.P256dh)
+	if err != nil {
+		return 0, err
+	}
+
+	auth, err := s.EncryptionService.Decrypt(subscription.Auth)
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := encrypt(delivery.payload, p256dh, auth)
+	if err != nil {
+		return 0, err
+	}
+
+	authorization, err := s.Keys.authorization(subscription.Endpoint, s.Subject, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "POST", subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	request.Header.Set("Authorization", authorization)
+	request.Header.Set("Content-Encoding", "aes128gcm")
+	request.Header.Set("Content-Type", "application/octet-stream")
+	request.Header.Set("TTL", fmt.Sprintf("%d", int(messageTtl.Seconds())))
+
+	response, err := pushClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	return response.StatusCode, nil
+}